@@ -3,6 +3,7 @@ package v1beta1
 import (
 	"crypto/sha256"
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +25,20 @@ type ValueFromSource struct {
 	// Selects a key of a Secret.
 	// +optional
 	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// Namespace of the referenced ConfigMap or Secret, defaults to the namespace of the resource.
+	// Objects in other namespaces must allow access with the operator.grafana.com/allowed-namespaces annotation,
+	// e.g. Secrets synchronized from a central secret store
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ResolveNamespace returns the namespace of the referenced object, falling back to the namespace of the resource
+func (in ValueFromSource) ResolveNamespace(namespace string) string {
+	if in.Namespace != "" {
+		return in.Namespace
+	}
+
+	return namespace
 }
 
 // Common Options that all CRs should embed, excluding GrafanaSpec
@@ -36,6 +51,13 @@ type GrafanaCommonSpec struct {
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
 	ResyncPeriod metav1.Duration `json:"resyncPeriod,omitempty"`
 
+	// Delays each resync by a random duration of up to the given percentage of the resync period,
+	// spreading the requests of resources sharing the same resync period. Defaults to the operator's --resync-jitter-percent
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ResyncJitterPercent *int `json:"resyncJitterPercent,omitempty"`
+
 	// Selects Grafana instances for import
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec.instanceSelector is immutable"
 	InstanceSelector *metav1.LabelSelector `json:"instanceSelector"`
@@ -48,6 +70,149 @@ type GrafanaCommonSpec struct {
 	// Suspend pauses synchronizing attempts and tells the operator to ignore changes
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
+
+	// Wave orders the first apply to an instance, like sync waves: the resource is applied to an instance once all
+	// resources of lower waves targeting the instance are applied to it. Changes to applied resources are not held back
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Wave int32 `json:"wave,omitempty"`
+}
+
+// ActiveWindow limits the time span during which a resource is provisioned to Grafana instances
+// +kubebuilder:validation:XValidation:rule="!has(self.start) || !has(self.end) || timestamp(self.start) < timestamp(self.end)", message="start must be before end"
+// +kubebuilder:validation:XValidation:rule="has(self.schedule) == has(self.duration)", message="schedule and duration must be set together"
+type ActiveWindow struct {
+	// The resource is provisioned from this time on
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// The resource is removed from Grafana instances at this time
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+
+	// Cron expression (minute hour day-of-month month day-of-week) evaluated in UTC, the resource is provisioned at each
+	// occurrence for the given duration, within start and end if set
+	// +optional
+	// +kubebuilder:validation:Pattern=`^\S+(\s+\S+){4}$`
+	Schedule string `json:"schedule,omitempty"`
+
+	// How long the resource stays provisioned after each occurrence of the schedule
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// Validate checks the schedule of the window, the remaining fields are validated by the API server
+func (in *ActiveWindow) Validate() error {
+	if in == nil || in.Schedule == "" {
+		return nil
+	}
+
+	if in.Duration == nil || in.Duration.Duration <= 0 {
+		return fmt.Errorf("schedule %q requires a positive duration", in.Schedule)
+	}
+
+	_, err := parseCronSchedule(in.Schedule)
+
+	return err
+}
+
+// schedule returns the parsed schedule and duration of the window, nil when the window is not scheduled or invalid
+func (in *ActiveWindow) schedule() (*cronSchedule, time.Duration) {
+	if in.Schedule == "" || in.Duration == nil || in.Duration.Duration <= 0 {
+		return nil, 0
+	}
+
+	schedule, err := parseCronSchedule(in.Schedule)
+	if err != nil {
+		return nil, 0
+	}
+
+	return schedule, in.Duration.Duration
+}
+
+// Active reports whether t lies within the window, a nil window is always active
+func (in *ActiveWindow) Active(t time.Time) bool {
+	if in == nil {
+		return true
+	}
+
+	if in.Start != nil && t.Before(in.Start.Time) {
+		return false
+	}
+
+	if in.End != nil && !t.Before(in.End.Time) {
+		return false
+	}
+
+	if in.Schedule == "" {
+		return true
+	}
+
+	schedule, d := in.schedule()
+	if schedule == nil {
+		return false
+	}
+
+	// An occurrence within the last duration keeps the window open
+	opened := schedule.next(t.UTC().Add(-d))
+
+	return !opened.IsZero() && !opened.After(t)
+}
+
+// NextTransition returns the duration until the window opens or closes, 0 when the state does not change anymore
+func (in *ActiveWindow) NextTransition(t time.Time) time.Duration {
+	if in == nil {
+		return 0
+	}
+
+	if in.Start != nil && t.Before(in.Start.Time) {
+		return in.Start.Sub(t)
+	}
+
+	if in.End != nil && !t.Before(in.End.Time) {
+		return 0
+	}
+
+	var at time.Time
+
+	if schedule, d := in.schedule(); schedule != nil {
+		if in.Active(t) {
+			at = scheduledWindowEnd(schedule, d, t.UTC())
+		} else {
+			at = schedule.next(t.UTC())
+		}
+	}
+
+	if in.End != nil && (at.IsZero() || at.After(in.End.Time)) {
+		at = in.End.Time
+	}
+
+	if at.IsZero() {
+		return 0
+	}
+
+	return at.Sub(t)
+}
+
+// scheduledWindowEnd returns when the scheduled window open at t closes, overlapping occurrences extend the window,
+// windows longer than a day are cut there and reevaluated
+func scheduledWindowEnd(schedule *cronSchedule, d time.Duration, t time.Time) time.Time {
+	opened := schedule.next(t.Add(-d))
+	end := opened.Add(d)
+
+	for end.Sub(t) < 24*time.Hour {
+		next := schedule.next(opened)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+
+		opened = next
+		end = next.Add(d)
+	}
+
+	return end
 }
 
 // Common Functions that all CRs should implement, excluding Grafana
@@ -61,6 +226,13 @@ type CommonResource interface {
 	CommonStatus() *GrafanaCommonStatus
 }
 
+// Implemented by the resources embedding GrafanaCommonSpec
+// +kubebuilder:object:generate=false
+type CommonSpecResource interface {
+	CommonResource
+	CommonSpec() *GrafanaCommonSpec
+}
+
 // The most recent observed state of a Grafana resource
 type GrafanaCommonStatus struct {
 	// Results when synchonizing resource with Grafana instances
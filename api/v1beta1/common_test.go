@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -94,6 +96,85 @@ var _ = Describe("GrafanaCommonSpec#AllowCrossNamespaceImport Validation rule te
 	})
 })
 
+func TestActiveWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	start := metav1.NewTime(now.Add(time.Hour))
+	end := metav1.NewTime(now.Add(2 * time.Hour))
+
+	var none *ActiveWindow
+
+	assert.True(t, none.Active(now))
+	assert.Equal(t, time.Duration(0), none.NextTransition(now))
+
+	window := &ActiveWindow{Start: &start, End: &end}
+
+	assert.False(t, window.Active(now))
+	assert.Equal(t, time.Hour, window.NextTransition(now))
+
+	assert.True(t, window.Active(start.Time))
+	assert.Equal(t, time.Hour, window.NextTransition(start.Time))
+
+	assert.False(t, window.Active(end.Time))
+	assert.Equal(t, time.Duration(0), window.NextTransition(end.Time))
+
+	openEnded := &ActiveWindow{Start: &start}
+	assert.True(t, openEnded.Active(end.Time))
+	assert.Equal(t, time.Duration(0), openEnded.NextTransition(end.Time))
+}
+
+func TestActiveWindowSchedule(t *testing.T) {
+	// Sunday
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Business hours on weekdays
+	window := &ActiveWindow{
+		Schedule: "0 8 * * 1-5",
+		Duration: &metav1.Duration{Duration: 10 * time.Hour},
+	}
+	require.NoError(t, window.Validate())
+
+	assert.False(t, window.Active(now))
+	assert.Equal(t, 20*time.Hour, window.NextTransition(now))
+
+	monday := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	assert.True(t, window.Active(monday))
+	assert.Equal(t, 8*time.Hour+30*time.Minute, window.NextTransition(monday))
+
+	assert.False(t, window.Active(time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)))
+
+	// Overlapping occurrences keep the window open
+	overlapping := &ActiveWindow{
+		Schedule: "0 */2 * * *",
+		Duration: &metav1.Duration{Duration: 3 * time.Hour},
+	}
+	assert.True(t, overlapping.Active(now))
+	assert.Equal(t, 25*time.Hour, overlapping.NextTransition(now))
+
+	// The end bounds the schedule
+	end := metav1.NewTime(time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC))
+	window.End = &end
+	assert.Equal(t, 30*time.Minute, window.NextTransition(time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)))
+	assert.Equal(t, 2*time.Hour+30*time.Minute, window.NextTransition(monday))
+	assert.False(t, window.Active(end.Time))
+
+	// Day of month and day of week match either when both are restricted
+	either := &ActiveWindow{
+		Schedule: "0 0 15 * 0",
+		Duration: &metav1.Duration{Duration: time.Hour},
+	}
+	assert.True(t, either.Active(time.Date(2026, 3, 1, 0, 30, 0, 0, time.UTC)))
+	assert.True(t, either.Active(time.Date(2026, 3, 15, 0, 30, 0, 0, time.UTC)))
+	assert.False(t, either.Active(time.Date(2026, 3, 14, 0, 30, 0, 0, time.UTC)))
+
+	for _, invalid := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		w := &ActiveWindow{Schedule: invalid, Duration: &metav1.Duration{Duration: time.Hour}}
+		assert.Error(t, w.Validate(), invalid)
+		assert.False(t, w.Active(now), invalid)
+	}
+
+	assert.Error(t, (&ActiveWindow{Schedule: "* * * * *"}).Validate())
+}
+
 func TestGetPluginConfigMapKey(t *testing.T) {
 	longName := strings.Repeat("a", 100)
 	longNameHash := "2816597888e4a0d3a36b82b83316ab32680eb8f00f8cd3b904d681246d285a0e"
@@ -37,8 +37,42 @@ type GrafanaContentURLBasicAuth struct {
 	Password *v1.SecretKeySelector `json:"password,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!(has(self.basicAuth) && has(self.bearerToken))",message="Only one of basicAuth or bearerToken can be declared at the same time"
+// +kubebuilder:validation:XValidation:rule="!(has(self.ca) && has(self.insecureSkipVerify) && self.insecureSkipVerify)",message="ca and insecureSkipVerify are mutually exclusive"
 type GrafanaContentURLAuthorization struct {
 	BasicAuth *GrafanaContentURLBasicAuth `json:"basicAuth,omitempty"`
+
+	// Token sent in the Authorization header as Bearer token, e.g. a GitHub personal access token
+	// +optional
+	BearerToken *v1.SecretKeySelector `json:"bearerToken,omitempty"`
+
+	// Additional headers sent with the request, e.g. PRIVATE-TOKEN for GitLab.
+	// The Authorization header of basicAuth or bearerToken takes precedence
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Headers []GrafanaContentURLHeader `json:"headers,omitempty"`
+
+	// PEM encoded CA bundle to verify the certificate of the server with.
+	// Without it, the certificate of the server is verified against the system roots
+	// +optional
+	CA *GrafanaContentEnvFromSource `json:"ca,omitempty"`
+
+	// Skip verifying the certificate of the server, the credentials are sent to whoever answers for the url.
+	// Prefer ca for servers with certificates of a private CA
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.valueFrom)",message="Exactly one of value or valueFrom must be declared"
+type GrafanaContentURLHeader struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Inline header value
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Reference on value source, might be the reference on a secret or config map
+	// +optional
+	ValueFrom *GrafanaContentEnvFromSource `json:"valueFrom,omitempty"`
 }
 
 type JsonnetProjectBuild struct {
@@ -53,6 +87,158 @@ type GrafanaComContentReference struct {
 	Revision *int `json:"revision,omitempty"`
 }
 
+// GitRepoContentReference is a reference to a file in a Git repository, fetched over HTTP(S)
+type GitRepoContentReference struct {
+	// URL of the repository, e.g. https://github.com/org/dashboards.git
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Branch, tag or commit to fetch, the default branch of the repository by default
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Glob selecting the file relative to the root of the repository, e.g. dashboards/*/overview.json.
+	// Must match a single JSON or YAML file
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Secret in the namespace of the resource holding the username and password keys used to authenticate, e.g. a personal access token as password
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// How often the ref is checked for new commits, defaults to 5m. Pushes received by the Git webhook of the operator are fetched right away
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+// S3ContentReference is a reference to an object in an Amazon S3 or S3 compatible bucket
+// +kubebuilder:validation:XValidation:rule="!has(self.endpoint) || has(self.secretRef)",message="endpoint requires secretRef"
+type S3ContentReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Key of the object, e.g. dashboards/overview.json. JSON and YAML objects are supported
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Region of the bucket, e.g. eu-west-1
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// Endpoint of S3 compatible storage, e.g. https://minio.example.com. Objects are addressed path-style below it.
+	// Defaults to the AWS endpoint of the region. Requires secretRef
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Secret in the namespace of the resource holding the accessKeyId, secretAccessKey and optionally sessionToken keys.
+	// Required unless the operator runs with --allow-ambient-storage-credentials, then the IAM role of the operator
+	// service account (IRSA) or the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY variables of the operator are used
+	// and requests are sent unsigned when neither is available
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// GCSContentReference is a reference to an object in a Google Cloud Storage bucket
+// +kubebuilder:validation:XValidation:rule="!has(self.endpoint) || has(self.secretRef)",message="endpoint requires secretRef"
+type GCSContentReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Name of the object, e.g. dashboards/overview.json. JSON and YAML objects are supported
+	// +kubebuilder:validation:MinLength=1
+	Object string `json:"object"`
+
+	// Endpoint of the storage API, defaults to https://storage.googleapis.com. Requires secretRef
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Secret in the namespace of the resource holding a service account key in the serviceAccountKey key.
+	// Required unless the operator runs with --allow-ambient-storage-credentials, then the service account of the
+	// operator is used through Workload Identity
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// AzureBlobContentReference is a reference to a blob in an Azure storage account
+// +kubebuilder:validation:XValidation:rule="!has(self.endpoint) || has(self.secretRef)",message="endpoint requires secretRef"
+type AzureBlobContentReference struct {
+	// Name of the storage account
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]{3,24}$`
+	Account string `json:"account"`
+
+	// +kubebuilder:validation:MinLength=1
+	Container string `json:"container"`
+
+	// Name of the blob, e.g. dashboards/overview.json. JSON and YAML blobs are supported
+	// +kubebuilder:validation:MinLength=1
+	Blob string `json:"blob"`
+
+	// Endpoint of the blob service, defaults to https://<account>.blob.core.windows.net. Requires secretRef
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Secret in the namespace of the resource holding a SAS token in the sasToken key.
+	// Required unless the operator runs with --allow-ambient-storage-credentials, then the identity of the operator
+	// is used through Azure Workload Identity
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// OCIContentReference is a reference to a file published as OCI artifact, e.g. with oras push
+type OCIContentReference struct {
+	// Reference of the artifact, e.g. ghcr.io/org/dashboards:v1 or ghcr.io/org/dashboards@sha256:<digest>
+	// +kubebuilder:validation:MinLength=1
+	Ref string `json:"ref"`
+
+	// Digest of the artifact manifest, e.g. sha256:<digest>. Pins the artifact like the digest of an image reference,
+	// the tag of ref is ignored
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Title of the layer holding the model, as in its org.opencontainers.image.title annotation.
+	// Required for artifacts with several layers. JSON and YAML files are supported
+	// +optional
+	File string `json:"file,omitempty"`
+
+	// Secret of type kubernetes.io/dockerconfigjson in the namespace of the resource, like the imagePullSecrets of pods.
+	// Artifacts are pulled anonymously without it
+	// +optional
+	PullSecretRef *v1.LocalObjectReference `json:"pullSecretRef,omitempty"`
+
+	// Pull over plain HTTP, e.g. from a registry inside the cluster
+	// +optional
+	PlainHTTP bool `json:"plainHTTP,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Keep;Degrade;Delete
+type SourceDeletionPolicy string
+
+const (
+	// SourceDeletionPolicyKeep keeps the last applied content in Grafana
+	SourceDeletionPolicyKeep SourceDeletionPolicy = "Keep"
+	// SourceDeletionPolicyDegrade keeps the last applied content in Grafana and fails the reconcile
+	SourceDeletionPolicyDegrade SourceDeletionPolicy = "Degrade"
+	// SourceDeletionPolicyDelete removes the content from Grafana after the grace period
+	SourceDeletionPolicyDelete SourceDeletionPolicy = "Delete"
+)
+
+// SourceDeletion configures what happens to applied content when its source is deleted
+type SourceDeletion struct {
+	// Keep leaves the last applied content in Grafana, Degrade additionally fails the reconcile with the InvalidSpec condition
+	// and Delete removes the content from Grafana once the source is missing for longer than the grace period
+	// +kubebuilder:default=Keep
+	// +optional
+	Policy SourceDeletionPolicy `json:"policy,omitempty"`
+
+	// How long the source must be missing before the content is removed with the Delete policy
+	// +optional
+	GracePeriod metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
 type GrafanaContentSpec struct {
 	// Manually specify the uid, overwrites uids already present in the json model.
 	// Can be any string consisting of alphanumeric characters, - and _ with a maximum length of 40.
@@ -70,6 +256,10 @@ type GrafanaContentSpec struct {
 	// +optional
 	GzipJSON []byte `json:"gzipJson,omitempty"`
 
+	// model yaml, converted to json. Multiple documents are merged in order, keys of later documents override earlier ones
+	// +optional
+	YAML string `json:"yaml,omitempty"`
+
 	// model url
 	// +optional
 	URL string `json:"url,omitempty"`
@@ -82,6 +272,11 @@ type GrafanaContentSpec struct {
 	// +optional
 	Jsonnet string `json:"jsonnet,omitempty"`
 
+	// Selects ConfigMaps in the namespace of the resource holding libraries imported by jsonnet, e.g. a vendored grafonnet.
+	// Every key is a file of the directory in the operator.grafana.com/jsonnet-path annotation, the name of the ConfigMap by default
+	// +optional
+	JsonnetLibSelector *metav1.LabelSelector `json:"jsonnetLibSelector,omitempty"`
+
 	// Jsonnet project build
 	JsonnetProjectBuild *JsonnetProjectBuild `json:"jsonnetLib,omitempty"`
 
@@ -93,19 +288,50 @@ type GrafanaContentSpec struct {
 	// +optional
 	GrafanaCom *GrafanaComContentReference `json:"grafanaCom,omitempty"`
 
+	// model from a file in a Git repository
+	// +optional
+	GitRepo *GitRepoContentReference `json:"gitRepo,omitempty"`
+
+	// model from an object in an S3 bucket
+	// +optional
+	S3 *S3ContentReference `json:"s3,omitempty"`
+
+	// model from an object in a Google Cloud Storage bucket
+	// +optional
+	GCS *GCSContentReference `json:"gcs,omitempty"`
+
+	// model from a blob in Azure Blob Storage
+	// +optional
+	AzureBlob *AzureBlobContentReference `json:"azureBlob,omitempty"`
+
+	// model from a file of an OCI artifact in a container registry
+	// +optional
+	OCI *OCIContentReference `json:"oci,omitempty"`
+
 	// Cache duration for models fetched from URLs
 	// +optional
 	ContentCacheDuration metav1.Duration `json:"contentCacheDuration,omitempty"`
 
+	// Keep applying the last fetched model when the url, grafana.com, the Git repository, the bucket or the registry can't be reached.
+	// The ContentStale condition is set once the source is unreachable for longer than the threshold
+	// +optional
+	StaleThreshold metav1.Duration `json:"staleThreshold,omitempty"`
+
+	// What happens to the applied content when the referenced ConfigMap, Secret or url is deleted.
+	// The SourceMissing condition is set while the source is missing, defaults to the Keep policy
+	// +optional
+	OnSourceDeletion *SourceDeletion `json:"onSourceDeletion,omitempty"`
+
 	// maps required data sources to existing ones
 	// +optional
 	Datasources []GrafanaContentDatasource `json:"datasources,omitempty"`
 
-	// environments variables as a map
+	// environments variables as a map, passed to jsonnet as external variables. The ${NAME} placeholders of the model
+	// of any other source are replaced by the values before it is applied, $${NAME} keeps a literal ${NAME}
 	// +optional
 	Envs []GrafanaContentEnv `json:"envs,omitempty"`
 
-	// environments variables from secrets or config maps
+	// environments variables from secrets or config maps, named after their key
 	// +optional
 	EnvsFrom []GrafanaContentEnvFromSource `json:"envFrom,omitempty"`
 }
@@ -116,6 +342,33 @@ type GrafanaContentStatus struct {
 	ContentURL       string      `json:"contentUrl,omitempty"`
 	Hash             string      `json:"hash,omitempty"`
 	UID              string      `json:"uid,omitempty"`
+
+	// Newer revisions of content pinned to a grafana.com revision, reported when the operator checks grafana.com for revisions
+	// +optional
+	GrafanaComRevision *GrafanaComRevisionStatus `json:"grafanaComRevision,omitempty"`
+
+	// Commit the content fetched from spec.gitRepo comes from
+	// +optional
+	GitRevision string `json:"gitRevision,omitempty"`
+
+	// ETag of the object fetched from spec.s3, spec.gcs or spec.azureBlob, the object is only downloaded again once it changes
+	// +optional
+	ObjectETag string `json:"objectETag,omitempty"`
+
+	// Digest of the artifact manifest fetched from spec.oci, the file is only downloaded again once the digest changes
+	// +optional
+	OCIDigest string `json:"ociDigest,omitempty"`
+}
+
+// GrafanaComRevisionStatus is the result of the last check of grafana.com for newer revisions
+type GrafanaComRevisionStatus struct {
+	// Latest revision published on grafana.com
+	Latest int `json:"latest"`
+	// Last time grafana.com was checked
+	LastChecked metav1.Time `json:"lastChecked"`
+	// Merge patch pinning spec.grafanaCom to the latest revision, set while a newer revision is available
+	// +optional
+	Patch string `json:"patch,omitempty"`
 }
 
 // Common interface for any resource that embeds or references Grafana-native model content.
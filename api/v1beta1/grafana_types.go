@@ -20,10 +20,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/blang/semver/v4"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -43,7 +49,14 @@ const (
 	OperatorStageService        OperatorStageName = "service"
 	OperatorStageIngress        OperatorStageName = "ingress"
 	OperatorStageHTTPRoute      OperatorStageName = "http route"
+	OperatorStageGRPCRoute      OperatorStageName = "grpc route"
 	OperatorStagePlugins        OperatorStageName = "plugins"
+	OperatorStagePdb            OperatorStageName = "pod disruption budget"
+	OperatorStageHpa            OperatorStageName = "horizontal pod autoscaler"
+	OperatorStageNetworkPolicy  OperatorStageName = "network policy"
+	OperatorStageMonitor        OperatorStageName = "monitor"
+	OperatorStageImageRenderer  OperatorStageName = "image renderer"
+	OperatorStageDatabase       OperatorStageName = "database"
 	OperatorStageDeployment     OperatorStageName = "deployment"
 	OperatorStageComplete       OperatorStageName = "complete"
 )
@@ -61,6 +74,18 @@ type OperatorReconcileVars struct {
 
 	// env var value for installed plugins
 	Plugins string
+
+	// env var value for the declared plugins of spec.plugins.unsignedAllowList
+	UnsignedPlugins string
+
+	// env var value for the host and port of spec.database
+	DatabaseHost string
+
+	// out-of-band modifications of the workloads found by spec.driftDetection, one entry per object
+	Drift []string
+
+	// why status.adminUrl was held back, set while the spec.dns hostname doesn't resolve yet
+	DNSPending string
 }
 
 // GrafanaSpec defines the desired state of Grafana
@@ -68,12 +93,20 @@ type GrafanaSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// Config defines how your grafana ini file should looks like.
 	Config map[string]map[string]string `json:"config,omitempty"`
+	// ConfigFrom lists keys of ConfigMaps in the namespace of the instance holding grafana.ini fragments.
+	// Fragments are merged in order, later ones override earlier ones and spec.config overrides all of them
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	ConfigFrom []v1.ConfigMapKeySelector `json:"configFrom,omitempty"`
 	// Ingress sets how the ingress object should look like with your grafana instance.
 	Ingress *IngressNetworkingV1 `json:"ingress,omitempty"`
 	// Route sets how the ingress object should look like with your grafana instance, this only works in Openshift.
 	Route *RouteOpenshiftV1 `json:"route,omitempty"`
 	// HTTPRoute sets how the ingress object should look like with your grafana instance, this only works use gateway api.
 	HTTPRoute *HTTPRouteV1 `json:"httpRoute,omitempty"`
+	// GRPCRoute generates a Gateway API GRPCRoute routing gRPC traffic to your grafana instance, alongside or instead of the HTTPRoute.
+	// +optional
+	GRPCRoute *GRPCRouteV1 `json:"grpcRoute,omitempty"`
 	// Service sets how the service object should look like with your grafana instance, contains a number of defaults.
 	Service *ServiceV1 `json:"service,omitempty"`
 	// Version sets the tag of the default image: docker.io/grafana/grafana.
@@ -94,12 +127,719 @@ type GrafanaSpec struct {
 	Preferences *GrafanaPreferences `json:"preferences,omitempty"`
 	// DisableDefaultAdminSecret prevents operator from creating default admin-credentials secret
 	DisableDefaultAdminSecret bool `json:"disableDefaultAdminSecret,omitempty"`
-	// Suspend pauses reconciliation of owned resources like deployments, Services, Etc. upon changes
+	// Suspend pauses reconciliation of owned resources like deployments, Services, Etc. upon changes,
+	// content keeps being applied to the running instance
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
+	// SuspendContent pauses applying dashboards, datasources, folders and other content to the instance,
+	// owned resources keep being reconciled
+	// +optional
+	SuspendContent bool `json:"suspendContent,omitempty"`
 	// DisableDefaultSecurityContext prevents the operator from populating securityContext on deployments
 	// +kubebuilder:validation:Enum=Pod;Container;All
 	DisableDefaultSecurityContext string `json:"disableDefaultSecurityContext,omitempty"`
+	// DNS publishes a hostname for the instance through external-dns
+	// +optional
+	DNS *GrafanaDNS `json:"dns,omitempty"`
+	// Security sets the login and session hardening options of the [security] section, safe defaults
+	// are applied to the options which are neither set here nor in spec.config
+	// +optional
+	Security *GrafanaSecurity `json:"security,omitempty"`
+	// AlertScreenshots configures the panel screenshots attached to alert notifications. Screenshots are captured
+	// by default once an image renderer is configured with spec.imageRenderer or server_url of the [rendering] section in spec.config
+	// +optional
+	AlertScreenshots *GrafanaAlertScreenshots `json:"alertScreenshots,omitempty"`
+	// ExternalImageStorage configures the store images of alert notifications and shared panels are uploaded to
+	// +optional
+	ExternalImageStorage *GrafanaExternalImageStorage `json:"externalImageStorage,omitempty"`
+	// Preset applies a predefined set of grafana.ini settings, settings present in spec.config take precedence.
+	// viewer-only grants anonymous users read-only access and disables editing and exploration
+	// +optional
+	// +kubebuilder:validation:Enum=viewer-only
+	Preset GrafanaPreset `json:"preset,omitempty"`
+	// Sizing applies curated resource requests and limits, GOMEMLIMIT and database connection pool settings
+	// for small, medium or large loads, spec.deployment and spec.config take precedence.
+	// custom applies none of them and leaves sizing to spec.deployment and spec.config
+	// +optional
+	// +kubebuilder:validation:Enum=small;medium;large;custom
+	Sizing GrafanaSizing `json:"sizing,omitempty"`
+	// Role of the instance among instances sharing a database. Content resources are only applied to primary instances,
+	// readonly instances serve the content of the shared database and don't execute alert rules
+	// +optional
+	// +kubebuilder:validation:Enum=primary;readonly
+	Role GrafanaRole `json:"role,omitempty"`
+	// Primary is the name of the primary instance in the same namespace sharing its database with this readonly instance,
+	// the plugins required by the content of the primary are installed on this instance too
+	// +optional
+	Primary string `json:"primary,omitempty"`
+	// Name of the GrafanaClass providing defaults for this spec, fields set here take precedence
+	// +optional
+	ClassName string `json:"className,omitempty"`
+	// ChangeWindow restricts when dashboards and alert rule groups may be changed on this instance,
+	// changes outside the window are deferred until it opens
+	// +optional
+	ChangeWindow *ChangeWindow `json:"changeWindow,omitempty"`
+	// Mesh sets the pod annotations Grafana needs to start reliably next to a service mesh sidecar
+	// +optional
+	Mesh *GrafanaMesh `json:"mesh,omitempty"`
+	// Telemetry disabled turns off usage reporting, update checks, feedback links and the news feed,
+	// which contact grafana.com, for air-gapped and privacy-sensitive deployments. Settings in spec.config take precedence
+	// +optional
+	// +kubebuilder:validation:Enum=enabled;disabled
+	Telemetry GrafanaTelemetry `json:"telemetry,omitempty"`
+	// StatusPage maintains an organization anonymous users are signed into as viewers, holding copies of the selected
+	// dashboards only, so public status pages can be served by an instance holding internal content
+	// +optional
+	StatusPage *GrafanaStatusPage `json:"statusPage,omitempty"`
+	// PodDisruptionBudget configures the PodDisruptionBudget created while spec.deployment.spec.replicas,
+	// or spec.autoscaling.maxReplicas when autoscaling, is greater than 1,
+	// so voluntary disruptions like node drains never evict all replicas at once. Defaults to maxUnavailable 1
+	// +optional
+	PodDisruptionBudget *GrafanaPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+	// Autoscaling creates a HorizontalPodAutoscaler scaling the deployment, spec.deployment.spec.replicas is ignored meanwhile
+	// +optional
+	Autoscaling *GrafanaAutoscaling `json:"autoscaling,omitempty"`
+	// NetworkPolicy creates a NetworkPolicy restricting the traffic of the Grafana pods to the operator, the ingress
+	// controllers and the listed egress endpoints
+	// +optional
+	NetworkPolicy *GrafanaNetworkPolicy `json:"networkPolicy,omitempty"`
+	// Metrics configures the scraping of the /metrics endpoint of Grafana by the prometheus-operator
+	// +optional
+	Metrics *GrafanaMetrics `json:"metrics,omitempty"`
+	// ImageRenderer deploys the grafana-image-renderer and points Grafana at it, so panels and dashboards can be
+	// rendered as images, e.g. for alert notifications and reports
+	// +optional
+	ImageRenderer *GrafanaImageRenderer `json:"imageRenderer,omitempty"`
+	// Plugins controls which plugins Grafana loads without a valid signature,
+	// overriding plugins.allow_loading_unsigned_plugins of spec.config
+	// +optional
+	Plugins *GrafanaPluginPolicy `json:"plugins,omitempty"`
+	// Database points Grafana at an existing PostgreSQL or MySQL database, overriding the [database] section of spec.config.
+	// The rollout waits until the database accepts connections
+	// +optional
+	Database *GrafanaDatabase `json:"database,omitempty"`
+	// Probes tunes the probes of the grafana container, e.g. to give instances installing many plugins more time to start
+	// +optional
+	Probes *GrafanaProbes `json:"probes,omitempty"`
+	// RemoteCache points the [remote_cache] and the HA engine of [live] at an existing Redis or Valkey service, so sessions
+	// and live features work across replicas. Its settings take precedence over spec.config
+	// +optional
+	RemoteCache *GrafanaRemoteCache `json:"remoteCache,omitempty"`
+	// DriftDetection periodically compares the Deployment, Service and Ingress of the instance with the operator's render
+	// and reports fields modified by other controllers or by hand in the WorkloadDrift condition
+	// +optional
+	DriftDetection *GrafanaDriftDetection `json:"driftDetection,omitempty"`
+}
+
+// GrafanaDriftDetection configures the detection of out-of-band modifications of the workloads of the instance
+type GrafanaDriftDetection struct {
+	// Interval between two checks, defaults to 5m
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Revert restores the fields set by the operator. Otherwise the modifications are kept until the Grafana resource
+	// or its references change
+	// +optional
+	Revert bool `json:"revert,omitempty"`
+}
+
+// DefaultDriftDetectionInterval applies to spec.driftDetection without interval
+const DefaultDriftDetectionInterval = 5 * time.Minute
+
+// DriftDetectionInterval returns the interval between two checks of spec.driftDetection, 0 when disabled
+func (in *Grafana) DriftDetectionInterval() time.Duration {
+	if in.Spec.DriftDetection == nil {
+		return 0
+	}
+
+	if in.Spec.DriftDetection.Interval == nil || in.Spec.DriftDetection.Interval.Duration <= 0 {
+		return DefaultDriftDetectionInterval
+	}
+
+	return in.Spec.DriftDetection.Interval.Duration
+}
+
+// GrafanaRemoteCache references the Redis or Valkey service shared by the replicas of the instance
+type GrafanaRemoteCache struct {
+	// Address of the service as host:port, e.g. redis.cache.svc:6379
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+	// Password of the service, passed to the Grafana container as env var
+	// +optional
+	Password *v1.SecretKeySelector `json:"password,omitempty"`
+	// Database index of the remote cache
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DB int32 `json:"db,omitempty"`
+	// TLS connects to the remote cache with TLS
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+	// Prefix of the keys of the remote cache, e.g. when several instances share a database
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Live uses the service as HA engine of Grafana Live, so live updates reach clients of all replicas. Defaults to true
+	// +optional
+	Live *bool `json:"live,omitempty"`
+}
+
+// GrafanaProbes configures the startup, readiness and liveness probes of the grafana container. The probes request
+// the health endpoint with the protocol and, with server.serve_from_sub_path, the sub path of server.root_url
+type GrafanaProbes struct {
+	// Startup adds a startup probe holding back the readiness and liveness probes until Grafana responds.
+	// Its failureThreshold defaults to 30
+	// +optional
+	Startup *GrafanaProbe `json:"startup,omitempty"`
+	// Readiness overrides the settings of the readiness probe, its failureThreshold defaults to 1
+	// +optional
+	Readiness *GrafanaProbe `json:"readiness,omitempty"`
+	// Liveness adds a liveness probe restarting Grafana when it stops responding. Its failureThreshold defaults to 3
+	// +optional
+	Liveness *GrafanaProbe `json:"liveness,omitempty"`
+}
+
+// GrafanaProbe holds the settings of a probe, unset fields keep the defaults of the operator
+type GrafanaProbe struct {
+	// Path requested by the probe, relative to the sub path. Defaults to /api/health
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// Defaults to 10
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// Defaults to 3
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+type GrafanaPluginSignatureEnforcement string
+
+const (
+	GrafanaPluginSignatureStrict    GrafanaPluginSignatureEnforcement = "Strict"
+	GrafanaPluginSignatureAllowList GrafanaPluginSignatureEnforcement = "AllowList"
+)
+
+// GrafanaPluginPolicy maps the signature enforcement of the instance to plugins.allow_loading_unsigned_plugins
+// +kubebuilder:validation:XValidation:rule="!has(self.signatureEnforcement) || self.signatureEnforcement != 'Strict' || !has(self.unsignedAllowList) || size(self.unsignedAllowList) == 0",message="unsignedAllowList must be empty with signatureEnforcement Strict"
+type GrafanaPluginPolicy struct {
+	// SignatureEnforcement Strict loads signed plugins only. AllowList also loads the plugins of unsignedAllowList
+	// declared in the plugins of resources targeting the instance
+	// +optional
+	// +kubebuilder:validation:Enum=Strict;AllowList
+	// +kubebuilder:default=AllowList
+	SignatureEnforcement GrafanaPluginSignatureEnforcement `json:"signatureEnforcement,omitempty"`
+	// UnsignedAllowList lists the ids of the plugins Grafana loads without a valid signature, e.g. private plugins
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MinLength=1
+	UnsignedAllowList []string `json:"unsignedAllowList,omitempty"`
+}
+
+type GrafanaDatabaseType string
+
+const (
+	GrafanaDatabasePostgres GrafanaDatabaseType = "postgres"
+	GrafanaDatabaseMySQL    GrafanaDatabaseType = "mysql"
+)
+
+// GrafanaDatabaseClusterKind is the operator managing the PostgreSQL cluster of spec.database.clusterRef
+type GrafanaDatabaseClusterKind string
+
+const (
+	GrafanaDatabaseClusterCloudNativePG GrafanaDatabaseClusterKind = "CloudNativePG"
+	GrafanaDatabaseClusterZalando       GrafanaDatabaseClusterKind = "Zalando"
+)
+
+// GrafanaDatabase references the connection details of the database, which are passed to Grafana as GF_DATABASE_* env vars
+// +kubebuilder:validation:XValidation:rule="has(self.secretRef) != has(self.clusterRef)",message="exactly one of secretRef and clusterRef is required"
+// +kubebuilder:validation:XValidation:rule="!has(self.clusterRef) || !has(self.type) || self.type == 'postgres'",message="clusterRef requires type postgres"
+type GrafanaDatabase struct {
+	// Type of the database
+	// +optional
+	// +kubebuilder:validation:Enum=postgres;mysql
+	// +kubebuilder:default=postgres
+	Type GrafanaDatabaseType `json:"type,omitempty"`
+	// SecretRef references a Secret in the namespace of the instance holding the keys host, username and password,
+	// optionally port and dbname. This is the layout of the app Secret of CloudNativePG clusters
+	// +optional
+	SecretRef *v1.LocalObjectReference `json:"secretRef,omitempty"`
+	// ClusterRef references a PostgreSQL cluster in the namespace of the instance, its Secret and service are used
+	// +optional
+	ClusterRef *GrafanaDatabaseClusterRef `json:"clusterRef,omitempty"`
+	// SSLMode sets database.ssl_mode, e.g. require or verify-full for postgres and true or skip-verify for mysql
+	// +optional
+	SSLMode string `json:"sslMode,omitempty"`
+	// SkipConnectivityCheck rolls out the instance without waiting for the database to accept connections
+	// +optional
+	SkipConnectivityCheck bool `json:"skipConnectivityCheck,omitempty"`
+}
+
+// GrafanaDatabaseClusterRef references a cluster of CloudNativePG, using its app Secret and rw service,
+// or of the Zalando postgres-operator, using the credentials Secret of the user and the master service
+// +kubebuilder:validation:XValidation:rule="self.kind != 'Zalando' || (has(self.user) && has(self.database))",message="user and database are required for Zalando clusters"
+type GrafanaDatabaseClusterRef struct {
+	// +kubebuilder:validation:Enum=CloudNativePG;Zalando
+	Kind GrafanaDatabaseClusterKind `json:"kind"`
+	// Name of the cluster
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Database Grafana uses, defaults to the app database of CloudNativePG clusters
+	// +optional
+	Database string `json:"database,omitempty"`
+	// User Grafana connects as, only used for Zalando clusters
+	// +optional
+	User string `json:"user,omitempty"`
+}
+
+// GrafanaMetrics configures how the metrics of the instance are collected
+type GrafanaMetrics struct {
+	// ServiceMonitor creates a ServiceMonitor or PodMonitor of the prometheus-operator scraping the instance
+	// +optional
+	ServiceMonitor *GrafanaServiceMonitor `json:"serviceMonitor,omitempty"`
+}
+
+// GrafanaMonitorKind is the kind of prometheus-operator resource scraping the instance
+type GrafanaMonitorKind string
+
+const (
+	GrafanaMonitorKindServiceMonitor GrafanaMonitorKind = "ServiceMonitor"
+	GrafanaMonitorKindPodMonitor     GrafanaMonitorKind = "PodMonitor"
+)
+
+// GrafanaServiceMonitor sets up the ServiceMonitor or PodMonitor of the instance
+type GrafanaServiceMonitor struct {
+	// Create the monitor, requires the CRDs of the prometheus-operator
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// ServiceMonitor scrapes the pods behind the service of the instance, PodMonitor scrapes the pods directly
+	// +optional
+	// +kubebuilder:default=ServiceMonitor
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+	Kind GrafanaMonitorKind `json:"kind,omitempty"`
+	// Labels added to the monitor, e.g. to match the serviceMonitorSelector or podMonitorSelector of a Prometheus
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Interval at which metrics are scraped, defaults to the scrape interval of the Prometheus
+	// +optional
+	// +kubebuilder:validation:Pattern="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	Interval string `json:"interval,omitempty"`
+	// Timeout of scrapes, defaults to the scrape timeout of the Prometheus
+	// +optional
+	// +kubebuilder:validation:Pattern="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+	// TLS configuration used when Grafana serves https, passed on to the endpoint of the monitor
+	// +optional
+	TLSConfig *GrafanaMonitorTLSConfig `json:"tlsConfig,omitempty"`
+}
+
+// GrafanaMonitorTLSConfig is the subset of the SafeTLSConfig of the prometheus-operator referencing secrets and config maps
+type GrafanaMonitorTLSConfig struct {
+	// Certificate authority used to verify the certificate of Grafana
+	// +optional
+	CA *GrafanaMonitorSecretOrConfigMap `json:"ca,omitempty"`
+	// Client certificate presented to Grafana
+	// +optional
+	Cert *GrafanaMonitorSecretOrConfigMap `json:"cert,omitempty"`
+	// Secret holding the key of the client certificate
+	// +optional
+	KeySecret *v1.SecretKeySelector `json:"keySecret,omitempty"`
+	// Server name used to verify the hostname of the certificate of Grafana
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// Skip the verification of the certificate of Grafana
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// GrafanaMonitorSecretOrConfigMap references a key of either a Secret or a ConfigMap
+// +kubebuilder:validation:XValidation:rule="has(self.secret) != has(self.configMap)",message="Exactly one of secret or configMap must be declared"
+type GrafanaMonitorSecretOrConfigMap struct {
+	// +optional
+	Secret *v1.SecretKeySelector `json:"secret,omitempty"`
+	// +optional
+	ConfigMap *v1.ConfigMapKeySelector `json:"configMap,omitempty"`
+}
+
+type GrafanaImageRendererMode string
+
+const (
+	GrafanaImageRendererSidecar GrafanaImageRendererMode = "Sidecar"
+	GrafanaImageRendererRemote  GrafanaImageRendererMode = "Remote"
+)
+
+// GrafanaImageRenderer configures the grafana-image-renderer of the instance
+// +kubebuilder:validation:XValidation:rule="!has(self.autoscaling) || self.mode == 'Remote'",message="autoscaling requires mode Remote"
+type GrafanaImageRenderer struct {
+	// Mode Sidecar runs the renderer as a container in each Grafana pod, Remote runs it in a separate
+	// deployment and service shared by all replicas
+	// +optional
+	// +kubebuilder:validation:Enum=Sidecar;Remote
+	// +kubebuilder:default=Sidecar
+	Mode GrafanaImageRendererMode `json:"mode,omitempty"`
+	// Image of the renderer, defaults to the renderer version the operator was released with
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Replicas of the renderer deployment, only used in Remote mode. Defaults to 1
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Autoscaling scales the renderer deployment with a KEDA ScaledObject on the render queue of the instance,
+	// replacing replicas. Requires mode Remote and KEDA
+	// +optional
+	Autoscaling *GrafanaImageRendererAutoscaling `json:"autoscaling,omitempty"`
+	// Resources of the renderer container
+	// +optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Env of the renderer container, e.g. to tune the browser or set an AUTH_TOKEN
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+}
+
+// GrafanaImageRendererAutoscaling sets the bounds and the Prometheus trigger of the KEDA ScaledObject of the renderer
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type GrafanaImageRendererAutoscaling struct {
+	// Lower limit of replicas. Defaults to 1
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Upper limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Address of the Prometheus KEDA queries, e.g. http://prometheus-operated.monitoring.svc:9090
+	// +kubebuilder:validation:MinLength=1
+	ServerAddress string `json:"serverAddress"`
+	// Query returning the render load of the instance. Defaults to the sum of grafana_rendering_queue_size of the
+	// instance, as scraped through spec.metrics.serviceMonitor
+	// +optional
+	Query string `json:"query,omitempty"`
+	// Value of the query one replica handles, the replicas are the query result divided by the threshold. Defaults to 5
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Threshold string `json:"threshold,omitempty"`
+	// Seconds between two queries. Defaults to 30
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+}
+
+// GrafanaAutoscaling sets the bounds and metrics of the HorizontalPodAutoscaler of the instance
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type GrafanaAutoscaling struct {
+	// Create the HorizontalPodAutoscaler and leave the replicas of the deployment to it. Defaults to true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Lower limit of replicas. Defaults to 1
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Upper limit of replicas
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Metrics the desired replica count is calculated from. Defaults to an average CPU utilization of 80%
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+}
+
+// GrafanaPodDisruptionBudget sets the disruptions the PodDisruptionBudget of the instance allows
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable cannot be set at the same time"
+type GrafanaPodDisruptionBudget struct {
+	// Create the PodDisruptionBudget while the instance runs more than one replica. Defaults to true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Number or percentage of replicas that must remain available during a disruption
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// Number or percentage of replicas that may be unavailable during a disruption
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// GrafanaNetworkPolicy selects the traffic allowed besides the operator, the gateways of spec.httpRoute and spec.grpcRoute,
+// the OpenShift router for spec.route, the remote image renderer and the other replicas of the instance
+type GrafanaNetworkPolicy struct {
+	// Namespaces of the ingress controllers forwarding the traffic of spec.ingress
+	// +optional
+	IngressNamespaces []string `json:"ingressNamespaces,omitempty"`
+	// Additional peers allowed to connect to Grafana, e.g. Prometheus scraping its metrics
+	// +optional
+	IngressFrom []networkingv1.NetworkPolicyPeer `json:"ingressFrom,omitempty"`
+	// Endpoints Grafana may connect to, e.g. datasources, the database or grafana.com for plugin installs.
+	// DNS lookups are always allowed
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// GrafanaStatusPage selects the dashboards copied into the anonymous organization.
+// The operator removes all other members of the organization and all dashboards that aren't selected
+type GrafanaStatusPage struct {
+	// Name of the organization, created by the operator when missing
+	// +optional
+	// +kubebuilder:default=Status
+	// +kubebuilder:validation:MinLength=1
+	OrgName string `json:"orgName,omitempty"`
+	// Labels of the GrafanaDashboards applied to the instance whose dashboards are copied into the organization
+	DashboardSelector *metav1.LabelSelector `json:"dashboardSelector"`
+}
+
+type GrafanaMeshProvider string
+
+const (
+	GrafanaMeshIstio   GrafanaMeshProvider = "istio"
+	GrafanaMeshLinkerd GrafanaMeshProvider = "linkerd"
+)
+
+// GrafanaMesh adapts the Grafana pod to the sidecar of a service mesh.
+// The alerting gossip port always bypasses the sidecar, peers connect to each other by pod IP
+type GrafanaMesh struct {
+	// Service mesh injecting the sidecar
+	// +kubebuilder:validation:Enum=istio;linkerd
+	Provider GrafanaMeshProvider `json:"provider"`
+	// Start Grafana only once the sidecar proxy is ready, so its first connections, e.g. to the database, do not fail.
+	// Defaults to true
+	// +optional
+	HoldApplicationUntilProxyStarts *bool `json:"holdApplicationUntilProxyStarts,omitempty"`
+	// Let requests to the Grafana HTTP port bypass the sidecar, so the operator reaches the instance without being
+	// part of the mesh. Required with strict mTLS unless the operator runs with a sidecar as well
+	// +optional
+	ExcludeHTTPPort bool `json:"excludeHTTPPort,omitempty"`
+	// Additional outbound ports bypassing the sidecar, e.g. of a database Grafana connects to with its own TLS
+	// +optional
+	ExcludeOutboundPorts []int32 `json:"excludeOutboundPorts,omitempty"`
+}
+
+// ChangeWindow is a recurring time span during which content changes are applied to an instance
+type ChangeWindow struct {
+	// Days of the week the window opens, every day when empty
+	// +optional
+	// +kubebuilder:validation:items:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+	Days []string `json:"days,omitempty"`
+	// Time of day the window opens, in 24h HH:MM format
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// How long the window stays open, at most a week
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
+	Duration metav1.Duration `json:"duration"`
+	// IANA name of the time zone of start, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Until returns 0 when t lies within the window, otherwise the duration until the window opens next.
+// A nil window is always open
+func (in *ChangeWindow) Until(t time.Time) (time.Duration, error) {
+	if in == nil {
+		return 0, nil
+	}
+
+	loc := time.UTC
+	if in.TimeZone != "" {
+		var err error
+
+		loc, err = time.LoadLocation(in.TimeZone)
+		if err != nil {
+			return 0, fmt.Errorf("invalid change window time zone %q: %w", in.TimeZone, err)
+		}
+	}
+
+	start, err := time.Parse("15:04", in.Start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid change window start %q: %w", in.Start, err)
+	}
+
+	if in.Duration.Duration <= 0 || in.Duration.Duration > 7*24*time.Hour {
+		return 0, fmt.Errorf("change window duration %s must be positive and at most a week", in.Duration.Duration)
+	}
+
+	local := t.In(loc)
+
+	var next time.Duration
+
+	// Windows opened up to a week ago may still be open
+	for offset := -7; offset <= 7; offset++ {
+		opens := time.Date(local.Year(), local.Month(), local.Day()+offset, start.Hour(), start.Minute(), 0, 0, loc)
+		if !in.opensOn(opens.Weekday()) {
+			continue
+		}
+
+		if !local.Before(opens) && local.Before(opens.Add(in.Duration.Duration)) {
+			return 0, nil
+		}
+
+		if opens.After(local) && (next == 0 || opens.Sub(local) < next) {
+			next = opens.Sub(local)
+		}
+	}
+
+	return next, nil
+}
+
+func (in *ChangeWindow) opensOn(day time.Weekday) bool {
+	if len(in.Days) == 0 {
+		return true
+	}
+
+	return slices.Contains(in.Days, day.String())
+}
+
+type GrafanaPreset string
+
+const (
+	GrafanaPresetViewerOnly GrafanaPreset = "viewer-only"
+)
+
+type GrafanaSizing string
+
+const (
+	GrafanaSizingSmall  GrafanaSizing = "small"
+	GrafanaSizingMedium GrafanaSizing = "medium"
+	GrafanaSizingLarge  GrafanaSizing = "large"
+	GrafanaSizingCustom GrafanaSizing = "custom"
+)
+
+type GrafanaTelemetry string
+
+const (
+	GrafanaTelemetryEnabled  GrafanaTelemetry = "enabled"
+	GrafanaTelemetryDisabled GrafanaTelemetry = "disabled"
+)
+
+type GrafanaRole string
+
+const (
+	GrafanaRolePrimary  GrafanaRole = "primary"
+	GrafanaRoleReadonly GrafanaRole = "readonly"
+)
+
+// GrafanaDNS adds external-dns annotations to the Ingress or HTTPRoute, or the Service if neither is configured
+type GrafanaDNS struct {
+	// Hostname of the record, set as external-dns.alpha.kubernetes.io/hostname annotation
+	// +kubebuilder:validation:MinLength=1
+	Hostname string `json:"hostname"`
+	// TTL of the record in seconds, set as external-dns.alpha.kubernetes.io/ttl annotation
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TTL *int64 `json:"ttl,omitempty"`
+	// Additional annotations for external-dns, e.g. Route53 routing policies
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Set status.adminUrl to the ingress hostname without waiting for it to resolve
+	// +optional
+	SkipResolutionCheck bool `json:"skipResolutionCheck,omitempty"`
+}
+
+// GrafanaSecurity groups login and session hardening options of grafana.ini
+// +kubebuilder:validation:XValidation:rule="!has(self.cookieSameSite) || self.cookieSameSite != 'none' || !has(self.cookieSecure) || self.cookieSecure",message="cookieSameSite none requires cookieSecure"
+type GrafanaSecurity struct {
+	// Number of days a remembered login is valid, login_remember_days
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	LoginRememberDays *int `json:"loginRememberDays,omitempty"`
+	// Only send cookies over https, cookie_secure. Defaults to true
+	// +optional
+	CookieSecure *bool `json:"cookieSecure,omitempty"`
+	// SameSite attribute of cookies, cookie_samesite
+	// +optional
+	// +kubebuilder:validation:Enum=lax;strict;none;disabled
+	CookieSameSite string `json:"cookieSameSite,omitempty"`
+	// Disable the use of Gravatar for profile images, disable_gravatar. Defaults to true
+	// +optional
+	DisableGravatar *bool `json:"disableGravatar,omitempty"`
+	// Add the Content-Security-Policy header, content_security_policy
+	// +optional
+	ContentSecurityPolicy *bool `json:"contentSecurityPolicy,omitempty"`
+	// Template of the Content-Security-Policy header, content_security_policy_template
+	// +optional
+	ContentSecurityPolicyTemplate string `json:"contentSecurityPolicyTemplate,omitempty"`
+	// Protection against brute force login attempts
+	// +optional
+	BruteForceProtection *GrafanaBruteForceProtection `json:"bruteForceProtection,omitempty"`
+}
+
+type GrafanaBruteForceProtection struct {
+	// Lock accounts after failed login attempts, inverse of disable_brute_force_login_protection. Defaults to true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Failed login attempts before an account is locked, brute_force_login_protection_max_attempts
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+}
+
+type GrafanaAlertScreenshots struct {
+	// Capture screenshots of the panels of alert rules, capture of [unified_alerting.screenshots].
+	// Defaults to true when an image renderer is configured
+	// +optional
+	Capture *bool `json:"capture,omitempty"`
+}
+
+// GrafanaExternalImageStorage configures the [external_image_storage] section, credentials are passed to Grafana
+// from Secrets as env vars or files
+// +kubebuilder:validation:XValidation:rule="(has(self.s3) ? 1 : 0) + (has(self.gcs) ? 1 : 0) + (has(self.azureBlob) ? 1 : 0) == 1",message="Exactly one of s3, gcs or azureBlob must be set"
+type GrafanaExternalImageStorage struct {
+	// +optional
+	S3 *GrafanaExternalImageStorageS3 `json:"s3,omitempty"`
+	// +optional
+	GCS *GrafanaExternalImageStorageGCS `json:"gcs,omitempty"`
+	// +optional
+	AzureBlob *GrafanaExternalImageStorageAzureBlob `json:"azureBlob,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.accessKey) == has(self.secretKey)",message="accessKey and secretKey must be set together"
+type GrafanaExternalImageStorageS3 struct {
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+	// Path prefix of the uploaded images within the bucket
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Endpoint of S3 compatible stores, e.g. MinIO
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Access key of the store, the default AWS credential chain is used without accessKey and secretKey
+	// +optional
+	AccessKey *v1.SecretKeySelector `json:"accessKey,omitempty"`
+	// Secret key of the store
+	// +optional
+	SecretKey *v1.SecretKeySelector `json:"secretKey,omitempty"`
+}
+
+type GrafanaExternalImageStorageGCS struct {
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// Path prefix of the uploaded images within the bucket
+	// +optional
+	Path string `json:"path,omitempty"`
+	// JSON key of the service account uploading images, mounted into the Grafana container.
+	// The default application credentials, e.g. of workload identity, are used without keyFile
+	// +optional
+	KeyFile *v1.SecretKeySelector `json:"keyFile,omitempty"`
+	// Link images through signed urls, for buckets which aren't publicly readable
+	// +optional
+	EnableSignedURLs bool `json:"enableSignedUrls,omitempty"`
+	// Expiration of signed urls, e.g. 7d
+	// +optional
+	SignedURLExpiration string `json:"signedUrlExpiration,omitempty"`
+}
+
+type GrafanaExternalImageStorageAzureBlob struct {
+	// +kubebuilder:validation:MinLength=1
+	AccountName string `json:"accountName"`
+	// +kubebuilder:validation:MinLength=1
+	ContainerName string `json:"containerName"`
+	// Key of the storage account
+	AccountKey v1.SecretKeySelector `json:"accountKey"`
 }
 
 type External struct {
@@ -117,7 +857,7 @@ type External struct {
 }
 
 // TLSConfig specifies options to use when communicating with the Grafana endpoint
-// +kubebuilder:validation:XValidation:rule="(has(self.insecureSkipVerify) && !(has(self.certSecretRef))) || (has(self.certSecretRef) && !(has(self.insecureSkipVerify)))", message="insecureSkipVerify and certSecretRef cannot be set at the same time"
+// +kubebuilder:validation:XValidation:rule="!(has(self.insecureSkipVerify) && has(self.certSecretRef))", message="insecureSkipVerify and certSecretRef cannot be set at the same time"
 type TLSConfig struct {
 	// Disable the CA check of the server
 	// +optional
@@ -125,6 +865,14 @@ type TLSConfig struct {
 	// Use a secret as a reference to give TLS Certificate information
 	// +optional
 	CertSecretRef *v1.SecretReference `json:"certSecretRef,omitempty"`
+	// Minimum TLS version of the connection, raises the operator wide --tls-min-version but never lowers it
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+	// TLS 1.2 cipher suites allowed for the connection by IANA name, overrides the operator wide --tls-cipher-suites.
+	// TLS 1.3 cipher suites are not configurable
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
 }
 
 type JsonnetConfig struct {
@@ -132,6 +880,7 @@ type JsonnetConfig struct {
 }
 
 // GrafanaClient contains the Grafana API client settings
+// +kubebuilder:validation:XValidation:rule="!has(self.adminUrlStrategy) || self.adminUrlStrategy != 'explicit' || has(self.adminUrlTemplate)",message="adminUrlStrategy explicit requires adminUrlTemplate"
 type GrafanaClient struct {
 	// Use Kubernetes Serviceaccount as authentication
 	// Requires configuring [auth.jwt] in the instance
@@ -148,8 +897,39 @@ type GrafanaClient struct {
 	// Custom HTTP headers to use when interacting with this Grafana.
 	// +optional
 	Headers map[string]string `json:"headers,omitempty"`
+	// DashboardAPI selects the API dashboards are applied with. provisioning applies them through the
+	// dashboard.grafana.app API of Grafana 12 and newer, so they show up as provisioned by the operator.
+	// Older instances keep using the legacy API
+	// +optional
+	// +kubebuilder:validation:Enum=legacy;provisioning
+	DashboardAPI DashboardAPI `json:"dashboardApi,omitempty"`
+	// AdminURLStrategy selects how status.adminUrl is derived from the ingress, route or HTTPRoute when preferIngress is set.
+	// hostname only uses hostnames of the spec, loadBalancerIP only the address of the load balancer or Gateway,
+	// explicit renders adminUrlTemplate. Without a strategy, the first hostname is used and the address is the fallback
+	// +optional
+	// +kubebuilder:validation:Enum=hostname;loadBalancerIP;explicit
+	AdminURLStrategy AdminURLStrategy `json:"adminUrlStrategy,omitempty"`
+	// AdminURLTemplate is the Go template of the admin url with the explicit strategy.
+	// It can refer to {{ .Scheme }}, {{ .Hostname }}, {{ .Address }} and {{ .Port }} of the ingress, route or HTTPRoute
+	// +optional
+	AdminURLTemplate string `json:"adminUrlTemplate,omitempty"`
 }
 
+type AdminURLStrategy string
+
+const (
+	AdminURLStrategyHostname       AdminURLStrategy = "hostname"
+	AdminURLStrategyLoadBalancerIP AdminURLStrategy = "loadBalancerIP"
+	AdminURLStrategyExplicit       AdminURLStrategy = "explicit"
+)
+
+type DashboardAPI string
+
+const (
+	DashboardAPILegacy       DashboardAPI = "legacy"
+	DashboardAPIProvisioning DashboardAPI = "provisioning"
+)
+
 // GrafanaPreferences holds Grafana preferences API settings
 type GrafanaPreferences struct {
 	HomeDashboardUID string `json:"homeDashboardUid,omitempty"`
@@ -172,6 +952,131 @@ type GrafanaStatus struct {
 	NotificationTemplates NamespacedResourceList `json:"notificationTemplates,omitempty"`
 	Version               string                 `json:"version,omitempty"`
 	Conditions            []metav1.Condition     `json:"conditions,omitempty"`
+	// grafana.ini settings applied by spec.preset as section.key=value
+	PresetSettings []string `json:"presetSettings,omitempty"`
+	// HTTPRoute reports whether the parents of the HTTPRoute of spec.httpRoute accepted it and resolved its references
+	HTTPRoute *GrafanaHTTPRouteStatus `json:"httpRoute,omitempty"`
+	// History lists the latest changes the operator rolled out to the deployment, oldest first
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	History []GrafanaHistoryEntry `json:"history,omitempty"`
+	// Startup is set while the instance is starting, resources targeting it are retried after a delay matching the phase
+	// +optional
+	Startup *GrafanaStartup `json:"startup,omitempty"`
+	// ImageRenderer reports the renderer of spec.imageRenderer
+	// +optional
+	ImageRenderer *GrafanaImageRendererStatus `json:"imageRenderer,omitempty"`
+	// StatusPage reports the organization of spec.statusPage
+	// +optional
+	StatusPage *GrafanaStatusPageStatus `json:"statusPage,omitempty"`
+}
+
+// GrafanaStatusPageStatus reports the organization the operator created for the status page
+type GrafanaStatusPageStatus struct {
+	// ID of the organization, organizations the operator didn't create are never managed as their members and
+	// dashboards are replaced
+	OrgID int64 `json:"orgId"`
+	// UIDs of the datasources referenced by the copied dashboards, they are provisioned into the organization as well
+	// +optional
+	Datasources []string `json:"datasources,omitempty"`
+}
+
+// Provisions reports whether the datasource is provisioned into the organization of the status page
+func (in *GrafanaStatusPageStatus) Provisions(uid string) bool {
+	return in != nil && uid != "" && slices.Contains(in.Datasources, uid)
+}
+
+// GrafanaImageRendererStatus reports where Grafana sends render requests and whether a renderer serves them
+type GrafanaImageRendererStatus struct {
+	// URL Grafana sends render requests to
+	URL string `json:"url"`
+	// ReadyReplicas of the renderer deployment, or of the Grafana pods with the renderer sidecar
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// Ready is true once at least one renderer is ready
+	Ready bool `json:"ready"`
+}
+
+type GrafanaStartupPhase string
+
+const (
+	// GrafanaStartupStarting means the health endpoint doesn't respond yet, e.g. while pods are scheduled,
+	// plugins are installed or database migrations run
+	GrafanaStartupStarting GrafanaStartupPhase = "Starting"
+	// GrafanaStartupDatabaseUnavailable means the health endpoint reports a failing database
+	GrafanaStartupDatabaseUnavailable GrafanaStartupPhase = "DatabaseUnavailable"
+)
+
+// Startups taking longer are considered failed, resources targeting the instance fall back to the usual backoff
+const maxStartupDuration = 10 * time.Minute
+
+// GrafanaStartup is the progress of a starting instance as reported by its health endpoint
+type GrafanaStartup struct {
+	// Phase of the startup
+	// +kubebuilder:validation:Enum=Starting;DatabaseUnavailable
+	Phase GrafanaStartupPhase `json:"phase"`
+	// Since the instance is starting
+	Since metav1.Time `json:"since"`
+}
+
+// RetryAfter returns the delay after which a starting instance is expected to have progressed,
+// 0 if the startup takes too long to be waited for
+func (in *GrafanaStartup) RetryAfter(now time.Time) time.Duration {
+	if in == nil || now.Sub(in.Since.Time) > maxStartupDuration {
+		return 0
+	}
+
+	switch in.Phase {
+	case GrafanaStartupStarting:
+		return 5 * time.Second
+	case GrafanaStartupDatabaseUnavailable:
+		return 30 * time.Second
+	default:
+		return 0
+	}
+}
+
+type GrafanaChangeKind string
+
+const (
+	GrafanaChangeImage   GrafanaChangeKind = "image"
+	GrafanaChangeConfig  GrafanaChangeKind = "config"
+	GrafanaChangePlugins GrafanaChangeKind = "plugins"
+)
+
+// GrafanaHistoryEntry is a change of the Grafana container rolled out to the deployment
+type GrafanaHistoryEntry struct {
+	// Time the change was applied to the deployment
+	Time metav1.Time `json:"time"`
+	// Kind of the change
+	// +kubebuilder:validation:Enum=image;config;plugins
+	Kind GrafanaChangeKind `json:"kind"`
+	// Value after the change: the image, the hash of grafana.ini or the hash of the installed plugins
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Value before the change, empty when the deployment was created
+	// +optional
+	Previous string `json:"previous,omitempty"`
+}
+
+// GrafanaHTTPRouteStatus holds the Accepted and ResolvedRefs conditions the parents set on the HTTPRoute.
+// The summaries are True once all parents set the condition to True on the current generation of the route,
+// False as soon as one parent set it to False and Unknown otherwise
+type GrafanaHTTPRouteStatus struct {
+	// Accepted summarizes the Accepted conditions of all parents
+	Accepted metav1.ConditionStatus `json:"accepted,omitempty"`
+	// ResolvedRefs summarizes the ResolvedRefs conditions of all parents
+	ResolvedRefs metav1.ConditionStatus `json:"resolvedRefs,omitempty"`
+	// Parents lists the conditions of each parent that reported on the route
+	// +optional
+	Parents []GrafanaHTTPRouteParentStatus `json:"parents,omitempty"`
+}
+
+type GrafanaHTTPRouteParentStatus struct {
+	// Parent as namespace/name, followed by /sectionName when the parent reference sets one
+	Parent string `json:"parent"`
+	// Accepted and ResolvedRefs conditions of the parent
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 func (in *GrafanaStatus) StatusList(cr client.Object) (*NamespacedResourceList, string, error) {
@@ -204,6 +1109,8 @@ func (in *GrafanaStatus) StatusList(cr client.Object) (*NamespacedResourceList,
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",description=""
 // +kubebuilder:printcolumn:name="Stage",type="string",JSONPath=".status.stage",description=""
 // +kubebuilder:printcolumn:name="Stage status",type="string",JSONPath=".status.stageStatus",description=""
+// +kubebuilder:printcolumn:name="Route accepted",type="string",JSONPath=".status.httpRoute.accepted",description=""
+// +kubebuilder:printcolumn:name="Route refs resolved",type="string",JSONPath=".status.httpRoute.resolvedRefs",description=""
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 // +kubebuilder:resource:categories={grafana-operator}
 type Grafana struct {
@@ -248,6 +1155,36 @@ func (in *Grafana) PreferIngress() bool {
 	return in.Spec.Client != nil && in.Spec.Client.PreferIngress != nil && *in.Spec.Client.PreferIngress
 }
 
+// UsesDashboardProvisioningAPI reports whether dashboards are applied through the provisioning API,
+// which requires the provisioning dashboard API and a detected Grafana version of 12 or newer
+func (in *Grafana) UsesDashboardProvisioningAPI() bool {
+	if in.Spec.Client == nil || in.Spec.Client.DashboardAPI != DashboardAPIProvisioning {
+		return false
+	}
+
+	version, err := semver.ParseTolerant(in.Status.Version)
+	if err != nil {
+		return false
+	}
+
+	return version.Major >= 12
+}
+
+// IsReadonly reports whether the instance serves the content of a primary instance sharing its database
+func (in *Grafana) IsReadonly() bool {
+	return in.Spec.Role == GrafanaRoleReadonly
+}
+
+// UsesServingCert reports whether Grafana serves https with a certificate of the OpenShift service CA
+func (in *Grafana) UsesServingCert() bool {
+	return in.Spec.Route != nil && in.Spec.Route.ServingCert
+}
+
+// IsStatefulSet reports whether the instance runs in a StatefulSet instead of a Deployment
+func (in *Grafana) IsStatefulSet() bool {
+	return in.Spec.Deployment != nil && in.Spec.Deployment.Strategy == DeploymentStrategyStatefulSet
+}
+
 func (in *Grafana) IsInternal() bool {
 	return in.Spec.External == nil
 }
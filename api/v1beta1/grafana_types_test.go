@@ -3,10 +3,12 @@ package v1beta1
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -464,3 +466,116 @@ func TestGetConfigSectionValue(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func TestUsesDashboardProvisioningAPI(t *testing.T) {
+	tests := []struct {
+		name    string
+		api     DashboardAPI
+		version string
+		want    bool
+	}{
+		{name: "grafana 12", api: DashboardAPIProvisioning, version: "12.0.0", want: true},
+		{name: "grafana 12 security release", api: DashboardAPIProvisioning, version: "12.0.2+security-01", want: true},
+		{name: "grafana 11", api: DashboardAPIProvisioning, version: "11.6.1", want: false},
+		{name: "version not detected yet", api: DashboardAPIProvisioning, version: "", want: false},
+		{name: "legacy api", api: DashboardAPILegacy, version: "12.0.0", want: false},
+		{name: "default", version: "12.0.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := Grafana{
+				Spec:   GrafanaSpec{Client: &GrafanaClient{DashboardAPI: tt.api}},
+				Status: GrafanaStatus{Version: tt.version},
+			}
+
+			assert.Equal(t, tt.want, cr.UsesDashboardProvisioningAPI())
+		})
+	}
+}
+
+func TestChangeWindowUntil(t *testing.T) {
+	// Thursday
+	thursday := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	weekend := &ChangeWindow{
+		Days:     []string{"Saturday"},
+		Start:    "22:00",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}
+
+	tests := []struct {
+		name   string
+		window *ChangeWindow
+		now    time.Time
+		want   time.Duration
+	}{
+		{name: "no window", window: nil, now: thursday, want: 0},
+		{name: "before the window", window: weekend, now: thursday, want: 58 * time.Hour},
+		{name: "within the window", window: weekend, now: thursday.Add(59 * time.Hour), want: 0},
+		{name: "past midnight", window: weekend, now: thursday.Add(61*time.Hour + 30*time.Minute), want: 0},
+		{name: "after the window", window: weekend, now: thursday.Add(62 * time.Hour), want: 164 * time.Hour},
+		{
+			name:   "time zone",
+			window: &ChangeWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Berlin"},
+			// 02:30 in Berlin
+			now:  time.Date(2026, 10, 15, 0, 30, 0, 0, time.UTC),
+			want: 0,
+		},
+		{
+			name:   "every day",
+			window: &ChangeWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			now:    thursday,
+			want:   14 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.Until(tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		window := &ChangeWindow{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"}
+
+		_, err := window.Until(thursday)
+		require.Error(t, err)
+	})
+}
+
+func TestGrafanaStartupRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		startup *GrafanaStartup
+		want    time.Duration
+	}{
+		{
+			name: "not starting",
+		},
+		{
+			name:    "starting",
+			startup: &GrafanaStartup{Phase: GrafanaStartupStarting, Since: metav1.NewTime(now.Add(-time.Minute))},
+			want:    5 * time.Second,
+		},
+		{
+			name:    "database unavailable",
+			startup: &GrafanaStartup{Phase: GrafanaStartupDatabaseUnavailable, Since: metav1.NewTime(now.Add(-time.Minute))},
+			want:    30 * time.Second,
+		},
+		{
+			name:    "starting for too long",
+			startup: &GrafanaStartup{Phase: GrafanaStartupStarting, Since: metav1.NewTime(now.Add(-time.Hour))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.startup.RetryAfter(now))
+		})
+	}
+}
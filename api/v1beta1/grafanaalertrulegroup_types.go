@@ -28,6 +28,8 @@ import (
 // +kubebuilder:validation:XValidation:rule="((!has(oldSelf.editable) && !has(self.editable)) || (has(oldSelf.editable) && has(self.editable)))", message="spec.editable is immutable"
 // +kubebuilder:validation:XValidation:rule="((!has(oldSelf.folderUID) && !has(self.folderUID)) || (has(oldSelf.folderUID) && has(self.folderUID)))", message="spec.folderUID is immutable"
 // +kubebuilder:validation:XValidation:rule="((!has(oldSelf.folderRef) && !has(self.folderRef)) || (has(oldSelf.folderRef) && has(self.folderRef)))", message="spec.folderRef is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(self.interval) || duration(self.interval) == duration('0s') || (duration(self.interval) >= duration('1s') && duration(self.interval).getMilliseconds() % 1000 == 0)", message="spec.interval must be a whole number of seconds"
+// +kubebuilder:validation:XValidation:rule="!has(self.interval) || duration(self.interval) < duration('1s') || self.rules.all(r, !has(r.__for__) || duration(r.__for__).getMilliseconds() % duration(self.interval).getMilliseconds() == 0)", message="rule for durations must be a multiple of spec.interval"
 type GrafanaAlertRuleGroupSpec struct {
 	GrafanaCommonSpec `json:",inline"`
 
@@ -47,16 +49,38 @@ type GrafanaAlertRuleGroupSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	Rules []AlertRule `json:"rules"`
 
+	// Evaluation interval of the group, defaults to the operator's --default-alert-rule-group-interval (1m)
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Format=duration
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"
-	// +kubebuilder:validation:Required
-	Interval metav1.Duration `json:"interval"`
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
 
 	// Whether to enable or disable editing of the alert rule group in Grafana UI
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	// +optional
 	Editable *bool `json:"editable,omitempty"`
+
+	// Pause the evaluation of all rules in the group, overriding isPaused of the individual rules
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Only provision the rule group within the given time span, it is removed from the instances outside of it
+	// +optional
+	ActiveWindow *ActiveWindow `json:"activeWindow,omitempty"`
+
+	// Export the rules as a Prometheus rule file, e.g. for backups or to mirror them to a Mimir ruler
+	// +optional
+	PrometheusExport *AlertRuleGroupPrometheusExport `json:"prometheusExport,omitempty"`
+}
+
+// AlertRuleGroupPrometheusExport configures the Prometheus rule file of the group, stored under the rules.yaml key of the
+// <name>-prometheus-rules ConfigMap in the namespace of the GrafanaAlertRuleGroup.
+// Only rules evaluating a single PromQL query, optionally reduced to its last value and compared to a threshold, are exported
+type AlertRuleGroupPrometheusExport struct {
+	// Labels added to the ConfigMap, e.g. to be picked up by a sidecar loading rule files
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // AlertRule defines a specific rule to be evaluated. It is based on the upstream model with some k8s specific type mappings
@@ -210,6 +234,10 @@ func (in *GrafanaAlertRuleGroup) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaAlertRuleGroup) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaAlertRuleGroup) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status
 }
@@ -112,6 +112,10 @@ func (in *GrafanaContactPoint) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaContactPoint) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaContactPoint) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status
 }
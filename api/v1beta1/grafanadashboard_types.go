@@ -48,6 +48,54 @@ type GrafanaDashboardSpec struct {
 	// plugins
 	// +optional
 	Plugins PluginList `json:"plugins,omitempty"`
+
+	// Propagate ownership metadata of the resource to the dashboard
+	// +optional
+	Ownership *GrafanaDashboardOwnership `json:"ownership,omitempty"`
+
+	// Only provision the dashboard within the given time span, it is removed from the instances outside of it
+	// +optional
+	ActiveWindow *ActiveWindow `json:"activeWindow,omitempty"`
+
+	// Run the queries of the panels through the datasources of every instance before applying changes to it
+	// +optional
+	QueryValidation *DashboardQueryValidation `json:"queryValidation,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Warn;Block
+type QueryValidationPolicy string
+
+const (
+	// QueryValidationPolicyWarn applies the dashboard and reports failing queries through the QueriesValid condition
+	QueryValidationPolicyWarn QueryValidationPolicy = "Warn"
+	// QueryValidationPolicyBlock additionally keeps the dashboard from instances on which queries fail
+	QueryValidationPolicyBlock QueryValidationPolicy = "Block"
+)
+
+// DashboardQueryValidation configures the validation of panel queries against the datasources of the target instances.
+// Queries of library panels, hidden queries and queries referencing dashboard variables other than datasource variables are skipped
+type DashboardQueryValidation struct {
+	// Warn only reports failing queries, Block does not apply the dashboard to instances on which queries fail
+	// +kubebuilder:default=Warn
+	// +optional
+	Policy QueryValidationPolicy `json:"policy,omitempty"`
+
+	// Time range ending now the queries are run for, short ranges keep the load on the datasources low
+	// +kubebuilder:default="5m"
+	// +optional
+	Range metav1.Duration `json:"range,omitempty"`
+}
+
+// GrafanaDashboardOwnership configures how ownership metadata (operator.grafana.com/team and operator.grafana.com/system labels
+// or annotations) of the resource is reflected in Grafana
+type GrafanaDashboardOwnership struct {
+	// Add the owning team and system as `team:<name>` and `system:<name>` dashboard tags
+	// +optional
+	Tags bool `json:"tags,omitempty"`
+
+	// Use the owning team as folder title when no folder is set, instead of the namespace
+	// +optional
+	TeamFolder bool `json:"teamFolder,omitempty"`
 }
 
 // GrafanaDashboardStatus defines the observed state of GrafanaDashboard
@@ -57,6 +105,52 @@ type GrafanaDashboardStatus struct {
 
 	// The dashboard instanceSelector can't find matching grafana instances
 	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+
+	// Change staged for instances requiring approval
+	// +optional
+	PendingApproval *PendingApproval `json:"pendingApproval,omitempty"`
+
+	// Folder of spec.folderUID as found in the instances
+	// +optional
+	Folder *DashboardFolderStatus `json:"folder,omitempty"`
+
+	// Errors and warnings found validating the resolved model, the dashboard is not applied until the errors are fixed
+	// +optional
+	ModelErrors []DashboardModelError `json:"modelErrors,omitempty"`
+}
+
+// DashboardModelError is an invalid field of a dashboard model
+type DashboardModelError struct {
+	// Path of the field, e.g. panels[2].gridPos.w
+	Path string `json:"path"`
+	// Title of the panel the field belongs to
+	// +optional
+	Panel   string `json:"panel,omitempty"`
+	Message string `json:"message"`
+	// Warning is true for fields Grafana accepts anyway, e.g. overlapping panels, the dashboard is still applied
+	// +optional
+	Warning bool `json:"warning,omitempty"`
+}
+
+// DashboardFolderStatus is the existing folder a dashboard is placed in with spec.folderUID
+type DashboardFolderStatus struct {
+	UID string `json:"uid"`
+	// Title of the folder in the instances
+	Title string `json:"title"`
+	// Adopted is true when no GrafanaFolder resource manages the folder, the operator never modifies or deletes it
+	Adopted bool `json:"adopted"`
+}
+
+// PendingApproval is a change staged for instances labeled operator.grafana.com/requires-approval
+type PendingApproval struct {
+	// Hash of the staged model, the change is applied to an instance once it is listed in the
+	// operator.grafana.com/approved-hashes annotation of the Grafana
+	Hash string `json:"hash"`
+	// JSON merge patch from the dashboard in the first staged instance to the staged model, truncated to 4KiB
+	// +optional
+	Diff string `json:"diff,omitempty"`
+	// Instances the change is staged for
+	Instances []string `json:"instances"`
 }
 
 //+kubebuilder:object:root=true
@@ -149,6 +243,10 @@ func (in *GrafanaDashboard) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaDashboard) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaDashboard) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status.GrafanaCommonStatus
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrafanaDashboardSetSpec defines the desired state of GrafanaDashboardSet
+type GrafanaDashboardSetSpec struct {
+	GrafanaCommonSpec `json:",inline"`
+
+	// grafana.com dashboards to provision, a GrafanaDashboard is created for each item.
+	// Dashboards removed from the list are pruned
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=id
+	GrafanaCom []GrafanaComContentReference `json:"grafanaCom"`
+
+	// folder assignment for the dashboards
+	// +optional
+	FolderTitle string `json:"folder,omitempty"`
+
+	// UID of the target folder for the dashboards
+	// +optional
+	FolderUID string `json:"folderUID,omitempty"`
+
+	// Name of a `GrafanaFolder` resource in the same namespace
+	// +optional
+	FolderRef string `json:"folderRef,omitempty"`
+
+	// Cache duration for dashboards fetched from grafana.com
+	// +optional
+	ContentCacheDuration metav1.Duration `json:"contentCacheDuration,omitempty"`
+
+	// maps required data sources to existing ones
+	// +optional
+	Datasources []GrafanaContentDatasource `json:"datasources,omitempty"`
+
+	// Time dashboards removed from the list are kept before they are pruned, meanwhile they are listed in
+	// status.pendingPrunes. Pruned right away by default
+	// +optional
+	PruneGracePeriod metav1.Duration `json:"pruneGracePeriod,omitempty"`
+}
+
+// GrafanaDashboardSetItem is the state of a dashboard provisioned by a GrafanaDashboardSet
+type GrafanaDashboardSetItem struct {
+	// grafana.com dashboard id
+	ID int `json:"id"`
+
+	// Name of the GrafanaDashboard created for the item
+	Dashboard string `json:"dashboard"`
+
+	// Revision currently applied, the requested revision or the latest one fetched from grafana.com
+	// +optional
+	Revision *int `json:"revision,omitempty"`
+}
+
+// GrafanaDashboardSetPrune is a dashboard removed from the set, waiting for its grace period to elapse
+type GrafanaDashboardSetPrune struct {
+	// Name of the GrafanaDashboard to prune
+	Dashboard string `json:"dashboard"`
+
+	// Time the dashboard is pruned at
+	PruneAfter metav1.Time `json:"pruneAfter"`
+}
+
+// GrafanaDashboardSetStatus defines the observed state of GrafanaDashboardSet
+type GrafanaDashboardSetStatus struct {
+	GrafanaCommonStatus `json:",inline"`
+
+	// Dashboards provisioned for the set
+	// +optional
+	Items []GrafanaDashboardSetItem `json:"items,omitempty"`
+
+	// Dashboards removed from the list which are pruned once spec.pruneGracePeriod elapsed
+	// +optional
+	PendingPrunes []GrafanaDashboardSetPrune `json:"pendingPrunes,omitempty"`
+
+	// Dashboards deleted by the latest pruning
+	// +optional
+	LastPruned []string `json:"lastPruned,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GrafanaDashboardSet is the Schema for the grafanadashboardsets API
+// +kubebuilder:printcolumn:name="Last resync",type="date",format="date-time",JSONPath=".status.lastResync",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:resource:categories={grafana-operator}
+type GrafanaDashboardSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrafanaDashboardSetSpec   `json:"spec"`
+	Status GrafanaDashboardSetStatus `json:"status,omitempty"`
+}
+
+func (in *GrafanaDashboardSet) CommonStatus() *GrafanaCommonStatus {
+	return &in.Status.GrafanaCommonStatus
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaDashboardSetList contains a list of GrafanaDashboardSet
+type GrafanaDashboardSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GrafanaDashboardSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GrafanaDashboardSet{}, &GrafanaDashboardSetList{})
+}
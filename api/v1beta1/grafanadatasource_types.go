@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -57,6 +58,28 @@ type GrafanaDatasourceInternal struct {
 	SecureJSONData json.RawMessage `json:"secureJsonData,omitempty"`
 }
 
+// GrafanaDatasourceTLS references a Secret holding TLS material for SQL datasources
+type GrafanaDatasourceTLS struct {
+	// Name of the Secret, must be in the same namespace as the datasource
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// Key of the CA certificate in the Secret
+	// +kubebuilder:default=ca.crt
+	// +optional
+	CACertKey string `json:"caCertKey,omitempty"`
+
+	// Key of the client certificate in the Secret
+	// +kubebuilder:default=tls.crt
+	// +optional
+	CertKey string `json:"certKey,omitempty"`
+
+	// Key of the client private key in the Secret
+	// +kubebuilder:default=tls.key
+	// +optional
+	KeyKey string `json:"keyKey,omitempty"`
+}
+
 // GrafanaDatasourceSpec defines the desired state of GrafanaDatasource
 // +kubebuilder:validation:XValidation:rule="((!has(oldSelf.uid) && !has(self.uid)) || (has(oldSelf.uid) && has(self.uid)))", message="spec.uid is immutable"
 type GrafanaDatasourceSpec struct {
@@ -80,6 +103,42 @@ type GrafanaDatasourceSpec struct {
 	// +optional
 	// +kubebuilder:validation:MaxItems=99
 	ValuesFrom []ValueFrom `json:"valuesFrom,omitempty"`
+
+	// TLS material for Postgres and MySQL datasources. For postgres, the Secret is mounted into
+	// Grafana instances in the same namespace and jsonData file paths are set accordingly,
+	// for mysql, the certificates are injected into secureJsonData
+	// +optional
+	TLS *GrafanaDatasourceTLS `json:"tls,omitempty"`
+
+	// Label based access control rules restricting the data teams may query through the datasource.
+	// Requires Grafana Enterprise or Cloud and a Loki, Prometheus or Tempo datasource. When set, the rules
+	// replace all rules of the datasource in Grafana, an empty list removes them. Unset leaves the rules untouched
+	// +optional
+	LBACRules *[]GrafanaDatasourceLBACRule `json:"lbacRules,omitempty"`
+
+	// Rewrites references to former UIDs of the datasource in the dashboards the operator applies to the same instances
+	// +optional
+	UIDMigration *GrafanaDatasourceUIDMigration `json:"uidMigration,omitempty"`
+}
+
+// GrafanaDatasourceUIDMigration maps former UIDs of a datasource to its current one
+type GrafanaDatasourceUIDMigration struct {
+	// UIDs the datasource was applied with before, e.g. when it was recreated with another uid.
+	// UIDs replaced by the operator itself are listed in status.previousUIDs and migrated as well
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	PreviousUIDs []string `json:"previousUIDs,omitempty"`
+}
+
+// GrafanaDatasourceLBACRule restricts the data a team may query to label selectors
+type GrafanaDatasourceLBACRule struct {
+	// Name of the team in Grafana
+	// +kubebuilder:validation:MinLength=1
+	Team string `json:"team"`
+
+	// Label selectors the queries of the team are restricted to, e.g. {namespace="team-a"}
+	// +kubebuilder:validation:MinItems=1
+	Rules []string `json:"rules"`
 }
 
 // GrafanaDatasourceStatus defines the observed state of GrafanaDatasource
@@ -92,6 +151,25 @@ type GrafanaDatasourceStatus struct {
 	// The datasource instanceSelector can't find matching grafana instances
 	NoMatchingInstances bool   `json:"NoMatchingInstances,omitempty"`
 	UID                 string `json:"uid,omitempty"`
+	// UIDs the datasource was applied with before its uid changed, most recent last
+	// +optional
+	PreviousUIDs []string `json:"previousUIDs,omitempty"`
+	// Dashboards referencing the datasource per instance, reported when the operator runs with --datasource-usage-interval
+	// +optional
+	Usage []GrafanaDatasourceUsage `json:"usage,omitempty"`
+}
+
+// GrafanaDatasourceUsage describes how a datasource is used in a Grafana instance
+type GrafanaDatasourceUsage struct {
+	// Grafana instance, <namespace>/<name>
+	Instance string `json:"instance"`
+	// Number of dashboards referencing the datasource by uid or name
+	DashboardCount int `json:"dashboardCount"`
+	// UIDs of the dashboards referencing the datasource, limited to the first 20
+	// +optional
+	Dashboards []string `json:"dashboards,omitempty"`
+	// Time the usage was last computed
+	LastUpdated metav1.Time `json:"lastUpdated"`
 }
 
 //+kubebuilder:object:root=true
@@ -147,6 +225,44 @@ func (in *GrafanaDatasource) CustomUIDOrUID() string {
 	return string(in.UID)
 }
 
+// MigratedUIDs returns the former UIDs whose references are rewritten to the current uid, nil without spec.uidMigration
+func (in *GrafanaDatasource) MigratedUIDs() []string {
+	if in.Spec.UIDMigration == nil {
+		return nil
+	}
+
+	current := in.CustomUIDOrUID()
+
+	uids := make([]string, 0, len(in.Spec.UIDMigration.PreviousUIDs)+len(in.Status.PreviousUIDs))
+
+	for _, uid := range slices.Concat(in.Spec.UIDMigration.PreviousUIDs, in.Status.PreviousUIDs) {
+		if uid != "" && uid != current && !slices.Contains(uids, uid) {
+			uids = append(uids, uid)
+		}
+	}
+
+	return uids
+}
+
+// TLSSecretKeys returns the Secret keys of the CA certificate, client certificate and client key, falling back to defaults
+func (in *GrafanaDatasourceTLS) TLSSecretKeys() (caCert, cert, key string) {
+	caCert, cert, key = "ca.crt", "tls.crt", "tls.key"
+
+	if in.CACertKey != "" {
+		caCert = in.CACertKey
+	}
+
+	if in.CertKey != "" {
+		cert = in.CertKey
+	}
+
+	if in.KeyKey != "" {
+		key = in.KeyKey
+	}
+
+	return caCert, cert, key
+}
+
 func (in *GrafanaDatasourceList) Exists(namespace, name string) bool {
 	for _, item := range in.Items {
 		if item.Namespace == namespace && item.Name == name {
@@ -173,6 +289,10 @@ func (in *GrafanaDatasource) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaDatasource) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaDatasource) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status.GrafanaCommonStatus
 }
@@ -2,6 +2,7 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -19,6 +20,30 @@ func TestGrafanaStatusListDatasource(t *testing.T) {
 	})
 }
 
+func TestMigratedUIDs(t *testing.T) {
+	ds := newDatasource("prometheus", "prometheus")
+	ds.Status.PreviousUIDs = []string{"prom-old", "prometheus"}
+
+	assert.Nil(t, ds.MigratedUIDs(), "references are only migrated with spec.uidMigration")
+
+	ds.Spec.UIDMigration = &GrafanaDatasourceUIDMigration{PreviousUIDs: []string{"prom-legacy", "prom-old"}}
+	assert.Equal(t, []string{"prom-legacy", "prom-old"}, ds.MigratedUIDs())
+}
+
+func TestLBACRulesKeepEmptyList(t *testing.T) {
+	var unset, empty GrafanaDatasourceSpec
+
+	assert.NoError(t, json.Unmarshal([]byte(`{}`), &unset))
+	assert.Nil(t, unset.LBACRules)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"lbacRules": []}`), &empty))
+	assert.NotNil(t, empty.LBACRules, "an empty list removes the rules and must not be dropped")
+
+	encoded, err := json.Marshal(empty)
+	assert.NoError(t, err)
+	assert.Contains(t, string(encoded), `"lbacRules":[]`)
+}
+
 func newDatasource(name string, uid string) *GrafanaDatasource {
 	return &GrafanaDatasource{
 		TypeMeta: v1.TypeMeta{
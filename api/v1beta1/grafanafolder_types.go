@@ -55,6 +55,11 @@ type GrafanaFolderSpec struct {
 	// Reference to an existing GrafanaFolder CR in the same namespace
 	// +optional
 	ParentFolderRef string `json:"parentFolderRef,omitempty"`
+
+	// Protect dashboards in the folder that are not managed by the operator.
+	// Unmanaged dashboards are reported in the UnmanagedContent condition and the folder is not deleted from Grafana while they exist
+	// +optional
+	Protected bool `json:"protected,omitempty"`
 }
 
 // GrafanaFolderStatus defines the observed state of GrafanaFolder
@@ -179,6 +184,10 @@ func (in *GrafanaFolder) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaFolder) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaFolder) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status.GrafanaCommonStatus
 }
@@ -35,6 +35,45 @@ type GrafanaNotificationPolicySpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	// +optional
 	Editable *bool `json:"editable,omitempty"`
+
+	// Example alerts routed through the assembled policy tree, the resulting receivers are reported in status.routingPreview
+	// +kubebuilder:validation:MaxItems=50
+	// +optional
+	RoutingPreview []RoutingPreviewAlert `json:"routingPreview,omitempty"`
+
+	// Snapshot the policy tree of each instance into a ConfigMap before replacing it
+	// +optional
+	Backup *NotificationPolicyBackup `json:"backup,omitempty"`
+}
+
+// NotificationPolicyBackup configures revisions of the replaced policy trees, stored in the
+// <name>-<instance namespace>-<instance>-backup ConfigMap in the namespace of the GrafanaNotificationPolicy.
+// Backups are kept when the GrafanaNotificationPolicy is deleted
+type NotificationPolicyBackup struct {
+	// Number of revisions to keep
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	// +kubebuilder:default=5
+	// +optional
+	Revisions int `json:"revisions,omitempty"`
+}
+
+// RoutingPreviewAlert describes an example alert for the routing preview
+type RoutingPreviewAlert struct {
+	// Labels of the example alert
+	Labels map[string]string `json:"labels"`
+}
+
+// RoutingPreviewResult reports where an example alert would be delivered
+type RoutingPreviewResult struct {
+	// Labels of the example alert
+	Labels map[string]string `json:"labels"`
+
+	// Contact points receiving the alert, in routing order
+	Receivers []string `json:"receivers,omitempty"`
+
+	// Error while matching the alert, e.g. an invalid regular expression in the policy tree
+	Error string `json:"error,omitempty"`
 }
 
 type Route struct {
@@ -179,6 +218,9 @@ type GrafanaNotificationPolicyStatus struct {
 	GrafanaCommonStatus `json:",inline"`
 
 	DiscoveredRoutes *[]string `json:"discoveredRoutes,omitempty"`
+
+	// Receivers of the example alerts in spec.routingPreview
+	RoutingPreview []RoutingPreviewResult `json:"routingPreview,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -227,6 +269,10 @@ func (in *GrafanaNotificationPolicy) AllowCrossNamespace() bool {
 	return in.Spec.AllowCrossNamespaceImport
 }
 
+func (in *GrafanaNotificationPolicy) CommonSpec() *GrafanaCommonSpec {
+	return &in.Spec.GrafanaCommonSpec
+}
+
 func (in *GrafanaNotificationPolicy) CommonStatus() *GrafanaCommonStatus {
 	return &in.Status.GrafanaCommonStatus
 }
@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrafanaOperatorConfigSpec defines operator wide policies
+type GrafanaOperatorConfigSpec struct {
+	// Restricts the folders the GrafanaAlertRuleGroups of a namespace may write to.
+	// Namespaces not matched by any policy are not restricted
+	// +optional
+	AlertRuleGroupFolders []NamespaceFolderPolicy `json:"alertRuleGroupFolders,omitempty"`
+
+	// Gives tenant namespaces a dedicated folder in every Grafana instance and forces their dashboards into it
+	// +optional
+	TenantFolders *TenantFolders `json:"tenantFolders,omitempty"`
+
+	// Log verbosity of the operator and its controllers, changes apply without restarting the operator
+	// +optional
+	Logging *OperatorLogging `json:"logging,omitempty"`
+}
+
+// LogLevel is the verbosity of a logger, debug includes the messages of info and info the ones of error
+// +kubebuilder:validation:Enum=error;info;debug
+type LogLevel string
+
+const (
+	LogLevelError LogLevel = "error"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelDebug LogLevel = "debug"
+)
+
+// OperatorLogging overrides the --zap-log-level flag of the operator, across GrafanaOperatorConfigs the most verbose level applies
+type OperatorLogging struct {
+	// Verbosity of the operator and all controllers without their own level
+	// +optional
+	Level LogLevel `json:"level,omitempty"`
+
+	// Verbosity of single controllers by the kind they reconcile, e.g. GrafanaDashboard
+	// +optional
+	Controllers map[string]LogLevel `json:"controllers,omitempty"`
+}
+
+// TenantFolders maps tenant namespaces to dedicated Grafana folders
+type TenantFolders struct {
+	// Tenant namespaces, * matches all namespaces
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+
+	// Also create a team per tenant namespace and grant it edit permission on the folder in place of the default folder permissions
+	// +optional
+	Teams bool `json:"teams,omitempty"`
+
+	// Grant edit permission to teams named after the namespace that exist before the folder is created, by default
+	// the folder is only created once the operator can create the team itself
+	// +optional
+	AdoptExistingTeams bool `json:"adoptExistingTeams,omitempty"`
+}
+
+// Matches reports whether namespace is a tenant namespace
+func (in TenantFolders) Matches(namespace string) bool {
+	return matchesNamespace(in.Namespaces, namespace)
+}
+
+// NamespaceFolderPolicy allows the resources of namespaces to write to a set of folders
+type NamespaceFolderPolicy struct {
+	// Namespaces the policy applies to, * matches all namespaces
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+
+	// UIDs of the folders the namespaces may write to
+	// +optional
+	FolderUIDs []string `json:"folderUIDs,omitempty"`
+
+	// Allow the folders of GrafanaFolders in the same namespace as the resource
+	// +optional
+	NamespaceFolders bool `json:"namespaceFolders,omitempty"`
+}
+
+// Matches reports whether the policy applies to namespace
+func (in NamespaceFolderPolicy) Matches(namespace string) bool {
+	return matchesNamespace(in.Namespaces, namespace)
+}
+
+func matchesNamespace(namespaces []string, namespace string) bool {
+	return slices.Contains(namespaces, "*") || slices.Contains(namespaces, namespace)
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaOperatorConfig holds operator wide policies, the policies of all GrafanaOperatorConfigs apply
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:resource:scope=Cluster,categories={grafana-operator}
+type GrafanaOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GrafanaOperatorConfigSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaOperatorConfigList contains a list of GrafanaOperatorConfig
+type GrafanaOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GrafanaOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GrafanaOperatorConfig{}, &GrafanaOperatorConfigList{})
+}
@@ -0,0 +1,146 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleLookahead bounds the search for the next occurrence of a schedule, leap days recur within 8 years
+const maxScheduleLookahead = 8 * 366 * 24 * time.Hour
+
+// cronSchedule is a parsed standard cron expression, each field is a bit set of the allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// day of month and day of week match either when both are restricted, as in cron
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// parseCronSchedule parses the five fields (minute, hour, day of month, month, day of week) of a cron expression,
+// fields accept *, values, ranges, lists and steps
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("schedule %q must have %d fields, got %d", spec, len(cronFields), len(fields))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+
+		sets[i] = set
+	}
+
+	// 7 is an alias for Sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+
+	return &cronSchedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+
+	for item := range strings.SplitSeq(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepStr, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if expr != "*" {
+			lowStr, highStr, isRange := strings.Cut(expr, "-")
+
+			var err error
+			if low, err = strconv.Atoi(lowStr); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", lowStr, f.name)
+			}
+
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highStr); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", highStr, f.name)
+				}
+			} else if hasStep {
+				high = f.max
+			}
+		}
+
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%q is out of the range %d-%d of the %s field", item, f.min, f.max, f.name)
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<t.Weekday()) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// next returns the first occurrence of the schedule after t in the location of t, the zero time if there is none
+func (s *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(maxScheduleLookahead)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
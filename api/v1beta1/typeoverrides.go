@@ -54,8 +54,47 @@ func (override *ObjectMeta) Merge(meta metav1.ObjectMeta) metav1.ObjectMeta {
 type DeploymentV1 struct {
 	ObjectMeta ObjectMeta       `json:"metadata,omitempty"`
 	Spec       DeploymentV1Spec `json:"spec,omitempty"`
+	// Strategy selects the workload running Grafana. StatefulSet keeps the data volume in volumeClaimTemplates
+	// and replaces pods one after another, so a ReadWriteOnce volume is never attached to two pods at once
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +optional
+	Strategy DeploymentStrategy `json:"strategy,omitempty"`
+	// Sidecars are added to the containers of the pod in the order listed, after the containers of the operator
+	// and spec.template. Names must be unique within the pod
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+	// InitContainers are added to the init containers of the pod in the order listed, after those of spec.template.
+	// Names must be unique within the pod
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// SpreadPolicy spreads the pods with a preset, zone-balanced adds a topology spread constraint across zones and
+	// hostname-anti-affinity prefers nodes not running a pod of the instance yet. Constraints and anti-affinity set in
+	// spec.template take precedence
+	// +kubebuilder:validation:Enum=zone-balanced;hostname-anti-affinity
+	// +optional
+	SpreadPolicy SpreadPolicy `json:"spreadPolicy,omitempty"`
 }
 
+// DeploymentStrategy is the kind of workload rendered for an instance
+type DeploymentStrategy string
+
+const (
+	DeploymentStrategyDeployment  DeploymentStrategy = "Deployment"
+	DeploymentStrategyStatefulSet DeploymentStrategy = "StatefulSet"
+)
+
+// SpreadPolicy is a preset spreading the pods of an instance
+type SpreadPolicy string
+
+const (
+	SpreadPolicyZoneBalanced         SpreadPolicy = "zone-balanced"
+	SpreadPolicyHostnameAntiAffinity SpreadPolicy = "hostname-anti-affinity"
+)
+
 type DeploymentV1Spec struct {
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty" protobuf:"varint,1,opt,name=replicas"`
@@ -319,13 +358,75 @@ type DeploymentV1PodSpec struct {
 type IngressNetworkingV1 struct {
 	ObjectMeta ObjectMeta                `json:"metadata,omitempty"`
 	Spec       *networkingv1.IngressSpec `json:"spec,omitempty"`
+	// TLS generates the TLS section of the ingress for all of its hosts
+	// +optional
+	TLS *IngressTLS `json:"tls,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+type IngressTLS struct {
+	// CertManager requests the certificate of the ingress from cert-manager through ingress-shim annotations
+	// and waits for it to become ready
+	// +optional
+	CertManager *IngressCertManager `json:"certManager,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:XValidation:rule="has(self.issuer) != has(self.clusterIssuer)",message="Exactly one of issuer or clusterIssuer must be set"
+
+type IngressCertManager struct {
+	// Issuer in the namespace of the instance, cert-manager.io/issuer annotation
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// ClusterIssuer, cert-manager.io/cluster-issuer annotation
+	// +optional
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+	// SecretName of the certificate, a Go template with the {{ .Name }} and {{ .Namespace }} of the instance.
+	// Defaults to {{ .Name }}-ingress-tls
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
+// +kubebuilder:validation:XValidation:rule="!has(self.servingCert) || !self.servingCert || !has(self.tls) || !has(self.tls.termination) || self.tls.termination == 'reencrypt'",message="servingCert requires reencrypt termination"
 
 type RouteOpenshiftV1 struct {
 	ObjectMeta ObjectMeta            `json:"metadata,omitempty"`
 	Spec       *RouteOpenShiftV1Spec `json:"spec,omitempty"`
+	// TLS sets the termination of the route, spec.tls of the route takes precedence
+	// +optional
+	TLS *RouteTLS `json:"tls,omitempty"`
+	// ServingCert has the OpenShift service CA issue a certificate for the service of the instance, Grafana serves
+	// https with it and the route re-encrypts to it. The route is created even without spec. Requires OpenShift
+	// +optional
+	ServingCert bool `json:"servingCert,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:XValidation:rule="!has(self.destinationCA) || self.termination == 'reencrypt'",message="destinationCA requires reencrypt termination"
+
+type RouteTLS struct {
+	// Termination of TLS connections. Defaults to passthrough when Grafana serves https itself, edge otherwise.
+	// reencrypt requires Grafana to serve https
+	// +optional
+	// +kubebuilder:validation:Enum=edge;reencrypt;passthrough
+	Termination TLSTerminationType `json:"termination,omitempty"`
+	// DestinationCA is the CA certificate the router verifies the certificate of Grafana with on reencrypt termination
+	// +optional
+	DestinationCA *RouteDestinationCA `json:"destinationCA,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="Exactly one of configMapKeyRef or secretKeyRef must be set"
+
+type RouteDestinationCA struct {
+	// Key of a ConfigMap in the namespace of the instance holding the PEM encoded CA certificate
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Key of a Secret in the namespace of the instance holding the PEM encoded CA certificate
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -333,6 +434,61 @@ type RouteOpenshiftV1 struct {
 type HTTPRouteV1 struct {
 	ObjectMeta ObjectMeta            `json:"metadata,omitempty"`
 	Spec       gwapiv1.HTTPRouteSpec `json:"spec,omitempty"`
+
+	// Hostnames added to the hostnames of spec
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Hostnames []gwapiv1.Hostname `json:"hostnames,omitempty"`
+
+	// Rules routing to the Grafana service, added to the rules of spec.
+	// Rules restricted to hostnames are applied through an additional HTTPRoute per set of hostnames
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Rules []GrafanaHTTPRouteRule `json:"rules,omitempty"`
+
+	// Filters applied to every rule routing to the Grafana service, e.g. URLRewrite to serve Grafana from a sub-path.
+	// Filters of a rule take precedence over filters of the same type set here
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:XValidation:rule="self.filter(f, f.type == 'RequestHeaderModifier').size() <= 1",message="RequestHeaderModifier filter cannot be repeated"
+	// +kubebuilder:validation:XValidation:rule="self.filter(f, f.type == 'ResponseHeaderModifier').size() <= 1",message="ResponseHeaderModifier filter cannot be repeated"
+	// +kubebuilder:validation:XValidation:rule="self.filter(f, f.type == 'RequestRedirect').size() <= 1",message="RequestRedirect filter cannot be repeated"
+	// +kubebuilder:validation:XValidation:rule="self.filter(f, f.type == 'URLRewrite').size() <= 1",message="URLRewrite filter cannot be repeated"
+	// +kubebuilder:validation:XValidation:rule="!(self.exists(f, f.type == 'RequestRedirect') && self.exists(f, f.type == 'URLRewrite'))",message="May specify either RequestRedirect or URLRewrite, but not both"
+	// +optional
+	Filters []gwapiv1.HTTPRouteFilter `json:"filters,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+
+type GRPCRouteV1 struct {
+	ObjectMeta ObjectMeta            `json:"metadata,omitempty"`
+	Spec       gwapiv1.GRPCRouteSpec `json:"spec,omitempty"`
+
+	// Port of the Grafana service receiving the gRPC traffic, e.g. a port added through spec.service
+	// for the Grafana gRPC server. Defaults to the port of the Grafana HTTP server
+	// +optional
+	Port *gwapiv1.PortNumber `json:"port,omitempty"`
+}
+
+// GrafanaHTTPRouteRule is a rule of an HTTPRoute whose backend is the Grafana service
+type GrafanaHTTPRouteRule struct {
+	// Hostnames the rule is restricted to, all hostnames of the HTTPRoute when empty
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Hostnames []gwapiv1.Hostname `json:"hostnames,omitempty"`
+
+	// Conditions of requests matching the rule, all requests when empty
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	Matches []gwapiv1.HTTPRouteMatch `json:"matches,omitempty"`
+
+	// Filters applied to matching requests, e.g. URLRewrite or RequestHeaderModifier
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Filters []gwapiv1.HTTPRouteFilter `json:"filters,omitempty"`
+
+	// +optional
+	Timeouts *gwapiv1.HTTPRouteTimeouts `json:"timeouts,omitempty"`
 }
 
 // RouteTargetReference specifies the target that resolve into endpoints. Only the 'Service'
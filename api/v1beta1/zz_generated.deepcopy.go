@@ -24,13 +24,44 @@ import (
 	"encoding/json"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apisv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveWindow) DeepCopyInto(out *ActiveWindow) {
+	*out = *in
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveWindow.
+func (in *ActiveWindow) DeepCopy() *ActiveWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AlertQuery) DeepCopyInto(out *AlertQuery) {
 	*out = *in
@@ -126,11 +157,134 @@ func (in *AlertRule) DeepCopy() *AlertRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertRuleGroupPrometheusExport) DeepCopyInto(out *AlertRuleGroupPrometheusExport) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertRuleGroupPrometheusExport.
+func (in *AlertRuleGroupPrometheusExport) DeepCopy() *AlertRuleGroupPrometheusExport {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertRuleGroupPrometheusExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureBlobContentReference) DeepCopyInto(out *AzureBlobContentReference) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureBlobContentReference.
+func (in *AzureBlobContentReference) DeepCopy() *AzureBlobContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureBlobContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeWindow) DeepCopyInto(out *ChangeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeWindow.
+func (in *ChangeWindow) DeepCopy() *ChangeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardFolderStatus) DeepCopyInto(out *DashboardFolderStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardFolderStatus.
+func (in *DashboardFolderStatus) DeepCopy() *DashboardFolderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardFolderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardModelError) DeepCopyInto(out *DashboardModelError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardModelError.
+func (in *DashboardModelError) DeepCopy() *DashboardModelError {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardModelError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardQueryValidation) DeepCopyInto(out *DashboardQueryValidation) {
+	*out = *in
+	out.Range = in.Range
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardQueryValidation.
+func (in *DashboardQueryValidation) DeepCopy() *DashboardQueryValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardQueryValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentV1) DeepCopyInto(out *DeploymentV1) {
 	*out = *in
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentV1.
@@ -402,6 +556,73 @@ func (in *External) DeepCopy() *External {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSContentReference) DeepCopyInto(out *GCSContentReference) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSContentReference.
+func (in *GCSContentReference) DeepCopy() *GCSContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCRouteV1) DeepCopyInto(out *GRPCRouteV1) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(apisv1.PortNumber)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCRouteV1.
+func (in *GRPCRouteV1) DeepCopy() *GRPCRouteV1 {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCRouteV1)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepoContentReference) DeepCopyInto(out *GitRepoContentReference) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepoContentReference.
+func (in *GitRepoContentReference) DeepCopy() *GitRepoContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepoContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Grafana) DeepCopyInto(out *Grafana) {
 	*out = *in
@@ -505,6 +726,16 @@ func (in *GrafanaAlertRuleGroupSpec) DeepCopyInto(out *GrafanaAlertRuleGroupSpec
 		*out = new(bool)
 		**out = **in
 	}
+	if in.ActiveWindow != nil {
+		in, out := &in.ActiveWindow, &out.ActiveWindow
+		*out = new(ActiveWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrometheusExport != nil {
+		in, out := &in.PrometheusExport, &out.PrometheusExport
+		*out = new(AlertRuleGroupPrometheusExport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaAlertRuleGroupSpec.
@@ -518,127 +749,102 @@ func (in *GrafanaAlertRuleGroupSpec) DeepCopy() *GrafanaAlertRuleGroupSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaClient) DeepCopyInto(out *GrafanaClient) {
+func (in *GrafanaAlertScreenshots) DeepCopyInto(out *GrafanaAlertScreenshots) {
 	*out = *in
-	if in.TimeoutSeconds != nil {
-		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
-		*out = new(int)
-		**out = **in
-	}
-	if in.PreferIngress != nil {
-		in, out := &in.PreferIngress, &out.PreferIngress
+	if in.Capture != nil {
+		in, out := &in.Capture, &out.Capture
 		*out = new(bool)
 		**out = **in
 	}
-	if in.TLS != nil {
-		in, out := &in.TLS, &out.TLS
-		*out = new(TLSConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaClient.
-func (in *GrafanaClient) DeepCopy() *GrafanaClient {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaAlertScreenshots.
+func (in *GrafanaAlertScreenshots) DeepCopy() *GrafanaAlertScreenshots {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaClient)
+	out := new(GrafanaAlertScreenshots)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaComContentReference) DeepCopyInto(out *GrafanaComContentReference) {
+func (in *GrafanaAutoscaling) DeepCopyInto(out *GrafanaAutoscaling) {
 	*out = *in
-	if in.Revision != nil {
-		in, out := &in.Revision, &out.Revision
-		*out = new(int)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaComContentReference.
-func (in *GrafanaComContentReference) DeepCopy() *GrafanaComContentReference {
-	if in == nil {
-		return nil
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
 	}
-	out := new(GrafanaComContentReference)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaCommonSpec) DeepCopyInto(out *GrafanaCommonSpec) {
-	*out = *in
-	out.ResyncPeriod = in.ResyncPeriod
-	if in.InstanceSelector != nil {
-		in, out := &in.InstanceSelector, &out.InstanceSelector
-		*out = new(metav1.LabelSelector)
-		(*in).DeepCopyInto(*out)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]v2.MetricSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaCommonSpec.
-func (in *GrafanaCommonSpec) DeepCopy() *GrafanaCommonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaAutoscaling.
+func (in *GrafanaAutoscaling) DeepCopy() *GrafanaAutoscaling {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaCommonSpec)
+	out := new(GrafanaAutoscaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaCommonStatus) DeepCopyInto(out *GrafanaCommonStatus) {
+func (in *GrafanaBruteForceProtection) DeepCopyInto(out *GrafanaBruteForceProtection) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int)
+		**out = **in
 	}
-	in.LastResync.DeepCopyInto(&out.LastResync)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaCommonStatus.
-func (in *GrafanaCommonStatus) DeepCopy() *GrafanaCommonStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaBruteForceProtection.
+func (in *GrafanaBruteForceProtection) DeepCopy() *GrafanaBruteForceProtection {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaCommonStatus)
+	out := new(GrafanaBruteForceProtection)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaContactPoint) DeepCopyInto(out *GrafanaContactPoint) {
+func (in *GrafanaClass) DeepCopyInto(out *GrafanaClass) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContactPoint.
-func (in *GrafanaContactPoint) DeepCopy() *GrafanaContactPoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaClass.
+func (in *GrafanaClass) DeepCopy() *GrafanaClass {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaContactPoint)
+	out := new(GrafanaClass)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaContactPoint) DeepCopyObject() runtime.Object {
+func (in *GrafanaClass) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -646,31 +852,31 @@ func (in *GrafanaContactPoint) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaContactPointList) DeepCopyInto(out *GrafanaContactPointList) {
+func (in *GrafanaClassList) DeepCopyInto(out *GrafanaClassList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaContactPoint, len(*in))
+		*out = make([]GrafanaClass, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContactPointList.
-func (in *GrafanaContactPointList) DeepCopy() *GrafanaContactPointList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaClassList.
+func (in *GrafanaClassList) DeepCopy() *GrafanaClassList {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaContactPointList)
+	out := new(GrafanaClassList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaContactPointList) DeepCopyObject() runtime.Object {
+func (in *GrafanaClassList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -678,18 +884,199 @@ func (in *GrafanaContactPointList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaContactPointSpec) DeepCopyInto(out *GrafanaContactPointSpec) {
+func (in *GrafanaClient) DeepCopyInto(out *GrafanaClient) {
 	*out = *in
-	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
-	if in.Settings != nil {
-		in, out := &in.Settings, &out.Settings
-		*out = new(apiextensionsv1.JSON)
-		(*in).DeepCopyInto(*out)
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int)
+		**out = **in
 	}
-	if in.ValuesFrom != nil {
-		in, out := &in.ValuesFrom, &out.ValuesFrom
-		*out = make([]ValueFrom, len(*in))
-		for i := range *in {
+	if in.PreferIngress != nil {
+		in, out := &in.PreferIngress, &out.PreferIngress
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaClient.
+func (in *GrafanaClient) DeepCopy() *GrafanaClient {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaClient)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaComContentReference) DeepCopyInto(out *GrafanaComContentReference) {
+	*out = *in
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaComContentReference.
+func (in *GrafanaComContentReference) DeepCopy() *GrafanaComContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaComContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaComRevisionStatus) DeepCopyInto(out *GrafanaComRevisionStatus) {
+	*out = *in
+	in.LastChecked.DeepCopyInto(&out.LastChecked)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaComRevisionStatus.
+func (in *GrafanaComRevisionStatus) DeepCopy() *GrafanaComRevisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaComRevisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaCommonSpec) DeepCopyInto(out *GrafanaCommonSpec) {
+	*out = *in
+	out.ResyncPeriod = in.ResyncPeriod
+	if in.ResyncJitterPercent != nil {
+		in, out := &in.ResyncJitterPercent, &out.ResyncJitterPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.InstanceSelector != nil {
+		in, out := &in.InstanceSelector, &out.InstanceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaCommonSpec.
+func (in *GrafanaCommonSpec) DeepCopy() *GrafanaCommonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaCommonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaCommonStatus) DeepCopyInto(out *GrafanaCommonStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastResync.DeepCopyInto(&out.LastResync)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaCommonStatus.
+func (in *GrafanaCommonStatus) DeepCopy() *GrafanaCommonStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaCommonStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaContactPoint) DeepCopyInto(out *GrafanaContactPoint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContactPoint.
+func (in *GrafanaContactPoint) DeepCopy() *GrafanaContactPoint {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaContactPoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaContactPoint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaContactPointList) DeepCopyInto(out *GrafanaContactPointList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaContactPoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContactPointList.
+func (in *GrafanaContactPointList) DeepCopy() *GrafanaContactPointList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaContactPointList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaContactPointList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaContactPointSpec) DeepCopyInto(out *GrafanaContactPointSpec) {
+	*out = *in
+	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValueFrom, len(*in))
+		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
@@ -774,6 +1161,11 @@ func (in *GrafanaContentSpec) DeepCopyInto(out *GrafanaContentSpec) {
 		*out = new(GrafanaContentURLAuthorization)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.JsonnetLibSelector != nil {
+		in, out := &in.JsonnetLibSelector, &out.JsonnetLibSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.JsonnetProjectBuild != nil {
 		in, out := &in.JsonnetProjectBuild, &out.JsonnetProjectBuild
 		*out = new(JsonnetProjectBuild)
@@ -789,7 +1181,38 @@ func (in *GrafanaContentSpec) DeepCopyInto(out *GrafanaContentSpec) {
 		*out = new(GrafanaComContentReference)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GitRepo != nil {
+		in, out := &in.GitRepo, &out.GitRepo
+		*out = new(GitRepoContentReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3ContentReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSContentReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AzureBlob != nil {
+		in, out := &in.AzureBlob, &out.AzureBlob
+		*out = new(AzureBlobContentReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCIContentReference)
+		(*in).DeepCopyInto(*out)
+	}
 	out.ContentCacheDuration = in.ContentCacheDuration
+	out.StaleThreshold = in.StaleThreshold
+	if in.OnSourceDeletion != nil {
+		in, out := &in.OnSourceDeletion, &out.OnSourceDeletion
+		*out = new(SourceDeletion)
+		**out = **in
+	}
 	if in.Datasources != nil {
 		in, out := &in.Datasources, &out.Datasources
 		*out = make([]GrafanaContentDatasource, len(*in))
@@ -830,6 +1253,11 @@ func (in *GrafanaContentStatus) DeepCopyInto(out *GrafanaContentStatus) {
 		copy(*out, *in)
 	}
 	in.ContentTimestamp.DeepCopyInto(&out.ContentTimestamp)
+	if in.GrafanaComRevision != nil {
+		in, out := &in.GrafanaComRevision, &out.GrafanaComRevision
+		*out = new(GrafanaComRevisionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContentStatus.
@@ -850,6 +1278,23 @@ func (in *GrafanaContentURLAuthorization) DeepCopyInto(out *GrafanaContentURLAut
 		*out = new(GrafanaContentURLBasicAuth)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]GrafanaContentURLHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(GrafanaContentEnvFromSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContentURLAuthorization.
@@ -887,6 +1332,53 @@ func (in *GrafanaContentURLBasicAuth) DeepCopy() *GrafanaContentURLBasicAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaContentURLHeader) DeepCopyInto(out *GrafanaContentURLHeader) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(GrafanaContentEnvFromSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaContentURLHeader.
+func (in *GrafanaContentURLHeader) DeepCopy() *GrafanaContentURLHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaContentURLHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDNS) DeepCopyInto(out *GrafanaDNS) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDNS.
+func (in *GrafanaDNS) DeepCopy() *GrafanaDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaDashboard) DeepCopyInto(out *GrafanaDashboard) {
 	*out = *in
@@ -947,33 +1439,238 @@ func (in *GrafanaDashboardList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardSpec) DeepCopyInto(out *GrafanaDashboardSpec) {
+func (in *GrafanaDashboardOwnership) DeepCopyInto(out *GrafanaDashboardOwnership) {
 	*out = *in
-	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
-	in.GrafanaContentSpec.DeepCopyInto(&out.GrafanaContentSpec)
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make(PluginList, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSpec.
-func (in *GrafanaDashboardSpec) DeepCopy() *GrafanaDashboardSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardOwnership.
+func (in *GrafanaDashboardOwnership) DeepCopy() *GrafanaDashboardOwnership {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDashboardSpec)
+	out := new(GrafanaDashboardOwnership)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardStatus) DeepCopyInto(out *GrafanaDashboardStatus) {
+func (in *GrafanaDashboardSet) DeepCopyInto(out *GrafanaDashboardSet) {
 	*out = *in
-	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
-	in.GrafanaContentStatus.DeepCopyInto(&out.GrafanaContentStatus)
-}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSet.
+func (in *GrafanaDashboardSet) DeepCopy() *GrafanaDashboardSet {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDashboardSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSetItem) DeepCopyInto(out *GrafanaDashboardSetItem) {
+	*out = *in
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSetItem.
+func (in *GrafanaDashboardSetItem) DeepCopy() *GrafanaDashboardSetItem {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSetItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSetList) DeepCopyInto(out *GrafanaDashboardSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaDashboardSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSetList.
+func (in *GrafanaDashboardSetList) DeepCopy() *GrafanaDashboardSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDashboardSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSetPrune) DeepCopyInto(out *GrafanaDashboardSetPrune) {
+	*out = *in
+	in.PruneAfter.DeepCopyInto(&out.PruneAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSetPrune.
+func (in *GrafanaDashboardSetPrune) DeepCopy() *GrafanaDashboardSetPrune {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSetPrune)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSetSpec) DeepCopyInto(out *GrafanaDashboardSetSpec) {
+	*out = *in
+	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
+	if in.GrafanaCom != nil {
+		in, out := &in.GrafanaCom, &out.GrafanaCom
+		*out = make([]GrafanaComContentReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.ContentCacheDuration = in.ContentCacheDuration
+	if in.Datasources != nil {
+		in, out := &in.Datasources, &out.Datasources
+		*out = make([]GrafanaContentDatasource, len(*in))
+		copy(*out, *in)
+	}
+	out.PruneGracePeriod = in.PruneGracePeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSetSpec.
+func (in *GrafanaDashboardSetSpec) DeepCopy() *GrafanaDashboardSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSetStatus) DeepCopyInto(out *GrafanaDashboardSetStatus) {
+	*out = *in
+	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaDashboardSetItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingPrunes != nil {
+		in, out := &in.PendingPrunes, &out.PendingPrunes
+		*out = make([]GrafanaDashboardSetPrune, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastPruned != nil {
+		in, out := &in.LastPruned, &out.LastPruned
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSetStatus.
+func (in *GrafanaDashboardSetStatus) DeepCopy() *GrafanaDashboardSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardSpec) DeepCopyInto(out *GrafanaDashboardSpec) {
+	*out = *in
+	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
+	in.GrafanaContentSpec.DeepCopyInto(&out.GrafanaContentSpec)
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make(PluginList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ownership != nil {
+		in, out := &in.Ownership, &out.Ownership
+		*out = new(GrafanaDashboardOwnership)
+		**out = **in
+	}
+	if in.ActiveWindow != nil {
+		in, out := &in.ActiveWindow, &out.ActiveWindow
+		*out = new(ActiveWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryValidation != nil {
+		in, out := &in.QueryValidation, &out.QueryValidation
+		*out = new(DashboardQueryValidation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSpec.
+func (in *GrafanaDashboardSpec) DeepCopy() *GrafanaDashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardStatus) DeepCopyInto(out *GrafanaDashboardStatus) {
+	*out = *in
+	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	in.GrafanaContentStatus.DeepCopyInto(&out.GrafanaContentStatus)
+	if in.PendingApproval != nil {
+		in, out := &in.PendingApproval, &out.PendingApproval
+		*out = new(PendingApproval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Folder != nil {
+		in, out := &in.Folder, &out.Folder
+		*out = new(DashboardFolderStatus)
+		**out = **in
+	}
+	if in.ModelErrors != nil {
+		in, out := &in.ModelErrors, &out.ModelErrors
+		*out = make([]DashboardModelError, len(*in))
+		copy(*out, *in)
+	}
+}
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardStatus.
 func (in *GrafanaDashboardStatus) DeepCopy() *GrafanaDashboardStatus {
@@ -985,6 +1682,46 @@ func (in *GrafanaDashboardStatus) DeepCopy() *GrafanaDashboardStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatabase) DeepCopyInto(out *GrafanaDatabase) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ClusterRef != nil {
+		in, out := &in.ClusterRef, &out.ClusterRef
+		*out = new(GrafanaDatabaseClusterRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatabase.
+func (in *GrafanaDatabase) DeepCopy() *GrafanaDatabase {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatabase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatabaseClusterRef) DeepCopyInto(out *GrafanaDatabaseClusterRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatabaseClusterRef.
+func (in *GrafanaDatabaseClusterRef) DeepCopy() *GrafanaDatabaseClusterRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatabaseClusterRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaDatasource) DeepCopyInto(out *GrafanaDatasource) {
 	*out = *in
@@ -1052,88 +1789,308 @@ func (in *GrafanaDatasourceInternal) DeepCopy() *GrafanaDatasourceInternal {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceInternal)
+	out := new(GrafanaDatasourceInternal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceLBACRule) DeepCopyInto(out *GrafanaDatasourceLBACRule) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceLBACRule.
+func (in *GrafanaDatasourceLBACRule) DeepCopy() *GrafanaDatasourceLBACRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceLBACRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceList) DeepCopyInto(out *GrafanaDatasourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaDatasource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceList.
+func (in *GrafanaDatasourceList) DeepCopy() *GrafanaDatasourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDatasourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceSpec) DeepCopyInto(out *GrafanaDatasourceSpec) {
+	*out = *in
+	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
+	if in.Datasource != nil {
+		in, out := &in.Datasource, &out.Datasource
+		*out = new(GrafanaDatasourceInternal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make(PluginList, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValueFrom, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(GrafanaDatasourceTLS)
+		**out = **in
+	}
+	if in.LBACRules != nil {
+		in, out := &in.LBACRules, &out.LBACRules
+		*out = new([]GrafanaDatasourceLBACRule)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]GrafanaDatasourceLBACRule, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.UIDMigration != nil {
+		in, out := &in.UIDMigration, &out.UIDMigration
+		*out = new(GrafanaDatasourceUIDMigration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceSpec.
+func (in *GrafanaDatasourceSpec) DeepCopy() *GrafanaDatasourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceStatus) DeepCopyInto(out *GrafanaDatasourceStatus) {
+	*out = *in
+	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	if in.PreviousUIDs != nil {
+		in, out := &in.PreviousUIDs, &out.PreviousUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]GrafanaDatasourceUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceStatus.
+func (in *GrafanaDatasourceStatus) DeepCopy() *GrafanaDatasourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTLS) DeepCopyInto(out *GrafanaDatasourceTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTLS.
+func (in *GrafanaDatasourceTLS) DeepCopy() *GrafanaDatasourceTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceUIDMigration) DeepCopyInto(out *GrafanaDatasourceUIDMigration) {
+	*out = *in
+	if in.PreviousUIDs != nil {
+		in, out := &in.PreviousUIDs, &out.PreviousUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceUIDMigration.
+func (in *GrafanaDatasourceUIDMigration) DeepCopy() *GrafanaDatasourceUIDMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceUIDMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceUsage) DeepCopyInto(out *GrafanaDatasourceUsage) {
+	*out = *in
+	if in.Dashboards != nil {
+		in, out := &in.Dashboards, &out.Dashboards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceUsage.
+func (in *GrafanaDatasourceUsage) DeepCopy() *GrafanaDatasourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDriftDetection) DeepCopyInto(out *GrafanaDriftDetection) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDriftDetection.
+func (in *GrafanaDriftDetection) DeepCopy() *GrafanaDriftDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDriftDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaExternalImageStorage) DeepCopyInto(out *GrafanaExternalImageStorage) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(GrafanaExternalImageStorageS3)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GrafanaExternalImageStorageGCS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AzureBlob != nil {
+		in, out := &in.AzureBlob, &out.AzureBlob
+		*out = new(GrafanaExternalImageStorageAzureBlob)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaExternalImageStorage.
+func (in *GrafanaExternalImageStorage) DeepCopy() *GrafanaExternalImageStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaExternalImageStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceList) DeepCopyInto(out *GrafanaDatasourceList) {
+func (in *GrafanaExternalImageStorageAzureBlob) DeepCopyInto(out *GrafanaExternalImageStorageAzureBlob) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaDatasource, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.AccountKey.DeepCopyInto(&out.AccountKey)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceList.
-func (in *GrafanaDatasourceList) DeepCopy() *GrafanaDatasourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaExternalImageStorageAzureBlob.
+func (in *GrafanaExternalImageStorageAzureBlob) DeepCopy() *GrafanaExternalImageStorageAzureBlob {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceList)
+	out := new(GrafanaExternalImageStorageAzureBlob)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaDatasourceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceSpec) DeepCopyInto(out *GrafanaDatasourceSpec) {
+func (in *GrafanaExternalImageStorageGCS) DeepCopyInto(out *GrafanaExternalImageStorageGCS) {
 	*out = *in
-	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
-	if in.Datasource != nil {
-		in, out := &in.Datasource, &out.Datasource
-		*out = new(GrafanaDatasourceInternal)
+	if in.KeyFile != nil {
+		in, out := &in.KeyFile, &out.KeyFile
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make(PluginList, len(*in))
-		copy(*out, *in)
-	}
-	if in.ValuesFrom != nil {
-		in, out := &in.ValuesFrom, &out.ValuesFrom
-		*out = make([]ValueFrom, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceSpec.
-func (in *GrafanaDatasourceSpec) DeepCopy() *GrafanaDatasourceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaExternalImageStorageGCS.
+func (in *GrafanaExternalImageStorageGCS) DeepCopy() *GrafanaExternalImageStorageGCS {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceSpec)
+	out := new(GrafanaExternalImageStorageGCS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceStatus) DeepCopyInto(out *GrafanaDatasourceStatus) {
+func (in *GrafanaExternalImageStorageS3) DeepCopyInto(out *GrafanaExternalImageStorageS3) {
 	*out = *in
-	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	if in.AccessKey != nil {
+		in, out := &in.AccessKey, &out.AccessKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKey != nil {
+		in, out := &in.SecretKey, &out.SecretKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceStatus.
-func (in *GrafanaDatasourceStatus) DeepCopy() *GrafanaDatasourceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaExternalImageStorageS3.
+func (in *GrafanaExternalImageStorageS3) DeepCopy() *GrafanaExternalImageStorageS3 {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceStatus)
+	out := new(GrafanaExternalImageStorageS3)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1229,6 +2186,182 @@ func (in *GrafanaFolderStatus) DeepCopy() *GrafanaFolderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaHTTPRouteParentStatus) DeepCopyInto(out *GrafanaHTTPRouteParentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaHTTPRouteParentStatus.
+func (in *GrafanaHTTPRouteParentStatus) DeepCopy() *GrafanaHTTPRouteParentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaHTTPRouteParentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaHTTPRouteRule) DeepCopyInto(out *GrafanaHTTPRouteRule) {
+	*out = *in
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]apisv1.Hostname, len(*in))
+		copy(*out, *in)
+	}
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]apisv1.HTTPRouteMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]apisv1.HTTPRouteFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(apisv1.HTTPRouteTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaHTTPRouteRule.
+func (in *GrafanaHTTPRouteRule) DeepCopy() *GrafanaHTTPRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaHTTPRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaHTTPRouteStatus) DeepCopyInto(out *GrafanaHTTPRouteStatus) {
+	*out = *in
+	if in.Parents != nil {
+		in, out := &in.Parents, &out.Parents
+		*out = make([]GrafanaHTTPRouteParentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaHTTPRouteStatus.
+func (in *GrafanaHTTPRouteStatus) DeepCopy() *GrafanaHTTPRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaHTTPRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaHistoryEntry) DeepCopyInto(out *GrafanaHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaHistoryEntry.
+func (in *GrafanaHistoryEntry) DeepCopy() *GrafanaHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaImageRenderer) DeepCopyInto(out *GrafanaImageRenderer) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(GrafanaImageRendererAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaImageRenderer.
+func (in *GrafanaImageRenderer) DeepCopy() *GrafanaImageRenderer {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaImageRenderer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaImageRendererAutoscaling) DeepCopyInto(out *GrafanaImageRendererAutoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaImageRendererAutoscaling.
+func (in *GrafanaImageRendererAutoscaling) DeepCopy() *GrafanaImageRendererAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaImageRendererAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaImageRendererStatus) DeepCopyInto(out *GrafanaImageRendererStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaImageRendererStatus.
+func (in *GrafanaImageRendererStatus) DeepCopy() *GrafanaImageRendererStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaImageRendererStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaLibraryPanel) DeepCopyInto(out *GrafanaLibraryPanel) {
 	*out = *in
@@ -1305,60 +2438,160 @@ func (in *GrafanaLibraryPanelSpec) DeepCopy() *GrafanaLibraryPanelSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaLibraryPanelSpec)
+	out := new(GrafanaLibraryPanelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaLibraryPanelStatus) DeepCopyInto(out *GrafanaLibraryPanelStatus) {
+	*out = *in
+	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	in.GrafanaContentStatus.DeepCopyInto(&out.GrafanaContentStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanelStatus.
+func (in *GrafanaLibraryPanelStatus) DeepCopy() *GrafanaLibraryPanelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaLibraryPanelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaList) DeepCopyInto(out *GrafanaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Grafana, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaList.
+func (in *GrafanaList) DeepCopy() *GrafanaList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaMesh) DeepCopyInto(out *GrafanaMesh) {
+	*out = *in
+	if in.HoldApplicationUntilProxyStarts != nil {
+		in, out := &in.HoldApplicationUntilProxyStarts, &out.HoldApplicationUntilProxyStarts
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludeOutboundPorts != nil {
+		in, out := &in.ExcludeOutboundPorts, &out.ExcludeOutboundPorts
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaMesh.
+func (in *GrafanaMesh) DeepCopy() *GrafanaMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaMetrics) DeepCopyInto(out *GrafanaMetrics) {
+	*out = *in
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(GrafanaServiceMonitor)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaMetrics.
+func (in *GrafanaMetrics) DeepCopy() *GrafanaMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaMetrics)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaLibraryPanelStatus) DeepCopyInto(out *GrafanaLibraryPanelStatus) {
+func (in *GrafanaMonitorSecretOrConfigMap) DeepCopyInto(out *GrafanaMonitorSecretOrConfigMap) {
 	*out = *in
-	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
-	in.GrafanaContentStatus.DeepCopyInto(&out.GrafanaContentStatus)
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanelStatus.
-func (in *GrafanaLibraryPanelStatus) DeepCopy() *GrafanaLibraryPanelStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaMonitorSecretOrConfigMap.
+func (in *GrafanaMonitorSecretOrConfigMap) DeepCopy() *GrafanaMonitorSecretOrConfigMap {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaLibraryPanelStatus)
+	out := new(GrafanaMonitorSecretOrConfigMap)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaList) DeepCopyInto(out *GrafanaList) {
+func (in *GrafanaMonitorTLSConfig) DeepCopyInto(out *GrafanaMonitorTLSConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Grafana, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(GrafanaMonitorSecretOrConfigMap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(GrafanaMonitorSecretOrConfigMap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaList.
-func (in *GrafanaList) DeepCopy() *GrafanaList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaMonitorTLSConfig.
+func (in *GrafanaMonitorTLSConfig) DeepCopy() *GrafanaMonitorTLSConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaList)
+	out := new(GrafanaMonitorTLSConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaMuteTiming) DeepCopyInto(out *GrafanaMuteTiming) {
 	*out = *in
@@ -1445,6 +2678,40 @@ func (in *GrafanaMuteTimingSpec) DeepCopy() *GrafanaMuteTimingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaNetworkPolicy) DeepCopyInto(out *GrafanaNetworkPolicy) {
+	*out = *in
+	if in.IngressNamespaces != nil {
+		in, out := &in.IngressNamespaces, &out.IngressNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IngressFrom != nil {
+		in, out := &in.IngressFrom, &out.IngressFrom
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNetworkPolicy.
+func (in *GrafanaNetworkPolicy) DeepCopy() *GrafanaNetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaNetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaNotificationPolicy) DeepCopyInto(out *GrafanaNotificationPolicy) {
 	*out = *in
@@ -1593,6 +2860,18 @@ func (in *GrafanaNotificationPolicySpec) DeepCopyInto(out *GrafanaNotificationPo
 		*out = new(bool)
 		**out = **in
 	}
+	if in.RoutingPreview != nil {
+		in, out := &in.RoutingPreview, &out.RoutingPreview
+		*out = make([]RoutingPreviewAlert, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(NotificationPolicyBackup)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationPolicySpec.
@@ -1600,142 +2879,399 @@ func (in *GrafanaNotificationPolicySpec) DeepCopy() *GrafanaNotificationPolicySp
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaNotificationPolicySpec)
+	out := new(GrafanaNotificationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaNotificationPolicyStatus) DeepCopyInto(out *GrafanaNotificationPolicyStatus) {
+	*out = *in
+	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
+	if in.DiscoveredRoutes != nil {
+		in, out := &in.DiscoveredRoutes, &out.DiscoveredRoutes
+		*out = new([]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		}
+	}
+	if in.RoutingPreview != nil {
+		in, out := &in.RoutingPreview, &out.RoutingPreview
+		*out = make([]RoutingPreviewResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationPolicyStatus.
+func (in *GrafanaNotificationPolicyStatus) DeepCopy() *GrafanaNotificationPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaNotificationPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaNotificationTemplate) DeepCopyInto(out *GrafanaNotificationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplate.
+func (in *GrafanaNotificationTemplate) DeepCopy() *GrafanaNotificationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaNotificationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaNotificationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaNotificationTemplateList) DeepCopyInto(out *GrafanaNotificationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaNotificationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplateList.
+func (in *GrafanaNotificationTemplateList) DeepCopy() *GrafanaNotificationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaNotificationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaNotificationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaNotificationTemplateSpec) DeepCopyInto(out *GrafanaNotificationTemplateSpec) {
+	*out = *in
+	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
+	if in.Editable != nil {
+		in, out := &in.Editable, &out.Editable
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplateSpec.
+func (in *GrafanaNotificationTemplateSpec) DeepCopy() *GrafanaNotificationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaNotificationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaOperatorConfig) DeepCopyInto(out *GrafanaOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaOperatorConfig.
+func (in *GrafanaOperatorConfig) DeepCopy() *GrafanaOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaOperatorConfigList) DeepCopyInto(out *GrafanaOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaOperatorConfigList.
+func (in *GrafanaOperatorConfigList) DeepCopy() *GrafanaOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaOperatorConfigSpec) DeepCopyInto(out *GrafanaOperatorConfigSpec) {
+	*out = *in
+	if in.AlertRuleGroupFolders != nil {
+		in, out := &in.AlertRuleGroupFolders, &out.AlertRuleGroupFolders
+		*out = make([]NamespaceFolderPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TenantFolders != nil {
+		in, out := &in.TenantFolders, &out.TenantFolders
+		*out = new(TenantFolders)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(OperatorLogging)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaOperatorConfigSpec.
+func (in *GrafanaOperatorConfigSpec) DeepCopy() *GrafanaOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaPlugin) DeepCopyInto(out *GrafanaPlugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPlugin.
+func (in *GrafanaPlugin) DeepCopy() *GrafanaPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaPlugin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaNotificationPolicyStatus) DeepCopyInto(out *GrafanaNotificationPolicyStatus) {
+func (in *GrafanaPluginPolicy) DeepCopyInto(out *GrafanaPluginPolicy) {
 	*out = *in
-	in.GrafanaCommonStatus.DeepCopyInto(&out.GrafanaCommonStatus)
-	if in.DiscoveredRoutes != nil {
-		in, out := &in.DiscoveredRoutes, &out.DiscoveredRoutes
-		*out = new([]string)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make([]string, len(*in))
-			copy(*out, *in)
-		}
+	if in.UnsignedAllowList != nil {
+		in, out := &in.UnsignedAllowList, &out.UnsignedAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationPolicyStatus.
-func (in *GrafanaNotificationPolicyStatus) DeepCopy() *GrafanaNotificationPolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPluginPolicy.
+func (in *GrafanaPluginPolicy) DeepCopy() *GrafanaPluginPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaNotificationPolicyStatus)
+	out := new(GrafanaPluginPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaNotificationTemplate) DeepCopyInto(out *GrafanaNotificationTemplate) {
+func (in *GrafanaPodDisruptionBudget) DeepCopyInto(out *GrafanaPodDisruptionBudget) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplate.
-func (in *GrafanaNotificationTemplate) DeepCopy() *GrafanaNotificationTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPodDisruptionBudget.
+func (in *GrafanaPodDisruptionBudget) DeepCopy() *GrafanaPodDisruptionBudget {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaNotificationTemplate)
+	out := new(GrafanaPodDisruptionBudget)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaNotificationTemplate) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaNotificationTemplateList) DeepCopyInto(out *GrafanaNotificationTemplateList) {
+func (in *GrafanaPreferences) DeepCopyInto(out *GrafanaPreferences) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaNotificationTemplate, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplateList.
-func (in *GrafanaNotificationTemplateList) DeepCopy() *GrafanaNotificationTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPreferences.
+func (in *GrafanaPreferences) DeepCopy() *GrafanaPreferences {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaNotificationTemplateList)
+	out := new(GrafanaPreferences)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaNotificationTemplateList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaProbe) DeepCopyInto(out *GrafanaProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaProbe.
+func (in *GrafanaProbe) DeepCopy() *GrafanaProbe {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(GrafanaProbe)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaNotificationTemplateSpec) DeepCopyInto(out *GrafanaNotificationTemplateSpec) {
+func (in *GrafanaProbes) DeepCopyInto(out *GrafanaProbes) {
 	*out = *in
-	in.GrafanaCommonSpec.DeepCopyInto(&out.GrafanaCommonSpec)
-	if in.Editable != nil {
-		in, out := &in.Editable, &out.Editable
-		*out = new(bool)
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(GrafanaProbe)
+		**out = **in
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(GrafanaProbe)
+		**out = **in
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(GrafanaProbe)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaNotificationTemplateSpec.
-func (in *GrafanaNotificationTemplateSpec) DeepCopy() *GrafanaNotificationTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaProbes.
+func (in *GrafanaProbes) DeepCopy() *GrafanaProbes {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaNotificationTemplateSpec)
+	out := new(GrafanaProbes)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaPlugin) DeepCopyInto(out *GrafanaPlugin) {
+func (in *GrafanaRemoteCache) DeepCopyInto(out *GrafanaRemoteCache) {
 	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Live != nil {
+		in, out := &in.Live, &out.Live
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPlugin.
-func (in *GrafanaPlugin) DeepCopy() *GrafanaPlugin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaRemoteCache.
+func (in *GrafanaRemoteCache) DeepCopy() *GrafanaRemoteCache {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaPlugin)
+	out := new(GrafanaRemoteCache)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaPreferences) DeepCopyInto(out *GrafanaPreferences) {
+func (in *GrafanaSecurity) DeepCopyInto(out *GrafanaSecurity) {
 	*out = *in
+	if in.LoginRememberDays != nil {
+		in, out := &in.LoginRememberDays, &out.LoginRememberDays
+		*out = new(int)
+		**out = **in
+	}
+	if in.CookieSecure != nil {
+		in, out := &in.CookieSecure, &out.CookieSecure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisableGravatar != nil {
+		in, out := &in.DisableGravatar, &out.DisableGravatar
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ContentSecurityPolicy != nil {
+		in, out := &in.ContentSecurityPolicy, &out.ContentSecurityPolicy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BruteForceProtection != nil {
+		in, out := &in.BruteForceProtection, &out.BruteForceProtection
+		*out = new(GrafanaBruteForceProtection)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPreferences.
-func (in *GrafanaPreferences) DeepCopy() *GrafanaPreferences {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSecurity.
+func (in *GrafanaSecurity) DeepCopy() *GrafanaSecurity {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaPreferences)
+	out := new(GrafanaSecurity)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1923,6 +3459,33 @@ func (in *GrafanaServiceAccountTokenStatus) DeepCopy() *GrafanaServiceAccountTok
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaServiceMonitor) DeepCopyInto(out *GrafanaServiceMonitor) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(GrafanaMonitorTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaServiceMonitor.
+func (in *GrafanaServiceMonitor) DeepCopy() *GrafanaServiceMonitor {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaServiceMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 	*out = *in
@@ -1944,6 +3507,13 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.ConfigFrom != nil {
+		in, out := &in.ConfigFrom, &out.ConfigFrom
+		*out = make([]v1.ConfigMapKeySelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Ingress != nil {
 		in, out := &in.Ingress, &out.Ingress
 		*out = new(IngressNetworkingV1)
@@ -1959,6 +3529,11 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 		*out = new(HTTPRouteV1)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GRPCRoute != nil {
+		in, out := &in.GRPCRoute, &out.GRPCRoute
+		*out = new(GRPCRouteV1)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceV1)
@@ -1999,6 +3574,91 @@ func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
 		*out = new(GrafanaPreferences)
 		**out = **in
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(GrafanaDNS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(GrafanaSecurity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AlertScreenshots != nil {
+		in, out := &in.AlertScreenshots, &out.AlertScreenshots
+		*out = new(GrafanaAlertScreenshots)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalImageStorage != nil {
+		in, out := &in.ExternalImageStorage, &out.ExternalImageStorage
+		*out = new(GrafanaExternalImageStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChangeWindow != nil {
+		in, out := &in.ChangeWindow, &out.ChangeWindow
+		*out = new(ChangeWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mesh != nil {
+		in, out := &in.Mesh, &out.Mesh
+		*out = new(GrafanaMesh)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatusPage != nil {
+		in, out := &in.StatusPage, &out.StatusPage
+		*out = new(GrafanaStatusPage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(GrafanaPodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(GrafanaAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(GrafanaNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(GrafanaMetrics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRenderer != nil {
+		in, out := &in.ImageRenderer, &out.ImageRenderer
+		*out = new(GrafanaImageRenderer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = new(GrafanaPluginPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(GrafanaDatabase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(GrafanaProbes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteCache != nil {
+		in, out := &in.RemoteCache, &out.RemoteCache
+		*out = new(GrafanaRemoteCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DriftDetection != nil {
+		in, out := &in.DriftDetection, &out.DriftDetection
+		*out = new(GrafanaDriftDetection)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSpec.
@@ -2011,6 +3671,22 @@ func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaStartup) DeepCopyInto(out *GrafanaStartup) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStartup.
+func (in *GrafanaStartup) DeepCopy() *GrafanaStartup {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaStartup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
 	*out = *in
@@ -2044,53 +3720,159 @@ func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
 		*out = make(NamespacedResourceList, len(*in))
 		copy(*out, *in)
 	}
-	if in.LibraryPanels != nil {
-		in, out := &in.LibraryPanels, &out.LibraryPanels
-		*out = make(NamespacedResourceList, len(*in))
-		copy(*out, *in)
+	if in.LibraryPanels != nil {
+		in, out := &in.LibraryPanels, &out.LibraryPanels
+		*out = make(NamespacedResourceList, len(*in))
+		copy(*out, *in)
+	}
+	if in.MuteTimings != nil {
+		in, out := &in.MuteTimings, &out.MuteTimings
+		*out = make(NamespacedResourceList, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotificationTemplates != nil {
+		in, out := &in.NotificationTemplates, &out.NotificationTemplates
+		*out = make(NamespacedResourceList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PresetSettings != nil {
+		in, out := &in.PresetSettings, &out.PresetSettings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(GrafanaHTTPRouteStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]GrafanaHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(GrafanaStartup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRenderer != nil {
+		in, out := &in.ImageRenderer, &out.ImageRenderer
+		*out = new(GrafanaImageRendererStatus)
+		**out = **in
+	}
+	if in.StatusPage != nil {
+		in, out := &in.StatusPage, &out.StatusPage
+		*out = new(GrafanaStatusPageStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatus.
+func (in *GrafanaStatus) DeepCopy() *GrafanaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaStatusPage) DeepCopyInto(out *GrafanaStatusPage) {
+	*out = *in
+	if in.DashboardSelector != nil {
+		in, out := &in.DashboardSelector, &out.DashboardSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatusPage.
+func (in *GrafanaStatusPage) DeepCopy() *GrafanaStatusPage {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaStatusPage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaStatusPageStatus) DeepCopyInto(out *GrafanaStatusPageStatus) {
+	*out = *in
+	if in.Datasources != nil {
+		in, out := &in.Datasources, &out.Datasources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatusPageStatus.
+func (in *GrafanaStatusPageStatus) DeepCopy() *GrafanaStatusPageStatus {
+	if in == nil {
+		return nil
 	}
-	if in.MuteTimings != nil {
-		in, out := &in.MuteTimings, &out.MuteTimings
-		*out = make(NamespacedResourceList, len(*in))
+	out := new(GrafanaStatusPageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRouteV1) DeepCopyInto(out *HTTPRouteV1) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]apisv1.Hostname, len(*in))
 		copy(*out, *in)
 	}
-	if in.NotificationTemplates != nil {
-		in, out := &in.NotificationTemplates, &out.NotificationTemplates
-		*out = make(NamespacedResourceList, len(*in))
-		copy(*out, *in)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]GrafanaHTTPRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]apisv1.HTTPRouteFilter, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatus.
-func (in *GrafanaStatus) DeepCopy() *GrafanaStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteV1.
+func (in *HTTPRouteV1) DeepCopy() *HTTPRouteV1 {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaStatus)
+	out := new(HTTPRouteV1)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HTTPRouteV1) DeepCopyInto(out *HTTPRouteV1) {
+func (in *IngressCertManager) DeepCopyInto(out *IngressCertManager) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRouteV1.
-func (in *HTTPRouteV1) DeepCopy() *HTTPRouteV1 {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressCertManager.
+func (in *IngressCertManager) DeepCopy() *IngressCertManager {
 	if in == nil {
 		return nil
 	}
-	out := new(HTTPRouteV1)
+	out := new(IngressCertManager)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -2104,6 +3886,11 @@ func (in *IngressNetworkingV1) DeepCopyInto(out *IngressNetworkingV1) {
 		*out = new(networkingv1.IngressSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(IngressTLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressNetworkingV1.
@@ -2116,6 +3903,26 @@ func (in *IngressNetworkingV1) DeepCopy() *IngressNetworkingV1 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressTLS) DeepCopyInto(out *IngressTLS) {
+	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(IngressCertManager)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressTLS.
+func (in *IngressTLS) DeepCopy() *IngressTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JsonnetConfig) DeepCopyInto(out *JsonnetConfig) {
 	*out = *in
@@ -2221,6 +4028,31 @@ func (in Matchers) DeepCopy() Matchers {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceFolderPolicy) DeepCopyInto(out *NamespaceFolderPolicy) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FolderUIDs != nil {
+		in, out := &in.FolderUIDs, &out.FolderUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceFolderPolicy.
+func (in *NamespaceFolderPolicy) DeepCopy() *NamespaceFolderPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceFolderPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in NamespacedResourceList) DeepCopyInto(out *NamespacedResourceList) {
 	{
@@ -2240,6 +4072,21 @@ func (in NamespacedResourceList) DeepCopy() NamespacedResourceList {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationPolicyBackup) DeepCopyInto(out *NotificationPolicyBackup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationPolicyBackup.
+func (in *NotificationPolicyBackup) DeepCopy() *NotificationPolicyBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationPolicyBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NotificationSettings) DeepCopyInto(out *NotificationSettings) {
 	*out = *in
@@ -2265,6 +4112,26 @@ func (in *NotificationSettings) DeepCopy() *NotificationSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCIContentReference) DeepCopyInto(out *OCIContentReference) {
+	*out = *in
+	if in.PullSecretRef != nil {
+		in, out := &in.PullSecretRef, &out.PullSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCIContentReference.
+func (in *OCIContentReference) DeepCopy() *OCIContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(OCIContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectMeta) DeepCopyInto(out *ObjectMeta) {
 	*out = *in
@@ -2314,9 +4181,36 @@ func (in *OpenshiftTLSConfig) DeepCopy() *OpenshiftTLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorLogging) DeepCopyInto(out *OperatorLogging) {
+	*out = *in
+	if in.Controllers != nil {
+		in, out := &in.Controllers, &out.Controllers
+		*out = make(map[string]LogLevel, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorLogging.
+func (in *OperatorLogging) DeepCopy() *OperatorLogging {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorLogging)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorReconcileVars) DeepCopyInto(out *OperatorReconcileVars) {
 	*out = *in
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorReconcileVars.
@@ -2329,6 +4223,26 @@ func (in *OperatorReconcileVars) DeepCopy() *OperatorReconcileVars {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingApproval) DeepCopyInto(out *PendingApproval) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingApproval.
+func (in *PendingApproval) DeepCopy() *PendingApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimV1) DeepCopyInto(out *PersistentVolumeClaimV1) {
 	*out = *in
@@ -2530,6 +4444,31 @@ func (in *Route) DeepCopy() *Route {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteDestinationCA) DeepCopyInto(out *RouteDestinationCA) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteDestinationCA.
+func (in *RouteDestinationCA) DeepCopy() *RouteDestinationCA {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteDestinationCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouteOpenShiftV1Spec) DeepCopyInto(out *RouteOpenShiftV1Spec) {
 	*out = *in
@@ -2576,6 +4515,11 @@ func (in *RouteOpenshiftV1) DeepCopyInto(out *RouteOpenshiftV1) {
 		*out = new(RouteOpenShiftV1Spec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(RouteTLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteOpenshiftV1.
@@ -2604,6 +4548,26 @@ func (in *RoutePort) DeepCopy() *RoutePort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteTLS) DeepCopyInto(out *RouteTLS) {
+	*out = *in
+	if in.DestinationCA != nil {
+		in, out := &in.DestinationCA, &out.DestinationCA
+		*out = new(RouteDestinationCA)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteTLS.
+func (in *RouteTLS) DeepCopy() *RouteTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouteTargetReference) DeepCopyInto(out *RouteTargetReference) {
 	*out = *in
@@ -2624,6 +4588,75 @@ func (in *RouteTargetReference) DeepCopy() *RouteTargetReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingPreviewAlert) DeepCopyInto(out *RoutingPreviewAlert) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingPreviewAlert.
+func (in *RoutingPreviewAlert) DeepCopy() *RoutingPreviewAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingPreviewAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutingPreviewResult) DeepCopyInto(out *RoutingPreviewResult) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingPreviewResult.
+func (in *RoutingPreviewResult) DeepCopy() *RoutingPreviewResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutingPreviewResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ContentReference) DeepCopyInto(out *S3ContentReference) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ContentReference.
+func (in *S3ContentReference) DeepCopy() *S3ContentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ContentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountV1) DeepCopyInto(out *ServiceAccountV1) {
 	*out = *in
@@ -2676,6 +4709,22 @@ func (in *ServiceV1) DeepCopy() *ServiceV1 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceDeletion) DeepCopyInto(out *SourceDeletion) {
+	*out = *in
+	out.GracePeriod = in.GracePeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceDeletion.
+func (in *SourceDeletion) DeepCopy() *SourceDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
@@ -2684,6 +4733,11 @@ func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
@@ -2696,6 +4750,26 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantFolders) DeepCopyInto(out *TenantFolders) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantFolders.
+func (in *TenantFolders) DeepCopy() *TenantFolders {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantFolders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeInterval) DeepCopyInto(out *TimeInterval) {
 	*out = *in
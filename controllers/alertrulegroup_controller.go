@@ -20,18 +20,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 
 	"github.com/go-openapi/strfmt"
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/folders"
 	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
@@ -40,6 +49,7 @@ import (
 
 const (
 	conditionAlertGroupSynchronized = "AlertGroupSynchronized"
+	conditionReasonInvalidDuration  = "InvalidDuration"
 )
 
 // GrafanaAlertRuleGroupReconciler reconciles a GrafanaAlertRuleGroup object
@@ -70,6 +80,15 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 	if group.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(group, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, group)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, group); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaAlertRuleGroup: %w", err)
 			}
@@ -82,7 +101,7 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, group)
+	defer UpdateStatus(ctx, r.Client, group, group.DeepCopy())
 
 	if group.Spec.Suspend {
 		setSuspended(&group.Status.Conditions, group.Generation, conditionReasonApplySuspended)
@@ -91,6 +110,50 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 
 	removeSuspended(&group.Status.Conditions)
 
+	if err := group.Spec.ActiveWindow.Validate(); err != nil {
+		setInvalidSpec(&group.Status.Conditions, group.Generation, conditionReasonInvalidWindow, err.Error())
+		meta.RemoveStatusCondition(&group.Status.Conditions, conditionAlertGroupSynchronized)
+
+		return ctrl.Result{}, fmt.Errorf("invalid active window: %w", err)
+	}
+
+	now := time.Now()
+	if !group.Spec.ActiveWindow.Active(now) {
+		// The Synchronized condition is only present once the group was applied
+		if meta.FindStatusCondition(group.Status.Conditions, conditionAlertGroupSynchronized) != nil {
+			log.Info("removing alert rule group outside of its active window")
+
+			if err := r.finalize(ctx, group); err != nil {
+				return ctrl.Result{}, fmt.Errorf("removing alert rule group outside of its active window: %w", err)
+			}
+		}
+
+		setInactive(&group.Status.Conditions, group.Generation, group.Spec.ActiveWindow)
+		meta.RemoveStatusCondition(&group.Status.Conditions, conditionAlertGroupSynchronized)
+		// The spec is only evaluated within the window
+		removeInvalidSpec(&group.Status.Conditions)
+
+		return ctrl.Result{RequeueAfter: group.Spec.ActiveWindow.NextTransition(now)}, nil
+	}
+
+	removeInactive(&group.Status.Conditions)
+
+	group.Spec.Interval.Duration = r.Cfg.alertRuleGroupInterval(group.Spec.Interval)
+
+	if err := validateRuleDurations(group); err != nil {
+		setInvalidSpec(&group.Status.Conditions, group.Generation, conditionReasonInvalidDuration, err.Error())
+		meta.RemoveStatusCondition(&group.Status.Conditions, conditionAlertGroupSynchronized)
+
+		return ctrl.Result{}, err
+	}
+
+	removeInvalidSpec(&group.Status.Conditions)
+
+	// The export only depends on the spec, so it is kept up to date regardless of the instances
+	if err := r.reconcilePrometheusRules(ctx, group); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, group)
 	if err != nil {
 		setNoMatchingInstancesCondition(&group.Status.Conditions, group.Generation, err)
@@ -103,10 +166,23 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 		setNoMatchingInstancesCondition(&group.Status.Conditions, group.Generation, err)
 		meta.RemoveStatusCondition(&group.Status.Conditions, conditionAlertGroupSynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, group)
 	}
 
 	removeNoMatchingInstance(&group.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, group, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&group.Status.Conditions, group.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for group", "count", len(instances))
 
 	folderUID, err := getFolderUID(ctx, r.Client, group)
@@ -118,6 +194,16 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, fmt.Errorf("folder uid not found, alert rule must reference a folder")
 	}
 
+	err = r.Cfg.checkAlertRuleGroupFolder(ctx, r.Client, group.Namespace, folderUID)
+	if err != nil {
+		if errors.Is(err, errFolderNotAllowed) {
+			setInvalidSpec(&group.Status.Conditions, group.Generation, conditionReasonFolderNotAllowed, err.Error())
+			meta.RemoveStatusCondition(&group.Status.Conditions, conditionAlertGroupSynchronized)
+		}
+
+		return ctrl.Result{}, err
+	}
+
 	editable := "true" //nolint:goconst
 	if group.Spec.Editable != nil && !*group.Spec.Editable {
 		editable = "false"
@@ -127,15 +213,44 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 
 	log.V(1).Info("converted cr to api model")
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
+	pendingWindow := make(map[string]time.Time)
 
 	for _, grafana := range instances {
-		err := r.reconcileWithInstance(ctx, &grafana, group, &mGroup, editable)
+		wait, err := grafana.Spec.ChangeWindow.Until(now)
+		if err != nil {
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
+			continue
+		}
+
+		if wait > 0 {
+			if alertRuleGroupChangePending(group, &grafana) {
+				pendingWindow[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = now.Add(wait)
+			}
+
+			continue
+		}
+
+		err = r.reconcileWithInstance(ctx, &grafana, group, &mGroup, editable)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
+	if len(pendingWindow) > 0 {
+		setPendingWindow(&group.Status.Conditions, group.Generation, pendingWindow)
+	} else {
+		removePendingWindow(&group.Status.Conditions)
+	}
+
+	requeueAfter := requeueForPendingWave(requeueWithinWindow(r.Cfg.requeueAfter(group.Spec.ResyncPeriod, group.Spec.ResyncJitterPercent), group.Spec.ActiveWindow, now), pendingWaves)
+
+	if len(applyErrors) == 0 && len(pendingWindow) > 0 {
+		// The Synchronized condition keeps the previous generation until the change reached all instances
+		log.Info("deferring alert rule group changes until the change windows of instances open", "instances", len(pendingWindow))
+		return ctrl.Result{RequeueAfter: requeueForPendingWindow(requeueAfter, pendingWindow, now)}, nil
+	}
+
 	condition := buildSynchronizedCondition("Alert Rule Group", conditionAlertGroupSynchronized, group.Generation, applyErrors, len(instances))
 	meta.SetStatusCondition(&group.Status.Conditions, condition)
 
@@ -143,7 +258,123 @@ func (r *GrafanaAlertRuleGroupReconciler) Reconcile(ctx context.Context, req ctr
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(group.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// alertRuleGroupChangePending reports whether applying the group would change the instance,
+// either because the current generation was not applied to all instances yet or because the instance does not have it
+func alertRuleGroupChangePending(group *grafanav1beta1.GrafanaAlertRuleGroup, grafana *grafanav1beta1.Grafana) bool {
+	synchronized := meta.FindStatusCondition(group.Status.Conditions, conditionAlertGroupSynchronized)
+	if synchronized == nil || synchronized.Status != metav1.ConditionTrue || synchronized.ObservedGeneration != group.Generation {
+		return true
+	}
+
+	found, _ := grafana.Status.AlertRuleGroups.Find(group.Namespace, group.Name)
+
+	return !found
+}
+
+// applyRulesInChunks calls apply for the rules in chunks of size parallel requests and returns the errors by rule UID
+func applyRulesInChunks(rules models.ProvisionedAlertRules, size int, apply func(rule *models.ProvisionedAlertRule) error) map[string]error {
+	failures := make(map[string]error)
+
+	for chunk := range slices.Chunk(rules, size) {
+		errs := make([]error, len(chunk))
+
+		var wg sync.WaitGroup
+
+		for i, rule := range chunk {
+			wg.Go(func() {
+				errs[i] = apply(rule)
+			})
+		}
+
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				failures[chunk[i].UID] = err
+			}
+		}
+	}
+
+	return failures
+}
+
+// ruleGroupBody returns the rules of the group to submit, failing rules are replaced by the version
+// applied to the instance or left out when they do not exist there yet
+func ruleGroupBody(mGroup *models.AlertRuleGroup, failures map[string]error, remote map[string]*models.ProvisionedAlertRule) models.ProvisionedAlertRules {
+	if len(failures) == 0 {
+		return mGroup.Rules
+	}
+
+	rules := make(models.ProvisionedAlertRules, 0, len(mGroup.Rules))
+
+	for _, mRule := range mGroup.Rules {
+		if _, failed := failures[mRule.UID]; !failed {
+			rules = append(rules, mRule)
+			continue
+		}
+
+		if remoteRule, ok := remote[mRule.UID]; ok {
+			rules = append(rules, remoteRule)
+		}
+	}
+
+	return rules
+}
+
+func putRuleGroup(cl *genapi.GrafanaHTTPAPI, mGroup *models.AlertRuleGroup, rules models.ProvisionedAlertRules, disableProvenance string) error {
+	body := *mGroup
+	body.Rules = rules
+
+	params := provisioning.NewPutAlertRuleGroupParams().
+		WithBody(&body).
+		WithGroup(mGroup.Title).
+		WithFolderUID(mGroup.FolderUID).
+		WithXDisableProvenance(&disableProvenance)
+
+	_, err := cl.Provisioning.PutAlertRuleGroup(params) //nolint:errcheck
+
+	return err
+}
+
+// ruleFailuresError reports the rules which could not be applied, in the order of the group
+func ruleFailuresError(failures map[string]error, rules models.ProvisionedAlertRules) error {
+	var sb strings.Builder
+
+	for _, rule := range rules {
+		err, failed := failures[rule.UID]
+		if !failed {
+			continue
+		}
+
+		title := ""
+		if rule.Title != nil {
+			title = *rule.Title
+		}
+
+		sb.WriteString(fmt.Sprintf("\n  - %s (%s): %s", title, rule.UID, err))
+	}
+
+	return fmt.Errorf("%d of %d rules failed to apply:%s", len(failures), len(rules), sb.String())
+}
+
+// validateRuleDurations rejects durations Grafana would otherwise silently round,
+// the interval is stored in seconds and rules are only evaluated once per interval
+func validateRuleDurations(cr *grafanav1beta1.GrafanaAlertRuleGroup) error {
+	interval := cr.Spec.Interval.Duration
+	if interval < time.Second || interval%time.Second != 0 {
+		return fmt.Errorf("spec.interval %s must be a whole number of seconds", interval)
+	}
+
+	for _, rule := range cr.Spec.Rules {
+		if rule.For != nil && rule.For.Duration%interval != 0 {
+			return fmt.Errorf("for duration %s of rule %s must be a multiple of spec.interval %s", rule.For.Duration, rule.Title, interval)
+		}
+	}
+
+	return nil
 }
 
 func crToModel(cr *grafanav1beta1.GrafanaAlertRuleGroup, folderUID string) models.AlertRuleGroup {
@@ -159,7 +390,7 @@ func crToModel(cr *grafanav1beta1.GrafanaAlertRuleGroup, folderUID string) model
 			ExecErrState: &r.ExecErrState,
 			FolderUID:    &folderUID,
 			For:          (*strfmt.Duration)(&r.For.Duration),
-			IsPaused:     r.IsPaused,
+			IsPaused:     r.IsPaused || cr.Spec.Paused,
 			Labels:       r.Labels,
 			NoDataState:  r.NoDataState,
 			RuleGroup:    &groupName,
@@ -245,41 +476,79 @@ func (r *GrafanaAlertRuleGroupReconciler) reconcileWithInstance(ctx context.Cont
 		remoteRules = applied.Payload.Rules
 	}
 
+	remote := make(map[string]*models.ProvisionedAlertRule, len(remoteRules))
+	for _, remoteRule := range remoteRules {
+		remote[remoteRule.UID] = remoteRule
+	}
+
 	// Rules must be created individually
 	// Find rules missing on the instance and create them
+	var missing models.ProvisionedAlertRules
+
 	for _, mRule := range mGroup.Rules {
-		ruleExists := false
+		if _, ok := remote[mRule.UID]; !ok {
+			missing = append(missing, mRule)
+		}
+	}
+
+	failures := applyRulesInChunks(missing, r.Cfg.alertRuleParallelism(), func(rule *models.ProvisionedAlertRule) error {
+		params := provisioning.NewPostAlertRuleParams().
+			WithBody(rule).
+			WithXDisableProvenance(&disableProvenance)
+
+		_, err := cl.Provisioning.PostAlertRule(params) //nolint:errcheck
+		if err != nil {
+			return fmt.Errorf("creating rule: %w", err)
+		}
+
+		return nil
+	})
 
-		for _, remoteRule := range remoteRules {
-			if mRule.UID == remoteRule.UID {
-				ruleExists = true
-				break
+	// Update whole group and all rules existing rules at once
+	// Will delete rules not present in the body
+	err = putRuleGroup(cl, mGroup, ruleGroupBody(mGroup, failures, remote), disableProvenance)
+	if err != nil {
+		// A single invalid rule fails the whole group, update the rules one by one to find it
+		// and keep the previous version of failing rules, so the others are applied
+		var existing models.ProvisionedAlertRules
+
+		for _, mRule := range mGroup.Rules {
+			if _, failed := failures[mRule.UID]; !failed {
+				existing = append(existing, mRule)
 			}
 		}
 
-		if !ruleExists {
-			params := provisioning.NewPostAlertRuleParams().
-				WithBody(mRule).
+		maps.Copy(failures, applyRulesInChunks(existing, r.Cfg.alertRuleParallelism(), func(rule *models.ProvisionedAlertRule) error {
+			params := provisioning.NewPutAlertRuleParams().
+				WithUID(rule.UID).
+				WithBody(rule).
 				WithXDisableProvenance(&disableProvenance)
 
-			_, err = cl.Provisioning.PostAlertRule(params) //nolint:errcheck
+			_, err := cl.Provisioning.PutAlertRule(params) //nolint:errcheck
 			if err != nil {
-				return fmt.Errorf("creating rule: %w", err)
+				return fmt.Errorf("updating rule: %w", err)
 			}
+
+			return nil
+		}))
+
+		if len(failures) == 0 {
+			return fmt.Errorf("updating group: %s", err.Error())
+		}
+
+		err = putRuleGroup(cl, mGroup, ruleGroupBody(mGroup, failures, remote), disableProvenance)
+		if err != nil {
+			return fmt.Errorf("updating group: %s", err.Error())
 		}
 	}
 
-	// Update whole group and all rules existing rules at once
-	// Will delete rules not present in the body
-	params := provisioning.NewPutAlertRuleGroupParams().
-		WithBody(mGroup).
-		WithGroup(mGroup.Title).
-		WithFolderUID(folderUID).
-		WithXDisableProvenance(&disableProvenance)
+	if len(failures) > 0 {
+		// Rules which were applied are tracked, so they are removed when the group is deleted
+		if err := instance.AddNamespacedResource(ctx, r.Client, group, group.NamespacedResource()); err != nil {
+			return err
+		}
 
-	_, err = cl.Provisioning.PutAlertRuleGroup(params) //nolint:errcheck
-	if err != nil {
-		return fmt.Errorf("updating group: %s", err.Error())
+		return ruleFailuresError(failures, mGroup.Rules)
 	}
 
 	// Update grafana instance Status
@@ -299,6 +568,18 @@ func (r *GrafanaAlertRuleGroupReconciler) finalize(ctx context.Context, group *g
 		isCleanupInGrafanaRequired = false
 	}
 
+	// Never delete a group of the same name written by another namespace
+	if isCleanupInGrafanaRequired {
+		err = r.Cfg.checkAlertRuleGroupFolder(ctx, r.Client, group.Namespace, folderUID)
+		if errors.Is(err, errFolderNotAllowed) {
+			log.Info("Skipping Grafana finalize logic as the folder is not allowed for the namespace")
+
+			isCleanupInGrafanaRequired = false
+		} else if err != nil {
+			return err
+		}
+	}
+
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, group)
 	if err != nil {
 		return fmt.Errorf("fetching instances: %w", err)
@@ -332,9 +613,20 @@ func (r *GrafanaAlertRuleGroupReconciler) finalize(ctx context.Context, group *g
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *GrafanaAlertRuleGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *GrafanaAlertRuleGroupReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Index the alert rule groups by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &grafanav1beta1.GrafanaAlertRuleGroup{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&grafanav1beta1.GrafanaAlertRuleGroup{}).
-		WithEventFilter(ignoreStatusUpdates()).
+		For(&grafanav1beta1.GrafanaAlertRuleGroup{}, builder.WithPredicates(
+			ignoreStatusUpdates(),
+		)).
+		Watches(
+			&grafanav1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &grafanav1beta1.GrafanaAlertRuleGroupList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
 		Complete(r)
 }
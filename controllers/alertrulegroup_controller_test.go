@@ -1,15 +1,137 @@
 package controllers
 
 import (
+	"context"
+	"fmt"
+	"testing"
 	"time"
 
+	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "github.com/onsi/ginkgo/v2"
 )
 
+func TestCrToModelPaused(t *testing.T) {
+	noDataState := "NoData"
+	cr := &v1beta1.GrafanaAlertRuleGroup{
+		Spec: v1beta1.GrafanaAlertRuleGroupSpec{
+			Interval: metav1.Duration{Duration: time.Minute},
+			Rules: []v1beta1.AlertRule{
+				{Title: "active", UID: "active", NoDataState: &noDataState, For: &metav1.Duration{}},
+				{Title: "paused", UID: "paused", NoDataState: &noDataState, For: &metav1.Duration{}, IsPaused: true},
+			},
+		},
+	}
+
+	group := crToModel(cr, "folder")
+	assert.False(t, group.Rules[0].IsPaused)
+	assert.True(t, group.Rules[1].IsPaused)
+
+	cr.Spec.Paused = true
+
+	group = crToModel(cr, "folder")
+	assert.True(t, group.Rules[0].IsPaused)
+	assert.True(t, group.Rules[1].IsPaused)
+}
+
+func TestValidateRuleDurations(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		ruleFor  *metav1.Duration
+		wantErr  bool
+	}{
+		{name: "for multiple of interval", interval: time.Minute, ruleFor: &metav1.Duration{Duration: 5 * time.Minute}},
+		{name: "for unset", interval: time.Minute},
+		{name: "for zero", interval: time.Minute, ruleFor: &metav1.Duration{}},
+		{name: "for not a multiple of interval", interval: time.Minute, ruleFor: &metav1.Duration{Duration: 90 * time.Second}, wantErr: true},
+		{name: "sub-second interval", interval: 1500 * time.Millisecond, wantErr: true},
+		{name: "interval below one second", interval: 500 * time.Millisecond, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1beta1.GrafanaAlertRuleGroup{
+				Spec: v1beta1.GrafanaAlertRuleGroupSpec{
+					Interval: metav1.Duration{Duration: tt.interval},
+					Rules:    []v1beta1.AlertRule{{Title: "rule", For: tt.ruleFor}},
+				},
+			}
+
+			err := validateRuleDurations(cr)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAlertRuleGroupIntervalDefault(t *testing.T) {
+	var cfg *Config
+	assert.Equal(t, DefaultAlertRuleGroupInterval, cfg.alertRuleGroupInterval(metav1.Duration{}))
+
+	cfg = &Config{AlertRuleGroupInterval: 5 * time.Minute}
+	assert.Equal(t, 5*time.Minute, cfg.alertRuleGroupInterval(metav1.Duration{}))
+	assert.Equal(t, 30*time.Second, cfg.alertRuleGroupInterval(metav1.Duration{Duration: 30 * time.Second}))
+}
+
+func TestCheckAlertRuleGroupFolder(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	config := &v1beta1.GrafanaOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+		Spec: v1beta1.GrafanaOperatorConfigSpec{
+			AlertRuleGroupFolders: []v1beta1.NamespaceFolderPolicy{
+				{Namespaces: []string{"team-a"}, FolderUIDs: []string{"shared"}, NamespaceFolders: true},
+				{Namespaces: []string{"team-b"}, FolderUIDs: []string{"team-b"}},
+			},
+		},
+	}
+	folder := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "alerts"},
+		Spec:       v1beta1.GrafanaFolderSpec{CustomUID: "team-a-alerts"},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(config, folder).Build()
+
+	tests := []struct {
+		name      string
+		namespace string
+		folderUID string
+		allowed   bool
+	}{
+		{name: "listed folder", namespace: "team-a", folderUID: "shared", allowed: true},
+		{name: "folder of the namespace", namespace: "team-a", folderUID: "team-a-alerts", allowed: true},
+		{name: "folder of another namespace", namespace: "team-b", folderUID: "team-a-alerts", allowed: false},
+		{name: "unlisted folder", namespace: "team-a", folderUID: "team-b", allowed: false},
+		{name: "unrestricted namespace", namespace: "platform", folderUID: "team-b", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{ClusterScoped: true}).checkAlertRuleGroupFolder(context.Background(), cl, tt.namespace, tt.folderUID)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errFolderNotAllowed)
+			}
+		})
+	}
+}
+
 var _ = Describe("AlertRulegroup Reconciler: Provoke Conditions", func() {
 	noDataState := "NoData"
 	rules := []v1beta1.AlertRule{
@@ -153,3 +275,95 @@ var _ = Describe("AlertRulegroup Reconciler: Provoke Conditions", func() {
 		})
 	}
 })
+
+func TestReconcileLargeAlertRuleGroup(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grafana"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+	group := &v1beta1.GrafanaAlertRuleGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "large"},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey, grafana).WithStatusSubresource(grafana).Build()
+	r := &GrafanaAlertRuleGroupReconciler{Client: cl, Scheme: s}
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Folders.CreateFolder(&models.CreateFolderCommand{UID: "alerts", Title: "alerts"})
+	require.NoError(t, err)
+
+	newRule := func(i int, condition string) *models.ProvisionedAlertRule {
+		title := fmt.Sprintf("rule %d", i)
+		folderUID := "alerts"
+		groupName := "large"
+
+		return &models.ProvisionedAlertRule{
+			UID:       fmt.Sprintf("rule-%d", i),
+			Title:     &title,
+			FolderUID: &folderUID,
+			RuleGroup: &groupName,
+			Condition: &condition,
+			Data:      []*models.AlertQuery{{RefID: "A"}},
+		}
+	}
+
+	mGroup := models.AlertRuleGroup{FolderUID: "alerts", Title: "large", Interval: 60}
+	for i := range 45 {
+		condition := "A"
+		if i == 7 || i == 31 {
+			condition = "B"
+		}
+
+		mGroup.Rules = append(mGroup.Rules, newRule(i, condition))
+	}
+
+	err = r.reconcileWithInstance(ctx, grafana, group, &mGroup, "true")
+	require.ErrorContains(t, err, "2 of 45 rules failed to apply")
+	require.ErrorContains(t, err, "rule 7 (rule-7)")
+	require.ErrorContains(t, err, "rule 31 (rule-31)")
+
+	applied, found := srv.AlertRuleGroup("alerts", "large")
+	require.True(t, found)
+	assert.Len(t, applied.Rules, 43)
+
+	// An invalid update of an existing rule keeps its previous version
+	mGroup.Rules[3] = newRule(3, "C")
+	*mGroup.Rules[3].Title = "rule 3 updated"
+	mGroup.Rules[7] = newRule(7, "A")
+	mGroup.Rules[31] = newRule(31, "A")
+
+	err = r.reconcileWithInstance(ctx, grafana, group, &mGroup, "true")
+	require.ErrorContains(t, err, "1 of 45 rules failed to apply")
+
+	applied, _ = srv.AlertRuleGroup("alerts", "large")
+	require.Len(t, applied.Rules, 45)
+	assert.Equal(t, "rule 3", *applied.Rules[3].Title)
+
+	mGroup.Rules[3] = newRule(3, "A")
+
+	require.NoError(t, r.reconcileWithInstance(ctx, grafana, group, &mGroup, "true"))
+}
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+)
+
+const (
+	conditionPrometheusRulesExported = "PrometheusRulesExported"
+	conditionReasonRulesExported     = "RulesExported"
+	conditionReasonRulesSkipped      = "RulesSkipped"
+
+	// prometheusRulesKey is the key of the rule file in the export ConfigMap
+	prometheusRulesKey = "rules.yaml"
+
+	// expressionDatasourceUID identifies server side expressions among the queries of a rule
+	expressionDatasourceUID = "__expr__"
+
+	// prometheusDatasourceType is the type of datasources queried with PromQL, including Mimir
+	prometheusDatasourceType = "prometheus"
+)
+
+// prometheusRuleFile is the Prometheus rule file format, also accepted by the Mimir ruler
+type prometheusRuleFile struct {
+	Groups []prometheusRuleGroup `json:"groups"`
+}
+
+type prometheusRuleGroup struct {
+	Name     string           `json:"name"`
+	Interval string           `json:"interval,omitempty"`
+	Rules    []prometheusRule `json:"rules"`
+}
+
+type prometheusRule struct {
+	Record        string            `json:"record,omitempty"`
+	Alert         string            `json:"alert,omitempty"`
+	Expr          string            `json:"expr"`
+	For           string            `json:"for,omitempty"`
+	KeepFiringFor string            `json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// alertQueryModel holds the fields of query models relevant to the conversion, PromQL queries carry expr,
+// server side expressions carry type and their settings
+type alertQueryModel struct {
+	Datasource struct {
+		Type string `json:"type"`
+	} `json:"datasource"`
+	Expr       string `json:"expr"`
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+	Reducer    string `json:"reducer"`
+	Conditions []struct {
+		Evaluator struct {
+			Type   string    `json:"type"`
+			Params []float64 `json:"params"`
+		} `json:"evaluator"`
+	} `json:"conditions"`
+}
+
+// thresholdOperators maps the threshold evaluators of Grafana to PromQL comparison operators
+var thresholdOperators = map[string]string{
+	"gt": ">",
+	"lt": "<",
+}
+
+func prometheusRulesConfigMapName(group *grafanav1beta1.GrafanaAlertRuleGroup) string {
+	return group.Name + "-prometheus-rules"
+}
+
+// reconcilePrometheusRules exports the rules of the group into the ConfigMap of spec.prometheusExport
+// and removes it once the export is disabled
+func (r *GrafanaAlertRuleGroupReconciler) reconcilePrometheusRules(ctx context.Context, group *grafanav1beta1.GrafanaAlertRuleGroup) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prometheusRulesConfigMapName(group),
+			Namespace: group.Namespace,
+		},
+	}
+
+	export := group.Spec.PrometheusExport
+	if export == nil {
+		meta.RemoveStatusCondition(&group.Status.Conditions, conditionPrometheusRulesExported)
+
+		err := r.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+		if err != nil {
+			if kuberr.IsNotFound(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !metav1.IsControlledBy(cm, group) {
+			return nil
+		}
+
+		return client.IgnoreNotFound(r.Delete(ctx, cm))
+	}
+
+	datasourceTypes, err := r.datasourceTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ruleGroup, skipped := toPrometheusRuleGroup(group, datasourceTypes)
+
+	data, err := yaml.Marshal(prometheusRuleFile{Groups: []prometheusRuleGroup{ruleGroup}})
+	if err != nil {
+		return err
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		// The operator cache only includes ConfigMaps carrying the common labels
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+
+		maps.Copy(cm.Labels, export.Labels)
+		maps.Copy(cm.Labels, model.GetCommonLabels())
+
+		cm.Data = map[string]string{prometheusRulesKey: string(data)}
+
+		return controllerutil.SetControllerReference(group, cm, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("exporting prometheus rules: %w", err)
+	}
+
+	condition := metav1.Condition{
+		Type:               conditionPrometheusRulesExported,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: group.Generation,
+		Reason:             conditionReasonRulesExported,
+		Message:            fmt.Sprintf("Exported %d rules to ConfigMap %s", len(ruleGroup.Rules), cm.Name),
+	}
+
+	if len(skipped) > 0 {
+		condition.Reason = conditionReasonRulesSkipped
+		condition.Message = fmt.Sprintf("Exported %d of %d rules to ConfigMap %s, skipped rules without Prometheus equivalent:\n- %s",
+			len(ruleGroup.Rules), len(group.Spec.Rules), cm.Name, strings.Join(skipped, "\n- "))
+	}
+
+	meta.SetStatusCondition(&group.Status.Conditions, condition)
+
+	return nil
+}
+
+// datasourceTypes maps the uids of the datasources managed by the operator to their type
+func (r *GrafanaAlertRuleGroupReconciler) datasourceTypes(ctx context.Context) (map[string]string, error) {
+	list := &grafanav1beta1.GrafanaDatasourceList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("listing datasources: %w", err)
+	}
+
+	types := make(map[string]string, len(list.Items))
+
+	for _, ds := range list.Items {
+		if ds.Spec.Datasource != nil {
+			types[ds.CustomUIDOrUID()] = ds.Spec.Datasource.Type
+		}
+	}
+
+	return types, nil
+}
+
+// toPrometheusRuleGroup converts the rules of the group, the rules without Prometheus equivalent
+// are returned in skipped along with the reason. datasourceTypes maps datasource uids to their type
+// for queries whose model doesn't name the type
+func toPrometheusRuleGroup(group *grafanav1beta1.GrafanaAlertRuleGroup, datasourceTypes map[string]string) (prometheusRuleGroup, []string) {
+	ruleGroup := prometheusRuleGroup{
+		Name:  group.GroupName(),
+		Rules: make([]prometheusRule, 0, len(group.Spec.Rules)),
+	}
+
+	if group.Spec.Interval.Duration > 0 {
+		ruleGroup.Interval = prometheusDuration(group.Spec.Interval.Duration)
+	}
+
+	var skipped []string
+
+	for _, rule := range group.Spec.Rules {
+		if rule.IsPaused || group.Spec.Paused {
+			skipped = append(skipped, fmt.Sprintf("%s: paused", rule.Title))
+			continue
+		}
+
+		promRule, err := toPrometheusRule(&rule, datasourceTypes)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %s", rule.Title, err))
+			continue
+		}
+
+		ruleGroup.Rules = append(ruleGroup.Rules, promRule)
+	}
+
+	return ruleGroup, skipped
+}
+
+func toPrometheusRule(rule *grafanav1beta1.AlertRule, datasourceTypes map[string]string) (prometheusRule, error) {
+	queries := make(map[string]*grafanav1beta1.AlertQuery, len(rule.Data))
+	for _, q := range rule.Data {
+		queries[q.RefID] = q
+	}
+
+	if rule.Record != nil {
+		expr, err := promQLExpression(queries, datasourceTypes, rule.Record.From)
+		if err != nil {
+			return prometheusRule{}, err
+		}
+
+		return prometheusRule{
+			Record: rule.Record.Metric,
+			Expr:   expr,
+			Labels: rule.Labels,
+		}, nil
+	}
+
+	expr, err := promQLExpression(queries, datasourceTypes, rule.Condition)
+	if err != nil {
+		return prometheusRule{}, err
+	}
+
+	promRule := prometheusRule{
+		Alert:       rule.Title,
+		Expr:        expr,
+		Labels:      rule.Labels,
+		Annotations: rule.Annotations,
+	}
+
+	if rule.For != nil && rule.For.Duration > 0 {
+		promRule.For = prometheusDuration(rule.For.Duration)
+	}
+
+	if rule.KeepFiringFor != nil && rule.KeepFiringFor.Duration > 0 {
+		promRule.KeepFiringFor = prometheusDuration(rule.KeepFiringFor.Duration)
+	}
+
+	return promRule, nil
+}
+
+// promQLExpression returns the PromQL expression equivalent to the query refID, following reduce and threshold
+// expressions down to a single query of a Prometheus datasource
+func promQLExpression(queries map[string]*grafanav1beta1.AlertQuery, datasourceTypes map[string]string, refID string) (string, error) {
+	q, ok := queries[refID]
+	if !ok {
+		return "", fmt.Errorf("query %s not found", refID)
+	}
+
+	// Each query is visited once, references in circles end as not found
+	delete(queries, refID)
+
+	m := alertQueryModel{}
+	if q.Model != nil {
+		if err := json.Unmarshal(q.Model.Raw, &m); err != nil {
+			return "", fmt.Errorf("parsing query %s: %w", refID, err)
+		}
+	}
+
+	if q.DatasourceUID != expressionDatasourceUID {
+		// Other datasources use expr as well, e.g. LogQL queries of Loki
+		datasourceType := m.Datasource.Type
+		if datasourceType == "" {
+			datasourceType = datasourceTypes[q.DatasourceUID]
+		}
+
+		switch datasourceType {
+		case prometheusDatasourceType:
+		case "":
+			return "", fmt.Errorf("type of datasource %s of query %s is unknown", q.DatasourceUID, refID)
+		default:
+			return "", fmt.Errorf("query %s uses a datasource of type %s", refID, datasourceType)
+		}
+
+		if m.Expr == "" {
+			return "", fmt.Errorf("query %s is not a PromQL query", refID)
+		}
+
+		return m.Expr, nil
+	}
+
+	switch m.Type {
+	case "reduce":
+		// Instant queries return a single sample per series already
+		if m.Reducer != "last" {
+			return "", fmt.Errorf("reducer %q of expression %s has no PromQL equivalent", m.Reducer, refID)
+		}
+
+		return promQLExpression(queries, datasourceTypes, m.Expression)
+	case "threshold":
+		if len(m.Conditions) != 1 || len(m.Conditions[0].Evaluator.Params) == 0 {
+			return "", fmt.Errorf("threshold expression %s has no single condition", refID)
+		}
+
+		evaluator := m.Conditions[0].Evaluator
+
+		operator, ok := thresholdOperators[evaluator.Type]
+		if !ok {
+			return "", fmt.Errorf("threshold %q of expression %s has no PromQL equivalent", evaluator.Type, refID)
+		}
+
+		expr, err := promQLExpression(queries, datasourceTypes, m.Expression)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("(%s) %s %s", expr, operator, strconv.FormatFloat(evaluator.Params[0], 'f', -1, 64)), nil
+	default:
+		return "", fmt.Errorf("expression %s of type %q has no PromQL equivalent", refID, m.Type)
+	}
+}
+
+// prometheusDuration formats d the way Prometheus does, e.g. 1h30m instead of 1h30m0s
+func prometheusDuration(d time.Duration) string {
+	return prommodel.Duration(d).String()
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func promQuery(refID, expr string) *v1beta1.AlertQuery {
+	return &v1beta1.AlertQuery{RefID: refID, DatasourceUID: "prometheus", Model: &v1.JSON{Raw: []byte(`{"expr":"` + expr + `"}`)}}
+}
+
+func expressionQuery(refID, model string) *v1beta1.AlertQuery {
+	return &v1beta1.AlertQuery{RefID: refID, DatasourceUID: expressionDatasourceUID, Model: &v1.JSON{Raw: []byte(model)}}
+}
+
+func TestToPrometheusRuleGroup(t *testing.T) {
+	group := &v1beta1.GrafanaAlertRuleGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "availability"},
+		Spec: v1beta1.GrafanaAlertRuleGroupSpec{
+			Interval: metav1.Duration{Duration: time.Minute},
+			Rules: []v1beta1.AlertRule{
+				{
+					Title:     "High error rate",
+					Condition: "C",
+					Data: []*v1beta1.AlertQuery{
+						promQuery("A", "sum(rate(errors_total[5m]))"),
+						expressionQuery("B", `{"type":"reduce","expression":"A","reducer":"last"}`),
+						expressionQuery("C", `{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"gt","params":[0.5]}}]}`),
+					},
+					For:           &metav1.Duration{Duration: 5 * time.Minute},
+					KeepFiringFor: &metav1.Duration{Duration: 90 * time.Minute},
+					Labels:        map[string]string{"severity": "critical"},
+					Annotations:   map[string]string{"summary": "Errors"},
+				},
+				{
+					Title:     "Target down",
+					Condition: "A",
+					Data:      []*v1beta1.AlertQuery{promQuery("A", "up == 0")},
+					For:       &metav1.Duration{},
+				},
+				{
+					Title:  "Error rate",
+					Record: &v1beta1.Record{From: "A", Metric: "job:errors:rate5m"},
+					Data:   []*v1beta1.AlertQuery{promQuery("A", "rate(errors_total[5m])")},
+				},
+				{
+					Title:     "Mean latency",
+					Condition: "C",
+					Data: []*v1beta1.AlertQuery{
+						promQuery("A", "latency_seconds"),
+						expressionQuery("B", `{"type":"reduce","expression":"A","reducer":"mean"}`),
+						expressionQuery("C", `{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"gt","params":[1]}}]}`),
+					},
+				},
+				{
+					Title:     "Logs",
+					Condition: "A",
+					Data:      []*v1beta1.AlertQuery{{RefID: "A", DatasourceUID: "loki", Model: &v1.JSON{Raw: []byte(`{"expr":"count_over_time({job=\"app\"}[5m]) > 0"}`)}}},
+				},
+				{
+					Title:     "Log errors",
+					Condition: "A",
+					Data: []*v1beta1.AlertQuery{{RefID: "A", DatasourceUID: "logs", Model: &v1.JSON{Raw: []byte(
+						`{"datasource":{"type":"loki","uid":"logs"},"expr":"count_over_time({level=\"error\"}[5m]) > 0"}`,
+					)}}},
+				},
+				{
+					Title:     "Mimir",
+					Condition: "A",
+					Data: []*v1beta1.AlertQuery{{RefID: "A", DatasourceUID: "mimir", Model: &v1.JSON{Raw: []byte(
+						`{"datasource":{"type":"prometheus","uid":"mimir"},"expr":"up == 0"}`,
+					)}}},
+				},
+				{
+					Title:     "Unmanaged",
+					Condition: "A",
+					Data:      []*v1beta1.AlertQuery{{RefID: "A", DatasourceUID: "external", Model: &v1.JSON{Raw: []byte(`{"expr":"up == 0"}`)}}},
+				},
+				{
+					Title:     "Paused",
+					Condition: "A",
+					Data:      []*v1beta1.AlertQuery{promQuery("A", "up == 0")},
+					IsPaused:  true,
+				},
+			},
+		},
+	}
+
+	ruleGroup, skipped := toPrometheusRuleGroup(group, map[string]string{"prometheus": "prometheus", "loki": "loki"})
+
+	assert.Equal(t, prometheusRuleGroup{
+		Name:     "availability",
+		Interval: "1m",
+		Rules: []prometheusRule{
+			{
+				Alert:         "High error rate",
+				Expr:          "(sum(rate(errors_total[5m]))) > 0.5",
+				For:           "5m",
+				KeepFiringFor: "1h30m",
+				Labels:        map[string]string{"severity": "critical"},
+				Annotations:   map[string]string{"summary": "Errors"},
+			},
+			{Alert: "Target down", Expr: "up == 0"},
+			{Record: "job:errors:rate5m", Expr: "rate(errors_total[5m])"},
+			{Alert: "Mimir", Expr: "up == 0"},
+		},
+	}, ruleGroup)
+
+	assert.Equal(t, []string{
+		`Mean latency: reducer "mean" of expression B has no PromQL equivalent`,
+		"Logs: query A uses a datasource of type loki",
+		"Log errors: query A uses a datasource of type loki",
+		"Unmanaged: type of datasource external of query A is unknown",
+		"Paused: paused",
+	}, skipped)
+}
+
+func TestPromQLExpressionCircle(t *testing.T) {
+	queries := map[string]*v1beta1.AlertQuery{
+		"A": expressionQuery("A", `{"type":"reduce","expression":"B","reducer":"last"}`),
+		"B": expressionQuery("B", `{"type":"reduce","expression":"A","reducer":"last"}`),
+	}
+
+	_, err := promQLExpression(queries, nil, "A")
+	assert.EqualError(t, err, "query A not found")
+}
+
+func TestReconcilePrometheusRules(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, corev1.AddToScheme(s))
+
+	group := &v1beta1.GrafanaAlertRuleGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "availability", Namespace: "default", UID: "group-uid"},
+		Spec: v1beta1.GrafanaAlertRuleGroupSpec{
+			Rules: []v1beta1.AlertRule{
+				{Title: "Target down", Condition: "A", Data: []*v1beta1.AlertQuery{promQuery("A", "up == 0")}},
+			},
+			PrometheusExport: &v1beta1.AlertRuleGroupPrometheusExport{Labels: map[string]string{"mimir.rules": "true"}},
+		},
+	}
+
+	datasource := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "default"},
+		Spec: v1beta1.GrafanaDatasourceSpec{
+			CustomUID:  "prometheus",
+			Datasource: &v1beta1.GrafanaDatasourceInternal{Type: "prometheus"},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(datasource).Build()
+	r := &GrafanaAlertRuleGroupReconciler{Client: cl, Scheme: s}
+
+	require.NoError(t, r.reconcilePrometheusRules(t.Context(), group))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "availability-prometheus-rules"}, cm))
+	assert.True(t, metav1.IsControlledBy(cm, group))
+	assert.Equal(t, "true", cm.Labels["mimir.rules"])
+	assert.Equal(t, `groups:
+- name: availability
+  rules:
+  - alert: Target down
+    expr: up == 0
+`, cm.Data[prometheusRulesKey])
+
+	condition := meta.FindStatusCondition(group.Status.Conditions, conditionPrometheusRulesExported)
+	require.NotNil(t, condition)
+	assert.Equal(t, conditionReasonRulesExported, condition.Reason)
+
+	group.Spec.PrometheusExport = nil
+
+	require.NoError(t, r.reconcilePrometheusRules(t.Context(), group))
+
+	err := cl.Get(t.Context(), client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+	assert.True(t, kuberr.IsNotFound(err))
+	assert.Nil(t, meta.FindStatusCondition(group.Status.Conditions, conditionPrometheusRulesExported))
+}
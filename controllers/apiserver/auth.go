@@ -0,0 +1,108 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+const apiGroup = "grafana.integreatly.org"
+
+type accessKey struct{}
+
+// access tells which namespaces the caller may list resources in, the answers of the API server are kept for the
+// duration of the request
+type access struct {
+	client  client.Client
+	user    authenticationv1.UserInfo
+	allowed map[string]bool
+}
+
+// withAuthentication resolves the bearer token of the request through a TokenReview and passes the access of the
+// caller to the handler, requests without a valid token are rejected
+func (s *Server) withAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+		if err := s.client.Create(r.Context(), review); err != nil {
+			writeError(w, fmt.Errorf("reviewing token: %w", err))
+			return
+		}
+
+		if !review.Status.Authenticated {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		a := &access{
+			client:  s.client,
+			user:    review.Status.User,
+			allowed: make(map[string]bool),
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accessKey{}, a)))
+	})
+}
+
+func accessFromContext(ctx context.Context) *access {
+	a, _ := ctx.Value(accessKey{}).(*access) //nolint:errcheck
+
+	return a
+}
+
+// canList returns whether the caller may list the resource of the API group in the namespace, callers allowed to
+// list it in all namespaces are not checked per namespace
+func (a *access) canList(ctx context.Context, resource, namespace string) (bool, error) {
+	if namespace != "" {
+		all, err := a.canList(ctx, resource, "")
+		if err != nil || all {
+			return all, err
+		}
+	}
+
+	key := resource + "/" + namespace
+	if allowed, ok := a.allowed[key]; ok {
+		return allowed, nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(a.user.Extra))
+	for k, v := range a.user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   a.user.Username,
+			UID:    a.user.UID,
+			Groups: a.user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     apiGroup,
+				Resource:  resource,
+			},
+		},
+	}
+
+	if err := a.client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("reviewing access to %s: %w", resource, err)
+	}
+
+	a.allowed[key] = review.Status.Allowed
+
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,221 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	operatorapi "github.com/grafana/grafana-operator/v5/api"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// Relations between nodes of the graph
+const (
+	RelationAppliedTo      = "appliedTo"
+	RelationInFolder       = "inFolder"
+	RelationUsesDatasource = "usesDatasource"
+	RelationNotifies       = "notifies"
+)
+
+// GraphNode is a Grafana instance or resource
+type GraphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	Suspended bool   `json:"suspended,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// GraphEdge is a relation between two nodes
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// Graph describes Grafana instances and resources with their relationships
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// handleGraph returns the dependency graph as JSON or, with format=dot, in the Graphviz DOT language.
+// The namespace query parameter limits the resources, all instances visible to the caller are included
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	grafanas, err := s.listGrafanas(r.Context(), "")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	objects, err := s.listObjects(r.Context(), query.Get("namespace"), "")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	graph := buildGraph(grafanas, objects)
+
+	switch query.Get("format") {
+	case "", "json":
+		writeJSON(w, graph)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		fmt.Fprint(w, graph.DOT()) //nolint:errcheck
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format %q, use json or dot", query.Get("format")), http.StatusBadRequest)
+	}
+}
+
+func nodeID(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func buildGraph(grafanas []v1beta1.Grafana, objects []object) Graph {
+	graph := Graph{
+		Nodes: make([]GraphNode, 0, len(grafanas)+len(objects)),
+		Edges: make([]GraphEdge, 0),
+	}
+
+	for _, grafana := range grafanas {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:        nodeID("Grafana", grafana.Namespace, grafana.Name),
+			Kind:      "Grafana",
+			Namespace: grafana.Namespace,
+			Name:      grafana.Name,
+			Healthy:   grafana.Status.StageStatus == v1beta1.OperatorStageResultSuccess,
+			Suspended: grafana.Spec.Suspend,
+			LastError: grafana.Status.LastMessage,
+		})
+	}
+
+	resources := make(map[string]Resource, len(objects))
+	folderUIDs := make(map[string]string)
+
+	for _, o := range objects {
+		resource := newResource(o.kind, o.cr, grafanas)
+		id := nodeID(o.kind, o.cr.GetNamespace(), o.cr.GetName())
+		resources[id] = resource
+
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:        id,
+			Kind:      o.kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+			Healthy:   resource.LastError == "",
+			Suspended: resource.Suspended,
+			LastError: resource.LastError,
+		})
+
+		for _, instance := range resource.Instances {
+			graph.Edges = append(graph.Edges, GraphEdge{From: id, To: "Grafana/" + instance, Relation: RelationAppliedTo})
+		}
+
+		if folder, ok := o.cr.(*v1beta1.GrafanaFolder); ok {
+			folderUIDs[folder.CustomUIDOrUID()] = id
+		}
+	}
+
+	// related resources match by name or title when they share an instance or namespace
+	related := func(from string, kind, namespace string, matches func(object) bool) {
+		for _, o := range objects {
+			if o.kind != kind || !matches(o) {
+				continue
+			}
+
+			to := nodeID(o.kind, o.cr.GetNamespace(), o.cr.GetName())
+			if o.cr.GetNamespace() == namespace || sharesInstance(resources[from], resources[to]) {
+				graph.Edges = append(graph.Edges, GraphEdge{From: from, To: to, Relation: kindRelation[kind]})
+			}
+		}
+	}
+
+	for _, o := range objects {
+		id := nodeID(o.kind, o.cr.GetNamespace(), o.cr.GetName())
+
+		if ref, ok := o.cr.(operatorapi.FolderReferencer); ok {
+			to := ""
+			if ref.FolderRef() != "" {
+				to = nodeID("GrafanaFolder", ref.FolderNamespace(), ref.FolderRef())
+			} else if ref.FolderUID() != "" {
+				to = folderUIDs[ref.FolderUID()]
+			}
+
+			if _, exists := resources[to]; exists {
+				graph.Edges = append(graph.Edges, GraphEdge{From: id, To: to, Relation: RelationInFolder})
+			}
+		}
+
+		switch cr := o.cr.(type) {
+		case *v1beta1.GrafanaDashboard:
+			for _, ds := range cr.Spec.Datasources {
+				related(id, "GrafanaDatasource", cr.Namespace, func(o object) bool {
+					datasource, ok := o.cr.(*v1beta1.GrafanaDatasource)
+					return ok && datasource.Spec.Datasource != nil && datasource.Spec.Datasource.Name == ds.DatasourceName
+				})
+			}
+		case *v1beta1.GrafanaAlertRuleGroup:
+			receivers := make([]string, 0)
+
+			for _, rule := range cr.Spec.Rules {
+				if rule.NotificationSettings != nil && !slices.Contains(receivers, rule.NotificationSettings.Receiver) {
+					receivers = append(receivers, rule.NotificationSettings.Receiver)
+				}
+			}
+
+			related(id, "GrafanaContactPoint", cr.Namespace, func(o object) bool {
+				contactPoint, ok := o.cr.(*v1beta1.GrafanaContactPoint)
+				return ok && slices.Contains(receivers, contactPoint.Spec.Name)
+			})
+		}
+	}
+
+	return graph
+}
+
+var kindRelation = map[string]string{
+	"GrafanaDatasource":   RelationUsesDatasource,
+	"GrafanaContactPoint": RelationNotifies,
+}
+
+func sharesInstance(a, b Resource) bool {
+	for _, instance := range a.Instances {
+		if slices.Contains(b.Instances, instance) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DOT renders the graph in the Graphviz DOT language, unhealthy nodes are drawn red
+func (g Graph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph grafana {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, n := range g.Nodes {
+		color := "darkgreen"
+		if !n.Healthy {
+			color = "red"
+		} else if n.Suspended {
+			color = "gray"
+		}
+
+		fmt.Fprintf(&b, "  %q [label=%q, color=%s];\n", n.ID, n.Kind+"\n"+n.Namespace+"/"+n.Name, color)
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Relation)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
@@ -0,0 +1,399 @@
+// Package apiserver exposes a read-only HTTP API describing the state computed by the operator,
+// e.g. which resources target which Grafana instances, for integration with developer portals.
+package apiserver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+var _ manager.LeaderElectionRunnable = (*Server)(nil)
+
+// Instance describes a Grafana instance and its reconcile state
+type Instance struct {
+	Namespace   string             `json:"namespace"`
+	Name        string             `json:"name"`
+	External    bool               `json:"external"`
+	Version     string             `json:"version,omitempty"`
+	AdminURL    string             `json:"adminUrl,omitempty"`
+	Stage       string             `json:"stage,omitempty"`
+	StageStatus string             `json:"stageStatus,omitempty"`
+	LastMessage string             `json:"lastMessage,omitempty"`
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Resource describes a resource, the instances it is applied to and its synchronization state
+type Resource struct {
+	Kind       string             `json:"kind"`
+	Namespace  string             `json:"namespace"`
+	Name       string             `json:"name"`
+	Instances  []string           `json:"instances"`
+	Suspended  bool               `json:"suspended"`
+	LastResync *metav1.Time       `json:"lastResync,omitempty"`
+	LastError  string             `json:"lastError,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type resourceKind struct {
+	kind     string
+	resource string
+	list     func() client.ObjectList
+}
+
+// Only kinds tracked in the Grafana status can be related to instances
+var resourceKinds = []resourceKind{
+	{"GrafanaAlertRuleGroup", "grafanaalertrulegroups", func() client.ObjectList { return &v1beta1.GrafanaAlertRuleGroupList{} }},
+	{"GrafanaContactPoint", "grafanacontactpoints", func() client.ObjectList { return &v1beta1.GrafanaContactPointList{} }},
+	{"GrafanaDashboard", "grafanadashboards", func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} }},
+	{"GrafanaDatasource", "grafanadatasources", func() client.ObjectList { return &v1beta1.GrafanaDatasourceList{} }},
+	{"GrafanaFolder", "grafanafolders", func() client.ObjectList { return &v1beta1.GrafanaFolderList{} }},
+	{"GrafanaLibraryPanel", "grafanalibrarypanels", func() client.ObjectList { return &v1beta1.GrafanaLibraryPanelList{} }},
+	{"GrafanaMuteTiming", "grafanamutetimings", func() client.ObjectList { return &v1beta1.GrafanaMuteTimingList{} }},
+	{"GrafanaNotificationTemplate", "grafananotificationtemplates", func() client.ObjectList { return &v1beta1.GrafanaNotificationTemplateList{} }},
+}
+
+// Server serves the read-only operator API over https. Callers authenticate with a Kubernetes bearer token and
+// only see the resources they may list themselves
+type Server struct {
+	client  client.Client
+	addr    string
+	certDir string
+}
+
+// New creates a Server listening on addr, reading objects through the given client. The serving certificate is
+// read from tls.crt and tls.key in certDir, a self-signed certificate is generated when certDir is empty
+func New(cl client.Client, addr, certDir string) *Server {
+	return &Server{
+		client:  cl,
+		addr:    addr,
+		certDir: certDir,
+	}
+}
+
+// NeedLeaderElection returns false, the API is read-only and served by all replicas
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the API until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("apiserver")
+
+	tlsConfig, err := s.tlsConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig:         tlsConfig,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "shutting down operator api server")
+		}
+	}()
+
+	log.Info("starting operator api server", "addr", s.addr)
+
+	err = srv.ListenAndServeTLS("", "")
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// tlsConfig serves the certificate of certDir, reloaded on changes, or a self-signed certificate
+func (s *Server) tlsConfig(ctx context.Context) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if s.certDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(s.certDir, "tls.crt"), filepath.Join(s.certDir, "tls.key"))
+		if err != nil {
+			return nil, fmt.Errorf("loading operator api server certificate: %w", err)
+		}
+
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				logf.FromContext(ctx).Error(err, "watching operator api server certificate")
+			}
+		}()
+
+		cfg.GetCertificate = watcher.GetCertificate
+
+		return cfg, nil
+	}
+
+	hostname, _ := os.Hostname() //nolint:errcheck
+
+	cert, key, err := certutil.GenerateSelfSignedCertKey("localhost", []net.IP{{127, 0, 0, 1}}, []string{hostname})
+	if err != nil {
+		return nil, fmt.Errorf("generating self-signed certificate: %w", err)
+	}
+
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Certificates = []tls.Certificate{keyPair}
+
+	return cfg, nil
+}
+
+// Handler returns the http.Handler serving the API endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/instances", s.handleInstances)
+	mux.HandleFunc("GET /api/v1/resources", s.handleResources)
+	mux.HandleFunc("GET /api/v1/graph", s.handleGraph)
+
+	return s.withAuthentication(mux)
+}
+
+// handleInstances lists Grafana instances, optionally filtered by the namespace query parameter
+func (s *Server) handleInstances(w http.ResponseWriter, r *http.Request) {
+	grafanas, err := s.listGrafanas(r.Context(), r.URL.Query().Get("namespace"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	instances := make([]Instance, 0, len(grafanas))
+	for _, grafana := range grafanas {
+		instances = append(instances, Instance{
+			Namespace:   grafana.Namespace,
+			Name:        grafana.Name,
+			External:    grafana.IsExternal(),
+			Version:     grafana.Status.Version,
+			AdminURL:    grafana.Status.AdminURL,
+			Stage:       string(grafana.Status.Stage),
+			StageStatus: string(grafana.Status.StageStatus),
+			LastMessage: grafana.Status.LastMessage,
+			Conditions:  grafana.Status.Conditions,
+		})
+	}
+
+	writeJSON(w, instances)
+}
+
+// handleResources lists resources and the instances they are applied to.
+// Supported query parameters are namespace, kind and instance (<namespace>/<name>)
+func (s *Server) handleResources(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	namespace := query.Get("namespace")
+	kind := query.Get("kind")
+	instance := query.Get("instance")
+
+	// Instances from all namespaces are needed for cross namespace imports
+	grafanas, err := s.listGrafanas(r.Context(), "")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	objects, err := s.listObjects(r.Context(), namespace, kind)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resources := make([]Resource, 0, len(objects))
+
+	for _, o := range objects {
+		resource := newResource(o.kind, o.cr, grafanas)
+		if instance != "" && !slices.Contains(resource.Instances, instance) {
+			continue
+		}
+
+		resources = append(resources, resource)
+	}
+
+	writeJSON(w, resources)
+}
+
+type object struct {
+	kind string
+	cr   v1beta1.CommonResource
+}
+
+// listObjects lists the resources of all kinds, or only the given kind, in the namespace or all namespaces.
+// Resources the caller may not list are left out
+func (s *Server) listObjects(ctx context.Context, namespace, kind string) ([]object, error) {
+	objects := make([]object, 0)
+	caller := accessFromContext(ctx)
+
+	for _, rk := range resourceKinds {
+		if kind != "" && !strings.EqualFold(kind, rk.kind) {
+			continue
+		}
+
+		list := rk.list()
+
+		opts := []client.ListOption{}
+		if namespace != "" {
+			opts = append(opts, client.InNamespace(namespace))
+		}
+
+		if err := s.client.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range items {
+			cr, ok := o.(v1beta1.CommonResource)
+			if !ok {
+				continue
+			}
+
+			allowed, err := caller.canList(ctx, rk.resource, cr.GetNamespace())
+			if err != nil {
+				return nil, err
+			}
+
+			if !allowed {
+				continue
+			}
+
+			objects = append(objects, object{kind: rk.kind, cr: cr})
+		}
+	}
+
+	return objects, nil
+}
+
+// listGrafanas lists the instances in the namespace or all namespaces the caller may list
+func (s *Server) listGrafanas(ctx context.Context, namespace string) ([]v1beta1.Grafana, error) {
+	var list v1beta1.GrafanaList
+
+	caller := accessFromContext(ctx)
+
+	opts := []client.ListOption{}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	if err := s.client.List(ctx, &list, opts...); err != nil {
+		return nil, err
+	}
+
+	visible := make([]v1beta1.Grafana, 0, len(list.Items))
+
+	for _, grafana := range list.Items {
+		allowed, err := caller.canList(ctx, "grafanas", grafana.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowed {
+			visible = append(visible, grafana)
+		}
+	}
+
+	grafanas, _, err := provision.ResolveClasses(ctx, s.client, visible)
+
+	return grafanas, err
+}
+
+func newResource(kind string, cr v1beta1.CommonResource, grafanas []v1beta1.Grafana) Resource {
+	status := cr.CommonStatus()
+
+	resource := Resource{
+		Kind:       kind,
+		Namespace:  cr.GetNamespace(),
+		Name:       cr.GetName(),
+		Instances:  make([]string, 0),
+		Conditions: status.Conditions,
+		LastError:  lastError(status.Conditions),
+	}
+
+	if !status.LastResync.IsZero() {
+		resource.LastResync = &status.LastResync
+	}
+
+	for _, c := range status.Conditions {
+		if c.Type == "Suspended" && c.Status == metav1.ConditionTrue {
+			resource.Suspended = true
+		}
+	}
+
+	for _, grafana := range grafanas {
+		statusList, _, err := grafana.Status.StatusList(cr)
+		if err != nil {
+			continue
+		}
+
+		if found, _ := statusList.Find(cr.GetNamespace(), cr.GetName()); found {
+			resource.Instances = append(resource.Instances, grafana.Namespace+"/"+grafana.Name)
+		}
+	}
+
+	return resource
+}
+
+// lastError returns the message of the first condition reporting a problem
+func lastError(conditions []metav1.Condition) string {
+	for _, c := range conditions {
+		if strings.HasSuffix(c.Type, "Synchronized") && c.Status == metav1.ConditionFalse {
+			return c.Message
+		}
+	}
+
+	for _, c := range conditions {
+		if c.Status == metav1.ConditionTrue && (c.Type == "InvalidSpec" || c.Type == "NoMatchingInstance" || c.Type == "NoMatchingFolder") {
+			return c.Message
+		}
+	}
+
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,258 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const (
+	// adminToken may list everything, teamAToken only dashboards in team-a
+	adminToken = "admin-token"
+	teamAToken = "team-a-token"
+)
+
+// reviews answers TokenReviews and SubjectAccessReviews like the API server would for the test tokens
+var reviews = interceptor.Funcs{
+	Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+		switch review := obj.(type) {
+		case *authenticationv1.TokenReview:
+			switch review.Spec.Token {
+			case adminToken:
+				review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "admin"}}
+			case teamAToken:
+				review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "team-a"}}
+			}
+
+			return nil
+		case *authorizationv1.SubjectAccessReview:
+			attrs := review.Spec.ResourceAttributes
+			review.Status.Allowed = review.Spec.User == "admin" ||
+				(review.Spec.User == "team-a" && attrs.Namespace == "team-a" && attrs.Resource == "grafanadashboards")
+
+			return nil
+		}
+
+		return cl.Create(ctx, obj, opts...)
+	},
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "grafana"},
+		Status: v1beta1.GrafanaStatus{
+			Version:    "12.2.1",
+			Dashboards: v1beta1.NamespacedResourceList{"team-a/synced/uid-1"},
+		},
+	}
+
+	synced := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "synced"},
+	}
+
+	failing := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "failing"},
+		Status: v1beta1.GrafanaDashboardStatus{
+			GrafanaCommonStatus: v1beta1.GrafanaCommonStatus{
+				Conditions: []metav1.Condition{{
+					Type:    "DashboardSynchronized",
+					Status:  metav1.ConditionFalse,
+					Message: "failed to apply",
+				}},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(grafana, synced, failing).
+		WithStatusSubresource(grafana, synced, failing).
+		WithInterceptorFuncs(reviews).
+		Build()
+
+	return New(cl, "", "")
+}
+
+func serve(s *Server, url, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	return rec
+}
+
+func getAs(t *testing.T, s *Server, token, url string, into any) {
+	t.Helper()
+
+	rec := serve(s, url, token)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), into))
+}
+
+func get(t *testing.T, s *Server, url string, into any) {
+	t.Helper()
+
+	getAs(t, s, adminToken, url, into)
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(s, "/api/v1/instances", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(s, "/api/v1/instances", "invalid").Code)
+}
+
+func TestAccessScope(t *testing.T) {
+	s := newTestServer(t)
+
+	var instances []Instance
+	getAs(t, s, teamAToken, "/api/v1/instances", &instances)
+	assert.Empty(t, instances)
+
+	var resources []Resource
+	getAs(t, s, teamAToken, "/api/v1/resources", &resources)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "synced", resources[0].Name)
+	// The instance in monitoring is not visible to the caller
+	assert.Empty(t, resources[0].Instances)
+
+	var graph Graph
+	getAs(t, s, teamAToken, "/api/v1/graph", &graph)
+	require.Len(t, graph.Nodes, 1)
+	assert.Equal(t, "GrafanaDashboard/team-a/synced", graph.Nodes[0].ID)
+}
+
+func TestHandleInstances(t *testing.T) {
+	s := newTestServer(t)
+
+	var instances []Instance
+	get(t, s, "/api/v1/instances", &instances)
+
+	require.Len(t, instances, 1)
+	assert.Equal(t, "grafana", instances[0].Name)
+	assert.Equal(t, "12.2.1", instances[0].Version)
+
+	get(t, s, "/api/v1/instances?namespace=other", &instances)
+	assert.Empty(t, instances)
+}
+
+func TestHandleResources(t *testing.T) {
+	s := newTestServer(t)
+
+	t.Run("relationships and errors", func(t *testing.T) {
+		var resources []Resource
+		get(t, s, "/api/v1/resources?kind=GrafanaDashboard", &resources)
+
+		require.Len(t, resources, 2)
+
+		byName := map[string]Resource{}
+		for _, r := range resources {
+			byName[r.Name] = r
+		}
+
+		assert.Equal(t, []string{"monitoring/grafana"}, byName["synced"].Instances)
+		assert.Empty(t, byName["synced"].LastError)
+		assert.Empty(t, byName["failing"].Instances)
+		assert.Equal(t, "failed to apply", byName["failing"].LastError)
+	})
+
+	t.Run("filter by instance", func(t *testing.T) {
+		var resources []Resource
+		get(t, s, "/api/v1/resources?instance=monitoring/grafana", &resources)
+
+		require.Len(t, resources, 1)
+		assert.Equal(t, "synced", resources[0].Name)
+	})
+
+	t.Run("filter by other kind", func(t *testing.T) {
+		var resources []Resource
+		get(t, s, "/api/v1/resources?kind=GrafanaFolder", &resources)
+
+		assert.Empty(t, resources)
+	})
+}
+
+func TestBuildGraph(t *testing.T) {
+	grafanas := []v1beta1.Grafana{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "grafana"},
+		Status: v1beta1.GrafanaStatus{
+			StageStatus: v1beta1.OperatorStageResultSuccess,
+			Dashboards:  v1beta1.NamespacedResourceList{"team-a/dashboard/uid-1"},
+			Datasources: v1beta1.NamespacedResourceList{"shared/prometheus/uid-2"},
+		},
+	}}
+
+	objects := []object{
+		{kind: "GrafanaFolder", cr: &v1beta1.GrafanaFolder{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "folder"},
+		}},
+		{kind: "GrafanaDashboard", cr: &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "dashboard"},
+			Spec: v1beta1.GrafanaDashboardSpec{
+				FolderRef: "folder",
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+					Datasources: []v1beta1.GrafanaContentDatasource{{InputName: "DS", DatasourceName: "Prometheus"}},
+				},
+			},
+		}},
+		{kind: "GrafanaDatasource", cr: &v1beta1.GrafanaDatasource{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shared", Name: "prometheus"},
+			Spec: v1beta1.GrafanaDatasourceSpec{
+				Datasource: &v1beta1.GrafanaDatasourceInternal{Name: "Prometheus"},
+			},
+		}},
+	}
+
+	graph := buildGraph(grafanas, objects)
+
+	require.Len(t, graph.Nodes, 4)
+	assert.True(t, graph.Nodes[0].Healthy)
+
+	assert.ElementsMatch(t, []GraphEdge{
+		{From: "GrafanaDashboard/team-a/dashboard", To: "Grafana/monitoring/grafana", Relation: RelationAppliedTo},
+		{From: "GrafanaDatasource/shared/prometheus", To: "Grafana/monitoring/grafana", Relation: RelationAppliedTo},
+		{From: "GrafanaDashboard/team-a/dashboard", To: "GrafanaFolder/team-a/folder", Relation: RelationInFolder},
+		{From: "GrafanaDashboard/team-a/dashboard", To: "GrafanaDatasource/shared/prometheus", Relation: RelationUsesDatasource},
+	}, graph.Edges)
+
+	dot := graph.DOT()
+	assert.Contains(t, dot, `"GrafanaDashboard/team-a/dashboard" -> "GrafanaFolder/team-a/folder" [label="inFolder"];`)
+}
+
+func TestHandleGraph(t *testing.T) {
+	s := newTestServer(t)
+
+	var graph Graph
+	get(t, s, "/api/v1/graph", &graph)
+	assert.Len(t, graph.Nodes, 3)
+
+	rec := serve(s, "/api/v1/graph?format=dot", adminToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "digraph grafana {")
+
+	rec = serve(s, "/api/v1/graph?format=svg", adminToken)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
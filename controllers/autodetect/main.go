@@ -2,6 +2,7 @@
 package autodetect
 
 import (
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 )
@@ -11,6 +12,10 @@ var _ AutoDetect = (*autoDetect)(nil)
 // AutoDetect provides an assortment of routines that auto-detect traits based on the runtime.
 type AutoDetect interface {
 	IsOpenshift() (bool, error)
+	HasHTTPRoutes() (bool, error)
+	HasGRPCRoutes() (bool, error)
+	HasMonitors() (bool, error)
+	HasScaledObjects() (bool, error)
 }
 
 type autoDetect struct {
@@ -48,3 +53,47 @@ func (a *autoDetect) IsOpenshift() (bool, error) {
 
 	return false, nil
 }
+
+// HasHTTPRoutes returns whether the Gateway API HTTPRoute resource is served by the cluster.
+func (a *autoDetect) HasHTTPRoutes() (bool, error) {
+	return a.hasResource("gateway.networking.k8s.io/v1", "httproutes")
+}
+
+// HasGRPCRoutes returns whether the Gateway API GRPCRoute resource is served by the cluster.
+func (a *autoDetect) HasGRPCRoutes() (bool, error) {
+	return a.hasResource("gateway.networking.k8s.io/v1", "grpcroutes")
+}
+
+// HasMonitors returns whether the ServiceMonitor and PodMonitor resources of the prometheus-operator are served by the cluster.
+func (a *autoDetect) HasMonitors() (bool, error) {
+	hasServiceMonitors, err := a.hasResource("monitoring.coreos.com/v1", "servicemonitors")
+	if err != nil || !hasServiceMonitors {
+		return false, err
+	}
+
+	return a.hasResource("monitoring.coreos.com/v1", "podmonitors")
+}
+
+// HasScaledObjects returns whether the ScaledObject resource of KEDA is served by the cluster.
+func (a *autoDetect) HasScaledObjects() (bool, error) {
+	return a.hasResource("keda.sh/v1alpha1", "scaledobjects")
+}
+
+func (a *autoDetect) hasResource(groupVersion, name string) (bool, error) {
+	resources, err := a.dcl.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if kuberr.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -52,3 +52,203 @@ func TestDetectPlatformBasedOnAvailableAPIGroups(t *testing.T) {
 		assert.Equal(t, tt.expected, plt)
 	}
 }
+
+func TestDetectHTTPRoutes(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		resources *metav1.APIResourceList
+		expected  bool
+	}{
+		{
+			name:     "gateway api not installed",
+			expected: false,
+		},
+		{
+			name: "httproutes served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "gateway.networking.k8s.io/v1",
+				APIResources: []metav1.APIResource{{Name: "gateways"}, {Name: "httproutes"}},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.resources == nil || req.URL.Path != "/apis/gateway.networking.k8s.io/v1" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				output, err := json.Marshal(tt.resources)
+				assert.NoError(t, err)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write(output)
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			autoDetect, err := autodetect.New(&rest.Config{Host: server.URL})
+			require.NoError(t, err)
+
+			found, err := autoDetect.HasHTTPRoutes()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
+
+func TestDetectGRPCRoutes(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		resources *metav1.APIResourceList
+		expected  bool
+	}{
+		{
+			name:     "gateway api not installed",
+			expected: false,
+		},
+		{
+			name: "only httproutes served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "gateway.networking.k8s.io/v1",
+				APIResources: []metav1.APIResource{{Name: "gateways"}, {Name: "httproutes"}},
+			},
+			expected: false,
+		},
+		{
+			name: "grpcroutes served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "gateway.networking.k8s.io/v1",
+				APIResources: []metav1.APIResource{{Name: "gateways"}, {Name: "httproutes"}, {Name: "grpcroutes"}},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.resources == nil || req.URL.Path != "/apis/gateway.networking.k8s.io/v1" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				output, err := json.Marshal(tt.resources)
+				assert.NoError(t, err)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write(output)
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			autoDetect, err := autodetect.New(&rest.Config{Host: server.URL})
+			require.NoError(t, err)
+
+			found, err := autoDetect.HasGRPCRoutes()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
+
+func TestDetectMonitors(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		resources *metav1.APIResourceList
+		expected  bool
+	}{
+		{
+			name:     "prometheus-operator not installed",
+			expected: false,
+		},
+		{
+			name: "only servicemonitors served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "monitoring.coreos.com/v1",
+				APIResources: []metav1.APIResource{{Name: "prometheuses"}, {Name: "servicemonitors"}},
+			},
+			expected: false,
+		},
+		{
+			name: "monitors served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "monitoring.coreos.com/v1",
+				APIResources: []metav1.APIResource{{Name: "podmonitors"}, {Name: "servicemonitors"}},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.resources == nil || req.URL.Path != "/apis/monitoring.coreos.com/v1" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				output, err := json.Marshal(tt.resources)
+				assert.NoError(t, err)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write(output)
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			autoDetect, err := autodetect.New(&rest.Config{Host: server.URL})
+			require.NoError(t, err)
+
+			found, err := autoDetect.HasMonitors()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
+
+func TestDetectScaledObjects(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		resources *metav1.APIResourceList
+		expected  bool
+	}{
+		{
+			name:     "keda not installed",
+			expected: false,
+		},
+		{
+			name: "scaledobjects served",
+			resources: &metav1.APIResourceList{
+				GroupVersion: "keda.sh/v1alpha1",
+				APIResources: []metav1.APIResource{{Name: "scaledjobs"}, {Name: "scaledobjects"}},
+			},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.resources == nil || req.URL.Path != "/apis/keda.sh/v1alpha1" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				output, err := json.Marshal(tt.resources)
+				assert.NoError(t, err)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write(output)
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+
+			autoDetect, err := autodetect.New(&rest.Config{Host: server.URL})
+			require.NoError(t, err)
+
+			found, err := autoDetect.HasScaledObjects()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
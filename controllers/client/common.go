@@ -35,3 +35,30 @@ func GetValueFromSecretKey(ctx context.Context, ref *v1.SecretKeySelector, c cli
 
 	return nil, fmt.Errorf("credentials not found in secret: %v/%v", namespace, ref.Name)
 }
+
+func GetValueFromConfigMapKey(ctx context.Context, ref *v1.ConfigMapKeySelector, c client.Client, namespace string) ([]byte, error) {
+	if ref == nil {
+		return nil, errors.New("empty config map key selector")
+	}
+
+	cm := &v1.ConfigMap{}
+	selector := client.ObjectKey{
+		Name:      ref.Name,
+		Namespace: namespace,
+	}
+
+	err := c.Get(ctx, selector, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := cm.Data[ref.Key]; ok {
+		return []byte(val), nil
+	}
+
+	if val, ok := cm.BinaryData[ref.Key]; ok {
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("key %s not found in config map: %v/%v", ref.Key, namespace, ref.Name)
+}
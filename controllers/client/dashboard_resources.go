@@ -0,0 +1,203 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DashboardResourceAPIVersion = "dashboard.grafana.app/v1beta1"
+
+	AnnotationFolder         = "grafana.app/folder"
+	AnnotationManagedBy      = "grafana.app/managedBy"
+	AnnotationManagerID      = "grafana.app/managerId"
+	AnnotationSourcePath     = "grafana.app/sourcePath"
+	AnnotationSourceChecksum = "grafana.app/sourceChecksum"
+
+	// ManagerKindKubectl marks resources applied from Kubernetes, Grafana shows them as provisioned and read-only
+	ManagerKindKubectl = "kubectl"
+
+	// ManagerID identifies the operator as the manager of the resources it applies
+	ManagerID = "grafana-operator"
+)
+
+var ErrDashboardResourceNotFound = errors.New("dashboard resource not found")
+
+// DashboardResource is a dashboard of the dashboard.grafana.app API
+type DashboardResource struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Metadata   DashboardResourceMetadata `json:"metadata"`
+	Spec       map[string]any            `json:"spec"`
+}
+
+type DashboardResourceMetadata struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+// DashboardResourceClient applies dashboards through the dashboard.grafana.app API of Grafana 12 and newer
+type DashboardResourceClient struct {
+	httpClient *http.Client
+	baseURL    *url.URL
+	authorize  func(req *http.Request) error
+}
+
+func NewDashboardResourceClient(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*DashboardResourceClient, error) {
+	httpClient, err := NewHTTPClient(ctx, c, grafana)
+	if err != nil {
+		return nil, fmt.Errorf("setup of the http client: %w", err)
+	}
+
+	// The resource APIs are served next to /api
+	gURL, err := ParseAdminURL(grafana.Status.AdminURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := &DashboardResourceClient{
+		httpClient: httpClient,
+		authorize: func(req *http.Request) error {
+			return InjectAuthHeaders(ctx, c, grafana, req)
+		},
+	}
+
+	namespace, err := resources.namespace(ctx, gURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resources.baseURL = gURL.JoinPath("..", "apis", DashboardResourceAPIVersion, "namespaces", namespace, "dashboards")
+
+	return resources, nil
+}
+
+// DashboardResourceNamespace is the namespace of an organization in the resource APIs of self-hosted Grafana
+func DashboardResourceNamespace(orgID int64) string {
+	if orgID == 1 {
+		return "default"
+	}
+
+	return fmt.Sprintf("org-%d", orgID)
+}
+
+// namespace returns the namespace of the organization the requests are sent to, e.g. through X-Grafana-Org-Id.
+// Grafana reports it in the frontend settings, which also covers the stacks-<id> namespaces of Grafana Cloud, versions
+// not reporting it are mapped from the id of the current organization
+func (c *DashboardResourceClient) namespace(ctx context.Context, gURL *url.URL) (string, error) {
+	settings := struct {
+		Namespace string `json:"namespace"`
+	}{}
+
+	err := c.do(ctx, http.MethodGet, gURL.JoinPath("frontend", "settings").String(), nil, &settings)
+	if err != nil && !errors.Is(err, ErrDashboardResourceNotFound) {
+		return "", fmt.Errorf("fetching the namespace of the organization: %w", err)
+	}
+
+	if settings.Namespace != "" {
+		return settings.Namespace, nil
+	}
+
+	org := struct {
+		ID int64 `json:"id"`
+	}{}
+
+	err = c.do(ctx, http.MethodGet, gURL.JoinPath("org").String(), nil, &org)
+	if err != nil {
+		return "", fmt.Errorf("fetching the current organization: %w", err)
+	}
+
+	return DashboardResourceNamespace(org.ID), nil
+}
+
+// Get returns the dashboard with the given name, ErrDashboardResourceNotFound when it does not exist
+func (c *DashboardResourceClient) Get(ctx context.Context, name string) (*DashboardResource, error) {
+	dashboard := &DashboardResource{}
+
+	err := c.do(ctx, http.MethodGet, c.baseURL.JoinPath(name).String(), nil, dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// Create creates the dashboard, Update replaces an existing one and requires its resourceVersion
+func (c *DashboardResourceClient) Create(ctx context.Context, dashboard *DashboardResource) error {
+	return c.do(ctx, http.MethodPost, c.baseURL.String(), dashboard, nil)
+}
+
+func (c *DashboardResourceClient) Update(ctx context.Context, dashboard *DashboardResource) error {
+	return c.do(ctx, http.MethodPut, c.baseURL.JoinPath(dashboard.Metadata.Name).String(), dashboard, nil)
+}
+
+// Delete removes the dashboard with the given name, ErrDashboardResourceNotFound when it does not exist
+func (c *DashboardResourceClient) Delete(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, c.baseURL.JoinPath(name).String(), nil, nil)
+}
+
+func (c *DashboardResourceClient) do(ctx context.Context, method, target string, body, into any) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding dashboard resource: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("building dashboard resource request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	err = c.authorize(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading dashboard resource response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrDashboardResourceNotFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Method: method, Path: req.URL.Path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+
+	if into == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, into)
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardResourceNamespace(t *testing.T) {
+	assert.Equal(t, "default", DashboardResourceNamespace(1))
+	assert.Equal(t, "org-3", DashboardResourceNamespace(3))
+
+	tests := []struct {
+		name     string
+		settings string
+		want     string
+	}{
+		{
+			name:     "reported by the frontend settings",
+			settings: `{"namespace": "stacks-42"}`,
+			want:     "stacks-42",
+		},
+		{
+			name:     "mapped from the current organization",
+			settings: `{}`,
+			want:     "org-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/frontend/settings":
+					w.Write([]byte(tt.settings)) //nolint:errcheck
+				case "/api/org":
+					w.Write([]byte(`{"id": 2, "name": "Team"}`)) //nolint:errcheck
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			gURL, err := url.Parse(srv.URL + "/api")
+			require.NoError(t, err)
+
+			c := &DashboardResourceClient{
+				httpClient: srv.Client(),
+				authorize:  func(*http.Request) error { return nil },
+			}
+
+			namespace, err := c.namespace(t.Context(), gURL)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, namespace)
+		})
+	}
+}
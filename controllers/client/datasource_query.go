@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type datasourceQueryRequest struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Queries []map[string]any `json:"queries"`
+}
+
+type datasourceQueryResponse struct {
+	Results map[string]struct {
+		Error string `json:"error,omitempty"`
+	} `json:"results"`
+	Message string `json:"message,omitempty"`
+}
+
+// QueryDatasources runs the queries through the /api/ds/query endpoint of the instance for the given time range ending now.
+// Every query must hold its datasource and refId. The error messages of failed queries are returned by refId,
+// an error is returned when the request itself fails
+func QueryDatasources(ctx context.Context, c client.Client, grafana *v1beta1.Grafana, queries []map[string]any, timeRange time.Duration) (map[string]string, error) {
+	httpClient, err := NewHTTPClient(ctx, c, grafana)
+	if err != nil {
+		return nil, fmt.Errorf("setup of the http client: %w", err)
+	}
+
+	gURL, err := ParseAdminURL(grafana.Status.AdminURL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	body, err := json.Marshal(datasourceQueryRequest{
+		From:    strconv.FormatInt(now.Add(-timeRange).UnixMilli(), 10),
+		To:      strconv.FormatInt(now.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding datasource queries: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gURL.JoinPath("ds", "query").String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building datasource query request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	err = InjectAuthHeaders(ctx, c, grafana, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading datasource query response: %w", err)
+	}
+
+	// Grafana answers with 400 or 207 along with the results when queries fail
+	result := datasourceQueryResponse{}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusMultiStatus, http.StatusBadRequest:
+		err = json.Unmarshal(data, &result)
+		if err != nil && resp.StatusCode != http.StatusBadRequest {
+			return nil, fmt.Errorf("decoding datasource query response: %w", err)
+		}
+	}
+
+	if len(result.Results) == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		message := result.Message
+		if message == "" {
+			message = strings.TrimSpace(string(data))
+		}
+
+		return nil, fmt.Errorf("datasource query failed with status %d: %s", resp.StatusCode, message)
+	}
+
+	failures := make(map[string]string)
+
+	for refID, res := range result.Results {
+		if res.Error != "" {
+			failures[refID] = res.Error
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Reasons of failed Grafana API requests, used in conditions and metrics.
+// They are part of the API of the operator, alerts and dashboards aggregate on them, don't rename them
+const (
+	ReasonAuthFailed         = "AuthFailed"
+	ReasonNotFound           = "NotFound"
+	ReasonConflict           = "Conflict"
+	ReasonValidationRejected = "ValidationRejected"
+	ReasonRateLimited        = "RateLimited"
+)
+
+// StatusError is returned for a Grafana API response with an unexpected status code
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// IsCode matches the errors of the generated Grafana API client
+func (e *StatusError) IsCode(code int) bool {
+	return e.StatusCode == code
+}
+
+// coder is implemented by the errors of the generated Grafana API client and StatusError
+type coder interface {
+	IsCode(code int) bool
+}
+
+// ClassifyError returns the reason of a failed Grafana API request, an empty string for
+// errors without a known status code, e.g. network errors or server errors
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, ErrDashboardResourceNotFound) {
+		return ReasonNotFound
+	}
+
+	var c coder
+	if !errors.As(err, &c) {
+		return ""
+	}
+
+	switch {
+	case c.IsCode(http.StatusUnauthorized), c.IsCode(http.StatusForbidden):
+		return ReasonAuthFailed
+	case c.IsCode(http.StatusNotFound):
+		return ReasonNotFound
+	case c.IsCode(http.StatusConflict), c.IsCode(http.StatusPreconditionFailed):
+		return ReasonConflict
+	case c.IsCode(http.StatusBadRequest), c.IsCode(http.StatusUnprocessableEntity):
+		return ReasonValidationRejected
+	case c.IsCode(http.StatusTooManyRequests):
+		return ReasonRateLimited
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/grafana/grafana-openapi-client-go/client/folders"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "network error", err: errors.New("dial tcp: connection refused"), want: ""},
+		{name: "generated client forbidden", err: folders.NewCreateFolderForbidden(), want: ReasonAuthFailed},
+		{name: "generated client conflict", err: fmt.Errorf("creating folder: %w", folders.NewCreateFolderConflict()), want: ReasonConflict},
+		{name: "generated client bad request", err: folders.NewCreateFolderBadRequest(), want: ReasonValidationRejected},
+		{name: "unexpected status", err: runtime.NewAPIError("unknown error", nil, http.StatusUnauthorized), want: ReasonAuthFailed},
+		{name: "server error", err: runtime.NewAPIError("unknown error", nil, http.StatusInternalServerError), want: ""},
+		{name: "rate limited", err: &StatusError{StatusCode: http.StatusTooManyRequests}, want: ReasonRateLimited},
+		{name: "precondition failed", err: &StatusError{StatusCode: http.StatusPreconditionFailed}, want: ReasonConflict},
+		{name: "unprocessable", err: &StatusError{StatusCode: http.StatusUnprocessableEntity}, want: ReasonValidationRejected},
+		{name: "dashboard resource not found", err: ErrDashboardResourceNotFound, want: ReasonNotFound},
+		{name: "not found", err: &StatusError{StatusCode: http.StatusNotFound}, want: ReasonNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
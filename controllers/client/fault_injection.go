@@ -0,0 +1,58 @@
+package client
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FaultInjection adds latency and errors to the requests sent to Grafana instances,
+// it exercises the error handling of the controllers during development and must not be enabled in production
+type FaultInjection struct {
+	// Latency added to every request
+	Latency time.Duration
+	// Fraction of requests, between 0 and 1, answered with 503 Service Unavailable instead of being sent
+	ErrorRate float64
+}
+
+var (
+	faultInjection FaultInjection
+	faultRand      = rand.Float64
+)
+
+// SetFaultInjection configures the faults injected into all Grafana clients created afterwards
+func SetFaultInjection(f FaultInjection) {
+	faultInjection = f
+}
+
+// inject delays the request and returns a synthetic response when the request is selected to fail.
+// A nil response and error lets the request through
+func (f FaultInjection) inject(r *http.Request) (*http.Response, error) {
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+	}
+
+	if f.ErrorRate <= 0 || faultRand() >= f.ErrorRate {
+		return nil, nil
+	}
+
+	body := "fault injected by grafana-operator"
+
+	return &http.Response{
+		Status:        "503 Service Unavailable",
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/plain"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
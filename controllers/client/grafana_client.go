@@ -15,9 +15,11 @@ import (
 	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
 	"github.com/grafana/grafana-operator/v5/controllers/metrics"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -134,7 +136,20 @@ func getExternalAdminPassword(ctx context.Context, c client.Client, cr *v1beta1.
 	return "", fmt.Errorf("password not set for external instance: %s/%s", cr.Namespace, cr.Name)
 }
 
+// getAdminCredentials reads the credentials of grafana and keeps them out of logs and error messages
 func getAdminCredentials(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*grafanaAdminCredentials, error) {
+	credentials, err := readAdminCredentials(ctx, c, grafana)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.RegisterSecret(credentials.apikey)
+	logging.RegisterSecret(credentials.adminPassword)
+
+	return credentials, nil
+}
+
+func readAdminCredentials(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*grafanaAdminCredentials, error) {
 	credentials := &grafanaAdminCredentials{}
 
 	if grafana.Spec.Client != nil && grafana.Spec.Client.UseKubeAuth {
@@ -176,18 +191,12 @@ func getAdminCredentials(ctx context.Context, c client.Client, grafana *v1beta1.
 		return credentials, nil
 	}
 
-	deployment := model.GetGrafanaDeployment(grafana, nil)
-	selector := client.ObjectKey{
-		Namespace: deployment.Namespace,
-		Name:      deployment.Name,
-	}
-
-	err := c.Get(ctx, selector, deployment)
+	template, err := getPodTemplate(ctx, c, grafana)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, container := range deployment.Spec.Template.Spec.Containers {
+	for _, container := range template.Spec.Containers {
 		for _, env := range container.Env {
 			if env.Name == config.GrafanaAdminUserEnvVar {
 				if env.Value != "" {
@@ -282,6 +291,7 @@ func NewGeneratedGrafanaClient(ctx context.Context, c client.Client, grafana *v1
 		"instance_namespace": grafana.Namespace,
 		"instance_name":      grafana.Name,
 	}))
+	transport.(*instrumentedRoundTripper).addDurations(metrics.GrafanaAPIRequestDuration.MustCurryWith(metrics.InstanceLabels(grafana.Namespace, grafana.Name))) //nolint:errcheck
 	if grafana.Spec.Client != nil && grafana.Spec.Client.Headers != nil {
 		transport.(*instrumentedRoundTripper).addHeaders(grafana.Spec.Client.Headers) //nolint:errcheck
 	}
@@ -321,3 +331,26 @@ func NewGeneratedGrafanaClient(ctx context.Context, c client.Client, grafana *v1
 
 	return cl, nil
 }
+
+// getPodTemplate returns the pod template of the deployment or statefulset running the instance
+func getPodTemplate(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*corev1.PodTemplateSpec, error) {
+	if grafana.IsStatefulSet() {
+		statefulSet := model.GetGrafanaStatefulSet(grafana, nil)
+
+		err := c.Get(ctx, client.ObjectKeyFromObject(statefulSet), statefulSet)
+		if err != nil {
+			return nil, err
+		}
+
+		return &statefulSet.Spec.Template, nil
+	}
+
+	deployment := model.GetGrafanaDeployment(grafana, nil)
+
+	err := c.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deployment.Spec.Template, nil
+}
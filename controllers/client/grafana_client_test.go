@@ -2,8 +2,12 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -418,3 +422,111 @@ func TestGetBearerToken(t *testing.T) {
 		require.Equal(t, tokenExpiration, jwtCache.Expiration)
 	})
 }
+
+func TestFaultInjection(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	t.Cleanup(func() {
+		SetFaultInjection(FaultInjection{})
+		faultRand = rand.Float64
+	})
+
+	cl := &http.Client{Transport: NewInstrumentedRoundTripper(false, nil)}
+
+	SetFaultInjection(FaultInjection{Latency: 50 * time.Millisecond, ErrorRate: 0.5})
+
+	faultRand = func() float64 { return 0.2 }
+	start := time.Now()
+	resp, err := cl.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 0, requests)
+
+	faultRand = func() float64 { return 0.7 }
+	resp, err = cl.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, requests)
+}
+
+func TestRoundTripDoesNotModifyRequest(t *testing.T) {
+	var userAgent string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	transport := NewInstrumentedRoundTripper(false, nil)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, userAgent, "grafana-operator/")
+	assert.Empty(t, req.Header, "headers are set on a clone")
+}
+
+func TestTLSPolicy(t *testing.T) {
+	t.Run("parses cipher suites by name", func(t *testing.T) {
+		suites, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", " TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"})
+		require.NoError(t, err)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, suites)
+
+		_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+		require.Error(t, err, "insecure cipher suites are rejected")
+
+		_, err = ParseTLSVersion("1.1")
+		require.Error(t, err)
+	})
+
+	t.Run("instances override the operator wide policy", func(t *testing.T) {
+		SetTLSPolicy(tls.VersionTLS13, nil)
+		defer SetTLSPolicy(tls.VersionTLS12, nil)
+
+		grafana := &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grafana"},
+			Spec: v1beta1.GrafanaSpec{
+				Client: &v1beta1.GrafanaClient{
+					TLS: &v1beta1.TLSConfig{
+						MinVersion:   "1.2",
+						CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+					},
+				},
+			},
+		}
+
+		cfg, err := buildTLSConfiguration(context.Background(), fake.NewClientBuilder().Build(), grafana)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion, "the operator wide minimum version is a floor")
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+
+		SetTLSPolicy(tls.VersionTLS12, nil)
+		grafana.Spec.Client.TLS.MinVersion = "1.3"
+
+		cfg, err = buildTLSConfiguration(context.Background(), fake.NewClientBuilder().Build(), grafana)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+
+		SetTLSPolicy(tls.VersionTLS13, nil)
+
+		grafana.Spec.Client.TLS = &v1beta1.TLSConfig{InsecureSkipVerify: true}
+
+		cfg, err = buildTLSConfiguration(context.Background(), fake.NewClientBuilder().Build(), grafana)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+		assert.True(t, cfg.InsecureSkipVerify)
+	})
+}
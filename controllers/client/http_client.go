@@ -28,6 +28,7 @@ func NewHTTPClient(ctx context.Context, c client.Client, grafana *v1beta1.Grafan
 		"instance_namespace": grafana.Namespace,
 		"instance_name":      grafana.Name,
 	}))
+	transport.(*instrumentedRoundTripper).addDurations(metrics.GrafanaAPIRequestDuration.MustCurryWith(metrics.InstanceLabels(grafana.Namespace, grafana.Name))) //nolint:errcheck
 	if grafana.Spec.Client != nil && grafana.Spec.Client.Headers != nil {
 		transport.(*instrumentedRoundTripper).addHeaders(grafana.Spec.Client.Headers) //nolint:errcheck
 	}
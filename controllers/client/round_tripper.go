@@ -6,6 +6,7 @@ import (
 	"maps"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/embeds"
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,6 +18,8 @@ type instrumentedRoundTripper struct {
 	wrapped http.RoundTripper
 	headers map[string]string
 	metrics []*prometheus.CounterVec
+	// durations observes request durations by method, nil when not instrumented
+	durations prometheus.ObserverVec
 }
 
 func NewInstrumentedRoundTripper(useProxy bool, tlsConfig *tls.Config, metrics ...*prometheus.CounterVec) http.RoundTripper {
@@ -27,6 +30,8 @@ func NewInstrumentedRoundTripper(useProxy bool, tlsConfig *tls.Config, metrics .
 
 	if tlsConfig != nil {
 		transport.TLSClientConfig = tlsConfig
+	} else {
+		transport.TLSClientConfig = DefaultTLSConfiguration.Clone()
 	}
 
 	if !useProxy {
@@ -45,12 +50,30 @@ func NewInstrumentedRoundTripper(useProxy bool, tlsConfig *tls.Config, metrics .
 
 func (in *instrumentedRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	if in.headers != nil {
+		// RoundTrippers must not modify the request of the caller
+		r = r.Clone(r.Context())
+
 		for k, v := range in.headers {
 			r.Header.Add(k, v)
 		}
 	}
 
-	resp, err := in.wrapped.RoundTrip(r)
+	start := time.Now()
+
+	resp, err := faultInjection.inject(r)
+	if resp == nil && err == nil {
+		resp, err = in.wrapped.RoundTrip(r)
+	}
+
+	if in.durations != nil {
+		o, err := in.durations.GetMetricWith(prometheus.Labels{"method": r.Method})
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed constructing metric", "err", err)
+		} else {
+			o.Observe(time.Since(start).Seconds())
+		}
+	}
+
 	if resp != nil {
 		for _, m := range in.metrics {
 			c, err := m.GetMetricWith(prometheus.Labels{
@@ -80,3 +103,7 @@ func (in *instrumentedRoundTripper) addHeaders(headers map[string]string) {
 
 	maps.Copy(in.headers, headers)
 }
+
+func (in *instrumentedRoundTripper) addDurations(durations prometheus.ObserverVec) {
+	in.durations = durations
+}
@@ -5,8 +5,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -14,8 +17,82 @@ import (
 var (
 	DefaultTLSConfiguration  = &tls.Config{MinVersion: tls.VersionTLS12}
 	InsecureTLSConfiguration = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: true} // #nosec G402 - Linter disabled because InsecureSkipVerify is the wanted behavior for this variable
+
+	tlsVersions = map[string]uint16{
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
 )
 
+// SetTLSPolicy sets the minimum TLS version and the TLS 1.2 cipher suites of all connections to Grafana instances
+// and content sources, instances can override them in their tls settings. nil cipher suites use the Go defaults.
+// Must be called before any client is created
+func SetTLSPolicy(minVersion uint16, cipherSuites []uint16) {
+	for _, cfg := range []*tls.Config{DefaultTLSConfiguration, InsecureTLSConfiguration} {
+		cfg.MinVersion = minVersion
+		cfg.CipherSuites = cipherSuites
+	}
+}
+
+// ParseTLSVersion returns the TLS version of "1.2" or "1.3"
+func ParseTLSVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q, must be 1.2 or 1.3", version)
+	}
+
+	return v, nil
+}
+
+// ParseCipherSuites returns the cipher suites of the given IANA names, suites with known security issues are rejected
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	suites := make(map[string]uint16, len(tls.CipherSuites()))
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite %q", name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// applyInstanceTLSPolicy overrides the operator wide TLS version and cipher suites with the ones of the instance.
+// The minimum version of the instance can only raise the operator wide floor
+func applyInstanceTLSPolicy(cfg *tls.Config, tlsConfigBlock *v1beta1.TLSConfig) error {
+	if tlsConfigBlock.MinVersion != "" {
+		v, err := ParseTLSVersion(tlsConfigBlock.MinVersion)
+		if err != nil {
+			return err
+		}
+
+		cfg.MinVersion = max(cfg.MinVersion, v)
+	}
+
+	if len(tlsConfigBlock.CipherSuites) > 0 {
+		suites, err := ParseCipherSuites(tlsConfigBlock.CipherSuites)
+		if err != nil {
+			return err
+		}
+
+		cfg.CipherSuites = suites
+	}
+
+	return nil
+}
+
 // build the tls.Config object based on the content of the Grafana CR object
 func buildTLSConfiguration(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*tls.Config, error) {
 	var tlsConfigBlock *v1beta1.TLSConfig
@@ -27,16 +104,33 @@ func buildTLSConfiguration(ctx context.Context, c client.Client, grafana *v1beta
 	case grafana.Spec.External != nil && grafana.Spec.External.TLS != nil:
 		// fall back to external tls field if set
 		tlsConfigBlock = grafana.Spec.External.TLS
+	case grafana.UsesServingCert() && !grafana.PreferIngress():
+		// the service serves the certificate issued by the OpenShift service CA
+		return buildServiceCATLSConfiguration(ctx, c, grafana)
 	default:
 		// if nothing is specified, ignore tls settings
 		return nil, nil
 	}
 
 	if tlsConfigBlock.InsecureSkipVerify {
-		return InsecureTLSConfiguration, nil
+		tlsConfig := InsecureTLSConfiguration.Clone()
+		if err := applyInstanceTLSPolicy(tlsConfig, tlsConfigBlock); err != nil {
+			return nil, err
+		}
+
+		return tlsConfig, nil
+	}
+
+	tlsConfig := DefaultTLSConfiguration.Clone()
+	if err := applyInstanceTLSPolicy(tlsConfig, tlsConfigBlock); err != nil {
+		return nil, err
+	}
+
+	// Only the TLS version or cipher suites are configured
+	if tlsConfigBlock.CertSecretRef == nil {
+		return tlsConfig, nil
 	}
 
-	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
 	secretName := tlsConfigBlock.CertSecretRef.Name
 
 	secretNamespace := grafana.Namespace
@@ -84,3 +178,28 @@ func buildTLSConfiguration(ctx context.Context, c client.Client, grafana *v1beta
 
 	return tlsConfig, nil
 }
+
+// buildServiceCATLSConfiguration trusts the CA bundle the OpenShift service CA injects into the service CA ConfigMap
+func buildServiceCATLSConfiguration(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*tls.Config, error) {
+	configMap := model.GetGrafanaServiceCAConfigMap(grafana, nil)
+
+	err := c.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	if err != nil {
+		return nil, fmt.Errorf("fetching service CA: %w", err)
+	}
+
+	ca, ok := configMap.Data[config.GrafanaServiceCAKey]
+	if !ok {
+		return nil, fmt.Errorf("service CA not injected into configmap %s/%s yet", configMap.Namespace, configMap.Name)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM([]byte(ca)) {
+		return nil, fmt.Errorf("failed to add %s from the configmap %s/%s", config.GrafanaServiceCAKey, configMap.Namespace, configMap.Name)
+	}
+
+	tlsConfig := DefaultTLSConfiguration.Clone()
+	tlsConfig.RootCAs = caCertPool
+
+	return tlsConfig, nil
+}
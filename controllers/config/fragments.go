@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ParseIni reads a grafana.ini fragment into settings by section, settings before the first section belong to global
+func ParseIni(data string) (map[string]map[string]string, error) {
+	cfg := make(map[string]map[string]string)
+	section := "global"
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, ";") || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") || len(text) < 3 {
+				return nil, fmt.Errorf("line %d: invalid section %q", line, text)
+			}
+
+			section = strings.TrimSpace(text[1 : len(text)-1])
+
+			continue
+		}
+
+		key, value, found := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected key = value, got %q", line, text)
+		}
+
+		if cfg[section] == nil {
+			cfg[section] = make(map[string]string)
+		}
+
+		cfg[section][key] = strings.TrimSpace(value)
+	}
+
+	return cfg, scanner.Err()
+}
+
+// WithFragments returns the settings of the fragments overridden by cfg, later fragments override earlier ones
+func WithFragments(cfg map[string]map[string]string, fragments []map[string]map[string]string) map[string]map[string]string {
+	if len(fragments) == 0 {
+		return cfg
+	}
+
+	merged := make(map[string]map[string]string)
+
+	for _, layer := range slices.Concat(fragments, []map[string]map[string]string{cfg}) {
+		for section, values := range layer {
+			maps.Copy(getSection(merged, section), values)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIni(t *testing.T) {
+	cfg, err := ParseIni(`
+instance_name = grafana
+; comment
+[server]
+root_url = https://grafana.example.com/
+# comment
+[auth.generic_oauth]
+enabled=true
+scopes = openid profile email
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]map[string]string{
+		"global":             {"instance_name": "grafana"},
+		"server":             {"root_url": "https://grafana.example.com/"},
+		"auth.generic_oauth": {"enabled": "true", "scopes": "openid profile email"},
+	}, cfg)
+
+	_, err = ParseIni("[server\nroot_url = x")
+	require.ErrorContains(t, err, "line 1: invalid section")
+
+	_, err = ParseIni("[server]\nroot_url")
+	require.ErrorContains(t, err, "line 2: expected key = value")
+}
+
+func TestWithFragments(t *testing.T) {
+	platform := map[string]map[string]string{
+		"server": {"root_url": "https://platform.example.com/", "enable_gzip": "true"},
+		"log":    {"level": "info"},
+	}
+	team := map[string]map[string]string{
+		"server": {"root_url": "https://team.example.com/"},
+		"log":    {"level": "debug"},
+	}
+	cfg := map[string]map[string]string{
+		"log": {"level": "warn"},
+	}
+
+	got := WithFragments(cfg, []map[string]map[string]string{platform, team})
+
+	assert.Equal(t, map[string]map[string]string{
+		"server": {"root_url": "https://team.example.com/", "enable_gzip": "true"},
+		"log":    {"level": "warn"},
+	}, got)
+	assert.Equal(t, map[string]string{"level": "warn"}, cfg["log"], "spec.config must not be modified")
+	assert.Equal(t, "https://platform.example.com/", platform["server"]["root_url"])
+
+	assert.Equal(t, cfg, WithFragments(cfg, nil))
+}
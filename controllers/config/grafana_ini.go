@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"maps"
 	"sort"
 	"strings"
 )
@@ -105,3 +106,24 @@ func GetHash(cfg string) string {
 
 	return fmt.Sprintf("%x", hash.Sum(nil))
 }
+
+// cloneConfig returns a copy of cfg whose sections can be changed without changing cfg
+func cloneConfig(cfg map[string]map[string]string) map[string]map[string]string {
+	cloned := make(map[string]map[string]string, len(cfg))
+	for section, settings := range cfg {
+		cloned[section] = maps.Clone(settings)
+	}
+
+	return cloned
+}
+
+// getSection returns the section of cfg, creating it when missing
+func getSection(cfg map[string]map[string]string, name string) map[string]string {
+	section := cfg[name]
+	if section == nil {
+		section = make(map[string]string)
+		cfg[name] = section
+	}
+
+	return section
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// WithExternalImageStorage returns cfg with the [external_image_storage] sections of the provider of storage,
+// settings of storage take precedence over spec.config. Credentials are not part of the ini,
+// they are passed to the Grafana container as env vars or, for the GCS key, as a file
+func WithExternalImageStorage(cfg map[string]map[string]string, storage *v1beta1.GrafanaExternalImageStorage) map[string]map[string]string {
+	if storage == nil {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	setIfNotEmpty := func(section map[string]string, settings map[string]string) {
+		for key, value := range settings {
+			if value != "" {
+				section[key] = value
+			}
+		}
+	}
+
+	switch {
+	case storage.S3 != nil:
+		getSection(merged, "external_image_storage")["provider"] = "s3"
+
+		setIfNotEmpty(getSection(merged, "external_image_storage.s3"), map[string]string{
+			"bucket":   storage.S3.Bucket,
+			"region":   storage.S3.Region,
+			"path":     storage.S3.Path,
+			"endpoint": storage.S3.Endpoint,
+		})
+	case storage.GCS != nil:
+		getSection(merged, "external_image_storage")["provider"] = "gcs"
+
+		gcs := getSection(merged, "external_image_storage.gcs")
+		setIfNotEmpty(gcs, map[string]string{
+			"bucket":                storage.GCS.Bucket,
+			"path":                  storage.GCS.Path,
+			"signed_url_expiration": storage.GCS.SignedURLExpiration,
+		})
+
+		gcs["enable_signed_urls"] = strconv.FormatBool(storage.GCS.EnableSignedURLs)
+
+		if storage.GCS.KeyFile != nil {
+			gcs["key_file"] = GrafanaGCSKeyFilePath
+		}
+	case storage.AzureBlob != nil:
+		getSection(merged, "external_image_storage")["provider"] = "azure_blob"
+
+		setIfNotEmpty(getSection(merged, "external_image_storage.azure_blob"), map[string]string{
+			"account_name":   storage.AzureBlob.AccountName,
+			"container_name": storage.AzureBlob.ContainerName,
+		})
+	}
+
+	return merged
+}
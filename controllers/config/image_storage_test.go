@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestWithExternalImageStorage(t *testing.T) {
+	t.Run("Config is unchanged without storage", func(t *testing.T) {
+		cfg := map[string]map[string]string{"external_image_storage": {"provider": "webdav"}}
+
+		assert.Equal(t, cfg, WithExternalImageStorage(cfg, nil))
+	})
+
+	t.Run("s3 overrides spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{
+			"external_image_storage":    {"provider": "webdav"},
+			"external_image_storage.s3": {"bucket": "other", "bucket_url": "https://other.s3.amazonaws.com"},
+		}
+
+		got := WithExternalImageStorage(cfg, &v1beta1.GrafanaExternalImageStorage{
+			S3: &v1beta1.GrafanaExternalImageStorageS3{Bucket: "screenshots", Region: "eu-west-1"},
+		})
+
+		assert.Equal(t, "s3", got["external_image_storage"]["provider"])
+		assert.Equal(t, map[string]string{
+			"bucket":     "screenshots",
+			"bucket_url": "https://other.s3.amazonaws.com",
+			"region":     "eu-west-1",
+		}, got["external_image_storage.s3"])
+		assert.Equal(t, "webdav", cfg["external_image_storage"]["provider"], "spec.config must not be modified")
+	})
+
+	t.Run("gcs with key file", func(t *testing.T) {
+		got := WithExternalImageStorage(nil, &v1beta1.GrafanaExternalImageStorage{
+			GCS: &v1beta1.GrafanaExternalImageStorageGCS{
+				Bucket:              "screenshots",
+				KeyFile:             &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gcs"}, Key: "key.json"},
+				EnableSignedURLs:    true,
+				SignedURLExpiration: "7d",
+			},
+		})
+
+		assert.Equal(t, "gcs", got["external_image_storage"]["provider"])
+		assert.Equal(t, map[string]string{
+			"bucket":                "screenshots",
+			"key_file":              GrafanaGCSKeyFilePath,
+			"enable_signed_urls":    "true",
+			"signed_url_expiration": "7d",
+		}, got["external_image_storage.gcs"])
+	})
+
+	t.Run("azure blob", func(t *testing.T) {
+		got := WithExternalImageStorage(nil, &v1beta1.GrafanaExternalImageStorage{
+			AzureBlob: &v1beta1.GrafanaExternalImageStorageAzureBlob{
+				AccountName:   "grafana",
+				ContainerName: "screenshots",
+				AccountKey:    corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "azure"}, Key: "key"},
+			},
+		})
+
+		assert.Equal(t, "azure_blob", got["external_image_storage"]["provider"])
+		assert.Equal(t, map[string]string{"account_name": "grafana", "container_name": "screenshots"}, got["external_image_storage.azure_blob"])
+	})
+}
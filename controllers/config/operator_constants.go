@@ -5,12 +5,25 @@ const (
 	GrafanaImage   = "docker.io/grafana/grafana"
 	GrafanaVersion = "12.2.1"
 
+	// Image renderer
+	GrafanaImageRendererImage         = "docker.io/grafana/grafana-image-renderer"
+	GrafanaImageRendererVersion       = "3.12.1"
+	GrafanaImageRendererContainerName = "grafana-image-renderer"
+
 	// Paths
 	GrafanaDataPath               = "/var/lib/grafana"
 	GrafanaLogsPath               = "/var/log/grafana"
 	GrafanaPluginsPath            = "/var/lib/grafana/plugins"
 	GrafanaProvisioningPath       = "/etc/grafana/provisioning/"
 	GrafanaDashboardsRuntimeBuild = "/tmp/dashboards"
+	GrafanaImageStoragePath       = "/etc/grafana-image-storage"
+	GrafanaGCSKeyFileName         = "gcs-key.json"
+	GrafanaGCSKeyFilePath         = GrafanaImageStoragePath + "/" + GrafanaGCSKeyFileName
+	GrafanaServingCertPath        = "/etc/grafana-serving-cert"
+	GrafanaServiceCAKey           = "service-ca.crt"
+
+	// Env var holding the password of spec.remoteCache, referenced by the ini with $__env
+	GrafanaRemoteCachePasswordEnv = "GRAFANA_REMOTE_CACHE_PASSWORD"
 
 	// Default limits
 	GrafanaDashboardVersionsToKeep = "20"
@@ -24,11 +37,13 @@ const (
 	GrafanaPluginsEnvVar       = "GF_INSTALL_PLUGINS"
 
 	// Networking
-	GrafanaHTTPPort       int = 3000
-	GrafanaHTTPPortName       = "grafana"
-	GrafanaServerProtocol     = "http"
-	GrafanaAlertPort      int = 9094
-	GrafanaAlertPortName      = "grafana-alert"
+	GrafanaHTTPPort              int = 3000
+	GrafanaHTTPPortName              = "grafana"
+	GrafanaServerProtocol            = "http"
+	GrafanaAlertPort             int = 9094
+	GrafanaAlertPortName             = "grafana-alert"
+	GrafanaImageRendererPort     int = 8081
+	GrafanaImageRendererPortName     = "renderer"
 
 	// Data storage
 	GrafanaProvisionPluginVolumeName    = "grafana-provision-plugins"
@@ -37,6 +52,9 @@ const (
 	GrafanaProvisionNotifierVolumeName  = "grafana-provision-notifiers"
 	GrafanaLogsVolumeName               = "grafana-logs"
 	GrafanaDataVolumeName               = "grafana-data"
+	GrafanaImageStorageVolumeName       = "grafana-image-storage"
+	GrafanaImageRendererTmpVolumeName   = "grafana-image-renderer-tmp"
+	GrafanaServingCertVolumeName        = "grafana-serving-cert"
 	SecretsMountDir                     = "/etc/grafana-secrets/" // #nosec G101
 	ConfigMapsMountDir                  = "/etc/grafana-configmaps/"
 )
@@ -0,0 +1,21 @@
+package config
+
+import "github.com/grafana/grafana-operator/v5/api/v1beta1"
+
+// WithPluginPolicy returns cfg without plugins.allow_loading_unsigned_plugins when spec.plugins is set.
+// The unsigned plugins are passed as env var once validated against the declared plugins
+func WithPluginPolicy(cfg map[string]map[string]string, policy *v1beta1.GrafanaPluginPolicy) map[string]map[string]string {
+	if policy == nil {
+		return cfg
+	}
+
+	if _, ok := cfg["plugins"]["allow_loading_unsigned_plugins"]; !ok {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	delete(merged["plugins"], "allow_loading_unsigned_plugins")
+
+	return merged
+}
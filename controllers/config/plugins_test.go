@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPluginPolicy(t *testing.T) {
+	cfg := map[string]map[string]string{"plugins": {"allow_loading_unsigned_plugins": "acme-panel", "enable_alpha": "true"}}
+
+	t.Run("Config is unchanged without a policy", func(t *testing.T) {
+		assert.Equal(t, cfg, WithPluginPolicy(cfg, nil))
+	})
+
+	t.Run("policy overrides the unsigned plugins of spec.config", func(t *testing.T) {
+		got := WithPluginPolicy(cfg, &v1beta1.GrafanaPluginPolicy{SignatureEnforcement: v1beta1.GrafanaPluginSignatureStrict})
+
+		assert.Equal(t, map[string]map[string]string{"plugins": {"enable_alpha": "true"}}, got)
+		assert.Equal(t, "acme-panel", cfg["plugins"]["allow_loading_unsigned_plugins"], "spec.config must not be modified")
+	})
+}
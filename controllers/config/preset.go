@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// Settings of each preset by section. The admin API stays available to the operator through its credentials
+var presets = map[v1beta1.GrafanaPreset]map[string]map[string]string{
+	v1beta1.GrafanaPresetViewerOnly: {
+		"auth.anonymous": {
+			"enabled":      "true",
+			"org_role":     "Viewer",
+			"hide_version": "true",
+		},
+		"users": {
+			"allow_sign_up":    "false",
+			"allow_org_create": "false",
+			"viewers_can_edit": "false",
+		},
+		"explore": {
+			"enabled": "false",
+		},
+		"query_history": {
+			"enabled": "false",
+		},
+		"snapshots": {
+			"enabled":          "false",
+			"external_enabled": "false",
+		},
+	},
+}
+
+// WithPreset returns cfg with the settings of the preset that aren't set in cfg,
+// together with the applied settings as sorted section.key=value entries
+func WithPreset(cfg map[string]map[string]string, preset v1beta1.GrafanaPreset) (map[string]map[string]string, []string) {
+	settings, ok := presets[preset]
+	if !ok {
+		return cfg, nil
+	}
+
+	merged := cloneConfig(cfg)
+
+	applied := make([]string, 0)
+
+	for name, values := range settings {
+		section := getSection(merged, name)
+
+		for key, value := range values {
+			if _, exists := section[key]; exists {
+				continue
+			}
+
+			section[key] = value
+			applied = append(applied, fmt.Sprintf("%s.%s=%s", name, key, value))
+		}
+	}
+
+	slices.Sort(applied)
+
+	return merged, applied
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPreset(t *testing.T) {
+	t.Run("Config is unchanged without preset", func(t *testing.T) {
+		cfg := map[string]map[string]string{"explore": {"enabled": "true"}}
+
+		got, applied := WithPreset(cfg, "")
+
+		assert.Equal(t, cfg, got)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("viewer-only keeps settings of spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{"explore": {"enabled": "true"}}
+
+		got, applied := WithPreset(cfg, v1beta1.GrafanaPresetViewerOnly)
+
+		assert.Equal(t, "true", got["explore"]["enabled"])
+		assert.Equal(t, "Viewer", got["auth.anonymous"]["org_role"])
+		assert.NotContains(t, applied, "explore.enabled=false")
+		assert.Contains(t, applied, "auth.anonymous.enabled=true")
+		assert.IsIncreasing(t, applied)
+		assert.Equal(t, map[string]string{"enabled": "true"}, cfg["explore"], "spec.config must not be modified")
+		assert.Nil(t, cfg["auth.anonymous"])
+	})
+}
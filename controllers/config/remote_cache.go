@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// WithRemoteCache returns cfg with the redis [remote_cache] and, unless disabled, the redis HA engine of [live]
+// of remoteCache, which take precedence over spec.config. The password is interpolated by Grafana from an env var
+func WithRemoteCache(cfg map[string]map[string]string, remoteCache *v1beta1.GrafanaRemoteCache) map[string]map[string]string {
+	if remoteCache == nil {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	password := ""
+	if remoteCache.Password != nil {
+		password = fmt.Sprintf("$__env{%s}", GrafanaRemoteCachePasswordEnv)
+	}
+
+	connstr := []string{
+		"addr=" + remoteCache.Address,
+		fmt.Sprintf("db=%d", remoteCache.DB),
+		fmt.Sprintf("ssl=%t", remoteCache.TLS),
+	}
+
+	if password != "" {
+		connstr = append(connstr, "password="+password)
+	}
+
+	cache := getSection(merged, "remote_cache")
+	cache["type"] = "redis"
+	cache["connstr"] = strings.Join(connstr, ",")
+
+	if remoteCache.Prefix != "" {
+		cache["prefix"] = remoteCache.Prefix
+	}
+
+	if remoteCache.Live != nil && !*remoteCache.Live {
+		return merged
+	}
+
+	live := getSection(merged, "live")
+	live["ha_engine"] = "redis"
+	live["ha_engine_address"] = remoteCache.Address
+
+	if password != "" {
+		live["ha_engine_password"] = password
+	}
+
+	return merged
+}
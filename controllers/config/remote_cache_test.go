@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestWithRemoteCache(t *testing.T) {
+	t.Run("Config is unchanged without remote cache", func(t *testing.T) {
+		cfg := map[string]map[string]string{"remote_cache": {"type": "database"}}
+
+		assert.Equal(t, cfg, WithRemoteCache(cfg, nil))
+	})
+
+	t.Run("redis with password overrides spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{
+			"remote_cache": {"type": "database", "encryption": "true"},
+			"live":         {"max_connections": "200"},
+		}
+
+		got := WithRemoteCache(cfg, &v1beta1.GrafanaRemoteCache{
+			Address:  "redis.cache.svc:6379",
+			Password: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis"}, Key: "password"},
+			DB:       2,
+			Prefix:   "grafana-",
+		})
+
+		assert.Equal(t, map[string]string{
+			"type":       "redis",
+			"connstr":    "addr=redis.cache.svc:6379,db=2,ssl=false,password=$__env{GRAFANA_REMOTE_CACHE_PASSWORD}",
+			"prefix":     "grafana-",
+			"encryption": "true",
+		}, got["remote_cache"])
+		assert.Equal(t, map[string]string{
+			"max_connections":    "200",
+			"ha_engine":          "redis",
+			"ha_engine_address":  "redis.cache.svc:6379",
+			"ha_engine_password": "$__env{GRAFANA_REMOTE_CACHE_PASSWORD}",
+		}, got["live"])
+		assert.Equal(t, "database", cfg["remote_cache"]["type"], "spec.config must not be modified")
+	})
+
+	t.Run("without live", func(t *testing.T) {
+		got := WithRemoteCache(nil, &v1beta1.GrafanaRemoteCache{Address: "valkey:6379", TLS: true, Live: ptr.To(false)})
+
+		assert.Equal(t, "addr=valkey:6379,db=0,ssl=true", got["remote_cache"]["connstr"])
+		assert.NotContains(t, got, "live")
+	})
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"maps"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// Settings of readonly instances, the primary sharing their database executes the alert rules
+var readonlySettings = map[string]map[string]string{
+	"unified_alerting": {
+		"execute_alerts": "false",
+	},
+}
+
+// WithRole returns cfg with the settings of the role applied. Settings of readonly instances override spec.config,
+// alert rules executed by both instances would send every notification twice
+func WithRole(cfg map[string]map[string]string, role v1beta1.GrafanaRole) map[string]map[string]string {
+	if role != v1beta1.GrafanaRoleReadonly {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	for section, values := range readonlySettings {
+		maps.Copy(getSection(merged, section), values)
+	}
+
+	return merged
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRole(t *testing.T) {
+	cfg := map[string]map[string]string{"unified_alerting": {"enabled": "true", "execute_alerts": "true"}}
+
+	t.Run("Config is unchanged for primary instances", func(t *testing.T) {
+		assert.Equal(t, cfg, WithRole(cfg, ""))
+		assert.Equal(t, cfg, WithRole(cfg, v1beta1.GrafanaRolePrimary))
+	})
+
+	t.Run("readonly never executes alert rules", func(t *testing.T) {
+		got := WithRole(cfg, v1beta1.GrafanaRoleReadonly)
+
+		assert.Equal(t, "false", got["unified_alerting"]["execute_alerts"])
+		assert.Equal(t, "true", got["unified_alerting"]["enabled"])
+		assert.Equal(t, "true", cfg["unified_alerting"]["execute_alerts"], "spec.config must not be modified")
+	})
+}
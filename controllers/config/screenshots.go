@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// WithAlertScreenshots returns cfg with the screenshots of alert notifications configured.
+// Screenshots are captured once an image renderer is configured, in spec.config or through spec.imageRenderer, and
+// uploaded once an external image storage is configured, unless capture or upload_external_image_storage are set in
+// spec.config. spec.alertScreenshots.capture takes precedence over spec.config, the storage is configured once in
+// spec.externalImageStorage
+func WithAlertScreenshots(cfg map[string]map[string]string, screenshots *v1beta1.GrafanaAlertScreenshots, imageRenderer bool) map[string]map[string]string {
+	renderer := imageRenderer || cfg["rendering"]["server_url"] != ""
+	storage := cfg["external_image_storage"]["provider"] != ""
+
+	if screenshots == nil && !renderer && !storage {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	section := getSection(merged, "unified_alerting.screenshots")
+
+	if _, ok := section["capture"]; !ok && renderer {
+		section["capture"] = "true"
+	}
+
+	if _, ok := section["upload_external_image_storage"]; !ok && storage {
+		section["upload_external_image_storage"] = "true"
+	}
+
+	if screenshots == nil {
+		return merged
+	}
+
+	if screenshots.Capture != nil {
+		section["capture"] = strconv.FormatBool(*screenshots.Capture)
+	}
+
+	return merged
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestWithAlertScreenshots(t *testing.T) {
+	renderer := map[string]map[string]string{"rendering": {"server_url": "http://renderer:8081/render"}}
+
+	t.Run("Config is unchanged without renderer", func(t *testing.T) {
+		cfg := map[string]map[string]string{"log": {"mode": "console"}}
+
+		assert.Equal(t, cfg, WithAlertScreenshots(cfg, nil, false))
+	})
+
+	t.Run("renderer enables capture", func(t *testing.T) {
+		got := WithAlertScreenshots(renderer, nil, false)
+
+		assert.Equal(t, "true", got["unified_alerting.screenshots"]["capture"])
+		assert.Nil(t, renderer["unified_alerting.screenshots"], "spec.config must not be modified")
+	})
+
+	t.Run("spec.imageRenderer enables capture", func(t *testing.T) {
+		cfg := map[string]map[string]string{"log": {"mode": "console"}}
+
+		got := WithAlertScreenshots(cfg, nil, true)
+
+		assert.Equal(t, "true", got["unified_alerting.screenshots"]["capture"])
+	})
+
+	t.Run("capture disabled in spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{
+			"rendering":                    renderer["rendering"],
+			"unified_alerting.screenshots": {"capture": "false"},
+		}
+
+		got := WithAlertScreenshots(cfg, nil, false)
+
+		assert.Equal(t, "false", got["unified_alerting.screenshots"]["capture"])
+	})
+
+	t.Run("spec.alertScreenshots takes precedence", func(t *testing.T) {
+		cfg := map[string]map[string]string{
+			"rendering":                    renderer["rendering"],
+			"unified_alerting.screenshots": {"capture": "false"},
+		}
+
+		got := WithAlertScreenshots(cfg, &v1beta1.GrafanaAlertScreenshots{Capture: ptr.To(true)}, false)
+
+		assert.Equal(t, "true", got["unified_alerting.screenshots"]["capture"])
+	})
+
+	t.Run("uploaded once a storage is configured", func(t *testing.T) {
+		cfg := map[string]map[string]string{"external_image_storage": {"provider": "s3"}}
+
+		got := WithAlertScreenshots(cfg, nil, false)
+
+		assert.Equal(t, "true", got["unified_alerting.screenshots"]["upload_external_image_storage"])
+		assert.Empty(t, got["unified_alerting.screenshots"]["capture"])
+	})
+
+	t.Run("upload disabled in spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{
+			"external_image_storage":       {"provider": "s3"},
+			"unified_alerting.screenshots": {"upload_external_image_storage": "false"},
+		}
+
+		got := WithAlertScreenshots(cfg, nil, false)
+
+		assert.Equal(t, "false", got["unified_alerting.screenshots"]["upload_external_image_storage"])
+	})
+}
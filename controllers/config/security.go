@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// Defaults applied by spec.security unless set in spec.security or spec.config
+var securityDefaults = map[string]string{
+	"cookie_secure":                        "true",
+	"disable_gravatar":                     "true",
+	"disable_brute_force_login_protection": "false",
+}
+
+// WithSecurity returns cfg with the [security] options of spec.security applied.
+// Explicit spec.security options take precedence over spec.config, which takes precedence over the safe defaults
+func WithSecurity(cfg map[string]map[string]string, security *v1beta1.GrafanaSecurity) map[string]map[string]string {
+	if security == nil {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	section := getSection(merged, "security")
+
+	for key, value := range securityDefaults {
+		if _, ok := section[key]; !ok {
+			section[key] = value
+		}
+	}
+
+	if security.LoginRememberDays != nil {
+		section["login_remember_days"] = strconv.Itoa(*security.LoginRememberDays)
+	}
+
+	if security.CookieSecure != nil {
+		section["cookie_secure"] = strconv.FormatBool(*security.CookieSecure)
+	}
+
+	if security.CookieSameSite != "" {
+		section["cookie_samesite"] = security.CookieSameSite
+	}
+
+	if security.DisableGravatar != nil {
+		section["disable_gravatar"] = strconv.FormatBool(*security.DisableGravatar)
+	}
+
+	if security.ContentSecurityPolicy != nil {
+		section["content_security_policy"] = strconv.FormatBool(*security.ContentSecurityPolicy)
+	}
+
+	if security.ContentSecurityPolicyTemplate != "" {
+		section["content_security_policy_template"] = security.ContentSecurityPolicyTemplate
+	}
+
+	if bf := security.BruteForceProtection; bf != nil {
+		if bf.Enabled != nil {
+			section["disable_brute_force_login_protection"] = strconv.FormatBool(!*bf.Enabled)
+		}
+
+		if bf.MaxAttempts != nil {
+			section["brute_force_login_protection_max_attempts"] = strconv.Itoa(*bf.MaxAttempts)
+		}
+	}
+
+	return merged
+}
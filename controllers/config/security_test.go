@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSecurity(t *testing.T) {
+	t.Run("Config is unchanged without spec.security", func(t *testing.T) {
+		cfg := map[string]map[string]string{"security": {"cookie_secure": "false"}}
+
+		assert.Equal(t, cfg, WithSecurity(cfg, nil))
+	})
+
+	t.Run("Safe defaults are applied unless set in spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{"security": {"cookie_secure": "false"}}
+
+		got := WithSecurity(cfg, &v1beta1.GrafanaSecurity{})
+
+		assert.Equal(t, map[string]string{
+			"cookie_secure":                        "false",
+			"disable_gravatar":                     "true",
+			"disable_brute_force_login_protection": "false",
+		}, got["security"])
+		assert.Equal(t, map[string]string{"cookie_secure": "false"}, cfg["security"], "spec.config must not be modified")
+	})
+
+	t.Run("spec.security takes precedence over spec.config", func(t *testing.T) {
+		days := 3
+		attempts := 10
+		enabled := true
+		disabled := false
+		cfg := map[string]map[string]string{
+			"security": {"cookie_secure": "false", "login_remember_days": "30"},
+			"server":   {"root_url": "https://grafana.example.com"},
+		}
+
+		got := WithSecurity(cfg, &v1beta1.GrafanaSecurity{
+			LoginRememberDays:     &days,
+			CookieSecure:          &enabled,
+			CookieSameSite:        "strict",
+			ContentSecurityPolicy: &enabled,
+			BruteForceProtection: &v1beta1.GrafanaBruteForceProtection{
+				Enabled:     &disabled,
+				MaxAttempts: &attempts,
+			},
+		})
+
+		assert.Equal(t, map[string]string{
+			"cookie_secure":                             "true",
+			"cookie_samesite":                           "strict",
+			"disable_gravatar":                          "true",
+			"content_security_policy":                   "true",
+			"login_remember_days":                       "3",
+			"disable_brute_force_login_protection":      "true",
+			"brute_force_login_protection_max_attempts": "10",
+		}, got["security"])
+		assert.Equal(t, cfg["server"], got["server"])
+	})
+}
@@ -0,0 +1,22 @@
+package config
+
+// WithServingCert returns cfg serving https with the certificate the OpenShift service CA stores in the serving cert
+// Secret. A protocol set in spec.config takes precedence, h2 works with the same certificate
+func WithServingCert(cfg map[string]map[string]string, enabled bool) map[string]map[string]string {
+	if !enabled {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	server := getSection(merged, "server")
+
+	if server["protocol"] == "" {
+		server["protocol"] = "https"
+	}
+
+	server["cert_file"] = GrafanaServingCertPath + "/tls.crt"
+	server["cert_key"] = GrafanaServingCertPath + "/tls.key"
+
+	return merged
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithServingCert(t *testing.T) {
+	cfg := map[string]map[string]string{"server": {"root_url": "https://grafana.example.com"}}
+
+	t.Run("Config is unchanged when disabled", func(t *testing.T) {
+		assert.Equal(t, cfg, WithServingCert(cfg, false))
+	})
+
+	t.Run("serves https with the serving cert", func(t *testing.T) {
+		got := WithServingCert(cfg, true)
+
+		assert.Equal(t, map[string]string{
+			"root_url":  "https://grafana.example.com",
+			"protocol":  "https",
+			"cert_file": "/etc/grafana-serving-cert/tls.crt",
+			"cert_key":  "/etc/grafana-serving-cert/tls.key",
+		}, got["server"])
+		assert.NotContains(t, cfg["server"], "protocol", "spec.config must not be modified")
+	})
+
+	t.Run("protocol of spec.config takes precedence", func(t *testing.T) {
+		got := WithServingCert(map[string]map[string]string{"server": {"protocol": "h2"}}, true)
+
+		assert.Equal(t, "h2", got["server"]["protocol"])
+	})
+}
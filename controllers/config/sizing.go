@@ -0,0 +1,42 @@
+package config
+
+import "github.com/grafana/grafana-operator/v5/api/v1beta1"
+
+// [database] connection pool settings of each sizing, conn_max_lifetime stays below the common idle timeouts of MySQL and PostgreSQL
+var sizingDatabase = map[v1beta1.GrafanaSizing]map[string]string{
+	v1beta1.GrafanaSizingSmall: {
+		"max_open_conn":     "10",
+		"max_idle_conn":     "5",
+		"conn_max_lifetime": "14400",
+	},
+	v1beta1.GrafanaSizingMedium: {
+		"max_open_conn":     "50",
+		"max_idle_conn":     "25",
+		"conn_max_lifetime": "14400",
+	},
+	v1beta1.GrafanaSizingLarge: {
+		"max_open_conn":     "200",
+		"max_idle_conn":     "100",
+		"conn_max_lifetime": "14400",
+	},
+}
+
+// WithSizing returns cfg with the [database] connection pool settings of the sizing that aren't set in cfg
+func WithSizing(cfg map[string]map[string]string, sizing v1beta1.GrafanaSizing) map[string]map[string]string {
+	settings, ok := sizingDatabase[sizing]
+	if !ok {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	section := getSection(merged, "database")
+
+	for key, value := range settings {
+		if _, ok := section[key]; !ok {
+			section[key] = value
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSizing(t *testing.T) {
+	t.Run("Config is unchanged without sizing", func(t *testing.T) {
+		cfg := map[string]map[string]string{"database": {"type": "postgres"}}
+
+		assert.Equal(t, cfg, WithSizing(cfg, ""))
+		assert.Equal(t, cfg, WithSizing(cfg, v1beta1.GrafanaSizingCustom))
+	})
+
+	t.Run("large keeps settings of spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{"database": {"type": "postgres", "max_open_conn": "300"}}
+
+		got := WithSizing(cfg, v1beta1.GrafanaSizingLarge)
+
+		assert.Equal(t, "postgres", got["database"]["type"])
+		assert.Equal(t, "300", got["database"]["max_open_conn"])
+		assert.Equal(t, "100", got["database"]["max_idle_conn"])
+		assert.Equal(t, "14400", got["database"]["conn_max_lifetime"])
+		assert.Equal(t, map[string]string{"type": "postgres", "max_open_conn": "300"}, cfg["database"], "spec.config must not be modified")
+	})
+}
@@ -0,0 +1,20 @@
+package config
+
+import "github.com/grafana/grafana-operator/v5/api/v1beta1"
+
+// WithStatusPage returns cfg signing anonymous users into the organization of the status page as viewers.
+// The settings override spec.config and spec.preset, anonymous users must never reach another organization
+func WithStatusPage(cfg map[string]map[string]string, statusPage *v1beta1.GrafanaStatusPage) map[string]map[string]string {
+	if statusPage == nil {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	section := getSection(merged, "auth.anonymous")
+	section["enabled"] = "true"
+	section["org_name"] = statusPage.OrgName
+	section["org_role"] = "Viewer"
+
+	return merged
+}
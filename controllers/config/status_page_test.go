@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStatusPage(t *testing.T) {
+	t.Run("Config is unchanged without status page", func(t *testing.T) {
+		cfg := map[string]map[string]string{"auth.anonymous": {"enabled": "false"}}
+
+		assert.Equal(t, cfg, WithStatusPage(cfg, nil))
+	})
+
+	t.Run("status page overrides spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{"auth.anonymous": {"enabled": "false", "org_role": "Editor", "hide_version": "true"}}
+
+		got := WithStatusPage(cfg, &v1beta1.GrafanaStatusPage{OrgName: "Status"})
+
+		assert.Equal(t, map[string]string{
+			"enabled":      "true",
+			"org_name":     "Status",
+			"org_role":     "Viewer",
+			"hide_version": "true",
+		}, got["auth.anonymous"])
+		assert.Equal(t, "false", cfg["auth.anonymous"]["enabled"], "spec.config must not be modified")
+	})
+}
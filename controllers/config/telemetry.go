@@ -0,0 +1,38 @@
+package config
+
+import "github.com/grafana/grafana-operator/v5/api/v1beta1"
+
+// Settings disabling everything Grafana reports to or fetches from grafana.com on its own
+var telemetryDisabled = map[string]map[string]string{
+	"analytics": {
+		"enabled":                  "false",
+		"reporting_enabled":        "false",
+		"check_for_updates":        "false",
+		"check_for_plugin_updates": "false",
+		"feedback_links_enabled":   "false",
+	},
+	"news": {
+		"news_feed_enabled": "false",
+	},
+}
+
+// WithTelemetry returns cfg with the telemetry settings that aren't set in cfg when telemetry is disabled
+func WithTelemetry(cfg map[string]map[string]string, telemetry v1beta1.GrafanaTelemetry) map[string]map[string]string {
+	if telemetry != v1beta1.GrafanaTelemetryDisabled {
+		return cfg
+	}
+
+	merged := cloneConfig(cfg)
+
+	for name, settings := range telemetryDisabled {
+		section := getSection(merged, name)
+
+		for key, value := range settings {
+			if _, ok := section[key]; !ok {
+				section[key] = value
+			}
+		}
+	}
+
+	return merged
+}
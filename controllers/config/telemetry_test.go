@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	t.Run("Config is unchanged unless disabled", func(t *testing.T) {
+		cfg := map[string]map[string]string{"analytics": {"reporting_enabled": "true"}}
+
+		assert.Equal(t, cfg, WithTelemetry(cfg, ""))
+		assert.Equal(t, cfg, WithTelemetry(cfg, v1beta1.GrafanaTelemetryEnabled))
+	})
+
+	t.Run("disabled keeps settings of spec.config", func(t *testing.T) {
+		cfg := map[string]map[string]string{"analytics": {"check_for_updates": "true"}}
+
+		got := WithTelemetry(cfg, v1beta1.GrafanaTelemetryDisabled)
+
+		assert.Equal(t, map[string]string{
+			"enabled":                  "false",
+			"reporting_enabled":        "false",
+			"check_for_updates":        "true",
+			"check_for_plugin_updates": "false",
+			"feedback_links_enabled":   "false",
+		}, got["analytics"])
+		assert.Equal(t, "false", got["news"]["news_feed_enabled"])
+		assert.Equal(t, map[string]string{"check_for_updates": "true"}, cfg["analytics"], "spec.config must not be modified")
+	})
+}
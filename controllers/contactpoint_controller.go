@@ -17,21 +17,28 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"time"
 
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	simplejson "github.com/bitly/go-simplejson"
@@ -45,7 +52,13 @@ import (
 
 const (
 	conditionContactPointSynchronized = "ContactPointSynchronized"
+	conditionContactPointTested       = "ContactPointTested"
 	conditionReasonInvalidSettings    = "InvalidSettings"
+	conditionReasonTestSucceeded      = "TestSucceeded"
+	conditionReasonTestFailed         = "TestFailed"
+
+	// Setting the annotation to any value sends a test notification, the operator removes it afterwards
+	annotationTestNotification = "operator.grafana.com/test-notification"
 )
 
 // GrafanaContactPointReconciler reconciles a GrafanaContactPoint object
@@ -73,6 +86,15 @@ func (r *GrafanaContactPointReconciler) Reconcile(ctx context.Context, req ctrl.
 	if contactPoint.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(contactPoint, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, contactPoint)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, contactPoint); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaContactPoint: %w", err)
 			}
@@ -85,7 +107,7 @@ func (r *GrafanaContactPointReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, contactPoint)
+	defer UpdateStatus(ctx, r.Client, contactPoint, contactPoint.DeepCopy())
 
 	if contactPoint.Spec.Suspend {
 		setSuspended(&contactPoint.Status.Conditions, contactPoint.Generation, conditionReasonApplySuspended)
@@ -106,10 +128,23 @@ func (r *GrafanaContactPointReconciler) Reconcile(ctx context.Context, req ctrl.
 		setNoMatchingInstancesCondition(&contactPoint.Status.Conditions, contactPoint.Generation, err)
 		meta.RemoveStatusCondition(&contactPoint.Status.Conditions, conditionContactPointSynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, contactPoint)
 	}
 
 	removeNoMatchingInstance(&contactPoint.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, contactPoint, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&contactPoint.Status.Conditions, contactPoint.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for Contact point", "count", len(instances))
 
 	settings, err := r.buildContactPointSettings(ctx, contactPoint)
@@ -122,12 +157,12 @@ func (r *GrafanaContactPointReconciler) Reconcile(ctx context.Context, req ctrl.
 
 	removeInvalidSpec(&contactPoint.Status.Conditions)
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		err := r.reconcileWithInstance(ctx, &grafana, contactPoint, &settings)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -138,7 +173,175 @@ func (r *GrafanaContactPointReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(contactPoint.Spec.ResyncPeriod)}, nil
+	if _, ok := contactPoint.Annotations[annotationTestNotification]; ok {
+		testErrors := make(map[string]string)
+
+		for _, grafana := range instances {
+			err := r.testContactPoint(ctx, &grafana, contactPoint, settings)
+			if err != nil {
+				testErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			}
+		}
+
+		meta.SetStatusCondition(&contactPoint.Status.Conditions, buildTestedCondition(contactPoint.Generation, testErrors, len(instances)))
+
+		// Remove the annotation so the next test can be requested the same way
+		if err := removeTestNotificationAnnotation(ctx, r.Client, contactPoint); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(contactPoint.Spec.ResyncPeriod, contactPoint.Spec.ResyncJitterPercent), pendingWaves)}, nil
+}
+
+// removeTestNotificationAnnotation removes the test annotation through a copy of the contact point, the response of
+// the patch would otherwise overwrite the conditions computed in this reconcile before the status is written
+func removeTestNotificationAnnotation(ctx context.Context, cl client.Client, contactPoint *grafanav1beta1.GrafanaContactPoint) error {
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": map[string]any{annotationTestNotification: nil}}})
+	if err != nil {
+		return err
+	}
+
+	if err := cl.Patch(ctx, contactPoint.DeepCopy(), client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("removing %s annotation: %w", annotationTestNotification, err)
+	}
+
+	return nil
+}
+
+// testContactPoint sends a test notification through the receivers test endpoint of the instance
+func (r *GrafanaContactPointReconciler) testContactPoint(ctx context.Context, instance *grafanav1beta1.Grafana, contactPoint *grafanav1beta1.GrafanaContactPoint, settings models.JSON) error {
+	cl, err := client2.NewHTTPClient(ctx, r.Client, instance)
+	if err != nil {
+		return fmt.Errorf("setup of the http client: %w", err)
+	}
+
+	gURL, err := client2.ParseAdminURL(instance.Status.AdminURL)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"receivers": []any{map[string]any{
+			"name": contactPoint.Spec.Name,
+			"grafana_managed_receiver_configs": []any{map[string]any{
+				"uid":                   contactPoint.CustomUIDOrUID(),
+				"name":                  contactPoint.Spec.Name,
+				"type":                  contactPoint.Spec.Type,
+				"disableResolveMessage": contactPoint.Spec.DisableResolveMessage,
+				"settings":              settings,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding test request: %w", err)
+	}
+
+	testURL := gURL.JoinPath("/alertmanager/grafana/config/api/v1/receivers/test").String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, testURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building test request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	err = client2.InjectAuthHeaders(ctx, r.Client, instance, req)
+	if err != nil {
+		return fmt.Errorf("fetching credentials for contact point test: %w", err)
+	}
+
+	resp, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading test response: %w", err)
+	}
+
+	return parseTestReceiversResponse(resp.StatusCode, data)
+}
+
+// parseTestReceiversResponse returns the delivery errors reported by Grafana.
+// Grafana answers with 207 Multi-Status when only some integrations failed
+func parseTestReceiversResponse(statusCode int, data []byte) error {
+	result := struct {
+		Message   string `json:"message"`
+		Receivers []struct {
+			Configs []struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			} `json:"grafana_managed_receiver_configs"`
+		} `json:"receivers"`
+	}{}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("test failed with status %d: %s", statusCode, strings.TrimSpace(string(data)))
+	}
+
+	errs := make([]string, 0)
+
+	for _, receiver := range result.Receivers {
+		for _, config := range receiver.Configs {
+			if config.Status != "ok" {
+				errs = append(errs, config.Error)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("delivery failed: %s", strings.Join(errs, ", "))
+	}
+
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("test failed with status %d: %s", statusCode, result.Message)
+	}
+
+	return nil
+}
+
+func buildTestedCondition(generation int64, testErrors map[string]string, total int) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               conditionContactPointTested,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+	}
+
+	if len(testErrors) == 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = conditionReasonTestSucceeded
+		condition.Message = fmt.Sprintf("Test notification was delivered through %d instances at %s", total, condition.LastTransitionTime.Format(time.RFC3339))
+	} else {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonTestFailed
+
+		var sb strings.Builder
+		for i, err := range testErrors {
+			sb.WriteString(fmt.Sprintf("\n- %s: %s", i, err))
+		}
+
+		condition.Message = fmt.Sprintf("Test notification failed for %d out of %d instances at %s. Errors:%s", len(testErrors), total, condition.LastTransitionTime.Format(time.RFC3339), sb.String())
+	}
+
+	return condition
+}
+
+// testNotificationRequested passes updates adding the test notification annotation,
+// which do not change the generation
+func testNotificationRequested() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			newValue, requested := e.ObjectNew.GetAnnotations()[annotationTestNotification]
+			oldValue, alreadyRequested := e.ObjectOld.GetAnnotations()[annotationTestNotification]
+
+			return requested && (!alreadyRequested || oldValue != newValue)
+		},
+	}
 }
 
 func (r *GrafanaContactPointReconciler) reconcileWithInstance(ctx context.Context, instance *grafanav1beta1.Grafana, contactPoint *grafanav1beta1.GrafanaContactPoint, settings *models.JSON) error {
@@ -280,17 +483,27 @@ func (r *GrafanaContactPointReconciler) SetupWithManager(ctx context.Context, mg
 		return fmt.Errorf("failed setting configmap index fields: %w", err)
 	}
 
+	// Index the contact points by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &grafanav1beta1.GrafanaContactPoint{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&grafanav1beta1.GrafanaContactPoint{}, builder.WithPredicates(
-			ignoreStatusUpdates(),
+			predicate.Or(ignoreStatusUpdates(), testNotificationRequested()),
 		)).
 		Watches(
 			&corev1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(secretIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(secretIndexKey), r.Cfg.SyncWindow),
 		).
 		Watches(
 			&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(configMapIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(configMapIndexKey), r.Cfg.SyncWindow),
+		).
+		Watches(
+			&grafanav1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &grafanav1beta1.GrafanaContactPointList{} })),
+			builder.WithPredicates(instanceRecovered()),
 		).
 		Complete(r)
 }
@@ -306,7 +519,7 @@ func (r *GrafanaContactPointReconciler) indexSecretSource() func(o client.Object
 
 		for _, valueFrom := range contactPoint.Spec.ValuesFrom {
 			if valueFrom.ValueFrom.SecretKeyRef != nil {
-				secretRefs = append(secretRefs, fmt.Sprintf("%s/%s", contactPoint.Namespace, valueFrom.ValueFrom.SecretKeyRef.Name))
+				secretRefs = append(secretRefs, fmt.Sprintf("%s/%s", valueFrom.ValueFrom.ResolveNamespace(contactPoint.Namespace), valueFrom.ValueFrom.SecretKeyRef.Name))
 			}
 		}
 
@@ -325,7 +538,7 @@ func (r *GrafanaContactPointReconciler) indexConfigMapSource() func(o client.Obj
 
 		for _, valueFrom := range contactPoint.Spec.ValuesFrom {
 			if valueFrom.ValueFrom.ConfigMapKeyRef != nil {
-				configMapRefs = append(configMapRefs, fmt.Sprintf("%s/%s", contactPoint.Namespace, valueFrom.ValueFrom.ConfigMapKeyRef.Name))
+				configMapRefs = append(configMapRefs, fmt.Sprintf("%s/%s", valueFrom.ValueFrom.ResolveNamespace(contactPoint.Namespace), valueFrom.ValueFrom.ConfigMapKeyRef.Name))
 			}
 		}
 
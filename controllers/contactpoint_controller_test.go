@@ -1,15 +1,22 @@
 package controllers
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var _ = Describe("ContactPoint Reconciler: Provoke Conditions", func() {
@@ -277,3 +284,72 @@ func TestContactPointIndexing(t *testing.T) {
 		require.Empty(t, result)
 	})
 }
+
+func TestParseTestReceiversResponse(t *testing.T) {
+	t.Run("delivered", func(t *testing.T) {
+		err := parseTestReceiversResponse(http.StatusOK, []byte(`{"receivers":[{"name":"cp","grafana_managed_receiver_configs":[{"name":"cp","uid":"cp","status":"ok"}]}]}`))
+		require.NoError(t, err)
+	})
+
+	t.Run("delivery failed", func(t *testing.T) {
+		err := parseTestReceiversResponse(http.StatusMultiStatus, []byte(`{"receivers":[{"name":"cp","grafana_managed_receiver_configs":[{"name":"cp","uid":"cp","status":"failed","error":"connection refused"}]}]}`))
+		require.EqualError(t, err, "delivery failed: connection refused")
+	})
+
+	t.Run("request rejected", func(t *testing.T) {
+		err := parseTestReceiversResponse(http.StatusBadRequest, []byte(`{"message":"invalid settings"}`))
+		require.EqualError(t, err, "test failed with status 400: invalid settings")
+	})
+
+	t.Run("non json response", func(t *testing.T) {
+		err := parseTestReceiversResponse(http.StatusInternalServerError, []byte("internal error\n"))
+		require.EqualError(t, err, "test failed with status 500: internal error")
+	})
+}
+
+func TestTestNotificationRequested(t *testing.T) {
+	p := testNotificationRequested()
+
+	withAnnotation := func(value *string) *v1beta1.GrafanaContactPoint {
+		cp := &v1beta1.GrafanaContactPoint{}
+		if value != nil {
+			cp.Annotations = map[string]string{annotationTestNotification: *value}
+		}
+
+		return cp
+	}
+
+	first, second := "1", "2"
+
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: withAnnotation(nil), ObjectNew: withAnnotation(&first)}))
+	require.True(t, p.Update(event.UpdateEvent{ObjectOld: withAnnotation(&first), ObjectNew: withAnnotation(&second)}))
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: withAnnotation(&first), ObjectNew: withAnnotation(&first)}))
+	require.False(t, p.Update(event.UpdateEvent{ObjectOld: withAnnotation(&first), ObjectNew: withAnnotation(nil)}))
+}
+
+func TestRemoveTestNotificationAnnotation(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	cp := &v1beta1.GrafanaContactPoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "cp",
+			Annotations: map[string]string{annotationTestNotification: "1"},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(cp).WithStatusSubresource(cp).Build()
+
+	// Conditions computed in the reconcile are not written yet
+	tested := metav1.Condition{Type: conditionContactPointTested, Status: metav1.ConditionTrue, Reason: "TestSucceeded"}
+	meta.SetStatusCondition(&cp.Status.Conditions, tested)
+
+	require.NoError(t, removeTestNotificationAnnotation(t.Context(), cl, cp))
+
+	assert.True(t, meta.IsStatusConditionTrue(cp.Status.Conditions, conditionContactPointTested))
+
+	stored := &v1beta1.GrafanaContactPoint{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(cp), stored))
+	assert.NotContains(t, stored.Annotations, annotationTestNotification)
+}
@@ -66,3 +66,29 @@ func getContentCache(in *v1beta1.GrafanaContentStatus, url string, cacheDuration
 
 	return cache
 }
+
+// GetLastKnownGoodContent returns the cached content regardless of its expiry, to be used when the source can't be fetched
+func GetLastKnownGoodContent(cr v1beta1.GrafanaContentResource) []byte {
+	spec := cr.GrafanaContentSpec()
+	if spec == nil {
+		return nil
+	}
+
+	status := cr.GrafanaContentStatus()
+	if status == nil {
+		return nil
+	}
+
+	// The url of grafana.com content depends on the latest revision, which may be unavailable as well.
+	// Git repositories and buckets don't use spec.url
+	if spec.URL != "" && status.ContentURL != spec.URL {
+		return nil
+	}
+
+	content, err := Gunzip(status.ContentCache)
+	if err != nil {
+		return nil
+	}
+
+	return content
+}
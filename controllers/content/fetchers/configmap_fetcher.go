@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,14 +24,22 @@ func FetchDashboardFromConfigMap(cr v1beta1.GrafanaContentResource, c client.Cli
 	}
 
 	err := c.Get(context.Background(), selector, dashboardConfigMap)
+	if kuberr.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %w", ErrSourceNotFound, err)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
 	if content, ok := dashboardConfigMap.Data[ref.Key]; ok {
+		if IsYAMLKey(ref.Key) {
+			return YAMLToJSON([]byte(content))
+		}
+
 		return []byte(content), nil
 	}
 
-	return nil, fmt.Errorf("cannot find key '%v' in config map '%v' for dashboard %v/%v",
-		ref.Key, ref.Name, cr.GetNamespace(), cr.GetName())
+	return nil, fmt.Errorf("%w: cannot find key '%v' in config map '%v' for dashboard %v/%v",
+		ErrSourceNotFound, ref.Key, ref.Name, cr.GetNamespace(), cr.GetName())
 }
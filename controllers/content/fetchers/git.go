@@ -0,0 +1,664 @@
+package fetchers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha1" //nolint:gosec // Git identifies objects by their SHA-1
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// gitMaxPackSize bounds the memory used to fetch a commit
+	gitMaxPackSize = 256 << 20
+	// gitMaxObjectsSize bounds the memory used by the inflated objects of a pack and the deltas resolved from them
+	gitMaxObjectsSize = 4 * gitMaxPackSize
+	// gitMinEntrySize is the smallest object a pack can hold, a header byte and an empty zlib stream
+	gitMinEntrySize = 9
+
+	gitObjectCommit   = 1
+	gitObjectTree     = 2
+	gitObjectBlob     = 3
+	gitObjectTag      = 4
+	gitObjectOfsDelta = 6
+	gitObjectRefDelta = 7
+)
+
+var (
+	gitObjectTypes = map[int]string{
+		gitObjectCommit: "commit",
+		gitObjectTree:   "tree",
+		gitObjectBlob:   "blob",
+		gitObjectTag:    "tag",
+	}
+
+	errGitDeltaBaseMissing = errors.New("delta base missing from pack")
+	errGitPackTooLarge     = fmt.Errorf("objects of the pack exceed %d bytes", gitMaxObjectsSize)
+)
+
+// gitRemote is a minimal client of the Git smart HTTP protocol (version 0), resolving refs and fetching
+// the files of a single commit without its history
+type gitRemote struct {
+	url      string
+	username string
+	password string
+	client   http.RoundTripper
+}
+
+// gitRefs are the refs and capabilities advertised by a remote
+type gitRefs struct {
+	refs         map[string]string
+	capabilities []string
+}
+
+type gitObject struct {
+	typ  int
+	data []byte
+}
+
+// packEntry is an object of a pack, deltas are replaced by the resolved object
+type packEntry struct {
+	id         string
+	typ        int
+	data       []byte
+	baseOffset int64
+	baseID     string
+}
+
+// packBudget is the memory left for the objects of a pack, sizes announced by the pack are checked against it before
+// anything is allocated
+type packBudget struct {
+	left uint64
+}
+
+func (b *packBudget) take(size uint64) error {
+	if size > b.left {
+		return errGitPackTooLarge
+	}
+
+	b.left -= size
+
+	return nil
+}
+
+func (g *gitRemote) do(ctx context.Context, method, url, contentType string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	if g.password != "" {
+		request.SetBasicAuth(g.username, g.password)
+	}
+
+	response, err := g.client.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return response, nil
+	}
+
+	response.Body.Close() //nolint:errcheck
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("%w: git repository %s answered with status %d", ErrSourceNotFound, g.url, response.StatusCode)
+	}
+
+	return nil, fmt.Errorf("git repository %s answered with status %d", g.url, response.StatusCode)
+}
+
+// listRefs returns the refs advertised by the remote, the equivalent of git ls-remote
+func (g *gitRemote) listRefs(ctx context.Context) (*gitRefs, error) {
+	response, err := g.do(ctx, http.MethodGet, strings.TrimSuffix(g.url, "/")+"/info/refs?service=git-upload-pack", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	r := bufio.NewReader(response.Body)
+	refs := &gitRefs{refs: make(map[string]string)}
+
+	for {
+		line, flush, err := readPktLine(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading refs: %w", err)
+		}
+
+		if flush {
+			// The service announcement is terminated by a flush, the refs by another one
+			if len(refs.refs) > 0 || len(refs.capabilities) > 0 {
+				break
+			}
+
+			continue
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, "# service=") {
+			continue
+		}
+
+		if ref, capabilities, ok := strings.Cut(line, "\x00"); ok {
+			line = ref
+			refs.capabilities = strings.Fields(capabilities)
+		}
+
+		id, name, ok := strings.Cut(line, " ")
+		if !ok || len(id) != 40 {
+			return nil, fmt.Errorf("invalid ref advertisement %q", line)
+		}
+
+		// Empty repositories advertise their capabilities only
+		if name != "capabilities^{}" {
+			refs.refs[name] = id
+		}
+	}
+
+	return refs, nil
+}
+
+func (refs *gitRefs) has(capability string) bool {
+	return slices.Contains(refs.capabilities, capability)
+}
+
+// resolve returns the commit of a branch, tag or commit id, HEAD when ref is empty
+func (refs *gitRefs) resolve(ref string) (string, error) {
+	if isGitObjectID(ref) {
+		if !slices.Contains(slices.Collect(maps.Values(refs.refs)), ref) && !refs.has("allow-reachable-sha1-in-want") && !refs.has("allow-any-sha1-in-want") {
+			return "", fmt.Errorf("commit %s is not the tip of a ref and the repository does not allow fetching other commits", ref)
+		}
+
+		return ref, nil
+	}
+
+	candidates := []string{ref}
+
+	switch {
+	case ref == "":
+		candidates = []string{"HEAD"}
+	case !strings.HasPrefix(ref, "refs/"):
+		candidates = []string{"refs/heads/" + ref, "refs/tags/" + ref}
+	}
+
+	for _, name := range candidates {
+		// Annotated tags are advertised along with the commit they point to
+		if id, ok := refs.refs[name+"^{}"]; ok {
+			return id, nil
+		}
+
+		if id, ok := refs.refs[name]; ok {
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: ref %s not found", ErrSourceNotFound, candidates[0])
+}
+
+// fetchFiles fetches the commit without its history and returns the files matching the filter
+func (g *gitRemote) fetchFiles(ctx context.Context, refs *gitRefs, commit string, match func(string) bool) (map[string][]byte, error) {
+	pack, err := g.fetchPack(ctx, refs, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := parsePack(pack)
+	if err != nil {
+		return nil, fmt.Errorf("reading pack: %w", err)
+	}
+
+	tree, err := commitTree(objects, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+
+	return files, walkTree(objects, tree, "", match, files)
+}
+
+func (g *gitRemote) fetchPack(ctx context.Context, refs *gitRefs, commit string) ([]byte, error) {
+	var capabilities []string
+	if refs.has("ofs-delta") {
+		capabilities = append(capabilities, "ofs-delta")
+	}
+
+	shallow := refs.has("shallow")
+
+	body := &bytes.Buffer{}
+	writePktLine(body, strings.TrimSpace(fmt.Sprintf("want %s %s", commit, strings.Join(capabilities, " ")))+"\n")
+
+	if shallow {
+		writePktLine(body, "deepen 1\n")
+	}
+
+	body.WriteString("0000")
+	writePktLine(body, "done\n")
+
+	response, err := g.do(ctx, http.MethodPost, strings.TrimSuffix(g.url, "/")+"/git-upload-pack", "application/x-git-upload-pack-request", body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	r := bufio.NewReader(io.LimitReader(response.Body, gitMaxPackSize))
+
+	for {
+		line, flush, err := readPktLine(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading fetch response: %w", err)
+		}
+
+		switch {
+		case flush:
+			continue
+		case strings.HasPrefix(line, "shallow "), strings.HasPrefix(line, "unshallow "):
+			continue
+		case strings.HasPrefix(line, "ERR "):
+			return nil, fmt.Errorf("fetching commit %s: %s", commit, strings.TrimSpace(strings.TrimPrefix(line, "ERR ")))
+		case strings.TrimSpace(line) == "NAK":
+			pack, err := io.ReadAll(r)
+			if err != nil {
+				return nil, fmt.Errorf("reading pack: %w", err)
+			}
+
+			if len(pack) >= gitMaxPackSize {
+				return nil, fmt.Errorf("pack of commit %s exceeds %d bytes", commit, gitMaxPackSize)
+			}
+
+			return pack, nil
+		default:
+			return nil, fmt.Errorf("unexpected fetch response %q", line)
+		}
+	}
+}
+
+// readPktLine reads a line of the pkt-line format, flush reports the 0000 packet
+func readPktLine(r io.Reader) (string, bool, error) {
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return "", false, err
+	}
+
+	n, err := strconv.ParseUint(string(size), 16, 16)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid pkt-line length %q", size)
+	}
+
+	// 0000 is a flush packet, 0001 and 0002 are delimiters of protocol version 2
+	if n <= 2 {
+		return "", true, nil
+	}
+
+	if n < 4 {
+		return "", false, fmt.Errorf("invalid pkt-line length %q", size)
+	}
+
+	line := make([]byte, n-4)
+	if _, err := io.ReadFull(r, line); err != nil {
+		return "", false, err
+	}
+
+	return string(line), false, nil
+}
+
+func writePktLine(w *bytes.Buffer, line string) {
+	fmt.Fprintf(w, "%04x%s", len(line)+4, line)
+}
+
+// parsePack returns the objects of a pack by id, resolving deltas
+func parsePack(pack []byte) (map[string]gitObject, error) {
+	if len(pack) < 12 || string(pack[:4]) != "PACK" {
+		return nil, errors.New("invalid pack header")
+	}
+
+	if version := binary.BigEndian.Uint32(pack[4:8]); version != 2 && version != 3 {
+		return nil, fmt.Errorf("unsupported pack version %d", version)
+	}
+
+	count := binary.BigEndian.Uint32(pack[8:12])
+	if uint64(count) > uint64(len(pack)-12)/gitMinEntrySize {
+		return nil, fmt.Errorf("pack of %d bytes can't hold %d objects", len(pack), count)
+	}
+
+	budget := &packBudget{left: gitMaxObjectsSize}
+
+	r := bytes.NewReader(pack)
+	if _, err := r.Seek(12, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*packEntry, 0, count)
+	byOffset := make(map[int64]*packEntry, count)
+
+	for range count {
+		offset := r.Size() - int64(r.Len())
+
+		entry, err := readPackEntry(r, offset, budget)
+		if err != nil {
+			return nil, fmt.Errorf("object at offset %d: %w", offset, err)
+		}
+
+		entries = append(entries, entry)
+		byOffset[offset] = entry
+	}
+
+	objects := make(map[string]gitObject, count)
+
+	// Deltas referencing their base by id are resolved once the base is, which may come later in the pack
+	for pending := entries; len(pending) > 0; {
+		var next []*packEntry
+
+		for _, entry := range pending {
+			err := resolvePackEntry(entry, byOffset, objects, budget)
+			if errors.Is(err, errGitDeltaBaseMissing) {
+				next = append(next, entry)
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(next) == len(pending) {
+			return nil, errGitDeltaBaseMissing
+		}
+
+		pending = next
+	}
+
+	return objects, nil
+}
+
+func readPackEntry(r *bytes.Reader, offset int64, budget *packBudget) (*packEntry, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &packEntry{typ: int(c>>4) & 7}
+	size := uint64(c & 0x0f)
+
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+
+		size |= uint64(c&0x7f) << shift
+	}
+
+	switch entry.typ {
+	case gitObjectCommit, gitObjectTree, gitObjectBlob, gitObjectTag:
+	case gitObjectOfsDelta:
+		if c, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return nil, err
+			}
+
+			distance = ((distance + 1) << 7) | int64(c&0x7f)
+		}
+
+		entry.baseOffset = offset - distance
+	case gitObjectRefDelta:
+		id := make([]byte, sha1.Size)
+		if _, err := io.ReadFull(r, id); err != nil {
+			return nil, err
+		}
+
+		entry.baseID = hex.EncodeToString(id)
+	default:
+		return nil, fmt.Errorf("unknown object type %d", entry.typ)
+	}
+
+	// bytes.Reader is an io.ByteReader, zlib doesn't read past the end of the object
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := budget.take(size); err != nil {
+		return nil, err
+	}
+
+	// Reading one byte past the announced size tells a lying header apart without inflating the rest
+	entry.data, err = io.ReadAll(io.LimitReader(zr, int64(size)+1)) //nolint:gosec // size is bounded by the budget
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(entry.data)) != size {
+		return nil, fmt.Errorf("inflated %d bytes, expected %d", len(entry.data), size)
+	}
+
+	return entry, nil
+}
+
+func resolvePackEntry(entry *packEntry, byOffset map[int64]*packEntry, objects map[string]gitObject, budget *packBudget) error {
+	if entry.id != "" {
+		return nil
+	}
+
+	switch entry.typ {
+	case gitObjectOfsDelta:
+		base, ok := byOffset[entry.baseOffset]
+		if !ok || base == entry {
+			return fmt.Errorf("no object at offset %d", entry.baseOffset)
+		}
+
+		if err := resolvePackEntry(base, byOffset, objects, budget); err != nil {
+			return err
+		}
+
+		data, err := applyDelta(base.data, entry.data, budget)
+		if err != nil {
+			return err
+		}
+
+		entry.typ, entry.data = base.typ, data
+	case gitObjectRefDelta:
+		base, ok := objects[entry.baseID]
+		if !ok {
+			return errGitDeltaBaseMissing
+		}
+
+		data, err := applyDelta(base.data, entry.data, budget)
+		if err != nil {
+			return err
+		}
+
+		entry.typ, entry.data = base.typ, data
+	}
+
+	h := sha1.New() //nolint:gosec
+	fmt.Fprintf(h, "%s %d\x00", gitObjectTypes[entry.typ], len(entry.data))
+	h.Write(entry.data)
+
+	entry.id = hex.EncodeToString(h.Sum(nil))
+	objects[entry.id] = gitObject{typ: entry.typ, data: entry.data}
+
+	return nil
+}
+
+// applyDelta rebuilds an object from its base and a delta made of copy and insert instructions
+func applyDelta(base, delta []byte, budget *packBudget) ([]byte, error) {
+	baseSize, delta := deltaSize(delta)
+	if baseSize != uint64(len(base)) {
+		return nil, fmt.Errorf("delta expects a base of %d bytes, got %d", baseSize, len(base))
+	}
+
+	size, delta := deltaSize(delta)
+	if err := budget.take(size); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, size)
+
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			var offset, n uint64
+
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+
+				if len(delta) == 0 {
+					return nil, errors.New("truncated delta")
+				}
+
+				if i < 4 {
+					offset |= uint64(delta[0]) << (8 * i)
+				} else {
+					n |= uint64(delta[0]) << (8 * (i - 4))
+				}
+
+				delta = delta[1:]
+			}
+
+			if n == 0 {
+				n = 0x10000
+			}
+
+			if offset+n > uint64(len(base)) {
+				return nil, errors.New("delta copies past the end of its base")
+			}
+
+			out = append(out, base[offset:offset+n]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errors.New("truncated delta")
+			}
+
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errors.New("invalid delta instruction")
+		}
+	}
+
+	if uint64(len(out)) != size {
+		return nil, fmt.Errorf("delta produced %d bytes, expected %d", len(out), size)
+	}
+
+	return out, nil
+}
+
+func deltaSize(delta []byte) (uint64, []byte) {
+	var size uint64
+
+	for i, shift := 0, 0; i < len(delta); i, shift = i+1, shift+7 {
+		size |= uint64(delta[i]&0x7f) << shift
+
+		if delta[i]&0x80 == 0 {
+			return size, delta[i+1:]
+		}
+	}
+
+	return size, nil
+}
+
+// commitTree returns the root tree of a commit, following annotated tags
+func commitTree(objects map[string]gitObject, id string) (string, error) {
+	for {
+		object, ok := objects[id]
+		if !ok {
+			return "", fmt.Errorf("commit %s missing from pack", id)
+		}
+
+		header, _, _ := bytes.Cut(object.data, []byte("\n"))
+		field, value, _ := strings.Cut(string(header), " ")
+
+		switch {
+		case object.typ == gitObjectCommit && field == "tree":
+			return value, nil
+		case object.typ == gitObjectTag && field == "object":
+			id = value
+		default:
+			return "", fmt.Errorf("object %s is not a commit", id)
+		}
+	}
+}
+
+// walkTree collects the blobs of the tree matching the filter, submodules and symbolic links are skipped
+func walkTree(objects map[string]gitObject, id, dir string, match func(string) bool, files map[string][]byte) error {
+	tree, ok := objects[id]
+	if !ok || tree.typ != gitObjectTree {
+		return fmt.Errorf("tree %s missing from pack", id)
+	}
+
+	data := tree.data
+
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+
+		if space < 0 || nul < space || len(data) < nul+1+sha1.Size {
+			return fmt.Errorf("malformed tree %s", id)
+		}
+
+		mode := string(data[:space])
+		name := path.Join(dir, string(data[space+1:nul]))
+		entryID := hex.EncodeToString(data[nul+1 : nul+1+sha1.Size])
+		data = data[nul+1+sha1.Size:]
+
+		switch mode {
+		case "40000":
+			if err := walkTree(objects, entryID, name, match, files); err != nil {
+				return err
+			}
+		case "100644", "100755":
+			if !match(name) {
+				continue
+			}
+
+			blob, ok := objects[entryID]
+			if !ok || blob.typ != gitObjectBlob {
+				return fmt.Errorf("blob %s of %s missing from pack", entryID, name)
+			}
+
+			files[name] = blob.data
+		}
+	}
+
+	return nil
+}
+
+func isGitObjectID(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
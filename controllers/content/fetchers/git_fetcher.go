@@ -0,0 +1,211 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+)
+
+// DefaultGitPollInterval is how often the ref of a Git repository source is checked for new commits by default
+const DefaultGitPollInterval = 5 * time.Minute
+
+// gitPushes holds the time of the last push event received for each repository
+var gitPushes = struct {
+	sync.Mutex
+	received map[string]time.Time
+}{received: make(map[string]time.Time)}
+
+// NotifyGitPush invalidates the cached content of the repository, its ref is checked on the next fetch
+func NotifyGitPush(repoURL string) {
+	gitPushes.Lock()
+	defer gitPushes.Unlock()
+
+	gitPushes.received[NormalizeGitURL(repoURL)] = time.Now()
+}
+
+func pushedSince(repoURL string, t time.Time) bool {
+	gitPushes.Lock()
+	defer gitPushes.Unlock()
+
+	return gitPushes.received[NormalizeGitURL(repoURL)].After(t)
+}
+
+// NormalizeGitURL returns a form of a repository url identical for the clone and web urls of the repository,
+// e.g. https://github.com/org/dashboards.git and https://github.com/org/dashboards/
+func NormalizeGitURL(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return repoURL
+	}
+
+	return strings.ToLower(u.Host) + strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+}
+
+// GitPollInterval returns how often the ref of the source is checked for new commits
+func GitPollInterval(source *v1beta1.GitRepoContentReference) time.Duration {
+	if source.PollInterval == nil || source.PollInterval.Duration <= 0 {
+		return DefaultGitPollInterval
+	}
+
+	return source.PollInterval.Duration
+}
+
+func FetchFromGitRepo(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) ([]byte, error) {
+	spec := cr.GrafanaContentSpec()
+	if spec == nil {
+		return nil, fmt.Errorf("missing content spec definition on resource")
+	}
+
+	status := cr.GrafanaContentStatus()
+	source := spec.GitRepo
+	key := gitContentKey(source)
+	now := time.Now()
+
+	if _, err := path.Match(source.Path, ""); err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", source.Path, err)
+	}
+
+	cached := getGitContentCache(status, key)
+	if len(cached) > 0 && status.ContentTimestamp.Add(GitPollInterval(source)).After(now) && !pushedSince(source.URL, status.ContentTimestamp.Time) {
+		return cached, nil
+	}
+
+	remote, err := newGitRemote(ctx, cr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := remote.listRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := refs.resolve(source.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ref did not move since the last fetch
+	if len(cached) > 0 && commit == status.GitRevision {
+		status.ContentTimestamp = v1.Time{Time: now}
+		return cached, nil
+	}
+
+	files, err := remote.fetchFiles(ctx, refs, commit, func(name string) bool {
+		matched, _ := path.Match(source.Path, name) //nolint:errcheck
+		return matched
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := slices.Sorted(maps.Keys(files))
+
+	switch len(names) {
+	case 0:
+		return nil, fmt.Errorf("%w: no file matching %s in %s at %s", ErrSourceNotFound, source.Path, source.URL, commit)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d files match %s, the path must select a single file: %s", len(names), source.Path, strings.Join(names, ", "))
+	}
+
+	content := files[names[0]]
+
+	if ext := path.Ext(names[0]); ext == ".yaml" || ext == ".yml" {
+		content, err = YAMLToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s: %w", names[0], err)
+		}
+	}
+
+	gz, err := cache.Gzip(content)
+	if err != nil {
+		return nil, err
+	}
+
+	status.ContentCache = gz
+	status.ContentTimestamp = v1.Time{Time: now}
+	status.ContentURL = key
+	status.GitRevision = commit
+
+	return content, nil
+}
+
+// gitContentKey identifies the file of the source in status.contentUrl, the cache is discarded once it changes
+func gitContentKey(source *v1beta1.GitRepoContentReference) string {
+	return fmt.Sprintf("%s?ref=%s&path=%s", source.URL, url.QueryEscape(source.Ref), url.QueryEscape(source.Path))
+}
+
+func getGitContentCache(status *v1beta1.GrafanaContentStatus, key string) []byte {
+	if status.ContentURL != key || status.GitRevision == "" {
+		return nil
+	}
+
+	content, err := cache.Gunzip(status.ContentCache)
+	if err != nil {
+		return nil
+	}
+
+	return content
+}
+
+func newGitRemote(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) (*gitRemote, error) {
+	source := cr.GrafanaContentSpec().GitRepo
+
+	contentMetric, err := metrics.ContentURLRequests.CurryWith(prometheus.Labels{
+		"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
+		"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building content metric: %w", err)
+	}
+
+	remote := &gitRemote{
+		url:    source.URL,
+		client: grafanaClient.NewInstrumentedRoundTripper(true, grafanaClient.DefaultTLSConfiguration, contentMetric),
+	}
+
+	if source.SecretRef == nil {
+		return remote, nil
+	}
+
+	secret := &corev1.Secret{}
+
+	err = c.Get(ctx, client.ObjectKey{Namespace: cr.GetNamespace(), Name: source.SecretRef.Name}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("fetching git credentials: %w", err)
+	}
+
+	password, ok := secret.Data[corev1.BasicAuthPasswordKey]
+	if !ok {
+		return nil, fmt.Errorf("missing key %s in secret %s", corev1.BasicAuthPasswordKey, source.SecretRef.Name)
+	}
+
+	remote.password = string(password)
+	logging.RegisterSecret(remote.password)
+
+	// Tokens are accepted with any username by the common Git hosts
+	remote.username = "git"
+	if username, ok := secret.Data[corev1.BasicAuthUsernameKey]; ok {
+		remote.username = string(username)
+	}
+
+	return remote, nil
+}
@@ -0,0 +1,278 @@
+package fetchers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// gitTestRepo is a repository served by git http-backend
+type gitTestRepo struct {
+	t   *testing.T
+	dir string
+	url string
+}
+
+func newGitTestRepo(t *testing.T, auth func(r *http.Request) bool) *gitTestRepo {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	repo := &gitTestRepo{t: t, dir: filepath.Join(root, "dashboards.git")}
+
+	repo.git("init", "--quiet", "--initial-branch=main", repo.dir)
+	repo.git("-C", repo.dir, "config", "user.email", "test@example.com")
+	repo.git("-C", repo.dir, "config", "user.name", "test")
+
+	backend := &cgi.Handler{
+		Path:   filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Env:    []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+		Stderr: io.Discard,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != nil && !auth(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	repo.url = server.URL + "/dashboards.git"
+
+	return repo
+}
+
+func (r *gitTestRepo) git(args ...string) string {
+	r.t.Helper()
+
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(r.t, err, string(out))
+
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes the files and returns the id of the commit
+func (r *gitTestRepo) commit(files map[string]string) string {
+	r.t.Helper()
+
+	for name, content := range files {
+		file := filepath.Join(r.dir, name)
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(r.t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	r.git("-C", r.dir, "add", "--all")
+	r.git("-C", r.dir, "commit", "--quiet", "--message", "update")
+
+	return r.git("-C", r.dir, "rev-parse", "HEAD")
+}
+
+func gitDashboard(source *v1beta1.GitRepoContentReference) *v1beta1.GrafanaDashboard {
+	return &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dashboard"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{GitRepo: source},
+		},
+	}
+}
+
+func TestFetchFromGitRepo(t *testing.T) {
+	repo := newGitTestRepo(t, nil)
+
+	// Similar files are stored as deltas of each other
+	panels := strings.Repeat(`{"type":"timeseries","title":"panel"},`, 200)
+	first := repo.commit(map[string]string{
+		"dashboards/team-a/overview.json": `{"title":"Team A","panels":[` + panels + `{}]}`,
+		"dashboards/team-b/overview.json": `{"title":"Team B","panels":[` + panels + `{}]}`,
+		"dashboards/team-c/details.yaml":  "title: Team C\n",
+		"README.md":                       "dashboards",
+	})
+	repo.git("-C", repo.dir, "tag", "--annotate", "v1", "--message", "v1")
+	second := repo.commit(map[string]string{
+		"dashboards/team-a/overview.json": `{"title":"Team A v2","panels":[` + panels + `{}]}`,
+	})
+
+	cl := fake.NewClientBuilder().Build()
+
+	t.Run("default branch", func(t *testing.T) {
+		cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Path: "dashboards/team-a/*.json"})
+
+		got, err := FetchFromGitRepo(t.Context(), cr, cl)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"title":"Team A v2","panels":[`+panels+`{}]}`, string(got))
+		assert.Equal(t, second, cr.Status.GitRevision)
+		assert.Equal(t, gitContentKey(cr.Spec.GitRepo), cr.Status.ContentURL)
+		assert.NotEmpty(t, cr.Status.ContentCache)
+	})
+
+	t.Run("annotated tag", func(t *testing.T) {
+		cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Ref: "v1", Path: "dashboards/team-b/overview.json"})
+
+		got, err := FetchFromGitRepo(t.Context(), cr, cl)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"title":"Team B","panels":[`+panels+`{}]}`, string(got))
+		assert.Equal(t, first, cr.Status.GitRevision)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Ref: "main", Path: "dashboards/*/details.yaml"})
+
+		got, err := FetchFromGitRepo(t.Context(), cr, cl)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"title":"Team C"}`, string(got))
+	})
+
+	t.Run("several files match", func(t *testing.T) {
+		cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Path: "dashboards/*/overview.json"})
+
+		_, err := FetchFromGitRepo(t.Context(), cr, cl)
+		require.EqualError(t, err, "2 files match dashboards/*/overview.json, the path must select a single file: dashboards/team-a/overview.json, dashboards/team-b/overview.json")
+	})
+
+	t.Run("missing file and ref", func(t *testing.T) {
+		cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Path: "missing.json"})
+
+		_, err := FetchFromGitRepo(t.Context(), cr, cl)
+		require.ErrorIs(t, err, ErrSourceNotFound)
+
+		cr = gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Ref: "missing", Path: "README.md"})
+
+		_, err = FetchFromGitRepo(t.Context(), cr, cl)
+		require.ErrorIs(t, err, ErrSourceNotFound)
+	})
+}
+
+func TestFetchFromGitRepoPolling(t *testing.T) {
+	repo := newGitTestRepo(t, nil)
+	repo.commit(map[string]string{"dashboard.json": `{"title":"v1"}`})
+
+	cl := fake.NewClientBuilder().Build()
+	cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Path: "dashboard.json"})
+
+	got, err := FetchFromGitRepo(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"v1"}`, string(got))
+
+	latest := repo.commit(map[string]string{"dashboard.json": `{"title":"v2"}`})
+
+	// The ref is checked once the poll interval elapsed
+	got, err = FetchFromGitRepo(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"v1"}`, string(got))
+
+	// or a push is received
+	NotifyGitPush(strings.TrimSuffix(repo.url, ".git") + "/")
+
+	got, err = FetchFromGitRepo(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"v2"}`, string(got))
+	assert.Equal(t, latest, cr.Status.GitRevision)
+}
+
+func TestFetchFromGitRepoAuthentication(t *testing.T) {
+	repo := newGitTestRepo(t, func(r *http.Request) bool {
+		username, password, ok := r.BasicAuth()
+		return ok && username == "git" && password == "token"
+	})
+	repo.commit(map[string]string{"dashboard.json": `{"title":"private"}`})
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(s))
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "git-credentials"},
+		Data:       map[string][]byte{v1.BasicAuthPasswordKey: []byte("token")},
+	}).Build()
+
+	cr := gitDashboard(&v1beta1.GitRepoContentReference{URL: repo.url, Path: "dashboard.json"})
+
+	_, err := FetchFromGitRepo(t.Context(), cr, cl)
+	require.EqualError(t, err, "git repository "+repo.url+" answered with status 401")
+
+	cr.Spec.GitRepo.SecretRef = &v1.LocalObjectReference{Name: "git-credentials"}
+
+	got, err := FetchFromGitRepo(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"private"}`, string(got))
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("0123456789")
+
+	// copy 4 bytes at offset 2, insert "ab", copy 2 bytes at offset 8
+	delta := []byte{10, 8, 0x91, 2, 4, 2, 'a', 'b', 0x91, 8, 2}
+
+	got, err := applyDelta(base, delta, &packBudget{left: gitMaxObjectsSize})
+	require.NoError(t, err)
+	assert.Equal(t, "2345ab89", string(got))
+
+	_, err = applyDelta([]byte("short"), delta, &packBudget{left: gitMaxObjectsSize})
+	require.Error(t, err)
+
+	// a delta announcing more than the budget is rejected before allocating
+	_, err = applyDelta(base, delta, &packBudget{left: 4})
+	require.ErrorIs(t, err, errGitPackTooLarge)
+}
+
+func TestParsePackBounds(t *testing.T) {
+	header := func(count uint32) []byte {
+		pack := []byte("PACK\x00\x00\x00\x02")
+		return binary.BigEndian.AppendUint32(pack, count)
+	}
+
+	_, err := parsePack(header(1 << 31))
+	require.EqualError(t, err, "pack of 12 bytes can't hold 2147483648 objects")
+
+	// a blob announcing 2 GiB in a few bytes
+	entry := []byte{0xb0, 0x80, 0x80, 0x80, 0x40}
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err = zw.Write([]byte("small"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	pack := append(append(header(1), entry...), compressed.Bytes()...)
+
+	_, err = parsePack(pack)
+	require.ErrorIs(t, err, errGitPackTooLarge)
+}
+
+func TestNormalizeGitURL(t *testing.T) {
+	for _, u := range []string{
+		"https://github.com/org/dashboards.git",
+		"https://GitHub.com/org/dashboards",
+		"https://github.com/org/dashboards/",
+	} {
+		assert.Equal(t, "github.com/org/dashboards", NormalizeGitURL(u), u)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -16,7 +17,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const grafanaComDashboardsAPIEndpoint = "https://grafana.com/api/dashboards"
+const (
+	grafanaComDashboardsAPIEndpoint = "https://grafana.com/api/dashboards"
+	grafanaComRequestTimeout        = 30 * time.Second
+)
 
 func FetchFromGrafanaCom(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) ([]byte, error) {
 	cache := cache.GetContentCache(cr)
@@ -47,6 +51,11 @@ func FetchFromGrafanaCom(ctx context.Context, cr v1beta1.GrafanaContentResource,
 	return FetchFromURL(ctx, cr, c, tlsConfig)
 }
 
+// LatestGrafanaComRevision returns the latest revision of the grafana.com dashboard referenced by the content
+func LatestGrafanaComRevision(cr v1beta1.GrafanaContentResource) (int, error) {
+	return getLatestGrafanaComRevision(cr, client2.DefaultTLSConfiguration)
+}
+
 func getLatestGrafanaComRevision(cr v1beta1.GrafanaContentResource, tlsConfig *tls.Config) (int, error) {
 	spec := cr.GrafanaContentSpec()
 	if spec == nil {
@@ -61,12 +70,15 @@ func getLatestGrafanaComRevision(cr v1beta1.GrafanaContentResource, tlsConfig *t
 		return -1, err
 	}
 
-	client := client2.NewInstrumentedRoundTripper(true, tlsConfig, metrics.GrafanaComAPIRevisionRequests.MustCurryWith(prometheus.Labels{
-		"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
-		"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
-	}))
+	client := &http.Client{
+		Transport: client2.NewInstrumentedRoundTripper(true, tlsConfig, metrics.GrafanaComAPIRevisionRequests.MustCurryWith(prometheus.Labels{
+			"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
+			"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
+		})),
+		Timeout: grafanaComRequestTimeout,
+	}
 
-	response, err := client.RoundTrip(request)
+	response, err := client.Do(request)
 	if err != nil {
 		return -1, err
 	}
@@ -11,7 +11,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,6 +27,12 @@ import (
 
 var errJsonnetNoContent = errors.New("no jsonnet Content Found, nil or empty string")
 
+// ErrJsonnetRender is returned when jsonnet fails to evaluate, e.g. on syntax errors or missing imports
+var ErrJsonnetRender = errors.New("rendering jsonnet")
+
+// JsonnetLibPathAnnotation is the directory the keys of a ConfigMap selected by spec.jsonnetLibSelector are imported from
+const JsonnetLibPathAnnotation = "operator.grafana.com/jsonnet-path"
+
 // EmbedFSImporter "imports" data from an in-memory embedFS.
 type EmbedFSImporter struct {
 	Embed embed.FS
@@ -103,7 +111,30 @@ func (importer *EmbedFSImporter) Import(importedFrom, importedPath string) (cont
 	return foundContents, s, nil
 }
 
-func FetchJsonnet(cr v1beta1.GrafanaContentResource, envs map[string]string, libsonnet embed.FS) ([]byte, error) {
+// LibImporter imports the files of the ConfigMaps selected by spec.jsonnetLibSelector, by path relative to the
+// importing file or to the root of the libraries. Other imports are passed to Fallback
+type LibImporter struct {
+	Libs     map[string]string
+	Fallback jsonnet.Importer
+}
+
+// Import fetches data from the libraries or the fallback importer.
+func (importer *LibImporter) Import(importedFrom, importedPath string) (contents jsonnet.Contents, foundAt string, err error) {
+	candidates := []string{path.Clean(importedPath)}
+	if importedFrom != "" && !path.IsAbs(importedPath) {
+		candidates = slices.Insert(candidates, 0, path.Join(path.Dir(importedFrom), importedPath))
+	}
+
+	for _, candidate := range candidates {
+		if data, ok := importer.Libs[candidate]; ok {
+			return jsonnet.MakeContents(data), candidate, nil
+		}
+	}
+
+	return importer.Fallback.Import(importedFrom, importedPath)
+}
+
+func FetchJsonnet(cr v1beta1.GrafanaContentResource, envs map[string]string, libs map[string]string, libsonnet embed.FS) ([]byte, error) {
 	spec := cr.GrafanaContentSpec()
 
 	if spec.Jsonnet == "" {
@@ -115,11 +146,14 @@ func FetchJsonnet(cr v1beta1.GrafanaContentResource, envs map[string]string, lib
 		vm.ExtVar(k, v)
 	}
 
-	vm.Importer(&EmbedFSImporter{Embed: libsonnet})
+	vm.Importer(&LibImporter{Libs: libs, Fallback: &EmbedFSImporter{Embed: libsonnet}})
 
 	jsonString, err := vm.EvaluateAnonymousSnippet(cr.GetName(), spec.Jsonnet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJsonnetRender, err)
+	}
 
-	return []byte(jsonString), err
+	return []byte(jsonString), nil
 }
 
 func listDirectoryContents(dirPath string) error {
@@ -244,7 +278,7 @@ func buildJsonnetProject(buildName string, envs map[string]string, cr v1beta1.Gr
 
 	jsonString, err := vm.EvaluateFile(evaluateFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("error evaluating jsonnet file: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrJsonnetRender, err)
 	}
 
 	return []byte(jsonString), nil
@@ -61,7 +61,7 @@ func TestFetchJsonnet(t *testing.T) {
 				},
 			}
 
-			got, err := FetchJsonnet(cr, tt.envs, embeds.GrafonnetEmbed)
+			got, err := FetchJsonnet(cr, tt.envs, nil, embeds.GrafonnetEmbed)
 			require.NoError(t, err)
 
 			assert.JSONEq(t, string(tt.want), string(got))
@@ -81,12 +81,50 @@ func TestFetchJsonnet(t *testing.T) {
 			},
 		}
 
-		got, err := FetchJsonnet(cr, map[string]string{}, embeds.GrafonnetEmbed)
+		got, err := FetchJsonnet(cr, map[string]string{}, nil, embeds.GrafonnetEmbed)
 		assert.Nil(t, got)
 		require.ErrorIs(t, err, errJsonnetNoContent)
 	})
 }
 
+func TestFetchJsonnetWithLibs(t *testing.T) {
+	libs := map[string]string{
+		"github.com/example/lib/main.libsonnet":        `(import 'panels/stat.libsonnet') + { title(t): { title: t } }`,
+		"github.com/example/lib/panels/stat.libsonnet": `{ stat(t): { type: 'stat', title: t } }`,
+	}
+
+	dashboard := func(jsonnet string) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafanadashboard-jsonnet", Namespace: "grafana"},
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{Jsonnet: jsonnet},
+			},
+		}
+	}
+
+	t.Run("relative imports of libraries", func(t *testing.T) {
+		cr := dashboard(`local lib = import 'github.com/example/lib/main.libsonnet'; lib.title('Overview') + { panels: [lib.stat('Requests')] }`)
+
+		got, err := FetchJsonnet(cr, nil, libs, embeds.GrafonnetEmbed)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"title":"Overview","panels":[{"type":"stat","title":"Requests"}]}`, string(got))
+	})
+
+	t.Run("embedded grafonnet is still available", func(t *testing.T) {
+		got, err := FetchJsonnet(dashboard(string(embeds.TestDashboardEmbed)), nil, libs, embeds.GrafonnetEmbed)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(embeds.TestDashboardEmbedExpectedJSON), string(got))
+	})
+
+	t.Run("render errors", func(t *testing.T) {
+		got, err := FetchJsonnet(dashboard(`import 'github.com/example/missing/main.libsonnet'`), nil, libs, embeds.GrafonnetEmbed)
+		assert.Nil(t, got)
+		require.ErrorIs(t, err, ErrJsonnetRender)
+	})
+}
+
 func TestBuildProjectAndFetchJsonnetFrom(t *testing.T) {
 	setup(t)
 	defer teardown(t)
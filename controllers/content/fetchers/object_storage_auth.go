@@ -0,0 +1,542 @@
+package fetchers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/jwt"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+)
+
+const (
+	// credentialRefreshWindow is how long before their expiry cached credentials are refreshed
+	credentialRefreshWindow = 5 * time.Minute
+
+	// emptySHA256 is the hash of the empty payload of GET requests
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	gcsReadOnlyScope      = "https://www.googleapis.com/auth/devstorage.read_only"
+	gcsDefaultTokenURL    = "https://oauth2.googleapis.com/token"
+	gcsDefaultMetadata    = "metadata.google.internal"
+	azureStorageScope     = "https://storage.azure.com/.default"
+	azureDefaultAuthority = "https://login.microsoftonline.com/"
+	azureAssertionType    = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// credentialClient fetches tokens and temporary credentials from the identity providers, following the operator
+// wide TLS policy
+var credentialClient = &http.Client{
+	Transport: grafanaClient.NewInstrumentedRoundTripper(true, grafanaClient.DefaultTLSConfiguration),
+	Timeout:   30 * time.Second,
+}
+
+// ambientCredentials allows sources without secretRef to use the identity of the operator, see SetAmbientCredentials
+var ambientCredentials bool
+
+// SetAmbientCredentials allows object storage sources without secretRef to authenticate with the identity of the
+// operator, e.g. IRSA or Workload Identity. Must be called before any content is fetched
+func SetAmbientCredentials(allowed bool) {
+	ambientCredentials = allowed
+}
+
+// checkAmbientCredentials returns an error unless the identity of the operator may be used for a source without
+// secretRef. Sources with an endpoint never get it, as the endpoint could forward it anywhere
+func checkAmbientCredentials(field, endpoint string) error {
+	if !ambientCredentials {
+		return fmt.Errorf("%s requires a secretRef, the identity of the operator is only used with --allow-ambient-storage-credentials", field)
+	}
+
+	if endpoint != "" {
+		return fmt.Errorf("%s requires a secretRef when an endpoint is set", field)
+	}
+
+	return nil
+}
+
+// credentialCache holds credentials until shortly before they expire
+type credentialCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]cachedCredential[T]
+}
+
+type cachedCredential[T any] struct {
+	value   T
+	expires time.Time
+}
+
+func newCredentialCache[T any]() *credentialCache[T] {
+	return &credentialCache[T]{entries: make(map[string]cachedCredential[T])}
+}
+
+// get returns the cached credential of the key or fetches a new one
+func (c *credentialCache[T]) get(key string, fetch func() (T, time.Time, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Add(credentialRefreshWindow).Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, expires, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cachedCredential[T]{value: value, expires: expires}
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+var (
+	awsCredentialCache = newCredentialCache[*awsCredentials]()
+	storageTokenCache  = newCredentialCache[string]()
+)
+
+// getSecret returns the secret referenced by a source in the namespace of the resource
+func getSecret(ctx context.Context, c client.Client, namespace string, ref *corev1.LocalObjectReference) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("fetching storage credentials: %w", err)
+	}
+
+	return secret, nil
+}
+
+func secretValue(secret *corev1.Secret, key string) (string, error) {
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("missing key %s in secret %s", key, secret.Name)
+	}
+
+	logging.RegisterSecret(string(value))
+
+	return strings.TrimSpace(string(value)), nil
+}
+
+// awsCredentials are used to sign requests to S3
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// s3Credentials returns the credentials of the secret or, when allowed, of the IAM role of the service account (IRSA)
+// or of the environment of the operator, nil when none are available and requests are sent anonymously
+func s3Credentials(ctx context.Context, c client.Client, namespace string, source *v1beta1.S3ContentReference) (*awsCredentials, error) {
+	if source.SecretRef != nil {
+		secret, err := getSecret(ctx, c, namespace, source.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		creds := &awsCredentials{}
+
+		if creds.accessKeyID, err = secretValue(secret, "accessKeyId"); err != nil {
+			return nil, err
+		}
+
+		if creds.secretAccessKey, err = secretValue(secret, "secretAccessKey"); err != nil {
+			return nil, err
+		}
+
+		creds.sessionToken, _ = secretValue(secret, "sessionToken") //nolint:errcheck
+
+		return creds, nil
+	}
+
+	if err := checkAmbientCredentials("spec.s3", source.Endpoint); err != nil {
+		return nil, err
+	}
+
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN != "" && tokenFile != "" {
+		return awsCredentialCache.get(roleARN, func() (*awsCredentials, time.Time, error) {
+			return assumeRoleWithWebIdentity(ctx, roleARN, tokenFile, source.Region)
+		})
+	}
+
+	if accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID"); accessKeyID != "" {
+		return &awsCredentials{
+			accessKeyID:     accessKeyID,
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// assumeRoleWithWebIdentity exchanges the projected service account token for temporary credentials of the role
+func assumeRoleWithWebIdentity(ctx context.Context, roleARN, tokenFile, region string) (*awsCredentials, time.Time, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("reading web identity token: %w", err)
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_STS")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com", region)
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {"grafana-operator"},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := credentialClient.Do(req)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("assuming role %s: %w", roleARN, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("assuming role %s: %s", roleARN, storageErrorMessage(resp.StatusCode, body))
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, time.Time{}, fmt.Errorf("parsing credentials of role %s: %w", roleARN, err)
+	}
+
+	creds := result.Credentials
+
+	return &awsCredentials{
+		accessKeyID:     creds.AccessKeyID,
+		secretAccessKey: creds.SecretAccessKey,
+		sessionToken:    creds.SessionToken,
+	}, creds.Expiration, nil
+}
+
+// signV4 signs the request with AWS Signature Version 4, covering the host and all headers set on the request
+func signV4(req *http.Request, creds *awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	names := []string{"host"}
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+
+	slices.Sort(names)
+
+	var headers strings.Builder
+
+	for _, name := range names {
+		value := req.URL.Host
+		if name != "host" {
+			value = strings.Join(req.Header.Values(name), ",")
+		}
+
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", creds.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		values := slices.Clone(query[key])
+		slices.Sort(values)
+
+		for _, value := range values {
+			params = append(params, uriEscape(key, false)+"="+uriEscape(value, false))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// uriEscape encodes all characters but the unreserved ones of RFC 3986 and, for paths, the slashes, as required by AWS signatures
+func uriEscape(s string, path bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+// gcsToken returns an access token of the service account key of the secret or, when allowed, of the service account of the operator
+func gcsToken(ctx context.Context, c client.Client, namespace string, source *v1beta1.GCSContentReference) (string, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, credentialClient)
+
+	if source.SecretRef == nil {
+		if err := checkAmbientCredentials("spec.gcs", source.Endpoint); err != nil {
+			return "", err
+		}
+
+		return storageTokenCache.get("gcs:metadata", func() (string, time.Time, error) {
+			return gcsMetadataToken(ctx)
+		})
+	}
+
+	secret, err := getSecret(ctx, c, namespace, source.SecretRef)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := secretValue(secret, "serviceAccountKey")
+	if err != nil {
+		return "", err
+	}
+
+	var sa struct {
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+
+	if err := json.Unmarshal([]byte(key), &sa); err != nil {
+		return "", fmt.Errorf("parsing service account key of secret %s: %w", secret.Name, err)
+	}
+
+	if sa.TokenURI == "" {
+		sa.TokenURI = gcsDefaultTokenURL
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	return storageTokenCache.get("gcs:"+hex.EncodeToString(hash[:]), func() (string, time.Time, error) {
+		cfg := &jwt.Config{
+			Email:        sa.ClientEmail,
+			PrivateKey:   []byte(sa.PrivateKey),
+			PrivateKeyID: sa.PrivateKeyID,
+			Scopes:       []string{gcsReadOnlyScope},
+			TokenURL:     sa.TokenURI,
+		}
+
+		token, err := cfg.TokenSource(ctx).Token()
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("fetching token of service account %s: %w", sa.ClientEmail, err)
+		}
+
+		return token.AccessToken, token.Expiry, nil
+	})
+}
+
+// gcsMetadataToken fetches a token of the service account of the pod from the metadata server, which GKE Workload Identity serves
+func gcsMetadataToken(ctx context.Context) (string, time.Time, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = gcsDefaultMetadata
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := credentialClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("fetching token from the metadata server: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("fetching token from the metadata server: status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", time.Time{}, fmt.Errorf("parsing token of the metadata server: %w", err)
+	}
+
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
+
+// azureAuthorize adds the SAS token of the secret to the url or, when allowed, a token of the Workload Identity of the operator to the request
+func azureAuthorize(ctx context.Context, req *http.Request, c client.Client, namespace string, source *v1beta1.AzureBlobContentReference) error {
+	if source.SecretRef != nil {
+		secret, err := getSecret(ctx, c, namespace, source.SecretRef)
+		if err != nil {
+			return err
+		}
+
+		sas, err := secretValue(secret, "sasToken")
+		if err != nil {
+			return err
+		}
+
+		req.URL.RawQuery = strings.TrimPrefix(sas, "?")
+
+		return nil
+	}
+
+	if err := checkAmbientCredentials("spec.azureBlob", source.Endpoint); err != nil {
+		return err
+	}
+
+	clientID, tenantID, tokenFile := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	if clientID == "" || tenantID == "" || tokenFile == "" {
+		return fmt.Errorf("spec.azureBlob requires a secretRef when Azure Workload Identity is not configured for the operator")
+	}
+
+	authority := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authority == "" {
+		authority = azureDefaultAuthority
+	}
+
+	token, err := storageTokenCache.get("azure:"+tenantID+"/"+clientID, func() (string, time.Time, error) {
+		// The projected token is rotated by the kubelet, it is read for every exchange
+		assertion, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("reading federated token: %w", err)
+		}
+
+		cfg := &clientcredentials.Config{
+			ClientID: clientID,
+			TokenURL: strings.TrimSuffix(authority, "/") + "/" + tenantID + "/oauth2/v2.0/token",
+			Scopes:   []string{azureStorageScope},
+			EndpointParams: url.Values{
+				"client_assertion_type": {azureAssertionType},
+				"client_assertion":      {strings.TrimSpace(string(assertion))},
+			},
+			AuthStyle: oauth2.AuthStyleInParams,
+		}
+
+		token, err := cfg.Token(context.WithValue(ctx, oauth2.HTTPClient, credentialClient))
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("fetching token of workload identity %s: %w", clientID, err)
+		}
+
+		return token.AccessToken, token.Expiry, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// storageErrorMessage returns the code and message of the XML errors of S3, GCS, Azure and STS
+func storageErrorMessage(status int, body []byte) string {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+		Error   struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		} `xml:"Error"`
+	}
+
+	if err := xml.Unmarshal(body, &e); err == nil {
+		if e.Code == "" {
+			e.Code, e.Message = e.Error.Code, e.Error.Message
+		}
+
+		if e.Code != "" {
+			return fmt.Sprintf("status %d %s: %s", status, e.Code, e.Message)
+		}
+	}
+
+	return fmt.Sprintf("status %d", status)
+}
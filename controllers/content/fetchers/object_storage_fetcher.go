@@ -0,0 +1,234 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+)
+
+// azureBlobVersion is the version of the Blob service REST API, the first supporting bearer tokens is 2017-11-09
+const azureBlobVersion = "2021-08-06"
+
+// storageObject is an object of a bucket and how requests for it are authorized
+type storageObject struct {
+	// url of the object, also identifying the object in status.contentUrl
+	url string
+	// name of the object, its extension tells whether it is YAML
+	name      string
+	authorize func(ctx context.Context, req *http.Request) error
+}
+
+// FetchFromObjectStorage fetches the object of spec.s3, spec.gcs or spec.azureBlob. The ETag of the object is kept
+// in the status and sent with the next request, unchanged objects are taken from the content cache
+func FetchFromObjectStorage(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) ([]byte, error) {
+	spec := cr.GrafanaContentSpec()
+	if spec == nil {
+		return nil, fmt.Errorf("missing content spec definition on resource")
+	}
+
+	object, err := newStorageObject(cr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	status := cr.GrafanaContentStatus()
+	now := time.Now()
+
+	cached := getObjectContentCache(status, object.url)
+	if len(cached) > 0 && status.ContentTimestamp.Add(spec.ContentCacheDuration.Duration).After(now) {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, object.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cached) > 0 {
+		req.Header.Set("If-None-Match", status.ObjectETag)
+	}
+
+	if err := object.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	contentMetric, err := metrics.ContentURLRequests.CurryWith(prometheus.Labels{
+		"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
+		"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building content metric: %w", err)
+	}
+
+	response, err := grafanaClient.NewInstrumentedRoundTripper(true, grafanaClient.DefaultTLSConfiguration, contentMetric).RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if len(cached) > 0 {
+			status.ContentTimestamp = v1.Time{Time: now}
+			return cached, nil
+		}
+
+		return nil, fmt.Errorf("object %s answered with status 304 without a cached copy", object.url)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: object %s", ErrSourceNotFound, object.url)
+	default:
+		return nil, fmt.Errorf("fetching object %s: %s", object.url, storageErrorMessage(response.StatusCode, body))
+	}
+
+	content := body
+
+	if ext := path.Ext(object.name); ext == ".yaml" || ext == ".yml" {
+		content, err = YAMLToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s: %w", object.name, err)
+		}
+	}
+
+	gz, err := cache.Gzip(content)
+	if err != nil {
+		return nil, err
+	}
+
+	status.ContentCache = gz
+	status.ContentTimestamp = v1.Time{Time: now}
+	status.ContentURL = object.url
+	status.ObjectETag = response.Header.Get("ETag")
+
+	return content, nil
+}
+
+func getObjectContentCache(status *v1beta1.GrafanaContentStatus, objectURL string) []byte {
+	if status.ContentURL != objectURL || status.ObjectETag == "" {
+		return nil
+	}
+
+	content, err := cache.Gunzip(status.ContentCache)
+	if err != nil {
+		return nil
+	}
+
+	return content
+}
+
+func newStorageObject(cr v1beta1.GrafanaContentResource, c client.Client) (*storageObject, error) {
+	spec := cr.GrafanaContentSpec()
+	namespace := cr.GetNamespace()
+
+	switch {
+	case spec.S3 != nil:
+		source := spec.S3
+
+		endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", source.Bucket, source.Region)
+		key := "/" + uriEscape(source.Key, true)
+
+		if err := checkStorageEndpoint(source.Endpoint); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case source.Endpoint != "":
+			key = "/" + uriEscape(source.Bucket, false) + key
+			endpoint = strings.TrimSuffix(source.Endpoint, "/")
+		case strings.Contains(source.Bucket, "."):
+			// Bucket names with dots don't match the certificate of the virtual-hosted endpoint
+			key = "/" + source.Bucket + key
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", source.Region)
+		}
+
+		return &storageObject{
+			url:  endpoint + key,
+			name: source.Key,
+			authorize: func(ctx context.Context, req *http.Request) error {
+				creds, err := s3Credentials(ctx, c, namespace, source)
+				if err != nil || creds == nil {
+					return err
+				}
+
+				signV4(req, creds, source.Region, "s3", time.Now())
+
+				return nil
+			},
+		}, nil
+	case spec.GCS != nil:
+		source := spec.GCS
+
+		if err := checkStorageEndpoint(source.Endpoint); err != nil {
+			return nil, err
+		}
+
+		endpoint := "https://storage.googleapis.com"
+		if source.Endpoint != "" {
+			endpoint = strings.TrimSuffix(source.Endpoint, "/")
+		}
+
+		return &storageObject{
+			url:  endpoint + "/" + uriEscape(source.Bucket, false) + "/" + uriEscape(source.Object, true),
+			name: source.Object,
+			authorize: func(ctx context.Context, req *http.Request) error {
+				token, err := gcsToken(ctx, c, namespace, source)
+				if err != nil {
+					return err
+				}
+
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				return nil
+			},
+		}, nil
+	case spec.AzureBlob != nil:
+		source := spec.AzureBlob
+
+		if err := checkStorageEndpoint(source.Endpoint); err != nil {
+			return nil, err
+		}
+
+		endpoint := fmt.Sprintf("https://%s.blob.core.windows.net", source.Account)
+		if source.Endpoint != "" {
+			endpoint = strings.TrimSuffix(source.Endpoint, "/")
+		}
+
+		return &storageObject{
+			url:  endpoint + "/" + uriEscape(source.Container, false) + "/" + uriEscape(source.Blob, true),
+			name: source.Blob,
+			authorize: func(ctx context.Context, req *http.Request) error {
+				req.Header.Set("x-ms-version", azureBlobVersion)
+				return azureAuthorize(ctx, req, c, namespace, source)
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("no object storage source on resource %s/%s", namespace, cr.GetName())
+	}
+}
+
+// checkStorageEndpoint rejects endpoints not using https, credentials must never be sent in plain text
+func checkStorageEndpoint(endpoint string) error {
+	if endpoint != "" && !strings.HasPrefix(endpoint, "https://") {
+		return fmt.Errorf("object storage endpoint %s must use https", endpoint)
+	}
+
+	return nil
+}
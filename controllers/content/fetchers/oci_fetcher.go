@@ -0,0 +1,590 @@
+package fetchers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+)
+
+const (
+	// dockerHubRegistry serves the repositories of docker.io
+	dockerHubRegistry = "registry-1.docker.io"
+
+	// maxOCIBlobSize bounds the manifests and files pulled from registries
+	maxOCIBlobSize = 16 << 20
+
+	// dockerManifestMediaType is the schema 2 manifest of the Docker registry, also used for artifacts by some tools
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	// dockerManifestListMediaType is the Docker counterpart of the OCI image index
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// ociRegistry pulls the artifact of spec.oci from its registry
+type ociRegistry struct {
+	// baseURL of the registry, e.g. https://ghcr.io
+	baseURL    string
+	repository string
+	// reference of the manifest, a tag or digest
+	reference string
+	// pinned is the digest the manifest must have, empty for tags
+	pinned digest.Digest
+
+	username string
+	password string
+	// token of the Bearer challenge of the registry, valid for the requests of a single fetch
+	token string
+	basic bool
+
+	client *http.Client
+}
+
+// FetchFromOCI fetches a file of the OCI artifact of spec.oci. The digest of the artifact manifest is kept in the status,
+// files of pinned or unchanged artifacts are taken from the content cache
+func FetchFromOCI(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) ([]byte, error) {
+	spec := cr.GrafanaContentSpec()
+	if spec == nil {
+		return nil, fmt.Errorf("missing content spec definition on resource")
+	}
+
+	source := spec.OCI
+	status := cr.GrafanaContentStatus()
+	key := ociContentKey(source)
+	now := time.Now()
+
+	registry, err := newOCIRegistry(ctx, cr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pinned artifacts never change, tags are resolved again once the content cache duration passed
+	cached := getOCIContentCache(status, key)
+	if len(cached) > 0 && (string(registry.pinned) == status.OCIDigest || status.ContentTimestamp.Add(spec.ContentCacheDuration.Duration).After(now)) {
+		return cached, nil
+	}
+
+	expected := registry.pinned
+
+	if expected == "" {
+		// HEAD requests are cheaper and not rate limited by Docker Hub
+		expected, err = registry.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cached) > 0 && string(expected) == status.OCIDigest {
+			status.ContentTimestamp = v1.Time{Time: now}
+			return cached, nil
+		}
+	}
+
+	manifestDigest, manifest, err := registry.manifest(ctx, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := selectOCILayer(manifest, source.File)
+	if err != nil {
+		return nil, fmt.Errorf("artifact %s: %w", source.Ref, err)
+	}
+
+	content, err := registry.blob(ctx, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	name := layer.Annotations[ocispec.AnnotationTitle]
+	if ext := path.Ext(name); ext == ".yaml" || ext == ".yml" {
+		content, err = YAMLToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s: %w", name, err)
+		}
+	}
+
+	gz, err := cache.Gzip(content)
+	if err != nil {
+		return nil, err
+	}
+
+	status.ContentCache = gz
+	status.ContentTimestamp = v1.Time{Time: now}
+	status.ContentURL = key
+	status.OCIDigest = string(manifestDigest)
+
+	return content, nil
+}
+
+// ociContentKey identifies the file of the source in status.contentUrl, the cache is discarded once it changes
+func ociContentKey(source *v1beta1.OCIContentReference) string {
+	return fmt.Sprintf("oci://%s?digest=%s&file=%s", source.Ref, url.QueryEscape(source.Digest), url.QueryEscape(source.File))
+}
+
+func getOCIContentCache(status *v1beta1.GrafanaContentStatus, key string) []byte {
+	if status.ContentURL != key || status.OCIDigest == "" {
+		return nil
+	}
+
+	content, err := cache.Gunzip(status.ContentCache)
+	if err != nil {
+		return nil
+	}
+
+	return content
+}
+
+func newOCIRegistry(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client) (*ociRegistry, error) {
+	source := cr.GrafanaContentSpec().OCI
+
+	named, err := reference.ParseNormalizedNamed(source.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact reference %s: %w", source.Ref, err)
+	}
+
+	domain := reference.Domain(named)
+
+	host := domain
+	if domain == "docker.io" {
+		host = dockerHubRegistry
+	}
+
+	scheme := "https"
+	if source.PlainHTTP {
+		scheme = "http"
+	}
+
+	registry := &ociRegistry{
+		baseURL:    scheme + "://" + host,
+		repository: reference.Path(named),
+		reference:  "latest",
+	}
+
+	if tagged, ok := named.(reference.Tagged); ok {
+		registry.reference = tagged.Tag()
+	}
+
+	if digested, ok := named.(reference.Digested); ok {
+		registry.pinned = digested.Digest()
+	}
+
+	if source.Digest != "" {
+		if registry.pinned != "" && string(registry.pinned) != source.Digest {
+			return nil, fmt.Errorf("digest %s differs from the digest of the artifact reference %s", source.Digest, source.Ref)
+		}
+
+		registry.pinned = digest.Digest(source.Digest)
+	}
+
+	if registry.pinned != "" {
+		registry.reference = string(registry.pinned)
+	}
+
+	if source.PullSecretRef != nil {
+		secret, err := getSecret(ctx, c, cr.GetNamespace(), source.PullSecretRef)
+		if err != nil {
+			return nil, err
+		}
+
+		registry.username, registry.password, err = dockerConfigCredentials(secret, domain+"/"+registry.repository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	contentMetric, err := metrics.ContentURLRequests.CurryWith(prometheus.Labels{
+		"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
+		"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building content metric: %w", err)
+	}
+
+	// Blobs are commonly redirected to object storage, the client drops the Authorization header on redirects to other hosts
+	registry.client = &http.Client{
+		Transport: grafanaClient.NewInstrumentedRoundTripper(true, grafanaClient.DefaultTLSConfiguration, contentMetric),
+	}
+
+	return registry, nil
+}
+
+// dockerConfigCredentials returns the credentials of the most specific registry of a kubernetes.io/dockerconfigjson
+// secret matching the repository, e.g. ghcr.io/org/dashboards
+func dockerConfigCredentials(secret *corev1.Secret, repository string) (string, string, error) {
+	raw, ok := secret.Data[corev1.DockerConfigJsonKey]
+	if !ok {
+		return "", "", fmt.Errorf("missing key %s in secret %s", corev1.DockerConfigJsonKey, secret.Name)
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Auth     string `json:"auth"`
+		} `json:"auths"`
+	}
+
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return "", "", fmt.Errorf("parsing %s of secret %s: %w", corev1.DockerConfigJsonKey, secret.Name, err)
+	}
+
+	match := ""
+
+	for registry := range config.Auths {
+		prefix := normalizeDockerConfigRegistry(registry)
+		if (repository == prefix || strings.HasPrefix(repository, prefix+"/")) && len(prefix) > len(normalizeDockerConfigRegistry(match)) {
+			match = registry
+		}
+	}
+
+	if match == "" {
+		// Anonymous pulls of registries without credentials, as for the imagePullSecrets of pods
+		return "", "", nil
+	}
+
+	entry := config.Auths[match]
+	username, password := entry.Username, entry.Password
+
+	if entry.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("decoding auth of %s in secret %s: %w", match, secret.Name, err)
+		}
+
+		username, password, _ = strings.Cut(string(decoded), ":")
+	}
+
+	logging.RegisterSecret(password)
+
+	return username, password, nil
+}
+
+// normalizeDockerConfigRegistry strips the scheme and the Docker Hub API path of a registry of a Docker config,
+// e.g. https://index.docker.io/v1/
+func normalizeDockerConfigRegistry(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	registry = strings.TrimSuffix(strings.TrimSuffix(registry, "/"), "/v1")
+
+	switch registry {
+	case "index.docker.io", dockerHubRegistry:
+		return "docker.io"
+	default:
+		return registry
+	}
+}
+
+// resolve returns the digest of the manifest the tag points to
+func (r *ociRegistry) resolve(ctx context.Context) (digest.Digest, error) {
+	response, err := r.do(ctx, http.MethodHead, "/manifests/"+r.reference, ociManifestAccept)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	if err := r.checkStatus(response, "manifest "+r.reference); err != nil {
+		return "", err
+	}
+
+	// Registries answering without digest are checked by downloading the manifest
+	return digest.Digest(response.Header.Get("Docker-Content-Digest")), nil
+}
+
+var ociManifestAccept = strings.Join([]string{ocispec.MediaTypeImageManifest, dockerManifestMediaType, ocispec.MediaTypeImageIndex}, ", ")
+
+// manifest downloads the manifest by the expected digest, or by tag when it is unknown, and verifies its digest
+func (r *ociRegistry) manifest(ctx context.Context, expected digest.Digest) (digest.Digest, *ocispec.Manifest, error) {
+	ref := r.reference
+	if expected != "" {
+		ref = string(expected)
+	}
+
+	response, err := r.do(ctx, http.MethodGet, "/manifests/"+ref, ociManifestAccept)
+	if err != nil {
+		return "", nil, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	if err := r.checkStatus(response, "manifest "+ref); err != nil {
+		return "", nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxOCIBlobSize))
+	if err != nil {
+		return "", nil, err
+	}
+
+	actual := digest.FromBytes(body)
+	if expected != "" && actual != expected {
+		return "", nil, fmt.Errorf("manifest %s of %s has digest %s", expected, r.repository, actual)
+	}
+
+	manifest := &ocispec.Manifest{}
+	if err := json.Unmarshal(body, manifest); err != nil {
+		return "", nil, fmt.Errorf("parsing manifest %s of %s: %w", ref, r.repository, err)
+	}
+
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType = response.Header.Get("Content-Type")
+	}
+
+	if mediaType == ocispec.MediaTypeImageIndex || mediaType == dockerManifestListMediaType {
+		return "", nil, fmt.Errorf("%s:%s is an index of several manifests, reference the artifact manifest", r.repository, ref)
+	}
+
+	return actual, manifest, nil
+}
+
+// blob downloads a layer and verifies its digest, gzip compressed layers are decompressed
+func (r *ociRegistry) blob(ctx context.Context, layer ocispec.Descriptor) ([]byte, error) {
+	if layer.Size > maxOCIBlobSize {
+		return nil, fmt.Errorf("layer %s of %s exceeds %d bytes", layer.Digest, r.repository, maxOCIBlobSize)
+	}
+
+	response, err := r.do(ctx, http.MethodGet, "/blobs/"+string(layer.Digest), "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	if err := r.checkStatus(response, "layer "+string(layer.Digest)); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxOCIBlobSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if actual := digest.FromBytes(body); actual != layer.Digest {
+		return nil, fmt.Errorf("layer %s of %s has digest %s", layer.Digest, r.repository, actual)
+	}
+
+	if !strings.HasSuffix(layer.MediaType, "+gzip") {
+		return body, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing layer %s: %w", layer.Digest, err)
+	}
+
+	return io.ReadAll(io.LimitReader(gz, maxOCIBlobSize))
+}
+
+func (r *ociRegistry) checkStatus(response *http.Response, what string) error {
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s of %s", ErrSourceNotFound, what, r.repository)
+	default:
+		return fmt.Errorf("fetching %s of %s: %s", what, r.repository, registryErrorMessage(response))
+	}
+}
+
+// do sends a request to the repository API, once challenged for credentials the request is repeated authorized
+func (r *ociRegistry) do(ctx context.Context, method, endpoint, accept string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, r.baseURL+"/v2/"+r.repository+endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		switch {
+		case r.token != "":
+			req.Header.Set("Authorization", "Bearer "+r.token)
+		case r.basic:
+			req.SetBasicAuth(r.username, r.password)
+		}
+
+		response, err := r.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return response, nil
+		}
+
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close() //nolint:errcheck,gosec
+
+		if err := r.login(ctx, challenge); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// login answers the challenge of the registry, either with the credentials or with a token of its token service
+func (r *ociRegistry) login(ctx context.Context, challenge string) error {
+	scheme, params := parseAuthChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if r.username == "" {
+			return fmt.Errorf("registry %s requires credentials, set pullSecretRef", r.baseURL)
+		}
+
+		r.basic = true
+
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("unsupported authentication challenge %q of registry %s", challenge, r.baseURL)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid token realm %q of registry %s", params["realm"], r.baseURL)
+	}
+
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + r.repository + ":pull"
+	}
+
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	response, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting registry token: %w", err)
+	}
+	defer response.Body.Close() //nolint:errcheck
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting registry token: %s", registryErrorMessage(response))
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return fmt.Errorf("parsing registry token: %w", err)
+	}
+
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+
+	if r.token == "" {
+		return errors.New("registry token response without token")
+	}
+
+	return nil
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header, e.g. Bearer realm="https://ghcr.io/token",service="ghcr.io"
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest != "" {
+		var key string
+
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		if key != "" {
+			params[strings.ToLower(strings.TrimSpace(key))] = value
+		}
+	}
+
+	return scheme, params
+}
+
+// selectOCILayer returns the layer titled file, or the only layer without file
+func selectOCILayer(manifest *ocispec.Manifest, file string) (ocispec.Descriptor, error) {
+	titles := make([]string, 0, len(manifest.Layers))
+
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		if file != "" && title == file {
+			return layer, nil
+		}
+
+		titles = append(titles, title)
+	}
+
+	switch {
+	case file != "":
+		return ocispec.Descriptor{}, fmt.Errorf("%w: no layer titled %s, the artifact holds %s", ErrSourceNotFound, file, strings.Join(titles, ", "))
+	case len(manifest.Layers) == 1:
+		return manifest.Layers[0], nil
+	case len(manifest.Layers) == 0:
+		return ocispec.Descriptor{}, errors.New("the artifact has no layers")
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("the artifact has %d layers, select one with file: %s", len(manifest.Layers), strings.Join(titles, ", "))
+	}
+}
+
+// registryErrorMessage returns the errors of a registry response, see the error codes of the OCI distribution spec
+func registryErrorMessage(response *http.Response) string {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10)) //nolint:errcheck
+	if err := json.Unmarshal(raw, &body); err != nil || len(body.Errors) == 0 {
+		return fmt.Sprintf("status %d", response.StatusCode)
+	}
+
+	messages := make([]string, 0, len(body.Errors))
+	for _, e := range body.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", e.Code, e.Message))
+	}
+
+	return fmt.Sprintf("status %d: %s", response.StatusCode, strings.Join(messages, "; "))
+}
@@ -0,0 +1,303 @@
+package fetchers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+// registryServer serves the artifacts of the org/dashboards repository behind a token service accepting user:password
+type registryServer struct {
+	*httptest.Server
+
+	manifests map[string][]byte
+	blobs     map[digest.Digest][]byte
+	requests  []string
+}
+
+func newRegistryServer(t *testing.T) *registryServer {
+	t.Helper()
+
+	r := &registryServer{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			user, password, _ := req.BasicAuth()
+			if user != "user" || password != "password" || req.URL.Query().Get("scope") != "repository:org/dashboards:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write([]byte(`{"token":"registry-token"}`)) //nolint:errcheck
+
+			return
+		}
+
+		r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+
+		if req.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:org/dashboards:pull"`, r.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors":[{"code":"UNAUTHORIZED","message":"authentication required"}]}`)) //nolint:errcheck
+
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/v2/org/dashboards/manifests/"):
+			manifest, ok := r.manifests[strings.TrimPrefix(req.URL.Path, "/v2/org/dashboards/manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`)) //nolint:errcheck
+
+				return
+			}
+
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+
+			if req.Method == http.MethodGet {
+				w.Write(manifest) //nolint:errcheck
+			}
+		case strings.HasPrefix(req.URL.Path, "/v2/org/dashboards/blobs/"):
+			blob, ok := r.blobs[digest.Digest(strings.TrimPrefix(req.URL.Path, "/v2/org/dashboards/blobs/"))]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write(blob) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+// push stores an artifact with a layer per file under tag and returns the digest of its manifest
+func (r *registryServer) push(t *testing.T, tag string, files map[string]string) digest.Digest {
+	t.Helper()
+
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.grafana.dashboard",
+		Config:       ocispec.DescriptorEmptyJSON,
+	}
+	manifest.SchemaVersion = 2
+
+	for name, content := range files {
+		d := digest.FromString(content)
+		r.blobs[d] = []byte(content)
+
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   "application/json",
+			Digest:      d,
+			Size:        int64(len(content)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: name},
+		})
+	}
+
+	raw, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	d := digest.FromBytes(raw)
+	r.manifests[tag] = raw
+	r.manifests[d.String()] = raw
+
+	return d
+}
+
+func (r *registryServer) ref(suffix string) string {
+	return strings.TrimPrefix(r.URL, "http://") + "/org/dashboards" + suffix
+}
+
+func pullSecret(registry string) *v1.Secret {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "registry"},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: fmt.Appendf(nil, `{"auths":{"other.example.com":{"auth":"b3RoZXI6b3RoZXI="},"%s":{"auth":"%s"}}}`, registry, auth),
+		},
+	}
+}
+
+func TestFetchFromOCI(t *testing.T) {
+	registry := newRegistryServer(t)
+	v1Digest := registry.push(t, "v1", map[string]string{"overview.json": `{"title":"overview"}`})
+
+	cl := storageClient(t, pullSecret(strings.TrimPrefix(registry.URL, "http://")))
+	pull := &v1.LocalObjectReference{Name: "registry"}
+
+	cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+		Ref: registry.ref(":v1"), PullSecretRef: pull, PlainHTTP: true,
+	}})
+
+	got, err := FetchFromOCI(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"overview"}`, string(got))
+	assert.Equal(t, v1Digest.String(), cr.Status.OCIDigest)
+	assert.Equal(t, "oci://"+registry.ref(":v1")+"?digest=&file=", cr.Status.ContentURL)
+
+	// Unchanged tags are only resolved
+	registry.requests = nil
+
+	_, err = FetchFromOCI(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HEAD /v2/org/dashboards/manifests/v1", "HEAD /v2/org/dashboards/manifests/v1"}, registry.requests)
+
+	// Moved tags are downloaded again
+	v2Digest := registry.push(t, "v1", map[string]string{"overview.json": `{"title":"overview v2"}`})
+
+	got, err = FetchFromOCI(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"overview v2"}`, string(got))
+	assert.Equal(t, v2Digest.String(), cr.Status.OCIDigest)
+
+	t.Run("pinned digest", func(t *testing.T) {
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref(":v1"), Digest: v1Digest.String(), PullSecretRef: pull, PlainHTTP: true,
+		}})
+
+		got, err := FetchFromOCI(t.Context(), cr, cl)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"title":"overview"}`, string(got))
+
+		// Pinned artifacts are never requested again
+		registry.requests = nil
+
+		_, err = FetchFromOCI(t.Context(), cr, cl)
+		require.NoError(t, err)
+		assert.Empty(t, registry.requests)
+	})
+
+	t.Run("digest reference", func(t *testing.T) {
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref("@" + v2Digest.String()), Digest: v1Digest.String(), PullSecretRef: pull, PlainHTTP: true,
+		}})
+
+		_, err := FetchFromOCI(t.Context(), cr, cl)
+		require.ErrorContains(t, err, "differs from the digest of the artifact reference")
+	})
+
+	t.Run("file of several layers", func(t *testing.T) {
+		registry.push(t, "bundle", map[string]string{"overview.json": `{"title":"overview"}`, "details.yaml": "title: details\n"})
+
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref(":bundle"), File: "details.yaml", PullSecretRef: pull, PlainHTTP: true,
+		}})
+
+		got, err := FetchFromOCI(t.Context(), cr, cl)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"title":"details"}`, string(got))
+
+		cr.Spec.OCI.File = ""
+		cr.Status = v1beta1.GrafanaDashboardStatus{}
+
+		_, err = FetchFromOCI(t.Context(), cr, cl)
+		require.ErrorContains(t, err, "the artifact has 2 layers, select one with file")
+
+		cr.Spec.OCI.File = "missing.json"
+
+		_, err = FetchFromOCI(t.Context(), cr, cl)
+		require.ErrorIs(t, err, ErrSourceNotFound)
+	})
+
+	t.Run("missing tag", func(t *testing.T) {
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref(":missing"), PullSecretRef: pull, PlainHTTP: true,
+		}})
+
+		_, err := FetchFromOCI(t.Context(), cr, cl)
+		require.ErrorIs(t, err, ErrSourceNotFound)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref(":v1"), PlainHTTP: true,
+		}})
+
+		_, err := FetchFromOCI(t.Context(), cr, cl)
+		require.EqualError(t, err, "requesting registry token: status 401")
+	})
+
+	t.Run("tampered layer", func(t *testing.T) {
+		d := registry.push(t, "tampered", map[string]string{"overview.json": `{"title":"original"}`})
+
+		manifest := ocispec.Manifest{}
+		require.NoError(t, json.Unmarshal(registry.manifests[d.String()], &manifest))
+		registry.blobs[manifest.Layers[0].Digest] = []byte(`{"title":"tampered"}`)
+
+		cr := objectDashboard(v1beta1.GrafanaContentSpec{OCI: &v1beta1.OCIContentReference{
+			Ref: registry.ref(":tampered"), PullSecretRef: pull, PlainHTTP: true,
+		}})
+
+		_, err := FetchFromOCI(t.Context(), cr, cl)
+		require.ErrorContains(t, err, "has digest")
+	})
+
+	// The cache duration skips resolving tags
+	cr.Spec.ContentCacheDuration = metav1.Duration{Duration: time.Hour}
+	registry.requests = nil
+
+	_, err = FetchFromOCI(t.Context(), cr, cl)
+	require.NoError(t, err)
+	assert.Empty(t, registry.requests)
+}
+
+func TestDockerConfigCredentials(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: []byte(`{"auths":{
+				"https://index.docker.io/v1/":{"username":"hub","password":"hub-password"},
+				"ghcr.io":{"username":"ghcr","password":"ghcr-password"},
+				"ghcr.io/org/dashboards":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("org:org-password")) + `"}
+			}}`),
+		},
+	}
+
+	for repository, want := range map[string][2]string{
+		"docker.io/library/dashboards": {"hub", "hub-password"},
+		"ghcr.io/org/dashboards":       {"org", "org-password"},
+		"ghcr.io/org/alerts":           {"ghcr", "ghcr-password"},
+		"quay.io/org/dashboards":       {"", ""},
+	} {
+		username, password, err := dockerConfigCredentials(secret, repository)
+		require.NoError(t, err)
+		assert.Equal(t, want, [2]string{username, password}, repository)
+	}
+
+	_, _, err := dockerConfigCredentials(&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "empty"}}, "ghcr.io/org/dashboards")
+	require.EqualError(t, err, "missing key .dockerconfigjson in secret empty")
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	scheme, params := parseAuthChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/dashboards:pull,push"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/dashboards:pull,push",
+	}, params)
+
+	scheme, params = parseAuthChallenge(`Basic realm=registry`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, map[string]string{"realm": "registry"}, params)
+}
@@ -3,10 +3,13 @@ package fetchers
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -14,12 +17,17 @@ import (
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
 	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
 	"github.com/grafana/grafana-operator/v5/controllers/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ErrSourceNotFound is returned when the source of the content doesn't exist, e.g. its ConfigMap was deleted or its
+// url answers with 404 Not Found or 410 Gone
+var ErrSourceNotFound = errors.New("content source not found")
+
 func FetchFromURL(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client, tlsConfig *tls.Config) ([]byte, error) {
 	spec := cr.GrafanaContentSpec()
 
@@ -55,32 +63,43 @@ func FetchFromURL(ctx context.Context, cr v1beta1.GrafanaContentResource, c clie
 		return nil, fmt.Errorf("building dashboards metric: %w", err)
 	}
 
-	client := grafanaClient.NewInstrumentedRoundTripper(true, tlsConfig, contentMetric, dashboardMetric)
-	// basic auth is supported for dashboards from url
-	if spec.URLAuthorization != nil && spec.URLAuthorization.BasicAuth != nil {
-		username, err := grafanaClient.GetValueFromSecretKey(ctx, spec.URLAuthorization.BasicAuth.Username, c, cr.GetNamespace())
-		if err != nil {
-			return nil, err
-		}
-
-		password, err := grafanaClient.GetValueFromSecretKey(ctx, spec.URLAuthorization.BasicAuth.Password, c, cr.GetNamespace())
-		if err != nil {
-			return nil, err
-		}
+	err = setURLAuthorization(ctx, request, cr, c)
+	if err != nil {
+		return nil, err
+	}
 
-		if username != nil && password != nil {
-			request.SetBasicAuth(string(username), string(password))
-		} else {
-			return nil, fmt.Errorf("basic auth username and/or password are missing for dashboard %s/%s", cr.GetNamespace(), cr.GetName())
+	// Credentials are only sent to verified servers unless explicitly allowed
+	if auth := spec.URLAuthorization; auth != nil {
+		switch {
+		case auth.CA != nil:
+			tlsConfig, err = getURLTLSConfiguration(ctx, cr, c, auth.CA)
+			if err != nil {
+				return nil, err
+			}
+		case auth.InsecureSkipVerify:
+			tlsConfig = grafanaClient.InsecureTLSConfiguration
+		default:
+			tlsConfig = grafanaClient.DefaultTLSConfiguration
 		}
 	}
 
+	client := grafanaClient.NewInstrumentedRoundTripper(true, tlsConfig, contentMetric, dashboardMetric)
+
 	response, err := client.RoundTrip(request)
 	if err != nil {
+		var verifyErr *tls.CertificateVerificationError
+		if spec.URLAuthorization != nil && errors.As(err, &verifyErr) {
+			return nil, fmt.Errorf("verifying certificate of %s, set urlAuthorization.ca for servers with certificates of a private CA: %w", url.Host, err)
+		}
+
 		return nil, err
 	}
 	defer response.Body.Close() //nolint:errcheck
 
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("%w: got %v from dashboard url request for dashboard %v", ErrSourceNotFound, response.StatusCode, cr.GetName())
+	}
+
 	if response.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code from dashboard url request, get %v for dashboard %v", response.StatusCode, cr.GetName())
 	}
@@ -102,3 +121,87 @@ func FetchFromURL(ctx context.Context, cr v1beta1.GrafanaContentResource, c clie
 
 	return content, nil
 }
+
+// setURLAuthorization adds the custom headers and the basic or bearer authorization of the content spec to the request
+func setURLAuthorization(ctx context.Context, request *http.Request, cr v1beta1.GrafanaContentResource, c client.Client) error {
+	auth := cr.GrafanaContentSpec().URLAuthorization
+	if auth == nil {
+		return nil
+	}
+
+	for _, header := range auth.Headers {
+		value := []byte(header.Value)
+
+		if header.ValueFrom != nil {
+			var err error
+
+			value, err = getReferencedValue(ctx, c, cr.GetNamespace(), header.ValueFrom)
+			if err != nil {
+				return fmt.Errorf("resolving header %s for dashboard %s/%s: %w", header.Name, cr.GetNamespace(), cr.GetName(), err)
+			}
+		}
+
+		request.Header.Set(header.Name, string(value))
+	}
+
+	// basic auth is supported for dashboards from url
+	if auth.BasicAuth != nil {
+		username, err := grafanaClient.GetValueFromSecretKey(ctx, auth.BasicAuth.Username, c, cr.GetNamespace())
+		if err != nil {
+			return err
+		}
+
+		password, err := grafanaClient.GetValueFromSecretKey(ctx, auth.BasicAuth.Password, c, cr.GetNamespace())
+		if err != nil {
+			return err
+		}
+
+		if username != nil && password != nil {
+			logging.RegisterSecret(string(password))
+			request.SetBasicAuth(string(username), string(password))
+		} else {
+			return fmt.Errorf("basic auth username and/or password are missing for dashboard %s/%s", cr.GetNamespace(), cr.GetName())
+		}
+	}
+
+	if auth.BearerToken != nil {
+		token, err := grafanaClient.GetValueFromSecretKey(ctx, auth.BearerToken, c, cr.GetNamespace())
+		if err != nil {
+			return err
+		}
+
+		logging.RegisterSecret(string(token))
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	return nil
+}
+
+// getURLTLSConfiguration returns a TLS configuration verifying the server certificate with the referenced CA bundle
+func getURLTLSConfiguration(ctx context.Context, cr v1beta1.GrafanaContentResource, c client.Client, source *v1beta1.GrafanaContentEnvFromSource) (*tls.Config, error) {
+	ca, err := getReferencedValue(ctx, c, cr.GetNamespace(), source)
+	if err != nil {
+		return nil, fmt.Errorf("resolving ca for dashboard %s/%s: %w", cr.GetNamespace(), cr.GetName(), err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no valid certificate in the ca for dashboard %s/%s", cr.GetNamespace(), cr.GetName())
+	}
+
+	tlsConfig := grafanaClient.DefaultTLSConfiguration.Clone()
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
+
+func getReferencedValue(ctx context.Context, c client.Client, namespace string, source *v1beta1.GrafanaContentEnvFromSource) ([]byte, error) {
+	switch {
+	case source.SecretKeyRef != nil:
+		return grafanaClient.GetValueFromSecretKey(ctx, source.SecretKeyRef, c, namespace)
+	case source.ConfigMapKeyRef != nil:
+		return grafanaClient.GetValueFromConfigMapKey(ctx, source.ConfigMapKeyRef, c, namespace)
+	default:
+		return nil, errors.New("neither secretKeyRef nor configMapKeyRef is set")
+	}
+}
@@ -2,15 +2,21 @@ package fetchers
 
 import (
 	"context"
+	"encoding/pem"
 	"net/http"
+	"net/http/httptest"
+	"testing"
 
 	"github.com/onsi/gomega/ghttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
 	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -124,3 +130,103 @@ var _ = Describe("Fetching dashboards from URL", func() {
 		})
 	})
 })
+
+func TestFetchFromURLAuthorization(t *testing.T) {
+	want := []byte(`{"dummyField": "dummyData"}`)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("PRIVATE-TOKEN") != "gitlab" || r.Header.Get("X-Static") != "static" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write(want) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(s))
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+			Data: map[string][]byte{
+				"TOKEN":  []byte("token\n"),
+				"GITLAB": []byte("gitlab"),
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default"},
+			Data:       map[string]string{"ca.crt": string(ca)},
+		},
+	).Build()
+
+	secretRef := func(key string) *v1.SecretKeySelector {
+		return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}, Key: key}
+	}
+
+	newDashboard := func(auth *v1beta1.GrafanaContentURLAuthorization) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+					URL:              srv.URL,
+					URLAuthorization: auth,
+				},
+			},
+		}
+	}
+
+	auth := &v1beta1.GrafanaContentURLAuthorization{
+		BearerToken: secretRef("TOKEN"),
+		Headers: []v1beta1.GrafanaContentURLHeader{
+			{Name: "PRIVATE-TOKEN", ValueFrom: &v1beta1.GrafanaContentEnvFromSource{SecretKeyRef: secretRef("GITLAB")}},
+			{Name: "X-Static", Value: "static"},
+		},
+		CA: &v1beta1.GrafanaContentEnvFromSource{
+			ConfigMapKeyRef: &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+		},
+	}
+
+	t.Run("headers, bearer token and ca", func(t *testing.T) {
+		got, err := FetchFromURL(t.Context(), newDashboard(auth), cl, nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("credentials are only sent to verified servers", func(t *testing.T) {
+		noCA := auth.DeepCopy()
+		noCA.CA = nil
+
+		_, err := FetchFromURL(t.Context(), newDashboard(noCA), cl, grafanaClient.InsecureTLSConfiguration)
+		require.ErrorContains(t, err, "set urlAuthorization.ca")
+	})
+
+	t.Run("insecureSkipVerify", func(t *testing.T) {
+		insecure := auth.DeepCopy()
+		insecure.CA = nil
+		insecure.InsecureSkipVerify = true
+
+		got, err := FetchFromURL(t.Context(), newDashboard(insecure), cl, nil)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("missing header secret", func(t *testing.T) {
+		missing := auth.DeepCopy()
+		missing.Headers[0].ValueFrom.SecretKeyRef.Key = "MISSING"
+
+		_, err := FetchFromURL(t.Context(), newDashboard(missing), cl, nil)
+		require.ErrorContains(t, err, "resolving header PRIVATE-TOKEN")
+	})
+
+	t.Run("invalid ca", func(t *testing.T) {
+		invalid := auth.DeepCopy()
+		invalid.CA = &v1beta1.GrafanaContentEnvFromSource{SecretKeyRef: secretRef("GITLAB")}
+
+		_, err := FetchFromURL(t.Context(), newDashboard(invalid), cl, nil)
+		require.ErrorContains(t, err, "no valid certificate")
+	})
+}
@@ -0,0 +1,66 @@
+package fetchers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+var errYAMLNoContent = errors.New("no yaml document found")
+
+// IsYAMLKey reports whether the content of a ConfigMap key is yaml, judging by the .yaml or .yml extension of the key
+func IsYAMLKey(key string) bool {
+	return strings.HasSuffix(key, ".yaml") || strings.HasSuffix(key, ".yml")
+}
+
+// YAMLToJSON converts a yaml model to json. The documents of multi-document yaml are applied in order
+// as JSON merge patches, so later documents override keys of earlier ones and remove keys set to null
+func YAMLToJSON(content []byte) ([]byte, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+
+	var merged []byte
+
+	for i := 0; ; i++ {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("reading yaml document %d: %w", i, err)
+		}
+
+		document, err = yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, fmt.Errorf("converting yaml document %d: %w", i, err)
+		}
+
+		// Empty documents, e.g. from a leading separator or comments only
+		if bytes.Equal(document, []byte("null")) {
+			continue
+		}
+
+		if merged == nil {
+			merged = document
+			continue
+		}
+
+		merged, err = jsonpatch.MergePatch(merged, document)
+		if err != nil {
+			return nil, fmt.Errorf("merging yaml document %d: %w", i, err)
+		}
+	}
+
+	if merged == nil {
+		return nil, errYAMLNoContent
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,96 @@
+package fetchers
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "single document",
+			content: "title: Overview\npanels:\n  - type: timeseries\n",
+			want:    `{"panels":[{"type":"timeseries"}],"title":"Overview"}`,
+		},
+		{
+			name:    "leading separator and comments",
+			content: "# exported from grafana\n---\ntitle: Overview\n",
+			want:    `{"title":"Overview"}`,
+		},
+		{
+			name:    "documents are merged in order",
+			content: "title: Overview\nrefresh: 5s\ntime:\n  from: now-6h\n  to: now\n---\nrefresh: null\ntime:\n  from: now-1h\n---\npanels: []\n",
+			want:    `{"panels":[],"time":{"from":"now-1h","to":"now"},"title":"Overview"}`,
+		},
+		{
+			name:    "empty",
+			content: "---\n# nothing\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			content: "title: [Overview\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := YAMLToJSON([]byte(tt.content))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestFetchDashboardFromConfigMapYAML(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(s))
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "default"},
+		Data: map[string]string{
+			"overview.yaml": "title: Overview\n",
+			"overview.json": `{"title": "Overview"}`,
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build()
+
+	for _, key := range []string{"overview.yaml", "overview.json"} {
+		t.Run(key, func(t *testing.T) {
+			dashboard := &v1beta1.GrafanaDashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "default"},
+				Spec: v1beta1.GrafanaDashboardSpec{
+					GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+						ConfigMapRef: &v1.ConfigMapKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "dashboards"},
+							Key:                  key,
+						},
+					},
+				},
+			}
+
+			got, err := FetchDashboardFromConfigMap(dashboard, cl)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"title": "Overview"}`, string(got))
+		})
+	}
+}
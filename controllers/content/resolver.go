@@ -5,15 +5,22 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"path"
+	"regexp"
 	"slices"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	grafanaClient "github.com/grafana/grafana-operator/v5/controllers/client"
 	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
 	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
 	"github.com/grafana/grafana-operator/v5/embeds"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -68,6 +75,7 @@ type ContentResolver struct {
 	Client          client.Client
 	resource        v1beta1.GrafanaContentResource
 	disabledSources []ContentSourceType
+	fetchErr        error
 }
 
 type Option func(r *ContentResolver)
@@ -97,6 +105,11 @@ func (h *ContentResolver) Resolve(ctx context.Context) (map[string]any, string,
 		return nil, "", fmt.Errorf("failed to fetch contents: %w", err)
 	}
 
+	json, err = h.substituteEnvs(ctx, json)
+	if err != nil {
+		return nil, "", err
+	}
+
 	model, hash, err := h.getContentModel(json)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to extract model: %w", err)
@@ -105,6 +118,119 @@ func (h *ContentResolver) Resolve(ctx context.Context) (map[string]any, string,
 	return model, hash, nil
 }
 
+// ResolveInline resolves the model of json, gzipJson and yaml sources without reading the cluster.
+// ok is false for other sources and for content with envs, which are only known once resolved by the controller
+func (h *ContentResolver) ResolveInline(ctx context.Context) (map[string]any, bool, error) {
+	spec := h.resource.GrafanaContentSpec()
+	if len(spec.Envs) > 0 || len(spec.EnvsFrom) > 0 {
+		return nil, false, nil
+	}
+
+	sourceTypes := GetSourceTypes(h.resource)
+	if len(sourceTypes) != 1 || !slices.Contains([]ContentSourceType{ContentSourceTypeRawJSON, ContentSourceTypeGzipJSON, ContentSourceTypeYAML}, sourceTypes[0]) {
+		return nil, false, nil
+	}
+
+	contentJSON, err := h.fetchContentJSON(ctx)
+	if err != nil {
+		return nil, true, err
+	}
+
+	model, _, err := h.getContentModel(contentJSON)
+
+	return model, true, err
+}
+
+// FetchError returns the error fetching the source when Resolve fell back to the last known good content
+func (h *ContentResolver) FetchError() error {
+	return h.fetchErr
+}
+
+// IsSourceNotFound reports whether err is caused by the source of the content not existing, missing objects the
+// source merely refers to, e.g. credentials or envFrom Secrets, are not reported
+func IsSourceNotFound(err error) bool {
+	return errors.Is(err, fetchers.ErrSourceNotFound)
+}
+
+// IsRenderError reports whether err is caused by jsonnet failing to render the content
+func IsRenderError(err error) bool {
+	return errors.Is(err, fetchers.ErrJsonnetRender)
+}
+
+// IsStale reports whether the source of cr can't be reached for longer than spec.staleThreshold,
+// counting from when the cached content expired
+func IsStale(cr v1beta1.GrafanaContentResource, now time.Time) bool {
+	spec := cr.GrafanaContentSpec()
+	status := cr.GrafanaContentStatus()
+
+	if spec == nil || status == nil {
+		return false
+	}
+
+	expired := status.ContentTimestamp.Add(spec.ContentCacheDuration.Duration)
+
+	return now.Sub(expired) > spec.StaleThreshold.Duration
+}
+
+// withLastKnownGood returns the cached content in place of fetch errors when spec.staleThreshold is set
+func (h *ContentResolver) withLastKnownGood(content []byte, err error) ([]byte, error) {
+	if err == nil || h.resource.GrafanaContentSpec().StaleThreshold.Duration <= 0 {
+		return content, err
+	}
+
+	lastKnownGood := cache.GetLastKnownGoodContent(h.resource)
+	if len(lastKnownGood) == 0 {
+		return content, err
+	}
+
+	h.fetchErr = err
+
+	return lastKnownGood, nil
+}
+
+// envPlaceholder matches ${NAME} placeholders and their $${NAME} escapes
+var envPlaceholder = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substituteEnvs replaces the ${NAME} placeholders of spec.envs and spec.envFrom in the model, so the same manifest can
+// be applied with e.g. the datasource uids of every cluster. Jsonnet reads the envs with std.extVar instead
+func (h *ContentResolver) substituteEnvs(ctx context.Context, contentJSON []byte) ([]byte, error) {
+	spec := h.resource.GrafanaContentSpec()
+	if len(spec.Envs) == 0 && len(spec.EnvsFrom) == 0 {
+		return contentJSON, nil
+	}
+
+	sourceTypes := GetSourceTypes(h.resource)
+	if slices.Contains(sourceTypes, ContentSourceTypeJsonnet) || slices.Contains(sourceTypes, ContentSourceJsonnetProject) {
+		return contentJSON, nil
+	}
+
+	envs, err := h.getContentEnvs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong while collecting envs, error: %w", err)
+	}
+
+	return replaceEnvPlaceholders(contentJSON, envs), nil
+}
+
+// replaceEnvPlaceholders replaces the ${NAME} placeholders of envs in contentJSON with the JSON escaped values. Placeholders of
+// other names are left to Grafana, which uses the same syntax for dashboard variables
+func replaceEnvPlaceholders(contentJSON []byte, envs map[string]string) []byte {
+	return envPlaceholder.ReplaceAllFunc(contentJSON, func(match []byte) []byte {
+		if bytes.HasPrefix(match, []byte("$$")) {
+			return match[1:]
+		}
+
+		value, ok := envs[string(match[2:len(match)-1])]
+		if !ok {
+			return match
+		}
+
+		escaped, _ := json.Marshal(value) //nolint:errcheck
+
+		return escaped[1 : len(escaped)-1]
+	})
+}
+
 // map data sources that are required in the content model to data sources that exist in the instance
 func (h *ContentResolver) resolveDatasources(contentJSON []byte) ([]byte, error) {
 	spec := h.resource.GrafanaContentSpec()
@@ -148,15 +274,22 @@ func (h *ContentResolver) fetchContentJSON(ctx context.Context) ([]byte, error)
 		return []byte(spec.JSON), nil
 	case ContentSourceTypeGzipJSON:
 		return cache.Gunzip([]byte(spec.GzipJSON))
+	case ContentSourceTypeYAML:
+		return fetchers.YAMLToJSON([]byte(spec.YAML))
 	case ContentSourceTypeURL:
-		return fetchers.FetchFromURL(ctx, h.resource, h.Client, grafanaClient.InsecureTLSConfiguration)
+		return h.withLastKnownGood(fetchers.FetchFromURL(ctx, h.resource, h.Client, grafanaClient.InsecureTLSConfiguration))
 	case ContentSourceTypeJsonnet:
 		envs, err := h.getContentEnvs(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("something went wrong while collecting envs, error: %w", err)
 		}
 
-		return fetchers.FetchJsonnet(h.resource, envs, embeds.GrafonnetEmbed)
+		libs, err := h.getJsonnetLibs(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return fetchers.FetchJsonnet(h.resource, envs, libs, embeds.GrafonnetEmbed)
 	case ContentSourceJsonnetProject:
 		envs, err := h.getContentEnvs(ctx)
 		if err != nil {
@@ -165,9 +298,15 @@ func (h *ContentResolver) fetchContentJSON(ctx context.Context) ([]byte, error)
 
 		return fetchers.BuildProjectAndFetchJsonnetFrom(h.resource, envs)
 	case ContentSourceTypeGrafanaCom:
-		return fetchers.FetchFromGrafanaCom(ctx, h.resource, h.Client)
+		return h.withLastKnownGood(fetchers.FetchFromGrafanaCom(ctx, h.resource, h.Client))
 	case ContentSourceConfigMap:
 		return fetchers.FetchDashboardFromConfigMap(h.resource, h.Client)
+	case ContentSourceTypeGitRepo:
+		return h.withLastKnownGood(fetchers.FetchFromGitRepo(ctx, h.resource, h.Client))
+	case ContentSourceTypeS3, ContentSourceTypeGCS, ContentSourceTypeAzureBlob:
+		return h.withLastKnownGood(fetchers.FetchFromObjectStorage(ctx, h.resource, h.Client))
+	case ContentSourceTypeOCI:
+		return h.withLastKnownGood(fetchers.FetchFromOCI(ctx, h.resource, h.Client))
 	default:
 		return nil, fmt.Errorf("unknown source type %v found in content resource %v", sourceTypes[0], h.resource.GetName())
 	}
@@ -207,6 +346,55 @@ func (h *ContentResolver) getContentEnvs(ctx context.Context) (map[string]string
 	return envs, nil
 }
 
+// getJsonnetLibs returns the files of the ConfigMaps selected by spec.jsonnetLibSelector by their import path
+func (h *ContentResolver) getJsonnetLibs(ctx context.Context) (map[string]string, error) {
+	spec := h.resource.GrafanaContentSpec()
+	if spec.JsonnetLibSelector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.JsonnetLibSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jsonnetLibSelector: %w", err)
+	}
+
+	var list v1.ConfigMapList
+
+	err = h.Client.List(ctx, &list, client.InNamespace(h.resource.GetNamespace()), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("listing jsonnet libraries: %w", err)
+	}
+
+	libs := make(map[string]string)
+	sources := make(map[string]string)
+
+	for _, cm := range list.Items {
+		dir, ok := cm.Annotations[fetchers.JsonnetLibPathAnnotation]
+		if !ok {
+			dir = cm.Name
+		}
+
+		files := make(map[string]string, len(cm.Data)+len(cm.BinaryData))
+		maps.Copy(files, cm.Data)
+
+		for key, value := range cm.BinaryData {
+			files[key] = string(value)
+		}
+
+		for key, value := range files {
+			file := path.Join(dir, key)
+			if other, ok := sources[file]; ok {
+				return nil, fmt.Errorf("jsonnet library %s is in ConfigMaps %s and %s", file, other, cm.Name)
+			}
+
+			libs[file] = value
+			sources[file] = cm.Name
+		}
+	}
+
+	return libs, nil
+}
+
 func (h *ContentResolver) getReferencedValue(ctx context.Context, cr v1beta1.GrafanaContentResource, source v1beta1.GrafanaContentEnvFromSource) (string, string, error) {
 	if source.SecretKeyRef != nil {
 		s := &v1.Secret{}
@@ -217,6 +405,7 @@ func (h *ContentResolver) getReferencedValue(ctx context.Context, cr v1beta1.Gra
 		}
 
 		if val, ok := s.Data[source.SecretKeyRef.Key]; ok {
+			logging.RegisterSecret(string(val))
 			return source.SecretKeyRef.Key, string(val), nil
 		} else {
 			return "", "", fmt.Errorf("missing key %s in secret %s", source.SecretKeyRef.Key, source.SecretKeyRef.Name)
@@ -255,7 +444,7 @@ func (h *ContentResolver) getContentModel(contentJSON []byte) (map[string]any, s
 
 	err = json.Unmarshal(contentJSON, &contentModel)
 	if err != nil {
-		return map[string]any{}, "", err
+		return map[string]any{}, "", fmt.Errorf("%w: %w", ErrInvalidJSON, err)
 	}
 
 	// NOTE: id should never be hardcoded in a model, otherwise grafana will try to update a model by id instead of uid.
@@ -3,16 +3,23 @@ package content
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/signal"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestGetDashboardEnvs(t *testing.T) {
@@ -193,6 +200,78 @@ func TestContentIsUpdatedUID(t *testing.T) {
 	}
 }
 
+func TestFetchContentJSONLastKnownGood(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	lastKnownGood := []byte(`{"uid":"cached"}`)
+	gz, err := cache.Gzip(lastKnownGood)
+	require.NoError(t, err)
+
+	newCR := func(staleThreshold time.Duration) *NopContentResource {
+		return &NopContentResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "mydashboard", Namespace: "grafana-operator-system"},
+			Spec: v1beta1.GrafanaContentSpec{
+				URL:                  server.URL,
+				ContentCacheDuration: metav1.Duration{Duration: time.Minute},
+				StaleThreshold:       metav1.Duration{Duration: staleThreshold},
+			},
+			Status: v1beta1.GrafanaContentStatus{
+				ContentCache:     gz,
+				ContentTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				ContentURL:       server.URL,
+			},
+		}
+	}
+
+	t.Run("fails without stale threshold", func(t *testing.T) {
+		resolver := NewContentResolver(newCR(0), k8sClient)
+
+		_, err := resolver.fetchContentJSON(context.Background())
+		require.Error(t, err)
+		assert.NoError(t, resolver.FetchError())
+	})
+
+	t.Run("falls back to last known good content", func(t *testing.T) {
+		resolver := NewContentResolver(newCR(10*time.Minute), k8sClient)
+
+		got, err := resolver.fetchContentJSON(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, lastKnownGood, got)
+		assert.Error(t, resolver.FetchError())
+	})
+
+	t.Run("fails without cached content for the url", func(t *testing.T) {
+		cr := newCR(10 * time.Minute)
+		cr.Status.ContentURL = "https://example.com/other.json"
+
+		resolver := NewContentResolver(cr, k8sClient)
+
+		_, err := resolver.fetchContentJSON(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestIsStale(t *testing.T) {
+	now := time.Now()
+
+	cr := &NopContentResource{
+		Spec: v1beta1.GrafanaContentSpec{
+			ContentCacheDuration: metav1.Duration{Duration: 10 * time.Minute},
+			StaleThreshold:       metav1.Duration{Duration: time.Hour},
+		},
+		Status: v1beta1.GrafanaContentStatus{
+			ContentTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+		},
+	}
+
+	// The source is unreachable for 50m since the cache expired
+	assert.False(t, IsStale(cr, now))
+	assert.True(t, IsStale(cr, now.Add(11*time.Minute)))
+}
+
 func getCR(t *testing.T, crUID string, statusUID string, specUID string, dashUID string) *NopContentResource {
 	t.Helper()
 
@@ -218,3 +297,92 @@ func getCR(t *testing.T, crUID string, statusUID string, specUID string, dashUID
 
 	return &cr
 }
+
+func TestGetJsonnetLibs(t *testing.T) {
+	configMap := func(name string, annotations map[string]string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{"jsonnet": "lib"},
+				Annotations: annotations,
+			},
+			Data: data,
+		}
+	}
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "default"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				JsonnetLibSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"jsonnet": "lib"}},
+			},
+		},
+	}
+
+	t.Run("files by import path", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithObjects(
+			configMap("grafonnet", map[string]string{fetchers.JsonnetLibPathAnnotation: "github.com/grafana/grafonnet/gen/grafonnet-latest"},
+				map[string]string{"main.libsonnet": "{}"}),
+			configMap("utils", nil, map[string]string{"utils.libsonnet": "{}"}),
+		).Build()
+
+		libs, err := NewContentResolver(dashboard, cl).getJsonnetLibs(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"github.com/grafana/grafonnet/gen/grafonnet-latest/main.libsonnet": "{}",
+			"utils/utils.libsonnet": "{}",
+		}, libs)
+	})
+
+	t.Run("conflicting files", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithObjects(
+			configMap("lib-a", map[string]string{fetchers.JsonnetLibPathAnnotation: "lib"}, map[string]string{"main.libsonnet": "{}"}),
+			configMap("lib-b", map[string]string{fetchers.JsonnetLibPathAnnotation: "lib"}, map[string]string{"main.libsonnet": "{}"}),
+		).Build()
+
+		_, err := NewContentResolver(dashboard, cl).getJsonnetLibs(t.Context())
+		require.ErrorContains(t, err, "lib/main.libsonnet is in ConfigMaps lib-a and lib-b")
+	})
+}
+
+func TestReplaceEnvPlaceholders(t *testing.T) {
+	envs := map[string]string{
+		"PROMETHEUS_UID": "prom-eu-1",
+		"TITLE":          `Checkout "EU"`,
+	}
+
+	got := replaceEnvPlaceholders([]byte(`{"title":"${TITLE}","panels":[{"datasource":{"uid":"${PROMETHEUS_UID}"},"targets":[{"expr":"up{job=\"${job}\"}"}]}],"description":"$${TITLE}"}`), envs)
+
+	assert.JSONEq(t, `{"title":"Checkout \"EU\"","panels":[{"datasource":{"uid":"prom-eu-1"},"targets":[{"expr":"up{job=\"${job}\"}"}]}],"description":"${TITLE}"}`, string(got))
+}
+
+func TestResolveSubstitutesEnvs(t *testing.T) {
+	cl := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Data:       map[string]string{"PROMETHEUS_UID": "prom-eu-1"},
+	}).Build()
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "default"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				JSON: `{"uid":"overview","title":"Overview ${REGION}","panels":[{"datasource":{"uid":"${PROMETHEUS_UID}"}}]}`,
+				Envs: []v1beta1.GrafanaContentEnv{{Name: "REGION", Value: "eu"}},
+				EnvsFrom: []v1beta1.GrafanaContentEnvFromSource{{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cluster"},
+						Key:                  "PROMETHEUS_UID",
+					},
+				}},
+			},
+		},
+	}
+
+	model, _, err := NewContentResolver(dashboard, cl).Resolve(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Overview eu", model["title"])
+	assert.Equal(t, map[string]any{"uid": "prom-eu-1"}, model["panels"].([]any)[0].(map[string]any)["datasource"])
+}
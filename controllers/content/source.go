@@ -7,11 +7,17 @@ type ContentSourceType string
 const (
 	ContentSourceTypeRawJSON    ContentSourceType = "json"
 	ContentSourceTypeGzipJSON   ContentSourceType = "gzipJson"
+	ContentSourceTypeYAML       ContentSourceType = "yaml"
 	ContentSourceJsonnetProject ContentSourceType = "jsonnetProjectWithRuntimeRaw"
 	ContentSourceTypeURL        ContentSourceType = "url"
 	ContentSourceTypeJsonnet    ContentSourceType = "jsonnet"
 	ContentSourceTypeGrafanaCom ContentSourceType = "grafana"
 	ContentSourceConfigMap      ContentSourceType = "configmap"
+	ContentSourceTypeGitRepo    ContentSourceType = "gitRepo"
+	ContentSourceTypeS3         ContentSourceType = "s3"
+	ContentSourceTypeGCS        ContentSourceType = "gcs"
+	ContentSourceTypeAzureBlob  ContentSourceType = "azureBlob"
+	ContentSourceTypeOCI        ContentSourceType = "oci"
 )
 
 func GetSourceTypes(cr v1beta1.GrafanaContentResource) []ContentSourceType {
@@ -27,6 +33,10 @@ func GetSourceTypes(cr v1beta1.GrafanaContentResource) []ContentSourceType {
 		sourceTypes = append(sourceTypes, ContentSourceTypeGzipJSON)
 	}
 
+	if spec.YAML != "" {
+		sourceTypes = append(sourceTypes, ContentSourceTypeYAML)
+	}
+
 	if spec.URL != "" {
 		sourceTypes = append(sourceTypes, ContentSourceTypeURL)
 	}
@@ -43,6 +53,26 @@ func GetSourceTypes(cr v1beta1.GrafanaContentResource) []ContentSourceType {
 		sourceTypes = append(sourceTypes, ContentSourceConfigMap)
 	}
 
+	if spec.GitRepo != nil {
+		sourceTypes = append(sourceTypes, ContentSourceTypeGitRepo)
+	}
+
+	if spec.S3 != nil {
+		sourceTypes = append(sourceTypes, ContentSourceTypeS3)
+	}
+
+	if spec.GCS != nil {
+		sourceTypes = append(sourceTypes, ContentSourceTypeGCS)
+	}
+
+	if spec.AzureBlob != nil {
+		sourceTypes = append(sourceTypes, ContentSourceTypeAzureBlob)
+	}
+
+	if spec.OCI != nil {
+		sourceTypes = append(sourceTypes, ContentSourceTypeOCI)
+	}
+
 	if spec.JsonnetProjectBuild != nil {
 		sourceTypes = append(sourceTypes, ContentSourceJsonnetProject)
 	}
@@ -0,0 +1,292 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/blang/semver/v4"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+const (
+	// maxModelErrors bounds the errors reported in status.modelErrors
+	maxModelErrors = 20
+
+	// Grafana rejects dashboard uids longer than 40 characters
+	maxDashboardUIDLength = 40
+
+	gridColumns = 24
+)
+
+// ErrInvalidJSON is returned by Resolve when the content isn't a valid JSON object
+var ErrInvalidJSON = errors.New("content is not a valid JSON object")
+
+// schemaVersionSince maps dashboard schema versions to the first Grafana version writing them.
+// Older versions apply dashboards of newer schema versions without error, but fail to render what they don't know
+var schemaVersionSince = []schemaVersionRelease{
+	{36, semver.MustParse("9.0.0")},
+	{37, semver.MustParse("9.1.0")},
+	{38, semver.MustParse("10.0.0")},
+	{39, semver.MustParse("10.4.0")},
+	{40, semver.MustParse("11.3.0")},
+	{41, semver.MustParse("11.6.0")},
+	{42, semver.MustParse("12.1.0")},
+}
+
+type schemaVersionRelease struct {
+	schemaVersion int
+	grafana       semver.Version
+}
+
+// IsInvalidJSON reports whether err is caused by content that isn't a valid JSON object
+func IsInvalidJSON(err error) bool {
+	return errors.Is(err, ErrInvalidJSON)
+}
+
+// ValidateDashboardModel returns the fields of a dashboard model Grafana would reject or fail to render,
+// including the fields of panels nested in rows. Only a missing title blocks the dashboard, the other fields are
+// returned as warnings as Grafana accepts them
+func ValidateDashboardModel(model map[string]any) []v1beta1.DashboardModelError {
+	v := &modelValidator{panelIDs: map[float64]string{}}
+
+	if title, ok := model["title"].(string); !ok || title == "" {
+		v.add("title", "", "title is required")
+	}
+
+	if uid, ok := model["uid"]; ok && uid != nil {
+		if s, ok := uid.(string); !ok || len(s) > maxDashboardUIDLength {
+			v.warn("uid", "", fmt.Sprintf("must be a string of at most %d characters", maxDashboardUIDLength))
+		}
+	}
+
+	if schemaVersion, ok := model["schemaVersion"]; ok {
+		if _, ok := schemaVersion.(float64); !ok {
+			v.warn("schemaVersion", "", "must be a number")
+		}
+	}
+
+	if tags, ok := model["tags"]; ok && tags != nil {
+		list, ok := tags.([]any)
+		if !ok {
+			v.warn("tags", "", "must be a list of strings")
+		}
+
+		for i, tag := range list {
+			if _, ok := tag.(string); !ok {
+				v.warn(fmt.Sprintf("tags[%d]", i), "", "must be a string")
+			}
+		}
+	}
+
+	v.validateTemplating(model["templating"])
+
+	if panels, ok := model["panels"]; ok && panels != nil {
+		v.validatePanels("panels", panels)
+	}
+
+	return v.errors
+}
+
+// BlockingModelErrors returns the model errors that aren't warnings
+func BlockingModelErrors(modelErrors []v1beta1.DashboardModelError) []v1beta1.DashboardModelError {
+	var blocking []v1beta1.DashboardModelError
+
+	for _, e := range modelErrors {
+		if !e.Warning {
+			blocking = append(blocking, e)
+		}
+	}
+
+	return blocking
+}
+
+type modelValidator struct {
+	errors []v1beta1.DashboardModelError
+	// paths of the panels by id
+	panelIDs map[float64]string
+}
+
+func (v *modelValidator) add(path, panel, message string) {
+	if len(v.errors) < maxModelErrors {
+		v.errors = append(v.errors, v1beta1.DashboardModelError{Path: path, Panel: panel, Message: message})
+	}
+}
+
+func (v *modelValidator) warn(path, panel, message string) {
+	if len(v.errors) < maxModelErrors {
+		v.errors = append(v.errors, v1beta1.DashboardModelError{Path: path, Panel: panel, Message: message, Warning: true})
+	}
+}
+
+func (v *modelValidator) validateTemplating(templating any) {
+	if templating == nil {
+		return
+	}
+
+	section, ok := templating.(map[string]any)
+	if !ok {
+		v.warn("templating", "", "must be an object")
+		return
+	}
+
+	list, ok := section["list"]
+	if !ok || list == nil {
+		return
+	}
+
+	variables, ok := list.([]any)
+	if !ok {
+		v.warn("templating.list", "", "must be a list")
+		return
+	}
+
+	for i, item := range variables {
+		variable, ok := item.(map[string]any)
+		if !ok {
+			v.warn(fmt.Sprintf("templating.list[%d]", i), "", "must be an object")
+			continue
+		}
+
+		if name, ok := variable["name"].(string); !ok || name == "" {
+			v.warn(fmt.Sprintf("templating.list[%d].name", i), "", "name is required")
+		}
+	}
+}
+
+func (v *modelValidator) validatePanels(path string, panels any) {
+	list, ok := panels.([]any)
+	if !ok {
+		v.warn(path, "", "must be a list")
+		return
+	}
+
+	for i, item := range list {
+		panelPath := fmt.Sprintf("%s[%d]", path, i)
+
+		panel, ok := item.(map[string]any)
+		if !ok {
+			v.warn(panelPath, "", "must be an object")
+			continue
+		}
+
+		v.validatePanel(panelPath, panel)
+	}
+}
+
+func (v *modelValidator) validatePanel(path string, panel map[string]any) {
+	title, _ := panel["title"].(string) //nolint:errcheck
+
+	// The model of library panels is stored in the library panel, the dashboard only references it
+	_, isLibraryPanel := panel["libraryPanel"]
+
+	panelType, ok := panel["type"].(string)
+	if !isLibraryPanel && (!ok || panelType == "") {
+		v.warn(path+".type", title, "type is required")
+	}
+
+	if id, ok := panel["id"]; ok && id != nil {
+		n, ok := id.(float64)
+
+		switch {
+		case !ok:
+			v.warn(path+".id", title, "must be a number")
+		case v.panelIDs[n] != "":
+			v.warn(path+".id", title, fmt.Sprintf("duplicate panel id %v, also used by %s", n, v.panelIDs[n]))
+		default:
+			v.panelIDs[n] = path
+		}
+	}
+
+	if gridPos, ok := panel["gridPos"]; ok && gridPos != nil {
+		v.validateGridPos(path+".gridPos", title, gridPos)
+	}
+
+	if targets, ok := panel["targets"]; ok && targets != nil {
+		list, ok := targets.([]any)
+		if !ok {
+			v.warn(path+".targets", title, "must be a list")
+		}
+
+		for i, target := range list {
+			if _, ok := target.(map[string]any); !ok {
+				v.warn(fmt.Sprintf("%s.targets[%d]", path, i), title, "must be an object")
+			}
+		}
+	}
+
+	// Collapsed rows hold their panels
+	if nested, ok := panel["panels"]; ok && nested != nil && panelType == "row" {
+		v.validatePanels(path+".panels", nested)
+	}
+}
+
+func (v *modelValidator) validateGridPos(path, title string, gridPos any) {
+	pos, ok := gridPos.(map[string]any)
+	if !ok {
+		v.warn(path, title, "must be an object")
+		return
+	}
+
+	values := map[string]float64{}
+
+	for _, key := range []string{"h", "w", "x", "y"} {
+		value, ok := pos[key]
+		if !ok {
+			continue
+		}
+
+		n, ok := value.(float64)
+		if !ok || n < 0 {
+			v.warn(path+"."+key, title, "must be a positive number")
+			continue
+		}
+
+		values[key] = n
+	}
+
+	w, ok := values["w"]
+
+	switch {
+	case !ok:
+	case w < 1:
+		v.warn(path+".w", title, "must be at least 1")
+	case w+values["x"] > gridColumns:
+		v.warn(path+".w", title, fmt.Sprintf("x+w of %v exceeds the %d columns of the grid", values["x"]+w, gridColumns))
+	}
+}
+
+// CheckSchemaVersion returns an error when the schemaVersion of a dashboard model is newer than the given Grafana version supports.
+// Models without schemaVersion and unknown Grafana versions pass
+func CheckSchemaVersion(model map[string]any, grafanaVersion string) error {
+	schemaVersion, ok := model["schemaVersion"].(float64)
+	if !ok {
+		return nil
+	}
+
+	version, err := semver.ParseTolerant(grafanaVersion)
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+
+	// Pre-releases of a version write its schema version
+	version.Pre = nil
+
+	idx := slices.IndexFunc(schemaVersionSince, func(s schemaVersionRelease) bool {
+		return s.schemaVersion > int(schemaVersion)
+	})
+
+	switch idx {
+	case 0:
+		return nil
+	case -1:
+		idx = len(schemaVersionSince)
+	}
+
+	required := schemaVersionSince[idx-1].grafana
+	if version.LT(required) {
+		return fmt.Errorf("dashboard schemaVersion %v requires Grafana %s or newer, the instance runs %s", schemaVersion, required, grafanaVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package content
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDashboardModel(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		want  []v1beta1.DashboardModelError
+	}{
+		{
+			name: "valid",
+			model: `{
+				"title": "Nodes",
+				"uid": "nodes",
+				"schemaVersion": 39,
+				"tags": ["infra"],
+				"templating": {"list": [{"name": "instance", "type": "query"}]},
+				"panels": [
+					{"id": 1, "type": "timeseries", "title": "CPU", "gridPos": {"h": 8, "w": 12, "x": 12, "y": 0}, "targets": [{"refId": "A"}]},
+					{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "type": "stat"}]},
+					{"id": 4, "libraryPanel": {"uid": "shared"}}
+				]
+			}`,
+		},
+		{
+			name:  "dashboard fields",
+			model: `{"uid": "a-uid-much-longer-than-the-forty-characters-grafana-accepts", "schemaVersion": "39", "tags": "infra", "templating": {"list": [{"type": "query"}]}}`,
+			want: []v1beta1.DashboardModelError{
+				{Path: "title", Message: "title is required"},
+				{Path: "uid", Message: "must be a string of at most 40 characters", Warning: true},
+				{Path: "schemaVersion", Message: "must be a number", Warning: true},
+				{Path: "tags", Message: "must be a list of strings", Warning: true},
+				{Path: "templating.list[0].name", Message: "name is required", Warning: true},
+			},
+		},
+		{
+			name: "panel fields",
+			model: `{
+				"title": "Nodes",
+				"panels": [
+					{"id": 1, "title": "CPU", "gridPos": {"w": 8, "x": 20}, "targets": {"refId": "A"}},
+					"text",
+					{"id": 2, "type": "row", "panels": [{"id": 1, "type": "stat", "title": "Memory", "gridPos": {"h": -1, "w": 0}}]}
+				]
+			}`,
+			want: []v1beta1.DashboardModelError{
+				{Path: "panels[0].type", Panel: "CPU", Message: "type is required", Warning: true},
+				{Path: "panels[0].gridPos.w", Panel: "CPU", Message: "x+w of 28 exceeds the 24 columns of the grid", Warning: true},
+				{Path: "panels[0].targets", Panel: "CPU", Message: "must be a list", Warning: true},
+				{Path: "panels[1]", Message: "must be an object", Warning: true},
+				{Path: "panels[2].panels[0].id", Panel: "Memory", Message: "duplicate panel id 1, also used by panels[0]", Warning: true},
+				{Path: "panels[2].panels[0].gridPos.h", Panel: "Memory", Message: "must be a positive number", Warning: true},
+				{Path: "panels[2].panels[0].gridPos.w", Panel: "Memory", Message: "must be at least 1", Warning: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var model map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.model), &model))
+
+			assert.Equal(t, tt.want, ValidateDashboardModel(model))
+		})
+	}
+}
+
+func TestValidateDashboardModelLimitsErrors(t *testing.T) {
+	panels := make([]any, 0, maxModelErrors+5)
+	for range maxModelErrors + 5 {
+		panels = append(panels, map[string]any{})
+	}
+
+	assert.Len(t, ValidateDashboardModel(map[string]any{"title": "Many", "panels": panels}), maxModelErrors)
+}
+
+func TestBlockingModelErrors(t *testing.T) {
+	modelErrors := ValidateDashboardModel(map[string]any{
+		"panels": []any{
+			map[string]any{"id": float64(1), "type": "stat", "gridPos": map[string]any{"w": float64(12), "x": float64(18)}},
+			map[string]any{"id": float64(1), "type": "stat"},
+		},
+	})
+
+	require.Len(t, modelErrors, 3)
+	assert.Equal(t, []v1beta1.DashboardModelError{{Path: "title", Message: "title is required"}}, BlockingModelErrors(modelErrors))
+	assert.Empty(t, BlockingModelErrors(modelErrors[1:]))
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		schemaVersion any
+		version       string
+		wantErr       string
+	}{
+		{name: "supported", schemaVersion: float64(39), version: "11.0.0"},
+		{name: "first version", schemaVersion: float64(41), version: "11.6.0"},
+		{name: "pre-release", schemaVersion: float64(41), version: "11.6.0-pre"},
+		{name: "older than known versions", schemaVersion: float64(27), version: "8.0.0"},
+		{name: "unknown schema version", schemaVersion: float64(99), version: "12.0.0", wantErr: "dashboard schemaVersion 99 requires Grafana 12.1.0 or newer, the instance runs 12.0.0"},
+		{name: "without schemaVersion", version: "8.0.0"},
+		{name: "unknown instance version", schemaVersion: float64(41)},
+		{name: "too old", schemaVersion: float64(41), version: "v11.2.3", wantErr: "dashboard schemaVersion 41 requires Grafana 11.6.0 or newer, the instance runs v11.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := map[string]any{"title": "Nodes"}
+			if tt.schemaVersion != nil {
+				model["schemaVersion"] = tt.schemaVersion
+			}
+
+			err := CheckSchemaVersion(model, tt.version)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
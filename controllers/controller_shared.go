@@ -5,24 +5,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
 	"slices"
 	"strings"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	operatorapi "github.com/grafana/grafana-operator/v5/api"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -38,21 +51,57 @@ const (
 	RequeueDelay        = 10 * time.Second
 	DefaultReSyncPeriod = 10 * time.Minute
 
+	// suspendedInstancesDelay is how often resources only matching instances suspending content check them again
+	suspendedInstancesDelay = time.Minute
+
+	// DefaultAlertRuleGroupInterval is used when .spec.interval is undefined on GrafanaAlertRuleGroups
+	DefaultAlertRuleGroupInterval = time.Minute
+
+	// DefaultAlertRuleParallelism is the number of alert rule requests sent to an instance at once
+	DefaultAlertRuleParallelism = 4
+
 	// condition types
 	conditionNoMatchingInstance             = "NoMatchingInstance"
 	conditionNoMatchingFolder               = "NoMatchingFolder"
 	conditionInvalidSpec                    = "InvalidSpec"
 	conditionNotificationPolicyLoopDetected = "NotificationPolicyLoopDetected"
 	conditionSuspended                      = "Suspended"
+	conditionContentStale                   = "ContentStale"
+	conditionInactive                       = "Inactive"
+	conditionSourceMissing                  = "SourceMissing"
+	conditionPendingWindow                  = "PendingWindow"
+	conditionGrafanaComUpdateAvailable      = "GrafanaComUpdateAvailable"
+	conditionQueriesValid                   = "QueriesValid"
+	conditionDanglingReferences             = "DanglingReferences"
 
 	// condition reasons
-	conditionReasonApplySuccessful = "ApplySuccessful"
-	conditionReasonApplyFailed     = "ApplyFailed"
-	conditionReasonApplySuspended  = "ApplySuspended"
-	conditionReasonEmptyAPIReply   = "EmptyAPIReply"
+	conditionReasonApplySuccessful    = "ApplySuccessful"
+	conditionReasonApplyFailed        = "ApplyFailed"
+	conditionReasonApplySuspended     = "ApplySuspended"
+	conditionReasonEmptyAPIReply      = "EmptyAPIReply"
+	conditionReasonSourceUnreachable  = "SourceUnreachable"
+	conditionReasonOutsideWindow      = "OutsideActiveWindow"
+	conditionReasonInvalidWindow      = "InvalidActiveWindow"
+	conditionReasonSourceDeleted      = "SourceDeleted"
+	conditionReasonWindowClosed       = "ChangeWindowClosed"
+	conditionReasonNewRevision        = "NewRevisionPublished"
+	conditionReasonQueriesFailed      = "QueriesFailed"
+	conditionReasonQueriesSucceeded   = "QueriesSucceeded"
+	conditionReasonReferencesNotFound = "ReferencesNotFound"
+	conditionReasonInstancesSuspended = "InstancesSuspended"
 
 	// Finalizer
 	grafanaFinalizer = "operator.grafana.com/finalizer"
+
+	// Comma separated list of namespaces allowed to reference a ConfigMap or Secret through valuesFrom, * allows all namespaces
+	annotationAllowedNamespaces = "operator.grafana.com/allowed-namespaces"
+
+	// Instances labeled with "true" only receive the dashboard changes approved through annotationApprovedHashes
+	labelRequiresApproval = "operator.grafana.com/requires-approval"
+
+	// Comma separated hashes of the staged dashboard models an approver allows to be applied to the instance. It is set
+	// on the Grafana, so approving requires permission to modify the instance rather than the dashboard
+	annotationApprovedHashes = "operator.grafana.com/approved-hashes"
 )
 
 var (
@@ -67,18 +116,77 @@ type GrafanaCommonReconciler interface {
 
 type Config struct {
 	ResyncPeriod time.Duration
+	// ResyncJitterPercent delays resyncs by up to the given percentage of the resync period
+	ResyncJitterPercent    int
+	AlertRuleGroupInterval time.Duration
+	// AlertRuleParallelism is the number of alert rule requests sent to an instance at once, DefaultAlertRuleParallelism when 0
+	AlertRuleParallelism int
+	// DashboardApplyDedupWindow skips applying identical dashboard models to an instance within the window, 0 disables it
+	DashboardApplyDedupWindow time.Duration
+	// DatasourceUsageInterval controls how often datasource usage is computed, 0 disables it
+	DatasourceUsageInterval time.Duration
+	// GrafanaComRevisionCheckInterval controls how often grafana.com is checked for newer revisions of pinned content, 0 disables it
+	GrafanaComRevisionCheckInterval time.Duration
+	// GrafanaComRevisionWebhookURL receives a POST request once a newer grafana.com revision is detected, empty disables it
+	GrafanaComRevisionWebhookURL string
+	// SyncWindow coalesces reconciles triggered by changes to referenced Secrets and ConfigMaps within the window, 0 disables it
+	SyncWindow time.Duration
+	// ClusterScoped applies the cluster scoped GrafanaOperatorConfigs, operators restricted to namespaces can't read them
+	ClusterScoped bool
 }
 
-func (c *Config) requeueAfter(d metav1.Duration) time.Duration {
-	if c == nil {
-		return d.Duration
+func (c *Config) requeueAfter(d metav1.Duration, jitterPercent *int) time.Duration {
+	period := d.Duration
+	jitter := 0
+
+	if c != nil {
+		// duration on CRs take precedence over global config.
+		if period <= 0 {
+			period = c.ResyncPeriod
+		}
+
+		jitter = c.ResyncJitterPercent
 	}
-	// duration on CRs take precedence over global config.
+
+	if jitterPercent != nil {
+		jitter = *jitterPercent
+	}
+
+	if period <= 0 || jitter <= 0 {
+		return period
+	}
+
+	return wait.Jitter(period, float64(jitter)/100)
+}
+
+// requeueWithinWindow shortens the requeue delay so the resource is reconciled again when its active window closes
+func requeueWithinWindow(requeueAfter time.Duration, window *v1beta1.ActiveWindow, now time.Time) time.Duration {
+	next := window.NextTransition(now)
+	if next > 0 && (requeueAfter <= 0 || next < requeueAfter) {
+		return next
+	}
+
+	return requeueAfter
+}
+
+func (c *Config) alertRuleGroupInterval(d metav1.Duration) time.Duration {
 	if d.Duration > 0 {
 		return d.Duration
 	}
 
-	return c.ResyncPeriod
+	if c == nil || c.AlertRuleGroupInterval == 0 {
+		return DefaultAlertRuleGroupInterval
+	}
+
+	return c.AlertRuleGroupInterval
+}
+
+func (c *Config) alertRuleParallelism() int {
+	if c == nil || c.AlertRuleParallelism <= 0 {
+		return DefaultAlertRuleParallelism
+	}
+
+	return c.AlertRuleParallelism
 }
 
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
@@ -101,57 +209,60 @@ func GetScopedMatchingInstances(ctx context.Context, k8sClient client.Client, cr
 		return []v1beta1.Grafana{}, nil
 	}
 
-	opts := []client.ListOption{
-		// Matches all instances when MatchLabels is undefined
-		client.MatchingLabels(instanceSelector.MatchLabels),
+	selectedList, unreadyInstances, err := provision.MatchingInstances(ctx, k8sClient, scopedNamespace(cr), instanceSelector)
+	if err != nil {
+		return []v1beta1.Grafana{}, err
 	}
 
-	if !cr.AllowCrossNamespace() {
-		// Only query resource namespace
-		opts = append(opts, client.InNamespace(cr.MatchNamespace()))
+	if len(unreadyInstances) > 0 {
+		log.Info("Grafana instances not ready or suspending content, excluded from matching", "instances", unreadyInstances)
 	}
 
-	var list v1beta1.GrafanaList
-
-	err := k8sClient.List(ctx, &list, opts...)
-	if err != nil {
-		return []v1beta1.Grafana{}, err
+	if len(selectedList) == 0 {
+		log.Info("None of the available Grafana instances matched the selector, skipping reconciliation", "AllowCrossNamespaceImport", cr.AllowCrossNamespace())
 	}
 
-	if len(list.Items) == 0 {
-		return []v1beta1.Grafana{}, nil
+	return selectedList, nil
+}
+
+// scopedNamespace returns the namespace instances of the resource are matched in, empty for all namespaces
+func scopedNamespace(cr v1beta1.CommonResource) string {
+	if cr.AllowCrossNamespace() {
+		return ""
 	}
 
-	selectedList := make([]v1beta1.Grafana, 0, len(list.Items))
+	return cr.MatchNamespace()
+}
 
-	var unreadyInstances []string
+// noMatchingInstancesResult is returned by the content reconcilers when no instance matches the resource.
+// Instances still starting are checked again once they are expected to respond, instead of backing off.
+// When matching instances suspend content, the Suspended condition replaces NoMatchingInstance so the finalizer is kept
+func noMatchingInstancesResult(ctx context.Context, k8sClient client.Client, cr v1beta1.CommonResource) (controllerruntime.Result, error) {
+	namespace := scopedNamespace(cr)
 
-	for _, instance := range list.Items {
-		// Matches all instances when MatchExpressions is undefined
-		selected := labelsSatisfyMatchExpressions(instance.Labels, instanceSelector.MatchExpressions)
-		if !selected {
-			continue
-		}
+	suspended, err := provision.SuspendedInstances(ctx, k8sClient, namespace, cr.MatchLabels())
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "checking suspended instances")
+	}
 
-		// admin url is required to interact with Grafana
-		// the instance or route might not yet be ready
-		if instance.Status.Stage != v1beta1.OperatorStageComplete || instance.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
-			unreadyInstances = append(unreadyInstances, instance.Name)
-			continue
-		}
+	if len(suspended) > 0 {
+		conditions := &cr.CommonStatus().Conditions
+		removeNoMatchingInstance(conditions)
+		setInstancesSuspended(conditions, cr.GetGeneration(), suspended)
 
-		selectedList = append(selectedList, instance)
+		return controllerruntime.Result{RequeueAfter: suspendedInstancesDelay}, nil
 	}
 
-	if len(unreadyInstances) > 0 {
-		log.Info("Grafana instances not ready, excluded from matching", "instances", unreadyInstances)
+	retryAfter, err := provision.StartupRetryAfter(ctx, k8sClient, namespace, cr.MatchLabels(), time.Now())
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "checking startup of matching instances")
 	}
 
-	if len(selectedList) == 0 {
-		log.Info("None of the available Grafana instances matched the selector, skipping reconciliation", "AllowCrossNamespaceImport", cr.AllowCrossNamespace())
+	if retryAfter > 0 {
+		return controllerruntime.Result{RequeueAfter: retryAfter}, nil
 	}
 
-	return selectedList, nil
+	return controllerruntime.Result{}, ErrNoMatchingInstances
 }
 
 // getFolderUID returns the folderUID from an existing GrafanaFolder CR within the same namespace
@@ -187,34 +298,7 @@ func getFolderUID(ctx context.Context, k8sClient client.Client, ref operatorapi.
 }
 
 func labelsSatisfyMatchExpressions(labels map[string]string, matchExpressions []metav1.LabelSelectorRequirement) bool {
-	// To preserve support for scenario with instanceSelector: {}
-	if len(labels) == 0 {
-		return true
-	}
-
-	for _, matchExpression := range matchExpressions {
-		selected := false
-
-		if label, ok := labels[matchExpression.Key]; ok {
-			switch matchExpression.Operator {
-			case metav1.LabelSelectorOpDoesNotExist:
-				selected = false
-			case metav1.LabelSelectorOpExists:
-				selected = true
-			case metav1.LabelSelectorOpIn:
-				selected = slices.Contains(matchExpression.Values, label)
-			case metav1.LabelSelectorOpNotIn:
-				selected = !slices.Contains(matchExpression.Values, label)
-			}
-		}
-
-		// All matchExpressions must evaluate to true in order to satisfy the conditions
-		if !selected {
-			return false
-		}
-	}
-
-	return true
+	return provision.LabelsSatisfyMatchExpressions(labels, matchExpressions)
 }
 
 func updatePluginConfigMap(cm *corev1.ConfigMap, value []byte, key string, deprecatedKey string) (isUpdated bool) {
@@ -374,6 +458,211 @@ func removeSuspended(conditions *[]metav1.Condition) {
 	meta.RemoveStatusCondition(conditions, conditionSuspended)
 }
 
+// setInstancesSuspended reports that all matching instances suspend content, the resource is applied once they resume
+func setInstancesSuspended(conditions *[]metav1.Condition, generation int64, instances []string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             conditionReasonInstancesSuspended,
+		Message:            fmt.Sprintf("Content of the matching instances is suspended: %s", strings.Join(instances, ", ")),
+	})
+}
+
+// waitForSuspendedInstances tells whether the finalizer of a deleted resource has to wait for matching instances
+// suspending content, its content is removed from them once they resume
+func waitForSuspendedInstances(ctx context.Context, k8sClient client.Client, cr v1beta1.CommonResource) (bool, error) {
+	suspended, err := provision.SuspendedInstances(ctx, k8sClient, scopedNamespace(cr), cr.MatchLabels())
+	if err != nil {
+		return false, fmt.Errorf("checking suspended instances: %w", err)
+	}
+
+	if len(suspended) > 0 {
+		logf.FromContext(ctx).Info("waiting for instances suspending content before removing the finalizer", "instances", suspended)
+	}
+
+	return len(suspended) > 0, nil
+}
+
+func setInactive(conditions *[]metav1.Condition, generation int64, window *v1beta1.ActiveWindow) {
+	now := time.Now()
+
+	message := "Outside of the active window"
+	if window.Start != nil && now.Before(window.Start.Time) {
+		message = fmt.Sprintf("Not provisioned before %s", window.Start.UTC().Format(time.RFC3339))
+	} else if window.End != nil && !now.Before(window.End.Time) {
+		message = fmt.Sprintf("Active window ended at %s", window.End.UTC().Format(time.RFC3339))
+	} else if next := window.NextTransition(now); next > 0 {
+		message = fmt.Sprintf("Next scheduled window opens at %s", now.Add(next).UTC().Truncate(time.Second).Format(time.RFC3339))
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionInactive,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: now,
+		},
+		Reason:  conditionReasonOutsideWindow,
+		Message: message,
+	})
+}
+
+func removeInactive(conditions *[]metav1.Condition) {
+	meta.RemoveStatusCondition(conditions, conditionInactive)
+}
+
+// setPendingWindow lists the instances with changes deferred until their change window opens, opens maps the instances to the opening time
+func setPendingWindow(conditions *[]metav1.Condition, generation int64, opens map[string]time.Time) {
+	instances := slices.Sorted(maps.Keys(opens))
+
+	var sb strings.Builder
+	for _, instance := range instances {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", instance, opens[instance].UTC().Format(time.RFC3339)))
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionPendingWindow,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonWindowClosed,
+		Message: fmt.Sprintf("Changes to %d instances are deferred until their change window opens:%s", len(opens), sb.String()),
+	})
+}
+
+func removePendingWindow(conditions *[]metav1.Condition) {
+	meta.RemoveStatusCondition(conditions, conditionPendingWindow)
+}
+
+// requeueForPendingWindow shortens the requeue delay so deferred changes are applied as soon as the first change window opens
+func requeueForPendingWindow(requeueAfter time.Duration, opens map[string]time.Time, now time.Time) time.Duration {
+	for _, opening := range opens {
+		next := max(opening.Sub(now), time.Second)
+		if requeueAfter <= 0 || next < requeueAfter {
+			requeueAfter = next
+		}
+	}
+
+	return requeueAfter
+}
+
+// setContentStale sets the ContentStale condition and metric when the content source has been unreachable
+// for longer than spec.staleThreshold. fetchErr is the error returned by ContentResolver.FetchError
+func setContentStale(ctx context.Context, conditions *[]metav1.Condition, generation int64, cr v1beta1.GrafanaContentResource, fetchErr error) {
+	if fetchErr == nil || !content.IsStale(cr, time.Now()) {
+		if fetchErr != nil {
+			logf.FromContext(ctx).Error(fetchErr, "source unreachable, applying last known good content")
+		}
+
+		meta.RemoveStatusCondition(conditions, conditionContentStale)
+		forgetContentStale(cr)
+
+		return
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionContentStale,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonSourceUnreachable,
+		Message: fmt.Sprintf("Applying content fetched at %s, source unreachable: %s", cr.GrafanaContentStatus().ContentTimestamp.Format(time.RFC3339), fetchErr.Error()),
+	})
+	metrics.ContentStale.With(contentMetricLabels(cr)).Set(1)
+}
+
+// forgetContentStale deletes the ContentStale series of a resource whose source recovered or which is deleted
+func forgetContentStale(cr v1beta1.GrafanaContentResource) {
+	metrics.ContentStale.Delete(contentMetricLabels(cr))
+}
+
+// contentMetricLabels returns the labels of the per resource content metrics
+func contentMetricLabels(cr v1beta1.GrafanaContentResource) prometheus.Labels {
+	return prometheus.Labels{
+		"kind":     cr.GetObjectKind().GroupVersionKind().Kind,
+		"resource": fmt.Sprintf("%v/%v", cr.GetNamespace(), cr.GetName()),
+	}
+}
+
+// isSourceDeleted reports whether resolving the content failed because its source was deleted after the content was applied.
+// Sources missing from the start keep failing with the InvalidSpec condition
+func isSourceDeleted(cr v1beta1.GrafanaContentResource, conditions []metav1.Condition, resolveErr error) bool {
+	if !content.IsSourceNotFound(resolveErr) {
+		return false
+	}
+
+	return cr.GrafanaContentStatus().Hash != "" || meta.FindStatusCondition(conditions, conditionSourceMissing) != nil
+}
+
+// onSourceDeleted applies spec.onSourceDeletion to content whose source was deleted, finalize removes the content from
+// the instances. synchronized is the condition type reporting the content as applied
+func onSourceDeleted(ctx context.Context, cr v1beta1.GrafanaContentResource, conditions *[]metav1.Condition, synchronized string, resolveErr error, requeueAfter time.Duration, finalize func() error) (controllerruntime.Result, error) {
+	policy := v1beta1.SourceDeletionPolicyKeep
+	gracePeriod := time.Duration(0)
+
+	if spec := cr.GrafanaContentSpec().OnSourceDeletion; spec != nil {
+		if spec.Policy != "" {
+			policy = spec.Policy
+		}
+
+		gracePeriod = spec.GracePeriod.Duration
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionSourceMissing,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cr.GetGeneration(),
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonSourceDeleted,
+		Message: fmt.Sprintf("Source deleted, applying policy %s: %s", policy, resolveErr.Error()),
+	})
+
+	if policy == v1beta1.SourceDeletionPolicyDegrade {
+		setInvalidSpec(conditions, cr.GetGeneration(), conditionReasonInvalidModelResolution, resolveErr.Error())
+		meta.RemoveStatusCondition(conditions, synchronized)
+
+		return controllerruntime.Result{}, fmt.Errorf("resolving contents: %w", resolveErr)
+	}
+
+	removeInvalidSpec(conditions)
+
+	if policy == v1beta1.SourceDeletionPolicyDelete {
+		missingSince := meta.FindStatusCondition(*conditions, conditionSourceMissing).LastTransitionTime.Time
+
+		if remaining := time.Until(missingSince.Add(gracePeriod)); remaining > 0 {
+			if requeueAfter <= 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+
+			return controllerruntime.Result{RequeueAfter: requeueAfter}, nil
+		}
+
+		// The synchronized condition is only present while the content is applied
+		if meta.FindStatusCondition(*conditions, synchronized) != nil {
+			logf.FromContext(ctx).Info("removing content after its source was deleted", "gracePeriod", gracePeriod)
+
+			if err := finalize(); err != nil {
+				return controllerruntime.Result{}, fmt.Errorf("removing content of deleted source: %w", err)
+			}
+
+			status := cr.GrafanaContentStatus()
+			status.Hash = ""
+			status.UID = ""
+
+			meta.RemoveStatusCondition(conditions, synchronized)
+		}
+	}
+
+	return controllerruntime.Result{RequeueAfter: requeueAfter}, nil
+}
+
 func ignoreStatusUpdates() predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -383,7 +672,233 @@ func ignoreStatusUpdates() predicate.Predicate {
 	}
 }
 
-func buildSynchronizedCondition(resource string, syncType string, generation int64, applyErrors map[string]string, total int) metav1.Condition {
+// statusPageDatasourcesChanged triggers when dashboards copied to the status page of an instance reference other datasources
+func statusPageDatasourcesChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, ok := e.ObjectOld.(*v1beta1.Grafana)
+			if !ok {
+				return false
+			}
+
+			newInstance, ok := e.ObjectNew.(*v1beta1.Grafana)
+			if !ok {
+				return false
+			}
+
+			return !reflect.DeepEqual(oldInstance.Status.StatusPage, newInstance.Status.StatusPage)
+		},
+	}
+}
+
+// approvalChanged triggers when the approved hashes of an instance change
+func approvalChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[annotationApprovedHashes] != e.ObjectNew.GetAnnotations()[annotationApprovedHashes]
+		},
+	}
+}
+
+// instanceScopeIndexKey indexes resources by the namespace of the instances they may target, "*" when they allow
+// cross namespace imports
+const instanceScopeIndexKey = ".spec.instanceScope"
+
+func indexInstanceScope(o client.Object) []string {
+	cr, ok := o.(v1beta1.CommonResource)
+	if !ok {
+		panic(fmt.Sprintf("Expected a CommonResource, got %T", o))
+	}
+
+	if cr.AllowCrossNamespace() {
+		return []string{"*"}
+	}
+
+	return []string{cr.MatchNamespace()}
+}
+
+// instanceRecovered triggers when an instance becomes available again, e.g. once Grafana responds after an outage or
+// content is no longer suspended. Suspension is read from the ContentSuspended condition, spec.suspendContent may be
+// set by the GrafanaClass of the instance. A role set by the class only causes an enqueue the resources skip
+func instanceRecovered() predicate.Predicate {
+	available := func(o client.Object) bool {
+		instance, ok := o.(*v1beta1.Grafana)
+		return ok && provision.IsReady(instance) && !meta.IsStatusConditionTrue(instance.Status.Conditions, conditionContentSuspended) &&
+			!instance.IsReadonly()
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !available(e.ObjectOld) && available(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// enqueueCoalesced maps events to requests like handler.EnqueueRequestsFromMapFunc, but delays the requests by window.
+// The queue keeps the earliest delay of a request, all events within the window result in a single reconcile
+func enqueueCoalesced(mapFn handler.MapFunc, window time.Duration) handler.EventHandler {
+	if window <= 0 {
+		return handler.EnqueueRequestsFromMapFunc(mapFn)
+	}
+
+	enqueue := func(ctx context.Context, o client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		for _, req := range mapFn(ctx, o) {
+			q.AddAfter(req, window)
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.ObjectOld, q)
+			enqueue(ctx, e.ObjectNew, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, q)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, q)
+		},
+	}
+}
+
+// requestsForRecoveredInstance maps a recovered instance to the resources selecting it, so they are applied right away
+// instead of after their resync period. The resources are listed through the instanceScopeIndexKey index
+func requestsForRecoveredInstance(k8sClient client.Client, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		instance, ok := o.(*v1beta1.Grafana)
+		if !ok {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+
+		for _, scope := range []string{instance.Namespace, "*"} {
+			list := newList()
+			if err := k8sClient.List(ctx, list, client.MatchingFields{instanceScopeIndexKey: scope}); err != nil {
+				logf.FromContext(ctx).Error(err, "listing resources for recovered instance", "instance", client.ObjectKeyFromObject(instance))
+				return nil
+			}
+
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil
+			}
+
+			for _, item := range items {
+				cr, ok := item.(v1beta1.CommonResource)
+				if !ok || !selectsInstance(cr.MatchLabels(), instance) {
+					continue
+				}
+
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+			}
+		}
+
+		if len(reqs) > 0 {
+			logf.FromContext(ctx).Info("instance recovered, requeueing resources", "instance", client.ObjectKeyFromObject(instance), "count", len(reqs))
+		}
+
+		return reqs
+	}
+}
+
+// jsonnetLibIndexKey indexes content resources with a spec.jsonnetLibSelector by their namespace
+const jsonnetLibIndexKey = ".spec.jsonnetLibSelector"
+
+func indexJsonnetLibSelector(o client.Object) []string {
+	cr, ok := o.(v1beta1.GrafanaContentResource)
+	if !ok {
+		panic(fmt.Sprintf("Expected a GrafanaContentResource, got %T", o))
+	}
+
+	if cr.GrafanaContentSpec().JsonnetLibSelector == nil {
+		return nil
+	}
+
+	return []string{cr.GetNamespace()}
+}
+
+// requestsForJsonnetLib maps a ConfigMap to the content resources in its namespace importing it as jsonnet library
+func requestsForJsonnetLib(k8sClient client.Client, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		list := newList()
+		if err := k8sClient.List(ctx, list, client.MatchingFields{jsonnetLibIndexKey: o.GetNamespace()}); err != nil {
+			return nil
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil
+		}
+
+		var reqs []reconcile.Request
+
+		for _, item := range items {
+			cr, ok := item.(v1beta1.GrafanaContentResource)
+			if !ok {
+				continue
+			}
+
+			selector, err := metav1.LabelSelectorAsSelector(cr.GrafanaContentSpec().JsonnetLibSelector)
+			if err != nil || !selector.Matches(labels.Set(o.GetLabels())) {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		}
+
+		return reqs
+	}
+}
+
+// selectsInstance evaluates an instanceSelector against an instance like provision.MatchingInstances
+func selectsInstance(selector *metav1.LabelSelector, instance *v1beta1.Grafana) bool {
+	if selector == nil {
+		return false
+	}
+
+	for key, value := range selector.MatchLabels {
+		if label, ok := instance.Labels[key]; !ok || label != value {
+			return false
+		}
+	}
+
+	return labelsSatisfyMatchExpressions(instance.Labels, selector.MatchExpressions)
+}
+
+// buildSynchronizedCondition summarizes applying a resource to its instances. Errors of the Grafana API are classified,
+// the reason of the condition is the one shared by all errors or ApplyFailed
+func buildSynchronizedCondition(resource string, syncType string, generation int64, applyErrors map[string]error, total int) metav1.Condition {
 	condition := metav1.Condition{
 		Type:               syncType,
 		ObservedGeneration: generation,
@@ -398,11 +913,25 @@ func buildSynchronizedCondition(resource string, syncType string, generation int
 		condition.Message = fmt.Sprintf("%s was successfully applied to %d instances", resource, total)
 	} else {
 		condition.Status = metav1.ConditionFalse
-		condition.Reason = conditionReasonApplyFailed
 
 		var sb strings.Builder
-		for i, err := range applyErrors {
-			sb.WriteString(fmt.Sprintf("\n- %s: %s", i, err))
+
+		for i, key := range slices.Sorted(maps.Keys(applyErrors)) {
+			reason := applyErrorReason(applyErrors[key])
+			metrics.ApplyErrors.WithLabelValues(syncType, reason).Inc()
+
+			switch {
+			case i == 0:
+				condition.Reason = reason
+			case condition.Reason != reason:
+				condition.Reason = conditionReasonApplyFailed
+			}
+
+			if reason == conditionReasonApplyFailed {
+				sb.WriteString(fmt.Sprintf("\n- %s: %s", key, applyErrors[key]))
+			} else {
+				sb.WriteString(fmt.Sprintf("\n- %s: %s: %s", key, reason, applyErrors[key]))
+			}
 		}
 
 		condition.Message = fmt.Sprintf("%s failed to be applied for %d out of %d instances. Errors:%s", resource, len(applyErrors), total, sb.String())
@@ -411,18 +940,33 @@ func buildSynchronizedCondition(resource string, syncType string, generation int
 	return condition
 }
 
+// applyErrorReason returns the reason of a Grafana API error, ApplyFailed for unknown errors
+func applyErrorReason(err error) string {
+	if reason := client2.ClassifyError(err); reason != "" {
+		return reason
+	}
+
+	return conditionReasonApplyFailed
+}
+
 func getReferencedValue(ctx context.Context, cl client.Client, cr metav1.ObjectMetaAccessor, source v1beta1.ValueFromSource) (string, string, error) {
 	objMeta := cr.GetObjectMeta()
+	namespace := source.ResolveNamespace(objMeta.GetNamespace())
 
 	if source.SecretKeyRef != nil {
 		s := &corev1.Secret{}
 
-		err := cl.Get(ctx, client.ObjectKey{Namespace: objMeta.GetNamespace(), Name: source.SecretKeyRef.Name}, s)
+		err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: source.SecretKeyRef.Name}, s)
 		if err != nil {
 			return "", "", err
 		}
 
+		if !allowsNamespace(s, objMeta.GetNamespace()) {
+			return "", "", fmt.Errorf("secret %s/%s does not allow references from namespace %s", namespace, source.SecretKeyRef.Name, objMeta.GetNamespace())
+		}
+
 		if val, ok := s.Data[source.SecretKeyRef.Key]; ok {
+			logging.RegisterSecret(string(val))
 			return string(val), source.SecretKeyRef.Key, nil
 		} else {
 			return "", "", fmt.Errorf("missing key %s in secret %s", source.SecretKeyRef.Key, source.SecretKeyRef.Name)
@@ -430,11 +974,15 @@ func getReferencedValue(ctx context.Context, cl client.Client, cr metav1.ObjectM
 	} else {
 		s := &corev1.ConfigMap{}
 
-		err := cl.Get(ctx, client.ObjectKey{Namespace: objMeta.GetNamespace(), Name: source.ConfigMapKeyRef.Name}, s)
+		err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: source.ConfigMapKeyRef.Name}, s)
 		if err != nil {
 			return "", "", err
 		}
 
+		if !allowsNamespace(s, objMeta.GetNamespace()) {
+			return "", "", fmt.Errorf("configmap %s/%s does not allow references from namespace %s", namespace, source.ConfigMapKeyRef.Name, objMeta.GetNamespace())
+		}
+
 		if val, ok := s.Data[source.ConfigMapKeyRef.Key]; ok {
 			return val, source.ConfigMapKeyRef.Key, nil
 		} else {
@@ -443,6 +991,28 @@ func getReferencedValue(ctx context.Context, cl client.Client, cr metav1.ObjectM
 	}
 }
 
+// allowsNamespace checks if obj can be referenced from the given namespace.
+// Objects can always be referenced from their own namespace, other namespaces need to be listed in the allowed-namespaces annotation
+func allowsNamespace(obj metav1.Object, namespace string) bool {
+	if obj.GetNamespace() == namespace {
+		return true
+	}
+
+	allowed, ok := obj.GetAnnotations()[annotationAllowedNamespaces]
+	if !ok {
+		return false
+	}
+
+	for ns := range strings.SplitSeq(allowed, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Add finalizer through a MergePatch
 // Avoids updating the entire object and only changes the finalizers
 func addFinalizer(ctx context.Context, cl client.Client, cr client.Object) error {
@@ -519,19 +1089,15 @@ func removeAnnotation(ctx context.Context, cl client.Client, cr client.Object, k
 	return cl.Patch(ctx, cr, client.RawPatch(types.JSONPatchType, patch))
 }
 
-func mergeReconcileErrors(sources ...map[string]string) map[string]string {
-	merged := make(map[string]string)
+func mergeReconcileErrors(sources ...map[string]error) map[string]error {
+	merged := make(map[string]error)
 
 	for _, source := range sources {
-		if source == nil {
-			source = make(map[string]string)
-		}
-
 		for k, v := range source {
-			if merged[k] == "" {
+			if merged[k] == nil {
 				merged[k] = v
 			} else {
-				merged[k] = fmt.Sprintf("%v; %v", merged[k], v)
+				merged[k] = fmt.Errorf("%w; %w", merged[k], v)
 			}
 		}
 	}
@@ -544,11 +1110,12 @@ type statusResource interface {
 	CommonStatus() *v1beta1.GrafanaCommonStatus
 }
 
-func UpdateStatus(ctx context.Context, cl client.Client, cr statusResource) {
+// UpdateStatus patches the status changes made to cr since original was read and manages the finalizer
+func UpdateStatus(ctx context.Context, cl client.Client, cr statusResource, original client.Object) {
 	log := logf.FromContext(ctx)
 
 	cr.CommonStatus().LastResync = metav1.Time{Time: time.Now()}
-	if err := cl.Status().Update(ctx, cr); err != nil {
+	if err := patchStatus(ctx, cl, original, cr); err != nil {
 		log.Error(err, "updating status")
 	}
 
@@ -562,3 +1129,193 @@ func UpdateStatus(ctx context.Context, cl client.Client, cr statusResource) {
 		}
 	}
 }
+
+// patchStatus writes the status fields changed between original and cr as a merge patch guarded by the resourceVersion.
+// On conflicts the changes are applied on top of the latest object and retried, conditions are merged by type,
+// so fields written concurrently by other controllers or replicas are kept
+func patchStatus(ctx context.Context, cl client.Client, original, cr client.Object) error {
+	before, err := statusFields(original)
+	if err != nil {
+		return err
+	}
+
+	after, err := statusFields(cr)
+	if err != nil {
+		return err
+	}
+
+	redactStatusMessages(after)
+
+	beforeConditions := conditionsByType(before["conditions"])
+	afterConditions := conditionsByType(after["conditions"])
+
+	delete(before, "conditions")
+	delete(after, "conditions")
+
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	fieldsPatch, err := jsonpatch.CreateMergePatch(beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("creating status patch: %w", err)
+	}
+
+	// The first attempt expects the object to be unchanged since original was read
+	latest, ok := original.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", original)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := statusFields(latest)
+		if err != nil {
+			return err
+		}
+
+		status := map[string]any{}
+		if err := json.Unmarshal(fieldsPatch, &status); err != nil {
+			return err
+		}
+
+		if conditions, changed := mergeConditions(current["conditions"], beforeConditions, afterConditions); changed {
+			status["conditions"] = conditions
+		}
+
+		if len(status) == 0 {
+			return nil
+		}
+
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{"resourceVersion": latest.GetResourceVersion()},
+			"status":   status,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = cl.Status().Patch(ctx, cr, client.RawPatch(types.MergePatchType, patch))
+		if kuberr.IsConflict(err) {
+			if getErr := cl.Get(ctx, client.ObjectKeyFromObject(cr), latest); getErr != nil {
+				return getErr
+			}
+		}
+
+		return err
+	})
+}
+
+func statusFields(obj client.Object) (map[string]any, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields struct {
+		Status map[string]any `json:"status"`
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	if fields.Status == nil {
+		fields.Status = map[string]any{}
+	}
+
+	return fields.Status, nil
+}
+
+// redactStatusMessages removes secure values, e.g. of error messages, from the lastMessage and the condition messages
+func redactStatusMessages(status map[string]any) {
+	if msg, ok := status["lastMessage"].(string); ok {
+		status["lastMessage"] = logging.Redact(msg)
+	}
+
+	list, _ := status["conditions"].([]any)
+	for _, c := range list {
+		if condition, ok := c.(map[string]any); ok {
+			if msg, ok := condition["message"].(string); ok {
+				condition["message"] = logging.Redact(msg)
+			}
+		}
+	}
+}
+
+func conditionsByType(conditions any) map[string]any {
+	byType := map[string]any{}
+
+	list, _ := conditions.([]any)
+	for _, c := range list {
+		if condition, ok := c.(map[string]any); ok {
+			if t, ok := condition["type"].(string); ok {
+				byType[t] = condition
+			}
+		}
+	}
+
+	return byType
+}
+
+// mergeConditions applies the conditions set and removed between before and after on top of the current conditions
+func mergeConditions(current any, before, after map[string]any) ([]any, bool) {
+	changed := false
+
+	merged := conditionsByType(current)
+	order := make([]string, 0, len(merged))
+
+	list, _ := current.([]any)
+	for _, c := range list {
+		if condition, ok := c.(map[string]any); ok {
+			if t, ok := condition["type"].(string); ok {
+				order = append(order, t)
+			}
+		}
+	}
+
+	for t := range before {
+		if _, ok := after[t]; !ok {
+			if _, exists := merged[t]; exists {
+				delete(merged, t)
+
+				changed = true
+			}
+		}
+	}
+
+	afterTypes := make([]string, 0, len(after))
+	for t := range after {
+		afterTypes = append(afterTypes, t)
+	}
+
+	slices.Sort(afterTypes)
+
+	for _, t := range afterTypes {
+		if reflect.DeepEqual(before[t], after[t]) {
+			continue
+		}
+
+		if _, exists := merged[t]; !exists {
+			order = append(order, t)
+		}
+
+		merged[t] = after[t]
+		changed = true
+	}
+
+	conditions := make([]any, 0, len(merged))
+
+	for _, t := range order {
+		if c, ok := merged[t]; ok {
+			conditions = append(conditions, c)
+		}
+	}
+
+	return conditions, changed
+}
@@ -17,17 +17,35 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reusable objectMetas and CommonSpecs to make test tables less verbose
@@ -415,23 +433,258 @@ func TestUpdatePluginConfigMap(t *testing.T) {
 	}
 }
 
+func TestAllowsNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		namespace   string
+		want        bool
+	}{
+		{
+			name:      "Same namespace is always allowed",
+			namespace: "secrets",
+			want:      true,
+		},
+		{
+			name:      "Other namespace without annotation",
+			namespace: "team-a",
+			want:      false,
+		},
+		{
+			name:        "Other namespace listed in annotation",
+			annotations: map[string]string{annotationAllowedNamespaces: "team-b, team-a"},
+			namespace:   "team-a",
+			want:        true,
+		},
+		{
+			name:        "Other namespace not listed in annotation",
+			annotations: map[string]string{annotationAllowedNamespaces: "team-b"},
+			namespace:   "team-a",
+			want:        false,
+		},
+		{
+			name:        "Wildcard allows all namespaces",
+			annotations: map[string]string{annotationAllowedNamespaces: "*"},
+			namespace:   "team-a",
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "secrets",
+					Name:        "credentials",
+					Annotations: tt.annotations,
+				},
+			}
+
+			got := allowsNamespace(secret, tt.namespace)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequeueAfter(t *testing.T) {
+	cfg := &Config{ResyncPeriod: 10 * time.Minute, ResyncJitterPercent: 20}
+	none := 0
+	half := 50
+
+	assert.Equal(t, time.Duration(0), (*Config)(nil).requeueAfter(metav1.Duration{}, nil))
+	assert.Equal(t, time.Minute, (*Config)(nil).requeueAfter(metav1.Duration{Duration: time.Minute}, nil))
+	assert.Equal(t, 10*time.Minute, cfg.requeueAfter(metav1.Duration{}, &none))
+	assert.Equal(t, 5*time.Minute, cfg.requeueAfter(metav1.Duration{Duration: 5 * time.Minute}, &none))
+
+	for range 100 {
+		got := cfg.requeueAfter(metav1.Duration{}, nil)
+		assert.GreaterOrEqual(t, got, 10*time.Minute)
+		assert.Less(t, got, 12*time.Minute)
+
+		got = cfg.requeueAfter(metav1.Duration{Duration: time.Minute}, &half)
+		assert.GreaterOrEqual(t, got, time.Minute)
+		assert.Less(t, got, 90*time.Second)
+	}
+}
+
+func TestRequeueWithinWindow(t *testing.T) {
+	now := time.Now()
+	end := metav1.NewTime(now.Add(time.Minute))
+	window := &v1beta1.ActiveWindow{End: &end}
+
+	assert.Equal(t, 10*time.Minute, requeueWithinWindow(10*time.Minute, nil, now))
+	assert.Equal(t, time.Minute, requeueWithinWindow(10*time.Minute, window, now))
+	assert.Equal(t, time.Minute, requeueWithinWindow(0, window, now))
+	assert.Equal(t, 30*time.Second, requeueWithinWindow(30*time.Second, window, now))
+}
+
+func TestPendingWindow(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	opens := map[string]time.Time{
+		"default/b": now.Add(2 * time.Hour),
+		"default/a": now.Add(time.Hour),
+	}
+
+	assert.Equal(t, time.Hour, requeueForPendingWindow(10*time.Hour, opens, now))
+	assert.Equal(t, time.Hour, requeueForPendingWindow(0, opens, now))
+	assert.Equal(t, 30*time.Minute, requeueForPendingWindow(30*time.Minute, opens, now))
+
+	var conditions []metav1.Condition
+
+	setPendingWindow(&conditions, 2, opens)
+
+	condition := meta.FindStatusCondition(conditions, conditionPendingWindow)
+	require.NotNil(t, condition)
+	assert.Equal(t, conditionReasonWindowClosed, condition.Reason)
+	assert.Equal(t, "Changes to 2 instances are deferred until their change window opens:\n- default/a: 2026-10-15T13:00:00Z\n- default/b: 2026-10-15T14:00:00Z", condition.Message)
+
+	removePendingWindow(&conditions)
+	assert.Empty(t, conditions)
+}
+
+func TestDashboardChangePending(t *testing.T) {
+	cr := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "overview"},
+		Status: v1beta1.GrafanaDashboardStatus{
+			GrafanaContentStatus: v1beta1.GrafanaContentStatus{Hash: "applied"},
+		},
+	}
+	grafana := &v1beta1.Grafana{
+		Status: v1beta1.GrafanaStatus{Dashboards: v1beta1.NamespacedResourceList{"default/overview/uid"}},
+	}
+
+	assert.False(t, dashboardChangePending(cr, grafana, "applied"))
+	assert.True(t, dashboardChangePending(cr, grafana, "changed"))
+	assert.True(t, dashboardChangePending(cr, &v1beta1.Grafana{}, "applied"))
+}
+
+func TestSetContentStale(t *testing.T) {
+	cr := &v1beta1.GrafanaDashboard{
+		TypeMeta:   metav1.TypeMeta{Kind: "GrafanaDashboard"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stale"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{StaleThreshold: metav1.Duration{Duration: time.Minute}},
+		},
+		Status: v1beta1.GrafanaDashboardStatus{
+			GrafanaContentStatus: v1beta1.GrafanaContentStatus{ContentTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		},
+	}
+
+	labels := contentMetricLabels(cr)
+
+	setContentStale(t.Context(), &cr.Status.Conditions, cr.Generation, cr, errors.New("unreachable"))
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionContentStale))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ContentStale.With(labels)))
+
+	// The series is removed once the source recovers
+	setContentStale(t.Context(), &cr.Status.Conditions, cr.Generation, cr, nil)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionContentStale))
+	assert.False(t, metrics.ContentStale.Delete(labels))
+}
+
+func TestOnSourceDeleted(t *testing.T) {
+	ctx := context.Background()
+	notFound := fmt.Errorf("%w: %w", fetchers.ErrSourceNotFound, kuberr.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "dashboards"))
+
+	newDashboard := func(onSourceDeletion *v1beta1.SourceDeletion) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{OnSourceDeletion: onSourceDeletion},
+			},
+			Status: v1beta1.GrafanaDashboardStatus{
+				GrafanaContentStatus: v1beta1.GrafanaContentStatus{Hash: "applied", UID: "uid"},
+				GrafanaCommonStatus: v1beta1.GrafanaCommonStatus{
+					Conditions: []metav1.Condition{{Type: conditionDashboardSynchronized, Status: metav1.ConditionTrue}},
+				},
+			},
+		}
+	}
+
+	t.Run("only applies to deleted sources of applied content", func(t *testing.T) {
+		cr := newDashboard(nil)
+
+		assert.True(t, isSourceDeleted(cr, cr.Status.Conditions, fmt.Errorf("fetching: %w", notFound)))
+		assert.False(t, isSourceDeleted(cr, cr.Status.Conditions, fmt.Errorf("invalid json")))
+		assert.False(t, isSourceDeleted(cr, cr.Status.Conditions, kuberr.NewNotFound(schema.GroupResource{Resource: "secrets"}, "credentials")))
+
+		cr.Status.Hash = ""
+		assert.False(t, isSourceDeleted(cr, cr.Status.Conditions, notFound))
+	})
+
+	t.Run("keeps content by default", func(t *testing.T) {
+		cr := newDashboard(nil)
+
+		res, err := onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, notFound, time.Minute, func() error {
+			t.Fatal("content must not be removed")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, res.RequeueAfter)
+		assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionSourceMissing))
+		assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionDashboardSynchronized))
+	})
+
+	t.Run("degrade fails the reconcile", func(t *testing.T) {
+		cr := newDashboard(&v1beta1.SourceDeletion{Policy: v1beta1.SourceDeletionPolicyDegrade})
+
+		_, err := onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, notFound, time.Minute, func() error {
+			t.Fatal("content must not be removed")
+			return nil
+		})
+		require.Error(t, err)
+		assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionInvalidSpec))
+		assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionDashboardSynchronized))
+	})
+
+	t.Run("delete removes content after the grace period", func(t *testing.T) {
+		cr := newDashboard(&v1beta1.SourceDeletion{Policy: v1beta1.SourceDeletionPolicyDelete, GracePeriod: metav1.Duration{Duration: time.Hour}})
+
+		removed := 0
+		finalize := func() error {
+			removed++
+			return nil
+		}
+
+		res, err := onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, notFound, 10*time.Hour, finalize)
+		require.NoError(t, err)
+		assert.Equal(t, 0, removed)
+		assert.InDelta(t, time.Hour, res.RequeueAfter, float64(time.Second))
+
+		// The source is missing for longer than the grace period
+		meta.FindStatusCondition(cr.Status.Conditions, conditionSourceMissing).LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+
+		_, err = onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, notFound, 10*time.Hour, finalize)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+		assert.Empty(t, cr.Status.Hash)
+		assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionDashboardSynchronized))
+
+		// Removed content is left alone while the source is missing
+		assert.True(t, isSourceDeleted(cr, cr.Status.Conditions, notFound))
+
+		_, err = onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, notFound, 10*time.Hour, finalize)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removed)
+	})
+}
+
 func TestMergeReconcileErrors(t *testing.T) {
 	tests := []struct {
 		name    string
-		sources []map[string]string
+		sources []map[string]error
 		want    map[string]string
 	}{
 		{
 			name: "Merge multiple maps",
-			sources: []map[string]string{
+			sources: []map[string]error{
 				{
-					"default-grafana": "error1",
+					"default-grafana": errors.New("error1"),
 				},
 				{
-					"default-grafana": "error2",
+					"default-grafana": errors.New("error2"),
 				},
 				{
-					"default-grafana2": "error3",
+					"default-grafana2": errors.New("error3"),
 				},
 			},
 			want: map[string]string{
@@ -441,10 +694,10 @@ func TestMergeReconcileErrors(t *testing.T) {
 		},
 		{
 			name: "Nil maps are properly handled",
-			sources: []map[string]string{
+			sources: []map[string]error{
 				nil,
 				{
-					"default-grafana": "error1",
+					"default-grafana": errors.New("error1"),
 				},
 			},
 			want: map[string]string{
@@ -453,7 +706,7 @@ func TestMergeReconcileErrors(t *testing.T) {
 		},
 		{
 			name: "Empty maps are properly handled",
-			sources: []map[string]string{
+			sources: []map[string]error{
 				{},
 				{},
 			},
@@ -463,10 +716,61 @@ func TestMergeReconcileErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := mergeReconcileErrors(tt.sources...)
+			got := make(map[string]string)
+			for k, err := range mergeReconcileErrors(tt.sources...) {
+				got[k] = err.Error()
+			}
+
 			assert.Equal(t, tt.want, got)
 		})
 	}
+
+	t.Run("Merged errors are still classified", func(t *testing.T) {
+		merged := mergeReconcileErrors(
+			map[string]error{"default-grafana": errors.New("error1")},
+			map[string]error{"default-grafana": &client2.StatusError{StatusCode: http.StatusForbidden}},
+		)
+
+		assert.Equal(t, client2.ReasonAuthFailed, client2.ClassifyError(merged["default-grafana"]))
+	})
+}
+
+func TestBuildSynchronizedCondition(t *testing.T) {
+	forbidden := &client2.StatusError{Method: http.MethodPut, Path: "/apis/dashboard", StatusCode: http.StatusForbidden, Body: "access denied"}
+
+	t.Run("applied", func(t *testing.T) {
+		condition := buildSynchronizedCondition("Folder", conditionFolderSynchronized, 2, nil, 3)
+
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, conditionReasonApplySuccessful, condition.Reason)
+		assert.Equal(t, int64(2), condition.ObservedGeneration)
+		assert.Equal(t, "Folder was successfully applied to 3 instances", condition.Message)
+	})
+
+	t.Run("shared reason", func(t *testing.T) {
+		condition := buildSynchronizedCondition("Folder", conditionFolderSynchronized, 2, map[string]error{
+			"default/a": fmt.Errorf("creating folder: %w", forbidden),
+			"default/b": forbidden,
+		}, 3)
+
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, client2.ReasonAuthFailed, condition.Reason)
+		assert.Equal(t, "Folder failed to be applied for 2 out of 3 instances. Errors:"+
+			"\n- default/a: AuthFailed: creating folder: PUT /apis/dashboard failed with status 403: access denied"+
+			"\n- default/b: AuthFailed: PUT /apis/dashboard failed with status 403: access denied", condition.Message)
+	})
+
+	t.Run("different reasons", func(t *testing.T) {
+		condition := buildSynchronizedCondition("Folder", conditionFolderSynchronized, 2, map[string]error{
+			"default/a": forbidden,
+			"default/b": errors.New("connection refused"),
+		}, 2)
+
+		assert.Equal(t, conditionReasonApplyFailed, condition.Reason)
+		assert.Equal(t, "Folder failed to be applied for 2 out of 2 instances. Errors:"+
+			"\n- default/a: AuthFailed: PUT /apis/dashboard failed with status 403: access denied"+
+			"\n- default/b: connection refused", condition.Message)
+	})
 }
 
 var _ = Describe("GetMatchingInstances functions", Ordered, func() {
@@ -551,3 +855,274 @@ var _ = Describe("GetMatchingInstances functions", Ordered, func() {
 		})
 	})
 })
+
+func TestPatchStatusMergesConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	folder := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Name: "folder", Namespace: "default"},
+		Status: v1beta1.GrafanaFolderStatus{
+			GrafanaCommonStatus: v1beta1.GrafanaCommonStatus{
+				Conditions: []metav1.Condition{
+					{Type: conditionSuspended, Status: metav1.ConditionTrue, Reason: conditionReasonApplySuspended},
+				},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(folder).WithStatusSubresource(folder).Build()
+
+	cr := &v1beta1.GrafanaFolder{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(folder), cr))
+	original := cr.DeepCopy()
+
+	// Another writer changes the status after the reconciler read the object
+	concurrent := cr.DeepCopy()
+	concurrent.Status.Hash = "concurrent"
+	meta.SetStatusCondition(&concurrent.Status.Conditions, metav1.Condition{Type: "Other", Status: metav1.ConditionTrue, Reason: "Other"})
+	require.NoError(t, cl.Status().Update(ctx, concurrent))
+
+	removeSuspended(&cr.Status.Conditions)
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{Type: conditionFolderSynchronized, Status: metav1.ConditionTrue, Reason: conditionReasonApplySuccessful})
+	cr.Status.LastResync = metav1.Now()
+
+	require.NoError(t, patchStatus(ctx, cl, original, cr))
+
+	got := &v1beta1.GrafanaFolder{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(folder), got))
+
+	assert.Equal(t, "concurrent", got.Status.Hash)
+	assert.False(t, got.Status.LastResync.IsZero())
+	assert.Nil(t, meta.FindStatusCondition(got.Status.Conditions, conditionSuspended))
+	assert.NotNil(t, meta.FindStatusCondition(got.Status.Conditions, "Other"))
+	assert.NotNil(t, meta.FindStatusCondition(got.Status.Conditions, conditionFolderSynchronized))
+}
+
+func TestPatchStatusRedactsMessages(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	datasource := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{Name: "loki", Namespace: "default"},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(datasource).WithStatusSubresource(datasource).Build()
+
+	cr := &v1beta1.GrafanaDatasource{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(datasource), cr))
+	original := cr.DeepCopy()
+
+	cr.Status.LastMessage = `Authorization: Bearer glsa_abc123` // nolint:staticcheck
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:   conditionDatasourceSynchronized,
+		Status: metav1.ConditionFalse,
+		Reason: conditionReasonApplyFailed,
+		Message: `Datasource failed to be applied for 1 out of 1 instances. Errors:
+- default/grafana: [PUT /datasources/uid/{uid}][400] {"secureJsonData":{"basicAuthPassword":"s3cr3t"}}`,
+	})
+
+	require.NoError(t, patchStatus(ctx, cl, original, cr))
+
+	got := &v1beta1.GrafanaDatasource{}
+	require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(datasource), got))
+
+	assert.Equal(t, "Authorization: Bearer [REDACTED]", got.Status.LastMessage) // nolint:staticcheck
+
+	condition := meta.FindStatusCondition(got.Status.Conditions, conditionDatasourceSynchronized)
+	require.NotNil(t, condition)
+	assert.NotContains(t, condition.Message, "s3cr3t")
+	assert.Contains(t, condition.Message, `"secureJsonData":"[REDACTED]"`)
+}
+
+func TestRequestsForRecoveredInstance(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	dashboard := func(namespace, name string, labels map[string]string, crossNamespace bool) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1beta1.GrafanaDashboardSpec{GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{
+				InstanceSelector:          &metav1.LabelSelector{MatchLabels: labels},
+				AllowCrossNamespaceImport: crossNamespace,
+			}},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).
+		WithIndex(&v1beta1.GrafanaDashboard{}, instanceScopeIndexKey, indexInstanceScope).
+		WithObjects(
+			dashboard("default", "matching", map[string]string{"dashboards": "grafana"}, false),
+			dashboard("default", "other-labels", map[string]string{"dashboards": "other"}, false),
+			dashboard("other", "other-namespace", map[string]string{"dashboards": "grafana"}, false),
+			dashboard("other", "cross-namespace", map[string]string{"dashboards": "grafana"}, true),
+		).Build()
+
+	instance := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", Labels: map[string]string{"dashboards": "grafana"}},
+	}
+
+	reqs := requestsForRecoveredInstance(cl, func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} })(ctx, instance)
+
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "matching"}},
+		{NamespacedName: types.NamespacedName{Namespace: "other", Name: "cross-namespace"}},
+	}, reqs)
+}
+
+func TestInstanceRecovered(t *testing.T) {
+	ready := &v1beta1.Grafana{Status: v1beta1.GrafanaStatus{
+		Stage:       v1beta1.OperatorStageComplete,
+		StageStatus: v1beta1.OperatorStageResultSuccess,
+	}}
+
+	failed := ready.DeepCopy()
+	failed.Status.StageStatus = v1beta1.OperatorStageResultFailed
+
+	// the condition is set from the resolved spec, suspendContent may come from the class
+	suspended := ready.DeepCopy()
+	suspended.Status.Conditions = []metav1.Condition{{Type: conditionContentSuspended, Status: metav1.ConditionTrue}}
+
+	p := instanceRecovered()
+
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: failed, ObjectNew: ready}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: suspended, ObjectNew: ready}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: ready}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: failed}))
+	assert.False(t, p.Create(event.CreateEvent{Object: ready}))
+}
+
+func TestNoMatchingInstancesResult(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "default"},
+		Spec: v1beta1.GrafanaDashboardSpec{GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{
+			InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}},
+		}},
+	}
+
+	starting := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", Labels: map[string]string{"dashboards": "grafana"}},
+		Status: v1beta1.GrafanaStatus{
+			Startup: &v1beta1.GrafanaStartup{Phase: v1beta1.GrafanaStartupStarting, Since: metav1.Now()},
+		},
+	}
+
+	t.Run("no starting instances", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).Build()
+
+		result, err := noMatchingInstancesResult(t.Context(), cl, dashboard)
+		require.ErrorIs(t, err, ErrNoMatchingInstances)
+		assert.Zero(t, result.RequeueAfter)
+	})
+
+	t.Run("starting instance", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(starting).Build()
+
+		result, err := noMatchingInstancesResult(t.Context(), cl, dashboard)
+		require.NoError(t, err)
+		assert.Positive(t, result.RequeueAfter)
+	})
+
+	t.Run("instance suspending content", func(t *testing.T) {
+		suspended := starting.DeepCopy()
+		suspended.Spec.SuspendContent = true
+		suspended.Status = v1beta1.GrafanaStatus{Stage: v1beta1.OperatorStageComplete, StageStatus: v1beta1.OperatorStageResultSuccess}
+
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(suspended).Build()
+
+		cr := dashboard.DeepCopy()
+		setNoMatchingInstancesCondition(&cr.Status.Conditions, cr.Generation, nil)
+
+		result, err := noMatchingInstancesResult(t.Context(), cl, cr)
+		require.NoError(t, err)
+		assert.Equal(t, suspendedInstancesDelay, result.RequeueAfter)
+
+		// the finalizer is kept, the content is removed once the instance resumes
+		assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionNoMatchingInstance))
+
+		condition := meta.FindStatusCondition(cr.Status.Conditions, conditionSuspended)
+		require.NotNil(t, condition)
+		assert.Equal(t, conditionReasonInstancesSuspended, condition.Reason)
+		assert.Contains(t, condition.Message, "grafana")
+
+		wait, err := waitForSuspendedInstances(t.Context(), cl, cr)
+		require.NoError(t, err)
+		assert.True(t, wait)
+
+		wait, err = waitForSuspendedInstances(t.Context(), fake.NewClientBuilder().WithScheme(s).Build(), cr)
+		require.NoError(t, err)
+		assert.False(t, wait)
+	})
+}
+
+func TestRequestsForJsonnetLib(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	dashboard := func(namespace, name string, selector *metav1.LabelSelector) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1beta1.GrafanaDashboardSpec{GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				JsonnetLibSelector: selector,
+			}},
+		}
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"jsonnet": "grafonnet"}}
+
+	cl := fake.NewClientBuilder().WithScheme(s).
+		WithIndex(&v1beta1.GrafanaDashboard{}, jsonnetLibIndexKey, indexJsonnetLibSelector).
+		WithObjects(
+			dashboard("default", "matching", selector),
+			dashboard("default", "other-selector", &metav1.LabelSelector{MatchLabels: map[string]string{"jsonnet": "other"}}),
+			dashboard("default", "no-selector", nil),
+			dashboard("other", "other-namespace", selector),
+		).Build()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafonnet", Namespace: "default", Labels: map[string]string{"jsonnet": "grafonnet"}},
+	}
+
+	reqs := requestsForJsonnetLib(cl, func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} })(ctx, cm)
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "matching"}},
+	}, reqs)
+}
+
+func TestEnqueueCoalesced(t *testing.T) {
+	ctx := context.Background()
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "grafana"}}
+	mapFn := func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{req}
+	}
+
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	h := enqueueCoalesced(mapFn, 100*time.Millisecond)
+
+	ds := &v1beta1.GrafanaDatasource{}
+	for range 3 {
+		h.Update(ctx, event.UpdateEvent{ObjectOld: ds, ObjectNew: ds}, q)
+	}
+
+	assert.Equal(t, 0, q.Len(), "requests are delayed by the window")
+
+	assert.Eventually(t, func() bool { return q.Len() == 1 }, time.Second, 10*time.Millisecond)
+
+	got, _ := q.Get()
+	assert.Equal(t, req, got)
+	q.Done(got)
+
+	// Without a window, requests are added right away
+	enqueueCoalesced(mapFn, 0).Create(ctx, event.CreateEvent{Object: ds}, q)
+	assert.Equal(t, 1, q.Len())
+}
@@ -0,0 +1,198 @@
+// Package crdcheck compares the CRDs installed in the cluster with the CRDs the operator was built with.
+package crdcheck
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Drift describes how an installed CRD falls behind the CRD the operator expects
+type Drift struct {
+	Name string
+	// NotInstalled is set when the CRD does not exist in the cluster
+	NotInstalled bool
+	// MissingVersions lists expected versions the installed CRD does not serve
+	MissingVersions []string
+	// MissingFields lists the paths of schema fields unknown to the installed CRD, per version
+	MissingFields []string
+}
+
+// Stale returns true when the installed CRD is missing or behind the expected one
+func (d Drift) Stale() bool {
+	return d.NotInstalled || len(d.MissingVersions) > 0 || len(d.MissingFields) > 0
+}
+
+func (d Drift) String() string {
+	if d.NotInstalled {
+		return fmt.Sprintf("%s is not installed", d.Name)
+	}
+
+	var parts []string
+
+	if len(d.MissingVersions) > 0 {
+		parts = append(parts, fmt.Sprintf("versions not served: %s", strings.Join(d.MissingVersions, ", ")))
+	}
+
+	if len(d.MissingFields) > 0 {
+		parts = append(parts, fmt.Sprintf("fields missing from the schema: %s", strings.Join(d.MissingFields, ", ")))
+	}
+
+	return fmt.Sprintf("%s is outdated, %s", d.Name, strings.Join(parts, "; "))
+}
+
+// LoadExpected parses the CRD manifests at the root of fsys
+func LoadExpected(fsys fs.FS) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	files, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	crds := make([]apiextensionsv1.CustomResourceDefinition, 0, len(files))
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		crd := apiextensionsv1.CustomResourceDefinition{}
+
+		err = yaml.Unmarshal(data, &crd)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path.Base(file), err)
+		}
+
+		crds = append(crds, crd)
+	}
+
+	return crds, nil
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// Check fetches the installed counterpart of every expected CRD and returns the stale ones
+func Check(ctx context.Context, restConfig *rest.Config, expected []apiextensionsv1.CustomResourceDefinition) ([]Drift, error) {
+	scheme := runtime.NewScheme()
+
+	err := apiextensionsv1.AddToScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	return check(ctx, cl, expected)
+}
+
+func check(ctx context.Context, cl client.Reader, expected []apiextensionsv1.CustomResourceDefinition) ([]Drift, error) {
+	var drifts []Drift
+
+	for i := range expected {
+		installed := &apiextensionsv1.CustomResourceDefinition{}
+
+		err := cl.Get(ctx, client.ObjectKey{Name: expected[i].Name}, installed)
+		if err != nil {
+			if kuberr.IsNotFound(err) {
+				drifts = append(drifts, Drift{Name: expected[i].Name, NotInstalled: true})
+				continue
+			}
+
+			return nil, fmt.Errorf("fetching CRD %s: %w", expected[i].Name, err)
+		}
+
+		drift := Compare(&expected[i], installed)
+		if drift.Stale() {
+			drifts = append(drifts, drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// Compare reports the versions and schema fields of expected the installed CRD lacks.
+// Additional versions or fields of the installed CRD are not drift, they belong to newer operator releases.
+func Compare(expected, installed *apiextensionsv1.CustomResourceDefinition) Drift {
+	drift := Drift{Name: expected.Name}
+
+	for _, version := range expected.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+
+		idx := slices.IndexFunc(installed.Spec.Versions, func(v apiextensionsv1.CustomResourceDefinitionVersion) bool {
+			return v.Name == version.Name
+		})
+		if idx < 0 || !installed.Spec.Versions[idx].Served {
+			drift.MissingVersions = append(drift.MissingVersions, version.Name)
+			continue
+		}
+
+		if version.Schema == nil || installed.Spec.Versions[idx].Schema == nil {
+			continue
+		}
+
+		missing := missingFields(version.Schema.OpenAPIV3Schema, installed.Spec.Versions[idx].Schema.OpenAPIV3Schema, "")
+		for _, field := range missing {
+			drift.MissingFields = append(drift.MissingFields, fmt.Sprintf("%s %s", version.Name, field))
+		}
+	}
+
+	sort.Strings(drift.MissingFields)
+
+	return drift
+}
+
+// missingFields returns the paths of the properties of expected that installed does not know.
+// Properties below a missing one are not reported separately.
+func missingFields(expected, installed *apiextensionsv1.JSONSchemaProps, prefix string) []string {
+	if expected == nil || installed == nil {
+		return nil
+	}
+
+	// Unknown fields are kept as they are, the installed CRD accepts anything below this point
+	if installed.XPreserveUnknownFields != nil && *installed.XPreserveUnknownFields && len(installed.Properties) == 0 {
+		return nil
+	}
+
+	var missing []string
+
+	for name, prop := range expected.Properties {
+		fieldPath := name
+		if prefix != "" {
+			fieldPath = prefix + "." + name
+		}
+
+		installedProp, ok := installed.Properties[name]
+		if !ok {
+			missing = append(missing, fieldPath)
+			continue
+		}
+
+		missing = append(missing, missingFields(&prop, &installedProp, fieldPath)...)
+	}
+
+	if expected.Items != nil && expected.Items.Schema != nil && installed.Items != nil {
+		missing = append(missing, missingFields(expected.Items.Schema, installed.Items.Schema, prefix+"[]")...)
+	}
+
+	if expected.AdditionalProperties != nil && expected.AdditionalProperties.Schema != nil && installed.AdditionalProperties != nil {
+		missing = append(missing, missingFields(expected.AdditionalProperties.Schema, installed.AdditionalProperties.Schema, prefix+"[*]")...)
+	}
+
+	return missing
+}
@@ -0,0 +1,103 @@
+package crdcheck
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func loadCRDs(t *testing.T) map[string]apiextensionsv1.CustomResourceDefinition {
+	t.Helper()
+
+	crds, err := LoadExpected(os.DirFS("../../config/crd/bases"))
+	require.NoError(t, err)
+	require.NotEmpty(t, crds)
+
+	byName := map[string]apiextensionsv1.CustomResourceDefinition{}
+	for _, crd := range crds {
+		byName[crd.Name] = crd
+	}
+
+	return byName
+}
+
+func TestCompare(t *testing.T) {
+	crds := loadCRDs(t)
+
+	t.Run("identical CRDs", func(t *testing.T) {
+		for _, crd := range crds {
+			drift := Compare(&crd, crd.DeepCopy())
+			assert.False(t, drift.Stale(), drift.String())
+		}
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		expected := crds["grafanadashboards.grafana.integreatly.org"]
+		installed := expected.DeepCopy()
+
+		spec := installed.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"]
+		delete(spec.Properties, "folderUID")
+
+		instanceSelector := spec.Properties["instanceSelector"]
+		delete(instanceSelector.Properties, "matchExpressions")
+		spec.Properties["instanceSelector"] = instanceSelector
+
+		installed.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"] = spec
+
+		drift := Compare(&expected, installed)
+		assert.True(t, drift.Stale())
+		assert.Empty(t, drift.MissingVersions)
+		assert.Equal(t, []string{
+			"v1beta1 spec.folderUID",
+			"v1beta1 spec.instanceSelector.matchExpressions",
+		}, drift.MissingFields)
+	})
+
+	t.Run("fields of newer releases", func(t *testing.T) {
+		installed := crds["grafanadashboards.grafana.integreatly.org"]
+		expected := installed.DeepCopy()
+
+		delete(expected.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties, "status")
+
+		drift := Compare(expected, &installed)
+		assert.False(t, drift.Stale())
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		expected := crds["grafanas.grafana.integreatly.org"]
+		installed := expected.DeepCopy()
+		installed.Spec.Versions[0].Name = "v1alpha1"
+
+		drift := Compare(&expected, installed)
+		assert.Equal(t, []string{"v1beta1"}, drift.MissingVersions)
+		assert.Equal(t, "grafanas.grafana.integreatly.org is outdated, versions not served: v1beta1", drift.String())
+	})
+}
+
+func TestCheck(t *testing.T) {
+	crds := loadCRDs(t)
+
+	s := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(s))
+
+	dashboards := crds["grafanadashboards.grafana.integreatly.org"]
+	folders := crds["grafanafolders.grafana.integreatly.org"]
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(dashboards.DeepCopy()).Build()
+
+	drifts, err := check(t.Context(), cl, []apiextensionsv1.CustomResourceDefinition{dashboards, folders})
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+	assert.Equal(t, Drift{Name: folders.Name, NotInstalled: true}, drifts[0])
+
+	require.NoError(t, cl.Create(t.Context(), folders.DeepCopy()))
+
+	drifts, err = check(t.Context(), cl, []apiextensionsv1.CustomResourceDefinition{dashboards, folders})
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+}
@@ -18,14 +18,21 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"k8s.io/utils/strings/slices"
 
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
 	"github.com/grafana/grafana-openapi-client-go/client/folders"
@@ -34,31 +41,110 @@ import (
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
 	"github.com/grafana/grafana-operator/v5/controllers/content"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	corev1 "k8s.io/api/core/v1"
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	conditionDashboardSynchronized        = "DashboardSynchronized"
+	conditionPendingApproval              = "PendingApproval"
 	conditionReasonInvalidModelResolution = "InvalidModelResolution"
+	conditionReasonJsonnetRenderFailed    = "JsonnetRenderFailed"
+	conditionReasonInvalidDashboardModel  = "InvalidDashboardModel"
+	conditionReasonApprovalRequired       = "ApprovalRequired"
+
+	// approvalDiffLimit bounds the size of the diff in status.pendingApproval
+	approvalDiffLimit = 4096
 )
 
 // GrafanaDashboardReconciler reconciles a GrafanaDashboard object
 type GrafanaDashboardReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Cfg    *Config
+	Scheme   *runtime.Scheme
+	Cfg      *Config
+	Recorder record.EventRecorder
+
+	// GitPushes receives the dashboards of repositories a push event was received for, nil disables the Git webhook
+	GitPushes <-chan event.GenericEvent
+
+	applies recentApplies
+}
+
+// dashboardApply identifies an apply to an instance by the hash of the applied content, so identical applies of
+// different resources coalesce
+type dashboardApply struct {
+	instance string
+	hash     string
+}
+
+// appliedDashboard is the uid an apply created and when it succeeded
+type appliedDashboard struct {
+	uid       string
+	appliedAt time.Time
+}
+
+// recentApplies remembers successful dashboard applies, so identical applies within
+// Config.DashboardApplyDedupWindow can be skipped
+type recentApplies struct {
+	mu      sync.Mutex
+	entries map[dashboardApply]appliedDashboard
+}
+
+// seen reports whether the apply succeeded within window and removes expired entries
+func (a *recentApplies) seen(apply dashboardApply, window time.Duration, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for k, applied := range a.entries {
+		if now.Sub(applied.appliedAt) >= window {
+			delete(a.entries, k)
+		}
+	}
+
+	_, ok := a.entries[apply]
+
+	return ok
+}
+
+func (a *recentApplies) record(apply dashboardApply, uid string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.entries == nil {
+		a.entries = make(map[dashboardApply]appliedDashboard)
+	}
+
+	a.entries[apply] = appliedDashboard{uid: uid, appliedAt: now}
+}
+
+// forget removes all applies of the dashboard uid, e.g. after deleting the dashboard
+func (a *recentApplies) forget(uid string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for k, applied := range a.entries {
+		if applied.uid == uid {
+			delete(a.entries, k)
+		}
+	}
 }
 
 func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) { //nolint:gocyclo
@@ -79,6 +165,15 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if cr.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(cr, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, cr)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, cr); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaDatasource: %w", err)
 			}
@@ -91,7 +186,7 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, cr)
+	defer UpdateStatus(ctx, r.Client, cr, cr.DeepCopy())
 
 	if cr.Spec.Suspend {
 		setSuspended(&cr.Status.Conditions, cr.Generation, conditionReasonApplySuspended)
@@ -100,11 +195,65 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	removeSuspended(&cr.Status.Conditions)
 
+	if err := cr.Spec.ActiveWindow.Validate(); err != nil {
+		setInvalidSpec(&cr.Status.Conditions, cr.Generation, conditionReasonInvalidWindow, err.Error())
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
+
+		return ctrl.Result{}, fmt.Errorf("invalid active window: %w", err)
+	}
+
+	now := time.Now()
+	if !cr.Spec.ActiveWindow.Active(now) {
+		// The Synchronized condition is only present once the dashboard was applied
+		if meta.FindStatusCondition(cr.Status.Conditions, conditionDashboardSynchronized) != nil {
+			log.Info("removing dashboard outside of its active window")
+
+			if err := r.finalize(ctx, cr); err != nil {
+				return ctrl.Result{}, fmt.Errorf("removing dashboard outside of its active window: %w", err)
+			}
+
+			cr.Status.Hash = ""
+			cr.Status.UID = ""
+		}
+
+		setInactive(&cr.Status.Conditions, cr.Generation, cr.Spec.ActiveWindow)
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
+		// The spec is only evaluated within the window
+		removeInvalidSpec(&cr.Status.Conditions)
+
+		return ctrl.Result{RequeueAfter: cr.Spec.ActiveWindow.NextTransition(now)}, nil
+	}
+
+	removeInactive(&cr.Status.Conditions)
+
 	// Retrieving the model before the loop ensures to exit early in case of failure and not fail once per matching instance
 	resolver := content.NewContentResolver(cr, r.Client)
 
 	dashboardModel, hash, err := resolver.Resolve(ctx)
 	if err != nil {
+		if isSourceDeleted(cr, cr.Status.Conditions, err) {
+			return onSourceDeleted(ctx, cr, &cr.Status.Conditions, conditionDashboardSynchronized, err, r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent), func() error {
+				return r.finalize(ctx, cr)
+			})
+		}
+
+		// Rendering fails the same way until the jsonnet or its libraries change, envs are picked up on the next resync
+		if content.IsRenderError(err) {
+			setInvalidSpec(&cr.Status.Conditions, cr.Generation, conditionReasonJsonnetRenderFailed, err.Error())
+			meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
+
+			return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent)}, nil
+		}
+
+		// Invalid content fails the same way until the source changes
+		if content.IsInvalidJSON(err) {
+			cr.Status.ModelErrors = nil
+			setInvalidSpec(&cr.Status.Conditions, cr.Generation, conditionReasonInvalidDashboardModel, err.Error())
+			meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
+
+			return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent)}, nil
+		}
+
 		// Resolve has a lot of failure cases.
 		// fetch content errors could be a temporary network issue but would result in an InvalidSpec condition
 		setInvalidSpec(&cr.Status.Conditions, cr.Generation, conditionReasonInvalidModelResolution, err.Error())
@@ -113,7 +262,23 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, fmt.Errorf("resolving dashboard contents: %w", err)
 	}
 
+	// Dashboards are validated even with the webhook, as it only sees inline content. Warnings don't block the dashboard
+	cr.Status.ModelErrors = content.ValidateDashboardModel(dashboardModel)
+	if blocking := content.BlockingModelErrors(cr.Status.ModelErrors); len(blocking) > 0 {
+		first := blocking[0]
+		setInvalidSpec(&cr.Status.Conditions, cr.Generation, conditionReasonInvalidDashboardModel,
+			fmt.Sprintf("%d invalid fields in the dashboard model, see status.modelErrors, first: %s: %s", len(blocking), first.Path, first.Message))
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
+
+		return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent)}, nil
+	}
+
 	removeInvalidSpec(&cr.Status.Conditions)
+	meta.RemoveStatusCondition(&cr.Status.Conditions, conditionSourceMissing)
+	setContentStale(ctx, &cr.Status.Conditions, cr.Generation, cr, resolver.FetchError())
+	checkGrafanaComRevision(ctx, r.Cfg, r.Recorder, cr, &cr.Status.Conditions, cr.Generation)
+
+	hash = applyOwnershipTags(cr, dashboardModel, hash)
 
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, cr)
 	if err != nil {
@@ -129,14 +294,37 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDashboardSynchronized)
 		cr.Status.NoMatchingInstances = true
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, cr)
 	}
 
 	removeNoMatchingInstance(&cr.Status.Conditions)
 	cr.Status.NoMatchingInstances = false
 
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, cr, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&cr.Status.Conditions, cr.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for dashboard", "count", len(instances))
 
+	migrations, err := datasourceUIDMigrations(ctx, r.Client, cr.Namespace, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if migrateDatasourceRefs(dashboardModel, migrations) {
+		log.Info("migrated references to former datasource uids")
+
+		hash = migrationsHash(hash, migrations)
+	}
+
 	uid := fmt.Sprintf("%s", dashboardModel["uid"])
 	log = log.WithValues("uid", uid)
 	ctx = logf.IntoContext(ctx, log)
@@ -161,31 +349,137 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, fmt.Errorf(ErrFetchingFolder, err)
 	}
 
-	applyHomeErrors := make(map[string]string)
-	pluginErrors := make(map[string]string)
-	applyErrors := make(map[string]string)
+	// Dashboards of tenant namespaces are forced into the folder of the namespace
+	tenant, err := r.Cfg.tenantFolders(ctx, r.Client, cr.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if tenant != nil {
+		folderUID = tenantFolderUID(cr.Namespace)
+	}
+
+	applyHomeErrors := make(map[string]error)
+	pluginErrors := make(map[string]error)
+	applyErrors := make(map[string]error)
+	pendingWindow := make(map[string]time.Time)
+	queryFailures := make(map[string][]string)
+
+	var (
+		panels           []panelQueries
+		queriesValidated bool
+	)
+
+	if cr.Spec.QueryValidation != nil {
+		panels = dashboardPanelQueries(dashboardModel, queryValidationRange(cr.Spec.QueryValidation))
+	}
+
+	var (
+		staged   []string
+		stagedOn *v1beta1.Grafana
+	)
+
+	// Folders of spec.folderUID are only verified, the operator neither creates nor modifies them
+	adopting := cr.Spec.FolderUID != "" && tenant == nil
+
+	var (
+		folderTitle   string
+		missingFolder []string
+	)
 
 	for _, grafana := range instances {
+		wait, err := grafana.Spec.ChangeWindow.Until(now)
+		if err != nil {
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
+			continue
+		}
+
+		if wait > 0 {
+			if dashboardChangePending(cr, &grafana, hash) {
+				pendingWindow[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = now.Add(wait)
+			}
+
+			continue
+		}
+
+		if requiresApproval(&grafana) && !isApproved(&grafana, hash) && dashboardChangePending(cr, &grafana, hash) {
+			staged = append(staged, fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name))
+			if stagedOn == nil {
+				stagedOn = grafana.DeepCopy()
+			}
+
+			continue
+		}
+
+		// Older instances accept newer dashboards, but may fail to render them
+		if err := content.CheckSchemaVersion(dashboardModel, grafana.Status.Version); err != nil {
+			cr.Status.ModelErrors = append(cr.Status.ModelErrors, v1beta1.DashboardModelError{
+				Path:    "schemaVersion",
+				Message: fmt.Sprintf("%s/%s: %s", grafana.Namespace, grafana.Name, err),
+				Warning: true,
+			})
+		}
+
+		if tenant != nil {
+			err = ensureTenantFolder(ctx, r.Client, &grafana, cr.Namespace, tenant)
+			if err != nil {
+				applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
+				continue
+			}
+		}
+
 		if grafana.IsInternal() {
 			// first reconcile the plugins
 			// append the requested dashboards to a configmap from where the
 			// grafana reconciler will pick them up
 			err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, cr.Spec.Plugins, cr.GetPluginConfigMapKey(), cr.GetPluginConfigMapDeprecatedKey())
 			if err != nil {
-				pluginErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+				pluginErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
+			}
+		}
+
+		// Queries are only validated for changes, not on every resync
+		if cr.Spec.QueryValidation != nil && dashboardChangePending(cr, &grafana, hash) {
+			queriesValidated = true
+
+			failures := validateDashboardQueries(ctx, r.Client, &grafana, panels, queryValidationRange(cr.Spec.QueryValidation))
+			if len(failures) > 0 {
+				queryFailures[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = failures
+
+				if cr.Spec.QueryValidation.Policy == v1beta1.QueryValidationPolicyBlock {
+					applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = fmt.Errorf("%d queries failed validation", len(failures))
+					continue
+				}
+			}
+		}
+
+		if adopting {
+			title, err := existingFolderTitle(ctx, r.Client, &grafana, folderUID)
+			if err != nil {
+				if errors.Is(err, errFolderNotFound) {
+					missingFolder = append(missingFolder, fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name))
+				}
+
+				applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
+
+				continue
+			}
+
+			if folderTitle == "" {
+				folderTitle = title
 			}
 		}
 
 		// then import the dashboard into the matching grafana instances
-		err = r.onDashboardCreated(ctx, &grafana, cr, dashboardModel, hash, folderUID)
+		err = r.applyDashboard(ctx, &grafana, cr, dashboardModel, hash, folderUID)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 
 		if grafana.Spec.Preferences != nil && uid == grafana.Spec.Preferences.HomeDashboardUID {
 			err = r.UpdateHomeDashboard(ctx, grafana, uid, cr)
 			if err != nil {
-				applyHomeErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+				applyHomeErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 			}
 		}
 	}
@@ -202,6 +496,43 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	allApplyErrors := mergeReconcileErrors(applyErrors, pluginErrors, applyHomeErrors)
 
+	if err := r.setDashboardFolder(ctx, cr, adopting, folderTitle, missingFolder); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(queryFailures) > 0 {
+		log.Info("dashboard queries failed validation", "instances", len(queryFailures))
+	}
+
+	switch {
+	case cr.Spec.QueryValidation == nil:
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionQueriesValid)
+	case queriesValidated:
+		setQueriesValid(&cr.Status.Conditions, cr.Generation, queryFailures)
+	}
+
+	if len(pendingWindow) > 0 {
+		setPendingWindow(&cr.Status.Conditions, cr.Generation, pendingWindow)
+	} else {
+		removePendingWindow(&cr.Status.Conditions)
+	}
+
+	if len(staged) > 0 {
+		r.stageForApproval(ctx, stagedOn, cr, dashboardModel, hash, staged)
+	} else {
+		cr.Status.PendingApproval = nil
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionPendingApproval)
+	}
+
+	requeueAfter := requeueForPendingWave(requeueWithinWindow(r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent), cr.Spec.ActiveWindow, now), pendingWaves)
+	requeueAfter = requeueForGitPoll(requeueAfter, cr.Spec.GitRepo)
+
+	if len(allApplyErrors) == 0 && (len(pendingWindow) > 0 || len(staged) > 0) {
+		// The hash is kept until the change reached all instances, so it is still detected once their windows open or it is approved
+		log.Info("deferring dashboard changes", "pendingWindow", len(pendingWindow), "pendingApproval", len(staged))
+		return ctrl.Result{RequeueAfter: requeueForPendingWindow(requeueAfter, pendingWindow, now)}, nil
+	}
+
 	condition := buildSynchronizedCondition("Dashboard", conditionDashboardSynchronized, cr.Generation, allApplyErrors, len(instances))
 	meta.SetStatusCondition(&cr.Status.Conditions, condition)
 
@@ -212,7 +543,114 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	cr.Status.Hash = hash
 	cr.Status.UID = uid
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// requiresApproval reports whether dashboard changes to the instance are staged until approved
+func requiresApproval(grafana *v1beta1.Grafana) bool {
+	return grafana.Labels[labelRequiresApproval] == "true"
+}
+
+// isApproved reports whether the approvers of the instance allowed the dashboard model of the hash to be applied to it
+func isApproved(grafana *v1beta1.Grafana, hash string) bool {
+	for approved := range strings.SplitSeq(grafana.Annotations[annotationApprovedHashes], ",") {
+		if strings.TrimSpace(approved) == hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stageForApproval records the staged change in status, the diff is computed against the dashboard in the first staged instance
+func (r *GrafanaDashboardReconciler) stageForApproval(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, dashboardModel map[string]any, hash string, instances []string) {
+	diff, err := r.approvalDiff(ctx, grafana, dashboardModel)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "computing the diff of the staged dashboard", "instance", instances[0])
+	}
+
+	cr.Status.PendingApproval = &v1beta1.PendingApproval{
+		Hash:      hash,
+		Diff:      diff,
+		Instances: instances,
+	}
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionPendingApproval,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cr.Generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonApprovalRequired,
+		Message: fmt.Sprintf("Changes to %d instances require approval, approvers of the instances add status.pendingApproval.hash to their %s annotation to apply them", len(instances), annotationApprovedHashes),
+	})
+}
+
+// approvalDiff returns the JSON merge patch turning the dashboard in the instance into the staged model, the whole model when it does not exist yet
+func (r *GrafanaDashboardReconciler) approvalDiff(ctx context.Context, grafana *v1beta1.Grafana, dashboardModel map[string]any) (string, error) {
+	staged := maps.Clone(dashboardModel)
+	// Assigned by Grafana, not part of the change
+	delete(staged, "id")
+	delete(staged, "version")
+
+	modified, err := json.Marshal(staged)
+	if err != nil {
+		return "", err
+	}
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return "", fmt.Errorf("creating grafana http client: %w", err)
+	}
+
+	diff := modified
+
+	remote, err := grafanaClient.Dashboards.GetDashboardByUID(fmt.Sprintf("%s", dashboardModel["uid"]))
+	if err != nil {
+		var notFound *dashboards.GetDashboardByUIDNotFound
+		if !errors.As(err, &notFound) {
+			return "", err
+		}
+	}
+
+	if remote != nil {
+		remoteModel, ok := remote.GetPayload().Dashboard.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("remote dashboard is not a valid object")
+		}
+
+		delete(remoteModel, "id")
+		delete(remoteModel, "version")
+
+		original, err := json.Marshal(remoteModel)
+		if err != nil {
+			return "", err
+		}
+
+		diff, err = jsonpatch.CreateMergePatch(original, modified)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(diff) > approvalDiffLimit {
+		return strings.ToValidUTF8(string(diff[:approvalDiffLimit]), "") + "...", nil
+	}
+
+	return string(diff), nil
+}
+
+// dashboardChangePending reports whether applying the dashboard would change the instance,
+// either because the model changed since the last apply or because the instance does not have it yet
+func dashboardChangePending(cr *v1beta1.GrafanaDashboard, grafana *v1beta1.Grafana, hash string) bool {
+	if !content.Unchanged(cr, hash) {
+		return true
+	}
+
+	found, _ := grafana.Status.Dashboards.Find(cr.Namespace, cr.Name)
+
+	return !found
 }
 
 func (r *GrafanaDashboardReconciler) finalize(ctx context.Context, cr *v1beta1.GrafanaDashboard) error {
@@ -221,6 +659,12 @@ func (r *GrafanaDashboardReconciler) finalize(ctx context.Context, cr *v1beta1.G
 
 	uid := content.CustomUIDOrUID(cr, cr.Status.UID)
 
+	// A dashboard recreated within the dedup window must be applied again
+	r.applies.forget(uid)
+
+	forgetContentStale(cr)
+	forgetGrafanaComRevision(cr)
+
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, cr)
 	if err != nil {
 		return fmt.Errorf("fetching instances: %w", err)
@@ -232,47 +676,25 @@ func (r *GrafanaDashboardReconciler) finalize(ctx context.Context, cr *v1beta1.G
 			return fmt.Errorf("creating grafana http client: %w", err)
 		}
 
-		isCleanupInGrafanaRequired := true
-
-		resp, err := grafanaClient.Dashboards.GetDashboardByUID(uid)
+		remoteFolderUID, err := r.deleteFromInstance(ctx, &grafana, grafanaClient, uid)
 		if err != nil {
-			var notFound *dashboards.GetDashboardByUIDNotFound
-			if !errors.As(err, &notFound) {
-				return fmt.Errorf("fetching dashboard from instance: %w", err)
-			}
-
-			isCleanupInGrafanaRequired = false
+			return err
 		}
 
-		if isCleanupInGrafanaRequired {
-			var dash *models.DashboardFullWithMeta
-			if resp != nil {
-				dash = resp.GetPayload()
-			}
+		if remoteFolderUID != "" && cr.Spec.FolderRef == "" && cr.Spec.FolderUID == "" && remoteFolderUID != tenantFolderUID(cr.Namespace) {
+			log.V(1).Info("Folder qualifies for deletion, checking if empty")
 
-			_, err = grafanaClient.Dashboards.DeleteDashboardByUID(uid) //nolint:errcheck
+			resp, err := r.DeleteFolderIfEmpty(grafanaClient, remoteFolderUID)
 			if err != nil {
-				var notFound *dashboards.DeleteDashboardByUIDNotFound
-				if !errors.As(err, &notFound) {
-					return fmt.Errorf("deleting dashboard from instance: %w", err)
-				}
+				return fmt.Errorf("deleting empty parent folder from instance: %w", err)
 			}
 
-			if dash != nil && dash.Meta != nil && dash.Meta.FolderUID != "" && cr.Spec.FolderRef == "" && cr.Spec.FolderUID == "" {
-				log.V(1).Info("Folder qualifies for deletion, checking if empty")
-
-				resp, err := r.DeleteFolderIfEmpty(grafanaClient, dash.Meta.FolderUID)
-				if err != nil {
-					return fmt.Errorf("deleting empty parent folder from instance: %w", err)
-				}
-
-				if resp.StatusCode == http.StatusOK {
-					log.Info("unused folder successfully removed")
-				}
+			if resp.StatusCode == http.StatusOK {
+				log.Info("unused folder successfully removed")
+			}
 
-				if resp.StatusCode == 432 {
-					log.Info("folder still in use by other dashboards, libraryPanels, or alertrules")
-				}
+			if resp.StatusCode == 432 {
+				log.Info("folder still in use by other dashboards, libraryPanels, or alertrules")
 			}
 		}
 
@@ -293,6 +715,108 @@ func (r *GrafanaDashboardReconciler) finalize(ctx context.Context, cr *v1beta1.G
 	return nil
 }
 
+// deleteFromInstance deletes the dashboard and returns the uid of the folder it was stored in, if any
+func (r *GrafanaDashboardReconciler) deleteFromInstance(ctx context.Context, grafana *v1beta1.Grafana, grafanaClient *genapi.GrafanaHTTPAPI, uid string) (string, error) {
+	if grafana.UsesDashboardProvisioningAPI() {
+		resourceClient, err := client2.NewDashboardResourceClient(ctx, r.Client, grafana)
+		if err != nil {
+			return "", err
+		}
+
+		dashboard, err := resourceClient.Get(ctx, uid)
+		if err != nil {
+			if errors.Is(err, client2.ErrDashboardResourceNotFound) {
+				return "", nil
+			}
+
+			return "", fmt.Errorf("fetching dashboard from instance: %w", err)
+		}
+
+		err = resourceClient.Delete(ctx, uid)
+		if err != nil && !errors.Is(err, client2.ErrDashboardResourceNotFound) {
+			return "", fmt.Errorf("deleting dashboard from instance: %w", err)
+		}
+
+		return dashboard.Metadata.Annotations[client2.AnnotationFolder], nil
+	}
+
+	resp, err := grafanaClient.Dashboards.GetDashboardByUID(uid)
+	if err != nil {
+		var notFound *dashboards.GetDashboardByUIDNotFound
+		if !errors.As(err, &notFound) {
+			return "", fmt.Errorf("fetching dashboard from instance: %w", err)
+		}
+
+		return "", nil
+	}
+
+	_, err = grafanaClient.Dashboards.DeleteDashboardByUID(uid) //nolint:errcheck
+	if err != nil {
+		var notFound *dashboards.DeleteDashboardByUIDNotFound
+		if !errors.As(err, &notFound) {
+			return "", fmt.Errorf("deleting dashboard from instance: %w", err)
+		}
+	}
+
+	dash := resp.GetPayload()
+	if dash == nil || dash.Meta == nil {
+		return "", nil
+	}
+
+	return dash.Meta.FolderUID, nil
+}
+
+// applyDashboard skips applying a model that was successfully applied to the instance within
+// the dedup window, e.g. by another GrafanaDashboard with identical content
+func (r *GrafanaDashboardReconciler) applyDashboard(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, dashboardModel map[string]any, hash, folderUID string) error {
+	if r.Cfg == nil || r.Cfg.DashboardApplyDedupWindow <= 0 {
+		return r.onDashboardCreated(ctx, grafana, cr, dashboardModel, hash, folderUID)
+	}
+
+	folder := folderUID
+	if folder == "" {
+		folder = "title:" + dashboardFolderTitle(cr)
+	}
+
+	appliedHash, err := dashboardApplyHash(dashboardModel, folder)
+	if err != nil {
+		return err
+	}
+
+	apply := dashboardApply{
+		instance: fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name),
+		hash:     appliedHash,
+	}
+
+	uid := fmt.Sprintf("%s", dashboardModel["uid"])
+
+	if r.applies.seen(apply, r.Cfg.DashboardApplyDedupWindow, time.Now()) {
+		logf.FromContext(ctx).V(1).Info("identical dashboard applied recently, skipping requests", "instance", apply.instance)
+		metrics.DashboardDeduplicatedApplies.WithLabelValues(grafana.Namespace, grafana.Name).Inc()
+
+		return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource(uid))
+	}
+
+	if err := r.onDashboardCreated(ctx, grafana, cr, dashboardModel, hash, folderUID); err != nil {
+		return err
+	}
+
+	r.applies.record(apply, uid, time.Now())
+
+	return nil
+}
+
+// dashboardApplyHash hashes the model as sent to the instance together with its folder. Unlike the content hash of a
+// resource, it covers the resolved uid, so resources with the same source but distinct dashboards never coalesce
+func dashboardApplyHash(dashboardModel map[string]any, folder string) (string, error) {
+	model, err := json.Marshal(dashboardModel)
+	if err != nil {
+		return "", fmt.Errorf("hashing dashboard model: %w", err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(append(model, []byte("\x00"+folder)...))), nil
+}
+
 func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, dashboardModel map[string]any, hash, folderUID string) error {
 	log := logf.FromContext(ctx)
 
@@ -312,6 +836,10 @@ func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, gra
 		}
 	}
 
+	if grafana.UsesDashboardProvisioningAPI() {
+		return r.applyDashboardResource(ctx, grafana, cr, dashboardModel, hash, folderUID)
+	}
+
 	uid := fmt.Sprintf("%s", dashboardModel["uid"])
 	title := fmt.Sprintf("%s", dashboardModel["title"])
 	remoteUID := uid
@@ -383,6 +911,71 @@ func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, gra
 	return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource(uid))
 }
 
+// applyDashboardResource applies the dashboard through the provisioning API of Grafana 12 and newer.
+// The managedBy annotations make Grafana show the dashboard as provisioned and refuse edits in the UI
+func (r *GrafanaDashboardReconciler) applyDashboardResource(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, dashboardModel map[string]any, hash, folderUID string) error {
+	log := logf.FromContext(ctx)
+
+	resourceClient, err := client2.NewDashboardResourceClient(ctx, r.Client, grafana)
+	if err != nil {
+		return err
+	}
+
+	uid := fmt.Sprintf("%s", dashboardModel["uid"])
+
+	existing, err := resourceClient.Get(ctx, uid)
+	if err != nil && !errors.Is(err, client2.ErrDashboardResourceNotFound) {
+		return fmt.Errorf("fetching dashboard resource: %w", err)
+	}
+
+	if existing != nil && existing.Metadata.Annotations[client2.AnnotationSourceChecksum] == hash &&
+		existing.Metadata.Annotations[client2.AnnotationFolder] == folderUID {
+		log.V(1).Info("dashboard model unchanged. skipping remaining requests")
+		return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource(uid))
+	}
+
+	// The id is assigned by Grafana and the version is tracked in the resource metadata
+	spec := make(map[string]any, len(dashboardModel))
+	for k, v := range dashboardModel {
+		if k != "id" && k != "version" {
+			spec[k] = v
+		}
+	}
+
+	annotations := map[string]string{
+		client2.AnnotationManagedBy:      client2.ManagerKindKubectl,
+		client2.AnnotationManagerID:      client2.ManagerID,
+		client2.AnnotationSourcePath:     fmt.Sprintf("%s/%s", cr.Namespace, cr.Name),
+		client2.AnnotationSourceChecksum: hash,
+	}
+	if folderUID != "" {
+		annotations[client2.AnnotationFolder] = folderUID
+	}
+
+	dashboard := &client2.DashboardResource{
+		APIVersion: client2.DashboardResourceAPIVersion,
+		Kind:       "Dashboard",
+		Metadata: client2.DashboardResourceMetadata{
+			Name:        uid,
+			Annotations: annotations,
+		},
+		Spec: spec,
+	}
+
+	if existing == nil {
+		err = resourceClient.Create(ctx, dashboard)
+	} else {
+		dashboard.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+		err = resourceClient.Update(ctx, dashboard)
+	}
+
+	if err != nil {
+		return fmt.Errorf("applying dashboard resource: %w", err)
+	}
+
+	return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource(uid))
+}
+
 func (r *GrafanaDashboardReconciler) Exists(client *genapi.GrafanaHTTPAPI, uid string, title string, folderUID string) (string, error) {
 	tvar := "dash-db"
 
@@ -450,12 +1043,141 @@ func (r *GrafanaDashboardReconciler) hasRemoteChange(exists bool, model map[stri
 	return false, nil
 }
 
-func (r *GrafanaDashboardReconciler) GetOrCreateFolder(client *genapi.GrafanaHTTPAPI, cr *v1beta1.GrafanaDashboard) (string, error) {
-	title := cr.Namespace
+// applyOwnershipTags adds the owning team and system as dashboard tags and returns the content hash accounting for them
+func applyOwnershipTags(cr *v1beta1.GrafanaDashboard, dashboardModel map[string]any, hash string) string {
+	if cr.Spec.Ownership == nil || !cr.Spec.Ownership.Tags {
+		return hash
+	}
+
+	tags, _ := dashboardModel["tags"].([]any) //nolint:errcheck
+
+	added := make([]string, 0)
+
+	for _, key := range []string{model.OwnershipSystemKey, model.OwnershipTeamKey} {
+		value := model.GetOwnershipValue(cr, key)
+		if value == "" {
+			continue
+		}
+
+		// operator.grafana.com/team -> team:<value>
+		added = append(added, fmt.Sprintf("%s:%s", key[strings.LastIndex(key, "/")+1:], value))
+	}
+
+	if len(added) == 0 {
+		return hash
+	}
+
+	for _, tag := range added {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	dashboardModel["tags"] = tags
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(hash+strings.Join(added, ","))))
+}
+
+func containsTag(tags []any, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+var errFolderNotFound = errors.New("folder not found")
+
+// existingFolderTitle returns the title of the folder with uid in the instance
+func existingFolderTitle(ctx context.Context, k8sClient client.Client, grafana *v1beta1.Grafana, uid string) (string, error) {
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, k8sClient, grafana)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := grafanaClient.Folders.GetFolderByUID(uid)
+	if err != nil {
+		var notFound *folders.GetFolderByUIDNotFound
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("%w: spec.folderUID %s doesn't exist", errFolderNotFound, uid)
+		}
+
+		return "", fmt.Errorf("fetching folder %s: %w", uid, err)
+	}
+
+	return resp.GetPayload().Title, nil
+}
+
+// setDashboardFolder reports the folder of spec.folderUID in the status. Folders no GrafanaFolder resource manages are
+// adopted: dashboards are placed in them, but they are left untouched when the dashboards are deleted
+func (r *GrafanaDashboardReconciler) setDashboardFolder(ctx context.Context, cr *v1beta1.GrafanaDashboard, adopting bool, title string, missing []string) error {
+	if !adopting {
+		cr.Status.Folder = nil
+
+		if cr.Spec.FolderRef == "" {
+			removeNoMatchingFolder(&cr.Status.Conditions)
+		}
+
+		return nil
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		setNoMatchingFolder(&cr.Status.Conditions, cr.Generation, "NotFound", fmt.Sprintf("Folder with uid %s not found in instances %s", cr.Spec.FolderUID, strings.Join(missing, ", ")))
+	} else {
+		removeNoMatchingFolder(&cr.Status.Conditions)
+	}
+
+	// Instances outside their change window or awaiting approval aren't checked
+	if title == "" {
+		return nil
+	}
+
+	var list v1beta1.GrafanaFolderList
+	if err := r.List(ctx, &list); err != nil {
+		return fmt.Errorf("listing folders: %w", err)
+	}
+
+	managed := false
+
+	for _, folder := range list.Items {
+		if folder.CustomUIDOrUID() == cr.Spec.FolderUID {
+			managed = true
+			break
+		}
+	}
+
+	if !managed && (cr.Status.Folder == nil || !cr.Status.Folder.Adopted) {
+		logf.FromContext(ctx).Info("adopting folder not managed by a GrafanaFolder", "folderUID", cr.Spec.FolderUID, "title", title)
+	}
+
+	cr.Status.Folder = &v1beta1.DashboardFolderStatus{
+		UID:     cr.Spec.FolderUID,
+		Title:   title,
+		Adopted: !managed,
+	}
+
+	return nil
+}
+
+// dashboardFolderTitle returns the title of the folder created for dashboards without a folder reference
+func dashboardFolderTitle(cr *v1beta1.GrafanaDashboard) string {
 	if cr.Spec.FolderTitle != "" {
-		title = cr.Spec.FolderTitle
+		return cr.Spec.FolderTitle
+	}
+
+	if team := model.GetOwnershipValue(cr, model.OwnershipTeamKey); team != "" && cr.Spec.Ownership != nil && cr.Spec.Ownership.TeamFolder {
+		return team
 	}
 
+	return cr.Namespace
+}
+
+func (r *GrafanaDashboardReconciler) GetOrCreateFolder(client *genapi.GrafanaHTTPAPI, cr *v1beta1.GrafanaDashboard) (string, error) {
+	title := dashboardFolderTitle(cr)
+
 	exists, folderUID, err := r.GetFolderUID(client, title)
 	if err != nil {
 		return "", err
@@ -568,15 +1290,86 @@ func (r *GrafanaDashboardReconciler) SetupWithManager(ctx context.Context, mgr c
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	// Index the dashboards by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaDashboard{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
+	// Index the dashboards importing jsonnet libraries from ConfigMaps by their namespace.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaDashboard{}, jsonnetLibIndexKey, indexJsonnetLibSelector); err != nil {
+		return fmt.Errorf("failed setting jsonnet library index: %w", err)
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.GrafanaDashboard{}, builder.WithPredicates(
 			ignoreStatusUpdates(),
 		)).
 		Watches(
 			&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(configMapIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(configMapIndexKey), r.Cfg.SyncWindow),
 		).
-		Complete(r)
+		Watches(
+			&corev1.ConfigMap{},
+			enqueueCoalesced(requestsForJsonnetLib(r.Client, func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} }), r.Cfg.SyncWindow),
+		).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForApprovals),
+			builder.WithPredicates(approvalChanged()),
+		)
+
+	if r.GitPushes != nil {
+		b = b.WatchesRawSource(source.Channel(r.GitPushes, &handler.EnqueueRequestForObject{}))
+	}
+
+	return b.Complete(r)
+}
+
+// requestsForApprovals requeues the dashboards with changes staged for the instance once its approved hashes change
+func (r *GrafanaDashboardReconciler) requestsForApprovals(ctx context.Context, o client.Object) []reconcile.Request {
+	instance, ok := o.(*v1beta1.Grafana)
+	if !ok {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%s", instance.Namespace, instance.Name)
+
+	var reqs []reconcile.Request
+
+	for _, scope := range []string{instance.Namespace, "*"} {
+		list := &v1beta1.GrafanaDashboardList{}
+		if err := r.List(ctx, list, client.MatchingFields{instanceScopeIndexKey: scope}); err != nil {
+			logf.FromContext(ctx).Error(err, "listing dashboards for approved changes", "instance", key)
+			return nil
+		}
+
+		for _, cr := range list.Items {
+			if cr.Status.PendingApproval != nil && slices.Contains(cr.Status.PendingApproval.Instances, key) {
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cr)})
+			}
+		}
+	}
+
+	return reqs
+}
+
+// requeueForGitPoll requeues dashboards of Git repositories once their ref is due to be checked for new commits
+func requeueForGitPoll(requeueAfter time.Duration, source *v1beta1.GitRepoContentReference) time.Duration {
+	if source == nil {
+		return requeueAfter
+	}
+
+	poll := fetchers.GitPollInterval(source)
+	if requeueAfter <= 0 || poll < requeueAfter {
+		return poll
+	}
+
+	return requeueAfter
 }
 
 func (r *GrafanaDashboardReconciler) indexConfigMapSource() func(o client.Object) []string {
@@ -17,12 +17,372 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "github.com/onsi/ginkgo/v2"
 )
 
+func TestApplyOwnershipTags(t *testing.T) {
+	cr := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{model.OwnershipTeamKey: "platform"},
+			Annotations: map[string]string{model.OwnershipSystemKey: "payments", model.OwnershipTeamKey: "ignored"},
+		},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		dashboardModel := map[string]any{}
+
+		hash := applyOwnershipTags(cr, dashboardModel, "abc")
+
+		assert.Equal(t, "abc", hash)
+		assert.NotContains(t, dashboardModel, "tags")
+	})
+
+	t.Run("tags appended to existing ones", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Ownership = &v1beta1.GrafanaDashboardOwnership{Tags: true}
+
+		dashboardModel := map[string]any{"tags": []any{"existing", "team:platform"}}
+
+		hash := applyOwnershipTags(cr, dashboardModel, "abc")
+
+		assert.NotEqual(t, "abc", hash)
+		assert.Equal(t, []any{"existing", "team:platform", "system:payments"}, dashboardModel["tags"])
+	})
+}
+
+func TestTenantFolders(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+	configs := []*v1beta1.GrafanaOperatorConfig{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+			Spec:       v1beta1.GrafanaOperatorConfigSpec{TenantFolders: &v1beta1.TenantFolders{Namespaces: []string{"team-a", "team-b"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "teams"},
+			Spec:       v1beta1.GrafanaOperatorConfigSpec{TenantFolders: &v1beta1.TenantFolders{Namespaces: []string{"team-b"}, Teams: true}},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey, configs[0], configs[1]).Build()
+	cfg := &Config{ClusterScoped: true}
+
+	t.Run("settings of all matching configs apply", func(t *testing.T) {
+		tenant, err := cfg.tenantFolders(ctx, cl, "team-a")
+		require.NoError(t, err)
+		assert.Equal(t, &v1beta1.TenantFolders{}, tenant)
+
+		tenant, err = cfg.tenantFolders(ctx, cl, "team-b")
+		require.NoError(t, err)
+		assert.Equal(t, &v1beta1.TenantFolders{Teams: true}, tenant)
+
+		tenant, err = cfg.tenantFolders(ctx, cl, "platform")
+		require.NoError(t, err)
+		assert.Nil(t, tenant)
+
+		// Operators restricted to namespaces don't read the configs
+		tenant, err = (&Config{}).tenantFolders(ctx, cl, "team-a")
+		require.NoError(t, err)
+		assert.Nil(t, tenant)
+	})
+
+	t.Run("folder uids fit grafana", func(t *testing.T) {
+		assert.Equal(t, "tenant-team-a", tenantFolderUID("team-a"))
+
+		long := tenantFolderUID(strings.Repeat("a", 63))
+		assert.Len(t, long, maxFolderUIDLength)
+		assert.NotEqual(t, long, tenantFolderUID(strings.Repeat("a", 62)))
+	})
+
+	t.Run("folder without team", func(t *testing.T) {
+		require.NoError(t, ensureTenantFolder(ctx, cl, grafana, "team-a", &v1beta1.TenantFolders{}))
+
+		folder, found := srv.Folder("tenant-team-a")
+		require.True(t, found)
+		assert.Equal(t, "team-a", folder.Title)
+
+		_, found = srv.FolderPermissions("tenant-team-a")
+		assert.False(t, found)
+	})
+
+	t.Run("folder with team", func(t *testing.T) {
+		tenant := &v1beta1.TenantFolders{Teams: true}
+		require.NoError(t, ensureTenantFolder(ctx, cl, grafana, "team-b", tenant))
+
+		team, found := srv.Team("team-b")
+		require.True(t, found)
+
+		permissions, found := srv.FolderPermissions("tenant-team-b")
+		require.True(t, found)
+		require.Len(t, permissions, 1)
+		assert.Equal(t, team.ID, permissions[0].TeamID)
+		assert.Equal(t, permissionEdit, permissions[0].Permission)
+
+		// Existing folders and teams are reused
+		require.NoError(t, ensureTenantFolder(ctx, cl, grafana, "team-b", tenant))
+	})
+
+	t.Run("existing teams are only adopted on request", func(t *testing.T) {
+		grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+		require.NoError(t, err)
+
+		_, err = grafanaClient.Teams.CreateTeam(&models.CreateTeamCommand{Name: "team-c"})
+		require.NoError(t, err)
+
+		tenant := &v1beta1.TenantFolders{Teams: true}
+		require.ErrorIs(t, ensureTenantFolder(ctx, cl, grafana, "team-c", tenant), errTenantTeamExists)
+
+		_, found := srv.Folder("tenant-team-c")
+		assert.False(t, found, "the folder is not created without its team")
+
+		tenant.AdoptExistingTeams = true
+		require.NoError(t, ensureTenantFolder(ctx, cl, grafana, "team-c", tenant))
+
+		team, _ := srv.Team("team-c")
+		permissions, found := srv.FolderPermissions("tenant-team-c")
+		require.True(t, found)
+		require.Len(t, permissions, 1)
+		assert.Equal(t, team.ID, permissions[0].TeamID)
+	})
+
+	t.Run("alert rule groups may use the tenant folder", func(t *testing.T) {
+		policy := &v1beta1.GrafanaOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "alerting"},
+			Spec: v1beta1.GrafanaOperatorConfigSpec{
+				AlertRuleGroupFolders: []v1beta1.NamespaceFolderPolicy{{Namespaces: []string{"*"}, NamespaceFolders: true}},
+			},
+		}
+		require.NoError(t, cl.Create(ctx, policy))
+
+		require.NoError(t, cfg.checkAlertRuleGroupFolder(ctx, cl, "team-a", "tenant-team-a"))
+		require.ErrorIs(t, cfg.checkAlertRuleGroupFolder(ctx, cl, "team-a", "tenant-team-b"), errFolderNotAllowed)
+	})
+}
+
+func TestDashboardProvisioningAPI(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grafana-12"},
+		Spec: v1beta1.GrafanaSpec{
+			Client: &v1beta1.GrafanaClient{DashboardAPI: v1beta1.DashboardAPIProvisioning},
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL, Version: grafanafake.Version},
+	}
+	cr := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "overview"},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey, grafana).WithStatusSubresource(grafana).Build()
+	r := &GrafanaDashboardReconciler{Client: cl, Scheme: s}
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Folders.CreateFolder(&models.CreateFolderCommand{UID: "team-a", Title: "team-a"})
+	require.NoError(t, err)
+
+	require.NoError(t, r.onDashboardCreated(ctx, grafana, cr, map[string]any{"uid": "overview", "title": "Overview", "id": nil}, "hash-1", "team-a"))
+
+	model, folderUID, found := srv.Dashboard("overview")
+	require.True(t, found)
+	assert.Equal(t, "Overview", model["title"])
+	assert.Equal(t, "team-a", folderUID)
+
+	annotations, _ := srv.DashboardAnnotations("overview")
+	assert.Equal(t, map[string]string{
+		client2.AnnotationFolder:         "team-a",
+		client2.AnnotationManagedBy:      client2.ManagerKindKubectl,
+		client2.AnnotationManagerID:      client2.ManagerID,
+		client2.AnnotationSourcePath:     "team-a/overview",
+		client2.AnnotationSourceChecksum: "hash-1",
+	}, annotations)
+
+	require.NoError(t, r.onDashboardCreated(ctx, grafana, cr, map[string]any{"uid": "overview", "title": "Renamed"}, "hash-2", "team-a"))
+
+	model, _, _ = srv.Dashboard("overview")
+	assert.Equal(t, "Renamed", model["title"])
+
+	folder, err := r.deleteFromInstance(ctx, grafana, grafanaClient, "overview")
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", folder)
+
+	_, _, found = srv.Dashboard("overview")
+	assert.False(t, found)
+}
+
+func TestAdoptExistingFolder(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grafana"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL, Version: grafanafake.Version},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey, grafana).Build()
+	r := &GrafanaDashboardReconciler{Client: cl, Scheme: s}
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Folders.CreateFolder(&models.CreateFolderCommand{UID: "shared", Title: "Shared"})
+	require.NoError(t, err)
+
+	title, err := existingFolderTitle(ctx, cl, grafana, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, "Shared", title)
+
+	_, err = existingFolderTitle(ctx, cl, grafana, "missing")
+	require.ErrorIs(t, err, errFolderNotFound)
+
+	cr := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "overview"},
+		Spec:       v1beta1.GrafanaDashboardSpec{FolderUID: "shared"},
+	}
+
+	require.NoError(t, r.setDashboardFolder(ctx, cr, true, title, nil))
+	assert.Equal(t, &v1beta1.DashboardFolderStatus{UID: "shared", Title: "Shared", Adopted: true}, cr.Status.Folder)
+
+	// Folders of GrafanaFolder resources are referenced, not adopted
+	require.NoError(t, cl.Create(ctx, &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "shared"},
+		Spec:       v1beta1.GrafanaFolderSpec{CustomUID: "shared"},
+	}))
+
+	require.NoError(t, r.setDashboardFolder(ctx, cr, true, title, nil))
+	assert.False(t, cr.Status.Folder.Adopted)
+
+	require.NoError(t, r.setDashboardFolder(ctx, cr, true, "", []string{"default/grafana"}))
+	condition := meta.FindStatusCondition(cr.Status.Conditions, conditionNoMatchingFolder)
+	require.NotNil(t, condition)
+	assert.Equal(t, "Folder with uid shared not found in instances default/grafana", condition.Message)
+
+	require.NoError(t, r.setDashboardFolder(ctx, cr, false, "", nil))
+	assert.Nil(t, cr.Status.Folder)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionNoMatchingFolder))
+}
+
+func TestRecentApplies(t *testing.T) {
+	now := time.Now()
+	apply := dashboardApply{instance: "default/grafana", hash: "1"}
+
+	var applies recentApplies
+
+	assert.False(t, applies.seen(apply, time.Minute, now))
+
+	applies.record(apply, "abc", now)
+
+	assert.True(t, applies.seen(apply, time.Minute, now.Add(30*time.Second)))
+
+	changed := apply
+	changed.hash = "2"
+	assert.False(t, applies.seen(changed, time.Minute, now.Add(30*time.Second)))
+
+	assert.False(t, applies.seen(apply, time.Minute, now.Add(time.Minute)))
+	assert.Empty(t, applies.entries)
+
+	applies.record(apply, "abc", now)
+	applies.forget("abc")
+	assert.False(t, applies.seen(apply, time.Minute, now))
+}
+
+func TestDashboardApplyHash(t *testing.T) {
+	first, err := dashboardApplyHash(map[string]any{"uid": "tenant-a", "title": "Overview"}, "folder")
+	require.NoError(t, err)
+
+	same, err := dashboardApplyHash(map[string]any{"title": "Overview", "uid": "tenant-a"}, "folder")
+	require.NoError(t, err)
+	assert.Equal(t, first, same, "identical applies of different resources must coalesce")
+
+	otherUID, err := dashboardApplyHash(map[string]any{"uid": "tenant-b", "title": "Overview"}, "folder")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, otherUID)
+
+	otherFolder, err := dashboardApplyHash(map[string]any{"uid": "tenant-a", "title": "Overview"}, "other")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, otherFolder)
+}
+
 var _ = Describe("Dashboard Reconciler: Provoke Conditions", func() {
 	tests := []struct {
 		name    string
@@ -48,7 +408,7 @@ var _ = Describe("Dashboard Reconciler: Provoke Conditions", func() {
 			meta: objectMetaNoMatchingInstances,
 			spec: v1beta1.GrafanaDashboardSpec{
 				GrafanaCommonSpec:  commonSpecNoMatchingInstances,
-				GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: "{}"},
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: `{"title": "No matching instances"}`},
 			},
 			want: metav1.Condition{
 				Type:   conditionNoMatchingInstance,
@@ -61,7 +421,7 @@ var _ = Describe("Dashboard Reconciler: Provoke Conditions", func() {
 			meta: objectMetaApplyFailed,
 			spec: v1beta1.GrafanaDashboardSpec{
 				GrafanaCommonSpec:  commonSpecApplyFailed,
-				GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: "{}"},
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: `{"title": "Apply failed"}`},
 			},
 			want: metav1.Condition{
 				Type:   conditionDashboardSynchronized,
@@ -78,9 +438,23 @@ var _ = Describe("Dashboard Reconciler: Provoke Conditions", func() {
 			},
 			want: metav1.Condition{
 				Type:   conditionInvalidSpec,
-				Reason: conditionReasonInvalidModelResolution,
+				Reason: conditionReasonInvalidDashboardModel,
+			},
+		},
+		{
+			name: "Invalid dashboard model",
+			meta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "invalid-spec-dashboard-model",
+			},
+			spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCommonSpec:  commonSpecInvalidSpec,
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: `{"title": "Invalid", "panels": [{"title": "CPU"}]}`},
+			},
+			want: metav1.Condition{
+				Type:   conditionInvalidSpec,
+				Reason: conditionReasonInvalidDashboardModel,
 			},
-			wantErr: "resolving dashboard contents",
 		},
 		{
 			name: "No model can be resolved, no model source is defined",
@@ -129,3 +503,80 @@ var _ = Describe("Dashboard Reconciler: Provoke Conditions", func() {
 		})
 	}
 })
+
+func TestApprovalDiff(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "production",
+			Labels:    map[string]string{labelRequiresApproval: "true"},
+		},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+	cr := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "overview"},
+		Spec:       v1beta1.GrafanaDashboardSpec{GrafanaContentSpec: v1beta1.GrafanaContentSpec{CustomUID: "overview"}},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey, grafana).WithStatusSubresource(grafana).Build()
+	r := &GrafanaDashboardReconciler{Client: cl, Scheme: s}
+
+	assert.True(t, requiresApproval(grafana))
+	assert.False(t, requiresApproval(&v1beta1.Grafana{}))
+
+	staged := map[string]any{"uid": "overview", "title": "Overview", "id": nil}
+
+	diff, err := r.approvalDiff(ctx, grafana, staged)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"uid": "overview", "title": "Overview"}`, diff)
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Folders.CreateFolder(&models.CreateFolderCommand{UID: "default", Title: "default"})
+	require.NoError(t, err)
+
+	require.NoError(t, r.onDashboardCreated(ctx, grafana, cr, staged, "hash-1", "default"))
+
+	diff, err = r.approvalDiff(ctx, grafana, map[string]any{"uid": "overview", "title": "Renamed"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Renamed"}`, diff)
+
+	r.stageForApproval(ctx, grafana, cr, map[string]any{"uid": "overview", "title": "Renamed"}, "hash-2", []string{"default/production"})
+	require.NotNil(t, cr.Status.PendingApproval)
+	assert.Equal(t, "hash-2", cr.Status.PendingApproval.Hash)
+	assert.Equal(t, []string{"default/production"}, cr.Status.PendingApproval.Instances)
+
+	condition := meta.FindStatusCondition(cr.Status.Conditions, conditionPendingApproval)
+	require.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "operator.grafana.com/approved-hashes")
+	assert.NotContains(t, condition.Message, "hash-2")
+
+	assert.False(t, isApproved(grafana, "hash-2"))
+
+	grafana.Annotations = map[string]string{"operator.grafana.com/approved-hashes": "hash-1, hash-2"}
+	assert.True(t, isApproved(grafana, "hash-2"))
+	assert.False(t, isApproved(grafana, "hash-3"))
+}
@@ -0,0 +1,279 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultQueryValidationRange = 5 * time.Minute
+	queryValidationDataPoints   = 100
+
+	// Failures listed per instance in the QueriesValid condition
+	maxReportedQueryFailures = 10
+)
+
+// Datasources which are not queried through /api/ds/query or whose queries depend on other panels
+var unvalidatedDatasources = []string{"grafana", "-- Grafana --", "-- Mixed --", "-- Dashboard --", "__expr__"}
+
+// variableReference matches $var, ${var} and ${var:format} as well as the deprecated [[var]] syntax
+var variableReference = regexp.MustCompile(`\$\{?(\w+)|\[\[(\w+)`)
+
+// panelQueries are the queries of a panel, validated within a single request
+type panelQueries struct {
+	title   string
+	queries []map[string]any
+}
+
+// dashboardPanelQueries extracts the queries of the panels of a dashboard model, including the panels of collapsed rows.
+// Datasource variables are replaced by their current value, queries which cannot be run without the dashboard are skipped
+func dashboardPanelQueries(dashboardModel map[string]any, timeRange time.Duration) []panelQueries {
+	datasourceVariables, variables := dashboardVariables(dashboardModel)
+
+	intervalMs := max(timeRange.Milliseconds()/queryValidationDataPoints, 1000)
+
+	var result []panelQueries
+
+	var visit func(panels []any)
+	visit = func(panels []any) {
+		for _, p := range panels {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			if nested, ok := panel["panels"].([]any); ok {
+				visit(nested)
+			}
+
+			// The model of library panels is not part of the dashboard
+			if _, ok := panel["libraryPanel"]; ok {
+				continue
+			}
+
+			targets, ok := panel["targets"].([]any)
+			if !ok {
+				continue
+			}
+
+			pq := panelQueries{title: panelTitle(panel)}
+
+			for _, t := range targets {
+				target, ok := t.(map[string]any)
+				if !ok || target["hide"] == true {
+					continue
+				}
+
+				ref := panel["datasource"]
+				if targetRef, ok := target["datasource"]; ok && targetRef != nil {
+					ref = targetRef
+				}
+
+				datasource, ok := resolveDatasourceRef(ref, datasourceVariables)
+				if !ok {
+					continue
+				}
+
+				query := maps.Clone(target)
+				delete(query, "datasource")
+
+				if referencesVariables(query, variables) {
+					continue
+				}
+
+				query["datasource"] = datasource
+				query["maxDataPoints"] = queryValidationDataPoints
+				query["intervalMs"] = intervalMs
+
+				if refID, _ := query["refId"].(string); refID == "" {
+					query["refId"] = string(rune('A' + len(pq.queries)))
+				}
+
+				pq.queries = append(pq.queries, query)
+			}
+
+			if len(pq.queries) > 0 {
+				result = append(result, pq)
+			}
+		}
+	}
+
+	panels, _ := dashboardModel["panels"].([]any)
+	visit(panels)
+
+	return result
+}
+
+// dashboardVariables returns the current value of datasource variables and the names of all other variables
+func dashboardVariables(dashboardModel map[string]any) (map[string]string, []string) {
+	datasources := map[string]string{}
+
+	var variables []string
+
+	templating, _ := dashboardModel["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+
+	for _, v := range list {
+		variable, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _ := variable["name"].(string)
+		if name == "" {
+			continue
+		}
+
+		if variable["type"] == "datasource" {
+			current, _ := variable["current"].(map[string]any)
+			if value, ok := current["value"].(string); ok && value != "" {
+				datasources[name] = value
+				continue
+			}
+		}
+
+		variables = append(variables, name)
+	}
+
+	return datasources, variables
+}
+
+// resolveDatasourceRef returns the {uid, type} reference of a datasource queries can be run against.
+// Panels without a datasource and legacy references by name are skipped as resolving them requires the frontend
+func resolveDatasourceRef(ref any, datasourceVariables map[string]string) (map[string]any, bool) {
+	var uid, dsType string
+
+	switch r := ref.(type) {
+	case map[string]any:
+		uid, _ = r["uid"].(string)
+		dsType, _ = r["type"].(string)
+	case string:
+		if !variableReference.MatchString(r) {
+			return nil, false
+		}
+
+		uid = r
+	default:
+		return nil, false
+	}
+
+	if match := variableReference.FindStringSubmatch(uid); match != nil {
+		value, ok := datasourceVariables[match[1]+match[2]]
+		if !ok {
+			return nil, false
+		}
+
+		uid = value
+	}
+
+	if uid == "" || slices.Contains(unvalidatedDatasources, uid) || slices.Contains(unvalidatedDatasources, dsType) {
+		return nil, false
+	}
+
+	datasource := map[string]any{"uid": uid}
+	if dsType != "" {
+		datasource["type"] = dsType
+	}
+
+	return datasource, true
+}
+
+// referencesVariables reports whether the query depends on the value of dashboard variables,
+// built-in variables like $__interval are interpolated by Grafana
+func referencesVariables(query map[string]any, variables []string) bool {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return true
+	}
+
+	for _, match := range variableReference.FindAllStringSubmatch(string(data), -1) {
+		if slices.Contains(variables, match[1]+match[2]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func panelTitle(panel map[string]any) string {
+	if title, ok := panel["title"].(string); ok && title != "" {
+		return title
+	}
+
+	return fmt.Sprintf("panel %v", panel["id"])
+}
+
+// validateDashboardQueries runs the queries of the panels through the datasources of the instance
+// and returns a message for every failed query
+func validateDashboardQueries(ctx context.Context, cl client.Client, grafana *v1beta1.Grafana, panels []panelQueries, timeRange time.Duration) []string {
+	var failures []string
+
+	for _, panel := range panels {
+		errs, err := client2.QueryDatasources(ctx, cl, grafana, panel.queries, timeRange)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("panel %q: %s", panel.title, err.Error()))
+			continue
+		}
+
+		for _, refID := range slices.Sorted(maps.Keys(errs)) {
+			failures = append(failures, fmt.Sprintf("panel %q query %s: %s", panel.title, refID, errs[refID]))
+		}
+	}
+
+	return failures
+}
+
+func queryValidationRange(validation *v1beta1.DashboardQueryValidation) time.Duration {
+	if validation.Range.Duration <= 0 {
+		return defaultQueryValidationRange
+	}
+
+	return validation.Range.Duration
+}
+
+// setQueriesValid sets the QueriesValid condition from the failed queries of the validated instances
+func setQueriesValid(conditions *[]metav1.Condition, generation int64, failures map[string][]string) {
+	condition := metav1.Condition{
+		Type:               conditionQueriesValid,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonQueriesSucceeded,
+		Message: "Queries of all panels succeeded",
+	}
+
+	if len(failures) > 0 {
+		var sb strings.Builder
+
+		for _, instance := range slices.Sorted(maps.Keys(failures)) {
+			for i, failure := range failures[instance] {
+				if i == maxReportedQueryFailures {
+					sb.WriteString(fmt.Sprintf("\n- %s: %d more", instance, len(failures[instance])-i))
+					break
+				}
+
+				sb.WriteString(fmt.Sprintf("\n- %s: %s", instance, failure))
+			}
+		}
+
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonQueriesFailed
+		condition.Message = fmt.Sprintf("Queries failed on %d instances:%s", len(failures), sb.String())
+	}
+
+	meta.SetStatusCondition(conditions, condition)
+}
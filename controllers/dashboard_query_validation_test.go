@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDashboardPanelQueries(t *testing.T) {
+	dashboardModel := map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{"name": "ds", "type": "datasource", "current": map[string]any{"value": "prometheus"}},
+				map[string]any{"name": "job", "type": "query"},
+			},
+		},
+		"panels": []any{
+			map[string]any{
+				"title":      "Up",
+				"datasource": map[string]any{"type": "prometheus", "uid": "${ds}"},
+				"targets": []any{
+					map[string]any{"refId": "A", "expr": "up"},
+					map[string]any{"refId": "B", "expr": "up{job=\"$job\"}"},
+					map[string]any{"refId": "C", "expr": "down", "hide": true},
+					map[string]any{"refId": "D", "expr": "rate(up[$__rate_interval])", "datasource": map[string]any{"uid": "thanos"}},
+				},
+			},
+			map[string]any{
+				"type":      "row",
+				"collapsed": true,
+				"panels": []any{
+					map[string]any{
+						"id":         4,
+						"datasource": map[string]any{"type": "loki", "uid": "loki"},
+						"targets":    []any{map[string]any{"expr": "{app=\"grafana\"}"}},
+					},
+				},
+			},
+			map[string]any{
+				"title":        "Library",
+				"libraryPanel": map[string]any{"uid": "shared"},
+				"targets":      []any{map[string]any{"refId": "A", "expr": "up"}},
+			},
+			map[string]any{
+				"title":      "Grafana",
+				"datasource": map[string]any{"type": "datasource", "uid": "grafana"},
+				"targets":    []any{map[string]any{"refId": "A"}},
+			},
+			map[string]any{
+				"title":   "Default datasource",
+				"targets": []any{map[string]any{"refId": "A", "expr": "up"}},
+			},
+		},
+	}
+
+	panels := dashboardPanelQueries(dashboardModel, 5*time.Minute)
+
+	require.Len(t, panels, 2)
+
+	assert.Equal(t, "Up", panels[0].title)
+	require.Len(t, panels[0].queries, 2)
+	assert.Equal(t, map[string]any{"type": "prometheus", "uid": "prometheus"}, panels[0].queries[0]["datasource"])
+	assert.Equal(t, "D", panels[0].queries[1]["refId"])
+	assert.Equal(t, map[string]any{"uid": "thanos"}, panels[0].queries[1]["datasource"])
+
+	assert.Equal(t, "panel 4", panels[1].title)
+	assert.Equal(t, []map[string]any{{
+		"refId":         "A",
+		"expr":          "{app=\"grafana\"}",
+		"datasource":    map[string]any{"type": "loki", "uid": "loki"},
+		"maxDataPoints": queryValidationDataPoints,
+		"intervalMs":    int64(3000),
+	}}, panels[1].queries)
+}
+
+func TestValidateDashboardQueries(t *testing.T) {
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "external"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(apiKey).Build()
+
+	gClient, err := client2.NewGeneratedGrafanaClient(t.Context(), cl, grafana)
+	require.NoError(t, err)
+
+	_, err = gClient.Datasources.AddDataSource(&models.AddDataSourceCommand{UID: "prometheus", Name: "Prometheus", Type: "prometheus"})
+	require.NoError(t, err)
+
+	srv.FailQuery("rate(up[", "parse error: unclosed left bracket")
+
+	dashboardModel := map[string]any{
+		"panels": []any{
+			map[string]any{
+				"title":      "Up",
+				"datasource": map[string]any{"type": "prometheus", "uid": "prometheus"},
+				"targets": []any{
+					map[string]any{"refId": "A", "expr": "up"},
+					map[string]any{"refId": "B", "expr": "rate(up["},
+				},
+			},
+			map[string]any{
+				"title":      "Removed datasource",
+				"datasource": map[string]any{"type": "prometheus", "uid": "removed"},
+				"targets":    []any{map[string]any{"refId": "A", "expr": "up"}},
+			},
+		},
+	}
+
+	panels := dashboardPanelQueries(dashboardModel, time.Minute)
+
+	failures := validateDashboardQueries(t.Context(), cl, grafana, panels, time.Minute)
+	assert.Equal(t, []string{
+		`panel "Up" query B: parse error: unclosed left bracket`,
+		`panel "Removed datasource" query A: data source not found`,
+	}, failures)
+
+	var conditions []metav1.Condition
+
+	setQueriesValid(&conditions, 1, map[string][]string{"default/external": failures})
+
+	condition := meta.FindStatusCondition(conditions, conditionQueriesValid)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, conditionReasonQueriesFailed, condition.Reason)
+	assert.Contains(t, condition.Message, `- default/external: panel "Up" query B: parse error`)
+
+	setQueriesValid(&conditions, 1, map[string][]string{})
+	assert.True(t, meta.IsStatusConditionTrue(conditions, conditionQueriesValid))
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/content"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-grafana-integreatly-org-v1beta1-grafanadashboard,mutating=false,failurePolicy=ignore,sideEffects=None,groups=grafana.integreatly.org,resources=grafanadashboards,verbs=create;update,versions=v1beta1,name=vgrafanadashboard.grafana.integreatly.org,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = (*DashboardValidator)(nil)
+
+// DashboardValidator rejects GrafanaDashboards with invalid inline content and warns about instances too old for their schemaVersion.
+// Content of other sources is only known once resolved, the dashboard controller validates it then
+type DashboardValidator struct {
+	Client client.Client
+}
+
+func (v *DashboardValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1beta1.GrafanaDashboard{}).
+		WithValidator(v).
+		Complete()
+}
+
+func (v *DashboardValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*v1beta1.GrafanaDashboard)
+	if !ok {
+		return nil, fmt.Errorf("expected a GrafanaDashboard but got %T", obj)
+	}
+
+	return v.validate(ctx, cr)
+}
+
+func (v *DashboardValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+func (v *DashboardValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *DashboardValidator) validate(ctx context.Context, cr *v1beta1.GrafanaDashboard) (admission.Warnings, error) {
+	model, ok, err := content.NewContentResolver(cr, v.Client).ResolveInline(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid dashboard content: %w", err)
+	}
+
+	modelErrors := content.ValidateDashboardModel(model)
+	if blocking := content.BlockingModelErrors(modelErrors); len(blocking) > 0 {
+		return nil, fmt.Errorf("invalid dashboard model: %s", formatModelErrors(blocking))
+	}
+
+	var warnings admission.Warnings
+	if len(modelErrors) > 0 {
+		warnings = append(warnings, "dashboard model: "+formatModelErrors(modelErrors))
+	}
+
+	// Instances matching later are checked by the controller
+	instances, err := GetScopedMatchingInstances(ctx, v.Client, cr)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "listing instances to check the dashboard schemaVersion")
+		return warnings, nil
+	}
+
+	for _, grafana := range instances {
+		if err := content.CheckSchemaVersion(model, grafana.Status.Version); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s/%s: %s", grafana.Namespace, grafana.Name, err))
+		}
+	}
+
+	return warnings, nil
+}
+
+func formatModelErrors(modelErrors []v1beta1.DashboardModelError) string {
+	msg := ""
+
+	for i, e := range modelErrors {
+		if i > 0 {
+			msg += "; "
+		}
+
+		msg += e.Path + ": " + e.Message
+		if e.Panel != "" {
+			msg += fmt.Sprintf(" (panel %q)", e.Panel)
+		}
+	}
+
+	return msg
+}
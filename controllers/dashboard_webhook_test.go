@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDashboardValidator(t *testing.T) {
+	ctx := context.Background()
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	grafana := func(name, version string) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"dashboards": "grafana"}},
+			Status: v1beta1.GrafanaStatus{
+				Stage:       v1beta1.OperatorStageComplete,
+				StageStatus: v1beta1.OperatorStageResultSuccess,
+				Version:     version,
+			},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(grafana("legacy", "11.2.0"), grafana("current", "12.1.0")).Build()
+	v := &DashboardValidator{Client: cl}
+
+	dashboard := func(spec v1beta1.GrafanaContentSpec) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nodes"},
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{
+					InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}},
+				},
+				GrafanaContentSpec: spec,
+			},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		warnings, err := v.ValidateCreate(ctx, dashboard(v1beta1.GrafanaContentSpec{JSON: `{"title": "Nodes", "schemaVersion": 39}`}))
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := v.ValidateCreate(ctx, dashboard(v1beta1.GrafanaContentSpec{JSON: `{"title": "Nodes",}`}))
+		require.ErrorContains(t, err, "invalid dashboard content")
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		_, err := v.ValidateUpdate(ctx, nil, dashboard(v1beta1.GrafanaContentSpec{YAML: "panels:\n- title: CPU\n"}))
+		require.EqualError(t, err, `invalid dashboard model: title: title is required`)
+	})
+
+	t.Run("invalid panels", func(t *testing.T) {
+		warnings, err := v.ValidateUpdate(ctx, nil, dashboard(v1beta1.GrafanaContentSpec{YAML: "title: Nodes\npanels:\n- title: CPU\n"}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{`dashboard model: panels[0].type: type is required (panel "CPU")`}, []string(warnings))
+	})
+
+	t.Run("schemaVersion newer than instances", func(t *testing.T) {
+		warnings, err := v.ValidateCreate(ctx, dashboard(v1beta1.GrafanaContentSpec{JSON: `{"title": "Nodes", "schemaVersion": 41}`}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"default/legacy: dashboard schemaVersion 41 requires Grafana 11.6.0 or newer, the instance runs 11.2.0"}, []string(warnings))
+	})
+
+	t.Run("content resolved by the controller", func(t *testing.T) {
+		warnings, err := v.ValidateCreate(ctx, dashboard(v1beta1.GrafanaContentSpec{URL: "https://grafana.example.com/dashboard.json"}))
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+
+		_, err = v.ValidateCreate(ctx, dashboard(v1beta1.GrafanaContentSpec{
+			JSON: `{"title": ${TITLE}}`,
+			Envs: []v1beta1.GrafanaContentEnv{{Name: "TITLE", Value: `"Nodes"`}},
+		}))
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+)
+
+const (
+	conditionDashboardSetSynchronized = "DashboardSetSynchronized"
+
+	// labelDashboardSet is set on the GrafanaDashboards created for a GrafanaDashboardSet
+	labelDashboardSet = "operator.grafana.com/dashboard-set"
+
+	eventReasonPruneScheduled = "PruneScheduled"
+	eventReasonPruned         = "Pruned"
+)
+
+// +kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards,verbs=create;update;delete
+
+var grafanaComRevisionURL = regexp.MustCompile(`/revisions/([0-9]+)/download$`)
+
+var errDashboardSetItemExists = errors.New("dashboard exists and is not controlled by the dashboard set")
+
+// GrafanaDashboardSetReconciler reconciles a GrafanaDashboardSet object
+type GrafanaDashboardSetReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Cfg      *Config
+	Recorder record.EventRecorder
+}
+
+func (r *GrafanaDashboardSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithName("GrafanaDashboardSetReconciler")
+	ctx = logf.IntoContext(ctx, log)
+
+	set := &v1beta1.GrafanaDashboardSet{}
+
+	err := r.Get(ctx, req.NamespacedName, set)
+	if err != nil {
+		if kuberr.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+
+		return ctrl.Result{}, fmt.Errorf("failed to get GrafanaDashboardSet: %w", err)
+	}
+
+	// Dashboards are owned by the set and garbage collected with it
+	if set.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	original := set.DeepCopy()
+
+	defer func() {
+		set.Status.LastResync = metav1.Time{Time: time.Now()}
+		if err := patchStatus(ctx, r.Client, original, set); err != nil {
+			log.Error(err, "updating status")
+		}
+	}()
+
+	existing := &v1beta1.GrafanaDashboardList{}
+
+	err = r.List(ctx, existing, client.InNamespace(set.Namespace), client.MatchingLabels{labelDashboardSet: set.Name})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing dashboards of the set: %w", err)
+	}
+
+	applyErrors := make(map[string]error)
+	items := make([]v1beta1.GrafanaDashboardSetItem, 0, len(set.Spec.GrafanaCom))
+	wanted := make(map[string]bool, len(set.Spec.GrafanaCom))
+
+	for _, ref := range set.Spec.GrafanaCom {
+		dashboard := &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      dashboardSetItemName(set, ref.ID),
+				Namespace: set.Namespace,
+			},
+		}
+		wanted[dashboard.Name] = true
+
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, dashboard, func() error {
+			// Dashboards created by hand or by another set are never taken over
+			if dashboard.ResourceVersion != "" && !metav1.IsControlledBy(dashboard, set) {
+				return errDashboardSetItemExists
+			}
+
+			buildDashboardSetItem(set, ref, dashboard)
+			return controllerutil.SetControllerReference(set, dashboard, r.Scheme)
+		})
+		if err != nil {
+			applyErrors[dashboard.Name] = err
+			continue
+		}
+
+		items = append(items, v1beta1.GrafanaDashboardSetItem{
+			ID:        ref.ID,
+			Dashboard: dashboard.Name,
+			Revision:  dashboardSetItemRevision(ref, dashboard),
+		})
+	}
+
+	now := time.Now()
+	pending := make([]v1beta1.GrafanaDashboardSetPrune, 0)
+
+	var pruned []string
+
+	for _, dashboard := range existing.Items {
+		if wanted[dashboard.Name] || !metav1.IsControlledBy(&dashboard, set) {
+			continue
+		}
+
+		pruneAfter, scheduled := pruneDeadline(set, dashboard.Name, now)
+		if !scheduled {
+			r.event(set, corev1.EventTypeNormal, eventReasonPruneScheduled,
+				fmt.Sprintf("Dashboard %s was removed from the set and is pruned at %s", dashboard.Name, pruneAfter.UTC().Format(time.RFC3339)))
+		}
+
+		if now.Before(pruneAfter) {
+			pending = append(pending, v1beta1.GrafanaDashboardSetPrune{Dashboard: dashboard.Name, PruneAfter: metav1.NewTime(pruneAfter)})
+			continue
+		}
+
+		log.Info("pruning dashboard removed from the set", "dashboard", dashboard.Name)
+		r.event(set, corev1.EventTypeNormal, eventReasonPruned, fmt.Sprintf("Pruning dashboard %s removed from the set", dashboard.Name))
+
+		if err := r.Delete(ctx, &dashboard); err != nil && !kuberr.IsNotFound(err) {
+			applyErrors[dashboard.Name] = err
+			pending = append(pending, v1beta1.GrafanaDashboardSetPrune{Dashboard: dashboard.Name, PruneAfter: metav1.NewTime(pruneAfter)})
+
+			continue
+		}
+
+		pruned = append(pruned, dashboard.Name)
+	}
+
+	slices.SortFunc(pending, func(a, b v1beta1.GrafanaDashboardSetPrune) int {
+		return strings.Compare(a.Dashboard, b.Dashboard)
+	})
+
+	set.Status.Items = items
+	set.Status.PendingPrunes = pending
+
+	if len(pruned) > 0 {
+		slices.Sort(pruned)
+		set.Status.LastPruned = pruned
+	}
+
+	condition := buildSynchronizedCondition("Dashboard set", conditionDashboardSetSynchronized, set.Generation, applyErrors, len(set.Spec.GrafanaCom))
+	meta.SetStatusCondition(&set.Status.Conditions, condition)
+
+	if len(applyErrors) > 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to apply all dashboards of the set: %v", applyErrors)
+	}
+
+	if len(pending) > 0 {
+		next := slices.MinFunc(pending, func(a, b v1beta1.GrafanaDashboardSetPrune) int {
+			return a.PruneAfter.Compare(b.PruneAfter.Time)
+		})
+
+		return ctrl.Result{RequeueAfter: next.PruneAfter.Sub(now)}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// pruneDeadline returns the time the dashboard removed from the set is pruned at and whether
+// the prune was scheduled by a previous reconciliation already
+func pruneDeadline(set *v1beta1.GrafanaDashboardSet, dashboard string, now time.Time) (time.Time, bool) {
+	for _, prune := range set.Status.PendingPrunes {
+		if prune.Dashboard == dashboard {
+			return prune.PruneAfter.Time, true
+		}
+	}
+
+	return now.Add(set.Spec.PruneGracePeriod.Duration), false
+}
+
+func (r *GrafanaDashboardSetReconciler) event(set *v1beta1.GrafanaDashboardSet, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(set, eventType, reason, message)
+	}
+}
+
+func dashboardSetItemName(set *v1beta1.GrafanaDashboardSet, id int) string {
+	return fmt.Sprintf("%s-%d", set.Name, id)
+}
+
+// buildDashboardSetItem sets the desired state of the dashboard provisioned for a grafana.com reference of the set
+func buildDashboardSetItem(set *v1beta1.GrafanaDashboardSet, ref v1beta1.GrafanaComContentReference, dashboard *v1beta1.GrafanaDashboard) {
+	labels := model.GetCommonLabels()
+	for k, v := range dashboard.Labels {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+
+	labels[labelDashboardSet] = set.Name
+	dashboard.Labels = labels
+
+	dashboard.Spec.GrafanaCommonSpec = *set.Spec.GrafanaCommonSpec.DeepCopy()
+	dashboard.Spec.GrafanaCom = ref.DeepCopy()
+	dashboard.Spec.FolderTitle = set.Spec.FolderTitle
+	dashboard.Spec.FolderUID = set.Spec.FolderUID
+	dashboard.Spec.FolderRef = set.Spec.FolderRef
+	dashboard.Spec.ContentCacheDuration = set.Spec.ContentCacheDuration
+	dashboard.Spec.Datasources = append([]v1beta1.GrafanaContentDatasource(nil), set.Spec.Datasources...)
+}
+
+// dashboardSetItemRevision returns the requested revision or, when tracking the latest revision,
+// the one last downloaded by the dashboard
+func dashboardSetItemRevision(ref v1beta1.GrafanaComContentReference, dashboard *v1beta1.GrafanaDashboard) *int {
+	if ref.Revision != nil {
+		revision := *ref.Revision
+		return &revision
+	}
+
+	match := grafanaComRevisionURL.FindStringSubmatch(dashboard.Status.ContentURL)
+	if match == nil {
+		return nil
+	}
+
+	revision, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil
+	}
+
+	return &revision
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GrafanaDashboardSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.GrafanaDashboardSet{}, builder.WithPredicates(ignoreStatusUpdates())).
+		Owns(&v1beta1.GrafanaDashboard{}).
+		Complete(r)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDashboardSetReconcile(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	revision := 9
+	set := &v1beta1.GrafanaDashboardSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "default", UID: "set-uid"},
+		Spec: v1beta1.GrafanaDashboardSetSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{
+				InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}},
+			},
+			GrafanaCom: []v1beta1.GrafanaComContentReference{
+				{ID: 1860},
+				{ID: 11074, Revision: &revision},
+			},
+			FolderTitle: "Nodes",
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(set).
+		WithStatusSubresource(&v1beta1.GrafanaDashboardSet{}, &v1beta1.GrafanaDashboard{}).
+		Build()
+
+	r := &GrafanaDashboardSetReconciler{Client: cl, Scheme: s, Cfg: &Config{}}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "node"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	dashboard := &v1beta1.GrafanaDashboard{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "node-1860"}, dashboard))
+	assert.Equal(t, 1860, dashboard.Spec.GrafanaCom.ID)
+	assert.Nil(t, dashboard.Spec.GrafanaCom.Revision)
+	assert.Equal(t, "Nodes", dashboard.Spec.FolderTitle)
+	assert.Equal(t, "node", dashboard.Labels[labelDashboardSet])
+	assert.True(t, metav1.IsControlledBy(dashboard, set))
+
+	// The dashboard resolved the latest revision from grafana.com
+	dashboard.Status.ContentURL = "https://grafana.com/api/dashboards/1860/revisions/37/download"
+	require.NoError(t, cl.Status().Update(ctx, dashboard))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	got := &v1beta1.GrafanaDashboardSet{}
+	require.NoError(t, cl.Get(ctx, req.NamespacedName, got))
+	require.Len(t, got.Status.Items, 2)
+	assert.Equal(t, "node-1860", got.Status.Items[0].Dashboard)
+	assert.Equal(t, 37, *got.Status.Items[0].Revision)
+	assert.Equal(t, 9, *got.Status.Items[1].Revision)
+	assert.True(t, meta.IsStatusConditionTrue(got.Status.Conditions, conditionDashboardSetSynchronized))
+
+	// Items removed from the list are pruned
+	got.Spec.GrafanaCom = got.Spec.GrafanaCom[1:]
+	require.NoError(t, cl.Update(ctx, got))
+
+	_, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	dashboards := &v1beta1.GrafanaDashboardList{}
+	require.NoError(t, cl.List(ctx, dashboards, client.InNamespace("default")))
+	require.Len(t, dashboards.Items, 1)
+	assert.Equal(t, "node-11074", dashboards.Items[0].Name)
+}
+
+func TestDashboardSetRefusesExistingDashboards(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	set := &v1beta1.GrafanaDashboardSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "default", UID: "set-uid"},
+		Spec: v1beta1.GrafanaDashboardSetSpec{
+			GrafanaCom: []v1beta1.GrafanaComContentReference{{ID: 1860}},
+		},
+	}
+	existing := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1860", Namespace: "default"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{JSON: "{}"},
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(set, existing).
+		WithStatusSubresource(&v1beta1.GrafanaDashboardSet{}, &v1beta1.GrafanaDashboard{}).
+		Build()
+
+	r := &GrafanaDashboardSetReconciler{Client: cl, Scheme: s, Cfg: &Config{}}
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "node"}})
+	require.ErrorContains(t, err, errDashboardSetItemExists.Error())
+
+	dashboard := &v1beta1.GrafanaDashboard{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "node-1860"}, dashboard))
+	assert.Equal(t, "{}", dashboard.Spec.JSON)
+	assert.Empty(t, dashboard.OwnerReferences)
+
+	got := &v1beta1.GrafanaDashboardSet{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "node"}, got))
+	assert.False(t, meta.IsStatusConditionTrue(got.Status.Conditions, conditionDashboardSetSynchronized))
+}
+
+func TestDashboardSetPruneGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	set := &v1beta1.GrafanaDashboardSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "default", UID: "set-uid"},
+		Spec: v1beta1.GrafanaDashboardSetSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{
+				InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}},
+			},
+			GrafanaCom:       []v1beta1.GrafanaComContentReference{{ID: 1860}, {ID: 11074}},
+			PruneGracePeriod: metav1.Duration{Duration: time.Hour},
+		},
+	}
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(set).
+		WithStatusSubresource(&v1beta1.GrafanaDashboardSet{}, &v1beta1.GrafanaDashboard{}).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	r := &GrafanaDashboardSetReconciler{Client: cl, Scheme: s, Cfg: &Config{}, Recorder: recorder}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "node"}}
+
+	_, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+
+	got := &v1beta1.GrafanaDashboardSet{}
+	require.NoError(t, cl.Get(ctx, req.NamespacedName, got))
+
+	got.Spec.GrafanaCom = got.Spec.GrafanaCom[1:]
+	require.NoError(t, cl.Update(ctx, got))
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, result.RequeueAfter, float64(time.Minute))
+	assert.Contains(t, <-recorder.Events, "Normal PruneScheduled Dashboard node-1860 was removed from the set")
+
+	// The dashboard is kept during the grace period
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "node-1860"}, &v1beta1.GrafanaDashboard{}))
+
+	require.NoError(t, cl.Get(ctx, req.NamespacedName, got))
+	require.Len(t, got.Status.PendingPrunes, 1)
+	assert.Equal(t, "node-1860", got.Status.PendingPrunes[0].Dashboard)
+
+	// Pruned once the grace period elapsed
+	got.Status.PendingPrunes[0].PruneAfter = metav1.NewTime(time.Now().Add(-time.Second))
+	require.NoError(t, cl.Status().Update(ctx, got))
+
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Contains(t, <-recorder.Events, "Normal Pruned Pruning dashboard node-1860 removed from the set")
+
+	err = cl.Get(ctx, types.NamespacedName{Namespace: "default", Name: "node-1860"}, &v1beta1.GrafanaDashboard{})
+	assert.True(t, kuberr.IsNotFound(err))
+
+	require.NoError(t, cl.Get(ctx, req.NamespacedName, got))
+	assert.Empty(t, got.Status.PendingPrunes)
+	assert.Equal(t, []string{"node-1860"}, got.Status.LastPruned)
+}
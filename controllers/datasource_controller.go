@@ -22,17 +22,27 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-openapi-client-go/client/datasources"
+	"github.com/grafana/grafana-openapi-client-go/client/enterprise"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/spyzhov/ajson"
 
 	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	corev1 "k8s.io/api/core/v1"
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -51,11 +61,35 @@ const (
 	conditionReasonInvalidModel     = "InvalidModel"
 )
 
+const (
+	datasourceTypePostgres       = "postgres"
+	datasourceTypePostgresPlugin = "grafana-postgresql-datasource"
+	datasourceTypeMySQL          = "mysql"
+)
+
+// maxDatasourceUsageDashboards limits the dashboard UIDs listed in status.usage
+const maxDatasourceUsageDashboards = 20
+
 // GrafanaDatasourceReconciler reconciles a GrafanaDatasource object
 type GrafanaDatasourceReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Cfg    *Config
+
+	usage datasourceUsageCache
+}
+
+// datasourceUsageCache holds the datasource references of all dashboards per instance,
+// so the dashboards are only scanned once per interval for all datasources
+type datasourceUsageCache struct {
+	mu      sync.Mutex
+	indexes map[string]datasourceUsageIndex
+}
+
+type datasourceUsageIndex struct {
+	// datasource uid or name -> dashboard uids
+	refs      map[string][]string
+	updatedAt time.Time
 }
 
 func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -76,6 +110,15 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	if cr.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(cr, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, cr)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, cr); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaDatasource: %w", err)
 			}
@@ -88,7 +131,7 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, cr)
+	defer UpdateStatus(ctx, r.Client, cr, cr.DeepCopy())
 
 	if cr.Spec.Suspend {
 		setSuspended(&cr.Status.Conditions, cr.Generation, conditionReasonApplySuspended)
@@ -111,12 +154,24 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDatasourceSynchronized)
 		cr.Status.NoMatchingInstances = true
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, cr)
 	}
 
 	removeNoMatchingInstance(&cr.Status.Conditions)
 	cr.Status.NoMatchingInstances = false
 
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, cr, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&cr.Status.Conditions, cr.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for datasource", "count", len(instances))
 
 	uid := cr.CustomUIDOrUID()
@@ -130,6 +185,11 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			return ctrl.Result{}, err
 		}
 
+		// Remember the old uid, so references to it can be migrated
+		if !slices.Contains(cr.Status.PreviousUIDs, cr.Status.UID) {
+			cr.Status.PreviousUIDs = append(cr.Status.PreviousUIDs, cr.Status.UID)
+		}
+
 		// Clean up uid, so further reconcilications can track changes there
 		cr.Status.UID = ""
 
@@ -147,8 +207,8 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	removeInvalidSpec(&cr.Status.Conditions)
 
-	pluginErrors := make(map[string]string)
-	applyErrors := make(map[string]string)
+	pluginErrors := make(map[string]error)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		if grafana.IsInternal() {
@@ -157,14 +217,14 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			// grafana reconciler will pick them up
 			err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, cr.Spec.Plugins, cr.GetPluginConfigMapKey(), cr.GetPluginConfigMapDeprecatedKey())
 			if err != nil {
-				pluginErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+				pluginErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 			}
 		}
 
 		// then import the datasource into the matching grafana instances
 		err = r.onDatasourceCreated(ctx, &grafana, cr, datasource, hash)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -186,7 +246,297 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	cr.Status.LastMessage = "" // nolint:staticcheck
 	cr.Status.UID = cr.CustomUIDOrUID()
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod)}, nil
+	if r.Cfg != nil && r.Cfg.DatasourceUsageInterval > 0 {
+		r.updateUsage(ctx, cr, datasource, instances, r.Cfg.DatasourceUsageInterval)
+	}
+
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, cr.Spec.ResyncJitterPercent), pendingWaves)}, nil
+}
+
+// updateUsage refreshes status.usage for instances not updated within interval.
+// Failures only affect the usage of the instance and are logged
+func (r *GrafanaDatasourceReconciler) updateUsage(ctx context.Context, cr *v1beta1.GrafanaDatasource, datasource *models.UpdateDataSourceCommand, instances []v1beta1.Grafana, interval time.Duration) {
+	log := logf.FromContext(ctx)
+
+	previous := make(map[string]v1beta1.GrafanaDatasourceUsage, len(cr.Status.Usage))
+	for _, u := range cr.Status.Usage {
+		previous[u.Instance] = u
+	}
+
+	usage := make([]v1beta1.GrafanaDatasourceUsage, 0, len(instances))
+
+	for _, grafana := range instances {
+		instance := fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)
+
+		if u, ok := previous[instance]; ok && time.Since(u.LastUpdated.Time) < interval {
+			usage = append(usage, u)
+			continue
+		}
+
+		index, err := r.usageIndex(ctx, &grafana, interval)
+		if err != nil {
+			log.Error(err, "computing datasource usage", "instance", instance)
+
+			if u, ok := previous[instance]; ok {
+				usage = append(usage, u)
+			}
+
+			continue
+		}
+
+		dashboards := datasourceDashboards(index, datasource.UID, datasource.Name)
+
+		u := v1beta1.GrafanaDatasourceUsage{
+			Instance:       instance,
+			DashboardCount: len(dashboards),
+			LastUpdated:    metav1.Now(),
+		}
+
+		if len(dashboards) > maxDatasourceUsageDashboards {
+			dashboards = dashboards[:maxDatasourceUsageDashboards]
+		}
+
+		if len(dashboards) > 0 {
+			u.Dashboards = dashboards
+		}
+
+		usage = append(usage, u)
+	}
+
+	slices.SortFunc(usage, func(a, b v1beta1.GrafanaDatasourceUsage) int {
+		return strings.Compare(a.Instance, b.Instance)
+	})
+
+	cr.Status.Usage = usage
+}
+
+// usageIndex returns the datasource references of the dashboards in the instance, cached for interval. The dashboards
+// are fetched without holding the lock, so a slow instance doesn't block the reconciles of datasources of other instances
+func (r *GrafanaDatasourceReconciler) usageIndex(ctx context.Context, grafana *v1beta1.Grafana, interval time.Duration) (map[string][]string, error) {
+	key := fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)
+
+	r.usage.mu.Lock()
+	index, ok := r.usage.indexes[key]
+	r.usage.mu.Unlock()
+
+	if ok && time.Since(index.updatedAt) < interval {
+		return index.refs, nil
+	}
+
+	refs, err := r.buildUsageIndex(ctx, grafana)
+	if err != nil {
+		return nil, err
+	}
+
+	r.usage.mu.Lock()
+	defer r.usage.mu.Unlock()
+
+	if r.usage.indexes == nil {
+		r.usage.indexes = make(map[string]datasourceUsageIndex)
+	}
+
+	r.usage.indexes[key] = datasourceUsageIndex{refs: refs, updatedAt: time.Now()}
+
+	return refs, nil
+}
+
+// buildUsageIndex scans the dashboards of the instance for the datasources they reference
+func (r *GrafanaDatasourceReconciler) buildUsageIndex(ctx context.Context, grafana *v1beta1.Grafana) (map[string][]string, error) {
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string][]string)
+
+	tvar := "dash-db"
+	page := int64(1)
+	limit := int64(1000)
+
+	for {
+		params := search.NewSearchParams().WithType(&tvar).WithLimit(&limit).WithPage(&page)
+
+		resp, err := grafanaClient.Search.Search(params)
+		if err != nil {
+			return nil, fmt.Errorf("searching dashboards: %w", err)
+		}
+
+		hits := resp.GetPayload()
+
+		for _, hit := range hits {
+			dashboard, err := grafanaClient.Dashboards.GetDashboardByUID(hit.UID)
+			if err != nil {
+				return nil, fmt.Errorf("fetching dashboard %s: %w", hit.UID, err)
+			}
+
+			found := make(map[string]bool)
+			collectDatasourceRefs(dashboard.GetPayload().Dashboard, found)
+
+			for ref := range found {
+				refs[ref] = append(refs[ref], hit.UID)
+			}
+		}
+
+		if len(hits) < int(limit) {
+			break
+		}
+
+		page++
+	}
+
+	return refs, nil
+}
+
+// collectDatasourceRefs adds the values of all datasource fields in the dashboard model to refs.
+// Panels and queries reference datasources by {"uid": ...} or, in older dashboards, by name
+func collectDatasourceRefs(v any, refs map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if key == "datasource" {
+				switch ref := value.(type) {
+				case string:
+					if ref != "" {
+						refs[ref] = true
+					}
+				case map[string]any:
+					if uid, ok := ref["uid"].(string); ok && uid != "" {
+						refs[uid] = true
+					}
+				}
+			}
+
+			collectDatasourceRefs(value, refs)
+		}
+	case []any:
+		for _, value := range v {
+			collectDatasourceRefs(value, refs)
+		}
+	}
+}
+
+// datasourceUIDMigrations returns the current uid by former uid of the datasources with spec.uidMigration in the
+// namespace applied to any of the instances. Former UIDs claimed by several datasources are ambiguous and former UIDs
+// still used by another datasource of the instances or the namespace are live, both are left alone
+func datasourceUIDMigrations(ctx context.Context, cl client.Client, namespace string, instances []v1beta1.Grafana) (map[string]string, error) {
+	list := &v1beta1.GrafanaDatasourceList{}
+
+	err := cl.List(ctx, list, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("listing datasources: %w", err)
+	}
+
+	migrations := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	inUse := make(map[string]bool)
+
+	for _, grafana := range instances {
+		for _, ds := range grafana.Status.Datasources {
+			_, _, uid := ds.Split()
+			inUse[uid] = true
+		}
+	}
+
+	for _, ds := range list.Items {
+		inUse[ds.CustomUIDOrUID()] = true
+
+		uids := ds.MigratedUIDs()
+		if len(uids) == 0 {
+			continue
+		}
+
+		applied := slices.ContainsFunc(instances, func(grafana v1beta1.Grafana) bool {
+			found, _ := grafana.Status.Datasources.Find(ds.Namespace, ds.Name)
+			return found
+		})
+		if !applied {
+			continue
+		}
+
+		current := ds.CustomUIDOrUID()
+
+		for _, uid := range uids {
+			if other, ok := migrations[uid]; ok && other != current {
+				ambiguous[uid] = true
+			}
+
+			migrations[uid] = current
+		}
+	}
+
+	log := logf.FromContext(ctx)
+
+	for uid := range ambiguous {
+		log.Info("former datasource uid is claimed by several datasources, references are not migrated", "uid", uid)
+
+		delete(migrations, uid)
+	}
+
+	for uid := range migrations {
+		if inUse[uid] {
+			log.Info("former datasource uid is still used by a datasource, references are not migrated", "uid", uid)
+
+			delete(migrations, uid)
+		}
+	}
+
+	return migrations, nil
+}
+
+// migrateDatasourceRefs rewrites datasource fields referencing a former uid, by {"uid": ...} or plain string,
+// to the current uid and reports whether anything changed
+func migrateDatasourceRefs(v any, migrations map[string]string) bool {
+	changed := false
+
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if key == "datasource" {
+				switch ref := value.(type) {
+				case string:
+					if uid, ok := migrations[ref]; ok {
+						v[key] = uid
+						changed = true
+					}
+				case map[string]any:
+					if old, ok := ref["uid"].(string); ok {
+						if uid, ok := migrations[old]; ok {
+							ref["uid"] = uid
+							changed = true
+						}
+					}
+				}
+			}
+
+			changed = migrateDatasourceRefs(value, migrations) || changed
+		}
+	case []any:
+		for _, value := range v {
+			changed = migrateDatasourceRefs(value, migrations) || changed
+		}
+	}
+
+	return changed
+}
+
+// migrationsHash returns the content hash accounting for the datasource uid migrations applied to it
+func migrationsHash(hash string, migrations map[string]string) string {
+	pairs := make([]string, 0, len(migrations))
+	for old, uid := range migrations {
+		pairs = append(pairs, old+"="+uid)
+	}
+
+	slices.Sort(pairs)
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(hash+strings.Join(pairs, ","))))
+}
+
+// datasourceDashboards returns the sorted UIDs of dashboards referencing the datasource by uid or name
+func datasourceDashboards(refs map[string][]string, uid, name string) []string {
+	dashboards := slices.Concat(refs[uid], refs[name])
+	slices.Sort(dashboards)
+
+	return slices.Compact(dashboards)
 }
 
 func (r *GrafanaDatasourceReconciler) deleteOldDatasource(ctx context.Context, cr *v1beta1.GrafanaDatasource) error {
@@ -245,6 +595,13 @@ func (r *GrafanaDatasourceReconciler) finalize(ctx context.Context, cr *v1beta1.
 			}
 		}
 
+		if grafana.Status.StatusPage.Provisions(uid) {
+			_, err = grafanaClient.WithOrgID(grafana.Status.StatusPage.OrgID).Datasources.DeleteDataSourceByUID(uid) // nolint:errcheck
+			if err != nil && !errors.As(err, &notFound) {
+				return fmt.Errorf("deleting datasource %s from the status page organization: %w", uid, err)
+			}
+		}
+
 		if grafana.IsInternal() {
 			err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, nil, cr.GetPluginConfigMapKey(), cr.GetPluginConfigMapDeprecatedKey())
 			if err != nil {
@@ -271,6 +628,11 @@ func (r *GrafanaDatasourceReconciler) onDatasourceCreated(ctx context.Context, g
 		return nil
 	}
 
+	// The TLS Secret can only be mounted into pods managed by the operator in the same namespace
+	if cr.Spec.TLS != nil && isPostgresDatasource(cr.Spec.Datasource.Type) && (grafana.IsExternal() || grafana.Namespace != cr.Namespace) {
+		return fmt.Errorf("spec.tls of postgres datasources is only supported for internal grafana instances in namespace %s", cr.Namespace)
+	}
+
 	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, r.Client, grafana)
 	if err != nil {
 		return err
@@ -281,6 +643,11 @@ func (r *GrafanaDatasourceReconciler) onDatasourceCreated(ctx context.Context, g
 		return err
 	}
 
+	err = r.provisionStatusPageDatasource(ctx, grafana, datasource, !cr.Unchanged(hash))
+	if err != nil {
+		return fmt.Errorf("provisioning the datasource into the status page organization: %w", err)
+	}
+
 	if exists && cr.Unchanged(hash) {
 		return nil
 	}
@@ -312,10 +679,107 @@ func (r *GrafanaDatasourceReconciler) onDatasourceCreated(ctx context.Context, g
 		}
 	}
 
+	// An empty list is kept apart from an unset field, it removes the rules instead of leaving them untouched
+	if cr.Spec.LBACRules != nil {
+		err = applyLBACRules(grafanaClient, datasource.UID, *cr.Spec.LBACRules)
+		if err != nil {
+			return fmt.Errorf("applying lbac rules: %w", err)
+		}
+	}
+
 	// Update grafana instance Status
 	return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource())
 }
 
+// provisionStatusPageDatasource applies the datasource to the organization of spec.statusPage when dashboards copied
+// there reference it, as datasources are not shared across organizations
+func (r *GrafanaDatasourceReconciler) provisionStatusPageDatasource(ctx context.Context, grafana *v1beta1.Grafana, datasource *models.UpdateDataSourceCommand, changed bool) error {
+	if !grafana.Status.StatusPage.Provisions(datasource.UID) {
+		return nil
+	}
+
+	orgClient, err := client2.NewGeneratedGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return err
+	}
+
+	orgClient = orgClient.WithOrgID(grafana.Status.StatusPage.OrgID)
+
+	exists, _, err := r.Exists(orgClient, datasource.UID, datasource.Name)
+	if err != nil {
+		return err
+	}
+
+	if exists && !changed {
+		return nil
+	}
+
+	encoded, err := json.Marshal(datasource)
+	if err != nil {
+		return fmt.Errorf("representing datasource as JSON: %w", err)
+	}
+
+	if exists {
+		var body models.UpdateDataSourceCommand
+		if err := json.Unmarshal(encoded, &body); err != nil {
+			return fmt.Errorf("representing data source as update command: %w", err)
+		}
+
+		_, err = orgClient.Datasources.UpdateDataSourceByUID(datasource.UID, &body) //nolint:errcheck
+
+		return err
+	}
+
+	var body models.AddDataSourceCommand
+	if err := json.Unmarshal(encoded, &body); err != nil {
+		return fmt.Errorf("representing data source as create command: %w", err)
+	}
+
+	_, err = orgClient.Datasources.AddDataSource(&body) //nolint:errcheck
+
+	return err
+}
+
+// applyLBACRules replaces the team LBAC rules of the datasource, rules of the same team are merged
+func applyLBACRules(grafanaClient *genapi.GrafanaHTTPAPI, uid string, rules []v1beta1.GrafanaDatasourceLBACRule) error {
+	teamRules := make([]*models.TeamLBACRule, 0, len(rules))
+	byTeam := make(map[string]*models.TeamLBACRule, len(rules))
+
+	for _, rule := range rules {
+		if existing, ok := byTeam[rule.Team]; ok {
+			existing.Rules = append(existing.Rules, rule.Rules...)
+			continue
+		}
+
+		team, err := findTeam(grafanaClient, rule.Team)
+		if err != nil {
+			return err
+		}
+
+		if team == nil {
+			return fmt.Errorf("team %s not found", rule.Team)
+		}
+
+		teamRule := &models.TeamLBACRule{
+			TeamID:  strconv.FormatInt(team.ID, 10),
+			TeamUID: team.UID,
+			Rules:   slices.Clone(rule.Rules),
+		}
+
+		byTeam[rule.Team] = teamRule
+		teamRules = append(teamRules, teamRule)
+	}
+
+	params := enterprise.NewUpdateTeamLBACRulesAPIParams().WithUID(uid).WithBody(&models.UpdateTeamLBACCommand{Rules: teamRules})
+
+	_, err := grafanaClient.Enterprise.UpdateTeamLBACRulesAPI(params) //nolint:errcheck
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *GrafanaDatasourceReconciler) Exists(client *genapi.GrafanaHTTPAPI, uid, name string) (bool, string, error) {
 	datasources, err := client.Datasources.GetDataSources()
 	if err != nil {
@@ -350,21 +814,60 @@ func (r *GrafanaDatasourceReconciler) SetupWithManager(ctx context.Context, mgr
 		return fmt.Errorf("failed setting configmap index fields: %w", err)
 	}
 
+	// Index the datasources by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaDatasource{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.GrafanaDatasource{}, builder.WithPredicates(
 			ignoreStatusUpdates(),
 		)).
 		Watches(
 			&corev1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(secretIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(secretIndexKey), r.Cfg.SyncWindow),
 		).
 		Watches(
 			&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(configMapIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(configMapIndexKey), r.Cfg.SyncWindow),
+		).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForStatusPage),
+			builder.WithPredicates(statusPageDatasourcesChanged()),
+		).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &v1beta1.GrafanaDatasourceList{} })),
+			builder.WithPredicates(instanceRecovered()),
 		).
 		Complete(r)
 }
 
+// requestsForStatusPage requeues the datasources referenced by the status page of the instance
+func (r *GrafanaDatasourceReconciler) requestsForStatusPage(ctx context.Context, o client.Object) []reconcile.Request {
+	instance, ok := o.(*v1beta1.Grafana)
+	if !ok || instance.Status.StatusPage == nil {
+		return nil
+	}
+
+	var list v1beta1.GrafanaDatasourceList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list datasources for the status page")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, datasource := range list.Items {
+		if instance.Status.StatusPage.Provisions(datasource.CustomUIDOrUID()) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&datasource)})
+		}
+	}
+
+	return reqs
+}
+
 func (r *GrafanaDatasourceReconciler) indexSecretSource() func(o client.Object) []string {
 	return func(o client.Object) []string {
 		datasource, ok := o.(*v1beta1.GrafanaDatasource)
@@ -376,10 +879,14 @@ func (r *GrafanaDatasourceReconciler) indexSecretSource() func(o client.Object)
 
 		for _, valueFrom := range datasource.Spec.ValuesFrom {
 			if valueFrom.ValueFrom.SecretKeyRef != nil {
-				secretRefs = append(secretRefs, fmt.Sprintf("%s/%s", datasource.Namespace, valueFrom.ValueFrom.SecretKeyRef.Name))
+				secretRefs = append(secretRefs, fmt.Sprintf("%s/%s", valueFrom.ValueFrom.ResolveNamespace(datasource.Namespace), valueFrom.ValueFrom.SecretKeyRef.Name))
 			}
 		}
 
+		if datasource.Spec.TLS != nil {
+			secretRefs = append(secretRefs, fmt.Sprintf("%s/%s", datasource.Namespace, datasource.Spec.TLS.SecretName))
+		}
+
 		return secretRefs
 	}
 }
@@ -395,7 +902,7 @@ func (r *GrafanaDatasourceReconciler) indexConfigMapSource() func(o client.Objec
 
 		for _, valueFrom := range datasource.Spec.ValuesFrom {
 			if valueFrom.ValueFrom.ConfigMapKeyRef != nil {
-				configMapRefs = append(configMapRefs, fmt.Sprintf("%s/%s", datasource.Namespace, valueFrom.ValueFrom.ConfigMapKeyRef.Name))
+				configMapRefs = append(configMapRefs, fmt.Sprintf("%s/%s", valueFrom.ValueFrom.ResolveNamespace(datasource.Namespace), valueFrom.ValueFrom.ConfigMapKeyRef.Name))
 			}
 		}
 
@@ -430,7 +937,24 @@ func (r *GrafanaDatasourceReconciler) buildDatasourceModel(ctx context.Context,
 	// Overwrite OrgID to ensure the field is useless
 	cr.Spec.Datasource.OrgID = nil
 
-	initialBytes, err := json.Marshal(cr.Spec.Datasource)
+	datasource := cr.Spec.Datasource
+	if cr.Spec.TLS != nil {
+		secret := &corev1.Secret{}
+
+		err := r.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: cr.Spec.TLS.SecretName}, secret)
+		if err != nil {
+			return nil, "", fmt.Errorf("fetching tls secret: %w", err)
+		}
+
+		datasource = datasource.DeepCopy()
+
+		err = applyDatasourceTLS(cr, datasource, secret)
+		if err != nil {
+			return nil, "", fmt.Errorf("applying tls secret: %w", err)
+		}
+	}
+
+	initialBytes, err := json.Marshal(datasource)
 	if err != nil {
 		return nil, "", fmt.Errorf("encoding existing datasource model as json: %w", err)
 	}
@@ -487,5 +1011,95 @@ func (r *GrafanaDatasourceReconciler) buildDatasourceModel(ctx context.Context,
 	hash := sha256.New()
 	hash.Write(newBytes)
 
+	// LBAC rules are applied separately, they are part of the hash so rule changes are applied
+	if cr.Spec.LBACRules != nil {
+		rules, err := json.Marshal(cr.Spec.LBACRules)
+		if err != nil {
+			return nil, "", fmt.Errorf("encoding lbac rules: %w", err)
+		}
+
+		hash.Write(rules)
+	}
+
 	return &res, fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
+
+func isPostgresDatasource(datasourceType string) bool {
+	return datasourceType == datasourceTypePostgres || datasourceType == datasourceTypePostgresPlugin
+}
+
+// applyDatasourceTLS points the datasource at the TLS material in the Secret. Postgres reads the
+// certificates from the files mounted into the Grafana pod, mysql only supports inlined certificates
+func applyDatasourceTLS(cr *v1beta1.GrafanaDatasource, datasource *v1beta1.GrafanaDatasourceInternal, secret *corev1.Secret) error {
+	jsonData := map[string]any{}
+	if len(datasource.JSONData) > 0 {
+		if err := json.Unmarshal(datasource.JSONData, &jsonData); err != nil {
+			return fmt.Errorf("parsing jsonData: %w", err)
+		}
+	}
+
+	secureJSONData := map[string]any{}
+	if len(datasource.SecureJSONData) > 0 {
+		if err := json.Unmarshal(datasource.SecureJSONData, &secureJSONData); err != nil {
+			return fmt.Errorf("parsing secureJsonData: %w", err)
+		}
+	}
+
+	caCertKey, certKey, keyKey := cr.Spec.TLS.TLSSecretKeys()
+
+	_, hasCACert := secret.Data[caCertKey]
+	_, hasCert := secret.Data[certKey]
+	_, hasKey := secret.Data[keyKey]
+	hasClientCert := hasCert && hasKey
+
+	if !hasCACert && !hasClientCert {
+		return fmt.Errorf("secret %s contains neither key %s nor keys %s and %s", secret.Name, caCertKey, certKey, keyKey)
+	}
+
+	switch {
+	case isPostgresDatasource(datasource.Type):
+		mountPath := model.GetDatasourceTLSMountPath(cr)
+
+		jsonData["tlsConfigurationMethod"] = "file-path"
+
+		if hasCACert {
+			jsonData["sslRootCertFile"] = path.Join(mountPath, caCertKey)
+		}
+
+		if hasClientCert {
+			jsonData["sslCertFile"] = path.Join(mountPath, certKey)
+			jsonData["sslKeyFile"] = path.Join(mountPath, keyKey)
+		}
+	case datasource.Type == datasourceTypeMySQL:
+		if hasCACert {
+			jsonData["tlsAuthWithCACert"] = true
+			secureJSONData["tlsCACert"] = string(secret.Data[caCertKey])
+		}
+
+		if hasClientCert {
+			jsonData["tlsAuth"] = true
+			secureJSONData["tlsClientCert"] = string(secret.Data[certKey])
+			secureJSONData["tlsClientKey"] = string(secret.Data[keyKey])
+
+			logging.RegisterSecret(string(secret.Data[keyKey]))
+		}
+	default:
+		return fmt.Errorf("spec.tls is not supported for datasources of type %q", datasource.Type)
+	}
+
+	var err error
+
+	datasource.JSONData, err = json.Marshal(jsonData)
+	if err != nil {
+		return fmt.Errorf("encoding jsonData: %w", err)
+	}
+
+	if len(secureJSONData) > 0 {
+		datasource.SecureJSONData, err = json.Marshal(secureJSONData)
+		if err != nil {
+			return fmt.Errorf("encoding secureJsonData: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -1,15 +1,21 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"strconv"
 	"testing"
 
+	"github.com/grafana/grafana-openapi-client-go/models"
 	v1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 	grafanaclient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "github.com/onsi/ginkgo/v2"
 )
@@ -202,6 +208,259 @@ func TestDatasourceIndexing(t *testing.T) {
 	})
 }
 
+func TestCollectDatasourceRefs(t *testing.T) {
+	var dashboard map[string]any
+
+	err := json.Unmarshal([]byte(`{
+		"panels": [
+			{"datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"datasource": {"uid": "loki"}}]},
+			{"datasource": "legacy-name"},
+			{"type": "row", "panels": [{"datasource": {"uid": "tempo"}}]},
+			{"datasource": null}
+		],
+		"templating": {"list": [{"datasource": ""}]}
+	}`), &dashboard)
+	require.NoError(t, err)
+
+	refs := make(map[string]bool)
+	collectDatasourceRefs(dashboard, refs)
+
+	assert.Equal(t, map[string]bool{"prom": true, "loki": true, "legacy-name": true, "tempo": true}, refs)
+}
+
+func TestMigrateDatasourceRefs(t *testing.T) {
+	var dashboard map[string]any
+
+	err := json.Unmarshal([]byte(`{
+		"panels": [
+			{"datasource": {"type": "prometheus", "uid": "prom-old"}, "targets": [{"datasource": {"uid": "loki"}}]},
+			{"datasource": "prom-old"},
+			{"type": "row", "panels": [{"datasource": {"uid": "prom-old"}}]}
+		]
+	}`), &dashboard)
+	require.NoError(t, err)
+
+	assert.False(t, migrateDatasourceRefs(dashboard, map[string]string{"tempo-old": "tempo"}))
+	assert.True(t, migrateDatasourceRefs(dashboard, map[string]string{"prom-old": "prom"}))
+
+	refs := make(map[string]bool)
+	collectDatasourceRefs(dashboard, refs)
+
+	assert.Equal(t, map[string]bool{"prom": true, "loki": true}, refs)
+}
+
+func TestDatasourceUIDMigrations(t *testing.T) {
+	newDatasource := func(name, uid string, previous ...string) *v1beta1.GrafanaDatasource {
+		return &v1beta1.GrafanaDatasource{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: v1beta1.GrafanaDatasourceSpec{
+				CustomUID:    uid,
+				Datasource:   &v1beta1.GrafanaDatasourceInternal{},
+				UIDMigration: &v1beta1.GrafanaDatasourceUIDMigration{PreviousUIDs: previous},
+			},
+		}
+	}
+
+	prometheus := newDatasource("prometheus", "prom", "prom-old", "shared-old")
+	loki := newDatasource("loki", "loki", "shared-old")
+	tempo := newDatasource("tempo", "tempo", "tempo-old")
+	tempo.Spec.UIDMigration = nil
+	mimir := newDatasource("mimir", "mimir", "mimir-old")
+	// claims the uid of tempo, which is still in use
+	jaeger := newDatasource("jaeger", "jaeger", "tempo")
+	// claims a uid from another namespace
+	foreign := newDatasource("foreign", "foreign", "prom-other")
+	foreign.Namespace = "other"
+
+	grafana := v1beta1.Grafana{
+		Status: v1beta1.GrafanaStatus{
+			Datasources: v1beta1.NamespacedResourceList{
+				prometheus.NamespacedResource(),
+				loki.NamespacedResource(),
+				tempo.NamespacedResource(),
+				jaeger.NamespacedResource(),
+				foreign.NamespacedResource(),
+			},
+		},
+	}
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(prometheus, loki, tempo, mimir, jaeger, foreign).Build()
+
+	migrations, err := datasourceUIDMigrations(context.Background(), cl, "default", []v1beta1.Grafana{grafana})
+	require.NoError(t, err)
+
+	// mimir is not applied to the instance, tempo does not migrate references, shared-old is ambiguous,
+	// tempo is in use and foreign is in another namespace
+	assert.Equal(t, map[string]string{"prom-old": "prom"}, migrations)
+}
+
+func TestDatasourceDashboards(t *testing.T) {
+	refs := map[string][]string{
+		"prom":       {"b", "a"},
+		"Prometheus": {"a", "c"},
+		"loki":       {"d"},
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, datasourceDashboards(refs, "prom", "Prometheus"))
+	assert.Empty(t, datasourceDashboards(refs, "unused", "Unused"))
+}
+
+func TestApplyDatasourceTLS(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "db-tls",
+		},
+		Data: map[string][]byte{
+			"ca.crt":  []byte("ca"),
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}
+
+	newCR := func(dsType string) *v1beta1.GrafanaDatasource {
+		return &v1beta1.GrafanaDatasource{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "grafana",
+				Name:      "db",
+			},
+			Spec: v1beta1.GrafanaDatasourceSpec{
+				TLS: &v1beta1.GrafanaDatasourceTLS{SecretName: "db-tls"},
+				Datasource: &v1beta1.GrafanaDatasourceInternal{
+					Type:     dsType,
+					JSONData: json.RawMessage(`{"sslmode":"verify-full"}`),
+				},
+			},
+		}
+	}
+
+	t.Run("postgres points at the mounted files", func(t *testing.T) {
+		cr := newCR("postgres")
+
+		err := applyDatasourceTLS(cr, cr.Spec.Datasource, secret)
+		require.NoError(t, err)
+
+		var jsonData map[string]any
+
+		require.NoError(t, json.Unmarshal(cr.Spec.Datasource.JSONData, &jsonData))
+		assert.Equal(t, "verify-full", jsonData["sslmode"])
+		assert.Equal(t, "file-path", jsonData["tlsConfigurationMethod"])
+		assert.Equal(t, "/etc/grafana-secrets/datasources/db/ca.crt", jsonData["sslRootCertFile"])
+		assert.Equal(t, "/etc/grafana-secrets/datasources/db/tls.crt", jsonData["sslCertFile"])
+		assert.Equal(t, "/etc/grafana-secrets/datasources/db/tls.key", jsonData["sslKeyFile"])
+		assert.Empty(t, cr.Spec.Datasource.SecureJSONData)
+	})
+
+	t.Run("mysql inlines the certificates", func(t *testing.T) {
+		cr := newCR("mysql")
+
+		err := applyDatasourceTLS(cr, cr.Spec.Datasource, secret)
+		require.NoError(t, err)
+
+		var jsonData, secureJSONData map[string]any
+
+		require.NoError(t, json.Unmarshal(cr.Spec.Datasource.JSONData, &jsonData))
+		require.NoError(t, json.Unmarshal(cr.Spec.Datasource.SecureJSONData, &secureJSONData))
+		assert.Equal(t, true, jsonData["tlsAuthWithCACert"])
+		assert.Equal(t, true, jsonData["tlsAuth"])
+		assert.Equal(t, "ca", secureJSONData["tlsCACert"])
+		assert.Equal(t, "cert", secureJSONData["tlsClientCert"])
+		assert.Equal(t, "key", secureJSONData["tlsClientKey"])
+	})
+
+	t.Run("custom keys without client certificate", func(t *testing.T) {
+		cr := newCR("grafana-postgresql-datasource")
+		cr.Spec.TLS.CACertKey = "root.pem"
+
+		s := &corev1.Secret{Data: map[string][]byte{"root.pem": []byte("ca")}}
+
+		err := applyDatasourceTLS(cr, cr.Spec.Datasource, s)
+		require.NoError(t, err)
+
+		var jsonData map[string]any
+
+		require.NoError(t, json.Unmarshal(cr.Spec.Datasource.JSONData, &jsonData))
+		assert.Equal(t, "/etc/grafana-secrets/datasources/db/root.pem", jsonData["sslRootCertFile"])
+		assert.NotContains(t, jsonData, "sslCertFile")
+		assert.NotContains(t, jsonData, "sslKeyFile")
+	})
+
+	t.Run("secret without tls material", func(t *testing.T) {
+		cr := newCR("postgres")
+
+		err := applyDatasourceTLS(cr, cr.Spec.Datasource, &corev1.Secret{})
+		require.Error(t, err)
+	})
+
+	t.Run("unsupported datasource type", func(t *testing.T) {
+		cr := newCR("prometheus")
+
+		err := applyDatasourceTLS(cr, cr.Spec.Datasource, secret)
+		require.Error(t, err)
+	})
+}
+
+func TestApplyLBACRules(t *testing.T) {
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "enterprise"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				APIKey: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+					Key:                  "token",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+
+	grafanaClient, err := grafanaclient.NewGeneratedGrafanaClient(context.Background(), fake.NewClientBuilder().WithObjects(apiKey).Build(), grafana)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Datasources.AddDataSource(&models.AddDataSourceCommand{UID: "loki", Name: "Loki", Type: "loki"})
+	require.NoError(t, err)
+
+	team, err := grafanaClient.Teams.CreateTeam(&models.CreateTeamCommand{Name: "team-a"})
+	require.NoError(t, err)
+
+	t.Run("rules of the same team are merged", func(t *testing.T) {
+		err := applyLBACRules(grafanaClient, "loki", []v1beta1.GrafanaDatasourceLBACRule{
+			{Team: "team-a", Rules: []string{`{namespace="team-a"}`}},
+			{Team: "team-a", Rules: []string{`{namespace="shared"}`}},
+		})
+		require.NoError(t, err)
+
+		rules, found := srv.DatasourceLBACRules("loki")
+		require.True(t, found)
+		require.Len(t, rules, 1)
+		assert.Equal(t, strconv.FormatInt(team.Payload.TeamID, 10), rules[0].TeamID)
+		assert.Equal(t, team.Payload.UID, rules[0].TeamUID)
+		assert.Equal(t, []string{`{namespace="team-a"}`, `{namespace="shared"}`}, rules[0].Rules)
+	})
+
+	t.Run("an empty list removes all rules", func(t *testing.T) {
+		require.NoError(t, applyLBACRules(grafanaClient, "loki", []v1beta1.GrafanaDatasourceLBACRule{}))
+
+		rules, _ := srv.DatasourceLBACRules("loki")
+		assert.Empty(t, rules)
+	})
+
+	t.Run("unknown teams fail", func(t *testing.T) {
+		err := applyLBACRules(grafanaClient, "loki", []v1beta1.GrafanaDatasourceLBACRule{{Team: "team-b", Rules: []string{`{namespace="team-b"}`}}})
+		require.ErrorContains(t, err, "team team-b not found")
+	})
+}
+
 var _ = Describe("Datasource: substitute reference values", func() {
 	t := GinkgoT()
 
@@ -21,28 +21,36 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/grafana/grafana-openapi-client-go/client/folders"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
 	"github.com/grafana/grafana-openapi-client-go/models"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
 
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 )
 
 const (
-	conditionFolderSynchronized = "FolderSynchronized"
-	conditionReasonCyclicParent = "CyclicParent"
+	conditionFolderSynchronized   = "FolderSynchronized"
+	conditionUnmanagedContent     = "UnmanagedContent"
+	conditionReasonCyclicParent   = "CyclicParent"
+	conditionReasonUnmanagedFound = "UnmanagedDashboardsFound"
 )
 
 // GrafanaFolderReconciler reconciles a GrafanaFolder object
@@ -70,6 +78,15 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if folder.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(folder, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, folder)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, folder); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaFolder: %w", err)
 			}
@@ -82,7 +99,7 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, folder)
+	defer UpdateStatus(ctx, r.Client, folder, folder.DeepCopy())
 
 	if folder.Spec.Suspend {
 		setSuspended(&folder.Status.Conditions, folder.Generation, conditionReasonApplySuspended)
@@ -114,12 +131,24 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		meta.RemoveStatusCondition(&folder.Status.Conditions, conditionFolderSynchronized)
 		folder.Status.NoMatchingInstances = true
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, folder)
 	}
 
 	removeNoMatchingInstance(&folder.Status.Conditions)
 	folder.Status.NoMatchingInstances = false
 
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, folder, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&folder.Status.Conditions, folder.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	parentFolderUID, err := getFolderUID(ctx, r.Client, folder)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf(ErrFetchingFolder, err)
@@ -127,25 +156,43 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	log.Info("found matching Grafana instances for folder", "count", len(instances))
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
+	unmanaged := make(map[string][]string)
 
 	for _, grafana := range instances {
+		key := fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)
+
 		err = r.onFolderCreated(ctx, &grafana, folder, parentFolderUID)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[key] = err
+			continue
+		}
+
+		if folder.Spec.Protected {
+			uids, err := r.unmanagedDashboards(ctx, &grafana, folder)
+			if err != nil {
+				applyErrors[key] = err
+				continue
+			}
+
+			if len(uids) > 0 {
+				unmanaged[key] = uids
+			}
 		}
 	}
 
 	condition := buildSynchronizedCondition("Folder", conditionFolderSynchronized, folder.Generation, applyErrors, len(instances))
 	meta.SetStatusCondition(&folder.Status.Conditions, condition)
 
+	setUnmanagedContentCondition(&folder.Status.Conditions, folder.Generation, unmanaged)
+
 	if len(applyErrors) > 0 {
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
 	folder.Status.Hash = folder.Hash()
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(folder.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(folder.Spec.ResyncPeriod, folder.Spec.ResyncJitterPercent), pendingWaves)}, nil
 }
 
 func (r *GrafanaFolderReconciler) finalize(ctx context.Context, folder *grafanav1beta1.GrafanaFolder) error {
@@ -168,12 +215,30 @@ func (r *GrafanaFolderReconciler) finalize(ctx context.Context, folder *grafanav
 			return err
 		}
 
-		_, err = grafanaClient.Folders.DeleteFolder(params.WithFolderUID(uid)) //nolint
-		if err != nil {
-			var notFound *folders.DeleteFolderNotFound
-			if !errors.As(err, &notFound) {
+		keep := false
+
+		if folder.Spec.Protected {
+			unmanaged, err := r.unmanagedDashboards(ctx, &grafana, folder)
+			if err != nil {
 				return err
 			}
+
+			// Deleting the folder would delete the unmanaged dashboards with it
+			if len(unmanaged) > 0 {
+				log.Info("protected folder contains unmanaged dashboards, keeping the folder in Grafana", "grafana", grafana.Name, "dashboards", unmanaged)
+
+				keep = true
+			}
+		}
+
+		if !keep {
+			_, err = grafanaClient.Folders.DeleteFolder(params.WithFolderUID(uid)) //nolint
+			if err != nil {
+				var notFound *folders.DeleteFolderNotFound
+				if !errors.As(err, &notFound) {
+					return err
+				}
+			}
 		}
 
 		// Update grafana instance Status
@@ -262,6 +327,78 @@ func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *
 	return grafana.AddNamespacedResource(ctx, r.Client, cr, cr.NamespacedResource(uid))
 }
 
+// unmanagedDashboards returns the UIDs of dashboards inside the folder which are not managed by the operator
+func (r *GrafanaFolderReconciler) unmanagedDashboards(ctx context.Context, grafana *grafanav1beta1.Grafana, cr *grafanav1beta1.GrafanaFolder) ([]string, error) {
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, uid, _, err := r.Exists(grafanaClient, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	managed := make(map[string]bool, len(grafana.Status.Dashboards))
+	for _, dashboard := range grafana.Status.Dashboards {
+		_, _, dashboardUID := dashboard.Split()
+		managed[dashboardUID] = true
+	}
+
+	tvar := "dash-db"
+	page := int64(1)
+	limit := int64(1000)
+	unmanaged := make([]string, 0)
+
+	for {
+		params := search.NewSearchParams().WithType(&tvar).WithFolderUIDs([]string{uid}).WithLimit(&limit).WithPage(&page)
+
+		resp, err := grafanaClient.Search.Search(params)
+		if err != nil {
+			return nil, err
+		}
+
+		hits := resp.GetPayload()
+
+		for _, hit := range hits {
+			if !managed[hit.UID] {
+				unmanaged = append(unmanaged, hit.UID)
+			}
+		}
+
+		if len(hits) < int(limit) {
+			return unmanaged, nil
+		}
+
+		page++
+	}
+}
+
+// setUnmanagedContentCondition reports dashboards in a protected folder that are not managed by the operator, per instance
+func setUnmanagedContentCondition(conditions *[]metav1.Condition, generation int64, unmanaged map[string][]string) {
+	if len(unmanaged) == 0 {
+		meta.RemoveStatusCondition(conditions, conditionUnmanagedContent)
+		return
+	}
+
+	var sb strings.Builder
+	for _, instance := range slices.Sorted(maps.Keys(unmanaged)) {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", instance, strings.Join(unmanaged[instance], ", ")))
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionUnmanagedContent,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             conditionReasonUnmanagedFound,
+		Message:            fmt.Sprintf("Folder contains dashboards not managed by the operator in %d instances:%s", len(unmanaged), sb.String()),
+	})
+}
+
 // Check if the folder exists. Matches UID first and fall back to title. Title matching only works for non-nested folders
 func (r *GrafanaFolderReconciler) Exists(client *genapi.GrafanaHTTPAPI, cr *grafanav1beta1.GrafanaFolder) (bool, string, string, error) {
 	title := cr.GetTitle()
@@ -305,9 +442,20 @@ func (r *GrafanaFolderReconciler) Exists(client *genapi.GrafanaHTTPAPI, cr *graf
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *GrafanaFolderReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *GrafanaFolderReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Index the folders by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &grafanav1beta1.GrafanaFolder{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&grafanav1beta1.GrafanaFolder{}).
-		WithEventFilter(ignoreStatusUpdates()).
+		For(&grafanav1beta1.GrafanaFolder{}, builder.WithPredicates(
+			ignoreStatusUpdates(),
+		)).
+		Watches(
+			&grafanav1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &grafanav1beta1.GrafanaFolderList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
 		Complete(r)
 }
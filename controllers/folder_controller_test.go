@@ -1,12 +1,35 @@
 package controllers
 
 import (
+	"testing"
+
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
 
 	. "github.com/onsi/ginkgo/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+func TestSetUnmanagedContentCondition(t *testing.T) {
+	conditions := []metav1.Condition{}
+
+	setUnmanagedContentCondition(&conditions, 2, map[string][]string{
+		"ns-b/grafana": {"uid-c"},
+		"ns-a/grafana": {"uid-a", "uid-b"},
+	})
+
+	condition := meta.FindStatusCondition(conditions, conditionUnmanagedContent)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, int64(2), condition.ObservedGeneration)
+	assert.Equal(t, "Folder contains dashboards not managed by the operator in 2 instances:\n- ns-a/grafana: uid-a, uid-b\n- ns-b/grafana: uid-c", condition.Message)
+
+	setUnmanagedContentCondition(&conditions, 3, map[string][]string{})
+	assert.Nil(t, meta.FindStatusCondition(conditions, conditionUnmanagedContent))
+}
+
 var _ = Describe("Folder Reconciler: Provoke Conditions", func() {
 	tests := []struct {
 		name    string
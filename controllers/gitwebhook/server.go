@@ -0,0 +1,249 @@
+// Package gitwebhook receives the push events of Git hosts and triggers the reconciliation of the dashboards
+// fetched from the pushed repositories, instead of waiting for their next poll.
+package gitwebhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// Path is the path push events are received on
+	Path = "/webhooks/git"
+
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+
+	// maxPayloadSize is the maximum size of the payloads sent by GitHub
+	maxPayloadSize = 25 << 20
+)
+
+var _ manager.LeaderElectionRunnable = (*Server)(nil)
+
+// pushEvent holds the fields of the push events of GitHub, GitLab and Gitea identifying the repository and the ref
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		CloneURL   string `json:"clone_url"`
+		HTMLURL    string `json:"html_url"`
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"repository"`
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+// Server receives push events and sends the dashboards referencing the pushed repository to the events channel
+type Server struct {
+	client client.Reader
+	addr   string
+	secret string
+	events chan<- event.GenericEvent
+}
+
+// New creates a Server listening on addr. Payloads are verified with the secret, the server refuses to start without it
+func New(cl client.Reader, addr, secret string, events chan<- event.GenericEvent) *Server {
+	return &Server{
+		client: cl,
+		addr:   addr,
+		secret: secret,
+		events: events,
+	}
+}
+
+// NeedLeaderElection returns true, the events are consumed by the controllers of the leader
+func (s *Server) NeedLeaderElection() bool {
+	return true
+}
+
+// Start receives push events until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("gitwebhook")
+
+	if s.secret == "" {
+		return errors.New("git webhook requires a secret, set GIT_WEBHOOK_SECRET")
+	}
+
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "shutting down git webhook server")
+		}
+	}()
+
+	log.Info("starting git webhook server", "addr", s.addr)
+
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Handler returns the http.Handler receiving push events
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+Path, s.handlePush)
+
+	return mux
+}
+
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !s.verify(r, payload) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	// Other events, e.g. the ping sent once the webhook is created, are acknowledged without effect
+	if !isPushEvent(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var push pushEvent
+	if err := json.Unmarshal(payload, &push); err != nil {
+		http.Error(w, fmt.Sprintf("invalid push event: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	repositories := push.repositories()
+	if len(repositories) == 0 {
+		http.Error(w, "push event without repository url", http.StatusBadRequest)
+		return
+	}
+
+	var dashboards v1beta1.GrafanaDashboardList
+	if err := s.client.List(r.Context(), &dashboards); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	triggered := 0
+
+	for _, dashboard := range dashboards.Items {
+		source := dashboard.Spec.GitRepo
+		if source == nil || !repositories[fetchers.NormalizeGitURL(source.URL)] || !refMatches(source.Ref, push.Ref) {
+			continue
+		}
+
+		fetchers.NotifyGitPush(source.URL)
+
+		select {
+		case s.events <- event.GenericEvent{Object: &dashboard}:
+			triggered++
+		case <-r.Context().Done():
+			http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	logf.FromContext(r.Context()).WithName("gitwebhook").Info("received push event", "ref", push.Ref, "dashboards", triggered)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	_ = json.NewEncoder(w).Encode(map[string]int{"dashboards": triggered}) //nolint:errcheck
+}
+
+// verify checks the HMAC signature of GitHub and Gitea or the token of GitLab
+func (s *Server) verify(r *http.Request, payload []byte) bool {
+	if s.secret == "" {
+		return false
+	}
+
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(s.secret)) == 1
+	}
+
+	signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(payload)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func isPushEvent(r *http.Request) bool {
+	if e := r.Header.Get("X-GitHub-Event"); e != "" {
+		return e == "push"
+	}
+
+	if e := r.Header.Get("X-Gitea-Event"); e != "" {
+		return e == "push"
+	}
+
+	if e := r.Header.Get("X-Gitlab-Event"); e != "" {
+		return e == "Push Hook" || e == "Tag Push Hook"
+	}
+
+	return true
+}
+
+// repositories returns the normalized urls of the pushed repository
+func (p *pushEvent) repositories() map[string]bool {
+	repositories := make(map[string]bool)
+
+	for _, u := range []string{p.Repository.CloneURL, p.Repository.HTMLURL, p.Repository.GitHTTPURL, p.Project.GitHTTPURL, p.Project.WebURL} {
+		if u != "" {
+			repositories[fetchers.NormalizeGitURL(u)] = true
+		}
+	}
+
+	return repositories
+}
+
+// refMatches reports whether the pushed ref is the ref of a source, sources tracking the default branch
+// match any push as the default branch isn't part of every payload
+func refMatches(ref, pushed string) bool {
+	switch {
+	case ref == "", pushed == "":
+		return true
+	case strings.HasPrefix(ref, "refs/"):
+		return ref == pushed
+	default:
+		return pushed == "refs/heads/"+ref || pushed == "refs/tags/"+ref
+	}
+}
@@ -0,0 +1,151 @@
+package gitwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const githubPush = `{
+	"ref": "refs/heads/main",
+	"repository": {
+		"clone_url": "https://github.com/org/dashboards.git",
+		"html_url": "https://github.com/org/dashboards"
+	}
+}`
+
+func newTestServer(t *testing.T, secret string) (*Server, chan event.GenericEvent) {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	dashboard := func(name, url, ref string) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+					GitRepo: &v1beta1.GitRepoContentReference{URL: url, Ref: ref, Path: "dashboard.json"},
+				},
+			},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		dashboard("default-branch", "https://github.com/org/dashboards.git", ""),
+		dashboard("main", "https://github.com/org/dashboards", "main"),
+		dashboard("release", "https://github.com/org/dashboards.git", "release"),
+		dashboard("other-repo", "https://github.com/org/other.git", ""),
+		&v1beta1.GrafanaDashboard{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "inline"}},
+	).Build()
+
+	events := make(chan event.GenericEvent, 10)
+
+	return New(cl, "", secret, events), events
+}
+
+func push(t *testing.T, s *Server, headers map[string]string, payload string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(payload))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	return rec
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func received(events chan event.GenericEvent) []string {
+	var names []string
+
+	for len(events) > 0 {
+		names = append(names, (<-events).Object.GetName())
+	}
+
+	return names
+}
+
+func TestPushTriggersDashboards(t *testing.T) {
+	s, events := newTestServer(t, "secret")
+
+	rec := push(t, s, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("secret", githubPush)}, githubPush)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"dashboards":2}`, rec.Body.String())
+	assert.ElementsMatch(t, []string{"default-branch", "main"}, received(events))
+
+	// GitLab identifies the repository in project
+	rec = push(t, s, map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "secret"}, `{
+		"ref": "refs/heads/release",
+		"project": {"git_http_url": "https://github.com/org/dashboards.git"}
+	}`)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.ElementsMatch(t, []string{"default-branch", "release"}, received(events))
+
+	rec = push(t, s, map[string]string{"X-GitHub-Event": "ping", "X-Gitlab-Token": "secret"}, `{"zen":"hello"}`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, received(events))
+
+	rec = push(t, s, map[string]string{"X-Gitlab-Token": "secret"}, `{"ref":"refs/heads/main"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestPushVerification(t *testing.T) {
+	s, events := newTestServer(t, "secret")
+
+	rec := push(t, s, map[string]string{"X-GitHub-Event": "push"}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = push(t, s, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("other", githubPush)}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = push(t, s, map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "other"}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, received(events))
+
+	rec = push(t, s, map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign("secret", githubPush)}, githubPush)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = push(t, s, map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "secret"}, githubPush)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Len(t, received(events), 4)
+
+	// Without a secret nothing is accepted
+	s, events = newTestServer(t, "")
+
+	rec = push(t, s, map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": ""}, githubPush)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, received(events))
+
+	require.EqualError(t, s.Start(t.Context()), "git webhook requires a secret, set GIT_WEBHOOK_SECRET")
+}
+
+func TestRefMatches(t *testing.T) {
+	assert.True(t, refMatches("", "refs/heads/feature"))
+	assert.True(t, refMatches("main", "refs/heads/main"))
+	assert.True(t, refMatches("v1", "refs/tags/v1"))
+	assert.True(t, refMatches("refs/heads/main", "refs/heads/main"))
+	assert.False(t, refMatches("main", "refs/heads/feature"))
+	assert.False(t, refMatches("0123456789012345678901234567890123456789", "refs/heads/main"))
+}
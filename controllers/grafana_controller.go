@@ -19,20 +19,30 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-operator/v5/controllers/config"
 	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers/grafana"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -40,31 +50,71 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 )
 
 const (
-	conditionTypeGrafanaReady         = "GrafanaReady"
-	conditionReasonReconcileSuspended = "ReconcileSuspended"
+	conditionTypeGrafanaReady          = "GrafanaReady"
+	conditionDatabaseUnavailable       = "DatabaseUnavailable"
+	conditionContentSuspended          = "ContentSuspended"
+	conditionReasonReconcileSuspended  = "ReconcileSuspended"
+	conditionReasonDatabaseFailing     = "DatabaseFailing"
+	conditionReasonDatabaseUnreachable = "DatabaseUnreachable"
+	conditionWorkloadDrift             = "WorkloadDrift"
+	conditionReasonDriftDetected       = "ModifiedOutOfBand"
+	conditionReasonDriftReverted       = "ModificationsReverted"
+
+	// dnsPendingRetry is how often the spec.dns hostname is looked up again while it doesn't resolve
+	dnsPendingRetry = 30 * time.Second
 )
 
 // GrafanaReconciler reconciles a Grafana object
 type GrafanaReconciler struct {
 	client.Client
-	Scheme        *runtime.Scheme
-	IsOpenShift   bool
+	Scheme      *runtime.Scheme
+	IsOpenShift bool
+	// HasHTTPRoutes watches the owned HTTPRoutes to report their status, requires the Gateway API CRDs
+	HasHTTPRoutes bool
+	// HasGRPCRoutes watches the owned GRPCRoutes to report their status, requires the Gateway API CRDs
+	HasGRPCRoutes bool
+	// HasMonitors watches the owned ServiceMonitors and PodMonitors, requires the prometheus-operator CRDs
+	HasMonitors bool
+	// HasScaledObjects maintains and watches the ScaledObjects of remote image renderers, requires the KEDA CRDs
+	HasScaledObjects bool
+	// ClusterScoped watches the cluster scoped GrafanaClasses, operators restricted to namespaces can't read them
+	ClusterScoped bool
 	ClusterDomain string
+	// Operator identifies the pods of the operator, the NetworkPolicies of spec.networkPolicy admit their requests
+	Operator grafana.OperatorPeer
+	// SyncWindow delays reconciles caused by datasource TLS, configFrom ConfigMap and route destination CA changes, so all changes
+	// affecting an instance within the window result in a single rollout. 0 reconciles right away
+	SyncWindow time.Duration
 }
 
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;list;create;update;delete;watch
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets;serviceaccounts;services;persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=grpcroutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch;delete
 
 func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx).WithName("GrafanaReconciler")
@@ -75,6 +125,7 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	err := r.Get(ctx, req.NamespacedName, cr)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			metrics.ForgetInstance(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 
@@ -85,18 +136,33 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	metrics.GrafanaReconciles.WithLabelValues(cr.Namespace, cr.Name).Inc()
 
+	original := cr.DeepCopy()
+
 	defer func() {
-		if err := r.Status().Update(ctx, cr); err != nil {
+		recordManagedObjects(cr)
+
+		if err := patchStatus(ctx, r.Client, original, cr); err != nil {
 			log.Error(err, "updating status")
 		}
 	}()
 
+	err = provision.ResolveClass(ctx, r.Client, cr)
+	if err != nil {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionTypeGrafanaReady)
+		cr.Status.LastMessage = err.Error()
+
+		return ctrl.Result{}, err
+	}
+
 	if cr.Spec.Suspend {
 		setSuspended(&cr.Status.Conditions, cr.Generation, conditionReasonReconcileSuspended)
+		setContentSuspended(cr)
+
 		return ctrl.Result{}, nil
 	}
 
 	removeSuspended(&cr.Status.Conditions)
+	setContentSuspended(cr)
 
 	var stages []grafanav1beta1.OperatorStageName
 	if cr.IsExternal() {
@@ -115,6 +181,21 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 				meta.RemoveStatusCondition(&cr.Status.Conditions, conditionTypeGrafanaReady)
 				return ctrl.Result{}, fmt.Errorf("patching grafana version in spec: %w", err)
 			}
+
+			// The patch response replaces the resolved spec
+			err = provision.ResolveClass(ctx, r.Client, cr)
+			if err != nil {
+				meta.RemoveStatusCondition(&cr.Status.Conditions, conditionTypeGrafanaReady)
+				return ctrl.Result{}, err
+			}
+		}
+
+		err = r.mergeConfigFrom(ctx, cr)
+		if err != nil {
+			meta.RemoveStatusCondition(&cr.Status.Conditions, conditionTypeGrafanaReady)
+			cr.Status.LastMessage = err.Error()
+
+			return ctrl.Result{}, err
 		}
 	}
 
@@ -137,14 +218,48 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			cr.Status.LastMessage = err.Error()
 
 			metrics.GrafanaFailedReconciles.WithLabelValues(cr.Namespace, cr.Name, string(stage)).Inc()
+			metrics.GrafanaInstanceReady.WithLabelValues(cr.Namespace, cr.Name).Set(0)
 			meta.RemoveStatusCondition(&cr.Status.Conditions, conditionTypeGrafanaReady)
 
+			// Retried with the backoff of the rate limiter, resources are not applied while the instance is not ready
+			if stderrors.Is(err, grafana.ErrDatabaseUnavailable) {
+				reason := conditionReasonDatabaseFailing
+				if stderrors.Is(err, grafana.ErrDatabaseUnreachable) {
+					// spec.database doesn't accept connections, the deployment isn't rolled out meanwhile
+					reason = conditionReasonDatabaseUnreachable
+				}
+
+				meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+					Type:               conditionDatabaseUnavailable,
+					Reason:             reason,
+					Message:            err.Error(),
+					ObservedGeneration: cr.Generation,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.Time{Time: time.Now()},
+				})
+			}
+
+			setStartup(cr, grafana.GetStartupPhase(err), time.Now())
+
+			// Starting instances are polled instead of backing off, so resources are applied soon after they respond
+			if cr.Status.Startup != nil && cr.Status.Startup.Phase == grafanav1beta1.GrafanaStartupStarting {
+				if retryAfter := cr.Status.Startup.RetryAfter(time.Now()); retryAfter > 0 {
+					log.Info("instance starting", "stage", stage, "retryAfter", retryAfter)
+					return ctrl.Result{RequeueAfter: retryAfter}, nil
+				}
+			}
+
 			return ctrl.Result{}, fmt.Errorf("reconciler error in stage '%s': %w", stage, err)
 		}
 	}
 
 	cr.Status.StageStatus = grafanav1beta1.OperatorStageResultSuccess
 	cr.Status.LastMessage = ""
+	cr.Status.Startup = nil
+
+	metrics.GrafanaInstanceReady.WithLabelValues(cr.Namespace, cr.Name).Set(1)
+
+	meta.RemoveStatusCondition(&cr.Status.Conditions, conditionDatabaseUnavailable)
 
 	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
 		Type:               conditionTypeGrafanaReady, // Maybe use Grafana instead to be consistent with other conditions
@@ -155,7 +270,101 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		LastTransitionTime: metav1.Time{Time: time.Now()},
 	})
 
-	return ctrl.Result{}, nil
+	setWorkloadDrift(ctx, cr, vars.Drift)
+
+	// status.adminUrl was held back, the hostname is looked up again soon instead of after the drift interval
+	if vars.DNSPending != "" {
+		cr.Status.LastMessage = vars.DNSPending
+		return ctrl.Result{RequeueAfter: dnsPendingRetry}, nil
+	}
+
+	// Workloads are checked for drift again after the interval, changes of Services and Ingresses aren't watched
+	return ctrl.Result{RequeueAfter: cr.DriftDetectionInterval()}, nil
+}
+
+// setWorkloadDrift reports the out-of-band modifications of the workloads found by spec.driftDetection
+func setWorkloadDrift(ctx context.Context, cr *grafanav1beta1.Grafana, drift []string) {
+	if len(drift) == 0 {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionWorkloadDrift)
+		return
+	}
+
+	reason := conditionReasonDriftDetected
+	message := "Modified out-of-band, kept until the Grafana resource changes: "
+
+	if cr.Spec.DriftDetection.Revert {
+		reason = conditionReasonDriftReverted
+		message = "Modified out-of-band and reverted: "
+	}
+
+	logf.FromContext(ctx).Info("workloads modified out-of-band", "drift", drift, "reverted", cr.Spec.DriftDetection.Revert)
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionWorkloadDrift,
+		Reason:             reason,
+		Message:            message + strings.Join(drift, "; "),
+		ObservedGeneration: cr.Generation,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+	})
+}
+
+// setContentSuspended reflects spec.suspendContent in the conditions, content controllers skip the instance meanwhile
+func setContentSuspended(cr *grafanav1beta1.Grafana) {
+	if !cr.Spec.SuspendContent {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionContentSuspended)
+		return
+	}
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionContentSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cr.Generation,
+		Reason:             conditionReasonApplySuspended,
+		Message:            "Dashboards, datasources and other content are not applied to the instance",
+	})
+}
+
+// setStartup records the startup phase, keeping the time the startup began across phases. An empty phase ends the startup
+func setStartup(cr *grafanav1beta1.Grafana, phase grafanav1beta1.GrafanaStartupPhase, now time.Time) {
+	if phase == "" {
+		cr.Status.Startup = nil
+		return
+	}
+
+	since := metav1.Time{Time: now}
+	if cr.Status.Startup != nil {
+		since = cr.Status.Startup.Since
+	}
+
+	cr.Status.Startup = &grafanav1beta1.GrafanaStartup{
+		Phase: phase,
+		Since: since,
+	}
+}
+
+// recordManagedObjects exports the number of resources applied to the instance by kind
+func recordManagedObjects(cr *grafanav1beta1.Grafana) {
+	labels := metrics.InstanceLabels(cr.Namespace, cr.Name)
+	if labels["instance_name"] == metrics.OtherInstances {
+		// Counts of aggregated instances would overwrite each other
+		return
+	}
+
+	managed := metrics.ManagedObjects.MustCurryWith(labels)
+
+	for kind, list := range map[string]grafanav1beta1.NamespacedResourceList{
+		"alertRuleGroups":       cr.Status.AlertRuleGroups,
+		"contactPoints":         cr.Status.ContactPoints,
+		"dashboards":            cr.Status.Dashboards,
+		"datasources":           cr.Status.Datasources,
+		"folders":               cr.Status.Folders,
+		"libraryPanels":         cr.Status.LibraryPanels,
+		"muteTimings":           cr.Status.MuteTimings,
+		"notificationTemplates": cr.Status.NotificationTemplates,
+	} {
+		managed.WithLabelValues(kind).Set(float64(len(list)))
+	}
 }
 
 func (r *GrafanaReconciler) setDefaultGrafanaVersion(ctx context.Context, cr client.Object) error {
@@ -175,6 +384,46 @@ func (r *GrafanaReconciler) setDefaultGrafanaVersion(ctx context.Context, cr cli
 	return r.Patch(ctx, cr, client.RawPatch(types.MergePatchType, patch))
 }
 
+// mergeConfigFrom merges the grafana.ini fragments of spec.configFrom below spec.config in the in-memory spec
+func (r *GrafanaReconciler) mergeConfigFrom(ctx context.Context, cr *grafanav1beta1.Grafana) error {
+	fragments := make([]map[string]map[string]string, 0, len(cr.Spec.ConfigFrom))
+
+	for _, ref := range cr.Spec.ConfigFrom {
+		optional := ref.Optional != nil && *ref.Optional
+
+		cm := &corev1.ConfigMap{}
+
+		err := r.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: ref.Name}, cm)
+		if err != nil {
+			if errors.IsNotFound(err) && optional {
+				continue
+			}
+
+			return fmt.Errorf("getting ConfigMap %s of spec.configFrom: %w", ref.Name, err)
+		}
+
+		data, ok := cm.Data[ref.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+
+			return fmt.Errorf("key %s not found in ConfigMap %s of spec.configFrom", ref.Key, ref.Name)
+		}
+
+		fragment, err := config.ParseIni(data)
+		if err != nil {
+			return fmt.Errorf("parsing key %s of ConfigMap %s: %w", ref.Key, ref.Name, err)
+		}
+
+		fragments = append(fragments, fragment)
+	}
+
+	cr.Spec.Config = config.WithFragments(cr.Spec.Config, fragments)
+
+	return nil
+}
+
 func removeMissingCRs(statusList *grafanav1beta1.NamespacedResourceList, crs grafanav1beta1.NamespacedResourceImpl, updateStatus *bool) {
 	toRemove := grafanav1beta1.NamespacedResourceList{}
 
@@ -268,6 +517,7 @@ func (r *GrafanaReconciler) syncStatuses(ctx context.Context) error {
 
 	for _, grafana := range grafanas.Items {
 		updateStatus := false
+		original := grafana.DeepCopy()
 
 		removeMissingCRs(&grafana.Status.AlertRuleGroups, alertRuleGroups, &updateStatus)
 		removeMissingCRs(&grafana.Status.ContactPoints, contactPoints, &updateStatus)
@@ -281,7 +531,7 @@ func (r *GrafanaReconciler) syncStatuses(ctx context.Context) error {
 		if updateStatus {
 			statusUpdates += 1
 
-			err = r.Client.Status().Update(ctx, &grafana)
+			err = patchStatus(ctx, r.Client, original, &grafana)
 			if err != nil {
 				return err
 			}
@@ -297,11 +547,63 @@ func (r *GrafanaReconciler) syncStatuses(ctx context.Context) error {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *GrafanaReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	err := ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&grafanav1beta1.Grafana{}, builder.WithPredicates(ignoreStatusUpdates())).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(ignoreStatusUpdates())).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(ignoreStatusUpdates())).
 		Owns(&corev1.ConfigMap{}).
-		WithOptions(controller.Options{RateLimiter: defaultRateLimiter()}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Watches(
+			&grafanav1beta1.GrafanaDatasource{},
+			enqueueCoalesced(r.requestsForDatasourceTLS, r.SyncWindow),
+			builder.WithPredicates(datasourceTLSChanged()),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			enqueueCoalesced(r.requestsForConfigFrom, r.SyncWindow),
+		).
+		Watches(
+			&corev1.Secret{},
+			enqueueCoalesced(r.requestsForRouteDestinationCA, r.SyncWindow),
+		)
+
+	if r.ClusterScoped {
+		b = b.Watches(
+			&grafanav1beta1.GrafanaClass{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForGrafanaClass),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		)
+	}
+
+	// status changes of the parents are copied into status.httpRoute
+	if r.HasHTTPRoutes {
+		b = b.Owns(&gwapiv1.HTTPRoute{})
+	}
+
+	// status changes of the parents are copied into the GRPCRouteAccepted condition
+	if r.HasGRPCRoutes {
+		b = b.Owns(&gwapiv1.GRPCRoute{})
+	}
+
+	// monitors changed or removed by hand are restored
+	if r.HasMonitors {
+		for _, gvk := range []schema.GroupVersionKind{model.ServiceMonitorGVK, model.PodMonitorGVK} {
+			monitor := &unstructured.Unstructured{}
+			monitor.SetGroupVersionKind(gvk)
+			b = b.Owns(monitor)
+		}
+	}
+
+	// scaled objects changed or removed by hand are restored
+	if r.HasScaledObjects {
+		scaledObject := &unstructured.Unstructured{}
+		scaledObject.SetGroupVersionKind(model.ScaledObjectGVK)
+		b = b.Owns(scaledObject)
+	}
+
+	err := b.WithOptions(controller.Options{RateLimiter: defaultRateLimiter()}).
 		Complete(r)
 	if err != nil {
 		return err
@@ -343,6 +645,69 @@ func (r *GrafanaReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 	return nil
 }
 
+// datasourceTLSChanged only passes datasource events that affect the TLS Secrets mounted into Grafana pods
+func datasourceTLSChanged() predicate.Predicate {
+	hasTLS := func(o client.Object) bool {
+		ds, ok := o.(*grafanav1beta1.GrafanaDatasource)
+		return ok && ds.Spec.TLS != nil
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasTLS(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return (hasTLS(e.ObjectOld) || hasTLS(e.ObjectNew)) && e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return hasTLS(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// requestsForDatasourceTLS maps a datasource to the instances in its namespace that mount its TLS Secret
+func (r *GrafanaReconciler) requestsForDatasourceTLS(ctx context.Context, o client.Object) []reconcile.Request {
+	ds, ok := o.(*grafanav1beta1.GrafanaDatasource)
+	if !ok || ds.Spec.InstanceSelector == nil {
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.InstanceSelector)
+	if err != nil {
+		return nil
+	}
+
+	var list grafanav1beta1.GrafanaList
+	if err := r.List(ctx, &list, client.InNamespace(ds.Namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list grafanas for datasource watch mapping")
+		return nil
+	}
+
+	grafanas, _, err := provision.ResolveClasses(ctx, r.Client, list.Items)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to resolve classes for datasource watch mapping")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, grafana := range grafanas {
+		if grafana.IsExternal() || !selector.Matches(labels.Set(grafana.Labels)) {
+			continue
+		}
+
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: grafana.Namespace,
+			Name:      grafana.Name,
+		}})
+	}
+
+	return reqs
+}
+
 func getInstallationStages() []grafanav1beta1.OperatorStageName {
 	return []grafanav1beta1.OperatorStageName{
 		grafanav1beta1.OperatorStageAdminUser,
@@ -352,7 +717,14 @@ func getInstallationStages() []grafanav1beta1.OperatorStageName {
 		grafanav1beta1.OperatorStageService,
 		grafanav1beta1.OperatorStageIngress,
 		grafanav1beta1.OperatorStageHTTPRoute,
+		grafanav1beta1.OperatorStageGRPCRoute,
 		grafanav1beta1.OperatorStagePlugins,
+		grafanav1beta1.OperatorStagePdb,
+		grafanav1beta1.OperatorStageHpa,
+		grafanav1beta1.OperatorStageNetworkPolicy,
+		grafanav1beta1.OperatorStageMonitor,
+		grafanav1beta1.OperatorStageImageRenderer,
+		grafanav1beta1.OperatorStageDatabase,
 		grafanav1beta1.OperatorStageDeployment,
 		grafanav1beta1.OperatorStageComplete,
 	}
@@ -369,13 +741,27 @@ func (r *GrafanaReconciler) getReconcilerForStage(stage grafanav1beta1.OperatorS
 	case grafanav1beta1.OperatorStageServiceAccount:
 		return grafana.NewServiceAccountReconciler(r.Client)
 	case grafanav1beta1.OperatorStageHTTPRoute:
-		return grafana.NewHTTPRouteReconciler(r.Client)
+		return grafana.NewHTTPRouteReconciler(r.Client, r.ClusterScoped)
+	case grafanav1beta1.OperatorStageGRPCRoute:
+		return grafana.NewGRPCRouteReconciler(r.Client, r.HasGRPCRoutes)
 	case grafanav1beta1.OperatorStageService:
 		return grafana.NewServiceReconciler(r.Client, r.ClusterDomain)
 	case grafanav1beta1.OperatorStageIngress:
 		return grafana.NewIngressReconciler(r.Client, r.IsOpenShift)
 	case grafanav1beta1.OperatorStagePlugins:
 		return grafana.NewPluginsReconciler(r.Client)
+	case grafanav1beta1.OperatorStagePdb:
+		return grafana.NewPodDisruptionBudgetReconciler(r.Client)
+	case grafanav1beta1.OperatorStageHpa:
+		return grafana.NewHorizontalPodAutoscalerReconciler(r.Client)
+	case grafanav1beta1.OperatorStageNetworkPolicy:
+		return grafana.NewNetworkPolicyReconciler(r.Client, r.IsOpenShift, r.Operator)
+	case grafanav1beta1.OperatorStageMonitor:
+		return grafana.NewMonitorReconciler(r.Client, r.HasMonitors)
+	case grafanav1beta1.OperatorStageImageRenderer:
+		return grafana.NewImageRendererReconciler(r.Client, r.IsOpenShift, r.HasScaledObjects)
+	case grafanav1beta1.OperatorStageDatabase:
+		return grafana.NewDatabaseReconciler(r.Client)
 	case grafanav1beta1.OperatorStageDeployment:
 		return grafana.NewDeploymentReconciler(r.Client, r.IsOpenShift)
 	case grafanav1beta1.OperatorStageComplete:
@@ -388,3 +774,110 @@ func (r *GrafanaReconciler) getReconcilerForStage(stage grafanav1beta1.OperatorS
 func isImageSHA256(image string) bool {
 	return strings.Contains(image, "@sha256:")
 }
+
+// requestsForConfigFrom enqueues the instances in the namespace of the ConfigMap referencing it in spec.configFrom
+// or as destination CA of spec.route.tls
+func (r *GrafanaReconciler) requestsForConfigFrom(ctx context.Context, o client.Object) []reconcile.Request {
+	var list grafanav1beta1.GrafanaList
+	if err := r.List(ctx, &list, client.InNamespace(o.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list grafanas for configFrom watch mapping")
+		return nil
+	}
+
+	grafanas, _, err := provision.ResolveClasses(ctx, r.Client, list.Items)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to resolve classes for configFrom watch mapping")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, grafana := range grafanas {
+		referenced := slices.ContainsFunc(grafana.Spec.ConfigFrom, func(ref corev1.ConfigMapKeySelector) bool {
+			return ref.Name == o.GetName()
+		})
+
+		// readonly instances install the plugins of their primary
+		if !referenced && !isPrimaryPluginsConfigMap(&grafana, o) && !isRouteDestinationCA(&grafana, o) {
+			continue
+		}
+
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: grafana.Namespace, Name: grafana.Name}})
+	}
+
+	return reqs
+}
+
+// isPrimaryPluginsConfigMap reports whether o is the plugins ConfigMap of the primary of a readonly instance
+func isPrimaryPluginsConfigMap(grafana *grafanav1beta1.Grafana, o client.Object) bool {
+	if !grafana.IsReadonly() || grafana.Spec.Primary == "" {
+		return false
+	}
+
+	return o.GetName() == grafana.Spec.Primary+"-plugins"
+}
+
+// requestsForRouteDestinationCA enqueues the instances in the namespace of the Secret holding the destination CA
+// of their spec.route.tls, so rotated CAs are applied to the route right away
+func (r *GrafanaReconciler) requestsForRouteDestinationCA(ctx context.Context, o client.Object) []reconcile.Request {
+	var list grafanav1beta1.GrafanaList
+	if err := r.List(ctx, &list, client.InNamespace(o.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list grafanas for destination CA watch mapping")
+		return nil
+	}
+
+	grafanas, _, err := provision.ResolveClasses(ctx, r.Client, list.Items)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to resolve classes for destination CA watch mapping")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, grafana := range grafanas {
+		if isRouteDestinationCA(&grafana, o) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: grafana.Namespace, Name: grafana.Name}})
+		}
+	}
+
+	return reqs
+}
+
+// isRouteDestinationCA reports whether o is the ConfigMap or Secret holding the destination CA of the route of the instance
+func isRouteDestinationCA(grafana *grafanav1beta1.Grafana, o client.Object) bool {
+	if grafana.Spec.Route == nil || grafana.Spec.Route.TLS == nil || grafana.Spec.Route.TLS.DestinationCA == nil {
+		return false
+	}
+
+	ca := grafana.Spec.Route.TLS.DestinationCA
+
+	switch o.(type) {
+	case *corev1.ConfigMap:
+		return ca.ConfigMapKeyRef != nil && ca.ConfigMapKeyRef.Name == o.GetName()
+	case *corev1.Secret:
+		return ca.SecretKeyRef != nil && ca.SecretKeyRef.Name == o.GetName()
+	default:
+		return false
+	}
+}
+
+// requestsForGrafanaClass maps a class to the instances referencing it
+func (r *GrafanaReconciler) requestsForGrafanaClass(ctx context.Context, o client.Object) []reconcile.Request {
+	var list grafanav1beta1.GrafanaList
+	if err := r.List(ctx, &list); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list grafanas for class watch mapping")
+		return nil
+	}
+
+	var reqs []reconcile.Request
+
+	for _, grafana := range list.Items {
+		if grafana.Spec.ClassName != o.GetName() {
+			continue
+		}
+
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: grafana.Namespace, Name: grafana.Name}})
+	}
+
+	return reqs
+}
@@ -1,16 +1,115 @@
 package controllers
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	v1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	. "github.com/onsi/ginkgo/v2"
 )
 
+func TestMergeConfigFrom(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	platform := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "platform-defaults"},
+		Data:       map[string]string{"grafana.ini": "[log]\nlevel = info\n[server]\nenable_gzip = true\n"},
+	}
+	team := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "team-overrides"},
+		Data:       map[string]string{"overrides.ini": "[log]\nlevel = debug\n[users]\ndefault_theme = light\n"},
+	}
+
+	r := &GrafanaReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(platform, team).Build()}
+
+	newGrafana := func(refs ...corev1.ConfigMapKeySelector) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "grafana"},
+			Spec: v1beta1.GrafanaSpec{
+				Config:     map[string]map[string]string{"users": {"default_theme": "dark"}},
+				ConfigFrom: refs,
+			},
+		}
+	}
+
+	ref := func(name, key string, optional bool) corev1.ConfigMapKeySelector {
+		return corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: &optional}
+	}
+
+	t.Run("fragments are merged in order below spec.config", func(t *testing.T) {
+		cr := newGrafana(ref("platform-defaults", "grafana.ini", false), ref("team-overrides", "overrides.ini", false), ref("missing", "grafana.ini", true))
+
+		require.NoError(t, r.mergeConfigFrom(context.Background(), cr))
+		assert.Equal(t, map[string]map[string]string{
+			"log":    {"level": "debug"},
+			"server": {"enable_gzip": "true"},
+			"users":  {"default_theme": "dark"},
+		}, cr.Spec.Config)
+	})
+
+	t.Run("required references must exist", func(t *testing.T) {
+		cr := newGrafana(ref("missing", "grafana.ini", false))
+		require.ErrorContains(t, r.mergeConfigFrom(context.Background(), cr), "getting ConfigMap missing of spec.configFrom")
+
+		cr = newGrafana(ref("platform-defaults", "other.ini", false))
+		require.ErrorContains(t, r.mergeConfigFrom(context.Background(), cr), "key other.ini not found in ConfigMap platform-defaults")
+	})
+
+	t.Run("referencing instances are enqueued", func(t *testing.T) {
+		cr := newGrafana(ref("team-overrides", "overrides.ini", false))
+		other := newGrafana()
+		other.Name = "other"
+
+		r := &GrafanaReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(cr, other).Build()}
+
+		reqs := r.requestsForConfigFrom(context.Background(), team)
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "grafana"}}}, reqs)
+	})
+
+	t.Run("instances with the route destination CA are enqueued", func(t *testing.T) {
+		cr := newGrafana()
+		cr.Spec.Route = &v1beta1.RouteOpenshiftV1{TLS: &v1beta1.RouteTLS{
+			Termination:   v1beta1.TLSTerminationReencrypt,
+			DestinationCA: &v1beta1.RouteDestinationCA{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "team-overrides"}, Key: "ca.crt"}},
+		}}
+
+		r := &GrafanaReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(cr).Build()}
+
+		reqs := r.requestsForConfigFrom(context.Background(), team)
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "grafana"}}}, reqs)
+	})
+
+	t.Run("instances with the route destination CA in a Secret are enqueued", func(t *testing.T) {
+		cr := newGrafana()
+		cr.Spec.Route = &v1beta1.RouteOpenshiftV1{TLS: &v1beta1.RouteTLS{
+			Termination:   v1beta1.TLSTerminationReencrypt,
+			DestinationCA: &v1beta1.RouteDestinationCA{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-ca"}, Key: "ca.crt"}},
+		}}
+
+		r := &GrafanaReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(cr).Build()}
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "grafana-ca"}}
+		reqs := r.requestsForRouteDestinationCA(context.Background(), secret)
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "grafana"}}}, reqs)
+
+		// A ConfigMap of the same name is not the destination CA
+		assert.Empty(t, r.requestsForConfigFrom(context.Background(), &corev1.ConfigMap{ObjectMeta: secret.ObjectMeta}))
+	})
+}
+
 func TestRemoveMissingCRs(t *testing.T) {
 	statusList := v1beta1.NamespacedResourceList{
 		"default/present/uid",
@@ -97,3 +196,36 @@ var _ = Describe("Grafana Reconciler: Provoke Conditions", func() {
 		})
 	}
 })
+
+func TestSetStartup(t *testing.T) {
+	began := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	cr := &v1beta1.Grafana{}
+
+	setStartup(cr, v1beta1.GrafanaStartupStarting, began)
+	require.NotNil(t, cr.Status.Startup)
+	assert.Equal(t, v1beta1.GrafanaStartupStarting, cr.Status.Startup.Phase)
+
+	setStartup(cr, v1beta1.GrafanaStartupDatabaseUnavailable, began.Add(time.Minute))
+	assert.Equal(t, v1beta1.GrafanaStartupDatabaseUnavailable, cr.Status.Startup.Phase)
+	assert.Equal(t, began, cr.Status.Startup.Since.Time, "the startup keeps its beginning across phases")
+
+	setStartup(cr, "", began.Add(2*time.Minute))
+	assert.Nil(t, cr.Status.Startup)
+}
+
+func TestSetContentSuspended(t *testing.T) {
+	cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{SuspendContent: true}}
+
+	setContentSuspended(cr)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionContentSuspended))
+
+	setSuspended(&cr.Status.Conditions, cr.Generation, conditionReasonReconcileSuspended)
+	setContentSuspended(cr)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionSuspended))
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionContentSuspended), "pausing the workload keeps the content paused")
+
+	cr.Spec.SuspendContent = false
+
+	setContentSuspended(cr)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionContentSuspended))
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const grafanaComRevisionWebhookTimeout = 10 * time.Second
+
+// latestGrafanaComRevision is replaced in tests to not depend on grafana.com
+var latestGrafanaComRevision = fetchers.LatestGrafanaComRevision
+
+// GrafanaComRevisionNotification is the body of the webhook request sent once a newer grafana.com revision is detected
+type GrafanaComRevisionNotification struct {
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	GrafanaComID    int    `json:"grafanaComId"`
+	CurrentRevision int    `json:"currentRevision"`
+	LatestRevision  int    `json:"latestRevision"`
+	// Merge patch pinning the resource to the latest revision
+	Patch string `json:"patch"`
+}
+
+// checkGrafanaComRevision reports newer grafana.com revisions of content pinned to a revision through status, the
+// GrafanaComUpdateAvailable condition, a metric, an event and the webhook of the operator config.
+// grafana.com is checked at most once per GrafanaComRevisionCheckInterval
+func checkGrafanaComRevision(ctx context.Context, cfg *Config, recorder record.EventRecorder, cr v1beta1.GrafanaContentResource, conditions *[]metav1.Condition, generation int64) {
+	log := logf.FromContext(ctx)
+
+	labels := contentMetricLabels(cr)
+
+	status := cr.GrafanaContentStatus()
+	source := cr.GrafanaContentSpec().GrafanaCom
+
+	// Content following the latest revision is updated on the next fetch already
+	if cfg == nil || cfg.GrafanaComRevisionCheckInterval <= 0 || source == nil || source.Revision == nil {
+		status.GrafanaComRevision = nil
+
+		meta.RemoveStatusCondition(conditions, conditionGrafanaComUpdateAvailable)
+		forgetGrafanaComRevision(cr)
+
+		return
+	}
+
+	previous := status.GrafanaComRevision
+	if previous != nil && time.Since(previous.LastChecked.Time) < cfg.GrafanaComRevisionCheckInterval {
+		return
+	}
+
+	latest, err := latestGrafanaComRevision(cr)
+	if err != nil {
+		log.Error(err, "checking grafana.com for newer revisions", "id", source.ID)
+		return
+	}
+
+	status.GrafanaComRevision = &v1beta1.GrafanaComRevisionStatus{
+		Latest:      latest,
+		LastChecked: metav1.Now(),
+	}
+
+	if latest <= *source.Revision {
+		meta.RemoveStatusCondition(conditions, conditionGrafanaComUpdateAvailable)
+		forgetGrafanaComRevision(cr)
+
+		return
+	}
+
+	notification := GrafanaComRevisionNotification{
+		Kind:            labels["kind"],
+		Namespace:       cr.GetNamespace(),
+		Name:            cr.GetName(),
+		GrafanaComID:    source.ID,
+		CurrentRevision: *source.Revision,
+		LatestRevision:  latest,
+		Patch:           fmt.Sprintf(`{"spec":{"grafanaCom":{"id":%d,"revision":%d}}}`, source.ID, latest),
+	}
+
+	status.GrafanaComRevision.Patch = notification.Patch
+
+	message := fmt.Sprintf("Revision %d of grafana.com dashboard %d is available, pinned to revision %d", latest, source.ID, *source.Revision)
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionGrafanaComUpdateAvailable,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonNewRevision,
+		Message: message,
+	})
+	metrics.GrafanaComUpdateAvailable.With(labels).Set(1)
+
+	// Notify once per published revision
+	if previous != nil && previous.Latest == latest {
+		return
+	}
+
+	if recorder != nil {
+		recorder.Event(cr, corev1.EventTypeNormal, conditionReasonNewRevision, message)
+	}
+
+	if cfg.GrafanaComRevisionWebhookURL != "" {
+		err := sendGrafanaComRevisionNotification(ctx, cfg.GrafanaComRevisionWebhookURL, notification)
+		if err != nil {
+			log.Error(err, "notifying about a newer grafana.com revision", "id", source.ID, "revision", latest)
+		}
+	}
+}
+
+// forgetGrafanaComRevision deletes the GrafanaComUpdateAvailable series of a resource which is up to date or deleted
+func forgetGrafanaComRevision(cr v1beta1.GrafanaContentResource) {
+	metrics.GrafanaComUpdateAvailable.Delete(contentMetricLabels(cr))
+}
+
+func sendGrafanaComRevisionNotification(ctx context.Context, url string, notification GrafanaComRevisionNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{
+		Transport: client2.NewInstrumentedRoundTripper(true, client2.DefaultTLSConfiguration),
+		Timeout:   grafanaComRevisionWebhookTimeout,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
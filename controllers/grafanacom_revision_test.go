@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckGrafanaComRevision(t *testing.T) {
+	latest := 12
+	checks := 0
+
+	original := latestGrafanaComRevision
+	latestGrafanaComRevision = func(_ v1beta1.GrafanaContentResource) (int, error) {
+		checks++
+		return latest, nil
+	}
+
+	defer func() { latestGrafanaComRevision = original }()
+
+	var notifications []GrafanaComRevisionNotification
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notification := GrafanaComRevisionNotification{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+
+		notifications = append(notifications, notification)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{
+		GrafanaComRevisionCheckInterval: time.Hour,
+		GrafanaComRevisionWebhookURL:    srv.URL,
+	}
+	recorder := record.NewFakeRecorder(10)
+
+	cr := &v1beta1.GrafanaDashboard{
+		TypeMeta:   metav1.TypeMeta{Kind: "GrafanaDashboard"},
+		ObjectMeta: metav1.ObjectMeta{Name: "node-exporter", Namespace: "default"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				GrafanaCom: &v1beta1.GrafanaComContentReference{ID: 1860, Revision: ptr.To(10)},
+			},
+		},
+	}
+
+	check := func() {
+		checkGrafanaComRevision(t.Context(), cfg, recorder, cr, &cr.Status.Conditions, cr.Generation)
+	}
+
+	check()
+
+	require.NotNil(t, cr.Status.GrafanaComRevision)
+	assert.Equal(t, 12, cr.Status.GrafanaComRevision.Latest)
+	assert.JSONEq(t, `{"spec":{"grafanaCom":{"id":1860,"revision":12}}}`, cr.Status.GrafanaComRevision.Patch)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionGrafanaComUpdateAvailable))
+	require.Len(t, notifications, 1)
+	assert.Equal(t, GrafanaComRevisionNotification{
+		Kind:            "GrafanaDashboard",
+		Namespace:       "default",
+		Name:            "node-exporter",
+		GrafanaComID:    1860,
+		CurrentRevision: 10,
+		LatestRevision:  12,
+		Patch:           cr.Status.GrafanaComRevision.Patch,
+	}, notifications[0])
+	assert.Len(t, recorder.Events, 1)
+
+	// grafana.com is not checked again within the interval
+	check()
+	assert.Equal(t, 1, checks)
+
+	// A known revision is not notified again
+	cr.Status.GrafanaComRevision.LastChecked = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	check()
+	assert.Equal(t, 2, checks)
+	assert.Len(t, notifications, 1)
+	assert.Len(t, recorder.Events, 1)
+
+	// Pinned to the latest revision
+	cr.Spec.GrafanaCom.Revision = ptr.To(12)
+	cr.Status.GrafanaComRevision.LastChecked = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	check()
+	assert.Empty(t, cr.Status.GrafanaComRevision.Patch)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionGrafanaComUpdateAvailable))
+	assert.False(t, metrics.GrafanaComUpdateAvailable.Delete(contentMetricLabels(cr)))
+
+	// Following the latest revision
+	cr.Spec.GrafanaCom.Revision = nil
+	check()
+	assert.Nil(t, cr.Status.GrafanaComRevision)
+	assert.Equal(t, 3, checks)
+}
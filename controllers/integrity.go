@@ -0,0 +1,533 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/cache"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Dangling references listed in the DanglingReferences condition
+const maxReportedDanglingReferences = 10
+
+// Contact points Grafana provisions itself
+var builtinContactPoints = []string{"grafana-default-email"}
+
+var _ manager.LeaderElectionRunnable = (*IntegrityChecker)(nil)
+
+// IntegrityChecker periodically validates the references between resources across the cluster, e.g. folderRefs,
+// datasources used by dashboards and contact points used by notification policies. References are resolved in every
+// instance the resource is applied to, against the objects of that instance including those not managed by the
+// operator. Resources with dangling references get the DanglingReferences condition, the number of affected resources
+// is reported by a metric
+type IntegrityChecker struct {
+	Client   client.Client
+	Interval time.Duration
+}
+
+// referenceIndex holds the targets references are resolved against per instance
+type referenceIndex struct {
+	// by namespace/name of the instance, instances whose objects could not be fetched are missing
+	instances map[string]*instanceIndex
+	// namespace/name of the instances of the notification policies discovering a route, by namespace/name of the route
+	routeInstances map[string][]string
+}
+
+// instanceIndex holds the targets available in an instance
+type instanceIndex struct {
+	// namespace/name of GrafanaFolders applied to the instance
+	folders map[string]bool
+	// uids and names of the datasources of the instance and former uids migrated by GrafanaDatasources
+	datasources map[string]bool
+	// names of the contact points of the instance
+	contactPoints map[string]bool
+	// names of the mute timings of the instance
+	muteTimings map[string]bool
+}
+
+// NeedLeaderElection returns true, only the leader updates the status of resources
+func (c *IntegrityChecker) NeedLeaderElection() bool {
+	return true
+}
+
+// Start checks the references every interval until the context is cancelled
+func (c *IntegrityChecker) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("integrity")
+	ctx = logf.IntoContext(ctx, log)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Check(ctx); err != nil {
+			log.Error(err, "checking resource references")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check validates the references of all resources once
+func (c *IntegrityChecker) Check(ctx context.Context) error {
+	folders := &v1beta1.GrafanaFolderList{}
+	if err := c.Client.List(ctx, folders); err != nil {
+		return fmt.Errorf("listing folders: %w", err)
+	}
+
+	index, err := c.buildIndex(ctx, folders.Items)
+	if err != nil {
+		return err
+	}
+
+	dashboards := &v1beta1.GrafanaDashboardList{}
+	if err := c.Client.List(ctx, dashboards); err != nil {
+		return fmt.Errorf("listing dashboards: %w", err)
+	}
+
+	libraryPanels := &v1beta1.GrafanaLibraryPanelList{}
+	if err := c.Client.List(ctx, libraryPanels); err != nil {
+		return fmt.Errorf("listing library panels: %w", err)
+	}
+
+	alertRuleGroups := &v1beta1.GrafanaAlertRuleGroupList{}
+	if err := c.Client.List(ctx, alertRuleGroups); err != nil {
+		return fmt.Errorf("listing alert rule groups: %w", err)
+	}
+
+	policies := &v1beta1.GrafanaNotificationPolicyList{}
+	if err := c.Client.List(ctx, policies); err != nil {
+		return fmt.Errorf("listing notification policies: %w", err)
+	}
+
+	policyRoutes := &v1beta1.GrafanaNotificationPolicyRouteList{}
+	if err := c.Client.List(ctx, policyRoutes); err != nil {
+		return fmt.Errorf("listing notification policy routes: %w", err)
+	}
+
+	dangling := map[string]int{}
+
+	report := func(kind string, obj client.Object, conditions *[]metav1.Condition, refs []string) {
+		if len(refs) > 0 {
+			dangling[kind]++
+		}
+
+		if err := c.report(ctx, obj, conditions, refs); err != nil {
+			logf.FromContext(ctx).Error(err, "reporting dangling references", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+	}
+
+	for i := range dashboards.Items {
+		cr := &dashboards.Items[i]
+		datasources := dashboardDatasourceRefs(cr)
+
+		report("GrafanaDashboard", cr, &cr.Status.Conditions, index.dangling(c.instancesOf(ctx, cr), func(instance *instanceIndex) []string {
+			return append(instance.danglingFolder(cr.Namespace, cr.Spec.FolderRef), instance.danglingDatasources(datasources)...)
+		}))
+	}
+
+	for i := range libraryPanels.Items {
+		cr := &libraryPanels.Items[i]
+		report("GrafanaLibraryPanel", cr, &cr.Status.Conditions, index.dangling(c.instancesOf(ctx, cr), func(instance *instanceIndex) []string {
+			return instance.danglingFolder(cr.Namespace, cr.Spec.FolderRef)
+		}))
+	}
+
+	for i := range folders.Items {
+		cr := &folders.Items[i]
+		report("GrafanaFolder", cr, &cr.Status.Conditions, index.dangling(c.instancesOf(ctx, cr), func(instance *instanceIndex) []string {
+			return instance.danglingFolder(cr.Namespace, cr.Spec.ParentFolderRef)
+		}))
+	}
+
+	for i := range alertRuleGroups.Items {
+		cr := &alertRuleGroups.Items[i]
+		report("GrafanaAlertRuleGroup", cr, &cr.Status.Conditions, index.dangling(c.instancesOf(ctx, cr), func(instance *instanceIndex) []string {
+			refs := instance.danglingFolder(cr.Namespace, cr.Spec.FolderRef)
+
+			for _, rule := range cr.Spec.Rules {
+				if rule.NotificationSettings == nil {
+					continue
+				}
+
+				refs = append(refs, instance.danglingContactPoints(rule.NotificationSettings.Receiver)...)
+				refs = append(refs, instance.danglingMuteTimings(rule.NotificationSettings.MuteTimeIntervals...)...)
+			}
+
+			return refs
+		}))
+	}
+
+	for i := range policies.Items {
+		cr := &policies.Items[i]
+		instances := c.instancesOf(ctx, cr)
+
+		// Routes are applied to the instances of the policies discovering them
+		if cr.Status.DiscoveredRoutes != nil {
+			for _, route := range *cr.Status.DiscoveredRoutes {
+				index.routeInstances[route] = append(index.routeInstances[route], instances...)
+			}
+		}
+
+		report("GrafanaNotificationPolicy", cr, &cr.Status.Conditions, index.dangling(instances, func(instance *instanceIndex) []string {
+			return instance.danglingRouteRefs(cr.Spec.Route)
+		}))
+	}
+
+	for i := range policyRoutes.Items {
+		cr := &policyRoutes.Items[i]
+		instances := index.routeInstances[cr.Namespace+"/"+cr.Name]
+
+		report("GrafanaNotificationPolicyRoute", cr, &cr.Status.Conditions, index.dangling(instances, func(instance *instanceIndex) []string {
+			return instance.danglingRouteRefs(&cr.Spec.Route)
+		}))
+	}
+
+	for _, kind := range []string{"GrafanaDashboard", "GrafanaLibraryPanel", "GrafanaFolder", "GrafanaAlertRuleGroup", "GrafanaNotificationPolicy", "GrafanaNotificationPolicyRoute"} {
+		metrics.DanglingReferences.WithLabelValues(kind).Set(float64(dangling[kind]))
+	}
+
+	return nil
+}
+
+// instancesOf returns the namespace/name of the ready instances the resource is applied to
+func (c *IntegrityChecker) instancesOf(ctx context.Context, cr v1beta1.CommonResource) []string {
+	instances, _, err := provision.MatchingInstances(ctx, c.Client, scopedNamespace(cr), cr.MatchLabels())
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "fetching instances", "namespace", cr.GetNamespace(), "name", cr.GetName())
+		return nil
+	}
+
+	keys := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		keys = append(keys, instance.Namespace+"/"+instance.Name)
+	}
+
+	return keys
+}
+
+// buildIndex fetches the datasources, contact points and mute timings of every ready instance from Grafana, so
+// objects provisioned outside the operator resolve as well. Targets managed by the operator are added to the
+// instances they are applied to, so references are not reported while the targets are still being applied
+func (c *IntegrityChecker) buildIndex(ctx context.Context, folders []v1beta1.GrafanaFolder) (*referenceIndex, error) {
+	index := &referenceIndex{
+		instances:      map[string]*instanceIndex{},
+		routeInstances: map[string][]string{},
+	}
+
+	grafanas := &v1beta1.GrafanaList{}
+	if err := c.Client.List(ctx, grafanas); err != nil {
+		return nil, fmt.Errorf("listing instances: %w", err)
+	}
+
+	for i := range grafanas.Items {
+		grafana := &grafanas.Items[i]
+		if !provision.IsReady(grafana) || grafana.Spec.SuspendContent {
+			continue
+		}
+
+		instance, err := c.fetchInstanceIndex(ctx, grafana)
+		if err != nil {
+			// References are not checked against instances whose objects are unknown
+			logf.FromContext(ctx).Error(err, "fetching objects of the instance", "namespace", grafana.Namespace, "name", grafana.Name)
+			continue
+		}
+
+		index.instances[grafana.Namespace+"/"+grafana.Name] = instance
+	}
+
+	add := func(cr v1beta1.CommonResource, apply func(instance *instanceIndex)) {
+		for _, key := range c.instancesOf(ctx, cr) {
+			if instance, ok := index.instances[key]; ok {
+				apply(instance)
+			}
+		}
+	}
+
+	for i := range folders {
+		folder := &folders[i]
+		add(folder, func(instance *instanceIndex) {
+			instance.folders[folder.Namespace+"/"+folder.Name] = true
+		})
+	}
+
+	datasources := &v1beta1.GrafanaDatasourceList{}
+	if err := c.Client.List(ctx, datasources); err != nil {
+		return nil, fmt.Errorf("listing datasources: %w", err)
+	}
+
+	for i := range datasources.Items {
+		ds := &datasources.Items[i]
+		add(ds, func(instance *instanceIndex) {
+			instance.datasources[ds.CustomUIDOrUID()] = true
+
+			if ds.Spec.Datasource != nil && ds.Spec.Datasource.Name != "" {
+				instance.datasources[ds.Spec.Datasource.Name] = true
+			}
+
+			for _, uid := range ds.MigratedUIDs() {
+				instance.datasources[uid] = true
+			}
+		})
+	}
+
+	contactPoints := &v1beta1.GrafanaContactPointList{}
+	if err := c.Client.List(ctx, contactPoints); err != nil {
+		return nil, fmt.Errorf("listing contact points: %w", err)
+	}
+
+	for i := range contactPoints.Items {
+		cp := &contactPoints.Items[i]
+		add(cp, func(instance *instanceIndex) {
+			instance.contactPoints[cp.Spec.Name] = true
+		})
+	}
+
+	muteTimings := &v1beta1.GrafanaMuteTimingList{}
+	if err := c.Client.List(ctx, muteTimings); err != nil {
+		return nil, fmt.Errorf("listing mute timings: %w", err)
+	}
+
+	for i := range muteTimings.Items {
+		mt := &muteTimings.Items[i]
+		add(mt, func(instance *instanceIndex) {
+			instance.muteTimings[mt.Spec.Name] = true
+		})
+	}
+
+	return index, nil
+}
+
+// fetchInstanceIndex returns the datasources, contact points and mute timings existing in the instance
+func (c *IntegrityChecker) fetchInstanceIndex(ctx context.Context, grafana *v1beta1.Grafana) (*instanceIndex, error) {
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, c.Client, grafana)
+	if err != nil {
+		return nil, fmt.Errorf("creating grafana http client: %w", err)
+	}
+
+	instance := &instanceIndex{
+		folders:       map[string]bool{},
+		datasources:   map[string]bool{},
+		contactPoints: map[string]bool{},
+		muteTimings:   map[string]bool{},
+	}
+
+	datasources, err := grafanaClient.Datasources.GetDataSources()
+	if err != nil {
+		return nil, fmt.Errorf("listing datasources: %w", err)
+	}
+
+	for _, ds := range datasources.Payload {
+		instance.datasources[ds.UID] = true
+		instance.datasources[ds.Name] = true
+	}
+
+	contactPoints, err := grafanaClient.Provisioning.GetContactpoints(provisioning.NewGetContactpointsParams())
+	if err != nil {
+		return nil, fmt.Errorf("listing contact points: %w", err)
+	}
+
+	for _, name := range builtinContactPoints {
+		instance.contactPoints[name] = true
+	}
+
+	for _, cp := range contactPoints.Payload {
+		instance.contactPoints[cp.Name] = true
+	}
+
+	muteTimings, err := grafanaClient.Provisioning.GetMuteTimings()
+	if err != nil {
+		return nil, fmt.Errorf("listing mute timings: %w", err)
+	}
+
+	for _, mt := range muteTimings.Payload {
+		instance.muteTimings[mt.Name] = true
+	}
+
+	return instance, nil
+}
+
+// dangling returns the references missing in the instances, suffixed with the instance they are missing in.
+// Instances which could not be indexed are skipped
+func (index *referenceIndex) dangling(instances []string, check func(instance *instanceIndex) []string) []string {
+	var refs []string
+
+	for _, key := range instances {
+		instance, ok := index.instances[key]
+		if !ok {
+			continue
+		}
+
+		for _, ref := range check(instance) {
+			refs = append(refs, fmt.Sprintf("%s in %s", ref, key))
+		}
+	}
+
+	slices.Sort(refs)
+
+	return slices.Compact(refs)
+}
+
+func (instance *instanceIndex) danglingFolder(namespace, folderRef string) []string {
+	if folderRef == "" || instance.folders[namespace+"/"+folderRef] {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("GrafanaFolder %s/%s", namespace, folderRef)}
+}
+
+// dashboardDatasourceRefs returns the datasources referenced by the dashboard model and spec.datasources.
+// Builtin datasources and dashboard variables are left out. Models of remote sources are only checked once they
+// have been fetched into the content cache
+func dashboardDatasourceRefs(cr *v1beta1.GrafanaDashboard) []string {
+	refs := map[string]bool{}
+
+	for _, ds := range cr.Spec.Datasources {
+		refs[ds.DatasourceName] = true
+	}
+
+	model := []byte(cr.Spec.JSON)
+
+	switch {
+	case len(model) > 0:
+	case cr.Spec.GzipJSON != nil:
+		model, _ = cache.Gunzip(cr.Spec.GzipJSON)
+	case cr.Spec.YAML != "":
+		model, _ = fetchers.YAMLToJSON([]byte(cr.Spec.YAML))
+	case cr.Status.ContentCache != nil:
+		model, _ = cache.Gunzip(cr.Status.ContentCache)
+	}
+
+	var dashboardModel map[string]any
+	if json.Unmarshal(model, &dashboardModel) == nil {
+		collectDatasourceRefs(dashboardModel, refs)
+	}
+
+	var datasources []string
+
+	for ref := range refs {
+		if ref == "" || slices.Contains(unvalidatedDatasources, ref) || variableReference.MatchString(ref) {
+			continue
+		}
+
+		datasources = append(datasources, ref)
+	}
+
+	slices.Sort(datasources)
+
+	return datasources
+}
+
+func (instance *instanceIndex) danglingDatasources(refs []string) []string {
+	var dangling []string
+
+	for _, ref := range refs {
+		if !instance.datasources[ref] {
+			dangling = append(dangling, fmt.Sprintf("GrafanaDatasource %s", ref))
+		}
+	}
+
+	return dangling
+}
+
+// danglingRouteRefs returns the contact points and mute timings of the route and its sub-routes which do not exist
+func (instance *instanceIndex) danglingRouteRefs(route *v1beta1.Route) []string {
+	if route == nil {
+		return nil
+	}
+
+	refs := instance.danglingContactPoints(route.Receiver)
+	refs = append(refs, instance.danglingMuteTimings(route.MuteTimeIntervals...)...)
+	refs = append(refs, instance.danglingMuteTimings(route.ActiveTimeIntervals...)...)
+
+	for _, r := range route.Routes {
+		refs = append(refs, instance.danglingRouteRefs(r)...)
+	}
+
+	return refs
+}
+
+func (instance *instanceIndex) danglingContactPoints(names ...string) []string {
+	var dangling []string
+
+	for _, name := range names {
+		if name != "" && !instance.contactPoints[name] {
+			dangling = append(dangling, fmt.Sprintf("GrafanaContactPoint %s", name))
+		}
+	}
+
+	return dangling
+}
+
+func (instance *instanceIndex) danglingMuteTimings(names ...string) []string {
+	var dangling []string
+
+	for _, name := range names {
+		if name != "" && !instance.muteTimings[name] {
+			dangling = append(dangling, fmt.Sprintf("GrafanaMuteTiming %s", name))
+		}
+	}
+
+	return dangling
+}
+
+// report sets or removes the DanglingReferences condition and patches the status when the condition changed
+func (c *IntegrityChecker) report(ctx context.Context, obj client.Object, conditions *[]metav1.Condition, refs []string) error {
+	base, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("copying %T", obj)
+	}
+
+	if !setDanglingReferences(conditions, obj.GetGeneration(), refs) {
+		return nil
+	}
+
+	return c.Client.Status().Patch(ctx, obj, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+}
+
+// setDanglingReferences sets the DanglingReferences condition listing the missing targets or removes it when all
+// references resolve. Reports whether the conditions changed
+func setDanglingReferences(conditions *[]metav1.Condition, generation int64, refs []string) bool {
+	if len(refs) == 0 {
+		return meta.RemoveStatusCondition(conditions, conditionDanglingReferences)
+	}
+
+	listed := refs
+	if len(listed) > maxReportedDanglingReferences {
+		listed = listed[:maxReportedDanglingReferences]
+	}
+
+	message := fmt.Sprintf("%d referenced resources not found: %s", len(refs), strings.Join(listed, ", "))
+	if len(refs) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(refs)-len(listed))
+	}
+
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionDanglingReferences,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonReferencesNotFound,
+		Message: message,
+	})
+}
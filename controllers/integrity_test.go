@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIntegrityCheckerCheck(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, corev1.AddToScheme(s))
+
+	// Objects provisioned outside the operator resolve references as well
+	shared := grafanafake.NewServer()
+	defer shared.Close()
+
+	other := grafanafake.NewServer()
+	defer other.Close()
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	instance := func(name, url string, labels map[string]string) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+			Spec: v1beta1.GrafanaSpec{
+				External: &v1beta1.External{
+					URL: url,
+					APIKey: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+						Key:                  "token",
+					},
+				},
+			},
+			Status: v1beta1.GrafanaStatus{
+				Stage:       v1beta1.OperatorStageComplete,
+				StageStatus: v1beta1.OperatorStageResultSuccess,
+				AdminURL:    url,
+			},
+		}
+	}
+	selector := func(labels map[string]string) v1beta1.GrafanaCommonSpec {
+		return v1beta1.GrafanaCommonSpec{InstanceSelector: &metav1.LabelSelector{MatchLabels: labels}}
+	}
+	all := map[string]string{"dashboards": "grafana"}
+	teamA := map[string]string{"team": "a"}
+
+	folder := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "folder"},
+		Spec:       v1beta1.GrafanaFolderSpec{GrafanaCommonSpec: selector(teamA)},
+	}
+	datasource := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec: v1beta1.GrafanaDatasourceSpec{
+			GrafanaCommonSpec: selector(all),
+			CustomUID:         "prometheus",
+			Datasource:        &v1beta1.GrafanaDatasourceInternal{Name: "Prometheus"},
+		},
+	}
+	contactPoint := &v1beta1.GrafanaContactPoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "oncall"},
+		Spec:       v1beta1.GrafanaContactPointSpec{GrafanaCommonSpec: selector(teamA), Name: "oncall"},
+	}
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dashboard"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaCommonSpec: selector(all),
+			FolderRef:         "folder",
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				JSON: `{"panels": [
+					{"datasource": {"uid": "prometheus"}},
+					{"datasource": {"uid": "${ds}"}},
+					{"datasource": {"uid": "-- Grafana --"}},
+					{"datasource": {"uid": "loki-external"}}
+				]}`,
+				Datasources: []v1beta1.GrafanaContentDatasource{{InputName: "DS_PROMETHEUS", DatasourceName: "Prometheus"}},
+			},
+		},
+	}
+	libraryPanel := &v1beta1.GrafanaLibraryPanel{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "panel"},
+		Spec: v1beta1.GrafanaLibraryPanelSpec{
+			GrafanaCommonSpec: selector(teamA),
+			FolderRef:         "folder",
+		},
+		Status: v1beta1.GrafanaLibraryPanelStatus{
+			GrafanaCommonStatus: v1beta1.GrafanaCommonStatus{
+				Conditions: []metav1.Condition{{Type: conditionDanglingReferences, Status: metav1.ConditionTrue, Reason: conditionReasonReferencesNotFound}},
+			},
+		},
+	}
+	policy := &v1beta1.GrafanaNotificationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec: v1beta1.GrafanaNotificationPolicySpec{
+			GrafanaCommonSpec: selector(teamA),
+			Route: &v1beta1.Route{
+				Receiver: "grafana-default-email",
+				Routes: []*v1beta1.Route{
+					{Receiver: "oncall", MuteTimeIntervals: []string{"weekends"}},
+					{Receiver: "removed"},
+				},
+			},
+		},
+	}
+
+	sharedInstance := instance("shared", shared.URL, map[string]string{"dashboards": "grafana", "team": "a"})
+
+	objects := []client.Object{apiKey, sharedInstance, instance("other", other.URL, all), folder, datasource, contactPoint, dashboard, libraryPanel, policy}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).WithStatusSubresource(objects...).Build()
+
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(t.Context(), cl, sharedInstance)
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Datasources.AddDataSource(&models.AddDataSourceCommand{UID: "loki-external", Name: "External Loki", Type: "loki"})
+	require.NoError(t, err)
+
+	_, err = grafanaClient.Provisioning.PostMuteTiming(provisioning.NewPostMuteTimingParams().WithBody(&models.MuteTimeInterval{Name: "weekends"}))
+	require.NoError(t, err)
+
+	checker := &IntegrityChecker{Client: cl}
+	require.NoError(t, checker.Check(t.Context()))
+
+	got := &v1beta1.GrafanaDashboard{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(dashboard), got))
+
+	// The folder and the unmanaged datasource only exist in the shared instance
+	condition := meta.FindStatusCondition(got.Status.Conditions, conditionDanglingReferences)
+	require.NotNil(t, condition)
+	assert.Equal(t, conditionReasonReferencesNotFound, condition.Reason)
+	assert.Equal(t, "2 referenced resources not found: GrafanaDatasource loki-external in default/other, GrafanaFolder default/folder in default/other", condition.Message)
+
+	gotPanel := &v1beta1.GrafanaLibraryPanel{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(libraryPanel), gotPanel))
+	assert.Nil(t, meta.FindStatusCondition(gotPanel.Status.Conditions, conditionDanglingReferences))
+
+	gotPolicy := &v1beta1.GrafanaNotificationPolicy{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(policy), gotPolicy))
+
+	condition = meta.FindStatusCondition(gotPolicy.Status.Conditions, conditionDanglingReferences)
+	require.NotNil(t, condition)
+	assert.Equal(t, "1 referenced resources not found: GrafanaContactPoint removed in default/shared", condition.Message)
+}
+
+func TestDashboardDatasourceRefs(t *testing.T) {
+	dashboard := &v1beta1.GrafanaDashboard{
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+				YAML: `panels:
+- datasource:
+    uid: prometheus
+- datasource:
+    uid: ${ds}
+---
+templating:
+  list:
+  - datasource:
+      uid: loki
+`,
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"loki", "prometheus"}, dashboardDatasourceRefs(dashboard))
+}
+
+func TestSetDanglingReferences(t *testing.T) {
+	var conditions []metav1.Condition
+
+	refs := make([]string, 12)
+	for i := range refs {
+		refs[i] = "GrafanaContactPoint cp"
+	}
+
+	assert.True(t, setDanglingReferences(&conditions, 1, refs))
+	assert.Contains(t, conditions[0].Message, "12 referenced resources not found")
+	assert.Contains(t, conditions[0].Message, " and 2 more")
+
+	assert.False(t, setDanglingReferences(&conditions, 1, refs))
+	assert.True(t, setDanglingReferences(&conditions, 1, nil))
+	assert.Empty(t, conditions)
+}
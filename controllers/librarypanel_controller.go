@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -55,8 +56,9 @@ var errLibraryPanelContentUIDImmutable = errors.New("library panel uid is immuta
 // GrafanaLibraryPanelReconciler reconciles a GrafanaLibraryPanel object
 type GrafanaLibraryPanelReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Cfg    *Config
+	Scheme   *runtime.Scheme
+	Cfg      *Config
+	Recorder record.EventRecorder
 }
 
 func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -77,6 +79,15 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 	if libraryPanel.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(libraryPanel, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, libraryPanel)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, libraryPanel); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaLibraryPanel: %w", err)
 			}
@@ -89,7 +100,7 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, libraryPanel)
+	defer UpdateStatus(ctx, r.Client, libraryPanel, libraryPanel.DeepCopy())
 
 	if libraryPanel.Spec.Suspend {
 		setSuspended(&libraryPanel.Status.Conditions, libraryPanel.Generation, conditionReasonApplySuspended)
@@ -102,17 +113,40 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 		// grafana.com does not currently support hosting library panels for distribution, but perhaps
 		// this will change in the future.
 		content.ContentSourceTypeGrafanaCom,
+		// Git repositories, buckets and OCI artifacts are only supported as dashboard source
+		content.ContentSourceTypeGitRepo,
+		content.ContentSourceTypeS3,
+		content.ContentSourceTypeGCS,
+		content.ContentSourceTypeAzureBlob,
+		content.ContentSourceTypeOCI,
 	}))
 
 	// Retrieving the model before the loop ensures to exit early in case of failure and not fail once per matching instance
 	contentModel, hash, err := resolver.Resolve(ctx)
 	if err != nil {
+		if isSourceDeleted(libraryPanel, libraryPanel.Status.Conditions, err) {
+			return onSourceDeleted(ctx, libraryPanel, &libraryPanel.Status.Conditions, conditionLibraryPanelSynchronized, err, r.Cfg.requeueAfter(libraryPanel.Spec.ResyncPeriod, libraryPanel.Spec.ResyncJitterPercent), func() error {
+				return r.finalize(ctx, libraryPanel)
+			})
+		}
+
+		if content.IsRenderError(err) {
+			setInvalidSpec(&libraryPanel.Status.Conditions, libraryPanel.Generation, conditionReasonJsonnetRenderFailed, err.Error())
+			meta.RemoveStatusCondition(&libraryPanel.Status.Conditions, conditionLibraryPanelSynchronized)
+
+			return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(libraryPanel.Spec.ResyncPeriod, libraryPanel.Spec.ResyncJitterPercent)}, nil
+		}
+
 		setInvalidSpec(&libraryPanel.Status.Conditions, libraryPanel.Generation, "InvalidModelResolution", err.Error())
 		meta.RemoveStatusCondition(&libraryPanel.Status.Conditions, conditionLibraryPanelSynchronized)
 
 		return ctrl.Result{}, fmt.Errorf("error resolving library panel contents: %w", err)
 	}
 
+	meta.RemoveStatusCondition(&libraryPanel.Status.Conditions, conditionSourceMissing)
+	setContentStale(ctx, &libraryPanel.Status.Conditions, libraryPanel.Generation, libraryPanel, resolver.FetchError())
+	checkGrafanaComRevision(ctx, r.Cfg, r.Recorder, libraryPanel, &libraryPanel.Status.Conditions, libraryPanel.Generation)
+
 	contentUID := fmt.Sprintf("%s", contentModel["uid"])
 	// it can happen that the user does not utilize `.spec.uid` but updates
 	// the UID within the content model itself. this will create a conflict b/c
@@ -142,10 +176,23 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 		setNoMatchingInstancesCondition(&libraryPanel.Status.Conditions, libraryPanel.Generation, err)
 		meta.RemoveStatusCondition(&libraryPanel.Status.Conditions, conditionLibraryPanelSynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, libraryPanel)
 	}
 
 	removeNoMatchingInstance(&libraryPanel.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, libraryPanel, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&libraryPanel.Status.Conditions, libraryPanel.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for library panel", "count", len(instances))
 
 	folderUID, err := getFolderUID(ctx, r.Client, libraryPanel)
@@ -153,12 +200,12 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, fmt.Errorf(ErrFetchingFolder, err)
 	}
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		err := r.reconcileWithInstance(ctx, &grafana, libraryPanel, contentModel, hash, folderUID)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -169,7 +216,7 @@ func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(libraryPanel.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(libraryPanel.Spec.ResyncPeriod, libraryPanel.Spec.ResyncJitterPercent), pendingWaves)}, nil
 }
 
 func (r *GrafanaLibraryPanelReconciler) reconcileWithInstance(ctx context.Context, instance *v1beta1.Grafana, cr *v1beta1.GrafanaLibraryPanel, model map[string]any, hash, folderUID string) error {
@@ -238,6 +285,9 @@ func (r *GrafanaLibraryPanelReconciler) finalize(ctx context.Context, cr *v1beta
 
 	uid := content.CustomUIDOrUID(cr, cr.Status.UID)
 
+	forgetContentStale(cr)
+	forgetGrafanaComRevision(cr)
+
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, cr)
 	if err != nil {
 		return fmt.Errorf("fetching instances: %w", err)
@@ -305,13 +355,32 @@ func (r *GrafanaLibraryPanelReconciler) SetupWithManager(ctx context.Context, mg
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
+	// Index the library panels by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaLibraryPanel{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
+	// Index the library panels importing jsonnet libraries from ConfigMaps by their namespace.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaLibraryPanel{}, jsonnetLibIndexKey, indexJsonnetLibSelector); err != nil {
+		return fmt.Errorf("failed setting jsonnet library index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.GrafanaLibraryPanel{}, builder.WithPredicates(
 			ignoreStatusUpdates(),
 		)).
 		Watches(
 			&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.requestsForChangeByField(configMapIndexKey)),
+			enqueueCoalesced(r.requestsForChangeByField(configMapIndexKey), r.Cfg.SyncWindow),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			enqueueCoalesced(requestsForJsonnetLib(r.Client, func() client.ObjectList { return &v1beta1.GrafanaLibraryPanelList{} }), r.Cfg.SyncWindow),
+		).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &v1beta1.GrafanaLibraryPanelList{} })),
+			builder.WithPredicates(instanceRecovered()),
 		).
 		Complete(r)
 }
@@ -0,0 +1,234 @@
+package logging
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces secure values in logs and error messages
+const Redacted = "[REDACTED]"
+
+const (
+	// minSecretLength is the length of the shortest registered secret replaced in messages, shorter values would
+	// replace common words
+	minSecretLength = 6
+	// maxSecrets bounds the registered secrets, the oldest ones are forgotten first
+	maxSecrets = 4096
+)
+
+// sensitiveKeys are the normalized keys, lowercase without separators, whose values are always redacted
+var sensitiveKeys = map[string]bool{
+	"password":        true,
+	"adminpassword":   true,
+	"token":           true,
+	"accesstoken":     true,
+	"bearertoken":     true,
+	"sessiontoken":    true,
+	"sastoken":        true,
+	"apikey":          true,
+	"authorization":   true,
+	"clientsecret":    true,
+	"secretaccesskey": true,
+	"securejsondata":  true,
+	"tlsclientkey":    true,
+}
+
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// redactions replace secure values in free text, e.g. error messages with request or response bodies
+var redactions = []redaction{
+	// secureJsonData objects and maps, their values are all secure
+	{regexp.MustCompile(`(?i)("?securejsondata"?\s*[:=]?\s*)(\{[^{}]*\}|map\[[^\]]*\])`), `${1}"` + Redacted + `"`},
+	// JSON, YAML, logfmt and query string values of sensitive keys, values already redacted start with [
+	{regexp.MustCompile(`(?i)("?\b(?:admin_?password|password|passwd|(?:access_|bearer_|session_|sas_)?token|api_?key|client_?secret|secret_?access_?key)"?\s*[:=]\s*"?)([^\s"&,;}\[\]][^\s"&,;}\]]*)`), `${1}` + Redacted},
+	// Authorization header values, tokens contain at least one digit or symbol unlike words following Bearer or Basic
+	{regexp.MustCompile(`\b(Bearer|Basic) [A-Za-z0-9\-._~+/]*[0-9\-._~+/][A-Za-z0-9\-._~+/]*=*`), `${1} ` + Redacted},
+	// Credentials in URLs
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), `${1}` + Redacted + `@`},
+	// Signatures of pre-signed and SAS URLs
+	{regexp.MustCompile(`(?i)([?&](?:sig|x-amz-signature|x-amz-security-token)=)[^&\s"]+`), `${1}` + Redacted},
+	// Grafana service account tokens and JWTs
+	{regexp.MustCompile(`\bglsa_[A-Za-z0-9_]+`), Redacted},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}(\.[A-Za-z0-9_-]*){0,2}`), Redacted},
+}
+
+var secrets = &secretSet{values: map[string]int{}}
+
+// secretSet holds the secure values read by the operator, e.g. from Secrets
+type secretSet struct {
+	mu     sync.Mutex
+	values map[string]int
+	next   int
+
+	replacer atomic.Pointer[strings.Replacer]
+}
+
+// RegisterSecret makes Redact replace value wherever it appears, e.g. a password read from a Secret
+func RegisterSecret(value string) {
+	secrets.register(value)
+}
+
+func (s *secretSet) register(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[value]; ok {
+		return
+	}
+
+	if len(s.values) >= maxSecrets {
+		oldest := ""
+		for v, n := range s.values {
+			if oldest == "" || n < s.values[oldest] {
+				oldest = v
+			}
+		}
+
+		delete(s.values, oldest)
+	}
+
+	s.values[value] = s.next
+	s.next++
+
+	// Longer secrets first, so that secrets containing others are replaced as a whole
+	values := make([]string, 0, len(s.values))
+	for v := range s.values {
+		values = append(values, v)
+	}
+
+	slices.SortFunc(values, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+
+	oldnew := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		oldnew = append(oldnew, v, Redacted)
+	}
+
+	s.replacer.Store(strings.NewReplacer(oldnew...))
+}
+
+// Redact replaces the registered secrets and the values of known secure fields in s
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+
+	if r := secrets.replacer.Load(); r != nil {
+		s = r.Replace(s)
+	}
+
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+
+	return s
+}
+
+// RedactError returns err with a redacted message, it still wraps err
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+
+	redacted := Redact(msg)
+	if redacted == msg {
+		return err
+	}
+
+	return &redactedError{err: err, msg: redacted}
+}
+
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	return sensitiveKeys[key]
+}
+
+func redactKeysAndValues(keysAndValues []any) []any {
+	if len(keysAndValues) == 0 {
+		return keysAndValues
+	}
+
+	redacted := make([]any, len(keysAndValues))
+
+	for i, v := range keysAndValues {
+		if i%2 == 1 {
+			if key, ok := keysAndValues[i-1].(string); ok && sensitiveKey(key) {
+				redacted[i] = Redacted
+				continue
+			}
+		}
+
+		redacted[i] = redactValue(v)
+	}
+
+	return redacted
+}
+
+// redactValue redacts strings, errors and the JSON of structured values, other values are returned unchanged
+func redactValue(v any) any {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return Redact(value)
+	case []byte:
+		if s := string(value); Redact(s) != s {
+			return Redact(s)
+		}
+
+		return value
+	case error:
+		return RedactError(value)
+	case fmt.Stringer:
+		if s := value.String(); Redact(s) != s {
+			return Redact(s)
+		}
+
+		return value
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() { //nolint:exhaustive
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+
+		if s := string(raw); Redact(s) != s {
+			return Redact(s)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,130 @@
+// Package logging adjusts the verbosity of the operator's loggers at runtime and redacts secure values from all logs
+package logging
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Verbosity levels of the GrafanaOperatorConfig logging levels
+const (
+	// VerbosityError only logs errors
+	VerbosityError = -1
+	// VerbosityInfo logs errors and info messages
+	VerbosityInfo = 0
+	// VerbosityDebug also logs debug messages written with V(1)
+	VerbosityDebug = 1
+)
+
+// controllerKey is the key controller-runtime adds the controller name with to the loggers of reconciles
+const controllerKey = "controller"
+
+// Levels overrides the verbosity of loggers
+type Levels struct {
+	// Default applies to all loggers without a controller override, nil keeps the level of the --zap-log-level flag
+	Default *int
+	// Controllers overrides the verbosity of the loggers of controllers by lowercase controller name, e.g. grafanadashboard
+	Controllers map[string]int
+}
+
+var levels atomic.Pointer[Levels]
+
+// SetLevels replaces the verbosity overrides of all loggers created by New
+func SetLevels(l Levels) {
+	levels.Store(&l)
+}
+
+// verbosity returns the verbosity override of the loggers of controller
+func verbosity(controller string) (int, bool) {
+	l := levels.Load()
+	if l == nil {
+		return 0, false
+	}
+
+	if v, ok := l.Controllers[controller]; ok {
+		return v, true
+	}
+
+	if l.Default != nil {
+		return *l.Default, true
+	}
+
+	return 0, false
+}
+
+// New wraps logger, whose own level must enable every verbosity, with the runtime verbosity overrides of SetLevels
+// and the redaction of secure values. Loggers without override log at base, nil logs at info
+func New(logger logr.Logger, base zapcore.LevelEnabler) logr.Logger {
+	if base == nil {
+		base = zapcore.InfoLevel
+	}
+
+	inner := logger.GetSink()
+	if withCallDepth, ok := inner.(logr.CallDepthLogSink); ok {
+		// Skip the frame of the wrapping sink
+		inner = withCallDepth.WithCallDepth(1)
+	}
+
+	return logr.New(&sink{sink: inner, base: base})
+}
+
+// sink filters the messages of the wrapped sink by the verbosity of its controller and redacts their values
+type sink struct {
+	sink       logr.LogSink
+	base       zapcore.LevelEnabler
+	controller string
+}
+
+var (
+	_ logr.LogSink          = (*sink)(nil)
+	_ logr.CallDepthLogSink = (*sink)(nil)
+)
+
+// Init does nothing, the wrapped sink was initialized by its own logger
+func (s *sink) Init(logr.RuntimeInfo) {}
+
+func (s *sink) Enabled(level int) bool {
+	if v, ok := verbosity(s.controller); ok {
+		return level <= v
+	}
+
+	return s.base.Enabled(zapcore.Level(-level))
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.sink.Info(level, Redact(msg), redactKeysAndValues(keysAndValues)...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	s.sink.Error(RedactError(err), Redact(msg), redactKeysAndValues(keysAndValues)...)
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{sink: s.sink.WithName(name), base: s.base, controller: s.controller}
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	controller := s.controller
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok && key == controllerKey {
+			if name, ok := keysAndValues[i+1].(string); ok {
+				controller = strings.ToLower(name)
+			}
+		}
+	}
+
+	return &sink{sink: s.sink.WithValues(redactKeysAndValues(keysAndValues)...), base: s.base, controller: controller}
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	inner := s.sink
+	if withCallDepth, ok := inner.(logr.CallDepthLogSink); ok {
+		inner = withCallDepth.WithCallDepth(depth)
+	}
+
+	return &sink{sink: inner, base: s.base, controller: s.controller}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/utils/ptr"
+)
+
+func newTestLogger(t *testing.T, base zapcore.LevelEnabler) (logr.Logger, *[]string) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		levels.Store(nil)
+	})
+
+	var lines []string
+
+	inner := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 10})
+
+	return New(inner, base), &lines
+}
+
+func TestVerbosity(t *testing.T) {
+	logger, lines := newTestLogger(t, zapcore.InfoLevel)
+
+	dashboards := logger.WithValues("controller", "grafanadashboard", "namespace", "default")
+	folders := logger.WithValues("controller", "grafanafolder")
+
+	// The base level applies without overrides
+	assert.True(t, dashboards.Enabled())
+	assert.False(t, dashboards.V(1).Enabled())
+
+	SetLevels(Levels{Default: ptr.To(VerbosityError), Controllers: map[string]int{"grafanadashboard": VerbosityDebug}})
+
+	assert.True(t, dashboards.V(1).Enabled())
+	assert.False(t, dashboards.V(2).Enabled())
+	assert.False(t, folders.Enabled())
+	assert.False(t, logger.Enabled())
+
+	// Loggers derived from a controller logger keep its level
+	assert.True(t, dashboards.WithName("GrafanaDashboardReconciler").V(1).Enabled())
+
+	folders.Info("hidden")
+	folders.Error(errors.New("failed"), "shown")
+	dashboards.V(1).Info("debug")
+
+	require.Len(t, *lines, 2)
+	assert.Contains(t, (*lines)[0], `"msg"="shown"`)
+	assert.Contains(t, (*lines)[1], `"msg"="debug"`)
+
+	SetLevels(Levels{})
+
+	assert.True(t, folders.Enabled())
+	assert.False(t, dashboards.V(1).Enabled())
+}
+
+func TestNewDefaultsToInfo(t *testing.T) {
+	logger, _ := newTestLogger(t, nil)
+
+	assert.True(t, logger.Enabled())
+	assert.False(t, logger.V(1).Enabled())
+}
+
+func TestSinkRedacts(t *testing.T) {
+	logger, lines := newTestLogger(t, zapcore.InfoLevel)
+
+	logger.WithValues("apiKey", "glsa_abc").Info("connecting with Bearer abc.def-123", "password", "hunter2", "user", "admin")
+	logger.Error(errors.New(`[PUT /datasources][400] {"secureJsonData":{"password":"hunter2"}}`), "applying datasource",
+		"datasource", map[string]any{"name": "prometheus", "secureJsonData": map[string]string{"httpHeaderValue1": "abc"}})
+
+	require.Len(t, *lines, 2)
+
+	for _, line := range *lines {
+		assert.NotContains(t, line, "hunter2")
+		assert.NotContains(t, line, "abc")
+	}
+
+	assert.Contains(t, (*lines)[0], `"user"="admin"`)
+	assert.Contains(t, (*lines)[0], `Bearer [REDACTED]`)
+	assert.Contains(t, (*lines)[1], `prometheus`)
+}
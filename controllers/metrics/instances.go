@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherInstances is the instance_name label of per instance series aggregating the instances above the label limit
+const OtherInstances = "_other"
+
+var instanceLabels = &boundedInstanceLabels{
+	seen: make(map[instanceKey]bool),
+}
+
+type instanceKey struct {
+	namespace string
+	name      string
+}
+
+// boundedInstanceLabels keeps per instance metrics bounded by aggregating the instances seen after the first limit ones
+type boundedInstanceLabels struct {
+	mu    sync.Mutex
+	limit int
+	seen  map[instanceKey]bool
+}
+
+// SetInstanceLabelLimit sets how many Grafana instances get their own series in the bounded per instance metrics,
+// further instances are aggregated under OtherInstances. 0 disables the limit
+func SetInstanceLabelLimit(limit int) {
+	instanceLabels.mu.Lock()
+	defer instanceLabels.mu.Unlock()
+
+	instanceLabels.limit = max(limit, 0)
+}
+
+// InstanceLabels returns the instance_namespace and instance_name labels to use for a Grafana instance
+func InstanceLabels(namespace, name string) prometheus.Labels {
+	key := instanceKey{namespace: namespace, name: name}
+
+	instanceLabels.mu.Lock()
+	defer instanceLabels.mu.Unlock()
+
+	if !instanceLabels.seen[key] {
+		if instanceLabels.limit > 0 && len(instanceLabels.seen) >= instanceLabels.limit {
+			return prometheus.Labels{"instance_namespace": "", "instance_name": OtherInstances}
+		}
+
+		instanceLabels.seen[key] = true
+	}
+
+	return prometheus.Labels{"instance_namespace": namespace, "instance_name": name}
+}
+
+// ForgetInstance deletes the per instance series of a deleted Grafana instance and frees its slot in the bounded metrics
+func ForgetInstance(namespace, name string) {
+	instanceLabels.mu.Lock()
+	delete(instanceLabels.seen, instanceKey{namespace: namespace, name: name})
+	instanceLabels.mu.Unlock()
+
+	labels := prometheus.Labels{"instance_namespace": namespace, "instance_name": name}
+	ManagedObjects.DeletePartialMatch(labels)
+	GrafanaInstanceReady.Delete(labels)
+	GrafanaAPIRequestDuration.DeletePartialMatch(labels)
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceLabels(t *testing.T) {
+	SetInstanceLabelLimit(2)
+	defer SetInstanceLabelLimit(0)
+
+	defer func() {
+		for _, name := range []string{"a", "b", "c"} {
+			ForgetInstance("default", name)
+		}
+	}()
+
+	assert.Equal(t, prometheus.Labels{"instance_namespace": "default", "instance_name": "a"}, InstanceLabels("default", "a"))
+	assert.Equal(t, prometheus.Labels{"instance_namespace": "default", "instance_name": "b"}, InstanceLabels("default", "b"))
+	assert.Equal(t, prometheus.Labels{"instance_namespace": "", "instance_name": OtherInstances}, InstanceLabels("default", "c"))
+
+	// Instances seen before keep their series
+	assert.Equal(t, prometheus.Labels{"instance_namespace": "default", "instance_name": "a"}, InstanceLabels("default", "a"))
+
+	// Deleted instances free their slot
+	ForgetInstance("default", "a")
+	assert.Equal(t, prometheus.Labels{"instance_namespace": "default", "instance_name": "c"}, InstanceLabels("default", "c"))
+}
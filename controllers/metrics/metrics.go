@@ -20,6 +20,20 @@ var (
 		Help:      "failed reconciles per Grafana instance and stage",
 	}, []string{"instance_namespace", "instance_name", "stage"})
 
+	GrafanaInstanceReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "reconciler",
+		Name:      "instance_ready",
+		Help:      "whether the last reconcile of the Grafana instance completed all stages, content is only applied to ready instances",
+	}, []string{"instance_namespace", "instance_name"})
+
+	ApplyErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "reconciler",
+		Name:      "apply_errors",
+		Help:      "resources failing to be applied to a Grafana instance per synchronized condition and reason",
+	}, []string{"condition", "reason"})
+
 	GrafanaAPIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "grafana_operator",
 		Subsystem: "grafana_api",
@@ -27,6 +41,23 @@ var (
 		Help:      "requests against the grafana api per instance",
 	}, []string{"instance_namespace", "instance_name", "method", "status"})
 
+	// Bounded per instance, see InstanceLabels
+	GrafanaAPIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "grafana_api",
+		Name:      "request_duration_seconds",
+		Help:      "duration of requests against the grafana api per instance",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"instance_namespace", "instance_name", "method"})
+
+	// Bounded per instance, see InstanceLabels
+	ManagedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "reconciler",
+		Name:      "managed_objects",
+		Help:      "resources applied per Grafana instance and kind",
+	}, []string{"instance_namespace", "instance_name", "kind"})
+
 	// Deprecated: will be removed in a future version of the operator. Use
 	// ContentURLRequests instead, which handles more types of resources that
 	// directly utilize Grafana model JSON.
@@ -37,6 +68,13 @@ var (
 		Help:      "requests to fetch dashboards from urls",
 	}, []string{"dashboard", "method", "status"})
 
+	DashboardDeduplicatedApplies = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "dashboards",
+		Name:      "deduplicated_applies",
+		Help:      "dashboard applies skipped per instance as the identical model was applied within the dedup window",
+	}, []string{"instance_namespace", "instance_name"})
+
 	ContentURLRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "grafana_operator",
 		Subsystem: "content",
@@ -44,12 +82,40 @@ var (
 		Help:      "requests to fetch model contents from urls",
 	}, []string{"kind", "resource", "method", "status"})
 
+	ContentStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "content",
+		Name:      "stale",
+		Help:      "whether the last known good model is applied because the source is unreachable for longer than the stale threshold",
+	}, []string{"kind", "resource"})
+
+	CRDStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "crd",
+		Name:      "stale",
+		Help:      "whether the installed CRD is missing versions or fields the operator expects, checked on startup",
+	}, []string{"crd"})
+
 	GrafanaComAPIRevisionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "grafana_operator",
 		Name:      "revision_requests",
 		Help:      "requests to list content revisions on grafana.com",
 	}, []string{"kind", "resource", "method", "status"})
 
+	GrafanaComUpdateAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "content",
+		Name:      "grafana_com_update_available",
+		Help:      "whether grafana.com publishes a newer revision than the one the content is pinned to",
+	}, []string{"kind", "resource"})
+
+	DanglingReferences = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "integrity",
+		Name:      "dangling_references",
+		Help:      "resources referencing folders, datasources, contact points or mute timings which do not exist",
+	}, []string{"kind"})
+
 	InitialStatusSyncDuration = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "grafana_operator",
 		Subsystem: "reconciler",
@@ -101,10 +167,19 @@ var (
 func init() {
 	metrics.Registry.MustRegister(GrafanaReconciles)
 	metrics.Registry.MustRegister(GrafanaFailedReconciles)
+	metrics.Registry.MustRegister(GrafanaInstanceReady)
+	metrics.Registry.MustRegister(ApplyErrors)
 	metrics.Registry.MustRegister(GrafanaAPIRequests)
+	metrics.Registry.MustRegister(GrafanaAPIRequestDuration)
+	metrics.Registry.MustRegister(ManagedObjects)
 	metrics.Registry.MustRegister(GrafanaComAPIRevisionRequests)
 	metrics.Registry.MustRegister(DashboardURLRequests)
 	metrics.Registry.MustRegister(ContentURLRequests)
+	metrics.Registry.MustRegister(DashboardDeduplicatedApplies)
+	metrics.Registry.MustRegister(ContentStale)
+	metrics.Registry.MustRegister(GrafanaComUpdateAvailable)
+	metrics.Registry.MustRegister(CRDStale)
+	metrics.Registry.MustRegister(DanglingReferences)
 	metrics.Registry.MustRegister(InitialStatusSyncDuration)
 	// TODO Remvoe below registrations
 	metrics.Registry.MustRegister(InitialContactPointSyncDuration)
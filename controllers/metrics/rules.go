@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+
+	// AlertRuleGroup is the name of the rule group with AlertRules
+	AlertRuleGroup = "grafana-operator"
+)
+
+// AlertRule is a Prometheus alerting rule on the metrics of the operator
+type AlertRule struct {
+	Alert string
+	Expr  string
+	// For is rounded to minutes
+	For         time.Duration
+	Severity    string
+	Summary     string
+	Description string
+}
+
+// AlertRules alert on the health of the operator itself, they are shipped as PrometheusRule in config/prometheus and the
+// helm chart, or maintained by the operator with --prometheus-rule-namespace
+var AlertRules = []AlertRule{
+	{
+		Alert: "GrafanaOperatorReconcileErrors",
+		Expr: `sum by (controller) (rate(controller_runtime_reconcile_errors_total{controller=~"grafana.*"}[10m]))
+  / sum by (controller) (rate(controller_runtime_reconcile_total{controller=~"grafana.*"}[10m])) > 0.1`,
+		For:         15 * time.Minute,
+		Severity:    SeverityWarning,
+		Summary:     "Grafana operator fails to sync resources",
+		Description: "{{ $value | humanizePercentage }} of the reconciles of the {{ $labels.controller }} controller fail, the conditions of the resources name the errors.",
+	},
+	{
+		Alert:       "GrafanaOperatorInstanceUnreachable",
+		Expr:        `grafana_operator_reconciler_instance_ready == 0`,
+		For:         15 * time.Minute,
+		Severity:    SeverityCritical,
+		Summary:     "Grafana instance not ready",
+		Description: "The Grafana instance {{ $labels.instance_namespace }}/{{ $labels.instance_name }} can't be reconciled or reached, dashboards, datasources and other content are not applied to it.",
+	},
+	{
+		Alert: "GrafanaOperatorGrafanaAPIErrors",
+		Expr: `sum by (instance_namespace, instance_name) (rate(grafana_operator_grafana_api_requests{status=~"5.."}[10m]))
+  / sum by (instance_namespace, instance_name) (rate(grafana_operator_grafana_api_requests[10m])) > 0.25`,
+		For:         15 * time.Minute,
+		Severity:    SeverityWarning,
+		Summary:     "Grafana API requests of the operator fail",
+		Description: "{{ $value | humanizePercentage }} of the requests to the Grafana instance {{ $labels.instance_namespace }}/{{ $labels.instance_name }} fail with server errors.",
+	},
+	{
+		Alert:       "GrafanaOperatorStaleContent",
+		Expr:        `max by (kind, resource) (grafana_operator_content_stale) == 1`,
+		For:         5 * time.Minute,
+		Severity:    SeverityWarning,
+		Summary:     "Content source of a Grafana resource unreachable",
+		Description: "The source of the {{ $labels.kind }} {{ $labels.resource }} is unreachable for longer than its stale threshold, the last known good content is applied.",
+	},
+	{
+		Alert:       "GrafanaOperatorStaleCRDs",
+		Expr:        `max by (crd) (grafana_operator_crd_stale) == 1`,
+		Severity:    SeverityWarning,
+		Summary:     "Grafana operator CRDs outdated",
+		Description: "The installed {{ $labels.crd }} CRD lacks versions or fields the operator expects, upgrade the CRDs with the operator.",
+	},
+}
+
+// PrometheusRuleGroups returns AlertRules as spec.groups of a PrometheusRule
+func PrometheusRuleGroups() []any {
+	rules := make([]any, 0, len(AlertRules))
+
+	for _, r := range AlertRules {
+		rule := map[string]any{
+			"alert": r.Alert,
+			"expr":  r.Expr,
+			"labels": map[string]any{
+				"severity": r.Severity,
+			},
+			"annotations": map[string]any{
+				"summary":     r.Summary,
+				"description": r.Description,
+			},
+		}
+
+		if r.For > 0 {
+			rule["for"] = fmt.Sprintf("%dm", int(r.For.Minutes()))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return []any{
+		map[string]any{
+			"name":  AlertRuleGroup,
+			"rules": rules,
+		},
+	}
+}
+
+// PrometheusRuleManifest renders a PrometheusRule with AlertRules, as shipped in config/prometheus
+func PrometheusRuleManifest(namespace, name string, labels map[string]string) ([]byte, error) {
+	metadata := map[string]any{
+		"name":      name,
+		"namespace": namespace,
+	}
+
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+
+	b, err := yaml.Marshal(map[string]any{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata":   metadata,
+		"spec": map[string]any{
+			"groups": PrometheusRuleGroups(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rendering PrometheusRule: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestAlertRules(t *testing.T) {
+	alerts := map[string]bool{}
+
+	for _, r := range AlertRules {
+		assert.False(t, alerts[r.Alert], "duplicate alert %s", r.Alert)
+		alerts[r.Alert] = true
+
+		assert.Contains(t, []string{SeverityWarning, SeverityCritical}, r.Severity, r.Alert)
+		assert.NotEmpty(t, r.Summary, r.Alert)
+		assert.NotEmpty(t, r.Description, r.Alert)
+	}
+}
+
+// The shipped manifests are generated with make prometheus-rules
+func TestPrometheusRuleManifestsUpToDate(t *testing.T) {
+	manifest, err := os.ReadFile("../../config/prometheus/rules.yaml")
+	require.NoError(t, err)
+
+	var rule map[string]any
+	require.NoError(t, yaml.Unmarshal(manifest, &rule))
+
+	spec, _ := rule["spec"].(map[string]any)
+	assert.Equal(t, normalize(t, PrometheusRuleGroups()), spec["groups"], "config/prometheus/rules.yaml is outdated, run make prometheus-rules")
+
+	chart, err := os.ReadFile("../../deploy/helm/grafana-operator/files/prometheus-rules.yaml")
+	require.NoError(t, err)
+
+	var groups []any
+	require.NoError(t, yaml.Unmarshal(chart, &groups))
+	assert.Equal(t, normalize(t, PrometheusRuleGroups()), groups, "the PrometheusRule of the helm chart is outdated, run make prometheus-rules")
+}
+
+func normalize(t *testing.T, v any) any {
+	t.Helper()
+
+	b, err := yaml.Marshal(v)
+	require.NoError(t, err)
+
+	var out any
+	require.NoError(t, yaml.Unmarshal(b, &out))
+
+	return out
+}
@@ -1,17 +1,27 @@
 package model
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"path"
+	"strings"
 
 	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
 	routev1 "github.com/openshift/api/route/v1"
 	v13 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	v12 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	v2 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 func GetCommonLabels() map[string]string {
@@ -33,6 +43,51 @@ func GetGrafanaConfigMap(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1
 	return config
 }
 
+// GetGrafanaInfoConfigMap returns the ConfigMap exposing the computed endpoint and metadata of the instance to other workloads
+func GetGrafanaInfoConfigMap(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.ConfigMap {
+	config := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-grafana-info", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, config, scheme) //nolint:errcheck
+	}
+
+	return config
+}
+
+// GetGrafanaServingCertSecret returns the Secret the OpenShift service CA stores the certificate of the service in.
+// The Secret is owned by the service CA
+func GetGrafanaServingCertSecret(cr *grafanav1beta1.Grafana) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-serving-cert", cr.Name),
+			Namespace: cr.Namespace,
+		},
+	}
+}
+
+// GetGrafanaServiceCAConfigMap returns the ConfigMap the OpenShift service CA injects its CA bundle into
+func GetGrafanaServiceCAConfigMap(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.ConfigMap {
+	config := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-service-ca", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, config, scheme) //nolint:errcheck
+	}
+
+	return config
+}
+
 func GetGrafanaAdminSecret(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.Secret {
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -84,7 +139,9 @@ func GetGrafanaService(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.S
 			Labels:    GetCommonLabels(),
 		},
 	}
-	controllerutil.SetControllerReference(cr, service, scheme) //nolint:errcheck
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, service, scheme) //nolint:errcheck
+	}
 
 	return service
 }
@@ -128,6 +185,45 @@ func GetGrafanaHTTPRoute(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v2
 	return httpRoute
 }
 
+func GetGrafanaTLSRoute(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *gwapiv1alpha2.TLSRoute {
+	tlsRoute := &gwapiv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-tlsroute", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+	controllerutil.SetControllerReference(cr, tlsRoute, scheme) //nolint:errcheck
+
+	return tlsRoute
+}
+
+func GetGrafanaReferenceGrant(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *gwapiv1beta1.ReferenceGrant {
+	referenceGrant := &gwapiv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-referencegrant", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+	controllerutil.SetControllerReference(cr, referenceGrant, scheme) //nolint:errcheck
+
+	return referenceGrant
+}
+
+func GetGrafanaGRPCRoute(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v2.GRPCRoute {
+	grpcRoute := &v2.GRPCRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-grpcroute", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+	controllerutil.SetControllerReference(cr, grpcRoute, scheme) //nolint:errcheck
+
+	return grpcRoute
+}
+
 func GetGrafanaRoute(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *routev1.Route {
 	route := &routev1.Route{
 		ObjectMeta: metav1.ObjectMeta{
@@ -141,6 +237,127 @@ func GetGrafanaRoute(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *routev
 	return route
 }
 
+// GetGrafanaPodDisruptionBudget returns the PodDisruptionBudget protecting the replicas of the deployment
+func GetGrafanaPodDisruptionBudget(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-pdb", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, pdb, scheme) //nolint:errcheck
+	}
+
+	return pdb
+}
+
+// GetGrafanaNetworkPolicy returns the NetworkPolicy restricting the traffic of the Grafana pods
+func GetGrafanaNetworkPolicy(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v12.NetworkPolicy {
+	policy := &v12.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-network-policy", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, policy, scheme) //nolint:errcheck
+	}
+
+	return policy
+}
+
+// GetGrafanaHorizontalPodAutoscaler returns the HorizontalPodAutoscaler scaling the deployment
+func GetGrafanaHorizontalPodAutoscaler(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-hpa", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, hpa, scheme) //nolint:errcheck
+	}
+
+	return hpa
+}
+
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	ScaledObjectGVK   = schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"}
+)
+
+// GetGrafanaMonitor returns the prometheus-operator ServiceMonitor or PodMonitor of the instance, depending on gvk
+func GetGrafanaMonitor(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(gvk)
+	monitor.SetName(fmt.Sprintf("%s-monitor", cr.Name))
+	monitor.SetNamespace(cr.Namespace)
+	monitor.SetLabels(GetCommonLabels())
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, monitor, scheme) //nolint:errcheck
+	}
+
+	return monitor
+}
+
+// GetGrafanaImageRendererDeployment returns the Deployment running the image renderer in Remote mode
+func GetGrafanaImageRendererDeployment(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v13.Deployment {
+	deployment := &v13.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-image-renderer", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, deployment, scheme) //nolint:errcheck
+	}
+
+	return deployment
+}
+
+// GetGrafanaImageRendererScaledObject returns the KEDA ScaledObject scaling the image renderer deployment
+func GetGrafanaImageRendererScaledObject(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *unstructured.Unstructured {
+	scaledObject := &unstructured.Unstructured{}
+	scaledObject.SetGroupVersionKind(ScaledObjectGVK)
+	scaledObject.SetName(fmt.Sprintf("%s-image-renderer", cr.Name))
+	scaledObject.SetNamespace(cr.Namespace)
+	scaledObject.SetLabels(GetCommonLabels())
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, scaledObject, scheme) //nolint:errcheck
+	}
+
+	return scaledObject
+}
+
+// GetGrafanaImageRendererService returns the Service Grafana reaches the image renderer through in Remote mode
+func GetGrafanaImageRendererService(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.Service {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-image-renderer", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, service, scheme) //nolint:errcheck
+	}
+
+	return service
+}
+
 func GetGrafanaDeployment(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v13.Deployment {
 	deployment := &v13.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -155,3 +372,37 @@ func GetGrafanaDeployment(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v
 
 	return deployment
 }
+
+func GetGrafanaStatefulSet(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v13.StatefulSet {
+	statefulSet := &v13.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-statefulset", cr.Name),
+			Namespace: cr.Namespace,
+			Labels:    GetCommonLabels(),
+		},
+	}
+	if scheme != nil {
+		controllerutil.SetControllerReference(cr, statefulSet, scheme) //nolint:errcheck
+	}
+
+	return statefulSet
+}
+
+// GetDatasourceTLSVolumeName returns the name of the volume holding the TLS Secret of a datasource.
+// Volume names must be valid DNS labels, so dots are replaced and long names truncated. The hash of the datasource
+// name keeps names apart that only differ in the dots or the truncated part
+func GetDatasourceTLSVolumeName(cr *grafanav1beta1.GrafanaDatasource) string {
+	suffix := fmt.Sprintf("%x", sha256.Sum256([]byte(cr.Name)))[:8]
+
+	name := strings.ReplaceAll(fmt.Sprintf("datasource-tls-%s", cr.Name), ".", "-")
+	if len(name) > 63-len(suffix)-1 {
+		name = strings.TrimRight(name[:63-len(suffix)-1], "-")
+	}
+
+	return name + "-" + suffix
+}
+
+// GetDatasourceTLSMountPath returns the directory the TLS Secret of a datasource is mounted at
+func GetDatasourceTLSMountPath(cr *grafanav1beta1.GrafanaDatasource) string {
+	return path.Join(config.SecretsMountDir, "datasources", cr.Name)
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestGetDatasourceTLSVolumeName(t *testing.T) {
+	datasource := func(name string) *grafanav1beta1.GrafanaDatasource {
+		return &grafanav1beta1.GrafanaDatasource{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	long := strings.Repeat("a", 70)
+
+	names := []string{
+		"prometheus",
+		"prometheus.eu",
+		"prometheus-eu",
+		long + "-eu",
+		long + "-us",
+		strings.Repeat("b", 48) + "." + strings.Repeat("c", 10),
+	}
+
+	volumes := map[string]string{}
+
+	for _, name := range names {
+		volume := GetDatasourceTLSVolumeName(datasource(name))
+
+		assert.Empty(t, validation.IsDNS1123Label(volume), "volume name of %s", name)
+		assert.NotContains(t, volumes, volume, "volume name of %s collides with %s", name, volumes[volume])
+
+		volumes[volume] = name
+	}
+
+	assert.Equal(t, GetDatasourceTLSVolumeName(datasource("prometheus")), GetDatasourceTLSVolumeName(datasource("prometheus")))
+	assert.True(t, strings.HasPrefix(GetDatasourceTLSVolumeName(datasource("prometheus.eu")), "datasource-tls-prometheus-eu-"))
+}
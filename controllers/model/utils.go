@@ -4,10 +4,31 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
+	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// OwnershipTeamKey is the label or annotation naming the team owning a resource
+	OwnershipTeamKey = "operator.grafana.com/team"
+	// OwnershipSystemKey is the label or annotation naming the system a resource belongs to
+	OwnershipSystemKey = "operator.grafana.com/system"
+	// Annotations with this prefix are used by the Backstage service catalog
+	backstageAnnotationPrefix = "backstage.io/"
+	// ExternalDNSHostnameKey is the annotation external-dns creates records for
+	ExternalDNSHostnameKey = "external-dns.alpha.kubernetes.io/hostname"
+	// ExternalDNSTTLKey is the annotation setting the TTL of external-dns records
+	ExternalDNSTTLKey = "external-dns.alpha.kubernetes.io/ttl"
+	// dnsAnnotationsKey lists the annotations set by SetDNSAnnotations, they are removed once no longer requested
+	dnsAnnotationsKey = "operator.grafana.com/dns-annotations"
+	// ownershipAnnotationsKey lists the annotations set by SetOwnershipAnnotations, they are removed once no longer requested
+	ownershipAnnotationsKey = "operator.grafana.com/ownership-annotations"
+)
+
 func generateRandomBytes(n int) []byte {
 	b := make([]byte, n)
 
@@ -51,3 +72,75 @@ func SetInheritedLabels(obj metav1.ObjectMetaAccessor, extraLabels map[string]st
 	maps.Copy(labels, GetCommonLabels())
 	meta.SetLabels(labels)
 }
+
+// SetOwnershipAnnotations copies ownership metadata (team, system and backstage.io annotations) of the parent resource
+// and removes the ones it copied before which the parent no longer has
+func SetOwnershipAnnotations(obj metav1.ObjectMetaAccessor, parentAnnotations map[string]string) {
+	requested := make(map[string]string)
+
+	for k, v := range parentAnnotations {
+		if k == OwnershipTeamKey || k == OwnershipSystemKey || strings.HasPrefix(k, backstageAnnotationPrefix) {
+			requested[k] = v
+		}
+	}
+
+	setTrackedAnnotations(obj, requested, ownershipAnnotationsKey)
+}
+
+// GetOwnershipValue returns the value of an ownership key, labels take precedence over annotations
+func GetOwnershipValue(obj metav1.Object, key string) string {
+	if v, ok := obj.GetLabels()[key]; ok {
+		return v
+	}
+
+	return obj.GetAnnotations()[key]
+}
+
+// SetDNSAnnotations adds the external-dns annotations requested by spec.dns and removes the ones it set before which
+// are no longer requested, all of them when dns is nil
+func SetDNSAnnotations(obj metav1.ObjectMetaAccessor, dns *grafanav1beta1.GrafanaDNS) {
+	requested := make(map[string]string)
+	if dns != nil {
+		maps.Copy(requested, dns.Annotations)
+
+		requested[ExternalDNSHostnameKey] = dns.Hostname
+		if dns.TTL != nil {
+			requested[ExternalDNSTTLKey] = strconv.FormatInt(*dns.TTL, 10)
+		}
+	}
+
+	setTrackedAnnotations(obj, requested, dnsAnnotationsKey)
+}
+
+// setTrackedAnnotations sets the requested annotations and lists their keys in the trackingKey annotation, the
+// annotations listed there before which are no longer requested are removed
+func setTrackedAnnotations(obj metav1.ObjectMetaAccessor, requested map[string]string, trackingKey string) {
+	meta := obj.GetObjectMeta()
+
+	annotations := meta.GetAnnotations()
+
+	if previous, ok := annotations[trackingKey]; ok {
+		for _, k := range strings.Split(previous, ",") {
+			if _, ok := requested[k]; !ok {
+				delete(annotations, k)
+			}
+		}
+
+		delete(annotations, trackingKey)
+	}
+
+	if len(requested) == 0 {
+		meta.SetAnnotations(annotations)
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	maps.Copy(annotations, requested)
+
+	annotations[trackingKey] = strings.Join(slices.Sorted(maps.Keys(requested)), ",")
+
+	meta.SetAnnotations(annotations)
+}
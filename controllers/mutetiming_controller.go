@@ -25,8 +25,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
@@ -64,6 +66,15 @@ func (r *GrafanaMuteTimingReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	if muteTiming.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(muteTiming, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, muteTiming)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, muteTiming); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaMuteTiming: %w", err)
 			}
@@ -76,7 +87,7 @@ func (r *GrafanaMuteTimingReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, muteTiming)
+	defer UpdateStatus(ctx, r.Client, muteTiming, muteTiming.DeepCopy())
 
 	if muteTiming.Spec.Suspend {
 		setSuspended(&muteTiming.Status.Conditions, muteTiming.Generation, conditionReasonApplySuspended)
@@ -97,18 +108,31 @@ func (r *GrafanaMuteTimingReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		setNoMatchingInstancesCondition(&muteTiming.Status.Conditions, muteTiming.Generation, err)
 		meta.RemoveStatusCondition(&muteTiming.Status.Conditions, conditionMuteTimingSynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, muteTiming)
 	}
 
 	removeNoMatchingInstance(&muteTiming.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, muteTiming, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&muteTiming.Status.Conditions, muteTiming.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for mute timing", "count", len(instances))
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		err := r.reconcileWithInstance(ctx, &grafana, muteTiming)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -119,7 +143,7 @@ func (r *GrafanaMuteTimingReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(muteTiming.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(muteTiming.Spec.ResyncPeriod, muteTiming.Spec.ResyncJitterPercent), pendingWaves)}, nil
 }
 
 func (r *GrafanaMuteTimingReconciler) reconcileWithInstance(ctx context.Context, instance *grafanav1beta1.Grafana, muteTiming *grafanav1beta1.GrafanaMuteTiming) error {
@@ -242,9 +266,20 @@ func (r *GrafanaMuteTimingReconciler) removeFromInstance(ctx context.Context, in
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *GrafanaMuteTimingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *GrafanaMuteTimingReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Index the mute timings by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &grafanav1beta1.GrafanaMuteTiming{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&grafanav1beta1.GrafanaMuteTiming{}).
-		WithEventFilter(ignoreStatusUpdates()).
+		For(&grafanav1beta1.GrafanaMuteTiming{}, builder.WithPredicates(
+			ignoreStatusUpdates(),
+		)).
+		Watches(
+			&grafanav1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &grafanav1beta1.GrafanaMuteTimingList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
 		Complete(r)
 }
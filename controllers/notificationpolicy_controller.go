@@ -18,9 +18,13 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"slices"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	kuberr "k8s.io/apimachinery/pkg/api/errors"
@@ -30,15 +34,18 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 )
 
 var ErrLoopDetected = errors.New("loop detected")
@@ -50,6 +57,10 @@ const (
 
 	conditionReasonFieldsMutuallyExclusive = "FieldsMutuallyExclusive"
 	conditionReasonLoopDetected            = "LoopDetected"
+
+	notificationPolicyBackupPrefix = "policy-"
+	notificationPolicyBackupSuffix = ".json"
+	notificationPolicyBackupLayout = "20060102T150405Z"
 )
 
 // GrafanaNotificationPolicyReconciler reconciles a GrafanaNotificationPolicy object
@@ -78,6 +89,15 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 	if notificationPolicy.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(notificationPolicy, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, notificationPolicy)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, notificationPolicy); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaNotificationPolicy: %w", err)
 			}
@@ -90,7 +110,7 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, notificationPolicy)
+	defer UpdateStatus(ctx, r.Client, notificationPolicy, notificationPolicy.DeepCopy())
 
 	if notificationPolicy.Spec.Suspend {
 		setSuspended(&notificationPolicy.Status.Conditions, notificationPolicy.Generation, conditionReasonApplySuspended)
@@ -134,6 +154,9 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 
 	meta.RemoveStatusCondition(&notificationPolicy.Status.Conditions, conditionNotificationPolicyLoopDetected)
 
+	// The route now contains all merged routes, preview the routing before applying it
+	notificationPolicy.Status.RoutingPreview = previewRouting(notificationPolicy.Spec.Route, notificationPolicy.Spec.RoutingPreview)
+
 	instances, err := GetScopedMatchingInstances(ctx, r.Client, notificationPolicy)
 	if err != nil {
 		setNoMatchingInstancesCondition(&notificationPolicy.Status.Conditions, notificationPolicy.Generation, err)
@@ -146,13 +169,26 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 		setNoMatchingInstancesCondition(&notificationPolicy.Status.Conditions, notificationPolicy.Generation, err)
 		meta.RemoveStatusCondition(&notificationPolicy.Status.Conditions, conditionNotificationPolicySynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, notificationPolicy)
 	}
 
 	removeNoMatchingInstance(&notificationPolicy.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, notificationPolicy, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&notificationPolicy.Status.Conditions, notificationPolicy.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for notificationPolicy", "count", len(instances))
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		appliedPolicy := grafana.Annotations[annotationAppliedNotificationPolicy]
@@ -163,7 +199,7 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 
 		err := r.reconcileWithInstance(ctx, &grafana, notificationPolicy)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -183,7 +219,7 @@ func (r *GrafanaNotificationPolicyReconciler) Reconcile(ctx context.Context, req
 		log.Error(err, "failed to add merged events to routes")
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(notificationPolicy.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(notificationPolicy.Spec.ResyncPeriod, notificationPolicy.Spec.ResyncJitterPercent), pendingWaves)}, nil
 }
 
 // assembleNotificationPolicyRoutes iterates over all routeSelectors transitively.
@@ -284,6 +320,12 @@ func (r *GrafanaNotificationPolicyReconciler) reconcileWithInstance(ctx context.
 		params.SetXDisableProvenance(&trueRef)
 	}
 
+	if notificationPolicy.Spec.Backup != nil {
+		if err := r.backupPolicyTree(ctx, cl, instance, notificationPolicy); err != nil {
+			return fmt.Errorf("backing up notification policy: %w", err)
+		}
+	}
+
 	if _, err := cl.Provisioning.PutPolicyTree(params); err != nil { //nolint:errcheck
 		return fmt.Errorf("applying notification policy: %w", err)
 	}
@@ -300,6 +342,84 @@ func (r *GrafanaNotificationPolicyReconciler) reconcileWithInstance(ctx context.
 	return nil
 }
 
+// backupPolicyTree stores the policy tree currently applied to the instance as a new revision
+// in the backup ConfigMap of the instance, see NotificationPolicyBackup
+func (r *GrafanaNotificationPolicyReconciler) backupPolicyTree(ctx context.Context, cl *genapi.GrafanaHTTPAPI, instance *v1beta1.Grafana, notificationPolicy *v1beta1.GrafanaNotificationPolicy) error {
+	current, err := cl.Provisioning.GetPolicyTree()
+	if err != nil {
+		return fmt.Errorf("fetching current notification policy: %w", err)
+	}
+
+	revision, err := json.MarshalIndent(current.Payload, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	keep := notificationPolicy.Spec.Backup.Revisions
+	if keep < 1 {
+		keep = 1
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-%s-backup", notificationPolicy.Name, instance.Namespace, instance.Name),
+			Namespace: notificationPolicy.Namespace,
+		},
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		// The operator cache only includes ConfigMaps carrying the common labels
+		if cm.Labels == nil {
+			cm.Labels = make(map[string]string)
+		}
+
+		for k, v := range model.GetCommonLabels() {
+			cm.Labels[k] = v
+		}
+
+		cm.Data = addBackupRevision(cm.Data, string(revision), keep, time.Now())
+
+		// Backups outlive the policy, they are needed most after a policy was deleted by mistake
+		return nil
+	})
+
+	return err
+}
+
+// addBackupRevision adds revision to the backup data unless it matches the latest revision
+// and removes the oldest revisions exceeding keep
+func addBackupRevision(data map[string]string, revision string, keep int, now time.Time) map[string]string {
+	if data == nil {
+		data = make(map[string]string)
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if strings.HasPrefix(k, notificationPolicyBackupPrefix) && strings.HasSuffix(k, notificationPolicyBackupSuffix) {
+			keys = append(keys, k)
+		}
+	}
+
+	// The timestamp layout sorts lexicographically
+	slices.Sort(keys)
+
+	if len(keys) == 0 || data[keys[len(keys)-1]] != revision {
+		key := notificationPolicyBackupPrefix + now.UTC().Format(notificationPolicyBackupLayout) + notificationPolicyBackupSuffix
+		data[key] = revision
+
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for len(keys) > keep {
+		delete(data, keys[0])
+		keys = keys[1:]
+	}
+
+	return data
+}
+
 func (r *GrafanaNotificationPolicyReconciler) finalize(ctx context.Context, notificationPolicy *v1beta1.GrafanaNotificationPolicy) error {
 	log := logf.FromContext(ctx)
 	log.Info("Finalizing GrafanaNotificationPolicy")
@@ -335,9 +455,16 @@ func (r *GrafanaNotificationPolicyReconciler) finalize(ctx context.Context, noti
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *GrafanaNotificationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *GrafanaNotificationPolicyReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Index the notification policies by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &v1beta1.GrafanaNotificationPolicy{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1beta1.GrafanaNotificationPolicy{}).
+		For(&v1beta1.GrafanaNotificationPolicy{}, builder.WithPredicates(
+			ignoreStatusUpdates(),
+		)).
 		Watches(&v1beta1.GrafanaContactPoint{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
 			log := logf.FromContext(ctx).WithName("GrafanaNotificationPolicyReconciler")
 			// resync all notification policies for now. Can be optimized by comparing instance selectors
@@ -356,7 +483,7 @@ func (r *GrafanaNotificationPolicyReconciler) SetupWithManager(mgr ctrl.Manager)
 				}
 			}
 			return requests
-		})).
+		}), builder.WithPredicates(ignoreStatusUpdates())).
 		Watches(&v1beta1.GrafanaNotificationPolicyRoute{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
 			log := logf.FromContext(ctx).WithName("GrafanaNotificationPolicyReconciler")
 			npr, ok := o.(*v1beta1.GrafanaNotificationPolicyRoute)
@@ -403,8 +530,12 @@ func (r *GrafanaNotificationPolicyReconciler) SetupWithManager(mgr ctrl.Manager)
 					})
 			}
 			return requests
-		})).
-		WithEventFilter(ignoreStatusUpdates()).
+		}), builder.WithPredicates(ignoreStatusUpdates())).
+		Watches(
+			&v1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &v1beta1.GrafanaNotificationPolicyList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
 		Complete(r)
 }
 
@@ -476,3 +607,139 @@ func statusDiscoveredRoutes(routes []*v1beta1.GrafanaNotificationPolicyRoute) []
 func setInvalidSpecMutuallyExclusive(conditions *[]metav1.Condition, generation int64) {
 	setInvalidSpec(conditions, generation, conditionReasonFieldsMutuallyExclusive, "RouteSelector and Routes are mutually exclusive")
 }
+
+// previewRouting routes the example alerts through the policy tree the same way the Alertmanager dispatcher does
+func previewRouting(route *v1beta1.Route, alerts []v1beta1.RoutingPreviewAlert) []v1beta1.RoutingPreviewResult {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	results := make([]v1beta1.RoutingPreviewResult, 0, len(alerts))
+
+	for _, alert := range alerts {
+		result := v1beta1.RoutingPreviewResult{
+			Labels: alert.Labels,
+		}
+
+		receivers, _, err := matchRoute(route, alert.Labels, "", true)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		for _, receiver := range receivers {
+			if !slices.Contains(result.Receivers, receiver) {
+				result.Receivers = append(result.Receivers, receiver)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// matchRoute returns the receivers of the deepest matching routes. Sibling routes are evaluated
+// until the first match, unless that route sets continue. Routes inherit the receiver of their parent
+func matchRoute(route *v1beta1.Route, labels map[string]string, parentReceiver string, root bool) ([]string, bool, error) {
+	// The root route matches all alerts
+	if !root {
+		matches, err := routeMatches(route, labels)
+		if err != nil || !matches {
+			return nil, false, err
+		}
+	}
+
+	receiver := route.Receiver
+	if receiver == "" {
+		receiver = parentReceiver
+	}
+
+	receivers := make([]string, 0)
+
+	for _, child := range route.Routes {
+		childReceivers, matched, err := matchRoute(child, labels, receiver, false)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if !matched {
+			continue
+		}
+
+		receivers = append(receivers, childReceivers...)
+
+		if !child.Continue {
+			break
+		}
+	}
+
+	if len(receivers) == 0 {
+		receivers = append(receivers, receiver)
+	}
+
+	return receivers, true, nil
+}
+
+func routeMatches(route *v1beta1.Route, labels map[string]string) (bool, error) {
+	for _, m := range route.Matchers {
+		name := ""
+		if m.Name != nil {
+			name = *m.Name
+		}
+
+		matches, err := matchLabel(labels[name], m.Value, m.IsEqual, m.IsRegex)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	for name, pattern := range route.MatchRe {
+		matches, err := matchLabel(labels[name], pattern, true, true)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	for _, m := range route.ObjectMatchers {
+		if len(m) != 3 { //nolint:mnd
+			return false, fmt.Errorf("invalid object matcher %v, expected [name, operator, value]", m)
+		}
+
+		var equal, regex bool
+
+		switch m[1] {
+		case "=":
+			equal = true
+		case "!=":
+		case "=~":
+			equal, regex = true, true
+		case "!~":
+			regex = true
+		default:
+			return false, fmt.Errorf("invalid operator %q in object matcher %v", m[1], m)
+		}
+
+		matches, err := matchLabel(labels[m[0]], m[2], equal, regex)
+		if err != nil || !matches {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchLabel compares a label value, missing labels have an empty value. Regular expressions are fully anchored
+func matchLabel(value, pattern string, equal, regex bool) (bool, error) {
+	matches := value == pattern
+
+	if regex {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+
+		matches = re.MatchString(value)
+	}
+
+	return matches == equal, nil
+}
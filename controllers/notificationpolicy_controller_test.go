@@ -19,12 +19,18 @@ package controllers
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -43,6 +49,169 @@ func stringP(s string) *string {
 	return &s
 }
 
+func TestPreviewRouting(t *testing.T) {
+	route := &v1beta1.Route{
+		Receiver: "default",
+		Routes: []*v1beta1.Route{
+			{
+				Receiver:       "team-a",
+				ObjectMatchers: models.ObjectMatchers{{"team", "=", "a"}},
+				Continue:       true,
+			},
+			{
+				Receiver: "critical",
+				Matchers: v1beta1.Matchers{{Name: stringP("severity"), Value: "critical|page", IsEqual: true, IsRegex: true}},
+				Routes: []*v1beta1.Route{
+					{
+						// Inherits the critical receiver
+						ObjectMatchers: models.ObjectMatchers{{"env", "!~", "prod.*"}},
+					},
+					{
+						Receiver:       "oncall",
+						ObjectMatchers: models.ObjectMatchers{{"env", "=~", "prod.*"}},
+					},
+				},
+			},
+			{
+				Receiver: "team-b",
+				MatchRe:  models.MatchRegexps{"team": "b"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		labels    map[string]string
+		receivers []string
+		err       string
+	}{
+		{name: "no match uses root receiver", labels: map[string]string{"team": "c"}, receivers: []string{"default"}},
+		{name: "continue evaluates siblings", labels: map[string]string{"team": "a", "severity": "page", "env": "production"}, receivers: []string{"team-a", "oncall"}},
+		{name: "first match stops evaluation", labels: map[string]string{"team": "b", "severity": "critical"}, receivers: []string{"critical"}},
+		{name: "regex matcher", labels: map[string]string{"team": "b"}, receivers: []string{"team-b"}},
+		{name: "regex is anchored", labels: map[string]string{"team": "bb"}, receivers: []string{"default"}},
+	}
+
+	alerts := make([]v1beta1.RoutingPreviewAlert, len(tests))
+	for i, tt := range tests {
+		alerts[i] = v1beta1.RoutingPreviewAlert{Labels: tt.labels}
+	}
+
+	results := previewRouting(route, alerts)
+	require.Len(t, results, len(tests))
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.labels, results[i].Labels)
+			assert.Equal(t, tt.receivers, results[i].Receivers)
+			assert.Empty(t, results[i].Error)
+		})
+	}
+
+	t.Run("invalid regex", func(t *testing.T) {
+		invalid := &v1beta1.Route{
+			Receiver: "default",
+			Routes:   []*v1beta1.Route{{Receiver: "other", ObjectMatchers: models.ObjectMatchers{{"team", "=~", "("}}}},
+		}
+
+		results := previewRouting(invalid, []v1beta1.RoutingPreviewAlert{{Labels: map[string]string{"team": "a"}}})
+		require.Len(t, results, 1)
+		assert.Empty(t, results[0].Receivers)
+		assert.Contains(t, results[0].Error, "invalid regular expression")
+	})
+
+	t.Run("no preview requested", func(t *testing.T) {
+		assert.Nil(t, previewRouting(route, nil))
+	})
+}
+
+func TestAddBackupRevision(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("adds first revision", func(t *testing.T) {
+		data := addBackupRevision(nil, "a", 2, now)
+
+		assert.Equal(t, map[string]string{"policy-20240501T120000Z.json": "a"}, data)
+	})
+
+	t.Run("skips unchanged revision", func(t *testing.T) {
+		data := map[string]string{"policy-20240430T120000Z.json": "a"}
+
+		data = addBackupRevision(data, "a", 2, now)
+
+		assert.Equal(t, map[string]string{"policy-20240430T120000Z.json": "a"}, data)
+	})
+
+	t.Run("prunes oldest revisions", func(t *testing.T) {
+		data := map[string]string{
+			"policy-20240429T120000Z.json": "a",
+			"policy-20240430T120000Z.json": "b",
+			"unrelated":                    "x",
+		}
+
+		data = addBackupRevision(data, "c", 2, now)
+
+		assert.Equal(t, map[string]string{
+			"policy-20240430T120000Z.json": "b",
+			"policy-20240501T120000Z.json": "c",
+			"unrelated":                    "x",
+		}, data)
+	})
+}
+
+func TestBackupPolicyTree(t *testing.T) {
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	policy := &v1beta1.GrafanaNotificationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy", UID: "policy-uid"},
+		Spec:       v1beta1.GrafanaNotificationPolicySpec{Backup: &v1beta1.NotificationPolicyBackup{Revisions: 5}},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &GrafanaNotificationPolicyReconciler{Client: cl, Scheme: s}
+
+	// Instances with the same name in different namespaces keep their own backup
+	for _, namespace := range []string{"team-a", "team-b"} {
+		apiKey := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "api-key"},
+			Data:       map[string][]byte{"token": []byte("token")},
+		}
+		require.NoError(t, cl.Create(t.Context(), apiKey))
+
+		grafana := &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "grafana"},
+			Spec: v1beta1.GrafanaSpec{
+				External: &v1beta1.External{
+					URL: srv.URL,
+					APIKey: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+						Key:                  "token",
+					},
+				},
+			},
+			Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+		}
+
+		gClient, err := client2.NewGeneratedGrafanaClient(t.Context(), cl, grafana)
+		require.NoError(t, err)
+
+		require.NoError(t, r.backupPolicyTree(t.Context(), gClient, grafana, policy))
+	}
+
+	for _, name := range []string{"policy-team-a-grafana-backup", "policy-team-b-grafana-backup"} {
+		cm := &corev1.ConfigMap{}
+		require.NoError(t, cl.Get(t.Context(), types.NamespacedName{Namespace: "default", Name: name}, cm))
+
+		assert.Len(t, cm.Data, 1)
+		assert.Empty(t, cm.OwnerReferences, "backups must outlive the policy")
+	}
+}
+
 func TestAssembleNotificationPolicyRoutes(t *testing.T) {
 	tests := []struct {
 		name                string
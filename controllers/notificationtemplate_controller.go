@@ -24,8 +24,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
@@ -63,6 +65,15 @@ func (r *GrafanaNotificationTemplateReconciler) Reconcile(ctx context.Context, r
 	if notificationTemplate.GetDeletionTimestamp() != nil {
 		// Check if resource needs clean up
 		if controllerutil.ContainsFinalizer(notificationTemplate, grafanaFinalizer) {
+			wait, err := waitForSuspendedInstances(ctx, r.Client, notificationTemplate)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			if wait {
+				return ctrl.Result{RequeueAfter: suspendedInstancesDelay}, nil
+			}
+
 			if err := r.finalize(ctx, notificationTemplate); err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to finalize GrafanaNotificationTemplate: %w", err)
 			}
@@ -75,7 +86,7 @@ func (r *GrafanaNotificationTemplateReconciler) Reconcile(ctx context.Context, r
 		return ctrl.Result{}, nil
 	}
 
-	defer UpdateStatus(ctx, r.Client, notificationTemplate)
+	defer UpdateStatus(ctx, r.Client, notificationTemplate, notificationTemplate.DeepCopy())
 
 	if notificationTemplate.Spec.Suspend {
 		setSuspended(&notificationTemplate.Status.Conditions, notificationTemplate.Generation, conditionReasonApplySuspended)
@@ -96,18 +107,31 @@ func (r *GrafanaNotificationTemplateReconciler) Reconcile(ctx context.Context, r
 		setNoMatchingInstancesCondition(&notificationTemplate.Status.Conditions, notificationTemplate.Generation, err)
 		meta.RemoveStatusCondition(&notificationTemplate.Status.Conditions, conditionNotificationTemplateSynchronized)
 
-		return ctrl.Result{}, ErrNoMatchingInstances
+		return noMatchingInstancesResult(ctx, r.Client, notificationTemplate)
 	}
 
 	removeNoMatchingInstance(&notificationTemplate.Status.Conditions)
+
+	instances, pendingWaves, err := holdForLowerWaves(ctx, r.Client, notificationTemplate, instances)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	setPendingWave(&notificationTemplate.Status.Conditions, notificationTemplate.Generation, pendingWaves)
+
+	if len(instances) == 0 {
+		log.Info("waiting for resources of lower waves", "instances", len(pendingWaves))
+		return ctrl.Result{RequeueAfter: waveRequeueDelay}, nil
+	}
+
 	log.Info("found matching Grafana instances for notification template", "count", len(instances))
 
-	applyErrors := make(map[string]string)
+	applyErrors := make(map[string]error)
 
 	for _, grafana := range instances {
 		err := r.reconcileWithInstance(ctx, &grafana, notificationTemplate)
 		if err != nil {
-			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err.Error()
+			applyErrors[fmt.Sprintf("%s/%s", grafana.Namespace, grafana.Name)] = err
 		}
 	}
 
@@ -118,7 +142,7 @@ func (r *GrafanaNotificationTemplateReconciler) Reconcile(ctx context.Context, r
 		return ctrl.Result{}, fmt.Errorf("failed to apply to all instances: %v", applyErrors)
 	}
 
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(notificationTemplate.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: requeueForPendingWave(r.Cfg.requeueAfter(notificationTemplate.Spec.ResyncPeriod, notificationTemplate.Spec.ResyncJitterPercent), pendingWaves)}, nil
 }
 
 func (r *GrafanaNotificationTemplateReconciler) reconcileWithInstance(ctx context.Context, instance *grafanav1beta1.Grafana, notificationTemplate *grafanav1beta1.GrafanaNotificationTemplate) error {
@@ -191,9 +215,20 @@ func (r *GrafanaNotificationTemplateReconciler) removeFromInstance(ctx context.C
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *GrafanaNotificationTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *GrafanaNotificationTemplateReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	// Index the notification templates by the namespace of the instances they select, to requeue them once an instance recovers.
+	if err := mgr.GetCache().IndexField(ctx, &grafanav1beta1.GrafanaNotificationTemplate{}, instanceScopeIndexKey, indexInstanceScope); err != nil {
+		return fmt.Errorf("failed setting instance scope index: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&grafanav1beta1.GrafanaNotificationTemplate{}).
-		WithEventFilter(ignoreStatusUpdates()).
+		For(&grafanav1beta1.GrafanaNotificationTemplate{}, builder.WithPredicates(
+			ignoreStatusUpdates(),
+		)).
+		Watches(
+			&grafanav1beta1.Grafana{},
+			handler.EnqueueRequestsFromMapFunc(requestsForRecoveredInstance(r.Client, func() client.ObjectList { return &grafanav1beta1.GrafanaNotificationTemplateList{} })),
+			builder.WithPredicates(instanceRecovered()),
+		).
 		Complete(r)
 }
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/folders"
+	"github.com/grafana/grafana-openapi-client-go/client/teams"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	conditionReasonFolderNotAllowed = "FolderNotAllowed"
+
+	// tenantFolderPrefix prefixes the UIDs of the folders created for tenant namespaces
+	tenantFolderPrefix = "tenant-"
+
+	// maxFolderUIDLength is the longest folder UID accepted by Grafana
+	maxFolderUIDLength = 40
+
+	// permissionEdit is the Edit permission of the Grafana folder permissions API
+	permissionEdit models.PermissionType = 2
+)
+
+var errFolderNotAllowed = errors.New("folder not allowed")
+
+// operatorConfigs lists the GrafanaOperatorConfigs, none apply to operators restricted to namespaces as they can't
+// read the cluster scoped resource
+func (c *Config) operatorConfigs(ctx context.Context, cl client.Client) ([]v1beta1.GrafanaOperatorConfig, error) {
+	if c == nil || !c.ClusterScoped {
+		return nil, nil
+	}
+
+	configs := &v1beta1.GrafanaOperatorConfigList{}
+
+	err := cl.List(ctx, configs)
+	if err != nil {
+		return nil, fmt.Errorf("listing GrafanaOperatorConfigs: %w", err)
+	}
+
+	return configs.Items, nil
+}
+
+// checkAlertRuleGroupFolder enforces the alertRuleGroupFolders policies of all GrafanaOperatorConfigs on
+// GrafanaAlertRuleGroups in namespace writing to the folder with folderUID
+func (c *Config) checkAlertRuleGroupFolder(ctx context.Context, cl client.Client, namespace, folderUID string) error {
+	configs, err := c.operatorConfigs(ctx, cl)
+	if err != nil {
+		return err
+	}
+
+	restricted := false
+	namespaceFolders := false
+
+	for _, config := range configs {
+		for _, policy := range config.Spec.AlertRuleGroupFolders {
+			if !policy.Matches(namespace) {
+				continue
+			}
+
+			if slices.Contains(policy.FolderUIDs, folderUID) {
+				return nil
+			}
+
+			restricted = true
+			namespaceFolders = namespaceFolders || policy.NamespaceFolders
+		}
+	}
+
+	if !restricted {
+		return nil
+	}
+
+	if namespaceFolders {
+		tenant, err := c.tenantFolders(ctx, cl, namespace)
+		if err != nil {
+			return err
+		}
+
+		if tenant != nil && tenantFolderUID(namespace) == folderUID {
+			return nil
+		}
+
+		grafanaFolders := &v1beta1.GrafanaFolderList{}
+
+		err = cl.List(ctx, grafanaFolders, client.InNamespace(namespace))
+		if err != nil {
+			return fmt.Errorf("listing folders of the namespace: %w", err)
+		}
+
+		for _, folder := range grafanaFolders.Items {
+			if folder.CustomUIDOrUID() == folderUID {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: GrafanaOperatorConfig policies don't allow namespace %s to write alert rule groups to folder %s", errFolderNotAllowed, namespace, folderUID)
+}
+
+// tenantFolders returns the tenant folder settings of the GrafanaOperatorConfigs matching namespace,
+// nil when namespace isn't a tenant namespace
+func (c *Config) tenantFolders(ctx context.Context, cl client.Client, namespace string) (*v1beta1.TenantFolders, error) {
+	configs, err := c.operatorConfigs(ctx, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant *v1beta1.TenantFolders
+
+	for _, config := range configs {
+		if config.Spec.TenantFolders == nil || !config.Spec.TenantFolders.Matches(namespace) {
+			continue
+		}
+
+		if tenant == nil {
+			tenant = &v1beta1.TenantFolders{}
+		}
+
+		tenant.Teams = tenant.Teams || config.Spec.TenantFolders.Teams
+		tenant.AdoptExistingTeams = tenant.AdoptExistingTeams || config.Spec.TenantFolders.AdoptExistingTeams
+	}
+
+	return tenant, nil
+}
+
+// tenantFolderUID returns the UID of the folder of a tenant namespace, hashing namespaces too long for a folder UID
+func tenantFolderUID(namespace string) string {
+	uid := tenantFolderPrefix + namespace
+	if len(uid) <= maxFolderUIDLength {
+		return uid
+	}
+
+	sum := sha256.Sum256([]byte(namespace))
+
+	return tenantFolderPrefix + hex.EncodeToString(sum[:])[:maxFolderUIDLength-len(tenantFolderPrefix)]
+}
+
+var errTenantTeamExists = errors.New("tenant team not created by the operator")
+
+// ensureTenantFolder creates the folder of a tenant namespace in the instance and, with teams enabled,
+// the team of the namespace with edit permission on the folder
+func ensureTenantFolder(ctx context.Context, cl client.Client, grafana *v1beta1.Grafana, namespace string, tenant *v1beta1.TenantFolders) error {
+	grafanaClient, err := client2.NewGeneratedGrafanaClient(ctx, cl, grafana)
+	if err != nil {
+		return fmt.Errorf("creating grafana http client: %w", err)
+	}
+
+	uid := tenantFolderUID(namespace)
+
+	folderCreated := false
+
+	_, err = grafanaClient.Folders.GetFolderByUID(uid) //nolint:errcheck
+	if err != nil {
+		var notFound *folders.GetFolderByUIDNotFound
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("fetching tenant folder: %w", err)
+		}
+
+		// The team is granted access along with the folder, teams the operator didn't create must be adopted explicitly
+		if tenant.Teams && !tenant.AdoptExistingTeams {
+			team, err := findTeam(grafanaClient, namespace)
+			if err != nil {
+				return err
+			}
+
+			if team != nil {
+				return fmt.Errorf("%w: team %s already exists, set tenantFolders.adoptExistingTeams to grant it access to the folder", errTenantTeamExists, namespace)
+			}
+		}
+
+		_, err = grafanaClient.Folders.CreateFolder(&models.CreateFolderCommand{UID: uid, Title: namespace}) //nolint:errcheck
+		if err != nil {
+			return fmt.Errorf("creating tenant folder: %w", err)
+		}
+
+		folderCreated = true
+	}
+
+	if !tenant.Teams {
+		return nil
+	}
+
+	teamID, teamCreated, err := getOrCreateTeam(grafanaClient, namespace)
+	if err != nil {
+		return err
+	}
+
+	// Permissions are only granted once so they can be changed in Grafana afterwards
+	if !folderCreated && !teamCreated {
+		return nil
+	}
+
+	_, err = grafanaClient.FolderPermissions.UpdateFolderPermissions(uid, &models.UpdateDashboardACLCommand{ //nolint:errcheck
+		Items: []*models.DashboardACLUpdateItem{
+			{TeamID: teamID, Permission: permissionEdit},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("granting the tenant team access to its folder: %w", err)
+	}
+
+	return nil
+}
+
+// getOrCreateTeam returns the ID of the team with the given name and whether it was created
+func getOrCreateTeam(grafanaClient *genapi.GrafanaHTTPAPI, name string) (int64, bool, error) {
+	team, err := findTeam(grafanaClient, name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if team != nil {
+		return team.ID, false, nil
+	}
+
+	created, err := grafanaClient.Teams.CreateTeam(&models.CreateTeamCommand{Name: name})
+	if err != nil {
+		return 0, false, fmt.Errorf("creating tenant team: %w", err)
+	}
+
+	return created.GetPayload().TeamID, true, nil
+}
+
+// findTeam returns the team with the given name, nil when it does not exist
+func findTeam(grafanaClient *genapi.GrafanaHTTPAPI, name string) (*models.TeamDTO, error) {
+	resp, err := grafanaClient.Teams.SearchTeams(teams.NewSearchTeamsParams().WithName(&name))
+	if err != nil {
+		return nil, fmt.Errorf("searching team %s: %w", name, err)
+	}
+
+	for _, team := range resp.GetPayload().Teams {
+		if team.Name == name {
+			return team, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+)
+
+var logLevelVerbosity = map[v1beta1.LogLevel]int{
+	v1beta1.LogLevelError: logging.VerbosityError,
+	v1beta1.LogLevelInfo:  logging.VerbosityInfo,
+	v1beta1.LogLevelDebug: logging.VerbosityDebug,
+}
+
+// GrafanaOperatorConfigReconciler applies the log levels of all GrafanaOperatorConfigs to the loggers of the operator.
+// It runs on every replica, as each one has its own loggers
+type GrafanaOperatorConfigReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *GrafanaOperatorConfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithName("GrafanaOperatorConfigReconciler")
+
+	configs := &v1beta1.GrafanaOperatorConfigList{}
+
+	err := r.Client.List(ctx, configs)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing GrafanaOperatorConfigs: %w", err)
+	}
+
+	levels := logLevels(configs.Items)
+	logging.SetLevels(levels)
+
+	log.Info("applied log levels", "default", levels.Default, "controllers", levels.Controllers)
+
+	return ctrl.Result{}, nil
+}
+
+// logLevels merges the logging of configs, the most verbose level of the operator and of each controller applies
+func logLevels(configs []v1beta1.GrafanaOperatorConfig) logging.Levels {
+	levels := logging.Levels{Controllers: map[string]int{}}
+
+	for _, config := range configs {
+		if config.Spec.Logging == nil {
+			continue
+		}
+
+		if v, ok := logLevelVerbosity[config.Spec.Logging.Level]; ok && (levels.Default == nil || v > *levels.Default) {
+			levels.Default = ptr.To(v)
+		}
+
+		for kind, level := range config.Spec.Logging.Controllers {
+			// Controllers are named after the lowercase kind they reconcile
+			name := strings.ToLower(kind)
+
+			v, ok := logLevelVerbosity[level]
+			if !ok {
+				continue
+			}
+
+			if current, seen := levels.Controllers[name]; !seen || v > current {
+				levels.Controllers[name] = v
+			}
+		}
+	}
+
+	return levels
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GrafanaOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.GrafanaOperatorConfig{}).
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
+		Complete(r)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestLogLevels(t *testing.T) {
+	configs := []v1beta1.GrafanaOperatorConfig{
+		{Spec: v1beta1.GrafanaOperatorConfigSpec{}},
+		{Spec: v1beta1.GrafanaOperatorConfigSpec{Logging: &v1beta1.OperatorLogging{
+			Level: v1beta1.LogLevelError,
+			Controllers: map[string]v1beta1.LogLevel{
+				"GrafanaDashboard": v1beta1.LogLevelDebug,
+				"GrafanaFolder":    v1beta1.LogLevelError,
+			},
+		}}},
+		{Spec: v1beta1.GrafanaOperatorConfigSpec{Logging: &v1beta1.OperatorLogging{
+			Level: v1beta1.LogLevelInfo,
+			Controllers: map[string]v1beta1.LogLevel{
+				"GrafanaFolder": v1beta1.LogLevelInfo,
+			},
+		}}},
+	}
+
+	assert.Equal(t, logging.Levels{
+		Default: ptr.To(logging.VerbosityInfo),
+		Controllers: map[string]int{
+			"grafanadashboard": logging.VerbosityDebug,
+			"grafanafolder":    logging.VerbosityInfo,
+		},
+	}, logLevels(configs))
+
+	// Without levels the flag applies
+	assert.Equal(t, logging.Levels{Controllers: map[string]int{}}, logLevels(configs[:1]))
+}
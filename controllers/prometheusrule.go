@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// PrometheusRuleName is the name of the PrometheusRule maintained with --prometheus-rule-namespace
+const PrometheusRuleName = "grafana-operator-alerts"
+
+var prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+var _ manager.LeaderElectionRunnable = (*PrometheusRuleApplier)(nil)
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;create;update;patch
+
+// PrometheusRuleApplier maintains a PrometheusRule with the alerts on the health of the operator, see metrics.AlertRules.
+// Modifications of the rules are reverted every interval, added labels and annotations are kept
+type PrometheusRuleApplier struct {
+	Client    client.Client
+	Namespace string
+	// Labels are set on the PrometheusRule, e.g. to match the ruleSelector of a Prometheus
+	Labels   map[string]string
+	Interval time.Duration
+}
+
+// NeedLeaderElection returns true, only the leader applies the PrometheusRule
+func (a *PrometheusRuleApplier) NeedLeaderElection() bool {
+	return true
+}
+
+// Start applies the PrometheusRule every interval until the context is cancelled
+func (a *PrometheusRuleApplier) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("prometheusrule")
+	ctx = logf.IntoContext(ctx, log)
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.Apply(ctx); err != nil {
+			if meta.IsNoMatchError(err) {
+				log.Error(err, "the PrometheusRule CRD of the prometheus-operator is not installed, alerts on the operator are not applied")
+				return nil
+			}
+
+			log.Error(err, "applying PrometheusRule", "namespace", a.Namespace, "name", PrometheusRuleName)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Apply creates or updates the PrometheusRule once
+func (a *PrometheusRuleApplier) Apply(ctx context.Context) error {
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	rule.SetNamespace(a.Namespace)
+	rule.SetName(PrometheusRuleName)
+
+	result, err := controllerutil.CreateOrUpdate(ctx, a.Client, rule, func() error {
+		labels := rule.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		maps.Copy(labels, a.Labels)
+		labels["app.kubernetes.io/managed-by"] = "grafana-operator"
+		rule.SetLabels(labels)
+
+		return unstructured.SetNestedSlice(rule.Object, metrics.PrometheusRuleGroups(), "spec", "groups")
+	})
+	if err != nil {
+		return fmt.Errorf("applying PrometheusRule %s/%s: %w", a.Namespace, PrometheusRuleName, err)
+	}
+
+	if result != controllerutil.OperationResultNone {
+		logf.FromContext(ctx).Info("applied PrometheusRule", "namespace", a.Namespace, "name", PrometheusRuleName, "result", result)
+	}
+
+	return nil
+}
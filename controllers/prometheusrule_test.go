@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPrometheusRuleApplier(t *testing.T) {
+	ctx := context.Background()
+
+	cl := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+
+	a := &PrometheusRuleApplier{Client: cl, Namespace: "grafana-operator", Labels: map[string]string{"release": "prometheus"}}
+	require.NoError(t, a.Apply(ctx))
+
+	get := func() *unstructured.Unstructured {
+		rule := &unstructured.Unstructured{}
+		rule.SetGroupVersionKind(prometheusRuleGVK)
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Namespace: "grafana-operator", Name: PrometheusRuleName}, rule))
+
+		return rule
+	}
+
+	rule := get()
+	assert.Equal(t, map[string]string{"release": "prometheus", "app.kubernetes.io/managed-by": "grafana-operator"}, rule.GetLabels())
+
+	groups, _, err := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	require.NoError(t, err)
+	assert.Equal(t, metrics.PrometheusRuleGroups(), groups)
+
+	// Modified rules are reverted, added labels are kept
+	require.NoError(t, unstructured.SetNestedSlice(rule.Object, []any{}, "spec", "groups"))
+	rule.SetLabels(map[string]string{"team": "platform"})
+	require.NoError(t, cl.Update(ctx, rule))
+
+	require.NoError(t, a.Apply(ctx))
+
+	rule = get()
+	assert.Equal(t, "platform", rule.GetLabels()["team"])
+
+	groups, _, err = unstructured.NestedSlice(rule.Object, "spec", "groups")
+	require.NoError(t, err)
+	assert.Len(t, groups, 1)
+}
@@ -41,6 +41,7 @@ func (r *AdminSecretReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafa
 		}
 
 		model.SetInheritedLabels(secret, cr.Labels)
+		model.SetOwnershipAnnotations(secret, cr.Annotations)
 
 		return nil
 	})
@@ -3,17 +3,40 @@ package grafana
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
+	"github.com/grafana/grafana-openapi-client-go/models"
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// Keys of the <name>-grafana-info ConfigMap
+const (
+	InfoKeyAdminURL = "GRAFANA_ADMIN_URL"
+	InfoKeyInstance = "GRAFANA_INSTANCE"
+	InfoKeyVersion  = "GRAFANA_VERSION"
+	InfoKeyExternal = "GRAFANA_EXTERNAL"
+	InfoKeyOrgID    = "GRAFANA_ORG_ID"
+	InfoKeyOrgName  = "GRAFANA_ORG_NAME"
+)
+
+var (
+	// ErrDatabaseUnavailable is returned when the health endpoint reports database connection failures
+	ErrDatabaseUnavailable = errors.New("grafana database unavailable")
+	// ErrStarting is returned while the health endpoint of an instance managed by the operator doesn't respond
+	ErrStarting = errors.New("grafana starting")
+)
+
 type CompleteReconciler struct {
 	client client.Client
 }
@@ -24,9 +47,15 @@ func NewCompleteReconciler(client client.Client) reconcilers.OperatorGrafanaReco
 	}
 }
 
-func (r *CompleteReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, _ *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+func (r *CompleteReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
 	log := logf.FromContext(ctx).WithName("CompleteReconciler")
 
+	log.V(1).Info("checking Grafana health")
+
+	if err := r.checkHealth(ctx, cr); err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
 	log.V(1).Info("fetching Grafana version from instance")
 
 	version, err := r.getVersion(ctx, cr)
@@ -37,11 +66,87 @@ func (r *CompleteReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 
 	cr.Status.Version = version
 
+	log.V(1).Info("updating Grafana info configmap")
+
+	if err := r.reconcileInfo(ctx, cr, scheme); err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("updating grafana info configmap: %w", err)
+	}
+
+	// Readonly instances share the organizations and dashboards of their primary
+	if cr.Spec.StatusPage != nil && !cr.IsReadonly() {
+		log.V(1).Info("reconciling status page organization")
+
+		if err := r.reconcileStatusPage(ctx, cr); err != nil {
+			return v1beta1.OperatorStageResultFailed, fmt.Errorf("reconciling status page: %w", err)
+		}
+	}
+
 	log.V(1).Info("reconciliation completed")
 
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
+// checkHealth returns ErrDatabaseUnavailable when the health endpoint reports a failing database and ErrStarting
+// when the endpoint of a managed instance doesn't respond. Other failures are left to the version detection
+func (r *CompleteReconciler) checkHealth(ctx context.Context, cr *v1beta1.Grafana) error {
+	cl, err := client2.NewHTTPClient(ctx, r.client, cr)
+	if err != nil {
+		return fmt.Errorf("setup of the http client: %w", err)
+	}
+
+	gURL, err := client2.ParseAdminURL(cr.Status.AdminURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, gURL.JoinPath(GrafanaHealthEndpoint).String(), nil)
+	if err != nil {
+		return fmt.Errorf("building request to check health: %w", err)
+	}
+
+	resp, err := cl.Do(req)
+	if err != nil {
+		// External instances are not started by the operator
+		if cr.IsExternal() {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %w", ErrStarting, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	return parseHealthResponse(resp.Body)
+}
+
+// parseHealthResponse checks the database state reported by /api/health,
+// which returns 503 with {"database": "failing"} if the database can't be queried
+func parseHealthResponse(body io.Reader) error {
+	data := struct {
+		Database string `json:"database"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&data); err != nil {
+		return nil
+	}
+
+	if data.Database != "" && data.Database != "ok" {
+		return fmt.Errorf("%w: health endpoint reports database %q", ErrDatabaseUnavailable, data.Database)
+	}
+
+	return nil
+}
+
+// GetStartupPhase returns the startup phase an error of the reconcilers indicates, empty for other errors
+func GetStartupPhase(err error) v1beta1.GrafanaStartupPhase {
+	switch {
+	case errors.Is(err, ErrStarting):
+		return v1beta1.GrafanaStartupStarting
+	case errors.Is(err, ErrDatabaseUnavailable):
+		return v1beta1.GrafanaStartupDatabaseUnavailable
+	default:
+		return ""
+	}
+}
+
 func (r *CompleteReconciler) getVersion(ctx context.Context, cr *v1beta1.Grafana) (string, error) {
 	cl, err := client2.NewHTTPClient(ctx, r.client, cr)
 	if err != nil {
@@ -85,3 +190,60 @@ func (r *CompleteReconciler) getVersion(ctx context.Context, cr *v1beta1.Grafana
 
 	return data.BuildInfo.Version, nil
 }
+
+// reconcileInfo maintains the <name>-grafana-info ConfigMap, allowing other workloads to consume the
+// endpoint of the instance, e.g. through envFrom, without parsing the Grafana status
+func (r *CompleteReconciler) reconcileInfo(ctx context.Context, cr *v1beta1.Grafana, scheme *runtime.Scheme) error {
+	log := logf.FromContext(ctx)
+
+	data := grafanaInfo(cr)
+
+	org, err := r.getCurrentOrg(ctx, cr)
+	if err != nil {
+		// Keep the remaining information available, the organization is optional
+		log.Error(err, "fetching current organization for the info configmap")
+	} else {
+		data[InfoKeyOrgID] = strconv.FormatInt(org.ID, 10)
+		data[InfoKeyOrgName] = org.Name
+	}
+
+	configMap := model.GetGrafanaInfoConfigMap(cr, scheme)
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, configMap, func() error {
+		configMap.Data = data
+
+		model.SetInheritedLabels(configMap, cr.Labels)
+
+		if scheme != nil {
+			return controllerutil.SetControllerReference(cr, configMap, scheme)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// grafanaInfo returns the info ConfigMap data known without contacting the instance
+func grafanaInfo(cr *v1beta1.Grafana) map[string]string {
+	return map[string]string{
+		InfoKeyAdminURL: cr.Status.AdminURL,
+		InfoKeyInstance: fmt.Sprintf("%s/%s", cr.Namespace, cr.Name),
+		InfoKeyVersion:  cr.Status.Version,
+		InfoKeyExternal: strconv.FormatBool(cr.IsExternal()),
+	}
+}
+
+func (r *CompleteReconciler) getCurrentOrg(ctx context.Context, cr *v1beta1.Grafana) (*models.OrgDetailsDTO, error) {
+	cl, err := client2.NewGeneratedGrafanaClient(ctx, r.client, cr)
+	if err != nil {
+		return nil, fmt.Errorf("building grafana client: %w", err)
+	}
+
+	resp, err := cl.Org.GetCurrentOrg()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Payload, nil
+}
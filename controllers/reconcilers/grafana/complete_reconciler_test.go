@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseHealthResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "database ok",
+			body: `{"commit":"abc","database":"ok","version":"11.3.0"}`,
+		},
+		{
+			name:    "database failing",
+			body:    `{"commit":"abc","database":"failing","version":"11.3.0"}`,
+			wantErr: true,
+		},
+		{
+			name: "database not reported",
+			body: `{"version":"11.3.0"}`,
+		},
+		{
+			name: "invalid body",
+			body: `<html></html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseHealthResponse(strings.NewReader(tt.body))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrDatabaseUnavailable)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGetStartupPhase(t *testing.T) {
+	assert.Equal(t, v1beta1.GrafanaStartupStarting, GetStartupPhase(fmt.Errorf("%w: connection refused", ErrStarting)))
+	assert.Equal(t, v1beta1.GrafanaStartupDatabaseUnavailable, GetStartupPhase(fmt.Errorf("%w: failing", ErrDatabaseUnavailable)))
+	assert.Empty(t, GetStartupPhase(errors.New("unauthorized")))
+}
+
+func TestGrafanaInfo(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "monitoring", Name: "grafana"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{URL: "https://grafana.example.com"},
+		},
+		Status: v1beta1.GrafanaStatus{
+			AdminURL: "https://grafana.example.com",
+			Version:  "12.0.0",
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		InfoKeyAdminURL: "https://grafana.example.com",
+		InfoKeyInstance: "monitoring/grafana",
+		InfoKeyVersion:  "12.0.0",
+		InfoKeyExternal: "true",
+	}, grafanaInfo(cr))
+}
@@ -26,7 +26,17 @@ func NewConfigReconciler(client client.Client) reconcilers.OperatorGrafanaReconc
 func (r *ConfigReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
 	_ = logf.FromContext(ctx)
 
-	cfg := config.WriteIni(cr.Spec.Config)
+	ini, presetSettings := config.WithPreset(cr.Spec.Config, cr.Spec.Preset)
+	cr.Status.PresetSettings = presetSettings
+
+	ini = config.WithRole(config.WithSizing(ini, cr.Spec.Sizing), cr.Spec.Role)
+	ini = config.WithStatusPage(config.WithTelemetry(ini, cr.Spec.Telemetry), cr.Spec.StatusPage)
+	ini = config.WithAlertScreenshots(config.WithExternalImageStorage(ini, cr.Spec.ExternalImageStorage), cr.Spec.AlertScreenshots, cr.Spec.ImageRenderer != nil)
+
+	ini = config.WithServingCert(config.WithPluginPolicy(ini, cr.Spec.Plugins), cr.UsesServingCert())
+	ini = config.WithRemoteCache(ini, cr.Spec.RemoteCache)
+
+	cfg := config.WriteIni(config.WithSecurity(ini, cr.Spec.Security))
 	vars.ConfigHash = config.GetHash(cfg)
 
 	configMap := model.GetGrafanaConfigMap(cr, scheme)
@@ -46,6 +56,7 @@ func (r *ConfigReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, v
 		}
 
 		model.SetInheritedLabels(configMap, cr.Labels)
+		model.SetOwnershipAnnotations(configMap, cr.Annotations)
 
 		return nil
 	})
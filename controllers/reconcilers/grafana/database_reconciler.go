@@ -0,0 +1,184 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Keys of the Secret of spec.database.secretRef, matching the app Secret of CloudNativePG clusters
+const (
+	databaseHostKey     = "host"
+	databasePortKey     = "port"
+	databaseUsernameKey = "username"
+	databasePasswordKey = "password"
+	databaseNameKey     = "dbname"
+)
+
+// ErrDatabaseUnreachable is returned while the database of spec.database doesn't accept connections
+var ErrDatabaseUnreachable = fmt.Errorf("%w: unreachable", ErrDatabaseUnavailable)
+
+// dialDatabase opens a connection to the database, replaced in tests
+var dialDatabase = (&net.Dialer{Timeout: 5 * time.Second}).DialContext
+
+// DatabaseReconciler resolves the connection details of spec.database and holds back the rollout until the database
+// accepts connections
+type DatabaseReconciler struct {
+	client client.Client
+}
+
+func NewDatabaseReconciler(client client.Client) reconcilers.OperatorGrafanaReconciler {
+	return &DatabaseReconciler{
+		client: client,
+	}
+}
+
+func (r *DatabaseReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, _ *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	database := cr.Spec.Database
+	if database == nil {
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log := logf.FromContext(ctx).WithName("DatabaseReconciler")
+
+	secret := &corev1.Secret{}
+
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: getDatabaseSecretName(cr)}, secret)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("fetching database secret: %w", err)
+	}
+
+	for _, key := range []string{databaseUsernameKey, databasePasswordKey} {
+		if _, ok := secret.Data[key]; !ok {
+			return v1beta1.OperatorStageResultFailed, fmt.Errorf("database secret %s lacks the key %s", secret.Name, key)
+		}
+	}
+
+	address, err := getDatabaseAddress(cr, secret)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	vars.DatabaseHost = address
+
+	if database.SkipConnectivityCheck {
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.V(1).Info("checking database connectivity", "address", address)
+
+	conn, err := dialDatabase(ctx, "tcp", address)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("%w: connecting to %s: %w", ErrDatabaseUnreachable, address, err)
+	}
+
+	_ = conn.Close()
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// getDatabaseSecretName returns the name of the Secret holding the credentials of spec.database
+func getDatabaseSecretName(cr *v1beta1.Grafana) string {
+	database := cr.Spec.Database
+
+	if database.SecretRef != nil {
+		return database.SecretRef.Name
+	}
+
+	cluster := database.ClusterRef
+	if cluster.Kind == v1beta1.GrafanaDatabaseClusterZalando {
+		// The postgres-operator replaces underscores of user names in Secret names
+		return fmt.Sprintf("%s.%s.credentials.postgresql.acid.zalan.do", strings.ReplaceAll(cluster.User, "_", "-"), cluster.Name)
+	}
+
+	return cluster.Name + "-app"
+}
+
+// getDatabaseAddress returns host:port of the database, hosts without domain are qualified with the namespace of
+// the instance, so they resolve for the operator too
+func getDatabaseAddress(cr *v1beta1.Grafana, secret *corev1.Secret) (string, error) {
+	host := string(secret.Data[databaseHostKey])
+	port := string(secret.Data[databasePortKey])
+
+	if cluster := cr.Spec.Database.ClusterRef; cluster != nil && cluster.Kind == v1beta1.GrafanaDatabaseClusterZalando {
+		// The master service is named after the cluster
+		host = cluster.Name
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("database secret %s lacks the key %s", secret.Name, databaseHostKey)
+	}
+
+	if !strings.Contains(host, ".") {
+		host = fmt.Sprintf("%s.%s.svc", host, cr.Namespace)
+	}
+
+	if port == "" {
+		port = "5432"
+		if cr.Spec.Database.Type == v1beta1.GrafanaDatabaseMySQL {
+			port = "3306"
+		}
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// getDatabaseEnvVars returns the GF_DATABASE_* env vars of spec.database, credentials are read from the Secret
+func getDatabaseEnvVars(cr *v1beta1.Grafana, host string) []corev1.EnvVar {
+	database := cr.Spec.Database
+	if database == nil || host == "" {
+		return nil
+	}
+
+	secretName := getDatabaseSecretName(cr)
+
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+
+	dbType := database.Type
+	if dbType == "" {
+		dbType = v1beta1.GrafanaDatabasePostgres
+	}
+
+	envVars := []corev1.EnvVar{
+		{Name: "GF_DATABASE_TYPE", Value: string(dbType)},
+		{Name: "GF_DATABASE_HOST", Value: host},
+		fromSecret("GF_DATABASE_USER", databaseUsernameKey),
+		fromSecret("GF_DATABASE_PASSWORD", databasePasswordKey),
+	}
+
+	if database.ClusterRef != nil && database.ClusterRef.Database != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "GF_DATABASE_NAME", Value: database.ClusterRef.Database})
+	} else {
+		// Grafana keeps its default database name when the Secret has none
+		name := fromSecret("GF_DATABASE_NAME", databaseNameKey)
+		name.ValueFrom.SecretKeyRef.Optional = ptr.To(true)
+
+		envVars = append(envVars, name)
+	}
+
+	if database.SSLMode != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "GF_DATABASE_SSL_MODE", Value: database.SSLMode})
+	}
+
+	return envVars
+}
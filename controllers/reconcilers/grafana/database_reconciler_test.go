@@ -0,0 +1,166 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDatabaseSecretName(t *testing.T) {
+	cr := func(database *v1beta1.GrafanaDatabase) *v1beta1.Grafana {
+		return &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Database: database}}
+	}
+
+	assert.Equal(t, "grafana-db", getDatabaseSecretName(cr(&v1beta1.GrafanaDatabase{
+		SecretRef: &corev1.LocalObjectReference{Name: "grafana-db"},
+	})))
+	assert.Equal(t, "pg-app", getDatabaseSecretName(cr(&v1beta1.GrafanaDatabase{
+		ClusterRef: &v1beta1.GrafanaDatabaseClusterRef{Kind: v1beta1.GrafanaDatabaseClusterCloudNativePG, Name: "pg"},
+	})))
+	assert.Equal(t, "grafana-owner.pg.credentials.postgresql.acid.zalan.do", getDatabaseSecretName(cr(&v1beta1.GrafanaDatabase{
+		ClusterRef: &v1beta1.GrafanaDatabaseClusterRef{Kind: v1beta1.GrafanaDatabaseClusterZalando, Name: "pg", User: "grafana_owner", Database: "grafana"},
+	})))
+}
+
+func TestGetDatabaseAddress(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-db"},
+		Data:       map[string][]byte{"host": []byte("pg-rw")},
+	}
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec: v1beta1.GrafanaSpec{
+			Database: &v1beta1.GrafanaDatabase{SecretRef: &corev1.LocalObjectReference{Name: "grafana-db"}},
+		},
+	}
+
+	address, err := getDatabaseAddress(cr, secret)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-rw.monitoring.svc:5432", address)
+
+	mysql := cr.DeepCopy()
+	mysql.Spec.Database.Type = v1beta1.GrafanaDatabaseMySQL
+
+	address, err = getDatabaseAddress(mysql, &corev1.Secret{Data: map[string][]byte{"host": []byte("mysql.example.com")}})
+	require.NoError(t, err)
+	assert.Equal(t, "mysql.example.com:3306", address)
+
+	zalando := cr.DeepCopy()
+	zalando.Spec.Database = &v1beta1.GrafanaDatabase{
+		ClusterRef: &v1beta1.GrafanaDatabaseClusterRef{Kind: v1beta1.GrafanaDatabaseClusterZalando, Name: "pg", User: "grafana", Database: "grafana"},
+	}
+
+	address, err = getDatabaseAddress(zalando, &corev1.Secret{})
+	require.NoError(t, err)
+	assert.Equal(t, "pg.monitoring.svc:5432", address)
+
+	_, err = getDatabaseAddress(cr, &corev1.Secret{})
+	assert.Error(t, err)
+}
+
+func TestGetDatabaseEnvVars(t *testing.T) {
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "pg-app"},
+			Key:                  key,
+		}}}
+	}
+
+	cr := &v1beta1.Grafana{
+		Spec: v1beta1.GrafanaSpec{
+			Database: &v1beta1.GrafanaDatabase{
+				ClusterRef: &v1beta1.GrafanaDatabaseClusterRef{Kind: v1beta1.GrafanaDatabaseClusterCloudNativePG, Name: "pg"},
+				SSLMode:    "require",
+			},
+		},
+	}
+
+	dbName := fromSecret("GF_DATABASE_NAME", "dbname")
+	dbName.ValueFrom.SecretKeyRef.Optional = ptr.To(true)
+
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "GF_DATABASE_TYPE", Value: "postgres"},
+		{Name: "GF_DATABASE_HOST", Value: "pg-rw.default.svc:5432"},
+		fromSecret("GF_DATABASE_USER", "username"),
+		fromSecret("GF_DATABASE_PASSWORD", "password"),
+		dbName,
+		{Name: "GF_DATABASE_SSL_MODE", Value: "require"},
+	}, getDatabaseEnvVars(cr, "pg-rw.default.svc:5432"))
+
+	cr.Spec.Database.ClusterRef.Database = "grafana"
+	assert.Contains(t, getDatabaseEnvVars(cr, "pg-rw.default.svc:5432"), corev1.EnvVar{Name: "GF_DATABASE_NAME", Value: "grafana"})
+
+	assert.Empty(t, getDatabaseEnvVars(&v1beta1.Grafana{}, ""))
+}
+
+func TestDatabaseReconciler(t *testing.T) {
+	reachable := true
+
+	dial := dialDatabase
+	dialDatabase = func(_ context.Context, _, _ string) (net.Conn, error) {
+		if !reachable {
+			return nil, errors.New("connection refused")
+		}
+
+		client, server := net.Pipe()
+		_ = server.Close()
+
+		return client, nil
+	}
+
+	defer func() { dialDatabase = dial }()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-db", Namespace: "default"},
+		Data: map[string][]byte{
+			"host":     []byte("postgres.example.com"),
+			"port":     []byte("5433"),
+			"username": []byte("grafana"),
+			"password": []byte("secret"),
+		},
+	}
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			Database: &v1beta1.GrafanaDatabase{SecretRef: &corev1.LocalObjectReference{Name: "grafana-db"}},
+		},
+	}
+
+	r := NewDatabaseReconciler(fake.NewClientBuilder().WithObjects(secret).Build())
+
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	status, err := r.Reconcile(t.Context(), cr, vars, nil)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+	assert.Equal(t, "postgres.example.com:5433", vars.DatabaseHost)
+
+	reachable = false
+
+	status, err = r.Reconcile(t.Context(), cr, vars, nil)
+	require.ErrorIs(t, err, ErrDatabaseUnreachable)
+	require.ErrorIs(t, err, ErrDatabaseUnavailable)
+	assert.Equal(t, v1beta1.OperatorStageResultFailed, status)
+
+	cr.Spec.Database.SkipConnectivityCheck = true
+
+	_, err = r.Reconcile(t.Context(), cr, vars, nil)
+	require.NoError(t, err)
+
+	cr.Spec.Database.SecretRef.Name = "missing"
+
+	_, err = r.Reconcile(t.Context(), cr, vars, nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrDatabaseUnavailable)
+}
@@ -3,7 +3,10 @@ package grafana
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/config"
@@ -11,8 +14,10 @@ import (
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,8 +34,39 @@ const (
 	ReadinessProbePeriodSeconds    int32 = 10
 	ReadinessProbeSuccessThreshold int32 = 1
 	ReadinessProbeTimeoutSeconds   int32 = 3
+	LivenessProbeFailureThreshold  int32 = 3
+	StartupProbeFailureThreshold   int32 = 30
 )
 
+// sizingProfile holds the container resources of a spec.sizing
+type sizingProfile struct {
+	cpuRequest    string
+	cpuLimit      string
+	memoryRequest string
+	memoryLimit   string
+}
+
+var sizingProfiles = map[v1beta1.GrafanaSizing]sizingProfile{
+	v1beta1.GrafanaSizingSmall: {
+		cpuRequest:    "100m",
+		cpuLimit:      "500m",
+		memoryRequest: "256Mi",
+		memoryLimit:   "512Mi",
+	},
+	v1beta1.GrafanaSizingMedium: {
+		cpuRequest:    "500m",
+		cpuLimit:      "2",
+		memoryRequest: "1Gi",
+		memoryLimit:   "2Gi",
+	},
+	v1beta1.GrafanaSizingLarge: {
+		cpuRequest:    "2",
+		cpuLimit:      "4",
+		memoryRequest: "4Gi",
+		memoryLimit:   "8Gi",
+	},
+}
+
 type DeploymentReconciler struct {
 	client      client.Client
 	isOpenShift bool
@@ -47,14 +83,40 @@ func (r *DeploymentReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafan
 	log := logf.FromContext(ctx).WithName("DeploymentReconciler")
 
 	openshiftPlatform := r.isOpenShift
+
+	tlsDatasources, err := r.getTLSDatasources(ctx, cr)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("listing datasources with tls secrets: %w", err)
+	}
+
+	if cr.IsStatefulSet() {
+		log.Info("reconciling statefulset", "openshift", openshiftPlatform)
+		return r.reconcileStatefulSet(ctx, cr, vars, scheme, tlsDatasources)
+	}
+
 	log.Info("reconciling deployment", "openshift", openshiftPlatform)
 
+	// Switching back from a StatefulSet, its pods release the data volume before the Deployment starts new ones
+	statefulSet := model.GetGrafanaStatefulSet(cr, nil)
+
+	retired, err := r.retireWorkload(ctx, cr, statefulSet)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	if !retired {
+		return v1beta1.OperatorStageResultInProgress, fmt.Errorf("waiting for statefulset %s to scale down", statefulSet.Name)
+	}
+
 	deployment := model.GetGrafanaDeployment(cr, scheme)
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.client, deployment, func() error {
-		deployment.Spec = getDeploymentSpec(cr, deployment.Name, scheme, vars, openshiftPlatform)
+	var changes []v1beta1.GrafanaHistoryEntry
 
-		err := v1beta1.Merge(deployment, cr.Spec.Deployment)
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, deployment, func() error {
+		live := deployment.Spec.DeepCopy()
+		deployment.Spec = getDeploymentSpec(cr, deployment.Name, scheme, vars, openshiftPlatform, tlsDatasources)
+
+		err := applyDeploymentOverrides(cr, deployment)
 		if err != nil {
 			setInvalidMergeCondition(cr, "Deployment", err)
 			return err
@@ -62,6 +124,19 @@ func (r *DeploymentReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafan
 
 		removeInvalidMergeCondition(cr, "Deployment")
 
+		// Writing the replicas would fight the HorizontalPodAutoscaler
+		if isAutoscalingEnabled(cr) {
+			deployment.Spec.Replicas = getAutoscaledReplicas(cr, live.Replicas)
+		}
+
+		keepServerDefaults(&deployment.Spec, live)
+
+		if detectDrift(cr, vars, deployment, "Deployment", live, &deployment.Spec) {
+			deployment.Spec = *live
+		}
+
+		changes = getRolloutChanges(live, &deployment.Spec)
+
 		if scheme != nil {
 			err = controllerutil.SetControllerReference(cr, deployment, scheme)
 			if err != nil {
@@ -70,6 +145,7 @@ func (r *DeploymentReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafan
 		}
 
 		model.SetInheritedLabels(deployment, cr.Labels)
+		model.SetOwnershipAnnotations(deployment, cr.Annotations)
 
 		return nil
 	})
@@ -77,10 +153,227 @@ func (r *DeploymentReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafan
 		return v1beta1.OperatorStageResultFailed, err
 	}
 
+	recordHistory(cr, changes, time.Now())
+
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
-func getResources() corev1.ResourceRequirements {
+// keepServerDefaults copies the fields the API server defaults from the live spec into the fields the desired spec
+// leaves unset. Otherwise both never compare equal and the Deployment is updated on every reconcile.
+// Live values are only kept when they are the default, values of removed overrides are reset to the default.
+// Replicas owned by a HorizontalPodAutoscaler are set by the caller
+func keepServerDefaults(desired, live *appsv1.DeploymentSpec) {
+	keepDefault(&desired.Replicas, live.Replicas, 1)
+	keepDefault(&desired.RevisionHistoryLimit, live.RevisionHistoryLimit, 10)
+	keepDefault(&desired.ProgressDeadlineSeconds, live.ProgressDeadlineSeconds, 600)
+
+	if live.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType && isDefaultRollingUpdate(live.Strategy.RollingUpdate) {
+		switch {
+		case desired.Strategy.Type == "":
+			desired.Strategy = live.Strategy
+		case desired.Strategy.Type == live.Strategy.Type && desired.Strategy.RollingUpdate == nil:
+			desired.Strategy.RollingUpdate = live.Strategy.RollingUpdate
+		}
+	}
+
+	keepPodServerDefaults(&desired.Template.Spec, &live.Template.Spec)
+}
+
+// keepDefault sets an unset desired field to the live value if the API server defaulted it to def
+func keepDefault[T comparable](desired **T, live *T, def T) {
+	if *desired == nil && live != nil && *live == def {
+		*desired = live
+	}
+}
+
+// keepDefaultValue is keepDefault for fields without pointer, unset is the zero value
+func keepDefaultValue[T comparable](desired *T, live T, def T) {
+	var zero T
+	if *desired == zero && live == def {
+		*desired = live
+	}
+}
+
+func isDefaultRollingUpdate(rollingUpdate *appsv1.RollingUpdateDeployment) bool {
+	defaultValue := intstr.FromString("25%")
+
+	return rollingUpdate != nil &&
+		rollingUpdate.MaxSurge != nil && *rollingUpdate.MaxSurge == defaultValue &&
+		rollingUpdate.MaxUnavailable != nil && *rollingUpdate.MaxUnavailable == defaultValue
+}
+
+func keepPodServerDefaults(desired, live *corev1.PodSpec) {
+	keepDefaultValue(&desired.RestartPolicy, live.RestartPolicy, corev1.RestartPolicyAlways)
+	keepDefault(&desired.TerminationGracePeriodSeconds, live.TerminationGracePeriodSeconds, corev1.DefaultTerminationGracePeriodSeconds)
+	keepDefaultValue(&desired.DNSPolicy, live.DNSPolicy, corev1.DNSClusterFirst)
+	keepDefaultValue(&desired.SchedulerName, live.SchedulerName, corev1.DefaultSchedulerName)
+
+	// An unset securityContext is defaulted to an empty one
+	if desired.SecurityContext == nil && live.SecurityContext != nil && equality.Semantic.DeepEqual(*live.SecurityContext, corev1.PodSecurityContext{}) {
+		desired.SecurityContext = live.SecurityContext
+	}
+
+	if desired.DeprecatedServiceAccount == "" && live.DeprecatedServiceAccount == desired.ServiceAccountName {
+		desired.DeprecatedServiceAccount = live.DeprecatedServiceAccount
+	}
+
+	for i := range desired.Volumes {
+		idx := slices.IndexFunc(live.Volumes, func(v corev1.Volume) bool { return v.Name == desired.Volumes[i].Name })
+		if idx >= 0 {
+			keepVolumeServerDefaults(&desired.Volumes[i], &live.Volumes[idx])
+		}
+	}
+
+	keepContainersServerDefaults(desired.InitContainers, live.InitContainers)
+	keepContainersServerDefaults(desired.Containers, live.Containers)
+}
+
+func keepContainersServerDefaults(desired, live []corev1.Container) {
+	for i := range desired {
+		idx := slices.IndexFunc(live, func(c corev1.Container) bool { return c.Name == desired[i].Name })
+		if idx >= 0 {
+			keepContainerServerDefaults(&desired[i], &live[idx])
+		}
+	}
+}
+
+func keepVolumeServerDefaults(desired, live *corev1.Volume) {
+	switch {
+	case desired.Secret != nil && live.Secret != nil:
+		keepDefault(&desired.Secret.DefaultMode, live.Secret.DefaultMode, corev1.SecretVolumeSourceDefaultMode)
+	case desired.ConfigMap != nil && live.ConfigMap != nil:
+		keepDefault(&desired.ConfigMap.DefaultMode, live.ConfigMap.DefaultMode, corev1.ConfigMapVolumeSourceDefaultMode)
+	case desired.Projected != nil && live.Projected != nil:
+		keepDefault(&desired.Projected.DefaultMode, live.Projected.DefaultMode, corev1.ProjectedVolumeSourceDefaultMode)
+	}
+}
+
+func keepContainerServerDefaults(desired, live *corev1.Container) {
+	keepDefaultValue(&desired.TerminationMessagePath, live.TerminationMessagePath, corev1.TerminationMessagePathDefault)
+	keepDefaultValue(&desired.TerminationMessagePolicy, live.TerminationMessagePolicy, corev1.TerminationMessageReadFile)
+	keepDefaultValue(&desired.ImagePullPolicy, live.ImagePullPolicy, defaultImagePullPolicy(desired.Image))
+
+	for i := range desired.Ports {
+		idx := slices.IndexFunc(live.Ports, func(p corev1.ContainerPort) bool { return p.ContainerPort == desired.Ports[i].ContainerPort })
+		if idx >= 0 {
+			keepDefaultValue(&desired.Ports[i].Protocol, live.Ports[idx].Protocol, corev1.ProtocolTCP)
+		}
+	}
+
+	for i := range desired.Env {
+		idx := slices.IndexFunc(live.Env, func(e corev1.EnvVar) bool { return e.Name == desired.Env[i].Name })
+		if idx < 0 || desired.Env[i].ValueFrom == nil || live.Env[idx].ValueFrom == nil {
+			continue
+		}
+
+		fieldRef, liveFieldRef := desired.Env[i].ValueFrom.FieldRef, live.Env[idx].ValueFrom.FieldRef
+		if fieldRef != nil && liveFieldRef != nil {
+			keepDefaultValue(&fieldRef.APIVersion, liveFieldRef.APIVersion, "v1")
+		}
+	}
+
+	// Requests default to the limits
+	for name, limit := range desired.Resources.Limits {
+		if _, ok := desired.Resources.Requests[name]; ok {
+			continue
+		}
+
+		if request, ok := live.Resources.Requests[name]; ok && request.Cmp(limit) == 0 {
+			if desired.Resources.Requests == nil {
+				desired.Resources.Requests = corev1.ResourceList{}
+			}
+
+			desired.Resources.Requests[name] = request
+		}
+	}
+
+	keepProbeServerDefaults(desired.LivenessProbe, live.LivenessProbe)
+	keepProbeServerDefaults(desired.ReadinessProbe, live.ReadinessProbe)
+	keepProbeServerDefaults(desired.StartupProbe, live.StartupProbe)
+}
+
+func keepProbeServerDefaults(desired, live *corev1.Probe) {
+	if desired == nil || live == nil {
+		return
+	}
+
+	keepDefaultValue(&desired.TimeoutSeconds, live.TimeoutSeconds, 1)
+	keepDefaultValue(&desired.PeriodSeconds, live.PeriodSeconds, 10)
+	keepDefaultValue(&desired.SuccessThreshold, live.SuccessThreshold, 1)
+	keepDefaultValue(&desired.FailureThreshold, live.FailureThreshold, 3)
+
+	if desired.HTTPGet != nil && live.HTTPGet != nil {
+		keepDefaultValue(&desired.HTTPGet.Scheme, live.HTTPGet.Scheme, corev1.URISchemeHTTP)
+	}
+}
+
+// defaultImagePullPolicy is the pull policy the API server defaults for the image, Always for the latest or no tag
+func defaultImagePullPolicy(image string) corev1.PullPolicy {
+	if strings.Contains(image, "@") {
+		return corev1.PullIfNotPresent
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+
+	tag := ""
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		tag = name[i+1:]
+	}
+
+	if tag == "" || tag == "latest" {
+		return corev1.PullAlways
+	}
+
+	return corev1.PullIfNotPresent
+}
+
+// getTLSDatasources returns the datasources in the namespace of the instance whose TLS Secret needs to be mounted
+func (r *DeploymentReconciler) getTLSDatasources(ctx context.Context, cr *v1beta1.Grafana) ([]v1beta1.GrafanaDatasource, error) {
+	var list v1beta1.GrafanaDatasourceList
+
+	err := r.client.List(ctx, &list, client.InNamespace(cr.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	datasources := make([]v1beta1.GrafanaDatasource, 0)
+
+	for _, ds := range list.Items {
+		if ds.Spec.TLS == nil || ds.Spec.InstanceSelector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(ds.Spec.InstanceSelector)
+		if err != nil || !selector.Matches(labels.Set(cr.Labels)) {
+			continue
+		}
+
+		datasources = append(datasources, ds)
+	}
+
+	// Keep the pod template stable regardless of the listing order
+	slices.SortFunc(datasources, func(a, b v1beta1.GrafanaDatasource) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return datasources, nil
+}
+
+// getResources returns the resources of spec.sizing, or the operator defaults without a curated sizing
+func getResources(cr *v1beta1.Grafana) corev1.ResourceRequirements {
+	if profile, ok := sizingProfiles[cr.Spec.Sizing]; ok {
+		return corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(profile.memoryRequest),
+				corev1.ResourceCPU:    resource.MustParse(profile.cpuRequest),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(profile.memoryLimit),
+				corev1.ResourceCPU:    resource.MustParse(profile.cpuLimit),
+			},
+		}
+	}
+
 	return corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
 			corev1.ResourceMemory: resource.MustParse(MemoryRequest),
@@ -92,7 +385,36 @@ func getResources() corev1.ResourceRequirements {
 	}
 }
 
-func getVolumes(cr *v1beta1.Grafana, scheme *runtime.Scheme) []corev1.Volume {
+// setGoMemLimit keeps the Go heap of the Grafana container of a curated sizing below its effective memory limit,
+// leaving 10% to non-heap memory. A GOMEMLIMIT set through spec.deployment is kept
+func setGoMemLimit(cr *v1beta1.Grafana, desired *appsv1.Deployment) {
+	if _, ok := sizingProfiles[cr.Spec.Sizing]; !ok {
+		return
+	}
+
+	containers := desired.Spec.Template.Spec.Containers
+
+	i := slices.IndexFunc(containers, func(c corev1.Container) bool { return c.Name == "grafana" })
+	if i < 0 {
+		return
+	}
+
+	limit, ok := containers[i].Resources.Limits[corev1.ResourceMemory]
+	if !ok || limit.IsZero() {
+		return
+	}
+
+	if slices.ContainsFunc(containers[i].Env, func(env corev1.EnvVar) bool { return env.Name == "GOMEMLIMIT" }) {
+		return
+	}
+
+	containers[i].Env = append(containers[i].Env, corev1.EnvVar{
+		Name:  "GOMEMLIMIT",
+		Value: fmt.Sprintf("%dMiB", limit.Value()*9/10/(1<<20)),
+	})
+}
+
+func getVolumes(cr *v1beta1.Grafana, scheme *runtime.Scheme, tlsDatasources []v1beta1.GrafanaDatasource) []corev1.Volume {
 	var volumes []corev1.Volume
 
 	cm := model.GetGrafanaConfigMap(cr, scheme)
@@ -124,10 +446,54 @@ func getVolumes(cr *v1beta1.Grafana, scheme *runtime.Scheme) []corev1.Volume {
 		},
 	})
 
+	// Volume holding the service account key of the GCS external image storage
+	if keyFile := getGCSKeyFile(cr); keyFile != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: config.GrafanaImageStorageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: keyFile.Name,
+					Items: []corev1.KeyToPath{{
+						Key:  keyFile.Key,
+						Path: config.GrafanaGCSKeyFileName,
+					}},
+				},
+			},
+		})
+	}
+
+	if isImageRendererSidecar(cr) {
+		volumes = append(volumes, getImageRendererVolume())
+	}
+
+	// Volume holding the certificate issued by the OpenShift service CA
+	if cr.UsesServingCert() {
+		volumes = append(volumes, corev1.Volume{
+			Name: config.GrafanaServingCertVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: model.GetGrafanaServingCertSecret(cr).Name,
+				},
+			},
+		})
+	}
+
+	// Volumes holding TLS material of SQL datasources
+	for _, ds := range tlsDatasources {
+		volumes = append(volumes, corev1.Volume{
+			Name: model.GetDatasourceTLSVolumeName(&ds),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: ds.Spec.TLS.SecretName,
+				},
+			},
+		})
+	}
+
 	return volumes
 }
 
-func getVolumeMounts(cr *v1beta1.Grafana, scheme *runtime.Scheme) []corev1.VolumeMount {
+func getVolumeMounts(cr *v1beta1.Grafana, scheme *runtime.Scheme, tlsDatasources []v1beta1.GrafanaDatasource) []corev1.VolumeMount {
 	var mounts []corev1.VolumeMount
 
 	cm := model.GetGrafanaConfigMap(cr, scheme)
@@ -148,9 +514,42 @@ func getVolumeMounts(cr *v1beta1.Grafana, scheme *runtime.Scheme) []corev1.Volum
 		MountPath: config.GrafanaLogsPath,
 	})
 
+	if getGCSKeyFile(cr) != nil {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      config.GrafanaImageStorageVolumeName,
+			MountPath: config.GrafanaImageStoragePath,
+			ReadOnly:  true,
+		})
+	}
+
+	if cr.UsesServingCert() {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      config.GrafanaServingCertVolumeName,
+			MountPath: config.GrafanaServingCertPath,
+			ReadOnly:  true,
+		})
+	}
+
+	for _, ds := range tlsDatasources {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      model.GetDatasourceTLSVolumeName(&ds),
+			MountPath: model.GetDatasourceTLSMountPath(&ds),
+			ReadOnly:  true,
+		})
+	}
+
 	return mounts
 }
 
+// getGCSKeyFile returns the Secret key holding the service account key of the GCS external image storage
+func getGCSKeyFile(cr *v1beta1.Grafana) *corev1.SecretKeySelector {
+	if cr.Spec.ExternalImageStorage == nil || cr.Spec.ExternalImageStorage.GCS == nil {
+		return nil
+	}
+
+	return cr.Spec.ExternalImageStorage.GCS.KeyFile
+}
+
 func getGrafanaImage(cr *v1beta1.Grafana) string {
 	if cr.Spec.Version == "" {
 		return fmt.Sprintf("%s:%s", config.GrafanaImage, config.GrafanaVersion)
@@ -163,7 +562,7 @@ func getGrafanaImage(cr *v1beta1.Grafana) string {
 	return fmt.Sprintf("%s:%s", config.GrafanaImage, cr.Spec.Version)
 }
 
-func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.OperatorReconcileVars, openshiftPlatform bool) []corev1.Container {
+func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.OperatorReconcileVars, openshiftPlatform bool, tlsDatasources []v1beta1.GrafanaDatasource) []corev1.Container {
 	var containers []corev1.Container
 
 	image := getGrafanaImage(cr)
@@ -182,6 +581,14 @@ func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.Op
 		Value: vars.Plugins,
 	})
 
+	// env var to load the declared plugins of spec.plugins.unsignedAllowList without a valid signature
+	if cr.Spec.Plugins != nil && vars.UnsignedPlugins != "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "GF_PLUGINS_ALLOW_LOADING_UNSIGNED_PLUGINS",
+			Value: vars.UnsignedPlugins,
+		})
+	}
+
 	// env var to set location where temporary files can be written (e.g. plugin downloads)
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "TMPDIR",
@@ -198,6 +605,25 @@ func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.Op
 		},
 	})
 
+	// credentials of the external image storage
+	envVars = append(envVars, getImageStorageEnvVars(cr)...)
+
+	// location of the image renderer
+	envVars = append(envVars, getImageRendererEnvVars(cr)...)
+
+	// connection of the database
+	envVars = append(envVars, getDatabaseEnvVars(cr, vars.DatabaseHost)...)
+
+	// password of the remote cache, interpolated into the ini by Grafana
+	if cache := cr.Spec.RemoteCache; cache != nil && cache.Password != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: config.GrafanaRemoteCachePasswordEnv,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: cache.Password,
+			},
+		})
+	}
+
 	containers = append(containers, corev1.Container{
 		Name:       "grafana",
 		Image:      image,
@@ -216,13 +642,15 @@ func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.Op
 			},
 		},
 		Env:                      envVars,
-		Resources:                getResources(),
-		VolumeMounts:             getVolumeMounts(cr, scheme),
+		Resources:                getResources(cr),
+		VolumeMounts:             getVolumeMounts(cr, scheme, tlsDatasources),
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: "File",
 		ImagePullPolicy:          "IfNotPresent",
 		SecurityContext:          getDefaultContainerSecurityContext(cr.Spec.DisableDefaultSecurityContext, openshiftPlatform),
 		ReadinessProbe:           getReadinessProbe(cr),
+		LivenessProbe:            getLivenessProbe(cr),
+		StartupProbe:             getStartupProbe(cr),
 	})
 
 	// Use auto generated admin account?
@@ -253,9 +681,47 @@ func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.Op
 		})
 	}
 
+	// The renderer sidecar is added after the admin credentials, it doesn't need them
+	if isImageRendererSidecar(cr) {
+		containers = append(containers, getImageRendererContainer(cr, openshiftPlatform))
+	}
+
 	return containers
 }
 
+// getImageStorageEnvVars returns the env vars passing the credentials of spec.externalImageStorage to Grafana
+func getImageStorageEnvVars(cr *v1beta1.Grafana) []corev1.EnvVar {
+	storage := cr.Spec.ExternalImageStorage
+	if storage == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+
+	fromSecret := func(name string, ref *corev1.SecretKeySelector) {
+		if ref == nil {
+			return
+		}
+
+		envVars = append(envVars, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: ref,
+			},
+		})
+	}
+
+	switch {
+	case storage.S3 != nil:
+		fromSecret("GF_EXTERNAL_IMAGE_STORAGE_S3_ACCESS_KEY", storage.S3.AccessKey)
+		fromSecret("GF_EXTERNAL_IMAGE_STORAGE_S3_SECRET_KEY", storage.S3.SecretKey)
+	case storage.AzureBlob != nil:
+		fromSecret("GF_EXTERNAL_IMAGE_STORAGE_AZURE_BLOB_ACCOUNT_KEY", &storage.AzureBlob.AccountKey)
+	}
+
+	return envVars
+}
+
 // getDefaultContainerSecurityContext provides securityContext for grafana container unless disabled
 func getDefaultContainerSecurityContext(disableSecurityContext string, openshiftPlatform bool) *corev1.SecurityContext {
 	if disableSecurityContext == "Container" || disableSecurityContext == "All" {
@@ -289,18 +755,138 @@ func getDefaultContainerSecurityContext(disableSecurityContext string, openshift
 }
 
 func getReadinessProbe(cr *v1beta1.Grafana) *corev1.Probe {
-	return &corev1.Probe{
+	var settings *v1beta1.GrafanaProbe
+	if cr.Spec.Probes != nil {
+		settings = cr.Spec.Probes.Readiness
+	}
+
+	return getProbe(cr, settings, ReadinessProbeFailureThreshold)
+}
+
+// getLivenessProbe returns the liveness probe of spec.probes.liveness, nil when not set
+func getLivenessProbe(cr *v1beta1.Grafana) *corev1.Probe {
+	if cr.Spec.Probes == nil || cr.Spec.Probes.Liveness == nil {
+		return nil
+	}
+
+	return getProbe(cr, cr.Spec.Probes.Liveness, LivenessProbeFailureThreshold)
+}
+
+// getStartupProbe returns the startup probe of spec.probes.startup, nil when not set
+func getStartupProbe(cr *v1beta1.Grafana) *corev1.Probe {
+	if cr.Spec.Probes == nil || cr.Spec.Probes.Startup == nil {
+		return nil
+	}
+
+	return getProbe(cr, cr.Spec.Probes.Startup, StartupProbeFailureThreshold)
+}
+
+// getProbe returns a probe of the health endpoint with the settings of spec.probes applied over the defaults
+func getProbe(cr *v1beta1.Grafana, settings *v1beta1.GrafanaProbe, failureThreshold int32) *corev1.Probe {
+	if settings == nil {
+		settings = &v1beta1.GrafanaProbe{}
+	}
+
+	path := GrafanaHealthEndpoint
+	if settings.Path != "" {
+		path = settings.Path
+	}
+
+	probe := &corev1.Probe{
 		ProbeHandler: corev1.ProbeHandler{
 			HTTPGet: &corev1.HTTPGetAction{
-				Path:   GrafanaHealthEndpoint,
+				Path:   getGrafanaSubPath(cr) + path,
 				Port:   intstr.FromInt(GetGrafanaPort(cr)),
-				Scheme: corev1.URISchemeHTTP,
+				Scheme: corev1.URIScheme(strings.ToUpper(getGrafanaServerScheme(cr))),
 			},
 		},
-		TimeoutSeconds:   ReadinessProbeTimeoutSeconds,
-		PeriodSeconds:    ReadinessProbePeriodSeconds,
-		SuccessThreshold: ReadinessProbeSuccessThreshold,
-		FailureThreshold: ReadinessProbeFailureThreshold,
+		InitialDelaySeconds: settings.InitialDelaySeconds,
+		TimeoutSeconds:      ReadinessProbeTimeoutSeconds,
+		PeriodSeconds:       ReadinessProbePeriodSeconds,
+		SuccessThreshold:    ReadinessProbeSuccessThreshold,
+		FailureThreshold:    failureThreshold,
+	}
+
+	if settings.TimeoutSeconds != 0 {
+		probe.TimeoutSeconds = settings.TimeoutSeconds
+	}
+
+	if settings.PeriodSeconds != 0 {
+		probe.PeriodSeconds = settings.PeriodSeconds
+	}
+
+	if settings.FailureThreshold != 0 {
+		probe.FailureThreshold = settings.FailureThreshold
+	}
+
+	return probe
+}
+
+// getGrafanaSubPath returns the path of server.root_url without trailing slash when Grafana serves from it
+// with server.serve_from_sub_path, empty otherwise. root_url may contain %(...)s placeholders, so it isn't parsed as url
+func getGrafanaSubPath(cr *v1beta1.Grafana) string {
+	if cr.GetConfigSectionValue("server", "serve_from_sub_path") != "true" {
+		return ""
+	}
+
+	_, rest, found := strings.Cut(cr.GetConfigSectionValue("server", "root_url"), "://")
+	if !found {
+		return ""
+	}
+
+	_, path, found := strings.Cut(rest, "/")
+	if !found {
+		return ""
+	}
+
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return ""
+	}
+
+	return "/" + path
+}
+
+// getMeshAnnotations returns the pod annotations configuring the sidecar of spec.mesh
+func getMeshAnnotations(cr *v1beta1.Grafana) map[string]string {
+	mesh := cr.Spec.Mesh
+	if mesh == nil {
+		return nil
+	}
+
+	hold := mesh.HoldApplicationUntilProxyStarts == nil || *mesh.HoldApplicationUntilProxyStarts
+
+	inbound := []string{strconv.Itoa(config.GrafanaAlertPort)}
+	if mesh.ExcludeHTTPPort {
+		inbound = append([]string{strconv.Itoa(GetGrafanaPort(cr))}, inbound...)
+	}
+
+	outbound := []string{strconv.Itoa(config.GrafanaAlertPort)}
+	for _, port := range mesh.ExcludeOutboundPorts {
+		outbound = append(outbound, strconv.Itoa(int(port)))
+	}
+
+	switch mesh.Provider {
+	case v1beta1.GrafanaMeshIstio:
+		return map[string]string{
+			"proxy.istio.io/config":                         fmt.Sprintf(`{"holdApplicationUntilProxyStarts": %t}`, hold),
+			"sidecar.istio.io/rewriteAppHTTPProbers":        "true",
+			"traffic.sidecar.istio.io/excludeInboundPorts":  strings.Join(inbound, ","),
+			"traffic.sidecar.istio.io/excludeOutboundPorts": strings.Join(outbound, ","),
+		}
+	case v1beta1.GrafanaMeshLinkerd:
+		proxyAwait := "disabled"
+		if hold {
+			proxyAwait = "enabled"
+		}
+
+		return map[string]string{
+			"config.linkerd.io/proxy-await":         proxyAwait,
+			"config.linkerd.io/skip-inbound-ports":  strings.Join(inbound, ","),
+			"config.linkerd.io/skip-outbound-ports": strings.Join(outbound, ","),
+		}
+	default:
+		return nil
 	}
 }
 
@@ -317,7 +903,7 @@ func getDefaultPodSecurityContext(disableSecurityContext string) *corev1.PodSecu
 	}
 }
 
-func getDeploymentSpec(cr *v1beta1.Grafana, deploymentName string, scheme *runtime.Scheme, vars *v1beta1.OperatorReconcileVars, openshiftPlatform bool) appsv1.DeploymentSpec {
+func getDeploymentSpec(cr *v1beta1.Grafana, deploymentName string, scheme *runtime.Scheme, vars *v1beta1.OperatorReconcileVars, openshiftPlatform bool, tlsDatasources []v1beta1.GrafanaDatasource) appsv1.DeploymentSpec {
 	sa := model.GetGrafanaServiceAccount(cr, scheme)
 
 	return appsv1.DeploymentSpec{
@@ -332,10 +918,11 @@ func getDeploymentSpec(cr *v1beta1.Grafana, deploymentName string, scheme *runti
 				Labels: map[string]string{
 					"app": cr.Name,
 				},
+				Annotations: getMeshAnnotations(cr),
 			},
 			Spec: corev1.PodSpec{
-				Volumes:            getVolumes(cr, scheme),
-				Containers:         getContainers(cr, scheme, vars, openshiftPlatform),
+				Volumes:            getVolumes(cr, scheme, tlsDatasources),
+				Containers:         getContainers(cr, scheme, vars, openshiftPlatform, tlsDatasources),
 				SecurityContext:    getDefaultPodSecurityContext(cr.Spec.DisableDefaultSecurityContext),
 				ServiceAccountName: sa.Name,
 			},
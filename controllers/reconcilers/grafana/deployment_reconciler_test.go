@@ -2,12 +2,24 @@ package grafana
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 )
 
 func TestGetGrafanaImage(t *testing.T) {
@@ -47,3 +59,473 @@ func TestGetGrafanaImage(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDatasourceTLSVolumes(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana",
+			Namespace: "monitoring",
+		},
+	}
+
+	datasources := []v1beta1.GrafanaDatasource{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "db.primary",
+				Namespace: "monitoring",
+			},
+			Spec: v1beta1.GrafanaDatasourceSpec{
+				TLS: &v1beta1.GrafanaDatasourceTLS{SecretName: "db-tls"},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	volumes := getVolumes(cr, scheme, datasources)
+	mounts := getVolumeMounts(cr, scheme, datasources)
+
+	volume := volumes[len(volumes)-1]
+	assert.Equal(t, model.GetDatasourceTLSVolumeName(&datasources[0]), volume.Name)
+	assert.True(t, strings.HasPrefix(volume.Name, "datasource-tls-db-primary-"))
+	require.NotNil(t, volume.Secret)
+	assert.Equal(t, "db-tls", volume.Secret.SecretName)
+
+	mount := mounts[len(mounts)-1]
+	assert.Equal(t, volume.Name, mount.Name)
+	assert.Equal(t, "/etc/grafana-secrets/datasources/db.primary", mount.MountPath)
+	assert.True(t, mount.ReadOnly)
+}
+
+func TestGetMeshAnnotations(t *testing.T) {
+	t.Run("no mesh", func(t *testing.T) {
+		assert.Nil(t, getMeshAnnotations(&v1beta1.Grafana{}))
+	})
+
+	t.Run("istio", func(t *testing.T) {
+		cr := &v1beta1.Grafana{
+			Spec: v1beta1.GrafanaSpec{
+				Mesh: &v1beta1.GrafanaMesh{
+					Provider:             v1beta1.GrafanaMeshIstio,
+					ExcludeHTTPPort:      true,
+					ExcludeOutboundPorts: []int32{5432},
+				},
+			},
+		}
+
+		assert.Equal(t, map[string]string{
+			"proxy.istio.io/config":                         `{"holdApplicationUntilProxyStarts": true}`,
+			"sidecar.istio.io/rewriteAppHTTPProbers":        "true",
+			"traffic.sidecar.istio.io/excludeInboundPorts":  "3000,9094",
+			"traffic.sidecar.istio.io/excludeOutboundPorts": "9094,5432",
+		}, getMeshAnnotations(cr))
+	})
+
+	t.Run("linkerd", func(t *testing.T) {
+		cr := &v1beta1.Grafana{
+			Spec: v1beta1.GrafanaSpec{
+				Mesh: &v1beta1.GrafanaMesh{
+					Provider:                        v1beta1.GrafanaMeshLinkerd,
+					HoldApplicationUntilProxyStarts: model.BoolPtr(false),
+				},
+			},
+		}
+
+		assert.Equal(t, map[string]string{
+			"config.linkerd.io/proxy-await":         "disabled",
+			"config.linkerd.io/skip-inbound-ports":  "9094",
+			"config.linkerd.io/skip-outbound-ports": "9094",
+		}, getMeshAnnotations(cr))
+	})
+}
+
+func TestGetReadinessProbeScheme(t *testing.T) {
+	cr := &v1beta1.Grafana{}
+	assert.Equal(t, corev1.URISchemeHTTP, getReadinessProbe(cr).HTTPGet.Scheme)
+
+	cr.Spec.Config = map[string]map[string]string{"server": {"protocol": "https"}}
+	assert.Equal(t, corev1.URISchemeHTTPS, getReadinessProbe(cr).HTTPGet.Scheme)
+}
+
+func TestGetProbes(t *testing.T) {
+	cr := &v1beta1.Grafana{}
+	assert.Nil(t, getLivenessProbe(cr))
+	assert.Nil(t, getStartupProbe(cr))
+	assert.Equal(t, ReadinessProbeFailureThreshold, getReadinessProbe(cr).FailureThreshold)
+
+	cr.Spec.Config = map[string]map[string]string{"server": {
+		"protocol":            "https",
+		"root_url":            "%(protocol)s://%(domain)s:%(http_port)s/grafana/",
+		"serve_from_sub_path": "true",
+	}}
+	cr.Spec.Probes = &v1beta1.GrafanaProbes{
+		Startup:   &v1beta1.GrafanaProbe{PeriodSeconds: 5, FailureThreshold: 60},
+		Readiness: &v1beta1.GrafanaProbe{TimeoutSeconds: 10},
+		Liveness:  &v1beta1.GrafanaProbe{Path: "/healthz", InitialDelaySeconds: 30},
+	}
+
+	startup := getStartupProbe(cr)
+	require.NotNil(t, startup)
+	assert.Equal(t, "/grafana/api/health", startup.HTTPGet.Path)
+	assert.Equal(t, corev1.URISchemeHTTPS, startup.HTTPGet.Scheme)
+	assert.Equal(t, int32(5), startup.PeriodSeconds)
+	assert.Equal(t, int32(60), startup.FailureThreshold)
+
+	readiness := getReadinessProbe(cr)
+	assert.Equal(t, int32(10), readiness.TimeoutSeconds)
+	assert.Equal(t, ReadinessProbePeriodSeconds, readiness.PeriodSeconds)
+	assert.Equal(t, ReadinessProbeFailureThreshold, readiness.FailureThreshold)
+
+	liveness := getLivenessProbe(cr)
+	require.NotNil(t, liveness)
+	assert.Equal(t, "/grafana/healthz", liveness.HTTPGet.Path)
+	assert.Equal(t, int32(30), liveness.InitialDelaySeconds)
+	assert.Equal(t, LivenessProbeFailureThreshold, liveness.FailureThreshold)
+}
+
+func TestGetGrafanaSubPath(t *testing.T) {
+	for rootURL, want := range map[string]string{
+		"https://grafana.example.com/":            "",
+		"https://grafana.example.com":             "",
+		"https://example.com/monitoring/grafana/": "/monitoring/grafana",
+		"%(protocol)s://%(domain)s/grafana":       "/grafana",
+	} {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Config: map[string]map[string]string{
+			"server": {"root_url": rootURL, "serve_from_sub_path": "true"},
+		}}}
+		assert.Equal(t, want, getGrafanaSubPath(cr), rootURL)
+	}
+
+	cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Config: map[string]map[string]string{
+		"server": {"root_url": "https://example.com/grafana/"},
+	}}}
+	assert.Empty(t, getGrafanaSubPath(cr), "served from the root without serve_from_sub_path")
+}
+
+// applyServerDefaults sets the defaults the API server applies to the fields left unset by getDeploymentSpec
+func applyServerDefaults(spec *appsv1.DeploymentSpec) {
+	spec.Replicas = ptr.To[int32](1)
+	spec.RevisionHistoryLimit = ptr.To[int32](10)
+	spec.ProgressDeadlineSeconds = ptr.To[int32](600)
+
+	maxSurge := intstr.FromString("25%")
+	spec.Strategy = appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxSurge},
+	}
+
+	pod := &spec.Template.Spec
+	pod.RestartPolicy = corev1.RestartPolicyAlways
+	pod.TerminationGracePeriodSeconds = ptr.To[int64](30)
+	pod.DNSPolicy = corev1.DNSClusterFirst
+	pod.SchedulerName = corev1.DefaultSchedulerName
+	pod.DeprecatedServiceAccount = pod.ServiceAccountName
+
+	for i := range pod.Volumes {
+		if pod.Volumes[i].ConfigMap != nil {
+			pod.Volumes[i].ConfigMap.DefaultMode = ptr.To[int32](420)
+		}
+	}
+
+	for i := range pod.Containers {
+		c := &pod.Containers[i]
+		c.Resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+		c.Resources.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+
+		for j := range c.Env {
+			if c.Env[j].ValueFrom != nil && c.Env[j].ValueFrom.FieldRef != nil {
+				c.Env[j].ValueFrom.FieldRef.APIVersion = "v1"
+			}
+		}
+	}
+}
+
+func TestKeepServerDefaults(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cr := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	newSpec := func() appsv1.DeploymentSpec {
+		spec := getDeploymentSpec(cr, "grafana-deployment", scheme, vars, false, nil)
+		spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceEphemeralStorage] = resource.MustParse("1Gi")
+
+		return spec
+	}
+
+	live := newSpec()
+	applyServerDefaults(&live)
+
+	t.Run("defaulted fields are no change", func(t *testing.T) {
+		desired := newSpec()
+		require.False(t, equality.Semantic.DeepEqual(desired, live))
+
+		keepServerDefaults(&desired, &live)
+		assert.True(t, equality.Semantic.DeepEqual(desired, live))
+	})
+
+	t.Run("fields set by the operator win", func(t *testing.T) {
+		desired := newSpec()
+		desired.Replicas = ptr.To[int32](2)
+		desired.Template.Spec.Containers[0].ReadinessProbe.PeriodSeconds = 30
+
+		keepServerDefaults(&desired, &live)
+		assert.Equal(t, int32(2), *desired.Replicas)
+		assert.Equal(t, int32(30), desired.Template.Spec.Containers[0].ReadinessProbe.PeriodSeconds)
+		assert.False(t, equality.Semantic.DeepEqual(desired, live))
+	})
+
+	t.Run("disabled pod security context is removed", func(t *testing.T) {
+		live := newSpec()
+		applyServerDefaults(&live)
+		require.NotNil(t, live.Template.Spec.SecurityContext.SeccompProfile)
+
+		disabled := cr.DeepCopy()
+		disabled.Spec.DisableDefaultSecurityContext = "Pod"
+		desired := getDeploymentSpec(disabled, "grafana-deployment", scheme, vars, false, nil)
+
+		keepServerDefaults(&desired, &live)
+		assert.Nil(t, desired.Template.Spec.SecurityContext)
+
+		// The empty securityContext the API server defaults is no change
+		live.Template.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		desired = getDeploymentSpec(disabled, "grafana-deployment", scheme, vars, false, nil)
+
+		keepServerDefaults(&desired, &live)
+		assert.Equal(t, &corev1.PodSecurityContext{}, desired.Template.Spec.SecurityContext)
+	})
+
+	t.Run("removed replicas are reset", func(t *testing.T) {
+		live := newSpec()
+		applyServerDefaults(&live)
+		live.Replicas = ptr.To[int32](3)
+
+		desired := newSpec()
+		keepServerDefaults(&desired, &live)
+		assert.Nil(t, desired.Replicas)
+	})
+
+	t.Run("removed strategy is reset", func(t *testing.T) {
+		live := newSpec()
+		applyServerDefaults(&live)
+		live.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+
+		desired := newSpec()
+		keepServerDefaults(&desired, &live)
+		assert.Empty(t, desired.Strategy.Type)
+
+		maxSurge := intstr.FromInt32(2)
+		live.Strategy = appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &maxSurge, MaxUnavailable: &maxSurge},
+		}
+
+		desired = newSpec()
+		keepServerDefaults(&desired, &live)
+		assert.Empty(t, desired.Strategy.Type)
+		assert.Nil(t, desired.Strategy.RollingUpdate)
+	})
+}
+
+func TestDefaultImagePullPolicy(t *testing.T) {
+	assert.Equal(t, corev1.PullAlways, defaultImagePullPolicy("grafana/grafana"))
+	assert.Equal(t, corev1.PullAlways, defaultImagePullPolicy("registry:5000/grafana/grafana:latest"))
+	assert.Equal(t, corev1.PullIfNotPresent, defaultImagePullPolicy("registry:5000/grafana/grafana:12.1.0"))
+	assert.Equal(t, corev1.PullIfNotPresent, defaultImagePullPolicy("grafana/grafana@sha256:abc"))
+}
+
+func TestGetContainersSizing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	goMemLimit := func(c corev1.Container) string {
+		for _, env := range c.Env {
+			if env.Name == "GOMEMLIMIT" {
+				return env.Value
+			}
+		}
+
+		return ""
+	}
+
+	t.Run("operator defaults without sizing", func(t *testing.T) {
+		for _, sizing := range []v1beta1.GrafanaSizing{"", v1beta1.GrafanaSizingCustom} {
+			cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Sizing: sizing}}
+
+			grafana := getContainers(cr, scheme, vars, false, nil)[0]
+
+			assert.Equal(t, resource.MustParse(MemoryLimit), grafana.Resources.Limits[corev1.ResourceMemory])
+			assert.NotContains(t, grafana.Resources.Limits, corev1.ResourceCPU)
+			assert.Empty(t, goMemLimit(grafana))
+		}
+	})
+
+	t.Run("medium", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Sizing: v1beta1.GrafanaSizingMedium}}
+
+		grafana := getContainers(cr, scheme, vars, false, nil)[0]
+
+		assert.Equal(t, resource.MustParse("500m"), grafana.Resources.Requests[corev1.ResourceCPU])
+		assert.Equal(t, resource.MustParse("2Gi"), grafana.Resources.Limits[corev1.ResourceMemory])
+	})
+
+	gomemlimitOf := func(cr *v1beta1.Grafana) string {
+		desired := &appsv1.Deployment{Spec: getDeploymentSpec(cr, "grafana-deployment", scheme, vars, false, nil)}
+		require.NoError(t, applyDeploymentOverrides(cr, desired))
+
+		return goMemLimit(desired.Spec.Template.Spec.Containers[0])
+	}
+
+	overrides := func(grafana corev1.Container) *v1beta1.DeploymentV1 {
+		return &v1beta1.DeploymentV1{
+			Spec: v1beta1.DeploymentV1Spec{
+				Template: &v1beta1.DeploymentV1PodTemplateSpec{
+					Spec: &v1beta1.DeploymentV1PodSpec{Containers: []corev1.Container{grafana}},
+				},
+			},
+		}
+	}
+
+	t.Run("gomemlimit of the sizing", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Sizing: v1beta1.GrafanaSizingMedium}}
+
+		assert.Equal(t, "1843MiB", gomemlimitOf(cr))
+	})
+
+	t.Run("gomemlimit of an overridden memory limit", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{
+			Sizing: v1beta1.GrafanaSizingMedium,
+			Deployment: overrides(corev1.Container{
+				Name:      "grafana",
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3Gi")}},
+			}),
+		}}
+
+		assert.Equal(t, "2764MiB", gomemlimitOf(cr))
+	})
+
+	t.Run("overridden gomemlimit", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{
+			Sizing:     v1beta1.GrafanaSizingMedium,
+			Deployment: overrides(corev1.Container{Name: "grafana", Env: []corev1.EnvVar{{Name: "GOMEMLIMIT", Value: "1GiB"}}}),
+		}}
+
+		assert.Equal(t, "1GiB", gomemlimitOf(cr))
+	})
+
+	t.Run("no gomemlimit without sizing", func(t *testing.T) {
+		assert.Empty(t, gomemlimitOf(&v1beta1.Grafana{}))
+	})
+}
+
+func TestGetImageStorageEnvVars(t *testing.T) {
+	secret := func(key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "image-store"}, Key: key}
+	}
+
+	t.Run("no storage", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{AlertScreenshots: &v1beta1.GrafanaAlertScreenshots{Capture: ptr.To(true)}}}
+
+		assert.Empty(t, getImageStorageEnvVars(cr))
+	})
+
+	t.Run("s3 without credentials", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{ExternalImageStorage: &v1beta1.GrafanaExternalImageStorage{
+			S3: &v1beta1.GrafanaExternalImageStorageS3{Bucket: "screenshots", Region: "eu-west-1"},
+		}}}
+
+		assert.Empty(t, getImageStorageEnvVars(cr))
+	})
+
+	t.Run("s3 credentials", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{ExternalImageStorage: &v1beta1.GrafanaExternalImageStorage{
+			S3: &v1beta1.GrafanaExternalImageStorageS3{
+				Bucket:    "screenshots",
+				Region:    "eu-west-1",
+				AccessKey: secret("access-key"),
+				SecretKey: secret("secret-key"),
+			},
+		}}}
+
+		envVars := getImageStorageEnvVars(cr)
+
+		require.Len(t, envVars, 2)
+		assert.Equal(t, "GF_EXTERNAL_IMAGE_STORAGE_S3_ACCESS_KEY", envVars[0].Name)
+		assert.Equal(t, "access-key", envVars[0].ValueFrom.SecretKeyRef.Key)
+		assert.Equal(t, "GF_EXTERNAL_IMAGE_STORAGE_S3_SECRET_KEY", envVars[1].Name)
+	})
+
+	t.Run("azure blob", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{ExternalImageStorage: &v1beta1.GrafanaExternalImageStorage{
+			AzureBlob: &v1beta1.GrafanaExternalImageStorageAzureBlob{
+				AccountName:   "grafana",
+				ContainerName: "screenshots",
+				AccountKey:    *secret("account-key"),
+			},
+		}}}
+
+		envVars := getImageStorageEnvVars(cr)
+
+		require.Len(t, envVars, 1)
+		assert.Equal(t, "GF_EXTERNAL_IMAGE_STORAGE_AZURE_BLOB_ACCOUNT_KEY", envVars[0].Name)
+		assert.Equal(t, "account-key", envVars[0].ValueFrom.SecretKeyRef.Key)
+	})
+}
+
+func TestGetVolumesGCSKeyFile(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{ExternalImageStorage: &v1beta1.GrafanaExternalImageStorage{
+			GCS: &v1beta1.GrafanaExternalImageStorageGCS{
+				Bucket:  "screenshots",
+				KeyFile: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "gcs"}, Key: "key.json"},
+			},
+		}},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	isKeyFileVolume := func(v corev1.Volume) bool { return v.Name == config.GrafanaImageStorageVolumeName }
+
+	volumes := getVolumes(cr, scheme, nil)
+	idx := slices.IndexFunc(volumes, isKeyFileVolume)
+	require.GreaterOrEqual(t, idx, 0)
+	assert.Equal(t, "gcs", volumes[idx].Secret.SecretName)
+	assert.Equal(t, []corev1.KeyToPath{{Key: "key.json", Path: config.GrafanaGCSKeyFileName}}, volumes[idx].Secret.Items)
+
+	mounts := getVolumeMounts(cr, scheme, nil)
+	idx = slices.IndexFunc(mounts, func(m corev1.VolumeMount) bool { return m.Name == config.GrafanaImageStorageVolumeName })
+	require.GreaterOrEqual(t, idx, 0)
+	assert.Equal(t, config.GrafanaImageStoragePath, mounts[idx].MountPath)
+
+	cr.Spec.ExternalImageStorage.GCS.KeyFile = nil
+	assert.False(t, slices.ContainsFunc(getVolumes(cr, scheme, nil), isKeyFileVolume))
+}
+
+func TestSetOwnershipAnnotationsRemovesStale(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"user": "kept"}}}
+
+	model.SetOwnershipAnnotations(deployment, map[string]string{
+		model.OwnershipTeamKey:       "platform",
+		"backstage.io/kubernetes-id": "grafana",
+		"other":                      "ignored",
+	})
+
+	assert.Equal(t, "platform", deployment.Annotations[model.OwnershipTeamKey])
+	assert.Equal(t, "grafana", deployment.Annotations["backstage.io/kubernetes-id"])
+	assert.NotContains(t, deployment.Annotations, "other")
+
+	model.SetOwnershipAnnotations(deployment, map[string]string{model.OwnershipTeamKey: "observability"})
+
+	assert.Equal(t, "observability", deployment.Annotations[model.OwnershipTeamKey])
+	assert.NotContains(t, deployment.Annotations, "backstage.io/kubernetes-id")
+
+	model.SetOwnershipAnnotations(deployment, nil)
+
+	assert.Equal(t, map[string]string{"user": "kept"}, deployment.Annotations)
+}
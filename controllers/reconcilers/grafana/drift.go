@@ -0,0 +1,128 @@
+package grafana
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// appliedSpecHashAnnotation holds the hash of the spec the operator last rendered for a workload, a live spec
+	// differing from an unchanged render was modified out-of-band
+	appliedSpecHashAnnotation = "operator.grafana.com/applied-spec-hash"
+
+	// maxDriftPaths bounds the fields listed per object in the WorkloadDrift condition
+	maxDriftPaths = 10
+)
+
+// detectDrift compares the live spec of a workload with the desired spec of the mutate function of CreateOrUpdate,
+// and records the fields set by the operator that changed although the render didn't in vars.Drift.
+// It returns true when the live spec is to be kept, because spec.driftDetection doesn't revert the modifications
+func detectDrift[T any](cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, obj client.Object, kind string, live, desired *T) bool {
+	if cr.Spec.DriftDetection == nil {
+		return false
+	}
+
+	hash, err := specHash(desired)
+	if err != nil {
+		return false
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	keepLive := false
+
+	if annotations[appliedSpecHashAnnotation] == hash {
+		paths, err := driftPaths(live, desired)
+		if err == nil && len(paths) > 0 {
+			vars.Drift = append(vars.Drift, fmt.Sprintf("%s %s: %s", kind, obj.GetName(), summarizePaths(paths)))
+			keepLive = !cr.Spec.DriftDetection.Revert
+		}
+	}
+
+	annotations[appliedSpecHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+
+	return keepLive
+}
+
+func specHash(spec any) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16], nil
+}
+
+// driftPaths returns the paths of the fields set in desired whose value differs in live. Fields desired leaves unset,
+// e.g. defaulted by the API server, are ignored
+func driftPaths(live, desired any) ([]string, error) {
+	liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+
+	collectDriftPaths("spec", desiredMap, liveMap, &paths)
+
+	return paths, nil
+}
+
+func collectDriftPaths(path string, desired, live any, paths *[]string) {
+	switch desired := desired.(type) {
+	case nil:
+		return
+	case map[string]any:
+		liveMap, _ := live.(map[string]any) //nolint:errcheck
+
+		keys := make([]string, 0, len(desired))
+		for key := range desired {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			collectDriftPaths(path+"."+key, desired[key], liveMap[key], paths)
+		}
+	case []any:
+		liveList, ok := live.([]any)
+		if !ok || len(liveList) != len(desired) {
+			*paths = append(*paths, path)
+			return
+		}
+
+		for i := range desired {
+			collectDriftPaths(fmt.Sprintf("%s[%d]", path, i), desired[i], liveList[i], paths)
+		}
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+func summarizePaths(paths []string) string {
+	if len(paths) <= maxDriftPaths {
+		return strings.Join(paths, ", ")
+	}
+
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxDriftPaths], ", "), len(paths)-maxDriftPaths)
+}
@@ -0,0 +1,110 @@
+package grafana
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDriftPaths(t *testing.T) {
+	desired := &corev1.ServiceSpec{
+		Type:  corev1.ServiceTypeClusterIP,
+		Ports: []corev1.ServicePort{{Name: "grafana", Port: 3000}},
+	}
+
+	t.Run("server defaults are ignored", func(t *testing.T) {
+		live := desired.DeepCopy()
+		live.ClusterIP = "10.0.0.1"
+		live.Ports[0].Protocol = corev1.ProtocolTCP
+
+		paths, err := driftPaths(live, desired)
+		require.NoError(t, err)
+		assert.Empty(t, paths)
+	})
+
+	t.Run("modified fields", func(t *testing.T) {
+		live := desired.DeepCopy()
+		live.Type = corev1.ServiceTypeNodePort
+		live.Ports[0].Port = 8080
+
+		paths, err := driftPaths(live, desired)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"spec.ports[0].port", "spec.type"}, paths)
+	})
+
+	t.Run("added list items", func(t *testing.T) {
+		live := desired.DeepCopy()
+		live.Ports = append(live.Ports, corev1.ServicePort{Name: "debug", Port: 6060})
+
+		paths, err := driftPaths(live, desired)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"spec.ports"}, paths)
+	})
+}
+
+func TestDetectDrift(t *testing.T) {
+	desired := &corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}
+
+	modified := desired.DeepCopy()
+	modified.Type = corev1.ServiceTypeLoadBalancer
+
+	hash, err := specHash(desired)
+	require.NoError(t, err)
+
+	service := func(annotation string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name:        "grafana-service",
+			Annotations: map[string]string{appliedSpecHashAnnotation: annotation},
+		}}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		vars := &v1beta1.OperatorReconcileVars{}
+		obj := &corev1.Service{}
+
+		assert.False(t, detectDrift(&v1beta1.Grafana{}, vars, obj, "Service", modified, desired))
+		assert.Empty(t, vars.Drift)
+		assert.Empty(t, obj.GetAnnotations())
+	})
+
+	t.Run("keeps modifications of an unchanged render", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{DriftDetection: &v1beta1.GrafanaDriftDetection{}}}
+		vars := &v1beta1.OperatorReconcileVars{}
+
+		assert.True(t, detectDrift(cr, vars, service(hash), "Service", modified, desired))
+		assert.Equal(t, []string{"Service grafana-service: spec.type"}, vars.Drift)
+	})
+
+	t.Run("reverts modifications", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{DriftDetection: &v1beta1.GrafanaDriftDetection{Revert: true}}}
+		vars := &v1beta1.OperatorReconcileVars{}
+
+		assert.False(t, detectDrift(cr, vars, service(hash), "Service", modified, desired))
+		assert.Equal(t, []string{"Service grafana-service: spec.type"}, vars.Drift)
+	})
+
+	t.Run("changed render is rolled out", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{DriftDetection: &v1beta1.GrafanaDriftDetection{}}}
+		vars := &v1beta1.OperatorReconcileVars{}
+		obj := service("outdated")
+
+		assert.False(t, detectDrift(cr, vars, obj, "Service", modified, desired))
+		assert.Empty(t, vars.Drift)
+		assert.Equal(t, hash, obj.GetAnnotations()[appliedSpecHashAnnotation])
+	})
+}
+
+func TestSummarizePaths(t *testing.T) {
+	paths := make([]string, 0, maxDriftPaths+2)
+	for i := range maxDriftPaths + 2 {
+		paths = append(paths, fmt.Sprintf("spec.f%d", i))
+	}
+
+	assert.Equal(t, "spec.f0, spec.f1", summarizePaths(paths[:2]))
+	assert.Contains(t, summarizePaths(paths), "spec.f9 and 2 more")
+}
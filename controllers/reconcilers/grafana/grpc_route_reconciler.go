@@ -0,0 +1,217 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	v2 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// conditionGRPCRouteAccepted mirrors the Accepted conditions the parent Gateways set on the GRPCRoute
+	conditionGRPCRouteAccepted = "GRPCRouteAccepted"
+
+	conditionReasonGRPCRoutePending = "Pending"
+)
+
+// GRPCRouteReconciler is responsible for managing and reconciling
+// the Gateway API GRPCRoute resource associated with a Grafana instance.
+type GRPCRouteReconciler struct {
+	client client.Client
+	// hasGRPCRoutes tells whether the GRPCRoute CRD is installed, routes can only be left behind when it is
+	hasGRPCRoutes bool
+}
+
+// NewGRPCRouteReconciler returns a new instance of GRPCRouteReconciler.
+func NewGRPCRouteReconciler(client client.Client, hasGRPCRoutes bool) reconcilers.OperatorGrafanaReconciler {
+	return &GRPCRouteReconciler{
+		client:        client,
+		hasGRPCRoutes: hasGRPCRoutes,
+	}
+}
+
+// Reconcile ensures the GRPCRoute of spec.grpcRoute exists and reports whether its parent Gateways accepted it,
+// the route is removed once spec.grpcRoute is unset.
+// The admin url is never derived from the GRPCRoute, the operator talks to Grafana through HTTP.
+func (r *GRPCRouteReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("GRPCRouteReconciler")
+
+	grpcRoute := model.GetGrafanaGRPCRoute(cr, scheme)
+
+	if cr.Spec.GRPCRoute == nil {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionGRPCRouteAccepted)
+
+		if r.hasGRPCRoutes {
+			if err := r.removeGRPCRoute(ctx, cr, grpcRoute); err != nil {
+				return v1beta1.OperatorStageResultFailed, err
+			}
+		}
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.Info("reconciling grpc route")
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, grpcRoute, func() error {
+		grpcRoute.Spec = getGRPCRouteSpec(cr, scheme)
+		grpcRoute.ObjectMeta = cr.Spec.GRPCRoute.ObjectMeta.Merge(grpcRoute.ObjectMeta)
+
+		err := controllerutil.SetControllerReference(cr, grpcRoute, scheme)
+		if err != nil {
+			return err
+		}
+
+		model.SetInheritedLabels(grpcRoute, cr.Labels)
+		model.SetOwnershipAnnotations(grpcRoute, cr.Annotations)
+		model.SetDNSAnnotations(grpcRoute, cr.Spec.DNS)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	setGRPCRouteAccepted(cr, grpcRoute)
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// removeGRPCRoute deletes the GRPCRoute of the instance, routes not controlled by the instance are left alone
+func (r *GRPCRouteReconciler) removeGRPCRoute(ctx context.Context, cr *v1beta1.Grafana, grpcRoute *v2.GRPCRoute) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(grpcRoute), grpcRoute)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching grpc route: %w", err)
+	}
+
+	if !metav1.IsControlledBy(grpcRoute, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing grpc route", "name", grpcRoute.Name)
+
+	if err := r.client.Delete(ctx, grpcRoute); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing grpc route: %w", err)
+	}
+
+	return nil
+}
+
+// getGRPCRouteBackendRefs returns the backend of all rules, the Grafana service at spec.grpcRoute.port.
+func getGRPCRouteBackendRefs(cr *v1beta1.Grafana, scheme *runtime.Scheme) []v2.GRPCBackendRef {
+	service := model.GetGrafanaService(cr, scheme)
+
+	port := v2.PortNumber(GetGrafanaPort(cr))
+	if cr.Spec.GRPCRoute.Port != nil {
+		port = *cr.Spec.GRPCRoute.Port
+	}
+
+	serviceNamespace := v2.Namespace(service.GetNamespace())
+
+	return []v2.GRPCBackendRef{
+		{
+			BackendRef: v2.BackendRef{
+				BackendObjectReference: v2.BackendObjectReference{
+					Name:      v2.ObjectName(service.GetName()),
+					Namespace: &serviceNamespace,
+					Port:      &port,
+				},
+			},
+		},
+	}
+}
+
+// getGRPCRouteSpec builds the desired GRPCRouteSpec for Grafana,
+// wiring its rules to the underlying Service backend.
+// Without any rule, all gRPC requests are routed to Grafana.
+func getGRPCRouteSpec(cr *v1beta1.Grafana, scheme *runtime.Scheme) v2.GRPCRouteSpec {
+	backendRefs := getGRPCRouteBackendRefs(cr, scheme)
+
+	rules := make([]v2.GRPCRouteRule, 0, len(cr.Spec.GRPCRoute.Spec.Rules))
+	for _, rule := range cr.Spec.GRPCRoute.Spec.Rules {
+		rule.BackendRefs = backendRefs
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 0 {
+		rules = append(rules, v2.GRPCRouteRule{
+			BackendRefs: backendRefs,
+		})
+	}
+
+	return v2.GRPCRouteSpec{
+		CommonRouteSpec: v2.CommonRouteSpec{
+			ParentRefs: cr.Spec.GRPCRoute.Spec.ParentRefs,
+		},
+		Hostnames: cr.Spec.GRPCRoute.Spec.Hostnames,
+		Rules:     rules,
+	}
+}
+
+// setGRPCRouteAccepted sets the GRPCRouteAccepted condition from the Accepted conditions of the parents of the GRPCRoute.
+// The condition is unknown until every parent reported, and false with the messages of all parents refusing the route.
+func setGRPCRouteAccepted(cr *v1beta1.Grafana, grpcRoute *v2.GRPCRoute) {
+	condition := metav1.Condition{
+		Type:               conditionGRPCRouteAccepted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cr.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+		Reason:             string(v2.RouteReasonAccepted),
+		Message:            "grpc route was accepted by all gateways",
+	}
+
+	var refused []string
+
+	reported := 0
+
+	for _, parent := range grpcRoute.Status.Parents {
+		accepted := meta.FindStatusCondition(parent.Conditions, string(v2.RouteConditionAccepted))
+		if accepted == nil || accepted.ObservedGeneration < grpcRoute.Generation {
+			continue
+		}
+
+		reported++
+
+		if accepted.Status != metav1.ConditionTrue {
+			refused = append(refused, fmt.Sprintf("%s: %s", getParentRefName(grpcRoute.Namespace, parent.ParentRef), accepted.Message))
+			condition.Reason = accepted.Reason
+		}
+	}
+
+	switch {
+	case len(refused) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Message = fmt.Sprintf("grpc route was not accepted by %s", strings.Join(refused, ", "))
+	case reported < len(grpcRoute.Spec.ParentRefs):
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = conditionReasonGRPCRoutePending
+		condition.Message = "waiting for gateways to accept the grpc route"
+	}
+
+	meta.SetStatusCondition(&cr.Status.Conditions, condition)
+}
+
+// getParentRefName returns namespace/name of the parent, parents default to the namespace of the route
+func getParentRefName(routeNamespace string, pr v2.ParentReference) string {
+	namespace := routeNamespace
+	if pr.Namespace != nil {
+		namespace = string(*pr.Namespace)
+	}
+
+	return fmt.Sprintf("%s/%s", namespace, pr.Name)
+}
@@ -0,0 +1,203 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	v2 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestGetGRPCRouteSpec(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			GRPCRoute: &v1beta1.GRPCRouteV1{
+				Spec: v2.GRPCRouteSpec{
+					CommonRouteSpec: v2.CommonRouteSpec{
+						ParentRefs: []v2.ParentReference{{Name: "gateway"}},
+					},
+					Hostnames: []v2.Hostname{"grpc.grafana.example.com"},
+				},
+			},
+		},
+	}
+
+	t.Run("default rule", func(t *testing.T) {
+		spec := getGRPCRouteSpec(cr, s)
+
+		assert.Equal(t, cr.Spec.GRPCRoute.Spec.Hostnames, spec.Hostnames)
+		assert.Equal(t, cr.Spec.GRPCRoute.Spec.ParentRefs, spec.ParentRefs)
+		require.Len(t, spec.Rules, 1)
+		assert.Empty(t, spec.Rules[0].Matches)
+		assert.Equal(t, v2.ObjectName("grafana-service"), spec.Rules[0].BackendRefs[0].Name)
+		assert.Equal(t, v2.PortNumber(3000), *spec.Rules[0].BackendRefs[0].Port)
+	})
+
+	t.Run("rules and port", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.GRPCRoute.Port = ptr.To(v2.PortNumber(10000))
+		cr.Spec.GRPCRoute.Spec.Rules = []v2.GRPCRouteRule{
+			{
+				Matches: []v2.GRPCRouteMatch{{
+					Method: &v2.GRPCMethodMatch{Service: ptr.To("grafana.Live")},
+				}},
+				BackendRefs: []v2.GRPCBackendRef{{BackendRef: v2.BackendRef{BackendObjectReference: v2.BackendObjectReference{Name: "other"}}}},
+			},
+		}
+
+		spec := getGRPCRouteSpec(cr, s)
+
+		require.Len(t, spec.Rules, 1)
+		assert.Equal(t, "grafana.Live", *spec.Rules[0].Matches[0].Method.Service)
+		require.Len(t, spec.Rules[0].BackendRefs, 1)
+		assert.Equal(t, v2.ObjectName("grafana-service"), spec.Rules[0].BackendRefs[0].Name)
+		assert.Equal(t, v2.PortNumber(10000), *spec.Rules[0].BackendRefs[0].Port)
+	})
+}
+
+func TestSetGRPCRouteAccepted(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+	}
+
+	route := &v2.GRPCRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-grpcroute", Namespace: "default", Generation: 2},
+		Spec: v2.GRPCRouteSpec{
+			CommonRouteSpec: v2.CommonRouteSpec{
+				ParentRefs: []v2.ParentReference{
+					{Name: "internal"},
+					{Name: "public", Namespace: ptr.To(v2.Namespace("gateways"))},
+				},
+			},
+		},
+	}
+
+	parentStatus := func(pr v2.ParentReference, status metav1.ConditionStatus, generation int64, reason, message string) v2.RouteParentStatus {
+		return v2.RouteParentStatus{
+			ParentRef: pr,
+			Conditions: []metav1.Condition{{
+				Type:               string(v2.RouteConditionAccepted),
+				Status:             status,
+				ObservedGeneration: generation,
+				Reason:             reason,
+				Message:            message,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		parents []v2.RouteParentStatus
+		status  metav1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{
+			name:    "not reported",
+			status:  metav1.ConditionUnknown,
+			reason:  conditionReasonGRPCRoutePending,
+			message: "waiting for gateways to accept the grpc route",
+		},
+		{
+			name: "outdated status",
+			parents: []v2.RouteParentStatus{
+				parentStatus(route.Spec.ParentRefs[0], metav1.ConditionTrue, 2, "Accepted", ""),
+				parentStatus(route.Spec.ParentRefs[1], metav1.ConditionTrue, 1, "Accepted", ""),
+			},
+			status: metav1.ConditionUnknown,
+			reason: conditionReasonGRPCRoutePending,
+		},
+		{
+			name: "accepted",
+			parents: []v2.RouteParentStatus{
+				parentStatus(route.Spec.ParentRefs[0], metav1.ConditionTrue, 2, "Accepted", ""),
+				parentStatus(route.Spec.ParentRefs[1], metav1.ConditionTrue, 2, "Accepted", ""),
+			},
+			status:  metav1.ConditionTrue,
+			reason:  string(v2.RouteReasonAccepted),
+			message: "grpc route was accepted by all gateways",
+		},
+		{
+			name: "refused",
+			parents: []v2.RouteParentStatus{
+				parentStatus(route.Spec.ParentRefs[0], metav1.ConditionTrue, 2, "Accepted", ""),
+				parentStatus(route.Spec.ParentRefs[1], metav1.ConditionFalse, 2, string(v2.RouteReasonNotAllowedByListeners), "no listener allows GRPCRoutes"),
+			},
+			status:  metav1.ConditionFalse,
+			reason:  string(v2.RouteReasonNotAllowedByListeners),
+			message: "grpc route was not accepted by gateways/public: no listener allows GRPCRoutes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := route.DeepCopy()
+			route.Status.Parents = tt.parents
+
+			setGRPCRouteAccepted(cr, route)
+
+			condition := meta.FindStatusCondition(cr.Status.Conditions, conditionGRPCRouteAccepted)
+			require.NotNil(t, condition)
+			assert.Equal(t, tt.status, condition.Status)
+			assert.Equal(t, tt.reason, condition.Reason)
+
+			if tt.message != "" {
+				assert.Equal(t, tt.message, condition.Message)
+			}
+		})
+	}
+}
+
+func TestGRPCRouteReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, v2.Install(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			GRPCRoute: &v1beta1.GRPCRouteV1{
+				ObjectMeta: v1beta1.ObjectMeta{Labels: map[string]string{"team": "observability"}},
+				Spec: v2.GRPCRouteSpec{
+					CommonRouteSpec: v2.CommonRouteSpec{
+						ParentRefs: []v2.ParentReference{{Name: "gateway"}},
+					},
+				},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewGRPCRouteReconciler(cl, true)
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	route := &v2.GRPCRoute{}
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "grafana-grpcroute"}, route))
+	assert.Equal(t, "observability", route.Labels["team"])
+	assert.True(t, metav1.IsControlledBy(route, cr))
+	assert.Equal(t, metav1.ConditionUnknown, meta.FindStatusCondition(cr.Status.Conditions, conditionGRPCRouteAccepted).Status)
+
+	cr.Spec.GRPCRoute = nil
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionGRPCRouteAccepted))
+
+	err = cl.Get(t.Context(), client.ObjectKeyFromObject(route), &v2.GRPCRoute{})
+	assert.True(t, kuberr.IsNotFound(err))
+}
@@ -0,0 +1,77 @@
+package grafana
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxHistoryEntries bounds status.history, the oldest entries are dropped first
+const maxHistoryEntries = 20
+
+// getRolloutChanges returns the changes of the image, config hash and plugins of the grafana container
+// between the live and the desired deployment spec
+func getRolloutChanges(live, desired *appsv1.DeploymentSpec) []v1beta1.GrafanaHistoryEntry {
+	before := getRolloutValues(live)
+	after := getRolloutValues(desired)
+
+	var changes []v1beta1.GrafanaHistoryEntry
+
+	for _, kind := range []v1beta1.GrafanaChangeKind{v1beta1.GrafanaChangeImage, v1beta1.GrafanaChangeConfig, v1beta1.GrafanaChangePlugins} {
+		if before[kind] != after[kind] {
+			changes = append(changes, v1beta1.GrafanaHistoryEntry{
+				Kind:     kind,
+				Value:    after[kind],
+				Previous: before[kind],
+			})
+		}
+	}
+
+	return changes
+}
+
+// getRolloutValues returns the image, config hash and plugins hash of the grafana container of spec
+func getRolloutValues(spec *appsv1.DeploymentSpec) map[v1beta1.GrafanaChangeKind]string {
+	values := map[v1beta1.GrafanaChangeKind]string{}
+
+	for _, container := range spec.Template.Spec.Containers {
+		if container.Name != "grafana" {
+			continue
+		}
+
+		values[v1beta1.GrafanaChangeImage] = container.Image
+		values[v1beta1.GrafanaChangeConfig] = getEnvValue(container.Env, "CONFIG_HASH")
+
+		if plugins := getEnvValue(container.Env, "GF_INSTALL_PLUGINS"); plugins != "" {
+			values[v1beta1.GrafanaChangePlugins] = config.GetHash(plugins)
+		}
+	}
+
+	return values
+}
+
+func getEnvValue(env []corev1.EnvVar, name string) string {
+	for _, e := range env {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+
+	return ""
+}
+
+// recordHistory appends the changes to status.history, keeping the latest maxHistoryEntries entries
+func recordHistory(cr *v1beta1.Grafana, changes []v1beta1.GrafanaHistoryEntry, now time.Time) {
+	for _, change := range changes {
+		change.Time = metav1.Time{Time: now}
+		cr.Status.History = append(cr.Status.History, change)
+	}
+
+	if len(cr.Status.History) > maxHistoryEntries {
+		cr.Status.History = cr.Status.History[len(cr.Status.History)-maxHistoryEntries:]
+	}
+}
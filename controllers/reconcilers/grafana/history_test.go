@@ -0,0 +1,75 @@
+package grafana
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetRolloutChanges(t *testing.T) {
+	spec := func(image, configHash, plugins string) *appsv1.DeploymentSpec {
+		return &appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "sidecar", Image: "sidecar:1"},
+						{
+							Name:  "grafana",
+							Image: image,
+							Env: []corev1.EnvVar{
+								{Name: "CONFIG_HASH", Value: configHash},
+								{Name: "GF_INSTALL_PLUGINS", Value: plugins},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("created deployment", func(t *testing.T) {
+		changes := getRolloutChanges(&appsv1.DeploymentSpec{}, spec("grafana:12.0.0", "abc", ""))
+
+		assert.Equal(t, []v1beta1.GrafanaHistoryEntry{
+			{Kind: v1beta1.GrafanaChangeImage, Value: "grafana:12.0.0"},
+			{Kind: v1beta1.GrafanaChangeConfig, Value: "abc"},
+		}, changes)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.Empty(t, getRolloutChanges(spec("grafana:12.0.0", "abc", "clock@1.0.0"), spec("grafana:12.0.0", "abc", "clock@1.0.0")))
+	})
+
+	t.Run("image and plugins", func(t *testing.T) {
+		changes := getRolloutChanges(spec("grafana:12.0.0", "abc", "clock@1.0.0"), spec("grafana:12.1.0", "abc", "clock@1.1.0"))
+
+		assert.Equal(t, []v1beta1.GrafanaHistoryEntry{
+			{Kind: v1beta1.GrafanaChangeImage, Value: "grafana:12.1.0", Previous: "grafana:12.0.0"},
+			{Kind: v1beta1.GrafanaChangePlugins, Value: config.GetHash("clock@1.1.0"), Previous: config.GetHash("clock@1.0.0")},
+		}, changes)
+	})
+}
+
+func TestRecordHistory(t *testing.T) {
+	cr := &v1beta1.Grafana{}
+	now := time.Date(2026, 10, 15, 14, 32, 0, 0, time.UTC)
+
+	recordHistory(cr, nil, now)
+	assert.Empty(t, cr.Status.History)
+
+	for i := range maxHistoryEntries + 2 {
+		recordHistory(cr, []v1beta1.GrafanaHistoryEntry{{Kind: v1beta1.GrafanaChangeConfig, Value: fmt.Sprint(i)}}, now.Add(time.Duration(i)*time.Minute))
+	}
+
+	require.Len(t, cr.Status.History, maxHistoryEntries)
+	assert.Equal(t, "2", cr.Status.History[0].Value)
+	assert.Equal(t, fmt.Sprint(maxHistoryEntries+1), cr.Status.History[maxHistoryEntries-1].Value)
+	assert.Equal(t, now.Add(2*time.Minute), cr.Status.History[0].Time.Time)
+}
@@ -0,0 +1,156 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultAutoscalingCPUUtilization is the average CPU utilization targeted when spec.autoscaling.metrics is empty
+const defaultAutoscalingCPUUtilization int32 = 80
+
+// HorizontalPodAutoscalerReconciler maintains the HorizontalPodAutoscaler of instances with spec.autoscaling
+type HorizontalPodAutoscalerReconciler struct {
+	client client.Client
+}
+
+func NewHorizontalPodAutoscalerReconciler(client client.Client) reconcilers.OperatorGrafanaReconciler {
+	return &HorizontalPodAutoscalerReconciler{
+		client: client,
+	}
+}
+
+// Reconcile creates the HorizontalPodAutoscaler while autoscaling is enabled and removes it otherwise
+func (r *HorizontalPodAutoscalerReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("HorizontalPodAutoscalerReconciler")
+
+	hpa := model.GetGrafanaHorizontalPodAutoscaler(cr, scheme)
+
+	if !isAutoscalingEnabled(cr) {
+		err := r.removeHorizontalPodAutoscaler(ctx, cr, hpa)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.V(1).Info("reconciling horizontal pod autoscaler")
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, hpa, func() error {
+		hpa.Spec = getHorizontalPodAutoscalerSpec(cr, scheme)
+
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, hpa, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(hpa, cr.Labels)
+		model.SetOwnershipAnnotations(hpa, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// isAutoscalingEnabled reports whether spec.autoscaling is set and not disabled
+func isAutoscalingEnabled(cr *v1beta1.Grafana) bool {
+	return cr.Spec.Autoscaling != nil && (cr.Spec.Autoscaling.Enabled == nil || *cr.Spec.Autoscaling.Enabled)
+}
+
+// getHorizontalPodAutoscalerSpec targets the deployment or statefulset of the instance, scaling on CPU utilization unless metrics are configured
+func getHorizontalPodAutoscalerSpec(cr *v1beta1.Grafana, scheme *runtime.Scheme) autoscalingv2.HorizontalPodAutoscalerSpec {
+	target := autoscalingv2.CrossVersionObjectReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+		Name:       model.GetGrafanaDeployment(cr, scheme).Name,
+	}
+
+	if cr.IsStatefulSet() {
+		target.Kind = "StatefulSet"
+		target.Name = model.GetGrafanaStatefulSet(cr, scheme).Name
+	}
+
+	metrics := cr.Spec.Autoscaling.Metrics
+	if len(metrics) == 0 {
+		metrics = []autoscalingv2.MetricSpec{
+			{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: ptr.To(defaultAutoscalingCPUUtilization),
+					},
+				},
+			},
+		}
+	}
+
+	return autoscalingv2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: target,
+		MinReplicas:    ptr.To(getAutoscalingMinReplicas(cr)),
+		MaxReplicas:    cr.Spec.Autoscaling.MaxReplicas,
+		Metrics:        metrics,
+	}
+}
+
+func getAutoscalingMinReplicas(cr *v1beta1.Grafana) int32 {
+	if cr.Spec.Autoscaling.MinReplicas == nil {
+		return 1
+	}
+
+	return *cr.Spec.Autoscaling.MinReplicas
+}
+
+// getAutoscaledReplicas keeps the replicas set by the HorizontalPodAutoscaler, new workloads start with minReplicas
+func getAutoscaledReplicas(cr *v1beta1.Grafana, live *int32) *int32 {
+	if live != nil {
+		return live
+	}
+
+	return ptr.To(getAutoscalingMinReplicas(cr))
+}
+
+// removeHorizontalPodAutoscaler deletes the HorizontalPodAutoscaler if it was created for the instance
+func (r *HorizontalPodAutoscalerReconciler) removeHorizontalPodAutoscaler(ctx context.Context, cr *v1beta1.Grafana, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(hpa), hpa)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching horizontal pod autoscaler: %w", err)
+	}
+
+	if !metav1.IsControlledBy(hpa, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing horizontal pod autoscaler", "name", hpa.Name)
+
+	if err := r.client.Delete(ctx, hpa); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing horizontal pod autoscaler: %w", err)
+	}
+
+	return nil
+}
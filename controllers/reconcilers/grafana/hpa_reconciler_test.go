@@ -0,0 +1,111 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetHorizontalPodAutoscalerSpec(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			Autoscaling: &v1beta1.GrafanaAutoscaling{MaxReplicas: 5},
+		},
+	}
+
+	t.Run("defaults to cpu utilization", func(t *testing.T) {
+		spec := getHorizontalPodAutoscalerSpec(cr, nil)
+
+		assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "grafana-deployment"}, spec.ScaleTargetRef)
+		assert.Equal(t, int32(1), *spec.MinReplicas)
+		assert.Equal(t, int32(5), spec.MaxReplicas)
+		require.Len(t, spec.Metrics, 1)
+		assert.Equal(t, corev1.ResourceCPU, spec.Metrics[0].Resource.Name)
+		assert.Equal(t, defaultAutoscalingCPUUtilization, *spec.Metrics[0].Resource.Target.AverageUtilization)
+	})
+
+	t.Run("targets the statefulset", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Deployment = &v1beta1.DeploymentV1{Strategy: v1beta1.DeploymentStrategyStatefulSet}
+
+		spec := getHorizontalPodAutoscalerSpec(cr, nil)
+
+		assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "grafana-statefulset"}, spec.ScaleTargetRef)
+	})
+
+	t.Run("configured metrics", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Autoscaling.MinReplicas = ptr.To[int32](2)
+		cr.Spec.Autoscaling.Metrics = []autoscalingv2.MetricSpec{
+			{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name:   corev1.ResourceMemory,
+					Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: ptr.To[int32](70)},
+				},
+			},
+		}
+
+		spec := getHorizontalPodAutoscalerSpec(cr, nil)
+
+		assert.Equal(t, int32(2), *spec.MinReplicas)
+		assert.Equal(t, cr.Spec.Autoscaling.Metrics, spec.Metrics)
+	})
+}
+
+func TestGetAutoscaledReplicas(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		Spec: v1beta1.GrafanaSpec{
+			Autoscaling: &v1beta1.GrafanaAutoscaling{MinReplicas: ptr.To[int32](2), MaxReplicas: 5},
+		},
+	}
+
+	assert.Equal(t, int32(2), *getAutoscaledReplicas(cr, nil))
+	assert.Equal(t, int32(4), *getAutoscaledReplicas(cr, ptr.To[int32](4)))
+}
+
+func TestHorizontalPodAutoscalerReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, autoscalingv2.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Autoscaling: &v1beta1.GrafanaAutoscaling{MaxReplicas: 3},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewHorizontalPodAutoscalerReconciler(cl)
+	key := client.ObjectKeyFromObject(model.GetGrafanaHorizontalPodAutoscaler(cr, nil))
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	require.NoError(t, cl.Get(t.Context(), key, hpa))
+	assert.True(t, metav1.IsControlledBy(hpa, cr))
+	assert.Equal(t, int32(3), hpa.Spec.MaxReplicas)
+
+	cr.Spec.Autoscaling.Enabled = ptr.To(false)
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	err = cl.Get(t.Context(), key, &autoscalingv2.HorizontalPodAutoscaler{})
+	assert.True(t, kuberr.IsNotFound(err))
+}
@@ -2,33 +2,44 @@ package grafana
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	v2 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/pkg/features"
 )
 
 // HTTPRouteReconciler is responsible for managing and reconciling
 // the Gateway API HTTPRoute resource associated with a Grafana instance.
 type HTTPRouteReconciler struct {
 	client client.Client
+	// clusterScoped checks the filters against the cluster scoped GatewayClasses, operators restricted to namespaces
+	// can't read them
+	clusterScoped bool
 }
 
 // NewHTTPRouteReconciler returns a new instance of HTTPRouteReconciler.
-func NewHTTPRouteReconciler(client client.Client) reconcilers.OperatorGrafanaReconciler {
+func NewHTTPRouteReconciler(client client.Client, clusterScoped bool) reconcilers.OperatorGrafanaReconciler {
 	return &HTTPRouteReconciler{
-		client: client,
+		client:        client,
+		clusterScoped: clusterScoped,
 	}
 }
 
@@ -44,15 +55,32 @@ func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana
 
 // reconcileHTTPRoute ensures the HTTPRoute object for Grafana matches the desired spec.
 // It creates or updates the HTTPRoute, merges configurations, and updates Grafana’s AdminURL if needed.
-func (r *HTTPRouteReconciler) reconcileHTTPRoute(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+func (r *HTTPRouteReconciler) reconcileHTTPRoute(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
 	if cr.Spec.HTTPRoute == nil {
+		cr.Status.HTTPRoute = nil
 		return v1beta1.OperatorStageResultSuccess, nil
 	}
 
+	if usesTLSRoute(cr) {
+		return r.reconcileTLSRoute(ctx, cr, vars, scheme)
+	}
+
+	err := r.removeRoute(ctx, cr, model.GetGrafanaTLSRoute(cr, scheme))
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	spec := getHTTPRouteSpec(cr, scheme)
+
+	err = r.validateHTTPRouteFilters(ctx, cr, spec, scheme)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
 	httpRoute := model.GetGrafanaHTTPRoute(cr, scheme)
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.client, httpRoute, func() error {
-		httpRoute.Spec = getHTTPRouteSpec(cr, scheme)
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, httpRoute, func() error {
+		httpRoute.Spec = spec
 
 		// Merge the CR-defined HTTPRoute into the generated object
 		err := v1beta1.Merge(cr.Spec.HTTPRoute, httpRoute)
@@ -71,6 +99,8 @@ func (r *HTTPRouteReconciler) reconcileHTTPRoute(ctx context.Context, cr *v1beta
 
 		// Propagate labels from Grafana CR to the HTTPRoute
 		model.SetInheritedLabels(httpRoute, cr.Labels)
+		model.SetOwnershipAnnotations(httpRoute, cr.Annotations)
+		model.SetDNSAnnotations(httpRoute, cr.Spec.DNS)
 
 		return nil
 	})
@@ -78,9 +108,33 @@ func (r *HTTPRouteReconciler) reconcileHTTPRoute(ctx context.Context, cr *v1beta
 		return v1beta1.OperatorStageResultFailed, err
 	}
 
-	// Assign AdminURL if ingress is preferred
+	cr.Status.HTTPRoute = getHTTPRouteStatus(httpRoute)
+
+	err = r.removeReferenceGrant(ctx, cr, scheme)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	err = r.reconcileHostnameHTTPRoutes(ctx, cr, scheme)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	err = getHTTPRouteFilterRejection(httpRoute)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	return r.setHTTPRouteAdminURL(ctx, cr, vars, httpRoute)
+}
+
+// setHTTPRouteAdminURL assigns the admin url derived from the Gateways of the route if ingress is preferred.
+func (r *HTTPRouteReconciler) setHTTPRouteAdminURL(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, httpRoute *v2.HTTPRoute) (v1beta1.OperatorStageStatus, error) {
 	if cr.PreferIngress() {
-		adminURL := r.getHTTPRouteAdminURL(ctx, httpRoute)
+		adminURL, err := r.getHTTPRouteAdminURL(ctx, cr, httpRoute)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
 
 		// Wait until route has parents (attached to Gateway)
 		if len(httpRoute.Status.Parents) == 0 {
@@ -91,135 +145,383 @@ func (r *HTTPRouteReconciler) reconcileHTTPRoute(ctx context.Context, cr *v1beta
 			return v1beta1.OperatorStageResultFailed, fmt.Errorf("http route spec is incomplete")
 		}
 
-		cr.Status.AdminURL = adminURL
+		setAdminURL(ctx, cr, vars, adminURL)
 	}
 
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
-// getHTTPRouteAdminURL builds the external access URL for Grafana based on the
-// associated Gateway’s status and listener configuration. Returns empty string
-// if the Gateway is not ready or missing an address.
-func (r *HTTPRouteReconciler) getHTTPRouteAdminURL(ctx context.Context, httpRoute *v2.HTTPRoute) (adminURL string) {
-	log := logf.FromContext(ctx)
+// usesTLSRoute reports whether Grafana terminates TLS itself, its traffic is then passed through by a TLSRoute.
+func usesTLSRoute(cr *v1beta1.Grafana) bool {
+	return getGrafanaServerScheme(cr) == "https"
+}
 
-	if httpRoute == nil {
-		return ""
+// getTLSRouteSpec builds the TLSRoute passing the connections of the hostnames of spec.httpRoute through to the Grafana service.
+func getTLSRouteSpec(cr *v1beta1.Grafana, scheme *runtime.Scheme) gwapiv1alpha2.TLSRouteSpec {
+	backendRef := getHTTPRouteBackendRefs(cr, scheme)[0].BackendRef
+
+	hostnames := slices.Clone(cr.Spec.HTTPRoute.Spec.Hostnames)
+	for _, hostname := range cr.Spec.HTTPRoute.Hostnames {
+		if !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
 	}
 
-	if len(httpRoute.Spec.ParentRefs) == 0 {
-		return ""
+	return gwapiv1alpha2.TLSRouteSpec{
+		CommonRouteSpec: v2.CommonRouteSpec{
+			ParentRefs: cr.Spec.HTTPRoute.Spec.ParentRefs,
+		},
+		Hostnames: hostnames,
+		Rules: []gwapiv1alpha2.TLSRouteRule{
+			{BackendRefs: []v2.BackendRef{backendRef}},
+		},
+	}
+}
+
+// reconcileTLSRoute applies the TLSRoute used instead of the HTTPRoute when Grafana terminates TLS itself.
+// HTTP rules and filters cannot apply to passed through connections, the HTTPRoutes of the instance are removed.
+func (r *HTTPRouteReconciler) reconcileTLSRoute(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	tlsRoute := model.GetGrafanaTLSRoute(cr, scheme)
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, tlsRoute, func() error {
+		tlsRoute.Spec = getTLSRouteSpec(cr, scheme)
+		tlsRoute.ObjectMeta = cr.Spec.HTTPRoute.ObjectMeta.Merge(tlsRoute.ObjectMeta)
+
+		err := controllerutil.SetControllerReference(cr, tlsRoute, scheme)
+		if err != nil {
+			return err
+		}
+
+		model.SetInheritedLabels(tlsRoute, cr.Labels)
+		model.SetOwnershipAnnotations(tlsRoute, cr.Annotations)
+		model.SetDNSAnnotations(tlsRoute, cr.Spec.DNS)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
 	}
 
-	var (
-		protocol  = "http"
-		namespace = "default"
-		hostname  string
-		port      int
-	)
+	// Gateways report on TLSRoutes like on HTTPRoutes, the status and admin url are derived the same way
+	route := &v2.HTTPRoute{
+		ObjectMeta: tlsRoute.ObjectMeta,
+		Spec: v2.HTTPRouteSpec{
+			CommonRouteSpec: tlsRoute.Spec.CommonRouteSpec,
+			Hostnames:       tlsRoute.Spec.Hostnames,
+		},
+		Status: v2.HTTPRouteStatus{RouteStatus: tlsRoute.Status.RouteStatus},
+	}
 
-	// Fetch the Gateway referenced by the HTTPRoute
-	gw := &v2.Gateway{}
-	pr := httpRoute.Spec.ParentRefs[0]
+	cr.Status.HTTPRoute = getHTTPRouteStatus(route)
 
-	if pr.Namespace != nil {
-		namespace = string(*pr.Namespace)
+	err = r.removeRoute(ctx, cr, model.GetGrafanaHTTPRoute(cr, scheme))
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
 	}
 
-	err := r.client.Get(ctx, types.NamespacedName{
-		Namespace: namespace,
-		Name:      string(pr.Name),
-	}, gw)
+	err = r.removeReferenceGrant(ctx, cr, scheme)
 	if err != nil {
-		log.Error(err, "error synchronizing grafana statuses")
-		return ""
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	err = r.reconcileHostnameHTTPRoutes(ctx, cr, scheme)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	return r.setHTTPRouteAdminURL(ctx, cr, vars, route)
+}
+
+// removeRoute deletes the route generated for the other server protocol, once Grafana switched between http and https.
+func (r *HTTPRouteReconciler) removeRoute(ctx context.Context, cr *v1beta1.Grafana, route client.Object) error {
+	log := logf.FromContext(ctx)
+
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(route), route)
+	if kuberr.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching route %s: %w", route.GetName(), err)
+	}
+
+	if !metav1.IsControlledBy(route, cr) {
+		return nil
+	}
+
+	log.Info("removing route of the previous server protocol", "route", route.GetName())
+
+	if err := r.client.Delete(ctx, route); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing route %s: %w", route.GetName(), err)
+	}
+
+	return nil
+}
+
+// getHTTPRouteStatus copies the Accepted and ResolvedRefs conditions the parents set on the current generation of the route
+func getHTTPRouteStatus(httpRoute *v2.HTTPRoute) *v1beta1.GrafanaHTTPRouteStatus {
+	status := &v1beta1.GrafanaHTTPRouteStatus{}
+
+	conditionTypes := []string{string(v2.RouteConditionAccepted), string(v2.RouteConditionResolvedRefs)}
+	summaries := []*metav1.ConditionStatus{&status.Accepted, &status.ResolvedRefs}
+
+	trueCount := make([]int, len(conditionTypes))
+
+	for _, parent := range httpRoute.Status.Parents {
+		parentStatus := v1beta1.GrafanaHTTPRouteParentStatus{
+			Parent: getParentRefName(httpRoute.Namespace, parent.ParentRef),
+		}
+		if parent.ParentRef.SectionName != nil {
+			parentStatus.Parent += "/" + string(*parent.ParentRef.SectionName)
+		}
+
+		for i, conditionType := range conditionTypes {
+			condition := meta.FindStatusCondition(parent.Conditions, conditionType)
+			if condition == nil || condition.ObservedGeneration < httpRoute.Generation {
+				continue
+			}
+
+			parentStatus.Conditions = append(parentStatus.Conditions, *condition)
+
+			switch condition.Status {
+			case metav1.ConditionTrue:
+				trueCount[i]++
+			case metav1.ConditionFalse:
+				*summaries[i] = metav1.ConditionFalse
+			}
+		}
+
+		status.Parents = append(status.Parents, parentStatus)
+	}
+
+	for i, summary := range summaries {
+		switch {
+		case *summary == metav1.ConditionFalse:
+		case trueCount[i] > 0 && trueCount[i] >= len(httpRoute.Spec.ParentRefs):
+			*summary = metav1.ConditionTrue
+		default:
+			*summary = metav1.ConditionUnknown
+		}
 	}
 
-	// Match appropriate listener from Gateway
-	listener := r.getMatchListener(ctx, httpRoute, gw)
+	return status
+}
+
+// getHTTPRouteAdminURL builds the external access URL for Grafana based on the
+// Gateways the HTTPRoute is attached to. The protocol follows the matching listener,
+// HTTPS and TLS listeners yield https. Hostname and address are picked according to
+// spec.client.adminUrlStrategy. Returns empty string if no Gateway is ready or has an address yet.
+func (r *HTTPRouteReconciler) getHTTPRouteAdminURL(ctx context.Context, cr *v1beta1.Grafana, httpRoute *v2.HTTPRoute) (string, error) {
+	log := logf.FromContext(ctx)
+
+	if httpRoute == nil {
+		return "", nil
+	}
 
-	if listener != nil {
-		if listener.TLS != nil {
-			protocol = "https"
+	for _, pr := range httpRoute.Spec.ParentRefs {
+		if !isGatewayParentRef(pr) {
+			continue
 		}
 
-		port = int(listener.Port)
+		// Parents default to the namespace of the route
+		namespace := httpRoute.Namespace
+		if pr.Namespace != nil {
+			namespace = string(*pr.Namespace)
+		}
+
+		// Fetch the Gateway referenced by the HTTPRoute
+		gw := &v2.Gateway{}
+
+		err := r.client.Get(ctx, types.NamespacedName{
+			Namespace: namespace,
+			Name:      string(pr.Name),
+		}, gw)
+		if err != nil {
+			log.Error(err, "error fetching gateway of http route", "gateway", pr.Name, "namespace", namespace)
+			continue
+		}
 
-		if listener.Hostname != nil {
-			hostname = string(*listener.Hostname)
+		// Match appropriate listener from Gateway
+		listener, hostname := r.getMatchListener(ctx, httpRoute, pr, gw)
+		if listener == nil {
+			continue
 		}
 
-		if hostname == "" {
-			for _, address := range gw.Status.Addresses {
-				if address.Value != "" {
-					hostname = address.Value
-					break
-				}
+		target := adminURLTarget{
+			Scheme:   getListenerScheme(listener),
+			Hostname: hostname,
+			Port:     int32(listener.Port),
+		}
+
+		for _, address := range gw.Status.Addresses {
+			if address.Value != "" {
+				target.Address = address.Value
+				break
 			}
 		}
+
+		adminURL, err := getAdminURL(cr, target)
+		if err != nil {
+			return "", err
+		}
+
+		// Wait until Gateway has an assigned address
+		if adminURL == "" {
+			log.Info("gateway has no assigned address yet; waiting for it to become ready",
+				"gateway", gw.Name, "namespace", gw.Namespace)
+
+			continue
+		}
+
+		return adminURL, nil
+	}
+
+	return "", nil
+}
+
+// isGatewayParentRef returns true when the parent reference points to a Gateway, the default kind of parents.
+func isGatewayParentRef(pr v2.ParentReference) bool {
+	if pr.Group != nil && *pr.Group != v2.GroupName {
+		return false
 	}
 
-	// Wait until Gateway has an assigned address
-	if hostname == "" {
-		log.Info("gateway has no assigned address yet; waiting for it to become ready",
-			"gateway", gw.Name, "namespace", gw.Namespace)
+	return pr.Kind == nil || *pr.Kind == "Gateway"
+}
 
-		return ""
+// getListenerScheme returns the scheme clients use to reach routes attached to the listener.
+// TLS listeners are https as well, Grafana is only reachable through HTTP on top of them.
+func getListenerScheme(listener *v2.Listener) string {
+	switch listener.Protocol {
+	case v2.HTTPSProtocolType, v2.TLSProtocolType:
+		return "https"
+	default:
+		return "http"
+	}
+}
+
+// getMatchListener tries to find a Gateway listener that matches the parent reference's
+// sectionName and port as well as the HTTPRoute's namespace and hostname constraints,
+// according to the Gateway API rules. Along with the listener, it returns the hostname
+// Grafana is served at, empty when neither the listener nor the route define a concrete one.
+func (r *HTTPRouteReconciler) getMatchListener(ctx context.Context, httpRoute *v2.HTTPRoute, pr v2.ParentReference, gw *v2.Gateway) (*v2.Listener, string) {
+	for i := range gw.Spec.Listeners {
+		listener := &gw.Spec.Listeners[i]
+
+		if pr.SectionName != nil && *pr.SectionName != listener.Name {
+			continue
+		}
+
+		if pr.Port != nil && *pr.Port != listener.Port {
+			continue
+		}
+
+		hostname, ok := getListenerHostname(listener, httpRoute.Spec.Hostnames)
+		if !ok {
+			continue
+		}
+
+		if r.listenerAllowsRoute(ctx, httpRoute, gw, listener) {
+			return listener, hostname
+		}
 	}
 
-	return fmt.Sprintf("%v://%v:%v", protocol, hostname, port)
+	return nil, ""
 }
 
-// getMatchListener tries to find a Gateway listener that matches the HTTPRoute’s
-// namespace and hostname constraints, according to the Gateway API rules.
-func (r *HTTPRouteReconciler) getMatchListener(ctx context.Context, httpRoute *v2.HTTPRoute, gw *v2.Gateway) *v2.Listener {
+// listenerAllowsRoute checks whether the listener accepts routes from the namespace of the HTTPRoute.
+// Listeners without allowedRoutes only accept routes of their own namespace.
+func (r *HTTPRouteReconciler) listenerAllowsRoute(ctx context.Context, httpRoute *v2.HTTPRoute, gw *v2.Gateway, listener *v2.Listener) bool {
 	log := logf.FromContext(ctx)
-	hostnames := httpRoute.Spec.Hostnames
-
-	for _, listener := range gw.Spec.Listeners {
-		if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil {
-			switch *listener.AllowedRoutes.Namespaces.From {
-			case v2.NamespacesFromAll:
-				if len(hostnames) == 0 || slices.Contains(hostnames, *listener.Hostname) {
-					return &listener
-				}
-			case v2.NamespacesFromSelector:
-				var (
-					nsList = corev1.NamespaceList{}
-
-					opts = []client.ListOption{
-						client.MatchingLabels(listener.AllowedRoutes.Namespaces.Selector.MatchLabels),
-					}
-				)
-
-				if err := r.client.List(ctx, &nsList, opts...); err != nil {
-					log.Error(err, "error fetching namespace for http route")
-					continue
-				}
-
-				if len(nsList.Items) > 0 {
-					for _, item := range nsList.Items {
-						if item.Namespace == httpRoute.Namespace && labelsSatisfyMatchExpressions(item.Labels,
-							listener.AllowedRoutes.Namespaces.Selector.MatchExpressions) {
-							if len(hostnames) == 0 || slices.Contains(hostnames, *listener.Hostname) {
-								return &listener
-							}
-						}
-					}
-				}
-			case v2.NamespacesFromSame:
-				if gw.Namespace == httpRoute.Namespace {
-					if len(hostnames) == 0 || slices.Contains(hostnames, *listener.Hostname) {
-						return &listener
-					}
-				}
-			case v2.NamespacesFromNone:
-				continue
+
+	from := v2.NamespacesFromSame
+
+	var selector *metav1.LabelSelector
+
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil {
+		if listener.AllowedRoutes.Namespaces.From != nil {
+			from = *listener.AllowedRoutes.Namespaces.From
+		}
+
+		selector = listener.AllowedRoutes.Namespaces.Selector
+	}
+
+	switch from {
+	case v2.NamespacesFromAll:
+		return true
+	case v2.NamespacesFromSame:
+		return gw.Namespace == httpRoute.Namespace
+	case v2.NamespacesFromSelector:
+		if selector == nil {
+			return false
+		}
+
+		nsList := corev1.NamespaceList{}
+
+		opts := []client.ListOption{
+			client.MatchingLabels(selector.MatchLabels),
+		}
+
+		if err := r.client.List(ctx, &nsList, opts...); err != nil {
+			log.Error(err, "error fetching namespace for http route")
+			return false
+		}
+
+		for _, item := range nsList.Items {
+			if item.Name == httpRoute.Namespace && labelsSatisfyMatchExpressions(item.Labels, selector.MatchExpressions) {
+				return true
 			}
 		}
 	}
 
-	return nil
+	return false
+}
+
+// getListenerHostname intersects the hostname of the listener with the hostnames of the route.
+// It returns false when they do not intersect, otherwise the most specific hostname of both,
+// empty when that is still a wildcard or no hostname is set at all.
+func getListenerHostname(listener *v2.Listener, hostnames []v2.Hostname) (string, bool) {
+	listenerHostname := ""
+	if listener.Hostname != nil {
+		listenerHostname = string(*listener.Hostname)
+	}
+
+	if len(hostnames) == 0 {
+		return concreteHostname(listenerHostname), true
+	}
+
+	for _, h := range hostnames {
+		routeHostname := string(h)
+
+		switch {
+		case listenerHostname == "" || listenerHostname == routeHostname:
+			return concreteHostname(routeHostname), true
+		case wildcardMatches(listenerHostname, routeHostname):
+			return concreteHostname(routeHostname), true
+		case wildcardMatches(routeHostname, listenerHostname):
+			return concreteHostname(listenerHostname), true
+		}
+	}
+
+	return "", false
+}
+
+// wildcardMatches reports whether the wildcard hostname (*.example.com) covers hostname,
+// wildcards match one or more labels.
+func wildcardMatches(wildcard, hostname string) bool {
+	suffix, ok := strings.CutPrefix(wildcard, "*")
+	if !ok {
+		return false
+	}
+
+	return strings.HasSuffix(hostname, suffix) && len(hostname) > len(suffix)
+}
+
+func concreteHostname(hostname string) string {
+	if strings.HasPrefix(hostname, "*") {
+		return ""
+	}
+
+	return hostname
 }
 
 // GetHTTPRouteTargetPort returns the target port number that should be used
@@ -228,42 +530,397 @@ func GetHTTPRouteTargetPort(cr *v1beta1.Grafana) intstr.IntOrString {
 	return intstr.FromInt(GetGrafanaPort(cr))
 }
 
-// getHTTPRouteSpec builds the desired HTTPRouteSpec for Grafana,
-// wiring its rules to the underlying Service backend.
-func getHTTPRouteSpec(cr *v1beta1.Grafana, scheme *runtime.Scheme) v2.HTTPRouteSpec {
+// getHTTPRouteBackendRefs returns the backend of all rules, the Grafana service.
+func getHTTPRouteBackendRefs(cr *v1beta1.Grafana, scheme *runtime.Scheme) []v2.HTTPBackendRef {
 	service := model.GetGrafanaService(cr, scheme)
 
 	port := GetHTTPRouteTargetPort(cr)
-	serviceName := v2.ObjectName(service.GetName())
 	serviceNamespace := v2.Namespace(service.GetNamespace())
 	servicePort := v2.PortNumber(port.IntVal)
 
+	return []v2.HTTPBackendRef{
+		{
+			BackendRef: v2.BackendRef{
+				BackendObjectReference: v2.BackendObjectReference{
+					Name:      v2.ObjectName(service.GetName()),
+					Namespace: &serviceNamespace,
+					Port:      &servicePort,
+				},
+			},
+		},
+	}
+}
+
+// toHTTPRouteRule converts a rule of spec.httpRoute.rules into a rule targeting the Grafana service.
+func toHTTPRouteRule(cr *v1beta1.Grafana, rule v1beta1.GrafanaHTTPRouteRule, backendRefs []v2.HTTPBackendRef) v2.HTTPRouteRule {
+	return v2.HTTPRouteRule{
+		Matches:     rule.Matches,
+		Filters:     withHTTPRouteFilters(cr, rule.Filters),
+		Timeouts:    rule.Timeouts,
+		BackendRefs: backendRefs,
+	}
+}
+
+// withHTTPRouteFilters prepends the filters of spec.httpRoute.filters to the filters of a rule.
+// Filters of the rule take precedence over the ones of the same type, as a rule may hold only one of each.
+func withHTTPRouteFilters(cr *v1beta1.Grafana, filters []v2.HTTPRouteFilter) []v2.HTTPRouteFilter {
+	if len(cr.Spec.HTTPRoute.Filters) == 0 {
+		return filters
+	}
+
+	result := make([]v2.HTTPRouteFilter, 0, len(cr.Spec.HTTPRoute.Filters)+len(filters))
+
+	for _, filter := range cr.Spec.HTTPRoute.Filters {
+		conflicts := slices.ContainsFunc(filters, func(f v2.HTTPRouteFilter) bool {
+			return httpRouteFiltersConflict(filter.Type, f.Type)
+		})
+		if !conflicts {
+			result = append(result, filter)
+		}
+	}
+
+	return append(result, filters...)
+}
+
+// httpRouteFiltersConflict reports whether a rule cannot hold filters of both types.
+// Only RequestMirror and ExtensionRef filters may be repeated, redirects cannot be combined with rewrites.
+func httpRouteFiltersConflict(a, b v2.HTTPRouteFilterType) bool {
+	if a == b {
+		return a != v2.HTTPRouteFilterRequestMirror && a != v2.HTTPRouteFilterExtensionRef
+	}
+
+	redirectOrRewrite := []v2.HTTPRouteFilterType{v2.HTTPRouteFilterRequestRedirect, v2.HTTPRouteFilterURLRewrite}
+
+	return slices.Contains(redirectOrRewrite, a) && slices.Contains(redirectOrRewrite, b)
+}
+
+// getHTTPRouteFilterFeatures returns the Gateway API features of extended support the filters depend on.
+func getHTTPRouteFilterFeatures(filters []v2.HTTPRouteFilter) []features.FeatureName {
+	var required []features.FeatureName
+
+	for _, filter := range filters {
+		switch filter.Type {
+		case v2.HTTPRouteFilterResponseHeaderModifier:
+			required = append(required, features.SupportHTTPRouteResponseHeaderModification)
+		case v2.HTTPRouteFilterRequestMirror:
+			required = append(required, features.SupportHTTPRouteRequestMirror)
+		case v2.HTTPRouteFilterRequestRedirect:
+			if filter.RequestRedirect == nil {
+				continue
+			}
+
+			if filter.RequestRedirect.Scheme != nil {
+				required = append(required, features.SupportHTTPRouteSchemeRedirect)
+			}
+
+			if filter.RequestRedirect.Port != nil {
+				required = append(required, features.SupportHTTPRoutePortRedirect)
+			}
+
+			if filter.RequestRedirect.Path != nil {
+				required = append(required, features.SupportHTTPRoutePathRedirect)
+			}
+		case v2.HTTPRouteFilterURLRewrite:
+			if filter.URLRewrite == nil {
+				continue
+			}
+
+			if filter.URLRewrite.Hostname != nil {
+				required = append(required, features.SupportHTTPRouteHostRewrite)
+			}
+
+			if filter.URLRewrite.Path != nil {
+				required = append(required, features.SupportHTTPRoutePathRewrite)
+			}
+		}
+	}
+
+	return required
+}
+
+// validateHTTPRouteFilters checks that the GatewayClasses of the parent Gateways support the filters of all rules.
+// GatewayClasses not publishing their supported features, or that can't be read, are assumed to support them.
+// Operators restricted to namespaces skip the check, the Gateways reject unsupported filters in the route status instead
+func (r *HTTPRouteReconciler) validateHTTPRouteFilters(ctx context.Context, cr *v1beta1.Grafana, spec v2.HTTPRouteSpec, scheme *runtime.Scheme) error {
+	log := logf.FromContext(ctx)
+
+	if !r.clusterScoped {
+		return nil
+	}
+
+	rules := slices.Clone(spec.Rules)
+	for _, hostnameSpec := range getHostnameHTTPRouteSpecs(cr, scheme) {
+		rules = append(rules, hostnameSpec.Rules...)
+	}
+
+	var required []features.FeatureName
+	for _, rule := range rules {
+		required = append(required, getHTTPRouteFilterFeatures(rule.Filters)...)
+	}
+
+	if len(required) == 0 {
+		return nil
+	}
+
+	slices.Sort(required)
+	required = slices.Compact(required)
+
+	for _, pr := range spec.ParentRefs {
+		if !isGatewayParentRef(pr) {
+			continue
+		}
+
+		namespace := cr.Namespace
+		if pr.Namespace != nil {
+			namespace = string(*pr.Namespace)
+		}
+
+		gw := &v2.Gateway{}
+
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: string(pr.Name)}, gw)
+		if err != nil {
+			log.Error(err, "error fetching gateway of http route", "gateway", pr.Name, "namespace", namespace)
+			continue
+		}
+
+		gwc := &v2.GatewayClass{}
+
+		err = r.client.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gwc)
+		if kuberr.IsForbidden(err) || meta.IsNoMatchError(err) {
+			log.V(1).Info("gateway class of http route can't be read, skipping the filter check", "gatewayClass", gw.Spec.GatewayClassName, "reason", err.Error())
+			continue
+		}
+
+		if err != nil {
+			log.Error(err, "error fetching gateway class of http route", "gatewayClass", gw.Spec.GatewayClassName)
+			continue
+		}
+
+		if len(gwc.Status.SupportedFeatures) == 0 {
+			continue
+		}
+
+		var unsupported []string
+
+		for _, feature := range required {
+			supported := slices.ContainsFunc(gwc.Status.SupportedFeatures, func(f v2.SupportedFeature) bool {
+				return f.Name == v2.FeatureName(feature)
+			})
+			if !supported {
+				unsupported = append(unsupported, string(feature))
+			}
+		}
+
+		if len(unsupported) > 0 {
+			return fmt.Errorf("gateway class %s of gateway %s/%s does not support the http route filter features: %s",
+				gwc.Name, gw.Namespace, gw.Name, strings.Join(unsupported, ", "))
+		}
+	}
+
+	return nil
+}
+
+// getHTTPRouteFilterRejection returns an error when a Gateway refused the rules of the HTTPRoute
+// because of filters its implementation does not support.
+func getHTTPRouteFilterRejection(httpRoute *v2.HTTPRoute) error {
+	for _, parent := range httpRoute.Status.Parents {
+		for _, condition := range parent.Conditions {
+			if condition.Type != string(v2.RouteConditionAccepted) || condition.Status != metav1.ConditionFalse {
+				continue
+			}
+
+			switch v2.RouteConditionReason(condition.Reason) {
+			case v2.RouteReasonUnsupportedValue, v2.RouteReasonIncompatibleFilters:
+				return fmt.Errorf("http route was not accepted by gateway %s: %s", parent.ParentRef.Name, condition.Message)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getHTTPRouteSpec builds the desired HTTPRouteSpec for Grafana,
+// wiring its rules to the underlying Service backend.
+// Without any rule, all requests are routed to Grafana.
+func getHTTPRouteSpec(cr *v1beta1.Grafana, scheme *runtime.Scheme) v2.HTTPRouteSpec {
+	backendRefs := getHTTPRouteBackendRefs(cr, scheme)
+
+	hostnames := slices.Clone(cr.Spec.HTTPRoute.Spec.Hostnames)
+	for _, hostname := range cr.Spec.HTTPRoute.Hostnames {
+		if !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	rules := make([]v2.HTTPRouteRule, 0, len(cr.Spec.HTTPRoute.Spec.Rules)+len(cr.Spec.HTTPRoute.Rules))
+	for _, rule := range cr.Spec.HTTPRoute.Spec.Rules {
+		rule.Filters = withHTTPRouteFilters(cr, rule.Filters)
+		rule.BackendRefs = backendRefs
+		rules = append(rules, rule)
+	}
+
+	for _, rule := range cr.Spec.HTTPRoute.Rules {
+		if len(rule.Hostnames) == 0 {
+			rules = append(rules, toHTTPRouteRule(cr, rule, backendRefs))
+		}
+	}
+
+	if len(rules) == 0 {
+		rules = append(rules, v2.HTTPRouteRule{
+			Matches: []v2.HTTPRouteMatch{
+				{
+					Path: &v2.HTTPPathMatch{
+						Type:  ptr.To(v2.PathMatchPathPrefix),
+						Value: ptr.To("/"),
+					},
+				},
+			},
+			Filters:     withHTTPRouteFilters(cr, nil),
+			BackendRefs: backendRefs,
+		})
+	}
+
 	return v2.HTTPRouteSpec{
 		CommonRouteSpec: v2.CommonRouteSpec{
 			ParentRefs: cr.Spec.HTTPRoute.Spec.ParentRefs,
 		},
-		Hostnames: cr.Spec.HTTPRoute.Spec.Hostnames,
-		Rules: func(cr *v1beta1.Grafana) []v2.HTTPRouteRule {
-			{
-				rules := make([]v2.HTTPRouteRule, 0, len(cr.Spec.HTTPRoute.Spec.Rules))
-				for _, rule := range cr.Spec.HTTPRoute.Spec.Rules {
-					rule.BackendRefs = []v2.HTTPBackendRef{
-						{
-							BackendRef: v2.BackendRef{
-								BackendObjectReference: v2.BackendObjectReference{
-									Name:      serviceName,
-									Namespace: &serviceNamespace,
-									Port:      &servicePort,
-								},
-							},
-						},
-					}
-					rules = append(rules, rule)
-				}
-				return rules
+		Hostnames: hostnames,
+		Rules:     rules,
+	}
+}
+
+// getHostnameHTTPRouteSpecs groups the rules of spec.httpRoute.rules restricted to hostnames by their set of hostnames.
+// Every set is applied through its own HTTPRoute, keyed by a name suffix derived from the hostnames.
+func getHostnameHTTPRouteSpecs(cr *v1beta1.Grafana, scheme *runtime.Scheme) map[string]v2.HTTPRouteSpec {
+	backendRefs := getHTTPRouteBackendRefs(cr, scheme)
+	specs := map[string]v2.HTTPRouteSpec{}
+
+	for _, rule := range cr.Spec.HTTPRoute.Rules {
+		if len(rule.Hostnames) == 0 {
+			continue
+		}
+
+		hostnames := slices.Clone(rule.Hostnames)
+		slices.Sort(hostnames)
+		hostnames = slices.Compact(hostnames)
+
+		key := make([]string, 0, len(hostnames))
+		for _, hostname := range hostnames {
+			key = append(key, string(hostname))
+		}
+
+		suffix := fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(key, ","))))[:8]
+
+		spec, ok := specs[suffix]
+		if !ok {
+			spec = v2.HTTPRouteSpec{
+				CommonRouteSpec: v2.CommonRouteSpec{
+					ParentRefs: cr.Spec.HTTPRoute.Spec.ParentRefs,
+				},
+				Hostnames: hostnames,
+			}
+		}
+
+		spec.Rules = append(spec.Rules, toHTTPRouteRule(cr, rule, backendRefs))
+		specs[suffix] = spec
+	}
+
+	return specs
+}
+
+// reconcileHostnameHTTPRoutes applies the HTTPRoutes of rules restricted to hostnames
+// and removes the ones of hostnames no longer referenced.
+func (r *HTTPRouteReconciler) reconcileHostnameHTTPRoutes(ctx context.Context, cr *v1beta1.Grafana, scheme *runtime.Scheme) error {
+	log := logf.FromContext(ctx)
+
+	prefix := model.GetGrafanaHTTPRoute(cr, scheme).Name + "-"
+
+	// hostnames of passed through connections are routed by the TLSRoute
+	specs := map[string]v2.HTTPRouteSpec{}
+	if !usesTLSRoute(cr) {
+		specs = getHostnameHTTPRouteSpecs(cr, scheme)
+	}
+
+	for suffix, spec := range specs {
+		httpRoute := model.GetGrafanaHTTPRoute(cr, scheme)
+		httpRoute.Name = prefix + suffix
+
+		_, err := controllerutil.CreateOrUpdate(ctx, r.client, httpRoute, func() error {
+			httpRoute.Spec = spec
+			httpRoute.ObjectMeta = cr.Spec.HTTPRoute.ObjectMeta.Merge(httpRoute.ObjectMeta)
+
+			err := controllerutil.SetControllerReference(cr, httpRoute, scheme)
+			if err != nil {
+				return err
 			}
-		}(cr),
+
+			model.SetInheritedLabels(httpRoute, cr.Labels)
+			model.SetOwnershipAnnotations(httpRoute, cr.Annotations)
+			model.SetDNSAnnotations(httpRoute, cr.Spec.DNS)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("applying http route %s: %w", httpRoute.Name, err)
+		}
+	}
+
+	routes := &v2.HTTPRouteList{}
+
+	err := r.client.List(ctx, routes, client.InNamespace(cr.Namespace))
+	if err != nil {
+		return fmt.Errorf("listing http routes: %w", err)
 	}
+
+	for i := range routes.Items {
+		route := &routes.Items[i]
+
+		suffix, ok := strings.CutPrefix(route.Name, prefix)
+		if !ok || !metav1.IsControlledBy(route, cr) {
+			continue
+		}
+
+		if _, wanted := specs[suffix]; wanted {
+			continue
+		}
+
+		log.Info("removing http route of hostnames no longer referenced", "httpRoute", route.Name)
+
+		if err := r.client.Delete(ctx, route); err != nil && !kuberr.IsNotFound(err) {
+			return fmt.Errorf("removing http route %s: %w", route.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// removeReferenceGrant deletes the ReferenceGrant earlier releases created for Gateways in other namespaces. Gateways
+// don't need a grant to reference the Grafana service and the route lives in the namespace of the service.
+func (r *HTTPRouteReconciler) removeReferenceGrant(ctx context.Context, cr *v1beta1.Grafana, scheme *runtime.Scheme) error {
+	log := logf.FromContext(ctx)
+
+	referenceGrant := model.GetGrafanaReferenceGrant(cr, scheme)
+
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(referenceGrant), referenceGrant)
+	if kuberr.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching reference grant: %w", err)
+	}
+
+	if !metav1.IsControlledBy(referenceGrant, cr) {
+		return nil
+	}
+
+	log.Info("removing reference grant no longer needed", "referenceGrant", referenceGrant.Name)
+
+	if err := r.client.Delete(ctx, referenceGrant); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing reference grant %s: %w", referenceGrant.Name, err)
+	}
+
+	return nil
 }
 
 // labelsSatisfyMatchExpressions checks if a given label set satisfies
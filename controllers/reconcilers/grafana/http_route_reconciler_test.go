@@ -0,0 +1,672 @@
+package grafana
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	v2 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestGetHTTPRouteAdminURL(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v2.Install(s))
+
+	gw := &v2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"},
+		Spec: v2.GatewaySpec{
+			Listeners: []v2.Listener{
+				{
+					Name:     "http",
+					Protocol: v2.HTTPProtocolType,
+					Port:     80,
+				},
+				{
+					Name:     "https",
+					Protocol: v2.HTTPSProtocolType,
+					Port:     443,
+					Hostname: ptr.To(v2.Hostname("*.example.com")),
+					TLS:      &v2.GatewayTLSConfig{Mode: ptr.To(v2.TLSModeTerminate)},
+				},
+				{
+					Name:     "internal",
+					Protocol: v2.HTTPSProtocolType,
+					Port:     8443,
+					Hostname: ptr.To(v2.Hostname("grafana.internal")),
+					AllowedRoutes: &v2.AllowedRoutes{
+						Namespaces: &v2.RouteNamespaces{From: ptr.To(v2.NamespacesFromAll)},
+					},
+				},
+			},
+		},
+		Status: v2.GatewayStatus{
+			Addresses: []v2.GatewayStatusAddress{{Value: "10.0.0.1"}},
+		},
+	}
+
+	r := &HTTPRouteReconciler{
+		client: fake.NewClientBuilder().WithScheme(s).WithObjects(gw).Build(),
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		parentRef v2.ParentReference
+		hostnames []v2.Hostname
+		client    *v1beta1.GrafanaClient
+		want      string
+	}{
+		{
+			name:      "first listener without hostname uses the gateway address",
+			parentRef: v2.ParentReference{Name: "gateway"},
+			want:      "http://10.0.0.1:80",
+		},
+		{
+			name:      "section name selects the https listener",
+			parentRef: v2.ParentReference{Name: "gateway", SectionName: ptr.To(v2.SectionName("https"))},
+			hostnames: []v2.Hostname{"grafana.example.com"},
+			want:      "https://grafana.example.com:443",
+		},
+		{
+			name:      "wildcard listener without route hostname uses the gateway address",
+			parentRef: v2.ParentReference{Name: "gateway", SectionName: ptr.To(v2.SectionName("https"))},
+			want:      "https://10.0.0.1:443",
+		},
+		{
+			name:      "port selects the listener",
+			parentRef: v2.ParentReference{Name: "gateway", Port: ptr.To(v2.PortNumber(443))},
+			hostnames: []v2.Hostname{"grafana.example.com"},
+			want:      "https://grafana.example.com:443",
+		},
+		{
+			name:      "hostname not matching the section",
+			parentRef: v2.ParentReference{Name: "gateway", SectionName: ptr.To(v2.SectionName("https"))},
+			hostnames: []v2.Hostname{"grafana.example.org"},
+			want:      "",
+		},
+		{
+			name:      "route of another namespace only matches listeners allowing it",
+			namespace: "monitoring",
+			parentRef: v2.ParentReference{Name: "gateway", Namespace: ptr.To(v2.Namespace("default"))},
+			want:      "https://grafana.internal:8443",
+		},
+		{
+			name:      "parent namespace defaults to the route namespace",
+			namespace: "monitoring",
+			parentRef: v2.ParentReference{Name: "gateway"},
+			want:      "",
+		},
+		{
+			name:      "parents other than gateways are skipped",
+			parentRef: v2.ParentReference{Name: "gateway", Kind: ptr.To(v2.Kind("Service")), Group: ptr.To(v2.Group(""))},
+			want:      "",
+		},
+		{
+			name:      "hostname strategy does not fall back to the gateway address",
+			parentRef: v2.ParentReference{Name: "gateway"},
+			client:    &v1beta1.GrafanaClient{AdminURLStrategy: v1beta1.AdminURLStrategyHostname},
+			want:      "",
+		},
+		{
+			name:      "loadBalancerIP strategy ignores the hostname",
+			parentRef: v2.ParentReference{Name: "gateway", SectionName: ptr.To(v2.SectionName("https"))},
+			hostnames: []v2.Hostname{"grafana.example.com"},
+			client:    &v1beta1.GrafanaClient{AdminURLStrategy: v1beta1.AdminURLStrategyLoadBalancerIP},
+			want:      "https://10.0.0.1:443",
+		},
+		{
+			name:      "explicit strategy renders the template",
+			parentRef: v2.ParentReference{Name: "gateway", SectionName: ptr.To(v2.SectionName("https"))},
+			hostnames: []v2.Hostname{"grafana.example.com"},
+			client: &v1beta1.GrafanaClient{
+				AdminURLStrategy: v1beta1.AdminURLStrategyExplicit,
+				AdminURLTemplate: "{{ .Scheme }}://{{ .Hostname }}/grafana",
+			},
+			want: "https://grafana.example.com/grafana",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := tt.namespace
+			if namespace == "" {
+				namespace = "default"
+			}
+
+			route := &v2.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: namespace},
+				Spec: v2.HTTPRouteSpec{
+					CommonRouteSpec: v2.CommonRouteSpec{ParentRefs: []v2.ParentReference{tt.parentRef}},
+					Hostnames:       tt.hostnames,
+				},
+			}
+
+			cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Client: tt.client}}
+
+			got, err := r.getHTTPRouteAdminURL(t.Context(), cr, route)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetListenerHostname(t *testing.T) {
+	tests := []struct {
+		name      string
+		listener  string
+		hostnames []v2.Hostname
+		want      string
+		matches   bool
+	}{
+		{name: "no hostnames", matches: true},
+		{name: "listener only", listener: "grafana.example.com", want: "grafana.example.com", matches: true},
+		{name: "route only", hostnames: []v2.Hostname{"grafana.example.com"}, want: "grafana.example.com", matches: true},
+		{name: "wildcard listener", listener: "*.example.com", hostnames: []v2.Hostname{"grafana.example.com"}, want: "grafana.example.com", matches: true},
+		{name: "wildcard route", listener: "grafana.example.com", hostnames: []v2.Hostname{"*.example.com"}, want: "grafana.example.com", matches: true},
+		{name: "both wildcards", listener: "*.example.com", hostnames: []v2.Hostname{"*.example.com"}, matches: true},
+		{name: "wildcard does not match the apex", listener: "*.example.com", hostnames: []v2.Hostname{"example.com"}},
+		{name: "second route hostname", listener: "grafana.example.org", hostnames: []v2.Hostname{"grafana.example.com", "grafana.example.org"}, want: "grafana.example.org", matches: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener := &v2.Listener{}
+			if tt.listener != "" {
+				listener.Hostname = ptr.To(v2.Hostname(tt.listener))
+			}
+
+			got, ok := getListenerHostname(listener, tt.hostnames)
+			assert.Equal(t, tt.matches, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetHTTPRouteSpec(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			HTTPRoute: &v1beta1.HTTPRouteV1{
+				Spec: v2.HTTPRouteSpec{
+					Hostnames: []v2.Hostname{"grafana.example.com"},
+				},
+			},
+		},
+	}
+
+	t.Run("default rule", func(t *testing.T) {
+		spec := getHTTPRouteSpec(cr, s)
+
+		require.Len(t, spec.Rules, 1)
+		assert.Equal(t, "/", *spec.Rules[0].Matches[0].Path.Value)
+		assert.Equal(t, v2.ObjectName("grafana-service"), spec.Rules[0].BackendRefs[0].Name)
+	})
+
+	t.Run("hostnames and rules", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.HTTPRoute.Hostnames = []v2.Hostname{"grafana.example.com", "grafana.example.org"}
+		cr.Spec.HTTPRoute.Rules = []v1beta1.GrafanaHTTPRouteRule{
+			{
+				Matches: []v2.HTTPRouteMatch{{
+					Headers: []v2.HTTPHeaderMatch{{Name: "X-Tenant", Value: "ops"}},
+				}},
+			},
+			{
+				Hostnames: []v2.Hostname{"ops.example.com"},
+				Filters: []v2.HTTPRouteFilter{{
+					Type: v2.HTTPRouteFilterURLRewrite,
+					URLRewrite: &v2.HTTPURLRewriteFilter{
+						Path: &v2.HTTPPathModifier{Type: v2.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+					},
+				}},
+			},
+		}
+
+		spec := getHTTPRouteSpec(cr, s)
+
+		assert.Equal(t, []v2.Hostname{"grafana.example.com", "grafana.example.org"}, spec.Hostnames)
+		require.Len(t, spec.Rules, 1)
+		assert.Equal(t, "X-Tenant", string(spec.Rules[0].Matches[0].Headers[0].Name))
+		assert.NotEmpty(t, spec.Rules[0].BackendRefs)
+
+		specs := getHostnameHTTPRouteSpecs(cr, s)
+		require.Len(t, specs, 1)
+
+		for _, spec := range specs {
+			assert.Equal(t, []v2.Hostname{"ops.example.com"}, spec.Hostnames)
+			require.Len(t, spec.Rules, 1)
+			assert.Equal(t, v2.HTTPRouteFilterURLRewrite, spec.Rules[0].Filters[0].Type)
+			assert.NotEmpty(t, spec.Rules[0].BackendRefs)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		rewrite := v2.HTTPRouteFilter{
+			Type: v2.HTTPRouteFilterURLRewrite,
+			URLRewrite: &v2.HTTPURLRewriteFilter{
+				Path: &v2.HTTPPathModifier{Type: v2.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+			},
+		}
+		headers := v2.HTTPRouteFilter{
+			Type: v2.HTTPRouteFilterRequestHeaderModifier,
+			RequestHeaderModifier: &v2.HTTPHeaderFilter{
+				Set: []v2.HTTPHeader{{Name: "X-Forwarded-Prefix", Value: "/grafana"}},
+			},
+		}
+		redirect := v2.HTTPRouteFilter{
+			Type:            v2.HTTPRouteFilterRequestRedirect,
+			RequestRedirect: &v2.HTTPRequestRedirectFilter{Scheme: ptr.To("https")},
+		}
+
+		cr := cr.DeepCopy()
+		cr.Spec.HTTPRoute.Filters = []v2.HTTPRouteFilter{rewrite, headers}
+
+		spec := getHTTPRouteSpec(cr, s)
+
+		require.Len(t, spec.Rules, 1)
+		assert.Equal(t, []v2.HTTPRouteFilter{rewrite, headers}, spec.Rules[0].Filters)
+
+		cr.Spec.HTTPRoute.Rules = []v1beta1.GrafanaHTTPRouteRule{
+			{Filters: []v2.HTTPRouteFilter{redirect}},
+			{Hostnames: []v2.Hostname{"ops.example.com"}},
+		}
+
+		spec = getHTTPRouteSpec(cr, s)
+
+		// A redirect replaces the rewrite of spec.httpRoute.filters
+		require.Len(t, spec.Rules, 1)
+		assert.Equal(t, []v2.HTTPRouteFilter{headers, redirect}, spec.Rules[0].Filters)
+
+		for _, spec := range getHostnameHTTPRouteSpecs(cr, s) {
+			assert.Equal(t, []v2.HTTPRouteFilter{rewrite, headers}, spec.Rules[0].Filters)
+		}
+	})
+}
+
+func TestValidateHTTPRouteFilters(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, v2.Install(s))
+
+	gw := &v2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"},
+		Spec:       v2.GatewaySpec{GatewayClassName: "example"},
+	}
+	gwc := &v2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Status: v2.GatewayClassStatus{
+			SupportedFeatures: []v2.SupportedFeature{{Name: "HTTPRoute"}, {Name: "HTTPRoutePathRewrite"}},
+		},
+	}
+
+	r := &HTTPRouteReconciler{
+		client:        fake.NewClientBuilder().WithScheme(s).WithObjects(gw, gwc).Build(),
+		clusterScoped: true,
+	}
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			HTTPRoute: &v1beta1.HTTPRouteV1{
+				Spec: v2.HTTPRouteSpec{
+					CommonRouteSpec: v2.CommonRouteSpec{
+						ParentRefs: []v2.ParentReference{{Name: "gateway"}},
+					},
+				},
+				Filters: []v2.HTTPRouteFilter{
+					{
+						Type: v2.HTTPRouteFilterURLRewrite,
+						URLRewrite: &v2.HTTPURLRewriteFilter{
+							Path: &v2.HTTPPathModifier{Type: v2.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.To("/")},
+						},
+					},
+					{
+						Type:                  v2.HTTPRouteFilterRequestHeaderModifier,
+						RequestHeaderModifier: &v2.HTTPHeaderFilter{Remove: []string{"X-Debug"}},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("supported filters", func(t *testing.T) {
+		err := r.validateHTTPRouteFilters(t.Context(), cr, getHTTPRouteSpec(cr, s), s)
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported filters", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.HTTPRoute.Rules = []v1beta1.GrafanaHTTPRouteRule{{
+			Hostnames: []v2.Hostname{"ops.example.com"},
+			Filters: []v2.HTTPRouteFilter{{
+				Type:                   v2.HTTPRouteFilterResponseHeaderModifier,
+				ResponseHeaderModifier: &v2.HTTPHeaderFilter{Remove: []string{"Server"}},
+			}},
+		}}
+
+		err := r.validateHTTPRouteFilters(t.Context(), cr, getHTTPRouteSpec(cr, s), s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTPRouteResponseHeaderModification")
+	})
+
+	t.Run("gateway class without supported features", func(t *testing.T) {
+		gwc := gwc.DeepCopy()
+		gwc.Status.SupportedFeatures = nil
+
+		r := &HTTPRouteReconciler{
+			client:        fake.NewClientBuilder().WithScheme(s).WithObjects(gw, gwc).Build(),
+			clusterScoped: true,
+		}
+
+		cr := cr.DeepCopy()
+		cr.Spec.HTTPRoute.Filters = append(cr.Spec.HTTPRoute.Filters, v2.HTTPRouteFilter{
+			Type:          v2.HTTPRouteFilterRequestMirror,
+			RequestMirror: &v2.HTTPRequestMirrorFilter{BackendRef: v2.BackendObjectReference{Name: "mirror"}},
+		})
+
+		err := r.validateHTTPRouteFilters(t.Context(), cr, getHTTPRouteSpec(cr, s), s)
+		require.NoError(t, err)
+	})
+
+	unsupported := cr.DeepCopy()
+	unsupported.Spec.HTTPRoute.Filters = append(unsupported.Spec.HTTPRoute.Filters, v2.HTTPRouteFilter{
+		Type:                   v2.HTTPRouteFilterResponseHeaderModifier,
+		ResponseHeaderModifier: &v2.HTTPHeaderFilter{Remove: []string{"Server"}},
+	})
+
+	t.Run("gateway class not readable", func(t *testing.T) {
+		r := &HTTPRouteReconciler{
+			client: fake.NewClientBuilder().WithScheme(s).WithObjects(gw, gwc).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, cl client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*v2.GatewayClass); ok {
+						return kuberr.NewForbidden(schema.GroupResource{Group: v2.GroupName, Resource: "gatewayclasses"}, key.Name, nil)
+					}
+
+					return cl.Get(ctx, key, obj, opts...)
+				},
+			}).Build(),
+			clusterScoped: true,
+		}
+
+		err := r.validateHTTPRouteFilters(t.Context(), unsupported, getHTTPRouteSpec(unsupported, s), s)
+		require.NoError(t, err)
+	})
+
+	t.Run("namespace scoped operator", func(t *testing.T) {
+		r := &HTTPRouteReconciler{client: r.client}
+
+		err := r.validateHTTPRouteFilters(t.Context(), unsupported, getHTTPRouteSpec(unsupported, s), s)
+		require.NoError(t, err)
+	})
+}
+
+func TestGetHTTPRouteFilterRejection(t *testing.T) {
+	route := &v2.HTTPRoute{
+		Status: v2.HTTPRouteStatus{
+			RouteStatus: v2.RouteStatus{
+				Parents: []v2.RouteParentStatus{{
+					ParentRef: v2.ParentReference{Name: "gateway"},
+					Conditions: []metav1.Condition{{
+						Type:    string(v2.RouteConditionAccepted),
+						Status:  metav1.ConditionTrue,
+						Reason:  string(v2.RouteReasonAccepted),
+						Message: "Route is accepted",
+					}},
+				}},
+			},
+		},
+	}
+
+	require.NoError(t, getHTTPRouteFilterRejection(route))
+
+	route.Status.Parents[0].Conditions[0] = metav1.Condition{
+		Type:    string(v2.RouteConditionAccepted),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(v2.RouteReasonUnsupportedValue),
+		Message: "URLRewrite filter is not supported",
+	}
+
+	err := getHTTPRouteFilterRejection(route)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "URLRewrite filter is not supported")
+}
+
+func TestGetHTTPRouteStatus(t *testing.T) {
+	internal := v2.ParentReference{Name: "internal"}
+	public := v2.ParentReference{Name: "public", Namespace: ptr.To(v2.Namespace("gateways")), SectionName: ptr.To(v2.SectionName("https"))}
+
+	route := &v2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-httproute", Namespace: "default", Generation: 2},
+		Spec: v2.HTTPRouteSpec{
+			CommonRouteSpec: v2.CommonRouteSpec{ParentRefs: []v2.ParentReference{internal, public}},
+		},
+	}
+
+	condition := func(conditionType v2.RouteConditionType, status metav1.ConditionStatus, generation int64) metav1.Condition {
+		return metav1.Condition{Type: string(conditionType), Status: status, ObservedGeneration: generation, Reason: "Reason"}
+	}
+
+	t.Run("not reported", func(t *testing.T) {
+		status := getHTTPRouteStatus(route)
+
+		assert.Equal(t, metav1.ConditionUnknown, status.Accepted)
+		assert.Equal(t, metav1.ConditionUnknown, status.ResolvedRefs)
+		assert.Empty(t, status.Parents)
+	})
+
+	t.Run("accepted by one parent", func(t *testing.T) {
+		route := route.DeepCopy()
+		route.Status.Parents = []v2.RouteParentStatus{{
+			ParentRef: internal,
+			Conditions: []metav1.Condition{
+				condition(v2.RouteConditionAccepted, metav1.ConditionTrue, 2),
+				condition(v2.RouteConditionResolvedRefs, metav1.ConditionTrue, 2),
+			},
+		}}
+
+		status := getHTTPRouteStatus(route)
+
+		assert.Equal(t, metav1.ConditionUnknown, status.Accepted)
+		assert.Equal(t, metav1.ConditionUnknown, status.ResolvedRefs)
+		require.Len(t, status.Parents, 1)
+		assert.Equal(t, "default/internal", status.Parents[0].Parent)
+		assert.Len(t, status.Parents[0].Conditions, 2)
+	})
+
+	t.Run("refs not resolved", func(t *testing.T) {
+		route := route.DeepCopy()
+		route.Status.Parents = []v2.RouteParentStatus{
+			{
+				ParentRef: internal,
+				Conditions: []metav1.Condition{
+					condition(v2.RouteConditionAccepted, metav1.ConditionTrue, 2),
+					condition(v2.RouteConditionResolvedRefs, metav1.ConditionTrue, 2),
+				},
+			},
+			{
+				ParentRef: public,
+				Conditions: []metav1.Condition{
+					condition(v2.RouteConditionAccepted, metav1.ConditionTrue, 2),
+					condition(v2.RouteConditionResolvedRefs, metav1.ConditionFalse, 2),
+					condition(v2.RouteConditionPartiallyInvalid, metav1.ConditionTrue, 2),
+				},
+			},
+		}
+
+		status := getHTTPRouteStatus(route)
+
+		assert.Equal(t, metav1.ConditionTrue, status.Accepted)
+		assert.Equal(t, metav1.ConditionFalse, status.ResolvedRefs)
+		require.Len(t, status.Parents, 2)
+		assert.Equal(t, "gateways/public/https", status.Parents[1].Parent)
+		assert.Len(t, status.Parents[1].Conditions, 2)
+	})
+
+	t.Run("outdated conditions", func(t *testing.T) {
+		route := route.DeepCopy()
+		route.Status.Parents = []v2.RouteParentStatus{
+			{ParentRef: internal, Conditions: []metav1.Condition{condition(v2.RouteConditionAccepted, metav1.ConditionTrue, 2)}},
+			{ParentRef: public, Conditions: []metav1.Condition{condition(v2.RouteConditionAccepted, metav1.ConditionFalse, 1)}},
+		}
+
+		status := getHTTPRouteStatus(route)
+
+		assert.Equal(t, metav1.ConditionUnknown, status.Accepted)
+		assert.Empty(t, status.Parents[1].Conditions)
+	})
+}
+
+func TestReconcileHostnameHTTPRoutes(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, v2.Install(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			HTTPRoute: &v1beta1.HTTPRouteV1{
+				Rules: []v1beta1.GrafanaHTTPRouteRule{
+					{Hostnames: []v2.Hostname{"b.example.com", "a.example.com"}},
+					{Hostnames: []v2.Hostname{"a.example.com", "b.example.com"}},
+					{Hostnames: []v2.Hostname{"c.example.com"}},
+				},
+			},
+		},
+	}
+
+	unrelated := &v2.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "grafana-httproute-other", Namespace: "default"}}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(unrelated).Build()
+	r := &HTTPRouteReconciler{client: cl}
+
+	listRoutes := func() map[string]v2.HTTPRoute {
+		routes := &v2.HTTPRouteList{}
+		require.NoError(t, cl.List(t.Context(), routes))
+
+		byName := map[string]v2.HTTPRoute{}
+		for _, route := range routes.Items {
+			byName[route.Name] = route
+		}
+
+		return byName
+	}
+
+	require.NoError(t, r.reconcileHostnameHTTPRoutes(t.Context(), cr, s))
+
+	routes := listRoutes()
+	require.Len(t, routes, 3)
+
+	rules := 0
+	for _, route := range routes {
+		rules += len(route.Spec.Rules)
+	}
+
+	assert.Equal(t, 3, rules)
+
+	cr.Spec.HTTPRoute.Rules = cr.Spec.HTTPRoute.Rules[2:]
+	require.NoError(t, r.reconcileHostnameHTTPRoutes(t.Context(), cr, s))
+
+	routes = listRoutes()
+	require.Len(t, routes, 2)
+	assert.Contains(t, routes, unrelated.Name)
+}
+
+func TestRemoveReferenceGrant(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, v2.Install(s))
+	require.NoError(t, gwapiv1beta1.Install(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec:       v1beta1.GrafanaSpec{HTTPRoute: &v1beta1.HTTPRouteV1{}},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &HTTPRouteReconciler{client: cl}
+
+	key := client.ObjectKey{Namespace: "default", Name: "grafana-referencegrant"}
+
+	// Nothing to remove
+	require.NoError(t, r.removeReferenceGrant(t.Context(), cr, s))
+
+	// Grants created by earlier releases are removed
+	require.NoError(t, cl.Create(t.Context(), model.GetGrafanaReferenceGrant(cr, s)))
+	require.NoError(t, r.removeReferenceGrant(t.Context(), cr, s))
+	assert.True(t, kuberr.IsNotFound(cl.Get(t.Context(), key, &gwapiv1beta1.ReferenceGrant{})))
+
+	// Grants not owned by the instance are left alone
+	unowned := &gwapiv1beta1.ReferenceGrant{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	require.NoError(t, cl.Create(t.Context(), unowned))
+	require.NoError(t, r.removeReferenceGrant(t.Context(), cr, s))
+	require.NoError(t, cl.Get(t.Context(), key, &gwapiv1beta1.ReferenceGrant{}))
+}
+
+func TestReconcileTLSRoute(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, v2.Install(s))
+	require.NoError(t, gwapiv1alpha2.Install(s))
+	require.NoError(t, gwapiv1beta1.Install(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Config: map[string]map[string]string{"server": {"protocol": "https"}},
+			HTTPRoute: &v1beta1.HTTPRouteV1{
+				Hostnames: []v2.Hostname{"grafana.internal.example.com"},
+				Spec: v2.HTTPRouteSpec{
+					CommonRouteSpec: v2.CommonRouteSpec{
+						ParentRefs: []v2.ParentReference{{Name: "gateway", SectionName: ptr.To(v2.SectionName("tls-passthrough"))}},
+					},
+					Hostnames: []v2.Hostname{"grafana.example.com"},
+				},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := &HTTPRouteReconciler{client: cl}
+
+	tlsRouteKey := client.ObjectKey{Namespace: "default", Name: "grafana-tlsroute"}
+	httpRouteKey := client.ObjectKey{Namespace: "default", Name: "grafana-httproute"}
+
+	status, err := r.reconcileHTTPRoute(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	tlsRoute := &gwapiv1alpha2.TLSRoute{}
+	require.NoError(t, cl.Get(t.Context(), tlsRouteKey, tlsRoute))
+	assert.True(t, metav1.IsControlledBy(tlsRoute, cr))
+	assert.Equal(t, []v2.Hostname{"grafana.example.com", "grafana.internal.example.com"}, tlsRoute.Spec.Hostnames)
+	assert.Equal(t, cr.Spec.HTTPRoute.Spec.ParentRefs, tlsRoute.Spec.ParentRefs)
+	require.Len(t, tlsRoute.Spec.Rules, 1)
+	assert.Equal(t, v2.ObjectName("grafana-service"), tlsRoute.Spec.Rules[0].BackendRefs[0].Name)
+	assert.Equal(t, v2.PortNumber(3000), *tlsRoute.Spec.Rules[0].BackendRefs[0].Port)
+	assert.True(t, kuberr.IsNotFound(cl.Get(t.Context(), httpRouteKey, &v2.HTTPRoute{})))
+	assert.Equal(t, metav1.ConditionUnknown, cr.Status.HTTPRoute.Accepted)
+
+	// Grafana no longer terminates TLS
+	cr.Spec.Config["server"]["protocol"] = "http"
+
+	_, err = r.reconcileHTTPRoute(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(t.Context(), httpRouteKey, &v2.HTTPRoute{}))
+	assert.True(t, kuberr.IsNotFound(cl.Get(t.Context(), tlsRouteKey, &gwapiv1alpha2.TLSRoute{})))
+}
@@ -0,0 +1,465 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	conditionImageRendererAutoscalingUnavailable = "ImageRendererAutoscalingUnavailable"
+	conditionReasonKEDAMissing                   = "KEDANotInstalled"
+
+	// defaultImageRendererThreshold is the render queue length one renderer replica handles
+	defaultImageRendererThreshold = "5"
+)
+
+// ImageRendererReconciler maintains the renderer deployment, service and ScaledObject of instances with a Remote
+// spec.imageRenderer and reports the readiness of the renderer. Sidecars are added to the pod template by the
+// DeploymentReconciler
+type ImageRendererReconciler struct {
+	client           client.Client
+	isOpenShift      bool
+	hasScaledObjects bool
+}
+
+func NewImageRendererReconciler(client client.Client, isOpenShift bool, hasScaledObjects bool) reconcilers.OperatorGrafanaReconciler {
+	return &ImageRendererReconciler{
+		client:           client,
+		isOpenShift:      isOpenShift,
+		hasScaledObjects: hasScaledObjects,
+	}
+}
+
+// Reconcile creates the renderer deployment and service in Remote mode and removes them otherwise. The ScaledObject
+// is only maintained with spec.imageRenderer.autoscaling, without KEDA the ImageRendererAutoscalingUnavailable condition
+// is set instead and the deployment runs the configured replicas
+func (r *ImageRendererReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("ImageRendererReconciler")
+
+	deployment := model.GetGrafanaImageRendererDeployment(cr, scheme)
+	service := model.GetGrafanaImageRendererService(cr, scheme)
+
+	r.setAutoscalingCondition(cr)
+
+	if !r.isAutoscaled(cr) && r.hasScaledObjects {
+		err := r.removeImageRendererObject(ctx, cr, model.GetGrafanaImageRendererScaledObject(cr, scheme))
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+	}
+
+	if !isImageRendererRemote(cr) {
+		for _, obj := range []client.Object{deployment, service} {
+			err := r.removeImageRendererObject(ctx, cr, obj)
+			if err != nil {
+				return v1beta1.OperatorStageResultFailed, err
+			}
+		}
+
+		if cr.Spec.ImageRenderer == nil {
+			cr.Status.ImageRenderer = nil
+			return v1beta1.OperatorStageResultSuccess, nil
+		}
+
+		// The renderer sidecar is ready in the ready Grafana pods
+		ready, err := r.getWorkloadReadyReplicas(ctx, cr)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+
+		setImageRendererStatus(cr, ready)
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.V(1).Info("reconciling image renderer")
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, deployment, func() error {
+		live := deployment.Spec.DeepCopy()
+		deployment.Spec = getImageRendererDeploymentSpec(cr, r.isOpenShift)
+
+		if r.isAutoscaled(cr) {
+			deployment.Spec.Replicas = getImageRendererAutoscaledReplicas(cr, live.Replicas)
+		}
+
+		keepServerDefaults(&deployment.Spec, live)
+
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, deployment, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(deployment, cr.Labels)
+		model.SetOwnershipAnnotations(deployment, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("reconciling image renderer deployment: %w", err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, service, func() error {
+		service.Spec = corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       config.GrafanaImageRendererPortName,
+					Protocol:   "TCP",
+					Port:       int32(config.GrafanaImageRendererPort),
+					TargetPort: intstr.FromString(config.GrafanaImageRendererPortName),
+				},
+			},
+			Selector: getImageRendererSelector(cr),
+			Type:     corev1.ServiceTypeClusterIP,
+		}
+
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, service, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(service, cr.Labels)
+		model.SetOwnershipAnnotations(service, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, fmt.Errorf("reconciling image renderer service: %w", err)
+	}
+
+	if r.isAutoscaled(cr) {
+		scaledObject := model.GetGrafanaImageRendererScaledObject(cr, scheme)
+
+		_, err = controllerutil.CreateOrUpdate(ctx, r.client, scaledObject, func() error {
+			scaledObject.Object["spec"] = getImageRendererScaledObjectSpec(cr)
+
+			if scheme != nil {
+				err := controllerutil.SetControllerReference(cr, scaledObject, scheme)
+				if err != nil {
+					return err
+				}
+			}
+
+			model.SetInheritedLabels(unstructuredMeta{scaledObject}, cr.Labels)
+			model.SetOwnershipAnnotations(unstructuredMeta{scaledObject}, cr.Annotations)
+
+			return nil
+		})
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, fmt.Errorf("reconciling image renderer scaled object: %w", err)
+		}
+	}
+
+	setImageRendererStatus(cr, deployment.Status.ReadyReplicas)
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// isAutoscaled reports whether the replicas of the renderer deployment are left to a ScaledObject
+func (r *ImageRendererReconciler) isAutoscaled(cr *v1beta1.Grafana) bool {
+	return r.hasScaledObjects && isImageRendererRemote(cr) && cr.Spec.ImageRenderer.Autoscaling != nil
+}
+
+func (r *ImageRendererReconciler) setAutoscalingCondition(cr *v1beta1.Grafana) {
+	if r.hasScaledObjects || !isImageRendererRemote(cr) || cr.Spec.ImageRenderer.Autoscaling == nil {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionImageRendererAutoscalingUnavailable)
+		return
+	}
+
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               conditionImageRendererAutoscalingUnavailable,
+		Reason:             conditionReasonKEDAMissing,
+		Message:            "spec.imageRenderer.autoscaling requires the ScaledObject CRD of KEDA, restart the operator after installing it",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cr.Generation,
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+	})
+}
+
+// getImageRendererScaledObjectSpec returns the spec of the ScaledObject, scaling the renderer deployment on the result
+// of a Prometheus query
+func getImageRendererScaledObjectSpec(cr *v1beta1.Grafana) map[string]any {
+	autoscaling := cr.Spec.ImageRenderer.Autoscaling
+
+	query := autoscaling.Query
+	if query == "" {
+		query = fmt.Sprintf(`sum(grafana_rendering_queue_size{namespace="%s",service="%s"})`, cr.Namespace, model.GetGrafanaService(cr, nil).Name)
+	}
+
+	threshold := autoscaling.Threshold
+	if threshold == "" {
+		threshold = defaultImageRendererThreshold
+	}
+
+	spec := map[string]any{
+		"scaleTargetRef": map[string]any{
+			"name": model.GetGrafanaImageRendererDeployment(cr, nil).Name,
+		},
+		"minReplicaCount": int64(getImageRendererMinReplicas(cr)),
+		"maxReplicaCount": int64(autoscaling.MaxReplicas),
+		"triggers": []any{map[string]any{
+			"type": "prometheus",
+			"metadata": map[string]any{
+				"serverAddress": autoscaling.ServerAddress,
+				"query":         query,
+				"threshold":     threshold,
+			},
+		}},
+	}
+
+	if autoscaling.PollingInterval != nil {
+		spec["pollingInterval"] = int64(*autoscaling.PollingInterval)
+	}
+
+	return spec
+}
+
+func getImageRendererMinReplicas(cr *v1beta1.Grafana) int32 {
+	if cr.Spec.ImageRenderer.Autoscaling.MinReplicas == nil {
+		return 1
+	}
+
+	return *cr.Spec.ImageRenderer.Autoscaling.MinReplicas
+}
+
+// getImageRendererAutoscaledReplicas keeps the replicas set by KEDA, new deployments start with minReplicas
+func getImageRendererAutoscaledReplicas(cr *v1beta1.Grafana, live *int32) *int32 {
+	if live != nil {
+		return live
+	}
+
+	return ptr.To(getImageRendererMinReplicas(cr))
+}
+
+func setImageRendererStatus(cr *v1beta1.Grafana, ready int32) {
+	cr.Status.ImageRenderer = &v1beta1.GrafanaImageRendererStatus{
+		URL:           getImageRendererURL(cr),
+		ReadyReplicas: ready,
+		Ready:         ready > 0,
+	}
+}
+
+// getWorkloadReadyReplicas returns the ready replicas of the deployment or statefulset of the instance
+func (r *ImageRendererReconciler) getWorkloadReadyReplicas(ctx context.Context, cr *v1beta1.Grafana) (int32, error) {
+	if cr.IsStatefulSet() {
+		statefulSet := model.GetGrafanaStatefulSet(cr, nil)
+
+		err := r.client.Get(ctx, client.ObjectKeyFromObject(statefulSet), statefulSet)
+		if err != nil {
+			return 0, client.IgnoreNotFound(err)
+		}
+
+		return statefulSet.Status.ReadyReplicas, nil
+	}
+
+	deployment := model.GetGrafanaDeployment(cr, nil)
+
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)
+	if err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+
+	return deployment.Status.ReadyReplicas, nil
+}
+
+// isImageRendererSidecar reports whether the renderer runs as a container of the Grafana pods
+func isImageRendererSidecar(cr *v1beta1.Grafana) bool {
+	return cr.Spec.ImageRenderer != nil && cr.Spec.ImageRenderer.Mode != v1beta1.GrafanaImageRendererRemote
+}
+
+// isImageRendererRemote reports whether the renderer runs in its own deployment
+func isImageRendererRemote(cr *v1beta1.Grafana) bool {
+	return cr.Spec.ImageRenderer != nil && cr.Spec.ImageRenderer.Mode == v1beta1.GrafanaImageRendererRemote
+}
+
+func getImageRendererImage(cr *v1beta1.Grafana) string {
+	if cr.Spec.ImageRenderer.Image != "" {
+		return cr.Spec.ImageRenderer.Image
+	}
+
+	return fmt.Sprintf("%s:%s", config.GrafanaImageRendererImage, config.GrafanaImageRendererVersion)
+}
+
+// getImageRendererURL returns the render endpoint Grafana sends requests to, the sidecar is reached on localhost
+func getImageRendererURL(cr *v1beta1.Grafana) string {
+	host := "localhost"
+	if isImageRendererRemote(cr) {
+		host = fmt.Sprintf("%s.%s.svc", model.GetGrafanaImageRendererService(cr, nil).Name, cr.Namespace)
+	}
+
+	return fmt.Sprintf("http://%s:%d/render", host, config.GrafanaImageRendererPort)
+}
+
+// getImageRendererCallbackURL returns the URL the renderer loads panels and dashboards of the instance from
+func getImageRendererCallbackURL(cr *v1beta1.Grafana) string {
+	host := "localhost"
+	if isImageRendererRemote(cr) {
+		host = fmt.Sprintf("%s.%s.svc", model.GetGrafanaService(cr, nil).Name, cr.Namespace)
+	}
+
+	return fmt.Sprintf("%s://%s:%d/", getGrafanaServerScheme(cr), host, GetGrafanaPort(cr))
+}
+
+// getImageRendererEnvVars returns the env vars pointing Grafana at the renderer. Settings in spec.config take precedence
+func getImageRendererEnvVars(cr *v1beta1.Grafana) []corev1.EnvVar {
+	if cr.Spec.ImageRenderer == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+
+	if cr.GetConfigSectionValue("rendering", "server_url") == "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "GF_RENDERING_SERVER_URL",
+			Value: getImageRendererURL(cr),
+		})
+	}
+
+	if cr.GetConfigSectionValue("rendering", "callback_url") == "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "GF_RENDERING_CALLBACK_URL",
+			Value: getImageRendererCallbackURL(cr),
+		})
+	}
+
+	return envVars
+}
+
+// getImageRendererVolume returns the writable /tmp of the browser of the renderer, the root filesystem is read-only
+func getImageRendererVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: config.GrafanaImageRendererTmpVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+func getImageRendererContainer(cr *v1beta1.Grafana, openshiftPlatform bool) corev1.Container {
+	renderer := cr.Spec.ImageRenderer
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(MemoryRequest),
+			corev1.ResourceCPU:    resource.MustParse(CPURequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(MemoryLimit),
+		},
+	}
+
+	if renderer.Resources != nil {
+		resources = *renderer.Resources.DeepCopy()
+	}
+
+	return corev1.Container{
+		Name:  config.GrafanaImageRendererContainerName,
+		Image: getImageRendererImage(cr),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          config.GrafanaImageRendererPortName,
+				ContainerPort: int32(config.GrafanaImageRendererPort),
+				Protocol:      "TCP",
+			},
+		},
+		Env:       slices.Clone(renderer.Env),
+		Resources: resources,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      config.GrafanaImageRendererTmpVolumeName,
+				MountPath: "/tmp",
+			},
+		},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
+		ImagePullPolicy:          "IfNotPresent",
+		SecurityContext:          getDefaultContainerSecurityContext(cr.Spec.DisableDefaultSecurityContext, openshiftPlatform),
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromString(config.GrafanaImageRendererPortName),
+				},
+			},
+			TimeoutSeconds:   ReadinessProbeTimeoutSeconds,
+			PeriodSeconds:    ReadinessProbePeriodSeconds,
+			SuccessThreshold: ReadinessProbeSuccessThreshold,
+			FailureThreshold: ReadinessProbeFailureThreshold,
+		},
+	}
+}
+
+func getImageRendererSelector(cr *v1beta1.Grafana) map[string]string {
+	return map[string]string{
+		"app": model.GetGrafanaImageRendererDeployment(cr, nil).Name,
+	}
+}
+
+func getImageRendererDeploymentSpec(cr *v1beta1.Grafana, openshiftPlatform bool) appsv1.DeploymentSpec {
+	replicas := cr.Spec.ImageRenderer.Replicas
+	if replicas == nil {
+		replicas = ptr.To[int32](1)
+	}
+
+	return appsv1.DeploymentSpec{
+		Replicas: ptr.To(*replicas),
+		Selector: &metav1.LabelSelector{
+			MatchLabels: getImageRendererSelector(cr),
+		},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: getImageRendererSelector(cr),
+			},
+			Spec: corev1.PodSpec{
+				Volumes:         []corev1.Volume{getImageRendererVolume()},
+				Containers:      []corev1.Container{getImageRendererContainer(cr, openshiftPlatform)},
+				SecurityContext: getDefaultPodSecurityContext(cr.Spec.DisableDefaultSecurityContext),
+			},
+		},
+	}
+}
+
+// removeImageRendererObject deletes the renderer deployment, service or ScaledObject if it was created for the instance
+func (r *ImageRendererReconciler) removeImageRendererObject(ctx context.Context, cr *v1beta1.Grafana, obj client.Object) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching image renderer %s: %w", obj.GetName(), err)
+	}
+
+	if !metav1.IsControlledBy(obj, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing image renderer", "name", obj.GetName())
+
+	if err := r.client.Delete(ctx, obj); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing image renderer %s: %w", obj.GetName(), err)
+	}
+
+	return nil
+}
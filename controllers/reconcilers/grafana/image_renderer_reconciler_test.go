@@ -0,0 +1,243 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetImageRendererEnvVars(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec: v1beta1.GrafanaSpec{
+			ImageRenderer: &v1beta1.GrafanaImageRenderer{},
+		},
+	}
+
+	t.Run("sidecar", func(t *testing.T) {
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "GF_RENDERING_SERVER_URL", Value: "http://localhost:8081/render"},
+			{Name: "GF_RENDERING_CALLBACK_URL", Value: "http://localhost:3000/"},
+		}, getImageRendererEnvVars(cr))
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.ImageRenderer.Mode = v1beta1.GrafanaImageRendererRemote
+		cr.Spec.Config = map[string]map[string]string{"server": {"protocol": "https", "http_port": "3443"}}
+
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "GF_RENDERING_SERVER_URL", Value: "http://grafana-image-renderer.monitoring.svc:8081/render"},
+			{Name: "GF_RENDERING_CALLBACK_URL", Value: "https://grafana-service.monitoring.svc:3443/"},
+		}, getImageRendererEnvVars(cr))
+	})
+
+	t.Run("config takes precedence", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Config = map[string]map[string]string{"rendering": {"callback_url": "https://grafana.example.com/"}}
+
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "GF_RENDERING_SERVER_URL", Value: "http://localhost:8081/render"},
+		}, getImageRendererEnvVars(cr))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Empty(t, getImageRendererEnvVars(&v1beta1.Grafana{}))
+	})
+}
+
+func TestImageRendererSidecar(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			ImageRenderer: &v1beta1.GrafanaImageRenderer{Image: "renderer:test"},
+		},
+	}
+
+	spec := getDeploymentSpec(cr, "grafana-deployment", scheme, &v1beta1.OperatorReconcileVars{}, false, nil)
+
+	require.Len(t, spec.Template.Spec.Containers, 2)
+
+	sidecar := spec.Template.Spec.Containers[1]
+	assert.Equal(t, config.GrafanaImageRendererContainerName, sidecar.Name)
+	assert.Equal(t, "renderer:test", sidecar.Image)
+	assert.Empty(t, sidecar.Env, "admin credentials stay in the grafana container")
+	assert.Contains(t, spec.Template.Spec.Volumes, getImageRendererVolume())
+
+	cr.Spec.ImageRenderer.Mode = v1beta1.GrafanaImageRendererRemote
+
+	spec = getDeploymentSpec(cr, "grafana-deployment", scheme, &v1beta1.OperatorReconcileVars{}, false, nil)
+	assert.Len(t, spec.Template.Spec.Containers, 1)
+	assert.NotContains(t, spec.Template.Spec.Volumes, getImageRendererVolume())
+}
+
+func TestImageRendererReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, appsv1.AddToScheme(s))
+	require.NoError(t, corev1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			ImageRenderer: &v1beta1.GrafanaImageRenderer{Mode: v1beta1.GrafanaImageRendererRemote},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewImageRendererReconciler(cl, false, false)
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	deployment := model.GetGrafanaImageRendererDeployment(cr, nil)
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), deployment))
+	assert.True(t, metav1.IsControlledBy(deployment, cr))
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	assert.Equal(t, "grafana-image-renderer", deployment.Spec.Template.Labels["app"])
+
+	service := model.GetGrafanaImageRendererService(cr, nil)
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(service), service))
+	assert.Equal(t, deployment.Spec.Selector.MatchLabels, service.Spec.Selector)
+
+	assert.Equal(t, &v1beta1.GrafanaImageRendererStatus{URL: "http://grafana-image-renderer.default.svc:8081/render"}, cr.Status.ImageRenderer)
+
+	deployment.Status.ReadyReplicas = 1
+	require.NoError(t, cl.Status().Update(t.Context(), deployment))
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.True(t, cr.Status.ImageRenderer.Ready)
+
+	cr.Spec.ImageRenderer.Mode = v1beta1.GrafanaImageRendererSidecar
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	err = cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})
+	assert.True(t, kuberr.IsNotFound(err))
+
+	err = cl.Get(t.Context(), client.ObjectKeyFromObject(service), &corev1.Service{})
+	assert.True(t, kuberr.IsNotFound(err))
+
+	assert.Equal(t, &v1beta1.GrafanaImageRendererStatus{URL: "http://localhost:8081/render"}, cr.Status.ImageRenderer)
+
+	cr.Spec.ImageRenderer = nil
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Nil(t, cr.Status.ImageRenderer)
+}
+
+func TestImageRendererAutoscaling(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, appsv1.AddToScheme(s))
+	require.NoError(t, corev1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			ImageRenderer: &v1beta1.GrafanaImageRenderer{
+				Mode:     v1beta1.GrafanaImageRendererRemote,
+				Replicas: ptr.To[int32](3),
+				Autoscaling: &v1beta1.GrafanaImageRendererAutoscaling{
+					MinReplicas:   ptr.To[int32](2),
+					MaxReplicas:   10,
+					ServerAddress: "http://prometheus.monitoring.svc:9090",
+				},
+			},
+		},
+	}
+
+	t.Run("scaled object", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).Build()
+		r := NewImageRendererReconciler(cl, false, true)
+
+		_, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+		require.NoError(t, err)
+
+		scaledObject := model.GetGrafanaImageRendererScaledObject(cr, nil)
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(scaledObject), scaledObject))
+		assert.True(t, metav1.IsControlledBy(scaledObject, cr))
+		assert.Equal(t, map[string]any{
+			"scaleTargetRef":  map[string]any{"name": "grafana-image-renderer"},
+			"minReplicaCount": int64(2),
+			"maxReplicaCount": int64(10),
+			"triggers": []any{map[string]any{
+				"type": "prometheus",
+				"metadata": map[string]any{
+					"serverAddress": "http://prometheus.monitoring.svc:9090",
+					"query":         `sum(grafana_rendering_queue_size{namespace="default",service="grafana-service"})`,
+					"threshold":     "5",
+				},
+			}},
+		}, scaledObject.Object["spec"])
+
+		// new deployments start with minReplicas, afterwards the replicas set by KEDA are kept
+		deployment := model.GetGrafanaImageRendererDeployment(cr, nil)
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), deployment))
+		assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+
+		deployment.Spec.Replicas = ptr.To[int32](7)
+		require.NoError(t, cl.Update(t.Context(), deployment))
+
+		_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), deployment))
+		assert.Equal(t, int32(7), *deployment.Spec.Replicas)
+
+		// disabling autoscaling removes the scaled object and restores the replicas
+		cr := cr.DeepCopy()
+		cr.Spec.ImageRenderer.Autoscaling = nil
+
+		_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+		require.NoError(t, err)
+
+		err = cl.Get(t.Context(), client.ObjectKeyFromObject(scaledObject), model.GetGrafanaImageRendererScaledObject(cr, nil))
+		assert.True(t, kuberr.IsNotFound(err))
+
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), deployment))
+		assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	})
+
+	t.Run("keda not installed", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).Build()
+		r := NewImageRendererReconciler(cl, false, false)
+
+		cr := cr.DeepCopy()
+
+		status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+		require.NoError(t, err)
+		assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+		assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionImageRendererAutoscalingUnavailable))
+
+		deployment := model.GetGrafanaImageRendererDeployment(cr, nil)
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), deployment))
+		assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+
+		cr.Spec.ImageRenderer.Autoscaling = nil
+
+		_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+		require.NoError(t, err)
+		assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionImageRendererAutoscalingUnavailable))
+	})
+}
@@ -4,13 +4,22 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
+	"text/template"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	routev1 "github.com/openshift/api/route/v1"
 	v1 "k8s.io/api/networking/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -19,8 +28,14 @@ import (
 
 const (
 	RouteKind = "Route"
+
+	certManagerIssuerAnnotation        = "cert-manager.io/issuer"
+	certManagerClusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+	defaultIngressTLSSecretName        = "{{ .Name }}-ingress-tls"
 )
 
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
 type IngressReconciler struct {
 	client      client.Client
 	isOpenShift bool
@@ -47,14 +62,28 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 	return r.reconcileIngress(ctx, cr, vars, scheme)
 }
 
-func (r *IngressReconciler) reconcileIngress(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+func (r *IngressReconciler) reconcileIngress(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
 	if cr.Spec.Ingress == nil {
 		return v1beta1.OperatorStageResultSuccess, nil
 	}
 
 	ingress := model.GetGrafanaIngress(cr, scheme)
 
+	certManager := getIngressCertManager(cr)
+
+	var tlsSecretName string
+
+	if certManager != nil {
+		var err error
+
+		tlsSecretName, err = getIngressTLSSecretName(cr, certManager)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+	}
+
 	_, err := controllerutil.CreateOrUpdate(ctx, r.client, ingress, func() error {
+		live := ingress.Spec.DeepCopy()
 		ingress.Spec = getIngressSpec(cr, scheme)
 
 		err := v1beta1.Merge(ingress, cr.Spec.Ingress)
@@ -65,12 +94,22 @@ func (r *IngressReconciler) reconcileIngress(ctx context.Context, cr *v1beta1.Gr
 
 		removeInvalidMergeCondition(cr, "Ingress")
 
+		if certManager != nil {
+			setIngressCertManager(ingress, certManager, tlsSecretName)
+		}
+
 		err = controllerutil.SetControllerReference(cr, ingress, scheme)
 		if err != nil {
 			return err
 		}
 
 		model.SetInheritedLabels(ingress, cr.Labels)
+		model.SetOwnershipAnnotations(ingress, cr.Annotations)
+		model.SetDNSAnnotations(ingress, cr.Spec.DNS)
+
+		if detectDrift(cr, vars, ingress, "Ingress", live, &ingress.Spec) {
+			ingress.Spec = *live
+		}
 
 		return nil
 	})
@@ -78,9 +117,18 @@ func (r *IngressReconciler) reconcileIngress(ctx context.Context, cr *v1beta1.Gr
 		return v1beta1.OperatorStageResultFailed, err
 	}
 
+	if certManager != nil {
+		if status, err := r.checkCertificateReady(ctx, cr.Namespace, tlsSecretName); err != nil {
+			return status, err
+		}
+	}
+
 	// try to assign the admin url
 	if cr.PreferIngress() {
-		adminURL := r.getIngressAdminURL(ingress)
+		adminURL, err := getAdminURL(cr, getIngressAdminURLTarget(ingress))
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
 
 		if len(ingress.Status.LoadBalancer.Ingress) == 0 {
 			return v1beta1.OperatorStageResultInProgress, fmt.Errorf("ingress is not ready yet")
@@ -90,21 +138,29 @@ func (r *IngressReconciler) reconcileIngress(ctx context.Context, cr *v1beta1.Gr
 			return v1beta1.OperatorStageResultFailed, fmt.Errorf("ingress spec is incomplete")
 		}
 
-		cr.Status.AdminURL = adminURL
+		setAdminURL(ctx, cr, vars, adminURL)
 	}
 
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
-func (r *IngressReconciler) reconcileRoute(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
-	if cr.Spec.Route == nil || cr.Spec.Route.Spec == nil {
+func (r *IngressReconciler) reconcileRoute(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	if cr.Spec.Route == nil || (cr.Spec.Route.Spec == nil && !cr.Spec.Route.ServingCert) {
 		return v1beta1.OperatorStageResultSuccess, nil
 	}
 
 	route := model.GetGrafanaRoute(cr, scheme)
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.client, route, func() error {
+	tls, err := r.getRouteTLSConfig(ctx, cr)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, route, func() error {
 		route.Spec = getRouteSpec(cr, scheme)
+		if tls != nil {
+			route.Spec.TLS = tls
+		}
 
 		err := v1beta1.Merge(route, cr.Spec.Route)
 		if err != nil {
@@ -122,6 +178,8 @@ func (r *IngressReconciler) reconcileRoute(ctx context.Context, cr *v1beta1.Graf
 		}
 
 		model.SetInheritedLabels(route, cr.Labels)
+		model.SetOwnershipAnnotations(route, cr.Annotations)
+		model.SetDNSAnnotations(route, cr.Spec.DNS)
 
 		return nil
 	})
@@ -131,69 +189,247 @@ func (r *IngressReconciler) reconcileRoute(ctx context.Context, cr *v1beta1.Graf
 
 	// try to assign the admin url
 	if cr.PreferIngress() {
-		if route.Spec.Host != "" {
-			cr.Status.AdminURL = fmt.Sprintf("https://%v", route.Spec.Host)
+		adminURL, err := getAdminURL(cr, getRouteAdminURLTarget(route))
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+
+		if adminURL != "" {
+			setAdminURL(ctx, cr, vars, adminURL)
 		}
 	}
 
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
-// getIngressAdminURL returns the first valid URL (Host field is set) from the ingress spec
-func (r *IngressReconciler) getIngressAdminURL(ingress *v1.Ingress) string {
-	if ingress == nil {
-		return ""
+// getIngressCertManager returns spec.ingress.tls.certManager, nil when not set
+func getIngressCertManager(cr *v1beta1.Grafana) *v1beta1.IngressCertManager {
+	if cr.Spec.Ingress == nil || cr.Spec.Ingress.TLS == nil {
+		return nil
+	}
+
+	return cr.Spec.Ingress.TLS.CertManager
+}
+
+// getIngressTLSSecretName renders the name of the Secret cert-manager stores the certificate of the ingress in
+func getIngressTLSSecretName(cr *v1beta1.Grafana, certManager *v1beta1.IngressCertManager) (string, error) {
+	secretName := certManager.SecretName
+	if secretName == "" {
+		secretName = defaultIngressTLSSecretName
 	}
 
-	protocol := "http"
+	tmpl, err := template.New("secretName").Parse(secretName)
+	if err != nil {
+		return "", fmt.Errorf("parsing ingress tls secret name: %w", err)
+	}
 
-	var (
-		hostname string
-		adminURL string
-	)
+	var sb strings.Builder
 
-	// An ingress rule might not have the field Host specified, better not to consider such rules
+	err = tmpl.Execute(&sb, struct{ Name, Namespace string }{Name: cr.Name, Namespace: cr.Namespace})
+	if err != nil {
+		return "", fmt.Errorf("rendering ingress tls secret name: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// setIngressCertManager sets the ingress-shim annotation of the issuer and a TLS section for all hosts of the ingress
+// with the given secret, cert-manager then creates a Certificate of the same name
+func setIngressCertManager(ingress *v1.Ingress, certManager *v1beta1.IngressCertManager, secretName string) {
+	if ingress.Annotations == nil {
+		ingress.Annotations = make(map[string]string)
+	}
+
+	if certManager.ClusterIssuer != "" {
+		ingress.Annotations[certManagerClusterIssuerAnnotation] = certManager.ClusterIssuer
+		delete(ingress.Annotations, certManagerIssuerAnnotation)
+	} else {
+		ingress.Annotations[certManagerIssuerAnnotation] = certManager.Issuer
+		delete(ingress.Annotations, certManagerClusterIssuerAnnotation)
+	}
+
+	var hosts []string
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" && !slices.Contains(hosts, rule.Host) {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+
+	for i := range ingress.Spec.TLS {
+		if ingress.Spec.TLS[i].SecretName == secretName {
+			ingress.Spec.TLS[i].Hosts = hosts
+			return
+		}
+	}
+
+	ingress.Spec.TLS = append(ingress.Spec.TLS, v1.IngressTLS{
+		Hosts:      hosts,
+		SecretName: secretName,
+	})
+}
+
+// checkCertificateReady returns an in progress error until the cert-manager Certificate is ready
+func (r *IngressReconciler) checkCertificateReady(ctx context.Context, namespace, name string) (v1beta1.OperatorStageStatus, error) {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, certificate)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return v1beta1.OperatorStageResultFailed, fmt.Errorf("spec.ingress.tls.certManager requires cert-manager: %w", err)
+		}
+
+		if kuberr.IsNotFound(err) {
+			return v1beta1.OperatorStageResultInProgress, fmt.Errorf("waiting for cert-manager to create certificate %s", name)
+		}
+
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		if condition["status"] == string(metav1.ConditionTrue) {
+			return v1beta1.OperatorStageResultSuccess, nil
+		}
+
+		return v1beta1.OperatorStageResultInProgress, fmt.Errorf("waiting for certificate %s to become ready: %v", name, condition["message"])
+	}
+
+	return v1beta1.OperatorStageResultInProgress, fmt.Errorf("waiting for certificate %s to become ready", name)
+}
 
+// getIngressAdminURLTarget returns the first hostname of the ingress rules and the address of its load balancer.
+// The scheme is https when the hostname is covered by any of the IngressTLS
+func getIngressAdminURLTarget(ingress *v1.Ingress) adminURLTarget {
+	target := adminURLTarget{Scheme: "http"}
+
+	if ingress == nil {
+		return target
+	}
+
+	// An ingress rule might not have the field Host specified, better not to consider such rules
 	for _, rule := range ingress.Spec.Rules {
 		if rule.Host != "" {
-			hostname = rule.Host
+			target.Hostname = rule.Host
 			break
 		}
 	}
 
-	// If we can find the target host in any of the IngressTLS, then we should use https protocol
 	for _, tls := range ingress.Spec.TLS {
-		if slices.Contains(tls.Hosts, hostname) {
-			protocol = "https"
+		if target.Hostname != "" && slices.Contains(tls.Hosts, target.Hostname) {
+			target.Scheme = "https"
 		}
 	}
 
-	// if all fails, try to get access through the load balancer
-	if hostname == "" {
-		loadBalancerIP := ""
+	// Load balancers announcing a hostname are preferred over those with an IP
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			target.Address = lb.Hostname
+			break
+		}
+
+		if lb.IP != "" {
+			target.Address = lb.IP
+		}
+	}
 
-		for _, lb := range ingress.Status.LoadBalancer.Ingress {
-			if lb.Hostname != "" {
-				hostname = lb.Hostname
-				break
-			}
+	return target
+}
 
-			if lb.IP != "" {
-				loadBalancerIP = lb.IP
-			}
+// getRouteAdminURLTarget returns the host of the route and the canonical hostname of the first router admitting it
+func getRouteAdminURLTarget(route *routev1.Route) adminURLTarget {
+	target := adminURLTarget{
+		Scheme:   "https",
+		Hostname: route.Spec.Host,
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterCanonicalHostname != "" {
+			target.Address = ingress.RouterCanonicalHostname
+			break
 		}
+	}
+
+	return target
+}
 
-		if hostname == "" && loadBalancerIP != "" {
-			hostname = loadBalancerIP
+// getRouteTLSConfig returns the TLS config of spec.route.tls, nil when not set. Without termination, connections are passed
+// through when Grafana serves https and terminated at the edge otherwise
+func (r *IngressReconciler) getRouteTLSConfig(ctx context.Context, cr *v1beta1.Grafana) (*routev1.TLSConfig, error) {
+	routeTLS := cr.Spec.Route.TLS
+	if routeTLS == nil {
+		if !cr.UsesServingCert() {
+			return nil, nil
 		}
+
+		routeTLS = &v1beta1.RouteTLS{}
 	}
 
-	// adminUrl should not be empty only in case hostname is found, otherwise we'll have broken URLs like "http://"
-	if hostname != "" {
-		adminURL = fmt.Sprintf("%v://%v", protocol, hostname)
+	termination := routev1.TLSTerminationType(routeTLS.Termination)
+
+	if termination == "" {
+		switch {
+		case cr.UsesServingCert():
+			termination = routev1.TLSTerminationReencrypt
+		case getGrafanaServerScheme(cr) == "https":
+			termination = routev1.TLSTerminationPassthrough
+		default:
+			termination = routev1.TLSTerminationEdge
+		}
 	}
 
-	return adminURL
+	tls := &routev1.TLSConfig{
+		Termination: termination,
+	}
+
+	ca := routeTLS.DestinationCA
+	if ca == nil {
+		if cr.UsesServingCert() {
+			return r.withServiceCA(ctx, cr, tls)
+		}
+
+		return tls, nil
+	}
+
+	var (
+		value []byte
+		err   error
+	)
+
+	if ca.ConfigMapKeyRef != nil {
+		value, err = client2.GetValueFromConfigMapKey(ctx, ca.ConfigMapKeyRef, r.client, cr.Namespace)
+	} else {
+		value, err = client2.GetValueFromSecretKey(ctx, ca.SecretKeyRef, r.client, cr.Namespace)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fetching destination CA of the route: %w", err)
+	}
+
+	tls.DestinationCACertificate = string(value)
+
+	return tls, nil
+}
+
+// withServiceCA sets the CA bundle injected by the OpenShift service CA as destination CA. Until it's injected the
+// router falls back to the service CA itself, which it trusts for serving certs
+func (r *IngressReconciler) withServiceCA(ctx context.Context, cr *v1beta1.Grafana, tls *routev1.TLSConfig) (*routev1.TLSConfig, error) {
+	configMap := model.GetGrafanaServiceCAConfigMap(cr, nil)
+
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	if err != nil && !kuberr.IsNotFound(err) {
+		return nil, fmt.Errorf("fetching service CA of the route: %w", err)
+	}
+
+	tls.DestinationCACertificate = configMap.Data[config.GrafanaServiceCAKey]
+
+	return tls, nil
 }
 
 func getRouteTLS() *routev1.TLSConfig {
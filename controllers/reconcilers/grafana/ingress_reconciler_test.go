@@ -2,16 +2,24 @@ package grafana
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"testing"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/kubernetes/scheme"
 
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Allow use of Ingress on OpenShift", func() {
@@ -72,3 +80,323 @@ var _ = Describe("Allow use of Ingress on OpenShift", func() {
 		Expect(status).To(Equal(v1beta1.OperatorStageResultFailed), "Route does not exist in Scheme outside of OpenShift")
 	})
 })
+
+func TestCheckDNSResolution(t *testing.T) {
+	resolvable := map[string]bool{"grafana.example.com": true}
+
+	lookup := lookupHost
+	lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if resolvable[host] {
+			return []string{"192.0.2.1"}, nil
+		}
+
+		return nil, errors.New("no such host")
+	}
+
+	defer func() { lookupHost = lookup }()
+
+	cr := func(dns *v1beta1.GrafanaDNS) *v1beta1.Grafana {
+		return &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{DNS: dns}}
+	}
+
+	ctx := context.Background()
+
+	assert.NoError(t, checkDNSResolution(ctx, cr(nil), "https://pending.example.com"))
+	assert.NoError(t, checkDNSResolution(ctx, cr(&v1beta1.GrafanaDNS{Hostname: "grafana.example.com"}), "https://grafana.example.com"))
+	assert.Error(t, checkDNSResolution(ctx, cr(&v1beta1.GrafanaDNS{Hostname: "pending.example.com"}), "https://pending.example.com:3000"))
+	assert.NoError(t, checkDNSResolution(ctx, cr(&v1beta1.GrafanaDNS{Hostname: "pending.example.com", SkipResolutionCheck: true}), "https://pending.example.com"))
+	assert.NoError(t, checkDNSResolution(ctx, cr(&v1beta1.GrafanaDNS{Hostname: "pending.example.com"}), "https://other.example.com"))
+}
+
+func TestSetAdminURLHoldsBackPendingHostname(t *testing.T) {
+	lookup := lookupHost
+	lookupHost = func(_ context.Context, _ string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+
+	defer func() { lookupHost = lookup }()
+
+	cr := &v1beta1.Grafana{
+		Spec:   v1beta1.GrafanaSpec{DNS: &v1beta1.GrafanaDNS{Hostname: "pending.example.com"}},
+		Status: v1beta1.GrafanaStatus{AdminURL: "http://grafana-service.default:3000"},
+	}
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	setAdminURL(context.Background(), cr, vars, "https://pending.example.com")
+
+	assert.Equal(t, "http://grafana-service.default:3000", cr.Status.AdminURL)
+	assert.Contains(t, vars.DNSPending, "pending.example.com")
+
+	cr.Spec.DNS.SkipResolutionCheck = true
+	vars = &v1beta1.OperatorReconcileVars{}
+
+	setAdminURL(context.Background(), cr, vars, "https://pending.example.com")
+
+	assert.Equal(t, "https://pending.example.com", cr.Status.AdminURL)
+	assert.Empty(t, vars.DNSPending)
+}
+
+func TestSetDNSAnnotationsRemovesStale(t *testing.T) {
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"user": "kept"}}}
+
+	model.SetDNSAnnotations(ingress, &v1beta1.GrafanaDNS{
+		Hostname:    "grafana.example.com",
+		TTL:         model.IntPtr(60),
+		Annotations: map[string]string{"external-dns.alpha.kubernetes.io/set-identifier": "eu"},
+	})
+
+	assert.Equal(t, "grafana.example.com", ingress.Annotations[model.ExternalDNSHostnameKey])
+	assert.Equal(t, "60", ingress.Annotations[model.ExternalDNSTTLKey])
+
+	model.SetDNSAnnotations(ingress, &v1beta1.GrafanaDNS{Hostname: "grafana.example.org"})
+
+	assert.Equal(t, "grafana.example.org", ingress.Annotations[model.ExternalDNSHostnameKey])
+	assert.NotContains(t, ingress.Annotations, model.ExternalDNSTTLKey)
+	assert.NotContains(t, ingress.Annotations, "external-dns.alpha.kubernetes.io/set-identifier")
+
+	model.SetDNSAnnotations(ingress, nil)
+
+	assert.Equal(t, map[string]string{"user": "kept"}, ingress.Annotations)
+}
+
+func TestGetIngressAdminURL(t *testing.T) {
+	ingress := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{}, {Host: "grafana.example.com"}, {Host: "grafana.example.org"}},
+			TLS:   []networkingv1.IngressTLS{{Hosts: []string{"grafana.example.com"}}},
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+				Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		client  *v1beta1.GrafanaClient
+		want    string
+	}{
+		{
+			name:    "first hostname",
+			ingress: ingress,
+			want:    "https://grafana.example.com",
+		},
+		{
+			name:    "load balancer without hostnames",
+			ingress: &networkingv1.Ingress{Status: ingress.Status},
+			want:    "http://10.0.0.1",
+		},
+		{
+			name:    "hostname strategy without hostnames",
+			ingress: &networkingv1.Ingress{Status: ingress.Status},
+			client:  &v1beta1.GrafanaClient{AdminURLStrategy: v1beta1.AdminURLStrategyHostname},
+			want:    "",
+		},
+		{
+			name:    "loadBalancerIP strategy",
+			ingress: ingress,
+			client:  &v1beta1.GrafanaClient{AdminURLStrategy: v1beta1.AdminURLStrategyLoadBalancerIP},
+			want:    "https://10.0.0.1",
+		},
+		{
+			name:    "explicit strategy",
+			ingress: ingress,
+			client: &v1beta1.GrafanaClient{
+				AdminURLStrategy: v1beta1.AdminURLStrategyExplicit,
+				AdminURLTemplate: "http://{{ .Address }}:8080",
+			},
+			want: "http://10.0.0.1:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Client: tt.client}}
+
+			got, err := getAdminURL(cr, getIngressAdminURLTarget(tt.ingress))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid template", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{Client: &v1beta1.GrafanaClient{
+			AdminURLStrategy: v1beta1.AdminURLStrategyExplicit,
+			AdminURLTemplate: "{{ .Unknown }}",
+		}}}
+
+		_, err := getAdminURL(cr, getIngressAdminURLTarget(ingress))
+		assert.Error(t, err)
+	})
+}
+
+func TestSetIngressCertManager(t *testing.T) {
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{certManagerIssuerAnnotation: "previous"}},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{Host: "grafana.example.com"}, {}, {Host: "grafana.example.org"}, {Host: "grafana.example.com"}},
+			TLS:   []networkingv1.IngressTLS{{Hosts: []string{"other.example.com"}, SecretName: "other"}},
+		},
+	}
+
+	setIngressCertManager(ingress, &v1beta1.IngressCertManager{ClusterIssuer: "letsencrypt"}, "grafana-ingress-tls")
+
+	assert.Equal(t, map[string]string{certManagerClusterIssuerAnnotation: "letsencrypt"}, ingress.Annotations)
+	assert.Equal(t, []networkingv1.IngressTLS{
+		{Hosts: []string{"other.example.com"}, SecretName: "other"},
+		{Hosts: []string{"grafana.example.com", "grafana.example.org"}, SecretName: "grafana-ingress-tls"},
+	}, ingress.Spec.TLS)
+
+	ingress.Spec.Rules = ingress.Spec.Rules[:1]
+	setIngressCertManager(ingress, &v1beta1.IngressCertManager{ClusterIssuer: "letsencrypt"}, "grafana-ingress-tls")
+
+	assert.Len(t, ingress.Spec.TLS, 2)
+	assert.Equal(t, []string{"grafana.example.com"}, ingress.Spec.TLS[1].Hosts)
+}
+
+func TestGetIngressTLSSecretName(t *testing.T) {
+	cr := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+
+	got, err := getIngressTLSSecretName(cr, &v1beta1.IngressCertManager{Issuer: "ca"})
+	assert.NoError(t, err)
+	assert.Equal(t, "grafana-ingress-tls", got)
+
+	got, err = getIngressTLSSecretName(cr, &v1beta1.IngressCertManager{Issuer: "ca", SecretName: "{{ .Namespace }}-{{ .Name }}-cert"})
+	assert.NoError(t, err)
+	assert.Equal(t, "monitoring-grafana-cert", got)
+
+	_, err = getIngressTLSSecretName(cr, &v1beta1.IngressCertManager{Issuer: "ca", SecretName: "{{ .Unknown }}"})
+	assert.Error(t, err)
+}
+
+func TestCheckCertificateReady(t *testing.T) {
+	certificate := func(conditions ...any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"conditions": conditions}}}
+		u.SetGroupVersionKind(certificateGVK)
+		u.SetName("grafana-ingress-tls")
+		u.SetNamespace("default")
+
+		return u
+	}
+
+	tests := []struct {
+		name        string
+		certificate *unstructured.Unstructured
+		want        v1beta1.OperatorStageStatus
+	}{
+		{
+			name: "not created yet",
+			want: v1beta1.OperatorStageResultInProgress,
+		},
+		{
+			name:        "not ready",
+			certificate: certificate(map[string]any{"type": "Ready", "status": "False", "message": "Issuing certificate"}),
+			want:        v1beta1.OperatorStageResultInProgress,
+		},
+		{
+			name:        "ready",
+			certificate: certificate(map[string]any{"type": "Ready", "status": "True"}),
+			want:        v1beta1.OperatorStageResultSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder()
+			if tt.certificate != nil {
+				builder = builder.WithObjects(tt.certificate)
+			}
+
+			r := &IngressReconciler{client: builder.Build()}
+
+			status, err := r.checkCertificateReady(context.Background(), "default", "grafana-ingress-tls")
+			assert.Equal(t, tt.want, status)
+			assert.Equal(t, tt.want != v1beta1.OperatorStageResultSuccess, err != nil)
+		})
+	}
+}
+
+func TestGetRouteTLSConfig(t *testing.T) {
+	ca := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-ca", Namespace: "default"},
+		Data:       map[string]string{"ca.crt": "-----BEGIN CERTIFICATE-----"},
+	}
+
+	r := &IngressReconciler{client: fake.NewClientBuilder().WithObjects(ca).Build()}
+
+	newGrafana := func(tls *v1beta1.RouteTLS) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+			Spec:       v1beta1.GrafanaSpec{Route: &v1beta1.RouteOpenshiftV1{TLS: tls}},
+		}
+	}
+
+	t.Run("not set", func(t *testing.T) {
+		tls, err := r.getRouteTLSConfig(context.Background(), newGrafana(nil))
+		assert.NoError(t, err)
+		assert.Nil(t, tls)
+	})
+
+	t.Run("edge by default", func(t *testing.T) {
+		tls, err := r.getRouteTLSConfig(context.Background(), newGrafana(&v1beta1.RouteTLS{}))
+		assert.NoError(t, err)
+		assert.Equal(t, routev1.TLSTerminationEdge, tls.Termination)
+	})
+
+	t.Run("passthrough when grafana serves https", func(t *testing.T) {
+		cr := newGrafana(&v1beta1.RouteTLS{})
+		cr.Spec.Config = map[string]map[string]string{"server": {"protocol": "https"}}
+
+		tls, err := r.getRouteTLSConfig(context.Background(), cr)
+		assert.NoError(t, err)
+		assert.Equal(t, routev1.TLSTerminationPassthrough, tls.Termination)
+	})
+
+	t.Run("reencrypt with destination CA", func(t *testing.T) {
+		cr := newGrafana(&v1beta1.RouteTLS{
+			Termination: v1beta1.TLSTerminationReencrypt,
+			DestinationCA: &v1beta1.RouteDestinationCA{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-ca"}, Key: "ca.crt"},
+			},
+		})
+
+		tls, err := r.getRouteTLSConfig(context.Background(), cr)
+		assert.NoError(t, err)
+		assert.Equal(t, routev1.TLSTerminationReencrypt, tls.Termination)
+		assert.Equal(t, "-----BEGIN CERTIFICATE-----", tls.DestinationCACertificate)
+	})
+
+	t.Run("missing destination CA", func(t *testing.T) {
+		cr := newGrafana(&v1beta1.RouteTLS{
+			Termination: v1beta1.TLSTerminationReencrypt,
+			DestinationCA: &v1beta1.RouteDestinationCA{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-ca"}, Key: "ca.crt"},
+			},
+		})
+
+		_, err := r.getRouteTLSConfig(context.Background(), cr)
+		assert.Error(t, err)
+	})
+
+	t.Run("reencrypt with serving cert", func(t *testing.T) {
+		cr := newGrafana(nil)
+		cr.Spec.Route.ServingCert = true
+
+		tls, err := r.getRouteTLSConfig(context.Background(), cr)
+		assert.NoError(t, err)
+		assert.Equal(t, routev1.TLSTerminationReencrypt, tls.Termination)
+		assert.Empty(t, tls.DestinationCACertificate, "service CA not injected yet")
+
+		serviceCA := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana-service-ca", Namespace: "default"},
+			Data:       map[string]string{"service-ca.crt": "-----BEGIN CERTIFICATE-----"},
+		}
+		r := &IngressReconciler{client: fake.NewClientBuilder().WithObjects(serviceCA).Build()}
+
+		tls, err = r.getRouteTLSConfig(context.Background(), cr)
+		assert.NoError(t, err)
+		assert.Equal(t, "-----BEGIN CERTIFICATE-----", tls.DestinationCACertificate)
+	})
+}
@@ -0,0 +1,229 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// serviceInstanceLabel is set on the service of an instance, the ServiceMonitor selects the service with it
+	serviceInstanceLabel = "app"
+
+	conditionMonitorUnavailable              = "MonitorUnavailable"
+	conditionReasonPrometheusOperatorMissing = "PrometheusOperatorNotInstalled"
+)
+
+// MonitorReconciler maintains the ServiceMonitor or PodMonitor of instances with spec.metrics.serviceMonitor
+type MonitorReconciler struct {
+	client      client.Client
+	hasMonitors bool
+}
+
+func NewMonitorReconciler(client client.Client, hasMonitors bool) reconcilers.OperatorGrafanaReconciler {
+	return &MonitorReconciler{
+		client:      client,
+		hasMonitors: hasMonitors,
+	}
+}
+
+// Reconcile creates the monitor of the configured kind and removes the other one. Without the prometheus-operator
+// CRDs the MonitorUnavailable condition is set instead, so a missing prometheus-operator never blocks the instance
+func (r *MonitorReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("MonitorReconciler")
+
+	if !r.hasMonitors {
+		if isMonitorEnabled(cr) {
+			meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+				Type:               conditionMonitorUnavailable,
+				Reason:             conditionReasonPrometheusOperatorMissing,
+				Message:            "spec.metrics.serviceMonitor requires the ServiceMonitor and PodMonitor CRDs of the prometheus-operator, restart the operator after installing them",
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: cr.Generation,
+				LastTransitionTime: metav1.Time{Time: time.Now()},
+			})
+		} else {
+			meta.RemoveStatusCondition(&cr.Status.Conditions, conditionMonitorUnavailable)
+		}
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	meta.RemoveStatusCondition(&cr.Status.Conditions, conditionMonitorUnavailable)
+
+	wanted := getMonitorKind(cr)
+
+	for _, monitorType := range []struct {
+		kind v1beta1.GrafanaMonitorKind
+		gvk  schema.GroupVersionKind
+	}{
+		{v1beta1.GrafanaMonitorKindServiceMonitor, model.ServiceMonitorGVK},
+		{v1beta1.GrafanaMonitorKindPodMonitor, model.PodMonitorGVK},
+	} {
+		monitor := model.GetGrafanaMonitor(cr, scheme, monitorType.gvk)
+
+		if monitorType.kind != wanted {
+			err := r.removeMonitor(ctx, cr, monitor)
+			if err != nil {
+				return v1beta1.OperatorStageResultFailed, err
+			}
+
+			continue
+		}
+
+		log.V(1).Info("reconciling monitor", "kind", monitorType.kind)
+
+		_, err := controllerutil.CreateOrUpdate(ctx, r.client, monitor, func() error {
+			spec, err := getMonitorSpec(cr, monitorType.kind)
+			if err != nil {
+				return err
+			}
+
+			monitor.Object["spec"] = spec
+
+			if scheme != nil {
+				err := controllerutil.SetControllerReference(cr, monitor, scheme)
+				if err != nil {
+					return err
+				}
+			}
+
+			model.SetInheritedLabels(unstructuredMeta{monitor}, cr.Labels)
+			model.SetOwnershipAnnotations(unstructuredMeta{monitor}, cr.Annotations)
+
+			labels := monitor.GetLabels()
+			maps.Copy(labels, cr.Spec.Metrics.ServiceMonitor.Labels)
+			monitor.SetLabels(labels)
+
+			return nil
+		})
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, fmt.Errorf("reconciling %s: %w", monitorType.kind, err)
+		}
+	}
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// unstructuredMeta adapts unstructured objects to the model helpers taking a metav1.ObjectMetaAccessor
+type unstructuredMeta struct {
+	*unstructured.Unstructured
+}
+
+func (u unstructuredMeta) GetObjectMeta() metav1.Object {
+	return u.Unstructured
+}
+
+// isMonitorEnabled reports whether spec.metrics.serviceMonitor is enabled
+func isMonitorEnabled(cr *v1beta1.Grafana) bool {
+	return cr.Spec.Metrics != nil && cr.Spec.Metrics.ServiceMonitor != nil && cr.Spec.Metrics.ServiceMonitor.Enabled
+}
+
+// getMonitorKind returns the kind of monitor wanted for the instance, empty when disabled
+func getMonitorKind(cr *v1beta1.Grafana) v1beta1.GrafanaMonitorKind {
+	if !isMonitorEnabled(cr) {
+		return ""
+	}
+
+	if cr.Spec.Metrics.ServiceMonitor.Kind == "" {
+		return v1beta1.GrafanaMonitorKindServiceMonitor
+	}
+
+	return cr.Spec.Metrics.ServiceMonitor.Kind
+}
+
+// getMonitorSpec returns the spec of the ServiceMonitor, selecting the service of the instance,
+// or of the PodMonitor, selecting its pods, scraping /metrics of the grafana port
+func getMonitorSpec(cr *v1beta1.Grafana, kind v1beta1.GrafanaMonitorKind) (map[string]any, error) {
+	settings := cr.Spec.Metrics.ServiceMonitor
+
+	endpoint := map[string]any{
+		"path":   "/metrics",
+		"scheme": getGrafanaServerScheme(cr),
+	}
+
+	if settings.Interval != "" {
+		endpoint["interval"] = settings.Interval
+	}
+
+	if settings.ScrapeTimeout != "" {
+		endpoint["scrapeTimeout"] = settings.ScrapeTimeout
+	}
+
+	if settings.TLSConfig != nil {
+		tlsConfig, err := runtime.DefaultUnstructuredConverter.ToUnstructured(settings.TLSConfig)
+		if err != nil {
+			return nil, fmt.Errorf("converting tls config: %w", err)
+		}
+
+		endpoint["tlsConfig"] = tlsConfig
+	}
+
+	spec := map[string]any{
+		"namespaceSelector": map[string]any{
+			"matchNames": []any{cr.Namespace},
+		},
+	}
+
+	if kind == v1beta1.GrafanaMonitorKindPodMonitor {
+		selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(getPodSelector(cr))
+		if err != nil {
+			return nil, fmt.Errorf("converting pod selector: %w", err)
+		}
+
+		endpoint["port"] = "grafana-http"
+		spec["selector"] = selector
+		spec["podMetricsEndpoints"] = []any{endpoint}
+
+		return spec, nil
+	}
+
+	endpoint["port"] = config.GrafanaHTTPPortName
+	spec["selector"] = map[string]any{
+		"matchLabels": map[string]any{
+			serviceInstanceLabel: cr.Name,
+		},
+	}
+	spec["endpoints"] = []any{endpoint}
+
+	return spec, nil
+}
+
+// removeMonitor deletes the monitor if it was created for the instance
+func (r *MonitorReconciler) removeMonitor(ctx context.Context, cr *v1beta1.Grafana, monitor *unstructured.Unstructured) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(monitor), monitor)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", monitor.GetKind(), err)
+	}
+
+	if !metav1.IsControlledBy(monitor, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing monitor", "kind", monitor.GetKind(), "name", monitor.GetName())
+
+	if err := r.client.Delete(ctx, monitor); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing %s: %w", monitor.GetKind(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetMonitorSpec(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec: v1beta1.GrafanaSpec{
+			Metrics: &v1beta1.GrafanaMetrics{
+				ServiceMonitor: &v1beta1.GrafanaServiceMonitor{Enabled: true, Interval: "30s"},
+			},
+		},
+	}
+
+	t.Run("service monitor", func(t *testing.T) {
+		spec, err := getMonitorSpec(cr, v1beta1.GrafanaMonitorKindServiceMonitor)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{
+			"namespaceSelector": map[string]any{"matchNames": []any{"monitoring"}},
+			"selector":          map[string]any{"matchLabels": map[string]any{"app": "grafana"}},
+			"endpoints": []any{map[string]any{
+				"port":     "grafana",
+				"path":     "/metrics",
+				"scheme":   "http",
+				"interval": "30s",
+			}},
+		}, spec)
+	})
+
+	t.Run("pod monitor with tls", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Config = map[string]map[string]string{"server": {"protocol": "https"}}
+		cr.Spec.Metrics.ServiceMonitor.TLSConfig = &v1beta1.GrafanaMonitorTLSConfig{
+			CA: &v1beta1.GrafanaMonitorSecretOrConfigMap{
+				ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+			},
+			ServerName: "grafana.monitoring.svc",
+		}
+
+		spec, err := getMonitorSpec(cr, v1beta1.GrafanaMonitorKindPodMonitor)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"matchLabels": map[string]any{"app": "grafana"}}, spec["selector"])
+		assert.NotContains(t, spec, "endpoints")
+
+		endpoints, ok := spec["podMetricsEndpoints"].([]any)
+		require.True(t, ok)
+		require.Len(t, endpoints, 1)
+
+		endpoint := endpoints[0].(map[string]any)
+		assert.Equal(t, "grafana-http", endpoint["port"])
+		assert.Equal(t, "https", endpoint["scheme"])
+		assert.Equal(t, map[string]any{
+			"ca":         map[string]any{"configMap": map[string]any{"name": "ca", "key": "ca.crt"}},
+			"serverName": "grafana.monitoring.svc",
+		}, endpoint["tlsConfig"])
+	})
+}
+
+func TestMonitorReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Metrics: &v1beta1.GrafanaMetrics{
+				ServiceMonitor: &v1beta1.GrafanaServiceMonitor{Enabled: true, Labels: map[string]string{"release": "prometheus"}},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewMonitorReconciler(cl, true)
+
+	get := func(gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+		monitor := model.GetGrafanaMonitor(cr, nil, gvk)
+		err := cl.Get(t.Context(), client.ObjectKeyFromObject(monitor), monitor)
+
+		return monitor, err
+	}
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	serviceMonitor, err := get(model.ServiceMonitorGVK)
+	require.NoError(t, err)
+	assert.True(t, metav1.IsControlledBy(serviceMonitor, cr))
+	assert.Equal(t, "prometheus", serviceMonitor.GetLabels()["release"])
+
+	cr.Spec.Metrics.ServiceMonitor.Kind = v1beta1.GrafanaMonitorKindPodMonitor
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	_, err = get(model.ServiceMonitorGVK)
+	assert.True(t, kuberr.IsNotFound(err))
+
+	_, err = get(model.PodMonitorGVK)
+	require.NoError(t, err)
+
+	cr.Spec.Metrics.ServiceMonitor.Enabled = false
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	_, err = get(model.PodMonitorGVK)
+	assert.True(t, kuberr.IsNotFound(err))
+}
+
+func TestMonitorReconcilerWithoutPrometheusOperator(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		Spec: v1beta1.GrafanaSpec{
+			Metrics: &v1beta1.GrafanaMetrics{
+				ServiceMonitor: &v1beta1.GrafanaServiceMonitor{Enabled: true},
+			},
+		},
+	}
+
+	r := NewMonitorReconciler(nil, false)
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, conditionMonitorUnavailable))
+
+	cr.Spec.Metrics = nil
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionMonitorUnavailable))
+}
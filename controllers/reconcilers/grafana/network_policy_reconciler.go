@@ -0,0 +1,207 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	v2 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// openShiftIngressPolicyGroupLabel selects the namespaces of the OpenShift routers
+const openShiftIngressPolicyGroupLabel = "network.openshift.io/policy-group"
+
+// OperatorPeer identifies the pods of the operator, the NetworkPolicy of an instance admits their requests
+type OperatorPeer struct {
+	// Namespace the operator runs in, pods of any namespace are admitted when empty
+	Namespace string
+	// Labels of the operator pods
+	Labels map[string]string
+}
+
+// NetworkPolicyReconciler maintains the NetworkPolicy of spec.networkPolicy
+type NetworkPolicyReconciler struct {
+	client      client.Client
+	isOpenShift bool
+	operator    OperatorPeer
+}
+
+func NewNetworkPolicyReconciler(client client.Client, isOpenShift bool, operator OperatorPeer) reconcilers.OperatorGrafanaReconciler {
+	return &NetworkPolicyReconciler{
+		client:      client,
+		isOpenShift: isOpenShift,
+		operator:    operator,
+	}
+}
+
+func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("NetworkPolicyReconciler")
+
+	policy := model.GetGrafanaNetworkPolicy(cr, scheme)
+
+	if cr.Spec.NetworkPolicy == nil {
+		err := r.removeNetworkPolicy(ctx, cr, policy)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.V(1).Info("reconciling network policy")
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, policy, func() error {
+		policy.Spec = getNetworkPolicySpec(cr, r.isOpenShift, r.operator)
+
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, policy, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(policy, cr.Labels)
+		model.SetOwnershipAnnotations(policy, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// getNetworkPolicySpec allows ingress from the operator, the controllers routing traffic to the instance, the remote
+// image renderer and the other replicas. Egress is limited to DNS, the other replicas, the remote image renderer
+// and the endpoints of spec.networkPolicy.egress
+func getNetworkPolicySpec(cr *v1beta1.Grafana, isOpenShift bool, operator OperatorPeer) networkingv1.NetworkPolicySpec {
+	podSelector := getPodSelector(cr)
+	replicas := networkingv1.NetworkPolicyPeer{PodSelector: podSelector}
+
+	operatorNamespace := &metav1.LabelSelector{}
+	if operator.Namespace != "" {
+		operatorNamespace.MatchLabels = map[string]string{corev1.LabelMetadataName: operator.Namespace}
+	}
+
+	from := []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: operatorNamespace,
+			PodSelector:       &metav1.LabelSelector{MatchLabels: operator.Labels},
+		},
+		replicas,
+	}
+
+	for _, namespace := range getNetworkPolicyIngressNamespaces(cr) {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: namespace}},
+		})
+	}
+
+	if isOpenShift && cr.Spec.Route != nil {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{openShiftIngressPolicyGroupLabel: "ingress"}},
+		})
+	}
+
+	to := []networkingv1.NetworkPolicyPeer{replicas}
+
+	if isImageRendererRemote(cr) {
+		renderer := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: getImageRendererSelector(cr)}}
+
+		// The renderer calls back Grafana to render panels
+		from = append(from, renderer)
+		to = append(to, renderer)
+	}
+
+	from = append(from, cr.Spec.NetworkPolicy.IngressFrom...)
+
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt32(53)
+
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+		{To: to},
+	}
+
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: *podSelector,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: from}},
+		Egress:      append(egress, cr.Spec.NetworkPolicy.Egress...),
+	}
+}
+
+// getNetworkPolicyIngressNamespaces returns the namespaces of spec.networkPolicy.ingressNamespaces and of the
+// Gateways the routes of the instance are attached to
+func getNetworkPolicyIngressNamespaces(cr *v1beta1.Grafana) []string {
+	namespaces := slices.Clone(cr.Spec.NetworkPolicy.IngressNamespaces)
+
+	var parentRefs []v2.ParentReference
+	if cr.Spec.HTTPRoute != nil {
+		parentRefs = append(parentRefs, cr.Spec.HTTPRoute.Spec.ParentRefs...)
+	}
+
+	if cr.Spec.GRPCRoute != nil {
+		parentRefs = append(parentRefs, cr.Spec.GRPCRoute.Spec.ParentRefs...)
+	}
+
+	for _, pr := range parentRefs {
+		if !isGatewayParentRef(pr) {
+			continue
+		}
+
+		namespace := cr.Namespace
+		if pr.Namespace != nil {
+			namespace = string(*pr.Namespace)
+		}
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	slices.Sort(namespaces)
+
+	return slices.Compact(namespaces)
+}
+
+// removeNetworkPolicy deletes the NetworkPolicy if it was created for the instance
+func (r *NetworkPolicyReconciler) removeNetworkPolicy(ctx context.Context, cr *v1beta1.Grafana, policy *networkingv1.NetworkPolicy) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(policy), policy)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching network policy: %w", err)
+	}
+
+	if !metav1.IsControlledBy(policy, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing network policy", "name", policy.Name)
+
+	if err := r.client.Delete(ctx, policy); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing network policy: %w", err)
+	}
+
+	return nil
+}
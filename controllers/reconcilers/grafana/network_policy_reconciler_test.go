@@ -0,0 +1,133 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	v2 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestGetNetworkPolicySpec(t *testing.T) {
+	namespaceNamed := func(name string) networkingv1.NetworkPolicyPeer {
+		return networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: name}},
+		}
+	}
+
+	replicas := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}}}
+	operatorPeer := OperatorPeer{Namespace: "grafana-operator", Labels: map[string]string{"app.kubernetes.io/name": "grafana-operator"}}
+	operator := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "grafana-operator"}},
+		PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "grafana-operator"}},
+	}
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec: v1beta1.GrafanaSpec{
+			NetworkPolicy: &v1beta1.GrafanaNetworkPolicy{IngressNamespaces: []string{"ingress-nginx"}},
+		},
+	}
+
+	t.Run("ingress controllers", func(t *testing.T) {
+		spec := getNetworkPolicySpec(cr, false, operatorPeer)
+
+		assert.Equal(t, map[string]string{"app": "grafana"}, spec.PodSelector.MatchLabels)
+		assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, spec.PolicyTypes)
+		require.Len(t, spec.Ingress, 1)
+		assert.Equal(t, []networkingv1.NetworkPolicyPeer{operator, replicas, namespaceNamed("ingress-nginx")}, spec.Ingress[0].From)
+
+		require.Len(t, spec.Egress, 2)
+		assert.Len(t, spec.Egress[0].Ports, 2, "dns over udp and tcp")
+		assert.Empty(t, spec.Egress[0].To)
+		assert.Equal(t, []networkingv1.NetworkPolicyPeer{replicas}, spec.Egress[1].To)
+	})
+
+	t.Run("gateways and openshift router", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.NetworkPolicy.IngressNamespaces = nil
+		cr.Spec.Route = &v1beta1.RouteOpenshiftV1{}
+		cr.Spec.HTTPRoute = &v1beta1.HTTPRouteV1{}
+		cr.Spec.HTTPRoute.Spec.ParentRefs = []v2.ParentReference{
+			{Name: "public", Namespace: ptr.To(v2.Namespace("gateways"))},
+			{Name: "internal"},
+		}
+
+		spec := getNetworkPolicySpec(cr, true, operatorPeer)
+
+		assert.Equal(t, []networkingv1.NetworkPolicyPeer{
+			operator,
+			replicas,
+			namespaceNamed("gateways"),
+			namespaceNamed("monitoring"),
+			{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"}}},
+		}, spec.Ingress[0].From)
+	})
+
+	t.Run("remote image renderer and custom rules", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.ImageRenderer = &v1beta1.GrafanaImageRenderer{Mode: v1beta1.GrafanaImageRendererRemote}
+
+		prometheus := networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}}
+		cr.Spec.NetworkPolicy.IngressFrom = []networkingv1.NetworkPolicyPeer{prometheus}
+		cr.Spec.NetworkPolicy.Egress = []networkingv1.NetworkPolicyEgressRule{{To: []networkingv1.NetworkPolicyPeer{prometheus}}}
+
+		spec := getNetworkPolicySpec(cr, false, operatorPeer)
+
+		renderer := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana-image-renderer"}}}
+
+		assert.Equal(t, []networkingv1.NetworkPolicyPeer{operator, replicas, namespaceNamed("ingress-nginx"), renderer, prometheus}, spec.Ingress[0].From)
+		require.Len(t, spec.Egress, 3)
+		assert.Equal(t, []networkingv1.NetworkPolicyPeer{replicas, renderer}, spec.Egress[1].To)
+		assert.Equal(t, cr.Spec.NetworkPolicy.Egress[0], spec.Egress[2])
+	})
+
+	t.Run("operator namespace unknown", func(t *testing.T) {
+		spec := getNetworkPolicySpec(cr, false, OperatorPeer{Labels: operatorPeer.Labels})
+
+		assert.Equal(t, &metav1.LabelSelector{}, spec.Ingress[0].From[0].NamespaceSelector)
+		assert.Equal(t, operator.PodSelector, spec.Ingress[0].From[0].PodSelector)
+	})
+}
+
+func TestNetworkPolicyReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, networkingv1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			NetworkPolicy: &v1beta1.GrafanaNetworkPolicy{},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewNetworkPolicyReconciler(cl, false, OperatorPeer{})
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	policy := model.GetGrafanaNetworkPolicy(cr, nil)
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(policy), policy))
+	assert.True(t, metav1.IsControlledBy(policy, cr))
+
+	cr.Spec.NetworkPolicy = nil
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	err = cl.Get(t.Context(), client.ObjectKeyFromObject(policy), &networkingv1.NetworkPolicy{})
+	assert.True(t, kuberr.IsNotFound(err))
+}
@@ -0,0 +1,143 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	policyv1 "k8s.io/api/policy/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PodDisruptionBudgetReconciler maintains the PodDisruptionBudget of instances running more than one replica
+type PodDisruptionBudgetReconciler struct {
+	client client.Client
+}
+
+func NewPodDisruptionBudgetReconciler(client client.Client) reconcilers.OperatorGrafanaReconciler {
+	return &PodDisruptionBudgetReconciler{
+		client: client,
+	}
+}
+
+// Reconcile creates the PodDisruptionBudget while more than one replica is requested and removes it otherwise,
+// a budget for a single replica would block node drains
+func (r *PodDisruptionBudgetReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, _ *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	log := logf.FromContext(ctx).WithName("PodDisruptionBudgetReconciler")
+
+	pdb := model.GetGrafanaPodDisruptionBudget(cr, scheme)
+
+	if !isPodDisruptionBudgetWanted(cr) {
+		err := r.removePodDisruptionBudget(ctx, cr, pdb)
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	log.V(1).Info("reconciling pod disruption budget")
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, pdb, func() error {
+		pdb.Spec = getPodDisruptionBudgetSpec(cr)
+
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, pdb, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(pdb, cr.Labels)
+		model.SetOwnershipAnnotations(pdb, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// isPodDisruptionBudgetWanted reports whether more than one replica is requested, or allowed when autoscaling,
+// and the budget isn't disabled
+func isPodDisruptionBudgetWanted(cr *v1beta1.Grafana) bool {
+	if cr.Spec.PodDisruptionBudget != nil && cr.Spec.PodDisruptionBudget.Enabled != nil && !*cr.Spec.PodDisruptionBudget.Enabled {
+		return false
+	}
+
+	if isAutoscalingEnabled(cr) {
+		return cr.Spec.Autoscaling.MaxReplicas > 1
+	}
+
+	if cr.Spec.Deployment == nil || cr.Spec.Deployment.Spec.Replicas == nil {
+		return false
+	}
+
+	return *cr.Spec.Deployment.Spec.Replicas > 1
+}
+
+// getPodDisruptionBudgetSpec selects the pods of the deployment, allowing one unavailable replica unless configured otherwise
+func getPodDisruptionBudgetSpec(cr *v1beta1.Grafana) policyv1.PodDisruptionBudgetSpec {
+	spec := policyv1.PodDisruptionBudgetSpec{
+		Selector: getPodSelector(cr),
+	}
+
+	if budget := cr.Spec.PodDisruptionBudget; budget != nil && (budget.MinAvailable != nil || budget.MaxUnavailable != nil) {
+		spec.MinAvailable = budget.MinAvailable
+		spec.MaxUnavailable = budget.MaxUnavailable
+
+		return spec
+	}
+
+	maxUnavailable := intstr.FromInt32(1)
+	spec.MaxUnavailable = &maxUnavailable
+
+	return spec
+}
+
+// removePodDisruptionBudget deletes the PodDisruptionBudget if it was created for the instance
+func (r *PodDisruptionBudgetReconciler) removePodDisruptionBudget(ctx context.Context, cr *v1beta1.Grafana, pdb *policyv1.PodDisruptionBudget) error {
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(pdb), pdb)
+	if kuberr.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching pod disruption budget: %w", err)
+	}
+
+	if !metav1.IsControlledBy(pdb, cr) {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("removing pod disruption budget", "name", pdb.Name)
+
+	if err := r.client.Delete(ctx, pdb); err != nil && !kuberr.IsNotFound(err) {
+		return fmt.Errorf("removing pod disruption budget: %w", err)
+	}
+
+	return nil
+}
+
+// getPodSelector returns the selector of the pods of the instance, overridden by spec.deployment.spec.selector
+func getPodSelector(cr *v1beta1.Grafana) *metav1.LabelSelector {
+	if cr.Spec.Deployment != nil && cr.Spec.Deployment.Spec.Selector != nil {
+		return cr.Spec.Deployment.Spec.Selector
+	}
+
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app": cr.Name,
+		},
+	}
+}
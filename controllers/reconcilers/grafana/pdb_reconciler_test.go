@@ -0,0 +1,122 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetPodDisruptionBudgetSpec(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			Deployment: &v1beta1.DeploymentV1{Spec: v1beta1.DeploymentV1Spec{Replicas: ptr.To[int32](3)}},
+		},
+	}
+
+	t.Run("defaults to maxUnavailable 1", func(t *testing.T) {
+		spec := getPodDisruptionBudgetSpec(cr)
+
+		assert.Equal(t, map[string]string{"app": "grafana"}, spec.Selector.MatchLabels)
+		assert.Equal(t, intstr.FromInt32(1), *spec.MaxUnavailable)
+		assert.Nil(t, spec.MinAvailable)
+	})
+
+	t.Run("minAvailable and deployment selector", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "custom"}}
+		cr.Spec.PodDisruptionBudget = &v1beta1.GrafanaPodDisruptionBudget{MinAvailable: ptr.To(intstr.FromString("50%"))}
+
+		spec := getPodDisruptionBudgetSpec(cr)
+
+		assert.Equal(t, map[string]string{"app": "custom"}, spec.Selector.MatchLabels)
+		assert.Equal(t, intstr.FromString("50%"), *spec.MinAvailable)
+		assert.Nil(t, spec.MaxUnavailable)
+	})
+}
+
+func TestIsPodDisruptionBudgetWanted(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas *int32
+		enabled  *bool
+		want     bool
+	}{
+		{name: "replicas unset"},
+		{name: "single replica", replicas: ptr.To[int32](1)},
+		{name: "multiple replicas", replicas: ptr.To[int32](2), want: true},
+		{name: "disabled", replicas: ptr.To[int32](2), enabled: ptr.To(false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &v1beta1.Grafana{
+				Spec: v1beta1.GrafanaSpec{
+					Deployment:          &v1beta1.DeploymentV1{Spec: v1beta1.DeploymentV1Spec{Replicas: tt.replicas}},
+					PodDisruptionBudget: &v1beta1.GrafanaPodDisruptionBudget{Enabled: tt.enabled},
+				},
+			}
+
+			assert.Equal(t, tt.want, isPodDisruptionBudgetWanted(cr))
+		})
+	}
+}
+
+func TestPodDisruptionBudgetReconciler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, policyv1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Deployment: &v1beta1.DeploymentV1{Spec: v1beta1.DeploymentV1Spec{Replicas: ptr.To[int32](2)}},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewPodDisruptionBudgetReconciler(cl)
+	key := client.ObjectKeyFromObject(model.GetGrafanaPodDisruptionBudget(cr, nil))
+
+	status, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	require.NoError(t, cl.Get(t.Context(), key, pdb))
+	assert.True(t, metav1.IsControlledBy(pdb, cr))
+	assert.Equal(t, intstr.FromInt32(1), *pdb.Spec.MaxUnavailable)
+
+	cr.Spec.Deployment.Spec.Replicas = ptr.To[int32](1)
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	err = cl.Get(t.Context(), key, &policyv1.PodDisruptionBudget{})
+	assert.True(t, kuberr.IsNotFound(err))
+}
+
+func TestIsPodDisruptionBudgetWantedAutoscaling(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		Spec: v1beta1.GrafanaSpec{
+			Deployment:  &v1beta1.DeploymentV1{Spec: v1beta1.DeploymentV1Spec{Replicas: ptr.To[int32](1)}},
+			Autoscaling: &v1beta1.GrafanaAutoscaling{MaxReplicas: 3},
+		},
+	}
+
+	assert.True(t, isPodDisruptionBudgetWanted(cr))
+
+	cr.Spec.Autoscaling.MaxReplicas = 1
+	assert.False(t, isPodDisruptionBudgetWanted(cr))
+}
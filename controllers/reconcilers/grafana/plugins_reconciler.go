@@ -3,16 +3,29 @@ package grafana
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	conditionUnsignedPluginsNotDeclared = "UnsignedPluginsNotDeclared"
+	conditionReasonNotDeclared          = "NotDeclared"
+)
+
 type PluginsReconciler struct {
 	client client.Client
 }
@@ -27,6 +40,7 @@ func (r *PluginsReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 	log := logf.FromContext(ctx).WithName("PluginsReconciler")
 
 	vars.Plugins = ""
+	vars.UnsignedPlugins = ""
 
 	cm := model.GetPluginsConfigMap(cr, scheme)
 
@@ -39,6 +53,7 @@ func (r *PluginsReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 		}
 
 		model.SetInheritedLabels(cm, cr.Labels)
+		model.SetOwnershipAnnotations(cm, cr.Annotations)
 
 		return nil
 	})
@@ -47,27 +62,89 @@ func (r *PluginsReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 		return v1beta1.OperatorStageResultFailed, err
 	}
 
-	// plugins config map found, but may be empty
-	if len(cm.BinaryData) == 0 {
-		vars.Plugins = ""
-		return v1beta1.OperatorStageResultSuccess, nil
+	pm := v1beta1.NewPluginMap()
+
+	err = mergePlugins(pm, cm)
+	if err != nil {
+		log.Error(err, "error consolidating plugins from ConfigMap", "name", cm.Name, "namespace", cm.Namespace)
+		return v1beta1.OperatorStageResultFailed, err
 	}
 
-	pm := v1beta1.NewPluginMap()
+	// content is only applied to the primary, its plugins are required to display the content on readonly instances
+	if cr.IsReadonly() && cr.Spec.Primary != "" {
+		primary := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: cr.Spec.Primary, Namespace: cr.Namespace}}
+		primaryCm := model.GetPluginsConfigMap(primary, scheme)
 
-	for k, v := range cm.BinaryData {
-		var plugins v1beta1.PluginList
+		err = r.client.Get(ctx, client.ObjectKeyFromObject(primaryCm), primaryCm)
+		if err != nil && !kuberr.IsNotFound(err) {
+			log.Error(err, "error getting plugins ConfigMap of primary", "name", primaryCm.Name, "namespace", primaryCm.Namespace)
+			return v1beta1.OperatorStageResultFailed, err
+		}
 
-		err = json.Unmarshal(v, &plugins)
+		err = mergePlugins(pm, primaryCm)
 		if err != nil {
-			log.Error(err, "error consolidating plugins from ConfigMap", "name", cm.Name, "namespace", cm.Namespace, "key", k)
+			log.Error(err, "error consolidating plugins from ConfigMap", "name", primaryCm.Name, "namespace", primaryCm.Namespace)
 			return v1beta1.OperatorStageResultFailed, err
 		}
-
-		pm.Merge(plugins)
 	}
 
 	vars.Plugins = pm.GetPluginList().String()
 
+	allowed, undeclared := getUnsignedPlugins(cr, pm)
+	vars.UnsignedPlugins = strings.Join(allowed, ",")
+
+	if len(undeclared) > 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               conditionUnsignedPluginsNotDeclared,
+			Reason:             conditionReasonNotDeclared,
+			Message:            fmt.Sprintf("unsigned plugins not declared by resources targeting the instance are not loaded: %s", strings.Join(undeclared, ", ")),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: cr.Generation,
+			LastTransitionTime: metav1.Time{Time: time.Now()},
+		})
+	} else {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, conditionUnsignedPluginsNotDeclared)
+	}
+
 	return v1beta1.OperatorStageResultSuccess, nil
 }
+
+// getUnsignedPlugins splits spec.plugins.unsignedAllowList into the plugins declared by resources targeting
+// the instance, which Grafana may load unsigned, and the undeclared ones. Nothing is allowed with Strict enforcement
+func getUnsignedPlugins(cr *v1beta1.Grafana, declared v1beta1.PluginMap) ([]string, []string) {
+	policy := cr.Spec.Plugins
+	if policy == nil || policy.SignatureEnforcement == v1beta1.GrafanaPluginSignatureStrict {
+		return nil, nil
+	}
+
+	var allowed, undeclared []string
+
+	for _, id := range policy.UnsignedAllowList {
+		if _, ok := declared[id]; ok {
+			allowed = append(allowed, id)
+		} else {
+			undeclared = append(undeclared, id)
+		}
+	}
+
+	slices.Sort(allowed)
+	slices.Sort(undeclared)
+
+	return slices.Compact(allowed), slices.Compact(undeclared)
+}
+
+// mergePlugins merges the plugins of all keys of the plugins ConfigMap into pm, the ConfigMap may be empty
+func mergePlugins(pm v1beta1.PluginMap, cm *corev1.ConfigMap) error {
+	for k, v := range cm.BinaryData {
+		var plugins v1beta1.PluginList
+
+		err := json.Unmarshal(v, &plugins)
+		if err != nil {
+			return fmt.Errorf("unmarshalling plugins of key %s: %w", k, err)
+		}
+
+		pm.Merge(plugins)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPluginsReconcilerReadonly(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	primaryPlugins := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "primary-plugins", Namespace: "default"},
+		BinaryData: map[string][]byte{
+			"default-dashboard-plugins": []byte(`[{"name":"grafana-piechart-panel","version":"1.6.4"}]`),
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(primaryPlugins).Build()
+	r := NewPluginsReconciler(cl)
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			Role:    v1beta1.GrafanaRoleReadonly,
+			Primary: "primary",
+		},
+	}
+
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	status, err := r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Equal(t, v1beta1.OperatorStageResultSuccess, status)
+	assert.Equal(t, "grafana-piechart-panel 1.6.4", vars.Plugins)
+
+	cr.Spec.Role = v1beta1.GrafanaRolePrimary
+
+	_, err = r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Empty(t, vars.Plugins)
+
+	cr.Spec.Role = v1beta1.GrafanaRoleReadonly
+	cr.Spec.Primary = "missing"
+
+	_, err = r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Empty(t, vars.Plugins)
+}
+
+func TestPluginsReconcilerUnsignedPlugins(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	plugins := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana-plugins", Namespace: "default"},
+		BinaryData: map[string][]byte{
+			"default-dashboard-plugins": []byte(`[{"name":"acme-private-panel","version":"1.0.0"},{"name":"grafana-piechart-panel","version":"1.6.4"}]`),
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(plugins).Build()
+	r := NewPluginsReconciler(cl)
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			Plugins: &v1beta1.GrafanaPluginPolicy{
+				UnsignedAllowList: []string{"acme-private-panel", "acme-unknown-app"},
+			},
+		},
+	}
+
+	vars := &v1beta1.OperatorReconcileVars{}
+
+	_, err := r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Equal(t, "acme-private-panel", vars.UnsignedPlugins)
+
+	condition := meta.FindStatusCondition(cr.Status.Conditions, conditionUnsignedPluginsNotDeclared)
+	require.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "acme-unknown-app")
+
+	cr.Spec.Plugins.UnsignedAllowList = []string{"acme-private-panel"}
+
+	_, err = r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, conditionUnsignedPluginsNotDeclared))
+
+	cr.Spec.Plugins = &v1beta1.GrafanaPluginPolicy{SignatureEnforcement: v1beta1.GrafanaPluginSignatureStrict}
+
+	_, err = r.Reconcile(t.Context(), cr, vars, s)
+	require.NoError(t, err)
+	assert.Empty(t, vars.UnsignedPlugins)
+}
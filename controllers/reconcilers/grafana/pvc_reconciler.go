@@ -30,6 +30,12 @@ func (r *PvcReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, vars
 		return v1beta1.OperatorStageResultSuccess, nil
 	}
 
+	// The StatefulSet creates the claims of its pods
+	if usesDataVolumeClaimTemplate(cr) {
+		log.Info("skip creating persistent volume claim, using volume claim template")
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
 	pvc := model.GetGrafanaDataPVC(cr, scheme)
 
 	_, err := controllerutil.CreateOrUpdate(ctx, r.client, pvc, func() error {
@@ -49,6 +55,7 @@ func (r *PvcReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, vars
 		}
 
 		model.SetInheritedLabels(pvc, cr.Labels)
+		model.SetOwnershipAnnotations(pvc, cr.Annotations)
 
 		return nil
 	})
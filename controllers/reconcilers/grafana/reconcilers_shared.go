@@ -1,7 +1,12 @@
 package grafana
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
@@ -23,3 +28,92 @@ func setInvalidMergeCondition(cr *v1beta1.Grafana, object string, err error) {
 func removeInvalidMergeCondition(cr *v1beta1.Grafana, object string) {
 	meta.RemoveStatusCondition(&cr.Status.Conditions, fmt.Sprintf("Invalid%sOverride", object))
 }
+
+// lookupHost resolves hostnames, replaced in tests
+var lookupHost = net.DefaultResolver.LookupHost
+
+// checkDNSResolution returns an error while the host of adminURL is the spec.dns hostname and doesn't resolve yet,
+// so status.adminUrl doesn't advertise a hostname before external-dns created the record
+func checkDNSResolution(ctx context.Context, cr *v1beta1.Grafana, adminURL string) error {
+	dns := cr.Spec.DNS
+	if dns == nil || dns.SkipResolutionCheck {
+		return nil
+	}
+
+	u, err := url.Parse(adminURL)
+	if err != nil || !strings.EqualFold(u.Hostname(), dns.Hostname) {
+		return nil
+	}
+
+	if _, err := lookupHost(ctx, dns.Hostname); err != nil {
+		return fmt.Errorf("waiting for %s to resolve: %w", dns.Hostname, err)
+	}
+
+	return nil
+}
+
+// setAdminURL assigns status.adminUrl unless its host is the spec.dns hostname which doesn't resolve yet, the
+// previous url is kept meanwhile and the remaining stages still run, the pending record is reported through vars
+func setAdminURL(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, adminURL string) {
+	if err := checkDNSResolution(ctx, cr, adminURL); err != nil {
+		if vars != nil {
+			vars.DNSPending = err.Error()
+		}
+
+		return
+	}
+
+	cr.Status.AdminURL = adminURL
+}
+
+// adminURLTarget holds what the admin url of an ingress, route or HTTPRoute is derived from,
+// the fields are available to spec.client.adminUrlTemplate
+type adminURLTarget struct {
+	Scheme   string
+	Hostname string
+	Address  string
+	Port     int32
+}
+
+// getAdminURL returns the admin url of the target according to spec.client.adminUrlStrategy,
+// empty when the target lacks the hostname or address the strategy relies on
+func getAdminURL(cr *v1beta1.Grafana, target adminURLTarget) (string, error) {
+	var strategy v1beta1.AdminURLStrategy
+	if cr.Spec.Client != nil {
+		strategy = cr.Spec.Client.AdminURLStrategy
+	}
+
+	host := target.Hostname
+
+	switch strategy {
+	case v1beta1.AdminURLStrategyHostname:
+	case v1beta1.AdminURLStrategyLoadBalancerIP:
+		host = target.Address
+	case v1beta1.AdminURLStrategyExplicit:
+		tmpl, err := template.New("adminUrl").Parse(cr.Spec.Client.AdminURLTemplate)
+		if err != nil {
+			return "", fmt.Errorf("parsing admin url template: %w", err)
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, target); err != nil {
+			return "", fmt.Errorf("rendering admin url template: %w", err)
+		}
+
+		return sb.String(), nil
+	default:
+		if host == "" {
+			host = target.Address
+		}
+	}
+
+	if host == "" {
+		return "", nil
+	}
+
+	if target.Port != 0 {
+		return fmt.Sprintf("%v://%v:%v", target.Scheme, host, target.Port), nil
+	}
+
+	return fmt.Sprintf("%v://%v", target.Scheme, host), nil
+}
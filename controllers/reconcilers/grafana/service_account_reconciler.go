@@ -41,6 +41,7 @@ func (r *ServiceAccountReconciler) Reconcile(ctx context.Context, cr *v1beta1.Gr
 		}
 
 		model.SetInheritedLabels(sa, cr.Labels)
+		model.SetOwnershipAnnotations(sa, cr.Annotations)
 
 		return nil
 	})
@@ -10,6 +10,8 @@ import (
 	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/grafana/grafana-operator/v5/controllers/reconcilers"
 	v1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,6 +19,11 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	injectCABundleAnnotation    = "service.beta.openshift.io/inject-cabundle"
+)
+
 type ServiceReconciler struct {
 	client        client.Client
 	clusterDomain string
@@ -35,6 +42,8 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 	service := model.GetGrafanaService(cr, scheme)
 
 	_, err := controllerutil.CreateOrUpdate(ctx, r.client, service, func() error {
+		live := service.Spec.DeepCopy()
+
 		service.Spec = v1.ServiceSpec{
 			Ports: getServicePorts(cr),
 			Selector: map[string]string{
@@ -59,6 +68,23 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 		}
 
 		model.SetInheritedLabels(service, cr.Labels)
+		model.SetOwnershipAnnotations(service, cr.Annotations)
+
+		// Selected by the ServiceMonitor of the instance
+		service.Labels[serviceInstanceLabel] = cr.Name
+
+		setServingCertAnnotation(cr, service)
+
+		// The record points at the Service when the instance isn't exposed otherwise
+		if cr.Spec.Ingress == nil && cr.Spec.Route == nil && cr.Spec.HTTPRoute == nil {
+			model.SetDNSAnnotations(service, cr.Spec.DNS)
+		} else {
+			model.SetDNSAnnotations(service, nil)
+		}
+
+		if detectDrift(cr, vars, service, "Service", live, &service.Spec) {
+			service.Spec = *live
+		}
 
 		return nil
 	})
@@ -75,7 +101,12 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 			adminHost += "." + r.clusterDomain
 		}
 
-		cr.Status.AdminURL = fmt.Sprintf("%v://%v:%d", getGrafanaServerProtocol(cr), adminHost, int32(GetGrafanaPort(cr))) // #nosec G115
+		cr.Status.AdminURL = fmt.Sprintf("%v://%v:%d", getGrafanaServerScheme(cr), adminHost, int32(GetGrafanaPort(cr))) // #nosec G115
+	}
+
+	err = r.reconcileServiceCA(ctx, cr, scheme)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
 	}
 
 	// Headless service for grafana unified alerting
@@ -83,6 +114,7 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 
 	_, err = controllerutil.CreateOrUpdate(ctx, r.client, headlessService, func() error {
 		model.SetInheritedLabels(headlessService, cr.Labels)
+		model.SetOwnershipAnnotations(headlessService, cr.Annotations)
 		headlessService.Spec = v1.ServiceSpec{
 			ClusterIP: "None",
 			Ports:     getHeadlessServicePorts(cr),
@@ -101,15 +133,102 @@ func (r *ServiceReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana,
 	return v1beta1.OperatorStageResultSuccess, nil
 }
 
+// setServingCertAnnotation has the OpenShift service CA issue the serving cert of spec.route.servingCert,
+// the annotation is removed again when disabled
+func setServingCertAnnotation(cr *v1beta1.Grafana, service *v1.Service) {
+	secretName := model.GetGrafanaServingCertSecret(cr).Name
+
+	if !cr.UsesServingCert() {
+		if service.Annotations[servingCertSecretAnnotation] == secretName {
+			delete(service.Annotations, servingCertSecretAnnotation)
+		}
+
+		return
+	}
+
+	if service.Annotations == nil {
+		service.Annotations = make(map[string]string)
+	}
+
+	service.Annotations[servingCertSecretAnnotation] = secretName
+}
+
+// reconcileServiceCA maintains the ConfigMap the OpenShift service CA injects its CA bundle into, the operator and
+// the route verify the serving cert with it
+func (r *ServiceReconciler) reconcileServiceCA(ctx context.Context, cr *v1beta1.Grafana, scheme *runtime.Scheme) error {
+	configMap := model.GetGrafanaServiceCAConfigMap(cr, scheme)
+
+	if !cr.UsesServingCert() {
+		err := r.client.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+		if kuberr.IsNotFound(err) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("fetching service CA configmap: %w", err)
+		}
+
+		if !metav1.IsControlledBy(configMap, cr) {
+			return nil
+		}
+
+		if err := r.client.Delete(ctx, configMap); err != nil && !kuberr.IsNotFound(err) {
+			return fmt.Errorf("removing service CA configmap: %w", err)
+		}
+
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, configMap, func() error {
+		if scheme != nil {
+			err := controllerutil.SetControllerReference(cr, configMap, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(configMap, cr.Labels)
+		model.SetOwnershipAnnotations(configMap, cr.Annotations)
+
+		if configMap.Annotations == nil {
+			configMap.Annotations = make(map[string]string)
+		}
+
+		configMap.Annotations[injectCABundleAnnotation] = "true"
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reconciling service CA configmap: %w", err)
+	}
+
+	return nil
+}
+
 func getGrafanaServerProtocol(cr *v1beta1.Grafana) string {
 	protocol := cr.GetConfigSectionValue("server", "protocol")
 	if protocol != "" {
 		return protocol
 	}
 
+	// The serving cert enables https, see config.WithServingCert
+	if cr.UsesServingCert() {
+		return "https"
+	}
+
 	return config.GrafanaServerProtocol
 }
 
+// getGrafanaServerScheme returns the URL scheme of the protocol Grafana serves, h2 is HTTP/2 over TLS
+func getGrafanaServerScheme(cr *v1beta1.Grafana) string {
+	switch getGrafanaServerProtocol(cr) {
+	case "https", "h2":
+		return "https"
+	default:
+		return "http"
+	}
+}
+
 func GetGrafanaPort(cr *v1beta1.Grafana) int {
 	port := cr.GetConfigSectionValue("server", "http_port")
 
@@ -5,13 +5,22 @@ import (
 
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_getGrafanaServerProtocol(t *testing.T) {
 	tests := []struct {
 		name   string
 		config map[string]map[string]string
+		route  *v1beta1.RouteOpenshiftV1
 		want   string
 	}{
 		{
@@ -41,6 +50,11 @@ func Test_getGrafanaServerProtocol(t *testing.T) {
 			},
 			want: "https",
 		},
+		{
+			name:  "Serving cert",
+			route: &v1beta1.RouteOpenshiftV1{ServingCert: true},
+			want:  "https",
+		},
 	}
 
 	for _, tt := range tests {
@@ -48,6 +62,7 @@ func Test_getGrafanaServerProtocol(t *testing.T) {
 			cr := &v1beta1.Grafana{
 				Spec: v1beta1.GrafanaSpec{
 					Config: tt.config,
+					Route:  tt.route,
 				},
 			}
 
@@ -58,6 +73,18 @@ func Test_getGrafanaServerProtocol(t *testing.T) {
 	}
 }
 
+func TestGetGrafanaServerScheme(t *testing.T) {
+	for protocol, want := range map[string]string{"": "http", "http": "http", "https": "https", "h2": "https"} {
+		cr := &v1beta1.Grafana{
+			Spec: v1beta1.GrafanaSpec{
+				Config: map[string]map[string]string{"server": {"protocol": protocol}},
+			},
+		}
+
+		assert.Equal(t, want, getGrafanaServerScheme(cr), "protocol %q", protocol)
+	}
+}
+
 func TestGetGrafanaPort(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -103,3 +130,42 @@ func TestGetGrafanaPort(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceReconcilerServingCert(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, corev1.AddToScheme(s))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Route: &v1beta1.RouteOpenshiftV1{ServingCert: true},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).Build()
+	r := NewServiceReconciler(cl, "cluster.local")
+
+	_, err := r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	service := model.GetGrafanaService(cr, nil)
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(service), service))
+	assert.Equal(t, "grafana-serving-cert", service.Annotations[servingCertSecretAnnotation])
+
+	configMap := model.GetGrafanaServiceCAConfigMap(cr, nil)
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(configMap), configMap))
+	assert.True(t, metav1.IsControlledBy(configMap, cr))
+	assert.Equal(t, "true", configMap.Annotations[injectCABundleAnnotation])
+
+	cr.Spec.Route.ServingCert = false
+
+	_, err = r.Reconcile(t.Context(), cr, &v1beta1.OperatorReconcileVars{}, s)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(service), service))
+	assert.NotContains(t, service.Annotations, servingCertSecretAnnotation)
+
+	err = cl.Get(t.Context(), client.ObjectKeyFromObject(configMap), configMap)
+	assert.True(t, kuberr.IsNotFound(err))
+}
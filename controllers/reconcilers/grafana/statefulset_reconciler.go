@@ -0,0 +1,362 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/grafana/grafana-operator/v5/controllers/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileStatefulSet runs the instance in a StatefulSet rendered from the same pod template as the Deployment.
+// A Deployment left from the Deployment strategy is scaled down and removed first
+func (r *DeploymentReconciler) reconcileStatefulSet(ctx context.Context, cr *v1beta1.Grafana, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme, tlsDatasources []v1beta1.GrafanaDatasource) (v1beta1.OperatorStageStatus, error) {
+	deployment := model.GetGrafanaDeployment(cr, nil)
+
+	retired, err := r.retireWorkload(ctx, cr, deployment)
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	if !retired {
+		return v1beta1.OperatorStageResultInProgress, fmt.Errorf("waiting for deployment %s to scale down", deployment.Name)
+	}
+
+	claim, err := getDataVolumeClaimTemplate(cr)
+	if err != nil {
+		setInvalidMergeCondition(cr, "PersistentVolumeClaim", err)
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	removeInvalidMergeCondition(cr, "PersistentVolumeClaim")
+
+	statefulSet := model.GetGrafanaStatefulSet(cr, scheme)
+
+	var changes []v1beta1.GrafanaHistoryEntry
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, statefulSet, func() error {
+		live := statefulSet.Spec.DeepCopy()
+
+		// spec.deployment overrides the fields shared by both workloads
+		desired := &appsv1.Deployment{
+			ObjectMeta: statefulSet.ObjectMeta,
+			Spec:       getDeploymentSpec(cr, statefulSet.Name, scheme, vars, r.isOpenShift, tlsDatasources),
+		}
+
+		err := applyDeploymentOverrides(cr, desired)
+		if err != nil {
+			setInvalidMergeCondition(cr, "Deployment", err)
+			return err
+		}
+
+		removeInvalidMergeCondition(cr, "Deployment")
+
+		// Writing the replicas would fight the HorizontalPodAutoscaler
+		if isAutoscalingEnabled(cr) {
+			desired.Spec.Replicas = getAutoscaledReplicas(cr, live.Replicas)
+		}
+
+		statefulSet.ObjectMeta = desired.ObjectMeta
+		statefulSet.Spec = getStatefulSetSpec(cr, &desired.Spec, live, claim, scheme)
+
+		changes = getRolloutChanges(&appsv1.DeploymentSpec{Template: live.Template}, &desired.Spec)
+
+		if scheme != nil {
+			err = controllerutil.SetControllerReference(cr, statefulSet, scheme)
+			if err != nil {
+				return err
+			}
+		}
+
+		model.SetInheritedLabels(statefulSet, cr.Labels)
+		model.SetOwnershipAnnotations(statefulSet, cr.Annotations)
+
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	recordHistory(cr, changes, time.Now())
+
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+// getStatefulSetSpec converts the desired deployment spec. The selector, service name and volume claim templates
+// can't change after creation and are kept from the live StatefulSet
+func getStatefulSetSpec(cr *v1beta1.Grafana, desired *appsv1.DeploymentSpec, live *appsv1.StatefulSetSpec, claim *corev1.PersistentVolumeClaim, scheme *runtime.Scheme) appsv1.StatefulSetSpec {
+	spec := appsv1.StatefulSetSpec{
+		Replicas:             desired.Replicas,
+		Selector:             desired.Selector,
+		Template:             *desired.Template.DeepCopy(),
+		ServiceName:          model.GetGrafanaHeadlessService(cr, scheme).Name,
+		MinReadySeconds:      desired.MinReadySeconds,
+		RevisionHistoryLimit: desired.RevisionHistoryLimit,
+	}
+
+	if claim != nil {
+		spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{*claim}
+	}
+
+	if live.ServiceName != "" {
+		spec.Selector = live.Selector
+		spec.ServiceName = live.ServiceName
+		spec.VolumeClaimTemplates = live.VolumeClaimTemplates
+		spec.PodManagementPolicy = live.PodManagementPolicy
+	}
+
+	// The claim of each pod takes the place of the emptyDir data volume
+	if slices.ContainsFunc(spec.VolumeClaimTemplates, isDataVolumeClaim) {
+		spec.Template.Spec.Volumes = slices.DeleteFunc(spec.Template.Spec.Volumes, func(v corev1.Volume) bool {
+			return v.Name == config.GrafanaDataVolumeName
+		})
+	}
+
+	keepStatefulSetServerDefaults(&spec, live)
+
+	return spec
+}
+
+// keepStatefulSetServerDefaults is keepServerDefaults for StatefulSets
+func keepStatefulSetServerDefaults(desired, live *appsv1.StatefulSetSpec) {
+	keepDefault(&desired.Replicas, live.Replicas, 1)
+	keepDefault(&desired.RevisionHistoryLimit, live.RevisionHistoryLimit, 10)
+	keepDefaultValue(&desired.PodManagementPolicy, live.PodManagementPolicy, appsv1.OrderedReadyPodManagement)
+
+	if desired.UpdateStrategy.Type == "" && live.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType &&
+		(live.UpdateStrategy.RollingUpdate == nil || ptr.Deref(live.UpdateStrategy.RollingUpdate.Partition, 0) == 0) &&
+		(live.UpdateStrategy.RollingUpdate == nil || live.UpdateStrategy.RollingUpdate.MaxUnavailable == nil) {
+		desired.UpdateStrategy = live.UpdateStrategy
+	}
+
+	retain := appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	keepDefault(&desired.PersistentVolumeClaimRetentionPolicy, live.PersistentVolumeClaimRetentionPolicy, retain)
+
+	keepPodServerDefaults(&desired.Template.Spec, &live.Template.Spec)
+}
+
+func isDataVolumeClaim(claim corev1.PersistentVolumeClaim) bool {
+	return claim.Name == config.GrafanaDataVolumeName
+}
+
+// usesDataVolumeClaimTemplate reports whether spec.persistentVolumeClaim is rendered as the volume claim template
+// of the data volume. Instances overriding the data volume, e.g. to keep mounting the claim used by their
+// Deployment, continue to use the claim created by the PvcReconciler
+func usesDataVolumeClaimTemplate(cr *v1beta1.Grafana) bool {
+	if !cr.IsStatefulSet() || cr.Spec.PersistentVolumeClaim == nil {
+		return false
+	}
+
+	template := cr.Spec.Deployment.Spec.Template
+	if template == nil || template.Spec == nil {
+		return true
+	}
+
+	return !slices.ContainsFunc(template.Spec.Volumes, func(v corev1.Volume) bool {
+		return v.Name == config.GrafanaDataVolumeName
+	})
+}
+
+// getDataVolumeClaimTemplate returns the volume claim template of the data volume, nil when it isn't used
+func getDataVolumeClaimTemplate(cr *v1beta1.Grafana) (*corev1.PersistentVolumeClaim, error) {
+	if !usesDataVolumeClaimTemplate(cr) {
+		return nil, nil
+	}
+
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: config.GrafanaDataVolumeName,
+		},
+	}
+
+	err := v1beta1.Merge(claim, cr.Spec.PersistentVolumeClaim)
+	if err != nil {
+		return nil, err
+	}
+
+	return claim, nil
+}
+
+// getDeploymentOverrides returns spec.deployment without the strategy, sidecars and init containers, which aren't fields of the workloads
+func getDeploymentOverrides(cr *v1beta1.Grafana) *v1beta1.DeploymentV1 {
+	if cr.Spec.Deployment == nil {
+		return nil
+	}
+
+	overrides := cr.Spec.Deployment.DeepCopy()
+	overrides.Strategy = ""
+	overrides.Sidecars = nil
+	overrides.InitContainers = nil
+	overrides.SpreadPolicy = ""
+
+	return overrides
+}
+
+// applyDeploymentOverrides merges spec.deployment into the desired workload, derives GOMEMLIMIT from the resulting
+// memory limit and adds its sidecars, init containers and spread policy
+func applyDeploymentOverrides(cr *v1beta1.Grafana, desired *appsv1.Deployment) error {
+	err := v1beta1.Merge(desired, getDeploymentOverrides(cr))
+	if err != nil {
+		return err
+	}
+
+	setGoMemLimit(cr, desired)
+
+	if cr.Spec.Deployment == nil {
+		return nil
+	}
+
+	applySpreadPolicy(cr.Spec.Deployment.SpreadPolicy, desired)
+
+	// Container names are unique across the init containers and containers of a pod
+	names := make(map[string]bool)
+	for _, container := range slices.Concat(desired.Spec.Template.Spec.InitContainers, desired.Spec.Template.Spec.Containers) {
+		names[container.Name] = true
+	}
+
+	add := func(field string, containers []corev1.Container, to *[]corev1.Container) error {
+		for _, container := range containers {
+			if names[container.Name] {
+				return fmt.Errorf("spec.deployment.%s: container name %q is already used in the pod", field, container.Name)
+			}
+
+			names[container.Name] = true
+			*to = append(*to, container)
+		}
+
+		return nil
+	}
+
+	podSpec := &desired.Spec.Template.Spec
+
+	err = add("initContainers", cr.Spec.Deployment.InitContainers, &podSpec.InitContainers)
+	if err != nil {
+		return err
+	}
+
+	return add("sidecars", cr.Spec.Deployment.Sidecars, &podSpec.Containers)
+}
+
+// applySpreadPolicy expands the spread policy preset into the pod template, leaving constraints of the same topology
+// key and anti-affinity set through spec.template untouched
+func applySpreadPolicy(policy v1beta1.SpreadPolicy, desired *appsv1.Deployment) {
+	if policy == "" || desired.Spec.Selector == nil {
+		return
+	}
+
+	podSpec := &desired.Spec.Template.Spec
+
+	// Pods of the instance are matched by the selector of the workload
+	selector := &metav1.LabelSelector{MatchLabels: desired.Spec.Selector.MatchLabels}
+
+	switch policy {
+	case v1beta1.SpreadPolicyZoneBalanced:
+		for _, constraint := range podSpec.TopologySpreadConstraints {
+			if constraint.TopologyKey == corev1.LabelTopologyZone {
+				return
+			}
+		}
+
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		})
+	case v1beta1.SpreadPolicyHostnameAntiAffinity:
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+
+		if podSpec.Affinity.PodAntiAffinity != nil {
+			return
+		}
+
+		// Preferred rather than required, so a rollout on a single node doesn't wait for the old pod forever
+		podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					TopologyKey:   corev1.LabelHostname,
+					LabelSelector: selector,
+				},
+			}},
+		}
+	}
+}
+
+// retireWorkload scales the Deployment or StatefulSet of the other strategy to zero and deletes it once its pods
+// are gone, so pods of both never mount a ReadWriteOnce data volume at the same time. Reports whether it's gone
+func (r *DeploymentReconciler) retireWorkload(ctx context.Context, cr *v1beta1.Grafana, workload client.Object) (bool, error) {
+	kind := "deployment"
+	if _, ok := workload.(*appsv1.StatefulSet); ok {
+		kind = "statefulset"
+	}
+
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(workload), workload)
+	if kuberr.IsNotFound(err) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("fetching %s: %w", kind, err)
+	}
+
+	if !metav1.IsControlledBy(workload, cr) {
+		return true, nil
+	}
+
+	var (
+		replicas **int32
+		running  int32
+	)
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		replicas, running = &w.Spec.Replicas, w.Status.Replicas
+	case *appsv1.StatefulSet:
+		replicas, running = &w.Spec.Replicas, w.Status.Replicas
+	}
+
+	log := logf.FromContext(ctx)
+
+	if *replicas == nil || **replicas != 0 {
+		log.Info("scaling down "+kind+" of the previous strategy", "name", workload.GetName())
+
+		patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+		*replicas = ptr.To[int32](0)
+
+		if err := r.client.Patch(ctx, workload, patch); err != nil {
+			return false, fmt.Errorf("scaling down %s: %w", kind, err)
+		}
+
+		return false, nil
+	}
+
+	if running > 0 {
+		return false, nil
+	}
+
+	log.Info("removing "+kind+" of the previous strategy", "name", workload.GetName())
+
+	if err := r.client.Delete(ctx, workload); err != nil && !kuberr.IsNotFound(err) {
+		return false, fmt.Errorf("removing %s: %w", kind, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,309 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func statefulSetGrafana() *v1beta1.Grafana {
+	return &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default", UID: "grafana-uid"},
+		Spec: v1beta1.GrafanaSpec{
+			Deployment: &v1beta1.DeploymentV1{Strategy: v1beta1.DeploymentStrategyStatefulSet},
+			PersistentVolumeClaim: &v1beta1.PersistentVolumeClaimV1{
+				Spec: &v1beta1.PersistentVolumeClaimV1Spec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestUsesDataVolumeClaimTemplate(t *testing.T) {
+	t.Run("statefulset with persistent volume claim", func(t *testing.T) {
+		assert.True(t, usesDataVolumeClaimTemplate(statefulSetGrafana()))
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		cr := statefulSetGrafana()
+		cr.Spec.Deployment.Strategy = v1beta1.DeploymentStrategyDeployment
+
+		assert.False(t, usesDataVolumeClaimTemplate(cr))
+	})
+
+	t.Run("without persistent volume claim", func(t *testing.T) {
+		cr := statefulSetGrafana()
+		cr.Spec.PersistentVolumeClaim = nil
+
+		assert.False(t, usesDataVolumeClaimTemplate(cr))
+	})
+
+	t.Run("data volume overridden", func(t *testing.T) {
+		cr := statefulSetGrafana()
+		cr.Spec.Deployment.Spec.Template = &v1beta1.DeploymentV1PodTemplateSpec{
+			Spec: &v1beta1.DeploymentV1PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: config.GrafanaDataVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "grafana-pvc"},
+					},
+				}},
+			},
+		}
+
+		assert.False(t, usesDataVolumeClaimTemplate(cr))
+	})
+}
+
+func TestGetStatefulSetSpec(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cr := statefulSetGrafana()
+	vars := &v1beta1.OperatorReconcileVars{}
+	desired := getDeploymentSpec(cr, "grafana-statefulset", scheme, vars, false, nil)
+
+	claim, err := getDataVolumeClaimTemplate(cr)
+	require.NoError(t, err)
+
+	hasDataVolume := func(spec appsv1.StatefulSetSpec) bool {
+		for _, v := range spec.Template.Spec.Volumes {
+			if v.Name == config.GrafanaDataVolumeName {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	t.Run("new statefulset", func(t *testing.T) {
+		spec := getStatefulSetSpec(cr, &desired, &appsv1.StatefulSetSpec{}, claim, scheme)
+
+		assert.Equal(t, "grafana-alerting", spec.ServiceName)
+		assert.Equal(t, desired.Selector, spec.Selector)
+		require.Len(t, spec.VolumeClaimTemplates, 1)
+		assert.Equal(t, config.GrafanaDataVolumeName, spec.VolumeClaimTemplates[0].Name)
+		assert.Equal(t, resource.MustParse("1Gi"), spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+		assert.False(t, hasDataVolume(spec))
+		assert.True(t, hasDataVolume(appsv1.StatefulSetSpec{Template: desired.Template}), "desired deployment spec is left untouched")
+	})
+
+	t.Run("keeps immutable fields", func(t *testing.T) {
+		live := &appsv1.StatefulSetSpec{
+			ServiceName:         "grafana-service",
+			Selector:            &metav1.LabelSelector{MatchLabels: map[string]string{"app": "legacy"}},
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+		}
+
+		spec := getStatefulSetSpec(cr, &desired, live, claim, scheme)
+
+		assert.Equal(t, "grafana-service", spec.ServiceName)
+		assert.Equal(t, live.Selector, spec.Selector)
+		assert.Empty(t, spec.VolumeClaimTemplates)
+		assert.True(t, hasDataVolume(spec), "emptyDir stays without a claim template")
+	})
+}
+
+func TestRetireWorkload(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+	require.NoError(t, appsv1.AddToScheme(s))
+
+	cr := statefulSetGrafana()
+
+	newDeployment := func(running int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "grafana-deployment",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1beta1.GroupVersion.String(),
+					Kind:       "Grafana",
+					Name:       cr.Name,
+					UID:        cr.UID,
+					Controller: ptr.To(true),
+				}},
+			},
+			Spec:   appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+			Status: appsv1.DeploymentStatus{Replicas: running},
+		}
+	}
+
+	t.Run("scales down before removing", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(newDeployment(0)).Build()
+		r := &DeploymentReconciler{client: cl}
+
+		retired, err := r.retireWorkload(t.Context(), cr, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "grafana-deployment", Namespace: "default"}})
+		require.NoError(t, err)
+		assert.False(t, retired)
+
+		deployment := &appsv1.Deployment{}
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKey{Namespace: "default", Name: "grafana-deployment"}, deployment))
+		assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+
+		retired, err = r.retireWorkload(t.Context(), cr, deployment)
+		require.NoError(t, err)
+		assert.True(t, retired)
+
+		err = cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})
+		assert.True(t, kuberr.IsNotFound(err))
+	})
+
+	t.Run("waits for running pods", func(t *testing.T) {
+		deployment := newDeployment(1)
+		deployment.Spec.Replicas = ptr.To[int32](0)
+
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(deployment).Build()
+		r := &DeploymentReconciler{client: cl}
+
+		retired, err := r.retireWorkload(t.Context(), cr, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "grafana-deployment", Namespace: "default"}})
+		require.NoError(t, err)
+		assert.False(t, retired)
+	})
+
+	t.Run("ignores workloads of others", func(t *testing.T) {
+		deployment := newDeployment(1)
+		deployment.OwnerReferences = nil
+
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(deployment).Build()
+		r := &DeploymentReconciler{client: cl}
+
+		retired, err := r.retireWorkload(t.Context(), cr, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "grafana-deployment", Namespace: "default"}})
+		require.NoError(t, err)
+		assert.True(t, retired)
+
+		require.NoError(t, cl.Get(t.Context(), client.ObjectKeyFromObject(deployment), &appsv1.Deployment{}))
+	})
+}
+
+func TestApplyDeploymentOverrides(t *testing.T) {
+	cr := &v1beta1.Grafana{
+		Spec: v1beta1.GrafanaSpec{
+			Deployment: &v1beta1.DeploymentV1{
+				Spec: v1beta1.DeploymentV1Spec{
+					Template: &v1beta1.DeploymentV1PodTemplateSpec{
+						Spec: &v1beta1.DeploymentV1PodSpec{
+							Containers: []corev1.Container{{Name: "grafana", Image: "grafana:custom"}, {Name: "template"}},
+						},
+					},
+				},
+				Sidecars:       []corev1.Container{{Name: "proxy", Image: "proxy"}, {Name: "exporter", Image: "exporter"}},
+				InitContainers: []corev1.Container{{Name: "wait", Image: "busybox"}},
+			},
+		},
+	}
+
+	desired := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "grafana", Image: "grafana"}}},
+			},
+		},
+	}
+
+	require.NoError(t, applyDeploymentOverrides(cr, desired))
+
+	var names []string
+	for _, container := range desired.Spec.Template.Spec.Containers {
+		names = append(names, container.Name)
+	}
+
+	assert.Equal(t, []string{"grafana", "template", "proxy", "exporter"}, names)
+	assert.Equal(t, "grafana:custom", desired.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []corev1.Container{{Name: "wait", Image: "busybox"}}, desired.Spec.Template.Spec.InitContainers)
+
+	cr.Spec.Deployment.InitContainers = []corev1.Container{{Name: "grafana"}}
+
+	err := applyDeploymentOverrides(cr, &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "grafana"}}},
+			},
+		},
+	})
+	assert.ErrorContains(t, err, `spec.deployment.initContainers: container name "grafana" is already used in the pod`)
+}
+
+func TestApplySpreadPolicy(t *testing.T) {
+	deployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}},
+			},
+		}
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}}
+
+	t.Run("zone-balanced", func(t *testing.T) {
+		desired := deployment()
+		applySpreadPolicy(v1beta1.SpreadPolicyZoneBalanced, desired)
+
+		assert.Equal(t, []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		}}, desired.Spec.Template.Spec.TopologySpreadConstraints)
+		assert.Nil(t, desired.Spec.Template.Spec.Affinity)
+	})
+
+	t.Run("hostname-anti-affinity", func(t *testing.T) {
+		desired := deployment()
+		applySpreadPolicy(v1beta1.SpreadPolicyHostnameAntiAffinity, desired)
+
+		antiAffinity := desired.Spec.Template.Spec.Affinity.PodAntiAffinity
+		require.NotNil(t, antiAffinity)
+		require.Len(t, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+		assert.Equal(t, corev1.PodAffinityTerm{
+			TopologyKey:   corev1.LabelHostname,
+			LabelSelector: selector,
+		}, antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm)
+		assert.Empty(t, desired.Spec.Template.Spec.TopologySpreadConstraints)
+	})
+
+	t.Run("template takes precedence", func(t *testing.T) {
+		cr := &v1beta1.Grafana{
+			Spec: v1beta1.GrafanaSpec{
+				Deployment: &v1beta1.DeploymentV1{
+					Spec: v1beta1.DeploymentV1Spec{
+						Template: &v1beta1.DeploymentV1PodTemplateSpec{
+							Spec: &v1beta1.DeploymentV1PodSpec{
+								TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+									MaxSkew:           2,
+									TopologyKey:       corev1.LabelTopologyZone,
+									WhenUnsatisfiable: corev1.DoNotSchedule,
+								}},
+							},
+						},
+					},
+					SpreadPolicy: v1beta1.SpreadPolicyZoneBalanced,
+				},
+			},
+		}
+
+		desired := deployment()
+		require.NoError(t, applyDeploymentOverrides(cr, desired))
+
+		constraints := desired.Spec.Template.Spec.TopologySpreadConstraints
+		require.Len(t, constraints, 1)
+		assert.Equal(t, int32(2), constraints[0].MaxSkew)
+		assert.Equal(t, corev1.DoNotSchedule, constraints[0].WhenUnsatisfiable)
+	})
+}
@@ -0,0 +1,370 @@
+package grafana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/grafana/grafana-openapi-client-go/client/datasources"
+	"github.com/grafana/grafana-openapi-client-go/client/orgs"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// statusPageOrgRole is the role of the operator user in the organization of the status page
+const statusPageOrgRole = "Admin"
+
+var ErrStatusPageOrgNotOwned = errors.New("status page organization not created by the operator")
+
+// reconcileStatusPage maintains the organization of spec.statusPage: the operator user is its only member
+// and it holds copies of the selected dashboards only. The copies keep the uid of the source dashboard, the
+// datasources they reference are provisioned into the organization by the datasource controller.
+// Selecting another organization than the one of the operator user requires basic auth credentials
+func (r *CompleteReconciler) reconcileStatusPage(ctx context.Context, cr *v1beta1.Grafana) error {
+	log := logf.FromContext(ctx)
+
+	cl, err := client2.NewGeneratedGrafanaClient(ctx, r.client, cr)
+	if err != nil {
+		return fmt.Errorf("building grafana client: %w", err)
+	}
+
+	var createdID int64
+	if cr.Status.StatusPage != nil {
+		createdID = cr.Status.StatusPage.OrgID
+	}
+
+	orgID, err := getOrCreateOrg(cl, cr.Spec.StatusPage.OrgName, createdID)
+	if err != nil {
+		return err
+	}
+
+	if createdID != orgID {
+		cr.Status.StatusPage = &v1beta1.GrafanaStatusPageStatus{OrgID: orgID}
+	}
+
+	err = reconcileStatusPageMembers(cl, orgID)
+	if err != nil {
+		return err
+	}
+
+	uids, err := r.getStatusPageDashboardUIDs(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	orgClient, err := client2.NewGeneratedGrafanaClient(ctx, r.client, cr)
+	if err != nil {
+		return fmt.Errorf("building grafana client: %w", err)
+	}
+
+	orgClient = orgClient.WithOrgID(orgID)
+
+	datasources := map[string]bool{}
+
+	for _, uid := range uids {
+		source, copied, err := copyStatusPageDashboard(cl, orgClient, uid)
+		if err != nil {
+			return fmt.Errorf("copying dashboard %s to the status page: %w", uid, err)
+		}
+
+		if copied {
+			log.Info("copied dashboard to the status page", "uid", uid, "org", cr.Spec.StatusPage.OrgName)
+		}
+
+		collectDatasourceUIDs(source, datasources)
+	}
+
+	cr.Status.StatusPage.Datasources = slices.Sorted(maps.Keys(datasources))
+
+	err = deleteUnselectedStatusPageDashboards(orgClient, uids)
+	if err != nil {
+		return err
+	}
+
+	return deleteUnreferencedStatusPageDatasources(orgClient, cr.Status.StatusPage.Datasources)
+}
+
+// getOrCreateOrg returns the id of the organization named name, creating it when missing. An existing organization is
+// only returned when its id is createdID, the id of the organization the operator created before
+func getOrCreateOrg(cl *genapi.GrafanaHTTPAPI, name string, createdID int64) (int64, error) {
+	resp, err := cl.Orgs.SearchOrgs(orgs.NewSearchOrgsParams().WithName(&name))
+	if err != nil {
+		return 0, fmt.Errorf("searching organization %s: %w", name, err)
+	}
+
+	for _, org := range resp.GetPayload() {
+		if org.Name != name {
+			continue
+		}
+
+		if org.ID != createdID {
+			return 0, fmt.Errorf("%w: organization %s already exists, its members and dashboards would be replaced", ErrStatusPageOrgNotOwned, name)
+		}
+
+		return org.ID, nil
+	}
+
+	created, err := cl.Orgs.CreateOrg(&models.CreateOrgCommand{Name: name})
+	if err != nil {
+		return 0, fmt.Errorf("creating organization %s: %w", name, err)
+	}
+
+	if created.Payload == nil || created.Payload.OrgID == nil {
+		return 0, fmt.Errorf("creating organization %s: no id returned", name)
+	}
+
+	return *created.Payload.OrgID, nil
+}
+
+// reconcileStatusPageMembers makes the operator user the only member of the organization,
+// anonymous users are signed in without being members
+func reconcileStatusPageMembers(cl *genapi.GrafanaHTTPAPI, orgID int64) error {
+	user, err := cl.SignedInUser.GetSignedInUser()
+	if err != nil {
+		return fmt.Errorf("fetching operator user: %w", err)
+	}
+
+	members, err := cl.Orgs.GetOrgUsers(orgID)
+	if err != nil {
+		return fmt.Errorf("fetching members of the status page organization: %w", err)
+	}
+
+	isMember := false
+
+	for _, member := range members.GetPayload() {
+		if member.UserID == user.Payload.ID {
+			isMember = true
+			continue
+		}
+
+		_, err := cl.Orgs.RemoveOrgUser(member.UserID, orgID) //nolint:errcheck
+		if err != nil {
+			return fmt.Errorf("removing %s from the status page organization: %w", member.Login, err)
+		}
+	}
+
+	if isMember {
+		return nil
+	}
+
+	_, err = cl.Orgs.AddOrgUser(orgID, &models.AddOrgUserCommand{ //nolint:errcheck
+		LoginOrEmail: user.Payload.Login,
+		Role:         statusPageOrgRole,
+	})
+	if err != nil {
+		return fmt.Errorf("adding operator user to the status page organization: %w", err)
+	}
+
+	return nil
+}
+
+// getStatusPageDashboardUIDs returns the uids of the dashboards matching the selector of spec.statusPage
+// which are applied to the instance
+func (r *CompleteReconciler) getStatusPageDashboardUIDs(ctx context.Context, cr *v1beta1.Grafana) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.StatusPage.DashboardSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parsing status page dashboard selector: %w", err)
+	}
+
+	list := &v1beta1.GrafanaDashboardList{}
+
+	err = r.client.List(ctx, list, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("listing status page dashboards: %w", err)
+	}
+
+	return selectStatusPageDashboards(list.Items, cr.Status.Dashboards), nil
+}
+
+// selectStatusPageDashboards returns the sorted uids of the dashboards present in applied
+func selectStatusPageDashboards(items []v1beta1.GrafanaDashboard, applied v1beta1.NamespacedResourceList) []string {
+	uids := make([]string, 0, len(items))
+
+	for _, dashboard := range items {
+		found, uid := applied.Find(dashboard.Namespace, dashboard.Name)
+		if found && !slices.Contains(uids, *uid) {
+			uids = append(uids, *uid)
+		}
+	}
+
+	slices.Sort(uids)
+
+	return uids
+}
+
+// copyStatusPageDashboard saves the dashboard uid of the operator organization into the organization of orgClient,
+// unless the copy is current, and returns the source model. Dashboards not yet created by the dashboard controller
+// are skipped
+func copyStatusPageDashboard(cl, orgClient *genapi.GrafanaHTTPAPI, uid string) (map[string]any, bool, error) {
+	source, err := getDashboardModel(cl, uid)
+	if err != nil || source == nil {
+		return nil, false, err
+	}
+
+	existing, err := getDashboardModel(orgClient, uid)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if isStatusPageCopyCurrent(source, existing) {
+		return source, false, nil
+	}
+
+	// The id is assigned by Grafana per organization
+	dashboard := maps.Clone(source)
+	delete(dashboard, "id")
+
+	resp, err := orgClient.Dashboards.PostDashboard(&models.SaveDashboardCommand{
+		Dashboard: dashboard,
+		Overwrite: true,
+		Message:   "copied by grafana-operator",
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	payload := resp.GetPayload()
+	if payload.Status == nil || *payload.Status != "success" {
+		return nil, false, fmt.Errorf("saving dashboard copy, status was %v", payload.Status)
+	}
+
+	return source, true, nil
+}
+
+// collectDatasourceUIDs adds the uids of the datasources referenced by the panels, targets and variables of the model.
+// Dashboard variables are skipped, builtin datasources are listed but never match a GrafanaDatasource
+func collectDatasourceUIDs(node any, uids map[string]bool) {
+	switch v := node.(type) {
+	case map[string]any:
+		if ds, ok := v["datasource"].(map[string]any); ok {
+			if uid, ok := ds["uid"].(string); ok && uid != "" && !strings.HasPrefix(uid, "$") {
+				uids[uid] = true
+			}
+		}
+
+		for _, child := range v {
+			collectDatasourceUIDs(child, uids)
+		}
+	case []any:
+		for _, child := range v {
+			collectDatasourceUIDs(child, uids)
+		}
+	}
+}
+
+// deleteUnreferencedStatusPageDatasources deletes the datasources of the organization of orgClient missing in uids,
+// anonymous users of the status page may query all datasources of the organization
+func deleteUnreferencedStatusPageDatasources(orgClient *genapi.GrafanaHTTPAPI, uids []string) error {
+	resp, err := orgClient.Datasources.GetDataSources()
+	if err != nil {
+		return fmt.Errorf("listing status page datasources: %w", err)
+	}
+
+	for _, ds := range resp.GetPayload() {
+		if slices.Contains(uids, ds.UID) {
+			continue
+		}
+
+		_, err := orgClient.Datasources.DeleteDataSourceByUID(ds.UID) //nolint:errcheck
+		if err != nil {
+			var notFound *datasources.DeleteDataSourceByUIDNotFound
+			if !errors.As(err, &notFound) {
+				return fmt.Errorf("deleting datasource %s from the status page: %w", ds.UID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getDashboardModel returns the model of dashboard uid, nil if it doesn't exist
+func getDashboardModel(cl *genapi.GrafanaHTTPAPI, uid string) (map[string]any, error) {
+	resp, err := cl.Dashboards.GetDashboardByUID(uid)
+	if err != nil {
+		var notFound *dashboards.GetDashboardByUIDNotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("fetching dashboard: %w", err)
+	}
+
+	model, ok := resp.GetPayload().Dashboard.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("dashboard %s has an unexpected model", uid)
+	}
+
+	return model, nil
+}
+
+// isStatusPageCopyCurrent reports whether the copy matches the source, ignoring the id and version maintained by Grafana
+func isStatusPageCopyCurrent(source, copied map[string]any) bool {
+	if copied == nil {
+		return false
+	}
+
+	strip := func(model map[string]any) map[string]any {
+		stripped := maps.Clone(model)
+		delete(stripped, "id")
+		delete(stripped, "version")
+
+		return stripped
+	}
+
+	return reflect.DeepEqual(strip(source), strip(copied))
+}
+
+// deleteUnselectedStatusPageDashboards deletes all dashboards of the organization of orgClient missing in uids
+func deleteUnselectedStatusPageDashboards(orgClient *genapi.GrafanaHTTPAPI, uids []string) error {
+	tvar := "dash-db"
+
+	page := int64(1)
+
+	limit := int64(1000)
+
+	var unselected []string
+
+	for {
+		resp, err := orgClient.Search.Search(search.NewSearchParams().WithType(&tvar).WithLimit(&limit).WithPage(&page))
+		if err != nil {
+			return fmt.Errorf("searching status page dashboards: %w", err)
+		}
+
+		hits := resp.GetPayload()
+
+		for _, hit := range hits {
+			if !slices.Contains(uids, hit.UID) {
+				unselected = append(unselected, hit.UID)
+			}
+		}
+
+		if len(hits) < int(limit) {
+			break
+		}
+
+		page++
+	}
+
+	for _, uid := range unselected {
+		_, err := orgClient.Dashboards.DeleteDashboardByUID(uid) //nolint:errcheck
+		if err != nil {
+			var notFound *dashboards.DeleteDashboardByUIDNotFound
+			if !errors.As(err, &notFound) {
+				return fmt.Errorf("deleting dashboard %s from the status page: %w", uid, err)
+			}
+		}
+	}
+
+	return nil
+}
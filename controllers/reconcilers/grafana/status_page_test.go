@@ -0,0 +1,131 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectStatusPageDashboards(t *testing.T) {
+	dashboard := func(namespace, name string) v1beta1.GrafanaDashboard {
+		return v1beta1.GrafanaDashboard{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	applied := v1beta1.NamespacedResourceList{
+		"default/uptime/uptime-uid",
+		"default/internal/internal-uid",
+		"public/api/api-uid",
+	}
+
+	items := []v1beta1.GrafanaDashboard{
+		dashboard("public", "api"),
+		dashboard("default", "uptime"),
+		dashboard("default", "pending"),
+	}
+
+	assert.Equal(t, []string{"api-uid", "uptime-uid"}, selectStatusPageDashboards(items, applied))
+	assert.Empty(t, selectStatusPageDashboards(nil, applied))
+}
+
+func TestIsStatusPageCopyCurrent(t *testing.T) {
+	source := map[string]any{"id": float64(3), "uid": "uptime", "title": "Uptime", "version": float64(7)}
+
+	tests := []struct {
+		name   string
+		copied map[string]any
+		want   bool
+	}{
+		{
+			name: "missing copy",
+		},
+		{
+			name:   "id and version differ",
+			copied: map[string]any{"id": float64(12), "uid": "uptime", "title": "Uptime", "version": float64(1)},
+			want:   true,
+		},
+		{
+			name:   "model differs",
+			copied: map[string]any{"id": float64(12), "uid": "uptime", "title": "Old uptime", "version": float64(1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isStatusPageCopyCurrent(source, tt.copied))
+			assert.Equal(t, float64(3), source["id"], "source must not be modified")
+		})
+	}
+}
+
+func TestCollectDatasourceUIDs(t *testing.T) {
+	model := map[string]any{
+		"panels": []any{
+			map[string]any{"datasource": map[string]any{"uid": "prometheus"}},
+			map[string]any{
+				"datasource": map[string]any{"uid": "${ds}"},
+				"targets":    []any{map[string]any{"datasource": map[string]any{"uid": "loki"}}},
+			},
+		},
+		"templating": map[string]any{"list": []any{map[string]any{"datasource": map[string]any{"type": "prometheus"}}}},
+	}
+
+	uids := map[string]bool{}
+	collectDatasourceUIDs(model, uids)
+
+	assert.Equal(t, map[string]bool{"prometheus": true, "loki": true}, uids)
+}
+
+func TestGetOrCreateOrg(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/orgs":
+			w.Write([]byte(`[{"id": 4, "name": "Status"}]`)) //nolint:errcheck
+		case r.Method == http.MethodPost && r.URL.Path == "/api/orgs":
+			w.Write([]byte(`{"orgId": 5, "message": "Organization created"}`)) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	cl := genapi.NewHTTPClientWithConfig(nil, &genapi.TransportConfig{Host: u.Host, BasePath: "/api", Schemes: []string{u.Scheme}})
+
+	t.Run("organizations created before are managed", func(t *testing.T) {
+		id, err := getOrCreateOrg(cl, "Status", 4)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), id)
+	})
+
+	t.Run("existing organizations are refused", func(t *testing.T) {
+		_, err := getOrCreateOrg(cl, "Status", 0)
+		require.ErrorIs(t, err, ErrStatusPageOrgNotOwned)
+	})
+
+	t.Run("missing organizations are created", func(t *testing.T) {
+		id, err := getOrCreateOrg(cl, "Public", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), id)
+	})
+}
+
+func TestStatusPageProvisions(t *testing.T) {
+	var unset *v1beta1.GrafanaStatusPageStatus
+	assert.False(t, unset.Provisions("prometheus"))
+
+	status := &v1beta1.GrafanaStatusPageStatus{OrgID: 4, Datasources: []string{"prometheus"}}
+	assert.True(t, status.Provisions("prometheus"))
+	assert.False(t, status.Provisions("loki"))
+	assert.False(t, status.Provisions(""))
+}
@@ -66,6 +66,7 @@ import (
 	"github.com/grafana/grafana-operator/v5/api/v1beta1"
 	client2 "github.com/grafana/grafana-operator/v5/controllers/client"
 	model2 "github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
 )
 
 const (
@@ -123,7 +124,7 @@ func (r *GrafanaServiceAccountReconciler) Reconcile(ctx context.Context, req ctr
 	}
 
 	// 3. From here on, we're handling normal reconciliation (not deletion)
-	defer UpdateStatus(ctx, r.Client, cr)
+	defer UpdateStatus(ctx, r.Client, cr, cr.DeepCopy())
 
 	// Check if reconciliation is suspended
 	if cr.Spec.Suspend {
@@ -158,9 +159,9 @@ func (r *GrafanaServiceAccountReconciler) Reconcile(ctx context.Context, req ctr
 	err = r.reconcileWithInstance(ctx, cr, grafana)
 
 	// 6. Update the resource status with current state and conditions
-	applyErrors := map[string]string{}
+	applyErrors := map[string]error{}
 	if err != nil {
-		applyErrors[grafana.Name] = err.Error()
+		applyErrors[grafana.Name] = err
 	}
 
 	condition := buildSynchronizedCondition(
@@ -177,7 +178,7 @@ func (r *GrafanaServiceAccountReconciler) Reconcile(ctx context.Context, req ctr
 	}
 
 	// 7. Schedule periodic reconciliation based on ResyncPeriod
-	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod)}, nil
+	return ctrl.Result{RequeueAfter: r.Cfg.requeueAfter(cr.Spec.ResyncPeriod, nil)}, nil
 }
 
 // finalize handles the cleanup logic when a GrafanaServiceAccount resource is being deleted.
@@ -263,6 +264,11 @@ func (r *GrafanaServiceAccountReconciler) lookupGrafana(
 		return nil, err
 	}
 
+	err = provision.ResolveClass(ctx, r.Client, &grafana)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if Grafana instance is ready
 	if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
 		return nil, fmt.Errorf("Grafana instance %q is not ready (stage: %q, status: %q)", cr.Spec.InstanceName, grafana.Status.Stage, grafana.Status.StageStatus) // nolint:staticcheck
@@ -808,6 +814,7 @@ func buildTokenSecret(
 	}
 
 	model2.SetInheritedLabels(secret, cr.Labels)
+	model2.SetOwnershipAnnotations(secret, cr.Annotations)
 
 	if scheme != nil {
 		err := controllerutil.SetControllerReference(cr, secret, scheme)
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	conditionPendingWave       = "PendingWave"
+	conditionReasonWavePending = "LowerWavesPending"
+
+	// waveRequeueDelay is how often resources held back on an instance check its lower waves again
+	waveRequeueDelay = 15 * time.Second
+)
+
+// waveKinds are the kinds whose applied resources are tracked in the status of instances or, for notification policies,
+// in an annotation. Only they can hold back later waves
+var waveKinds = []struct {
+	obj     client.Object
+	newList func() client.ObjectList
+}{
+	{&v1beta1.GrafanaAlertRuleGroup{}, func() client.ObjectList { return &v1beta1.GrafanaAlertRuleGroupList{} }},
+	{&v1beta1.GrafanaContactPoint{}, func() client.ObjectList { return &v1beta1.GrafanaContactPointList{} }},
+	{&v1beta1.GrafanaDashboard{}, func() client.ObjectList { return &v1beta1.GrafanaDashboardList{} }},
+	{&v1beta1.GrafanaDatasource{}, func() client.ObjectList { return &v1beta1.GrafanaDatasourceList{} }},
+	{&v1beta1.GrafanaFolder{}, func() client.ObjectList { return &v1beta1.GrafanaFolderList{} }},
+	{&v1beta1.GrafanaLibraryPanel{}, func() client.ObjectList { return &v1beta1.GrafanaLibraryPanelList{} }},
+	{&v1beta1.GrafanaMuteTiming{}, func() client.ObjectList { return &v1beta1.GrafanaMuteTimingList{} }},
+	{&v1beta1.GrafanaNotificationPolicy{}, func() client.ObjectList { return &v1beta1.GrafanaNotificationPolicyList{} }},
+	{&v1beta1.GrafanaNotificationTemplate{}, func() client.ObjectList { return &v1beta1.GrafanaNotificationTemplateList{} }},
+}
+
+// waveIndexKey indexes the resources able to hold back later waves by their instance scope, see indexInstanceScope.
+// Suspended resources never hold back later waves and are left out
+const waveIndexKey = ".spec.waveScope"
+
+func indexWave(o client.Object) []string {
+	cr, ok := o.(v1beta1.CommonSpecResource)
+	if !ok {
+		panic(fmt.Sprintf("Expected a CommonSpecResource, got %T", o))
+	}
+
+	if cr.CommonSpec().Suspend {
+		return nil
+	}
+
+	return indexInstanceScope(o)
+}
+
+// SetupWaveIndex indexes the resources of all kinds by their wave, holdForLowerWaves looks up the resources of lower
+// waves through it
+func SetupWaveIndex(ctx context.Context, mgr ctrl.Manager) error {
+	for _, kind := range waveKinds {
+		if err := mgr.GetCache().IndexField(ctx, kind.obj, waveIndexKey, indexWave); err != nil {
+			return fmt.Errorf("failed setting wave index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// holdForLowerWaves returns the instances cr can be applied to: the instances it is applied to already and the instances
+// all resources of lower waves targeting them are applied to. pending maps the other instances to the resources they wait for
+func holdForLowerWaves(ctx context.Context, cl client.Client, cr v1beta1.CommonSpecResource, instances []v1beta1.Grafana) ([]v1beta1.Grafana, map[string][]string, error) {
+	pending := make(map[string][]string)
+
+	// Nothing precedes the first wave, skip looking up resources
+	if cr.CommonSpec().Wave == 0 {
+		return instances, pending, nil
+	}
+
+	// Only the instances cr isn't applied to yet wait, the resources of lower waves are looked up in their namespaces
+	scopes := []string{"*"}
+
+	for _, instance := range instances {
+		if !isAppliedTo(&instance, cr) && !slices.Contains(scopes, instance.Namespace) {
+			scopes = append(scopes, instance.Namespace)
+		}
+	}
+
+	if len(scopes) == 1 {
+		return instances, pending, nil
+	}
+
+	type lowerResource struct {
+		v1beta1.CommonSpecResource
+		kind string
+	}
+
+	var lower []lowerResource
+
+	// Every kind is listed once per scope, the waves are compared in memory so the number of lookups doesn't grow with the wave
+	for _, kind := range waveKinds {
+		for _, scope := range scopes {
+			list := kind.newList()
+			if err := cl.List(ctx, list, client.MatchingFields{waveIndexKey: scope}); err != nil {
+				return nil, nil, fmt.Errorf("listing resources of lower waves: %w", err)
+			}
+
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// Items of typed lists carry no kind
+			name := strings.TrimSuffix(reflect.TypeOf(list).Elem().Name(), "List")
+
+			for _, item := range items {
+				resource, ok := item.(v1beta1.CommonSpecResource)
+				if ok && resource.GetDeletionTimestamp() == nil && resource.CommonSpec().Wave < cr.CommonSpec().Wave {
+					lower = append(lower, lowerResource{resource, name})
+				}
+			}
+		}
+	}
+
+	ready := make([]v1beta1.Grafana, 0, len(instances))
+
+	for _, instance := range instances {
+		if isAppliedTo(&instance, cr) {
+			ready = append(ready, instance)
+			continue
+		}
+
+		var waiting []string
+
+		for _, resource := range lower {
+			if targets(resource, &instance) && !isAppliedTo(&instance, resource.CommonSpecResource) {
+				waiting = append(waiting, fmt.Sprintf("%s %s/%s (wave %d)", resource.kind, resource.GetNamespace(), resource.GetName(), resource.CommonSpec().Wave))
+			}
+		}
+
+		if len(waiting) > 0 {
+			slices.Sort(waiting)
+			pending[fmt.Sprintf("%s/%s", instance.Namespace, instance.Name)] = waiting
+
+			continue
+		}
+
+		ready = append(ready, instance)
+	}
+
+	return ready, pending, nil
+}
+
+// isAppliedTo reports whether the resource is listed in the status of the instance.
+// Notification policies are tracked through an annotation of the instance instead
+func isAppliedTo(instance *v1beta1.Grafana, resource client.Object) bool {
+	if policy, ok := resource.(*v1beta1.GrafanaNotificationPolicy); ok {
+		return instance.Annotations[annotationAppliedNotificationPolicy] == policy.NamespacedResource()
+	}
+
+	list, _, err := instance.Status.StatusList(resource)
+	if err != nil {
+		return false
+	}
+
+	found, _ := list.Find(resource.GetNamespace(), resource.GetName())
+
+	return found
+}
+
+// targets reports whether the instance is in the scope of the resource and matches its instanceSelector
+func targets(resource v1beta1.CommonSpecResource, instance *v1beta1.Grafana) bool {
+	if !resource.AllowCrossNamespace() && resource.MatchNamespace() != instance.Namespace {
+		return false
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(resource.MatchLabels())
+	if err != nil || resource.MatchLabels() == nil {
+		return false
+	}
+
+	return selector.Matches(labels.Set(instance.Labels))
+}
+
+// setPendingWave lists the instances the resource is held back from until the resources of lower waves are applied
+func setPendingWave(conditions *[]metav1.Condition, generation int64, pending map[string][]string) {
+	if len(pending) == 0 {
+		meta.RemoveStatusCondition(conditions, conditionPendingWave)
+		return
+	}
+
+	var sb strings.Builder
+	for _, instance := range slices.Sorted(maps.Keys(pending)) {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", instance, strings.Join(pending[instance], ", ")))
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionPendingWave,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Time{
+			Time: time.Now(),
+		},
+		Reason:  conditionReasonWavePending,
+		Message: fmt.Sprintf("Applying to %d instances waits for resources of lower waves:%s", len(pending), sb.String()),
+	})
+}
+
+// requeueForPendingWave shortens the requeue delay while the resource is held back from instances
+func requeueForPendingWave(requeueAfter time.Duration, pending map[string][]string) time.Duration {
+	if len(pending) > 0 && (requeueAfter <= 0 || waveRequeueDelay < requeueAfter) {
+		return waveRequeueDelay
+	}
+
+	return requeueAfter
+}
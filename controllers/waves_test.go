@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestHoldForLowerWaves(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}}
+
+	datasource := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus"},
+		Spec: v1beta1.GrafanaDatasourceSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector},
+		},
+	}
+	folder := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "folder"},
+		Spec: v1beta1.GrafanaFolderSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector, Wave: 1},
+		},
+	}
+	suspended := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "suspended"},
+		Spec: v1beta1.GrafanaFolderSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector, Wave: 1, Suspend: true},
+		},
+	}
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dashboard"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector, Wave: 2},
+		},
+	}
+
+	policy := &v1beta1.GrafanaNotificationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec: v1beta1.GrafanaNotificationPolicySpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector, Wave: 1},
+		},
+	}
+	otherNamespace := &v1beta1.GrafanaFolder{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "folder"},
+		Spec: v1beta1.GrafanaFolderSpec{
+			GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: selector, Wave: 1},
+		},
+	}
+
+	objects := []client.Object{datasource, folder, suspended, dashboard, policy, otherNamespace}
+	cl := withWaveIndex(fake.NewClientBuilder().WithScheme(s)).WithObjects(objects...).Build()
+
+	instance := func(name string, datasources, folders, dashboards v1beta1.NamespacedResourceList) v1beta1.Grafana {
+		return v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"dashboards": "grafana"}},
+			Status: v1beta1.GrafanaStatus{
+				Datasources: datasources,
+				Folders:     folders,
+				Dashboards:  dashboards,
+			},
+		}
+	}
+
+	fresh := instance("fresh", nil, nil, nil)
+	partial := instance("partial", v1beta1.NamespacedResourceList{"default/prometheus/prometheus"}, nil, nil)
+	prepared := instance("prepared", v1beta1.NamespacedResourceList{"default/prometheus/prometheus"}, v1beta1.NamespacedResourceList{"default/folder/folder"}, nil)
+	prepared.Annotations = map[string]string{annotationAppliedNotificationPolicy: policy.NamespacedResource()}
+	applied := instance("applied", nil, nil, v1beta1.NamespacedResourceList{"default/dashboard/dashboard"})
+
+	t.Run("first wave is never held back", func(t *testing.T) {
+		ready, pending, err := holdForLowerWaves(t.Context(), cl, datasource, []v1beta1.Grafana{fresh})
+		require.NoError(t, err)
+
+		assert.Len(t, ready, 1)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("waits for all lower waves", func(t *testing.T) {
+		ready, pending, err := holdForLowerWaves(t.Context(), cl, dashboard, []v1beta1.Grafana{fresh, partial, prepared, applied})
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(ready))
+		for _, instance := range ready {
+			names = append(names, instance.Name)
+		}
+
+		assert.Equal(t, []string{"prepared", "applied"}, names)
+		assert.Equal(t, map[string][]string{
+			"default/fresh": {
+				"GrafanaDatasource default/prometheus (wave 0)",
+				"GrafanaFolder default/folder (wave 1)",
+				"GrafanaNotificationPolicy default/policy (wave 1)",
+			},
+			"default/partial": {
+				"GrafanaFolder default/folder (wave 1)",
+				"GrafanaNotificationPolicy default/policy (wave 1)",
+			},
+		}, pending)
+	})
+
+	t.Run("no lookups once applied to all instances", func(t *testing.T) {
+		// without the index any lookup fails
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+
+		ready, pending, err := holdForLowerWaves(t.Context(), cl, dashboard, []v1beta1.Grafana{applied})
+		require.NoError(t, err)
+
+		assert.Len(t, ready, 1)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("ignores instances not targeted by lower waves", func(t *testing.T) {
+		other := fresh
+		other.Labels = map[string]string{"dashboards": "other"}
+
+		ready, pending, err := holdForLowerWaves(t.Context(), cl, dashboard, []v1beta1.Grafana{other})
+		require.NoError(t, err)
+
+		assert.Len(t, ready, 1)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("lists every kind once regardless of the wave", func(t *testing.T) {
+		lists := 0
+		cl := interceptor.NewClient(cl, interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				lists++
+				return c.List(ctx, list, opts...)
+			},
+		})
+
+		last := dashboard.DeepCopy()
+		last.Spec.Wave = math.MaxInt32
+
+		_, pending, err := holdForLowerWaves(t.Context(), cl, last, []v1beta1.Grafana{fresh})
+		require.NoError(t, err)
+
+		// the scope of the namespace and the cross namespace scope
+		assert.Equal(t, 2*len(waveKinds), lists)
+		assert.Contains(t, pending["default/fresh"], "GrafanaDashboard default/dashboard (wave 2)")
+	})
+}
+
+func TestSetPendingWave(t *testing.T) {
+	conditions := []metav1.Condition{}
+
+	setPendingWave(&conditions, 1, map[string][]string{
+		"default/b": {"GrafanaFolder default/folder (wave 1)"},
+		"default/a": {"GrafanaDatasource default/prometheus (wave 0)", "GrafanaFolder default/folder (wave 1)"},
+	})
+
+	condition := meta.FindStatusCondition(conditions, conditionPendingWave)
+	require.NotNil(t, condition)
+	assert.Equal(t, conditionReasonWavePending, condition.Reason)
+	assert.Equal(t, "Applying to 2 instances waits for resources of lower waves:\n"+
+		"- default/a: GrafanaDatasource default/prometheus (wave 0), GrafanaFolder default/folder (wave 1)\n"+
+		"- default/b: GrafanaFolder default/folder (wave 1)", condition.Message)
+
+	setPendingWave(&conditions, 1, map[string][]string{})
+	assert.Nil(t, meta.FindStatusCondition(conditions, conditionPendingWave))
+}
+
+func TestRequeueForPendingWave(t *testing.T) {
+	pending := map[string][]string{"default/grafana": {"GrafanaFolder default/folder (wave 1)"}}
+
+	assert.Equal(t, 10*time.Minute, requeueForPendingWave(10*time.Minute, nil))
+	assert.Equal(t, waveRequeueDelay, requeueForPendingWave(10*time.Minute, pending))
+	assert.Equal(t, waveRequeueDelay, requeueForPendingWave(0, pending))
+	assert.Equal(t, 5*time.Second, requeueForPendingWave(5*time.Second, pending))
+}
+
+// withWaveIndex registers the index SetupWaveIndex sets up on the cache of the manager
+func withWaveIndex(b *fake.ClientBuilder) *fake.ClientBuilder {
+	for _, kind := range waveKinds {
+		b = b.WithIndex(kind.obj, waveIndexKey, indexWave)
+	}
+
+	return b
+}
@@ -0,0 +1,41 @@
+// Command prometheusrules writes the PrometheusRule manifests shipped in config/prometheus and the helm chart
+// from the alert rules of the operator
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grafana/grafana-operator/v5/controllers/metrics"
+	"sigs.k8s.io/yaml"
+)
+
+const header = "# Code generated by make prometheus-rules from controllers/metrics/rules.go. DO NOT EDIT.\n"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	manifest, err := metrics.PrometheusRuleManifest("system", "alerts", map[string]string{
+		"app.kubernetes.io/name":       "grafana-operator",
+		"app.kubernetes.io/managed-by": "olm",
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile("config/prometheus/rules.yaml", append([]byte(header), manifest...), 0o644); err != nil { //nolint:gosec
+		return err
+	}
+
+	groups, err := yaml.Marshal(metrics.PrometheusRuleGroups())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile("deploy/helm/grafana-operator/files/prometheus-rules.yaml", append([]byte(header), groups...), 0o644) //nolint:gosec
+}
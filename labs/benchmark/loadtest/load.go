@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+const (
+	// loadLabel is set on every resource created by the load test
+	loadLabel = "grafana-operator.integreatly.org/loadtest"
+
+	apiKeySecret = "loadtest-api-key"
+	apiKeyKey    = "token"
+
+	// Conditions set by the operator once content is applied to all matching instances
+	conditionDashboardSynchronized = "DashboardSynchronized"
+	conditionFolderSynchronized    = "FolderSynchronized"
+)
+
+var (
+	loadResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "loadtest",
+		Name:      "resources",
+		Help:      "resources created by the load test",
+	}, []string{"kind"})
+
+	loadSyncDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "loadtest",
+		Name:      "sync_duration_seconds",
+		Help:      "time from the creation of the resources until all of them were synchronized",
+	}, []string{"kind"})
+
+	loadReconciles = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "loadtest",
+		Name:      "reconciles_per_second",
+		Help:      "reconciles per second of each controller until the resources were synchronized",
+	}, []string{"controller"})
+
+	loadPeakHeap = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "loadtest",
+		Name:      "peak_heap_inuse_bytes",
+		Help:      "highest heap in use while the resources were synchronized",
+	})
+
+	loadRetainedHeap = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "loadtest",
+		Name:      "retained_heap_inuse_bytes",
+		Help:      "heap in use after a garbage collection once the resources were synchronized",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(loadResources, loadSyncDuration, loadReconciles, loadPeakHeap, loadRetainedHeap)
+}
+
+// load describes the resources created by a load test
+type load struct {
+	namespace string
+	instances int
+	folders   int
+	// dashboards are spread over the folders and applied to every instance
+	dashboards int
+	// panels of each dashboard, larger models cost more memory in the cache and the status
+	panels int
+}
+
+func (l *load) labels() map[string]string {
+	return map[string]string{loadLabel: "true"}
+}
+
+func (l *load) objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: l.namespace, Name: name, Labels: l.labels()}
+}
+
+func (l *load) apiKey() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: l.objectMeta(apiKeySecret),
+		StringData: map[string]string{apiKeyKey: "loadtest"},
+	}
+}
+
+// grafanas returns an external instance for each of the urls
+func (l *load) grafanas(urls []string) []*v1beta1.Grafana {
+	grafanas := make([]*v1beta1.Grafana, 0, len(urls))
+
+	for i, url := range urls {
+		grafanas = append(grafanas, &v1beta1.Grafana{
+			ObjectMeta: l.objectMeta(fmt.Sprintf("grafana-%d", i)),
+			Spec: v1beta1.GrafanaSpec{
+				External: &v1beta1.External{
+					URL: url,
+					APIKey: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: apiKeySecret},
+						Key:                  apiKeyKey,
+					},
+				},
+			},
+		})
+	}
+
+	return grafanas
+}
+
+func (l *load) instanceSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: l.labels()}
+}
+
+func (l *load) grafanaFolders() []*v1beta1.GrafanaFolder {
+	folders := make([]*v1beta1.GrafanaFolder, 0, l.folders)
+
+	for i := range l.folders {
+		folders = append(folders, &v1beta1.GrafanaFolder{
+			ObjectMeta: l.objectMeta(fmt.Sprintf("folder-%d", i)),
+			Spec: v1beta1.GrafanaFolderSpec{
+				GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: l.instanceSelector()},
+				Title:             fmt.Sprintf("Load test %d", i),
+			},
+		})
+	}
+
+	return folders
+}
+
+func (l *load) grafanaDashboards() []*v1beta1.GrafanaDashboard {
+	dashboards := make([]*v1beta1.GrafanaDashboard, 0, l.dashboards)
+
+	for i := range l.dashboards {
+		dashboard := &v1beta1.GrafanaDashboard{
+			ObjectMeta: l.objectMeta(fmt.Sprintf("dashboard-%d", i)),
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCommonSpec: v1beta1.GrafanaCommonSpec{InstanceSelector: l.instanceSelector()},
+				GrafanaContentSpec: v1beta1.GrafanaContentSpec{
+					JSON: l.dashboardModel(i),
+				},
+			},
+		}
+
+		if l.folders > 0 {
+			dashboard.Spec.FolderRef = fmt.Sprintf("folder-%d", i%l.folders)
+		}
+
+		dashboards = append(dashboards, dashboard)
+	}
+
+	return dashboards
+}
+
+func (l *load) dashboardModel(i int) string {
+	panels := ""
+	for p := range l.panels {
+		if p > 0 {
+			panels += ","
+		}
+
+		panels += fmt.Sprintf(`{"id":%d,"type":"timeseries","title":"Panel %d","gridPos":{"h":8,"w":12,"x":%d,"y":%d},"targets":[{"expr":"up{job=\"loadtest-%d\"}"}]}`, p+1, p, (p%2)*12, (p/2)*8, p)
+	}
+
+	return fmt.Sprintf(`{"uid":"loadtest-%d","title":"Load test %d","panels":[%s]}`, i, i, panels)
+}
+
+// synced returns the number of resources of the list reporting the condition as true
+func synced[T any](items []T, conditions func(*T) []metav1.Condition, condition string) int {
+	n := 0
+
+	for i := range items {
+		if meta.IsStatusConditionTrue(conditions(&items[i]), condition) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// countSynced returns the number of resources of the kind the operator finished reconciling
+func (l *load) countSynced(ctx context.Context, cl client.Client, kind string) (int, error) {
+	opts := []client.ListOption{client.InNamespace(l.namespace), client.MatchingLabels(l.labels())}
+
+	switch kind {
+	case "Grafana":
+		var list v1beta1.GrafanaList
+		if err := cl.List(ctx, &list, opts...); err != nil {
+			return 0, err
+		}
+
+		n := 0
+
+		for _, grafana := range list.Items {
+			if grafana.Status.Stage == v1beta1.OperatorStageComplete && grafana.Status.StageStatus == v1beta1.OperatorStageResultSuccess {
+				n++
+			}
+		}
+
+		return n, nil
+	case "GrafanaFolder":
+		var list v1beta1.GrafanaFolderList
+		if err := cl.List(ctx, &list, opts...); err != nil {
+			return 0, err
+		}
+
+		return synced(list.Items, func(f *v1beta1.GrafanaFolder) []metav1.Condition { return f.Status.Conditions }, conditionFolderSynchronized), nil
+	case "GrafanaDashboard":
+		var list v1beta1.GrafanaDashboardList
+		if err := cl.List(ctx, &list, opts...); err != nil {
+			return 0, err
+		}
+
+		return synced(list.Items, func(d *v1beta1.GrafanaDashboard) []metav1.Condition { return d.Status.Conditions }, conditionDashboardSynchronized), nil
+	default:
+		return 0, fmt.Errorf("unknown kind %s", kind)
+	}
+}
+
+// waitSynced blocks until the expected number of resources of the kind are synchronized
+func (l *load) waitSynced(ctx context.Context, cl client.Client, kind string, expected int, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := l.countSynced(ctx, cl, kind)
+		if err != nil {
+			return err
+		}
+
+		if n >= expected {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d of %d %s resources synchronized: %w", n, expected, kind, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// heapSampler records the highest heap in use while running
+type heapSampler struct {
+	mu   sync.Mutex
+	peak uint64
+}
+
+func (h *heapSampler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.sample()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *heapSampler) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.peak = max(h.peak, stats.HeapInuse)
+}
+
+func (h *heapSampler) peakHeap() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.peak
+}
+
+// retainedHeap returns the heap in use once garbage is collected, i.e. the steady state memory of the operator
+func retainedHeap() uint64 {
+	runtime.GC()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapInuse
+}
+
+// reconcileCounts returns controller_runtime_reconcile_total by controller
+func reconcileCounts() (map[string]float64, error) {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]float64)
+
+	for _, family := range families {
+		if family.GetName() != "controller_runtime_reconcile_total" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "controller" {
+					counts[label.GetValue()] += m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return counts, nil
+}
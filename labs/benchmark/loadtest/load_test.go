@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+)
+
+func TestLoadResources(t *testing.T) {
+	l := &load{namespace: "loadtest", instances: 2, folders: 3, dashboards: 7, panels: 4}
+
+	grafanas := l.grafanas([]string{"http://127.0.0.1:3000", "http://127.0.0.1:3001"})
+	require.Len(t, grafanas, 2)
+	assert.Equal(t, "http://127.0.0.1:3001", grafanas[1].Spec.External.URL)
+	assert.Equal(t, apiKeySecret, grafanas[1].Spec.External.APIKey.Name)
+
+	assert.Len(t, l.grafanaFolders(), 3)
+
+	dashboards := l.grafanaDashboards()
+	require.Len(t, dashboards, 7)
+	assert.Equal(t, "folder-0", dashboards[6].Spec.FolderRef)
+
+	var model struct {
+		UID    string `json:"uid"`
+		Panels []any  `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(dashboards[6].Spec.JSON), &model))
+	assert.Equal(t, "loadtest-6", model.UID)
+	assert.Len(t, model.Panels, 4)
+
+	// Dashboards stay in the general folder without folders
+	l.folders = 0
+	assert.Empty(t, l.grafanaDashboards()[0].Spec.FolderRef)
+}
+
+func TestCountSynced(t *testing.T) {
+	l := &load{namespace: "loadtest", dashboards: 3}
+
+	dashboards := l.grafanaDashboards()
+	dashboards[0].Status.Conditions = []metav1.Condition{{Type: conditionDashboardSynchronized, Status: metav1.ConditionTrue}}
+	dashboards[1].Status.Conditions = []metav1.Condition{{Type: conditionDashboardSynchronized, Status: metav1.ConditionFalse}}
+
+	grafanas := l.grafanas([]string{"http://127.0.0.1:3000", "http://127.0.0.1:3001"})
+	grafanas[0].Status.Stage = v1beta1.OperatorStageComplete
+	grafanas[0].Status.StageStatus = v1beta1.OperatorStageResultSuccess
+	grafanas[1].Status.Stage = v1beta1.OperatorStageComplete
+	grafanas[1].Status.StageStatus = v1beta1.OperatorStageResultFailed
+
+	// Resources of other load tests are ignored
+	other := &load{namespace: "other", dashboards: 1}
+	otherDashboard := other.grafanaDashboards()[0]
+	otherDashboard.Status.Conditions = dashboards[0].Status.Conditions
+
+	objs := append(objects(dashboards), objects(grafanas)...)
+	objs = append(objs, otherDashboard)
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	for kind, expected := range map[string]int{"GrafanaDashboard": 1, "Grafana": 1, "GrafanaFolder": 0} {
+		n, err := l.countSynced(t.Context(), cl, kind)
+		require.NoError(t, err)
+		assert.Equal(t, expected, n, kind)
+	}
+
+	_, err := l.countSynced(t.Context(), cl, "GrafanaDatasource")
+	require.Error(t, err)
+}
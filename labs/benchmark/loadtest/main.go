@@ -0,0 +1,344 @@
+// Command loadtest measures the reconcile throughput and memory of the operator's controllers.
+//
+// It runs the Grafana, GrafanaFolder and GrafanaDashboard controllers in process, starts in-memory Grafana
+// instances, points external Grafana resources at them and creates folders and dashboards applied to all of them.
+// The time until every resource is synchronized, the reconcile rate of each controller and the heap of the process
+// are logged and exported as grafana_operator_loadtest_* metrics next to the usual operator metrics.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/controllers"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+)
+
+const (
+	pollInterval = time.Second
+	heapInterval = 500 * time.Millisecond
+)
+
+var (
+	scheme = runtime.NewScheme()
+	log    = ctrl.Log.WithName("loadtest")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
+}
+
+type options struct {
+	load
+
+	metricsAddr             string
+	maxConcurrentReconciles int
+	resyncPeriod            time.Duration
+	timeout                 time.Duration
+	useEnvtest              bool
+	crdDir                  string
+	keepRunning             bool
+	keepResources           bool
+}
+
+func main() {
+	var opts options
+
+	flag.IntVar(&opts.instances, "instances", 10, "Number of in-memory Grafana instances, each one is targeted by an external Grafana resource.")
+	flag.IntVar(&opts.folders, "folders", 10, "Number of GrafanaFolders applied to every instance.")
+	flag.IntVar(&opts.dashboards, "dashboards", 100, "Number of GrafanaDashboards applied to every instance, spread over the folders.")
+	flag.IntVar(&opts.panels, "panels", 10, "Number of panels of each dashboard.")
+	flag.StringVar(&opts.namespace, "namespace", "grafana-operator-loadtest", "Namespace the resources are created in, it is created if missing.")
+	flag.StringVar(&opts.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.IntVar(&opts.maxConcurrentReconciles, "max-concurrent-reconciles", 1, "Maximum number of concurrent reconciles of each controller.")
+	flag.DurationVar(&opts.resyncPeriod, "default-resync-period", controllers.DefaultReSyncPeriod, "Controls the default .spec.resyncPeriod when undefined on CRs.")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Minute, "How long to wait for the resources to be synchronized.")
+	flag.BoolVar(&opts.useEnvtest, "envtest", false, "Run against a local kube-apiserver started with envtest, KUBEBUILDER_ASSETS must point at its binaries. The current kubeconfig is used otherwise.")
+	flag.StringVar(&opts.crdDir, "crd-dir", filepath.Join("config", "crd", "bases"), "Directory of the CRDs installed with --envtest.")
+	flag.BoolVar(&opts.keepRunning, "keep-running", false, "Keep serving metrics once the load test finished, until interrupted.")
+	flag.BoolVar(&opts.keepResources, "keep-resources", false, "Skip deleting the created resources once the load test finished.")
+
+	zapOpts := zap.Options{}
+	zapOpts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
+
+	if err := run(ctrl.SetupSignalHandler(), &opts); err != nil {
+		log.Error(err, "load test failed")
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts *options) error {
+	restConfig, stop, err := restConfig(opts)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: opts.metricsAddr},
+		HealthProbeBindAddress: "0",
+		Controller: config.Controller{
+			MaxConcurrentReconciles: opts.maxConcurrentReconciles,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating manager: %w", err)
+	}
+
+	if err := setupControllers(ctx, mgr, opts); err != nil {
+		return err
+	}
+
+	mgrCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	mgrErr := make(chan error, 1)
+
+	go func() {
+		mgrErr <- mgr.Start(mgrCtx)
+	}()
+
+	if !mgr.GetCache().WaitForCacheSync(ctx) {
+		return errors.New("waiting for the cache to sync")
+	}
+
+	if err := runLoad(ctx, mgr.GetClient(), opts); err != nil {
+		return err
+	}
+
+	if opts.keepRunning {
+		log.Info("serving metrics until interrupted", "addr", opts.metricsAddr)
+		<-ctx.Done()
+	}
+
+	cancel()
+
+	return <-mgrErr
+}
+
+// restConfig returns the config of the current kubeconfig or of a new envtest environment
+func restConfig(opts *options) (*rest.Config, func(), error) {
+	if !opts.useEnvtest {
+		cfg, err := ctrl.GetConfig()
+		return cfg, func() {}, err
+	}
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{opts.crdDir},
+		ErrorIfCRDPathMissing: true,
+	}
+
+	cfg, err := env.Start()
+	if err != nil {
+		return nil, nil, fmt.Errorf("starting envtest: %w", err)
+	}
+
+	return cfg, func() {
+		if err := env.Stop(); err != nil {
+			log.Error(err, "stopping envtest")
+		}
+	}, nil
+}
+
+func setupControllers(ctx context.Context, mgr ctrl.Manager, opts *options) error {
+	cfg := &controllers.Config{
+		ResyncPeriod: opts.resyncPeriod,
+	}
+
+	if err := (&controllers.GrafanaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		return fmt.Errorf("creating Grafana controller: %w", err)
+	}
+
+	if err := (&controllers.GrafanaFolderReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Cfg:    cfg,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		return fmt.Errorf("creating GrafanaFolder controller: %w", err)
+	}
+
+	if err := (&controllers.GrafanaDashboardReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Cfg:      cfg,
+		Recorder: mgr.GetEventRecorderFor("GrafanaDashboard"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		return fmt.Errorf("creating GrafanaDashboard controller: %w", err)
+	}
+
+	return nil
+}
+
+func runLoad(ctx context.Context, cl client.Client, opts *options) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+
+	urls := make([]string, 0, opts.instances)
+	servers := make([]*grafanafake.Server, 0, opts.instances)
+
+	defer func() {
+		for _, srv := range servers {
+			srv.Close()
+		}
+	}()
+
+	for range opts.instances {
+		srv := grafanafake.NewServer()
+		servers = append(servers, srv)
+		urls = append(urls, srv.URL)
+	}
+
+	err := cl.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: opts.namespace}})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating namespace: %w", err)
+	}
+
+	if err := cl.Create(ctx, opts.apiKey()); err != nil {
+		return fmt.Errorf("creating api key: %w", err)
+	}
+
+	if !opts.keepResources {
+		defer cleanup(cl, opts) //nolint:contextcheck
+	}
+
+	heap := &heapSampler{}
+
+	heapCtx, stopHeap := context.WithCancel(ctx)
+	defer stopHeap()
+
+	go heap.run(heapCtx, heapInterval)
+
+	start := time.Now()
+
+	before, err := reconcileCounts()
+	if err != nil {
+		return err
+	}
+
+	// Instances are synchronized before content so that the content durations exclude their startup
+	phases := []struct {
+		kind    string
+		objects []client.Object
+	}{
+		{"Grafana", objects(opts.grafanas(urls))},
+		{"GrafanaFolder", objects(opts.grafanaFolders())},
+		{"GrafanaDashboard", objects(opts.grafanaDashboards())},
+	}
+
+	for _, phase := range phases {
+		phaseStart := time.Now()
+
+		for _, obj := range phase.objects {
+			if err := cl.Create(ctx, obj); err != nil {
+				return fmt.Errorf("creating %s %s: %w", phase.kind, obj.GetName(), err)
+			}
+		}
+
+		if err := opts.waitSynced(ctx, cl, phase.kind, len(phase.objects), pollInterval); err != nil {
+			return err
+		}
+
+		duration := time.Since(phaseStart)
+
+		loadResources.WithLabelValues(phase.kind).Set(float64(len(phase.objects)))
+		loadSyncDuration.WithLabelValues(phase.kind).Set(duration.Seconds())
+
+		log.Info("resources synchronized", "kind", phase.kind, "count", len(phase.objects), "duration", duration)
+	}
+
+	elapsed := time.Since(start)
+	stopHeap()
+
+	after, err := reconcileCounts()
+	if err != nil {
+		return err
+	}
+
+	for controller, count := range after {
+		rate := (count - before[controller]) / elapsed.Seconds()
+		loadReconciles.WithLabelValues(controller).Set(rate)
+
+		log.Info("reconcile throughput", "controller", controller, "reconciles", count-before[controller], "perSecond", rate)
+	}
+
+	loadPeakHeap.Set(float64(heap.peakHeap()))
+	loadRetainedHeap.Set(float64(retainedHeap()))
+
+	log.Info("load test finished",
+		"instances", opts.instances,
+		"folders", opts.folders,
+		"dashboards", opts.dashboards,
+		"duration", elapsed,
+		"peakHeapInuseBytes", heap.peakHeap(),
+	)
+
+	return nil
+}
+
+func objects[T client.Object](items []T) []client.Object {
+	objs := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		objs = append(objs, item)
+	}
+
+	return objs
+}
+
+// cleanup deletes the resources while the controllers still run to remove the finalizers of the content
+func cleanup(cl client.Client, opts *options) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	selector := []client.DeleteAllOfOption{client.InNamespace(opts.namespace), client.MatchingLabels(opts.labels())}
+
+	for _, obj := range []client.Object{&v1beta1.GrafanaDashboard{}, &v1beta1.GrafanaFolder{}, &v1beta1.Grafana{}, &corev1.Secret{}} {
+		if err := cl.DeleteAllOf(ctx, obj, selector...); err != nil {
+			log.Error(err, "deleting load test resources", "kind", fmt.Sprintf("%T", obj))
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var dashboards v1beta1.GrafanaDashboardList
+		if err := cl.List(ctx, &dashboards, client.InNamespace(opts.namespace), client.MatchingLabels(opts.labels())); err != nil || len(dashboards.Items) == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("dashboards are still being deleted", "remaining", len(dashboards.Items))
+			return
+		case <-ticker.C:
+		}
+	}
+}
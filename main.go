@@ -18,9 +18,12 @@ package main
 
 import (
 	"context"
+	"embed"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"math"
 	"os"
 	"strings"
 	"time"
@@ -33,23 +36,30 @@ import (
 
 	routev1 "github.com/openshift/api/route/v1"
 	v1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gwapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -59,9 +69,18 @@ import (
 
 	grafanav1beta1 "github.com/grafana/grafana-operator/v5/api/v1beta1"
 	"github.com/grafana/grafana-operator/v5/controllers"
+	"github.com/grafana/grafana-operator/v5/controllers/apiserver"
 	"github.com/grafana/grafana-operator/v5/controllers/autodetect"
+	grafanaclient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/grafana/grafana-operator/v5/controllers/content/fetchers"
+	"github.com/grafana/grafana-operator/v5/controllers/crdcheck"
+	"github.com/grafana/grafana-operator/v5/controllers/gitwebhook"
+	"github.com/grafana/grafana-operator/v5/controllers/logging"
+	operatormetrics "github.com/grafana/grafana-operator/v5/controllers/metrics"
 	"github.com/grafana/grafana-operator/v5/controllers/model"
+	"github.com/grafana/grafana-operator/v5/controllers/reconcilers/grafana"
 	"github.com/grafana/grafana-operator/v5/embeds"
+	"github.com/grafana/grafana-operator/v5/pkg/provision"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -88,8 +107,18 @@ const (
 	// wish to have services addressed using their FQDNs, you can specify the cluster domain explicitly, e.g., "cluster.local"
 	// for the default Kubernetes configuration.
 	clusterDomainEnvVar = "CLUSTER_DOMAIN"
+	// gitWebhookSecretEnvVar is the secret push events received by the Git webhook are verified with
+	gitWebhookSecretEnvVar = "GIT_WEBHOOK_SECRET"
+	// operatorNamespaceEnvVar is the namespace the operator runs in, set through the downward API. NetworkPolicies of
+	// instances admit the operator pods of this namespace only, of any namespace when undefined.
+	operatorNamespaceEnvVar = "OPERATOR_NAMESPACE"
 )
 
+// expectedCRDs are compared with the installed CRDs on startup
+//
+//go:embed config/crd/bases/*.yaml
+var expectedCRDs embed.FS
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup").WithValues("version", embeds.Version)
@@ -102,6 +131,8 @@ func init() {
 
 	utilruntime.Must(routev1.AddToScheme(scheme))
 	utilruntime.Must(gwapiv1.Install(scheme))
+	utilruntime.Must(gwapiv1alpha2.Install(scheme))
+	utilruntime.Must(gwapiv1beta1.Install(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -113,6 +144,30 @@ func main() { // nolint:gocyclo
 		pprofAddr               string
 		maxConcurrentReconciles int
 		resyncPeriod            time.Duration
+		resyncJitterPercent     int
+		ruleGroupInterval       time.Duration
+		ruleParallelism         int
+		apiServerAddr           string
+		apiServerCertDir        string
+		datasourceUsageInterval time.Duration
+		dashboardDedupWindow    time.Duration
+		faultLatency            time.Duration
+		faultErrorRate          float64
+		metricsInstanceLimit    int
+		tlsMinVersion           string
+		tlsCipherSuites         string
+		syncWindow              time.Duration
+		failOnStaleCRDs         bool
+		ambientStorageCreds     bool
+
+		grafanaComRevisionCheckInterval time.Duration
+		grafanaComRevisionWebhookURL    string
+		integrityCheckInterval          time.Duration
+		gitWebhookAddr                  string
+		enableDashboardWebhook          bool
+		prometheusRuleNamespace         string
+		prometheusRuleLabels            string
+		operatorPodLabels               string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -124,6 +179,29 @@ func main() { // nolint:gocyclo
 	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
 		"Maximum number of concurrent reconciles for dashboard, datasource, folder controllers.")
 	flag.DurationVar(&resyncPeriod, "default-resync-period", controllers.DefaultReSyncPeriod, "Controls the default .spec.resyncPeriod when undefined on CRs.")
+	flag.IntVar(&resyncJitterPercent, "resync-jitter-percent", 0, "Delays resyncs by a random duration of up to the given percentage of the resync period, when undefined on CRs.")
+	flag.DurationVar(&ruleGroupInterval, "default-alert-rule-group-interval", controllers.DefaultAlertRuleGroupInterval, "Controls the default .spec.interval when undefined on GrafanaAlertRuleGroups.")
+	flag.IntVar(&ruleParallelism, "alert-rule-parallelism", controllers.DefaultAlertRuleParallelism, "Number of alert rule requests sent to an instance at once when applying rules one by one.")
+	flag.DurationVar(&dashboardDedupWindow, "dashboard-apply-dedup-window", 0, "Skips applying a dashboard model to an instance when the identical model was applied within the window. 0 disables deduplication.")
+	flag.DurationVar(&datasourceUsageInterval, "datasource-usage-interval", 0, "How often dashboards are scanned to report datasource usage in GrafanaDatasource status. 0 disables usage reporting.")
+	flag.DurationVar(&syncWindow, "sync-window", 0, "Coalesces reconciles triggered by changes to referenced Secrets and ConfigMaps, and to datasources with TLS Secrets mounted into a Grafana instance, within the window into a single reconcile. 0 reconciles every change right away.")
+	flag.StringVar(&apiServerAddr, "api-server-bind-address", "", "The address the read-only operator API binds to. Empty string disables the API server.")
+	flag.StringVar(&apiServerCertDir, "api-server-cert-dir", "", "Directory with the tls.crt and tls.key files the operator API is served with. Empty string serves a self-signed certificate.")
+	flag.IntVar(&metricsInstanceLimit, "metrics-instance-label-limit", 200, "Number of Grafana instances with their own series in the API latency and managed objects metrics, further instances are aggregated. 0 disables the limit.")
+	flag.StringVar(&tlsMinVersion, "tls-min-version", "1.2", "Minimum TLS version of connections to Grafana instances and content sources, 1.2 or 1.3.")
+	flag.StringVar(&tlsCipherSuites, "tls-cipher-suites", "", "Comma separated IANA names of the TLS 1.2 cipher suites allowed for connections to Grafana instances and content sources. Empty uses the Go defaults.")
+	flag.DurationVar(&grafanaComRevisionCheckInterval, "grafana-com-revision-check-interval", 0, "How often grafana.com is checked for newer revisions of dashboards and library panels pinned to a revision. 0 disables the check.")
+	flag.StringVar(&grafanaComRevisionWebhookURL, "grafana-com-revision-webhook-url", "", "URL receiving a POST request with a JSON notification once a newer grafana.com revision is detected. Empty disables notifications.")
+	flag.DurationVar(&integrityCheckInterval, "integrity-check-interval", 0, "How often references between resources, e.g. folderRefs and contact points of notification policies, are checked across the cluster. 0 disables the check.")
+	flag.StringVar(&gitWebhookAddr, "git-webhook-bind-address", "", "The address the webhook receiving Git push events for dashboards of Git repositories binds to, requires the GIT_WEBHOOK_SECRET environment variable. Empty string disables the webhook.")
+	flag.BoolVar(&enableDashboardWebhook, "enable-dashboard-validation-webhook", false, "Serve the admission webhook validating the content of GrafanaDashboards on port 9443. Requires a ValidatingWebhookConfiguration and a serving certificate in /tmp/k8s-webhook-server/serving-certs.")
+	flag.StringVar(&prometheusRuleNamespace, "prometheus-rule-namespace", "", "Namespace the operator maintains a PrometheusRule alerting on its own health in, requires the prometheus-operator. Empty disables the PrometheusRule.")
+	flag.StringVar(&prometheusRuleLabels, "prometheus-rule-labels", "", "Comma separated key=value labels set on the PrometheusRule, e.g. to match the ruleSelector of a Prometheus.")
+	flag.StringVar(&operatorPodLabels, "operator-pod-labels", "app.kubernetes.io/name=grafana-operator", "Comma separated key=value labels of the operator pods, the NetworkPolicies of spec.networkPolicy admit requests of these pods.")
+	flag.BoolVar(&ambientStorageCreds, "allow-ambient-storage-credentials", false, "Let S3, GCS and Azure Blob content sources without secretRef and endpoint authenticate with the identity of the operator, e.g. IRSA or Workload Identity.")
+	flag.BoolVar(&failOnStaleCRDs, "fail-on-stale-crds", false, "Refuse to start when the installed CRDs lack versions or fields this operator version expects.")
+	flag.DurationVar(&faultLatency, "fault-injection-latency", 0, "Development only: latency added to every Grafana API request.")
+	flag.Float64Var(&faultErrorRate, "fault-injection-error-rate", 0, "Development only: fraction of Grafana API requests, between 0 and 1, failing with 503 Service Unavailable.")
 
 	logCfg := uberzap.NewProductionEncoderConfig()
 	logCfg.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -135,11 +213,49 @@ func main() { // nolint:gocyclo
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	// The level of --zap-log-level applies until GrafanaOperatorConfigs override it, zap itself logs every level
+	flagLevel := opts.Level
+	if flagLevel == nil && opts.Development {
+		flagLevel = zapcore.DebugLevel
+	}
+
+	opts.Level = zapcore.Level(math.MinInt8)
+
+	ctrl.SetLogger(logging.New(zap.New(zap.UseFlagOptions(&opts)), flagLevel))
 
 	slogger := slog.New(logr.ToSlogHandler(setupLog))
 	slog.SetDefault(slogger)
 
+	if faultErrorRate < 0 || faultErrorRate > 1 {
+		setupLog.Error(fmt.Errorf("invalid value %v for --fault-injection-error-rate", faultErrorRate), "the error rate must be between 0 and 1")
+		os.Exit(1) //nolint
+	}
+
+	operatormetrics.SetInstanceLabelLimit(metricsInstanceLimit)
+
+	minVersion, err := grafanaclient.ParseTLSVersion(tlsMinVersion)
+	if err != nil {
+		setupLog.Error(err, "invalid value for --tls-min-version")
+		os.Exit(1) //nolint
+	}
+
+	var cipherSuites []uint16
+	if tlsCipherSuites != "" {
+		cipherSuites, err = grafanaclient.ParseCipherSuites(strings.Split(tlsCipherSuites, ","))
+		if err != nil {
+			setupLog.Error(err, "invalid value for --tls-cipher-suites")
+			os.Exit(1) //nolint
+		}
+	}
+
+	grafanaclient.SetTLSPolicy(minVersion, cipherSuites)
+	fetchers.SetAmbientCredentials(ambientStorageCreds)
+
+	if faultLatency > 0 || faultErrorRate > 0 {
+		setupLog.Info("WARNING: injecting faults into Grafana API requests, do not use in production", "latency", faultLatency, "errorRate", faultErrorRate)
+		grafanaclient.SetFaultInjection(grafanaclient.FaultInjection{Latency: faultLatency, ErrorRate: faultErrorRate})
+	}
+
 	// Optimize Go runtime based on CGroup limits (GOMEMLIMIT, sets a soft memory limit for the runtime)
 	memlimit.SetGoMemLimitWithOpts(memlimit.WithLogger(slogger)) //nolint:errcheck
 
@@ -186,6 +302,32 @@ func main() { // nolint:gocyclo
 		os.Exit(1)
 	}
 
+	hasHTTPRoutes, err := autodetect.HasHTTPRoutes()
+	if err != nil {
+		setupLog.Error(err, "unable to detect the gateway api")
+		os.Exit(1)
+	}
+
+	hasGRPCRoutes, err := autodetect.HasGRPCRoutes()
+	if err != nil {
+		setupLog.Error(err, "unable to detect the gateway api")
+		os.Exit(1)
+	}
+
+	hasMonitors, err := autodetect.HasMonitors()
+	if err != nil {
+		setupLog.Error(err, "unable to detect the prometheus-operator")
+		os.Exit(1)
+	}
+
+	hasScaledObjects, err := autodetect.HasScaledObjects()
+	if err != nil {
+		setupLog.Error(err, "unable to detect keda")
+		os.Exit(1)
+	}
+
+	checkCRDs(restConfig, failOnStaleCRDs)
+
 	mgrOptions := ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
@@ -219,17 +361,33 @@ func main() { // nolint:gocyclo
 
 		// ConfigMaps and secrets stay fully cached until we implement support for bypassing the cache for referenced objects
 		mgrOptions.Cache.ByObject = map[client.Object]cache.ByObject{
-			&v1.Deployment{}:                cacheLabelConfig,
-			&corev1.Service{}:               cacheLabelConfig,
-			&corev1.ServiceAccount{}:        cacheLabelConfig,
-			&networkingv1.Ingress{}:         cacheLabelConfig,
-			&corev1.PersistentVolumeClaim{}: cacheLabelConfig,
-			&corev1.ConfigMap{}:             cacheLabelConfig, // Matching just labeled ConfigMaps and Secrets greatly reduces cache size
-			&corev1.Secret{}:                cacheLabelConfig, // Omitting labels or supporting custom labels would require changes in Grafana Reconciler
+			&v1.Deployment{}:                         cacheLabelConfig,
+			&v1.StatefulSet{}:                        cacheLabelConfig,
+			&corev1.Service{}:                        cacheLabelConfig,
+			&corev1.ServiceAccount{}:                 cacheLabelConfig,
+			&networkingv1.Ingress{}:                  cacheLabelConfig,
+			&corev1.PersistentVolumeClaim{}:          cacheLabelConfig,
+			&policyv1.PodDisruptionBudget{}:          cacheLabelConfig,
+			&autoscalingv2.HorizontalPodAutoscaler{}: cacheLabelConfig,
+			&networkingv1.NetworkPolicy{}:            cacheLabelConfig,
+			&corev1.ConfigMap{}:                      cacheLabelConfig, // Matching just labeled ConfigMaps and Secrets greatly reduces cache size
+			&corev1.Secret{}:                         cacheLabelConfig, // Omitting labels or supporting custom labels would require changes in Grafana Reconciler
 		}
 		if isOpenShift {
 			mgrOptions.Cache.ByObject[&routev1.Route{}] = cacheLabelConfig
 		}
+		if hasMonitors {
+			for _, gvk := range []schema.GroupVersionKind{model.ServiceMonitorGVK, model.PodMonitorGVK} {
+				monitor := &unstructured.Unstructured{}
+				monitor.SetGroupVersionKind(gvk)
+				mgrOptions.Cache.ByObject[monitor] = cacheLabelConfig
+			}
+		}
+		if hasScaledObjects {
+			scaledObject := &unstructured.Unstructured{}
+			scaledObject.SetGroupVersionKind(model.ScaledObjectGVK)
+			mgrOptions.Cache.ByObject[scaledObject] = cacheLabelConfig
+		}
 
 		if enforceCacheLabelsLevel == cachingLevelSafe {
 			mgrOptions.Client.Cache = &client.CacheOptions{
@@ -239,6 +397,8 @@ func main() { // nolint:gocyclo
 	}
 
 	// Determine Operator scope
+	clusterScoped := watchNamespace == "" && watchNamespaceSelector == ""
+
 	switch {
 	case strings.Contains(watchNamespace, ","):
 		// multi namespace scoped
@@ -254,7 +414,7 @@ func main() { // nolint:gocyclo
 
 		setupLog.Info("operator running in namespace scoped mode using namespace selector", "namespace", watchNamespace)
 
-	case watchNamespace == "" && watchNamespaceSelector == "":
+	case clusterScoped:
 		// cluster scoped
 		mgrOptions.Cache.DefaultLabelSelector = labelSelectors
 
@@ -270,28 +430,76 @@ func main() { // nolint:gocyclo
 	}
 
 	ctrlCfg := &controllers.Config{
-		ResyncPeriod: resyncPeriod,
+		ResyncPeriod:              resyncPeriod,
+		ResyncJitterPercent:       resyncJitterPercent,
+		AlertRuleGroupInterval:    ruleGroupInterval,
+		AlertRuleParallelism:      ruleParallelism,
+		DatasourceUsageInterval:   datasourceUsageInterval,
+		DashboardApplyDedupWindow: dashboardDedupWindow,
+		SyncWindow:                syncWindow,
+		ClusterScoped:             clusterScoped,
+
+		GrafanaComRevisionCheckInterval: grafanaComRevisionCheckInterval,
+		GrafanaComRevisionWebhookURL:    grafanaComRevisionWebhookURL,
+	}
+	// The stored Grafanas merged onto their class are read from the cache
+	provision.ConfigureClasses(mgr.GetCache(), clusterScoped)
+
+	if err = controllers.SetupWaveIndex(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to set up wave index")
+		os.Exit(1)
 	}
+
 	// Register controllers
+	operatorLabels, err := labels.ConvertSelectorToLabelsMap(operatorPodLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid value for --operator-pod-labels")
+		os.Exit(1) //nolint
+	}
+
 	if err = (&controllers.GrafanaReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		IsOpenShift:   isOpenShift,
-		ClusterDomain: clusterDomain,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		IsOpenShift:      isOpenShift,
+		HasHTTPRoutes:    hasHTTPRoutes,
+		HasGRPCRoutes:    hasGRPCRoutes,
+		HasMonitors:      hasMonitors,
+		HasScaledObjects: hasScaledObjects,
+		ClusterScoped:    clusterScoped,
+		ClusterDomain:    clusterDomain,
+		Operator: grafana.OperatorPeer{
+			Namespace: os.Getenv(operatorNamespaceEnvVar),
+			Labels:    operatorLabels,
+		},
+		SyncWindow: syncWindow,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Grafana")
 		os.Exit(1)
 	}
 
+	var gitPushes chan event.GenericEvent
+	if gitWebhookAddr != "" {
+		gitPushes = make(chan event.GenericEvent)
+	}
+
 	if err = (&controllers.GrafanaDashboardReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Cfg:    ctrlCfg,
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Cfg:       ctrlCfg,
+		Recorder:  mgr.GetEventRecorderFor("GrafanaDashboard"),
+		GitPushes: gitPushes,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDashboard")
 		os.Exit(1)
 	}
 
+	if enableDashboardWebhook {
+		if err = (&controllers.DashboardValidator{Client: mgr.GetClient()}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GrafanaDashboard")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.GrafanaDatasourceReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -314,15 +522,16 @@ func main() { // nolint:gocyclo
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cfg:    ctrlCfg,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaFolder")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.GrafanaLibraryPanelReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Cfg:    ctrlCfg,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Cfg:      ctrlCfg,
+		Recorder: mgr.GetEventRecorderFor("GrafanaLibraryPanel"),
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaLibraryPanel")
 		os.Exit(1)
@@ -332,7 +541,7 @@ func main() { // nolint:gocyclo
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cfg:    ctrlCfg,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaAlertRuleGroup")
 		os.Exit(1)
 	}
@@ -351,7 +560,7 @@ func main() { // nolint:gocyclo
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("GrafanaNotificationPolicy"),
 		Cfg:      ctrlCfg,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaNotificationPolicy")
 		os.Exit(1)
 	}
@@ -360,21 +569,87 @@ func main() { // nolint:gocyclo
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cfg:    ctrlCfg,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaNotificationTemplate")
 		os.Exit(1)
 	}
 
+	if err = (&controllers.GrafanaDashboardSetReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Cfg:      ctrlCfg,
+		Recorder: mgr.GetEventRecorderFor("GrafanaDashboardSet"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDashboardSet")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.GrafanaMuteTimingReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cfg:    ctrlCfg,
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaMuteTiming")
 		os.Exit(1)
 	}
+
+	// GrafanaOperatorConfigs are cluster scoped, operators restricted to namespaces can't watch them
+	if clusterScoped {
+		if err = (&controllers.GrafanaOperatorConfigReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "GrafanaOperatorConfig")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if apiServerAddr != "" {
+		if err := mgr.Add(apiserver.New(mgr.GetClient(), apiServerAddr, apiServerCertDir)); err != nil {
+			setupLog.Error(err, "unable to set up operator api server")
+			os.Exit(1)
+		}
+	}
+
+	if gitWebhookAddr != "" {
+		gitWebhookSecret, _ := os.LookupEnv(gitWebhookSecretEnvVar)
+		if gitWebhookSecret == "" {
+			setupLog.Error(fmt.Errorf("missing %s environment variable", gitWebhookSecretEnvVar), "the git webhook verifies push events with the secret, see --git-webhook-bind-address")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(gitwebhook.New(mgr.GetClient(), gitWebhookAddr, gitWebhookSecret, gitPushes)); err != nil {
+			setupLog.Error(err, "unable to set up git webhook server")
+			os.Exit(1)
+		}
+	}
+
+	if integrityCheckInterval > 0 {
+		if err := mgr.Add(&controllers.IntegrityChecker{Client: mgr.GetClient(), Interval: integrityCheckInterval}); err != nil {
+			setupLog.Error(err, "unable to set up integrity checker")
+			os.Exit(1)
+		}
+	}
+
+	if prometheusRuleNamespace != "" {
+		ruleLabels, err := labels.ConvertSelectorToLabelsMap(prometheusRuleLabels)
+		if err != nil {
+			setupLog.Error(err, "invalid value for --prometheus-rule-labels")
+			os.Exit(1) //nolint
+		}
+
+		if err := mgr.Add(&controllers.PrometheusRuleApplier{
+			Client:    mgr.GetClient(),
+			Namespace: prometheusRuleNamespace,
+			Labels:    ruleLabels,
+			Interval:  resyncPeriod,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up PrometheusRule")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -466,3 +741,41 @@ func getLabelSelectors(watchLabelSelectors string) (labels.Selector, error) {
 
 	return labelSelectors, nil
 }
+
+// checkCRDs compares the installed CRDs with the ones embedded in the binary, so that outdated CRDs,
+// e.g. after upgrading the operator through helm which does not upgrade CRDs, are reported up front
+// rather than through decode errors and silently dropped fields during reconciles.
+func checkCRDs(restConfig *rest.Config, failOnStale bool) {
+	sub, err := fs.Sub(expectedCRDs, "config/crd/bases")
+	if err != nil {
+		setupLog.Error(err, "unable to read the expected CRDs")
+		os.Exit(1)
+	}
+
+	expected, err := crdcheck.LoadExpected(sub)
+	if err != nil {
+		setupLog.Error(err, "unable to read the expected CRDs")
+		os.Exit(1)
+	}
+
+	drifts, err := crdcheck.Check(context.Background(), restConfig, expected)
+	if err != nil {
+		// Missing permissions or an unreachable API server should not block startup, the check is advisory
+		setupLog.Error(err, "unable to compare the installed CRDs with the expected ones")
+		return
+	}
+
+	for _, crd := range expected {
+		operatormetrics.CRDStale.WithLabelValues(crd.Name).Set(0)
+	}
+
+	for _, drift := range drifts {
+		operatormetrics.CRDStale.WithLabelValues(drift.Name).Set(1)
+		setupLog.Error(fmt.Errorf("stale CRD: %s", drift), "installed CRD does not match this operator version, apply the CRDs of this release", "crd", drift.Name)
+	}
+
+	if len(drifts) > 0 && failOnStale {
+		setupLog.Error(fmt.Errorf("%d stale CRDs", len(drifts)), "refusing to start with outdated CRDs, see --fail-on-stale-crds")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,152 @@
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// storedReader reads the stored Grafanas merged onto their class, the client of the caller when unset
+	storedReader client.Reader
+	// classesUnavailable is set when the operator can't read the cluster scoped GrafanaClasses
+	classesUnavailable bool
+
+	errClassesUnavailable = errors.New("GrafanaClasses require a cluster scoped operator")
+)
+
+// ConfigureClasses sets how classes are resolved: stored is the cache of the manager the stored Grafanas are listed
+// from instead of the API server. Operators restricted to namespaces can't read classes, their instances referencing
+// one stay unresolved
+func ConfigureClasses(stored client.Reader, clusterScoped bool) {
+	storedReader = stored
+	classesUnavailable = !clusterScoped
+}
+
+// storedGrafanas returns the reader of the stored Grafanas
+func storedGrafanas(k8sClient client.Reader) client.Reader {
+	if storedReader != nil {
+		return storedReader
+	}
+
+	return k8sClient
+}
+
+// ResolveClass replaces the in-memory spec of the instance with the spec of the GrafanaClass referenced in
+// spec.className, overridden by the instance spec. Everything reading the spec of an instance resolves its class first,
+// the stored Grafana spec is not modified
+func ResolveClass(ctx context.Context, k8sClient client.Reader, instance *v1beta1.Grafana) error {
+	if instance.Spec.ClassName == "" {
+		return nil
+	}
+
+	if classesUnavailable {
+		return errClassesUnavailable
+	}
+
+	stored := &unstructured.Unstructured{}
+	stored.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("Grafana"))
+
+	err := storedGrafanas(k8sClient).Get(ctx, client.ObjectKeyFromObject(instance), stored)
+	if err != nil {
+		return fmt.Errorf("getting Grafana %s/%s: %w", instance.Namespace, instance.Name, err)
+	}
+
+	return resolveClass(ctx, k8sClient, instance, stored)
+}
+
+// ResolveClasses is ResolveClass for a list of instances, the stored specs are listed once per namespace from the
+// cache. Instances whose class can't be resolved are dropped and returned by name in unresolved
+func ResolveClasses(ctx context.Context, k8sClient client.Reader, instances []v1beta1.Grafana) (resolved []v1beta1.Grafana, unresolved []string, err error) {
+	stored := map[string]map[string]*unstructured.Unstructured{}
+	resolved = make([]v1beta1.Grafana, 0, len(instances))
+
+	for _, instance := range instances {
+		if instance.Spec.ClassName == "" {
+			resolved = append(resolved, instance)
+			continue
+		}
+
+		if classesUnavailable {
+			unresolved = append(unresolved, instance.Name)
+			continue
+		}
+
+		if _, ok := stored[instance.Namespace]; !ok {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("GrafanaList"))
+
+			if err := storedGrafanas(k8sClient).List(ctx, list, client.InNamespace(instance.Namespace)); err != nil {
+				return nil, nil, fmt.Errorf("listing Grafanas in %s: %w", instance.Namespace, err)
+			}
+
+			stored[instance.Namespace] = make(map[string]*unstructured.Unstructured, len(list.Items))
+			for i := range list.Items {
+				stored[instance.Namespace][list.Items[i].GetName()] = &list.Items[i]
+			}
+		}
+
+		obj, ok := stored[instance.Namespace][instance.Name]
+		if !ok || resolveClass(ctx, k8sClient, &instance, obj) != nil {
+			unresolved = append(unresolved, instance.Name)
+			continue
+		}
+
+		resolved = append(resolved, instance)
+	}
+
+	return resolved, unresolved, nil
+}
+
+func resolveClass(ctx context.Context, k8sClient client.Reader, instance *v1beta1.Grafana, stored *unstructured.Unstructured) error {
+	class := &v1beta1.GrafanaClass{}
+
+	err := k8sClient.Get(ctx, client.ObjectKey{Name: instance.Spec.ClassName}, class)
+	if err != nil {
+		return fmt.Errorf("getting GrafanaClass %s: %w", instance.Spec.ClassName, err)
+	}
+
+	spec, _, err := unstructured.NestedFieldNoCopy(stored.Object, "spec")
+	if err != nil {
+		return err
+	}
+
+	overrides, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	return mergeClass(class, instance, overrides)
+}
+
+// mergeClass merges the instance spec given as stored JSON on top of the class spec. Objects are merged recursively
+// and lists set on the instance replace the ones of the class (JSON merge patch).
+// The stored JSON keeps fields explicitly set to false or empty, they override the class
+func mergeClass(class *v1beta1.GrafanaClass, instance *v1beta1.Grafana, overrides []byte) error {
+	classSpec, err := json.Marshal(class.Spec)
+	if err != nil {
+		return err
+	}
+
+	merged, err := jsonpatch.MergePatch(classSpec, overrides)
+	if err != nil {
+		return err
+	}
+
+	spec := v1beta1.GrafanaSpec{}
+	if err := json.Unmarshal(merged, &spec); err != nil {
+		return err
+	}
+
+	// Classes do not chain, a className set in the class is ignored
+	spec.ClassName = instance.Spec.ClassName
+	instance.Spec = spec
+
+	return nil
+}
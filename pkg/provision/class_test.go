@@ -0,0 +1,124 @@
+package provision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMergeClass(t *testing.T) {
+	replicas := int32(2)
+	class := &v1beta1.GrafanaClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec: v1beta1.GrafanaSpec{
+			Version:        "12.0.0",
+			SuspendContent: true,
+			Config: map[string]map[string]string{
+				"log":      {"mode": "console"},
+				"database": {"type": "postgres"},
+			},
+			Deployment: &v1beta1.DeploymentV1{
+				Spec: v1beta1.DeploymentV1Spec{Replicas: &replicas},
+			},
+		},
+	}
+
+	t.Run("instance overrides the class", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{ClassName: "standard"}}
+
+		require.NoError(t, mergeClass(class, cr, []byte(`{"className":"standard","version":"12.1.0","config":{"log":{"mode":"file"}}}`)))
+
+		assert.Equal(t, "12.1.0", cr.Spec.Version)
+		assert.Equal(t, "file", cr.Spec.Config["log"]["mode"])
+		assert.Equal(t, "postgres", cr.Spec.Config["database"]["type"])
+		require.NotNil(t, cr.Spec.Deployment)
+		assert.Equal(t, int32(2), *cr.Spec.Deployment.Spec.Replicas)
+		assert.True(t, cr.Spec.SuspendContent)
+		assert.Equal(t, "standard", cr.Spec.ClassName)
+	})
+
+	t.Run("explicit false overrides the class", func(t *testing.T) {
+		cr := &v1beta1.Grafana{Spec: v1beta1.GrafanaSpec{ClassName: "standard"}}
+
+		require.NoError(t, mergeClass(class, cr, []byte(`{"className":"standard","suspendContent":false}`)))
+
+		assert.False(t, cr.Spec.SuspendContent)
+		assert.Equal(t, "12.0.0", cr.Spec.Version)
+	})
+}
+
+func TestResolveClasses(t *testing.T) {
+	ctx := context.Background()
+
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	class := &v1beta1.GrafanaClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "readonly"},
+		Spec:       v1beta1.GrafanaSpec{Role: v1beta1.GrafanaRoleReadonly, SuspendContent: true},
+	}
+
+	instance := func(name, className string) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1beta1.GrafanaSpec{ClassName: className, Version: "12.1.0"},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		class,
+		instance("plain", ""),
+		instance("classed", "readonly"),
+		instance("missing-class", "missing"),
+	).Build()
+
+	t.Run("single instance", func(t *testing.T) {
+		cr := instance("classed", "readonly")
+
+		require.NoError(t, ResolveClass(ctx, cl, cr))
+
+		assert.True(t, cr.IsReadonly())
+		assert.True(t, cr.Spec.SuspendContent)
+		assert.Equal(t, "12.1.0", cr.Spec.Version)
+	})
+
+	t.Run("missing class", func(t *testing.T) {
+		require.Error(t, ResolveClass(ctx, cl, instance("missing-class", "missing")))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		resolved, unresolved, err := ResolveClasses(ctx, cl, []v1beta1.Grafana{
+			*instance("plain", ""),
+			*instance("classed", "readonly"),
+			*instance("missing-class", "missing"),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, resolved, 2)
+		assert.False(t, resolved[0].IsReadonly())
+		assert.True(t, resolved[1].IsReadonly())
+		assert.Equal(t, []string{"missing-class"}, unresolved)
+	})
+
+	t.Run("namespace scoped operator", func(t *testing.T) {
+		ConfigureClasses(nil, false)
+		t.Cleanup(func() { ConfigureClasses(nil, true) })
+
+		require.ErrorIs(t, ResolveClass(ctx, cl, instance("classed", "readonly")), errClassesUnavailable)
+
+		resolved, unresolved, err := ResolveClasses(ctx, cl, []v1beta1.Grafana{
+			*instance("plain", ""),
+			*instance("classed", "readonly"),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, resolved, 1)
+		assert.Equal(t, []string{"classed"}, unresolved)
+	})
+}
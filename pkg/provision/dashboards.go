@@ -0,0 +1,102 @@
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrNoMatchingInstances is returned when no ready instance matches the selector
+var ErrNoMatchingInstances = errors.New("no matching instances")
+
+// apiClientError is implemented by the responses of the generated Grafana client
+type apiClientError interface {
+	IsClientError() bool
+}
+
+// isRetryable retries connection and server errors, client errors like 4xx responses are final
+func isRetryable(err error) bool {
+	var clientErr apiClientError
+	return !errors.As(err, &clientErr) || !clientErr.IsClientError()
+}
+
+// ApplyDashboard creates or overwrites the dashboard model in the folder of every ready instance matching selector,
+// folderUID may be empty for the General folder. Transient errors are retried, the returned error joins the errors of
+// all instances the dashboard could not be applied to
+func ApplyDashboard(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector, model map[string]any, folderUID string) error {
+	instances, _, err := MatchingInstances(ctx, k8sClient, namespace, selector)
+	if err != nil {
+		return fmt.Errorf("fetching instances: %w", err)
+	}
+
+	if len(instances) == 0 {
+		return ErrNoMatchingInstances
+	}
+
+	var errs []error
+
+	for _, instance := range instances {
+		cl, err := NewClient(ctx, k8sClient, &instance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", instance.Namespace, instance.Name, err))
+			continue
+		}
+
+		err = retry.OnError(retry.DefaultBackoff, isRetryable, func() error {
+			_, err := cl.Dashboards.PostDashboard(&models.SaveDashboardCommand{ //nolint:errcheck
+				// Grafana sets the id and version of the stored model
+				Dashboard: maps.Clone(model),
+				FolderUID: folderUID,
+				Overwrite: true,
+			})
+
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", instance.Namespace, instance.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteDashboard deletes the dashboard from every ready instance matching selector, missing dashboards are ignored
+func DeleteDashboard(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector, uid string) error {
+	instances, _, err := MatchingInstances(ctx, k8sClient, namespace, selector)
+	if err != nil {
+		return fmt.Errorf("fetching instances: %w", err)
+	}
+
+	var errs []error
+
+	for _, instance := range instances {
+		cl, err := NewClient(ctx, k8sClient, &instance)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", instance.Namespace, instance.Name, err))
+			continue
+		}
+
+		err = retry.OnError(retry.DefaultBackoff, isRetryable, func() error {
+			_, err := cl.Dashboards.DeleteDashboardByUID(uid) //nolint:errcheck
+
+			var notFound *dashboards.DeleteDashboardByUIDNotFound
+			if errors.As(err, &notFound) {
+				return nil
+			}
+
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", instance.Namespace, instance.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,93 @@
+package provision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/grafana/grafana-operator/v5/pkg/testing/grafanafake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyDashboard(t *testing.T) {
+	ctx := context.Background()
+
+	srv := grafanafake.NewServer()
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+
+	instance := func(name string, labels map[string]string, ready bool) *v1beta1.Grafana {
+		grafana := &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+			Spec: v1beta1.GrafanaSpec{
+				External: &v1beta1.External{
+					URL: srv.URL,
+					APIKey: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "api-key"},
+						Key:                  "token",
+					},
+				},
+			},
+			Status: v1beta1.GrafanaStatus{AdminURL: srv.URL, Stage: v1beta1.OperatorStageComplete},
+		}
+
+		if ready {
+			grafana.Status.StageStatus = v1beta1.OperatorStageResultSuccess
+		}
+
+		return grafana
+	}
+
+	readonly := instance("team-a-readonly", map[string]string{"team": "a"}, true)
+	readonly.Spec.Role = v1beta1.GrafanaRoleReadonly
+
+	cl := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(
+			apiKey,
+			instance("team-a", map[string]string{"team": "a"}, true),
+			instance("team-a-pending", map[string]string{"team": "a"}, false),
+			instance("team-b", map[string]string{"team": "b"}, true),
+			readonly,
+		).
+		WithStatusSubresource(&v1beta1.Grafana{}).
+		Build()
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+
+	ready, unready, err := MatchingInstances(ctx, cl, "default", selector)
+	require.NoError(t, err)
+	require.Len(t, ready, 1)
+	assert.Equal(t, "team-a", ready[0].Name)
+	assert.Equal(t, []string{"team-a-pending"}, unready)
+
+	model := map[string]any{"uid": "overview", "title": "Overview"}
+
+	require.NoError(t, ApplyDashboard(ctx, cl, "default", selector, model, ""))
+
+	got, _, found := srv.Dashboard("overview")
+	require.True(t, found)
+	assert.Equal(t, "Overview", got["title"])
+	assert.NotContains(t, model, "version", "the model passed by the caller must not be modified")
+
+	require.NoError(t, DeleteDashboard(ctx, cl, "default", selector, "overview"))
+
+	_, _, found = srv.Dashboard("overview")
+	assert.False(t, found)
+
+	err = ApplyDashboard(ctx, cl, "default", &metav1.LabelSelector{MatchLabels: map[string]string{"team": "c"}}, model, "")
+	assert.ErrorIs(t, err, ErrNoMatchingInstances)
+}
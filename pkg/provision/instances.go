@@ -0,0 +1,182 @@
+// Package provision exposes how the operator resolves Grafana instances and talks to them, for controllers and tools
+// built on top of the operator's resources, e.g. to apply a dashboard to all instances matching a selector.
+//
+// It is part of the operator module and follows its versioning, the operator's controllers use the same functions.
+package provision
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	genapi "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaclient "github.com/grafana/grafana-operator/v5/controllers/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MatchingInstances returns the ready Grafana instances in namespace matching selector, an empty namespace matches all namespaces.
+// Matching instances that are not ready yet, e.g. still being deployed, or suspend content are returned by name in unready.
+// Readonly instances are never returned, they serve the content applied to the primary sharing their database
+func MatchingInstances(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector) (ready []v1beta1.Grafana, unready []string, err error) {
+	instances, unresolved, err := listMatchingInstances(ctx, k8sClient, namespace, selector)
+	if err != nil {
+		return []v1beta1.Grafana{}, nil, err
+	}
+
+	// Instances whose class is missing are not reconciled either
+	unready = unresolved
+
+	ready = make([]v1beta1.Grafana, 0, len(instances))
+
+	for _, instance := range instances {
+		// admin url is required to interact with Grafana
+		// the instance or route might not yet be ready
+		if !IsReady(&instance) || instance.Spec.SuspendContent {
+			unready = append(unready, instance.Name)
+			continue
+		}
+
+		ready = append(ready, instance)
+	}
+
+	return ready, unready, nil
+}
+
+// StartupRetryAfter returns the shortest delay after which one of the matching instances that are still starting
+// is expected to have progressed, 0 if none is starting
+func StartupRetryAfter(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector, now time.Time) (time.Duration, error) {
+	instances, _, err := listMatchingInstances(ctx, k8sClient, namespace, selector)
+	if err != nil {
+		return 0, err
+	}
+
+	var retryAfter time.Duration
+
+	for _, instance := range instances {
+		if IsReady(&instance) || instance.Spec.SuspendContent {
+			continue
+		}
+
+		delay := instance.Status.Startup.RetryAfter(now)
+		if delay > 0 && (retryAfter == 0 || delay < retryAfter) {
+			retryAfter = delay
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// SuspendedInstances returns the names of the instances in namespace matching selector that suspend content. Their
+// content is neither applied nor removed until they resume
+func SuspendedInstances(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector) ([]string, error) {
+	instances, _, err := listMatchingInstances(ctx, k8sClient, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var suspended []string
+
+	for _, instance := range instances {
+		if instance.Spec.SuspendContent {
+			suspended = append(suspended, instance.Name)
+		}
+	}
+
+	return suspended, nil
+}
+
+// listMatchingInstances returns the instances in namespace matching selector with their class resolved, except readonly
+// instances. Instances whose class can't be resolved are returned by name in unresolved
+func listMatchingInstances(ctx context.Context, k8sClient client.Client, namespace string, selector *metav1.LabelSelector) (instances []v1beta1.Grafana, unresolved []string, err error) {
+	if selector == nil {
+		return nil, nil, nil
+	}
+
+	opts := []client.ListOption{
+		// Matches all instances when MatchLabels is undefined
+		client.MatchingLabels(selector.MatchLabels),
+	}
+
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	var list v1beta1.GrafanaList
+
+	err = k8sClient.List(ctx, &list, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matching := make([]v1beta1.Grafana, 0, len(list.Items))
+
+	for _, instance := range list.Items {
+		// Matches all instances when MatchExpressions is undefined
+		if LabelsSatisfyMatchExpressions(instance.Labels, selector.MatchExpressions) {
+			matching = append(matching, instance)
+		}
+	}
+
+	matching, unresolved, err = ResolveClasses(ctx, k8sClient, matching)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	instances = make([]v1beta1.Grafana, 0, len(matching))
+
+	for _, instance := range matching {
+		// The role may be set by the class
+		if instance.IsReadonly() {
+			continue
+		}
+
+		instances = append(instances, instance)
+	}
+
+	return instances, unresolved, nil
+}
+
+// IsReady reports whether the operator completed reconciling the instance, only ready instances can be reached
+func IsReady(instance *v1beta1.Grafana) bool {
+	return instance.Status.Stage == v1beta1.OperatorStageComplete && instance.Status.StageStatus == v1beta1.OperatorStageResultSuccess
+}
+
+// LabelsSatisfyMatchExpressions evaluates the matchExpressions of an instanceSelector against the labels of an instance
+func LabelsSatisfyMatchExpressions(labels map[string]string, matchExpressions []metav1.LabelSelectorRequirement) bool {
+	// To preserve support for scenario with instanceSelector: {}
+	if len(labels) == 0 {
+		return true
+	}
+
+	for _, matchExpression := range matchExpressions {
+		selected := false
+
+		if label, ok := labels[matchExpression.Key]; ok {
+			switch matchExpression.Operator {
+			case metav1.LabelSelectorOpDoesNotExist:
+				selected = false
+			case metav1.LabelSelectorOpExists:
+				selected = true
+			case metav1.LabelSelectorOpIn:
+				selected = slices.Contains(matchExpression.Values, label)
+			case metav1.LabelSelectorOpNotIn:
+				selected = !slices.Contains(matchExpression.Values, label)
+			}
+		}
+
+		// All matchExpressions must evaluate to true in order to satisfy the conditions
+		if !selected {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewClient returns a Grafana API client for the instance, authenticated like the operator's own clients
+// with the admin credentials, API key or service account token configured on the Grafana resource
+func NewClient(ctx context.Context, k8sClient client.Client, instance *v1beta1.Grafana) (*genapi.GrafanaHTTPAPI, error) {
+	return grafanaclient.NewGeneratedGrafanaClient(ctx, k8sClient, instance)
+}
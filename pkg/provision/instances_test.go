@@ -0,0 +1,108 @@
+package provision
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStartupRetryAfter(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	instance := func(name string, startup *v1beta1.GrafanaStartup) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"team": "a"}},
+			Status: v1beta1.GrafanaStatus{
+				Stage:       v1beta1.OperatorStageComplete,
+				StageStatus: v1beta1.OperatorStageResultFailed,
+				Startup:     startup,
+			},
+		}
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+
+	t.Run("shortest delay of starting instances", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			instance("database", &v1beta1.GrafanaStartup{Phase: v1beta1.GrafanaStartupDatabaseUnavailable, Since: metav1.NewTime(now)}),
+			instance("starting", &v1beta1.GrafanaStartup{Phase: v1beta1.GrafanaStartupStarting, Since: metav1.NewTime(now)}),
+			instance("failed", nil),
+		).Build()
+
+		retryAfter, err := StartupRetryAfter(t.Context(), cl, "default", selector, now)
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, retryAfter)
+	})
+
+	t.Run("no starting instances", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(instance("failed", nil)).Build()
+
+		retryAfter, err := StartupRetryAfter(t.Context(), cl, "default", selector, now)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter)
+	})
+}
+
+func TestMatchingInstancesSuspendContent(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	instance := func(name string, suspendContent bool) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"team": "a"}},
+			Spec:       v1beta1.GrafanaSpec{SuspendContent: suspendContent},
+			Status: v1beta1.GrafanaStatus{
+				Stage:       v1beta1.OperatorStageComplete,
+				StageStatus: v1beta1.OperatorStageResultSuccess,
+			},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(instance("active", false), instance("suspended", true)).Build()
+
+	ready, unready, err := MatchingInstances(t.Context(), cl, "default", &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}})
+	require.NoError(t, err)
+	require.Len(t, ready, 1)
+	assert.Equal(t, "active", ready[0].Name)
+	assert.Equal(t, []string{"suspended"}, unready)
+}
+
+func TestMatchingInstancesClass(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(s))
+
+	instance := func(name, className string) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"team": "a"}},
+			Spec:       v1beta1.GrafanaSpec{ClassName: className},
+			Status: v1beta1.GrafanaStatus{
+				Stage:       v1beta1.OperatorStageComplete,
+				StageStatus: v1beta1.OperatorStageResultSuccess,
+			},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&v1beta1.GrafanaClass{ObjectMeta: metav1.ObjectMeta{Name: "paused"}, Spec: v1beta1.GrafanaSpec{SuspendContent: true}},
+		&v1beta1.GrafanaClass{ObjectMeta: metav1.ObjectMeta{Name: "replica"}, Spec: v1beta1.GrafanaSpec{Role: v1beta1.GrafanaRoleReadonly}},
+		instance("active", ""),
+		instance("suspended", "paused"),
+		instance("readonly", "replica"),
+		instance("unresolved", "missing"),
+	).Build()
+
+	ready, unready, err := MatchingInstances(t.Context(), cl, "default", &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}})
+	require.NoError(t, err)
+	require.Len(t, ready, 1)
+	assert.Equal(t, "active", ready[0].Name)
+	assert.ElementsMatch(t, []string{"suspended", "unresolved"}, unready)
+}
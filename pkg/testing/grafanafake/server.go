@@ -0,0 +1,1321 @@
+// Package grafanafake provides an in-memory HTTP server implementing the subset of the Grafana API used by the
+// operator: dashboards, folders, teams, datasources, datasource queries and alerting provisioning. Dashboards are also served through
+// the dashboard.grafana.app resource API of Grafana 12.
+//
+// It is meant for tests of code built on the operator's Grafana clients, e.g. an envtest suite pointing an external
+// Grafana resource at Server.URL. Content is stored as is, the server does not validate it like Grafana does,
+// except for the condition of alert rules, which must reference one of their queries.
+package grafanafake
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+)
+
+// Version is reported by the health endpoint
+const Version = "12.0.0"
+
+type dashboard struct {
+	id          int64
+	version     int64
+	folderUID   string
+	model       map[string]any
+	annotations map[string]string
+}
+
+// dashboardResource is a dashboard of the dashboard.grafana.app API
+type dashboardResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace,omitempty"`
+		ResourceVersion string            `json:"resourceVersion,omitempty"`
+		Annotations     map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata"`
+	Spec map[string]any `json:"spec"`
+}
+
+// Server is an in-memory Grafana API served over HTTP
+type Server struct {
+	*httptest.Server
+
+	user     string
+	password string
+
+	mu            sync.Mutex
+	nextID        int64
+	dashboards    map[string]*dashboard
+	folders       map[string]*models.Folder
+	datasources   map[string]*models.DataSource
+	ruleGroups    map[string]*models.AlertRuleGroup
+	contactPoints map[string]*models.EmbeddedContactPoint
+	muteTimings   map[string]*models.MuteTimeInterval
+	teams         map[string]*models.TeamDTO
+	permissions   map[string][]*models.DashboardACLUpdateItem
+	lbacRules     map[string][]*models.TeamLBACRule
+	queryErrors   map[string]string
+}
+
+type Option func(s *Server)
+
+// WithBasicAuth requires requests to authenticate with the given credentials, by default all requests are accepted
+func WithBasicAuth(user, password string) Option {
+	return func(s *Server) {
+		s.user = user
+		s.password = password
+	}
+}
+
+// NewServer starts a Server, callers must Close it when done.
+// Server.URL is the admin URL of the instance, the API is served below /api
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		dashboards:    make(map[string]*dashboard),
+		folders:       make(map[string]*models.Folder),
+		datasources:   make(map[string]*models.DataSource),
+		ruleGroups:    make(map[string]*models.AlertRuleGroup),
+		contactPoints: make(map[string]*models.EmbeddedContactPoint),
+		muteTimings:   make(map[string]*models.MuteTimeInterval),
+		teams:         make(map[string]*models.TeamDTO),
+		permissions:   make(map[string][]*models.DashboardACLUpdateItem),
+		lbacRules:     make(map[string][]*models.TeamLBACRule),
+		queryErrors:   make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/org", s.handleOrg)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+
+	mux.HandleFunc("GET /api/dashboards/uid/{uid}", s.handleGetDashboard)
+	mux.HandleFunc("POST /api/dashboards/db", s.handlePostDashboard)
+	mux.HandleFunc("DELETE /api/dashboards/uid/{uid}", s.handleDeleteDashboard)
+
+	mux.HandleFunc("GET /apis/dashboard.grafana.app/v1beta1/namespaces/{namespace}/dashboards/{name}", s.handleGetDashboardResource)
+	mux.HandleFunc("POST /apis/dashboard.grafana.app/v1beta1/namespaces/{namespace}/dashboards", s.handleCreateDashboardResource)
+	mux.HandleFunc("PUT /apis/dashboard.grafana.app/v1beta1/namespaces/{namespace}/dashboards/{name}", s.handleUpdateDashboardResource)
+	mux.HandleFunc("DELETE /apis/dashboard.grafana.app/v1beta1/namespaces/{namespace}/dashboards/{name}", s.handleDeleteDashboardResource)
+
+	mux.HandleFunc("GET /api/folders", s.handleGetFolders)
+	mux.HandleFunc("GET /api/folders/{uid}", s.handleGetFolder)
+	mux.HandleFunc("POST /api/folders", s.handleCreateFolder)
+	mux.HandleFunc("PUT /api/folders/{uid}", s.handleUpdateFolder)
+	mux.HandleFunc("POST /api/folders/{uid}/move", s.handleMoveFolder)
+	mux.HandleFunc("DELETE /api/folders/{uid}", s.handleDeleteFolder)
+	mux.HandleFunc("POST /api/folders/{uid}/permissions", s.handleUpdateFolderPermissions)
+
+	mux.HandleFunc("GET /api/teams/search", s.handleSearchTeams)
+	mux.HandleFunc("POST /api/teams", s.handleCreateTeam)
+
+	mux.HandleFunc("GET /api/datasources", s.handleGetDatasources)
+	mux.HandleFunc("GET /api/datasources/uid/{uid}", s.handleGetDatasource)
+	mux.HandleFunc("GET /api/datasources/name/{name}", s.handleGetDatasourceByName)
+	mux.HandleFunc("POST /api/datasources", s.handleAddDatasource)
+	mux.HandleFunc("PUT /api/datasources/uid/{uid}", s.handleUpdateDatasource)
+	mux.HandleFunc("DELETE /api/datasources/uid/{uid}", s.handleDeleteDatasource)
+	mux.HandleFunc("GET /api/datasources/uid/{uid}/lbac/teams", s.handleGetLBACRules)
+	mux.HandleFunc("PUT /api/datasources/uid/{uid}/lbac/teams", s.handleUpdateLBACRules)
+	mux.HandleFunc("POST /api/ds/query", s.handleQueryDatasources)
+
+	mux.HandleFunc("GET /api/v1/provisioning/folder/{folder}/rule-groups/{group}", s.handleGetRuleGroup)
+	mux.HandleFunc("PUT /api/v1/provisioning/folder/{folder}/rule-groups/{group}", s.handlePutRuleGroup)
+	mux.HandleFunc("DELETE /api/v1/provisioning/folder/{folder}/rule-groups/{group}", s.handleDeleteRuleGroup)
+	mux.HandleFunc("POST /api/v1/provisioning/alert-rules", s.handlePostAlertRule)
+	mux.HandleFunc("PUT /api/v1/provisioning/alert-rules/{uid}", s.handlePutAlertRule)
+
+	mux.HandleFunc("GET /api/v1/provisioning/contact-points", s.handleGetContactPoints)
+	mux.HandleFunc("POST /api/v1/provisioning/contact-points", s.handlePostContactPoint)
+	mux.HandleFunc("PUT /api/v1/provisioning/contact-points/{uid}", s.handlePutContactPoint)
+	mux.HandleFunc("DELETE /api/v1/provisioning/contact-points/{uid}", s.handleDeleteContactPoint)
+
+	mux.HandleFunc("GET /api/v1/provisioning/mute-timings", s.handleGetMuteTimings)
+	mux.HandleFunc("POST /api/v1/provisioning/mute-timings", s.handlePostMuteTiming)
+
+	mux.HandleFunc("GET /api/v1/provisioning/policies", s.handleGetPolicyTree)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+
+	return s
+}
+
+// Dashboard returns the model of the dashboard and the uid of its folder
+func (s *Server) Dashboard(uid string) (map[string]any, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dashboards[uid]
+	if !ok {
+		return nil, "", false
+	}
+
+	return d.model, d.folderUID, true
+}
+
+// DashboardAnnotations returns the annotations of a dashboard applied through the resource API
+func (s *Server) DashboardAnnotations(uid string) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dashboards[uid]
+	if !ok {
+		return nil, false
+	}
+
+	return d.annotations, true
+}
+
+// Folder returns the folder with the given uid
+func (s *Server) Folder(uid string) (*models.Folder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.folders[uid]
+
+	return f, ok
+}
+
+// FolderPermissions returns the permissions last set on the folder with the given uid
+func (s *Server) FolderPermissions(uid string) ([]*models.DashboardACLUpdateItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.permissions[uid]
+
+	return p, ok
+}
+
+// Team returns the team with the given name
+func (s *Server) Team(name string) (*models.TeamDTO, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[name]
+
+	return t, ok
+}
+
+// Datasource returns the datasource with the given uid
+func (s *Server) Datasource(uid string) (*models.DataSource, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, ok := s.datasources[uid]
+
+	return ds, ok
+}
+
+// DatasourceLBACRules returns the team LBAC rules of the datasource with the given uid
+func (s *Server) DatasourceLBACRules(uid string) ([]*models.TeamLBACRule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules, ok := s.lbacRules[uid]
+
+	return rules, ok
+}
+
+// FailQuery makes queries with the given expr fail with the message, other queries of existing datasources succeed
+func (s *Server) FailQuery(expr, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queryErrors[expr] = message
+}
+
+// AlertRuleGroup returns the rule group with the given title in a folder
+func (s *Server) AlertRuleGroup(folderUID, title string) (*models.AlertRuleGroup, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.ruleGroups[ruleGroupKey(folderUID, title)]
+
+	return g, ok
+}
+
+// ContactPoint returns the contact point with the given uid
+func (s *Server) ContactPoint(uid string) (*models.EmbeddedContactPoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp, ok := s.contactPoints[uid]
+
+	return cp, ok
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.user != "" {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != s.user || password != s.password {
+				writeMessage(w, http.StatusUnauthorized, "invalid username or password")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// id returns the next numeric id, the caller must hold the lock
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func ruleGroupKey(folderUID, title string) string {
+	return folderUID + "/" + title
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, models.HealthResponse{Commit: "grafanafake", Database: "ok", Version: Version})
+}
+
+func (s *Server) handleOrg(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, models.OrgDetailsDTO{ID: 1, Name: "Main Org."})
+}
+
+// handleSearch supports the type, query, folderUIDs, dashboardUIDs, limit and page parameters
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+
+	hits := models.HitList{}
+
+	if t := query.Get("type"); t == "" || t == "dash-folder" {
+		for _, f := range s.folders {
+			hits = append(hits, &models.Hit{ID: f.ID, UID: f.UID, Title: f.Title, FolderUID: f.ParentUID, Type: "dash-folder", Tags: []string{}})
+		}
+	}
+
+	if t := query.Get("type"); t == "" || t == "dash-db" {
+		for uid, d := range s.dashboards {
+			title, _ := d.model["title"].(string) //nolint:errcheck
+			hits = append(hits, &models.Hit{ID: d.id, UID: uid, Title: title, FolderUID: d.folderUID, Type: "dash-db", Tags: []string{}})
+		}
+	}
+
+	s.mu.Unlock()
+
+	hits = slices.DeleteFunc(hits, func(h *models.Hit) bool {
+		if q := query.Get("query"); q != "" && !strings.Contains(strings.ToLower(h.Title), strings.ToLower(q)) {
+			return true
+		}
+
+		if uids := query["folderUIDs"]; len(uids) > 0 && !slices.Contains(uids, h.FolderUID) {
+			return true
+		}
+
+		return len(query["dashboardUIDs"]) > 0 && !slices.Contains(query["dashboardUIDs"], h.UID)
+	})
+
+	slices.SortFunc(hits, func(a, b *models.Hit) int {
+		return strings.Compare(a.Title+a.UID, b.Title+b.UID)
+	})
+
+	writeJSON(w, http.StatusOK, paginate(hits, query.Get("limit"), query.Get("page")))
+}
+
+func (s *Server) handleGetDashboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dashboards[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Dashboard not found")
+		return
+	}
+
+	meta := &models.DashboardMeta{FolderUID: d.folderUID, Version: d.version, Type: "db"}
+	if f, ok := s.folders[d.folderUID]; ok {
+		meta.FolderTitle = f.Title
+		meta.FolderID = f.ID
+	}
+
+	writeJSON(w, http.StatusOK, models.DashboardFullWithMeta{Dashboard: d.model, Meta: meta})
+}
+
+func (s *Server) handlePostDashboard(w http.ResponseWriter, r *http.Request) {
+	var cmd models.SaveDashboardCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	model, ok := cmd.Dashboard.(map[string]any)
+	if !ok {
+		writeMessage(w, http.StatusBadRequest, "dashboard must be an object")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cmd.FolderUID != "" {
+		if _, ok := s.folders[cmd.FolderUID]; !ok {
+			writeMessage(w, http.StatusBadRequest, "folder not found")
+			return
+		}
+	}
+
+	uid, _ := model["uid"].(string) //nolint:errcheck
+	if uid == "" {
+		uid = fmt.Sprintf("fake%06d", s.nextID+1)
+	}
+
+	d, exists := s.dashboards[uid]
+	if exists && !cmd.Overwrite {
+		writeMessage(w, http.StatusPreconditionFailed, "A dashboard with the same uid already exists")
+		return
+	}
+
+	if !exists {
+		d = &dashboard{id: s.id()}
+		s.dashboards[uid] = d
+	}
+
+	d.version++
+	d.folderUID = cmd.FolderUID
+	d.model = model
+	d.model["uid"] = uid
+	d.model["id"] = d.id
+	d.model["version"] = d.version
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      d.id,
+		"uid":     uid,
+		"url":     "/d/" + uid,
+		"status":  "success",
+		"version": d.version,
+	})
+}
+
+func (s *Server) handleDeleteDashboard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+
+	d, ok := s.dashboards[uid]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Dashboard not found")
+		return
+	}
+
+	delete(s.dashboards, uid)
+
+	title, _ := d.model["title"].(string) //nolint:errcheck
+	writeJSON(w, http.StatusOK, map[string]any{"id": d.id, "title": title, "message": "Dashboard deleted"})
+}
+
+func (s *Server) handleGetDashboardResource(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dashboards[r.PathValue("name")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "dashboards.dashboard.grafana.app not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, d.resource(r.PathValue("name"), r.PathValue("namespace")))
+}
+
+func (s *Server) handleCreateDashboardResource(w http.ResponseWriter, r *http.Request) {
+	var res dashboardResource
+	if !decode(w, r, &res) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.dashboards[res.Metadata.Name]; exists {
+		writeMessage(w, http.StatusConflict, "dashboards.dashboard.grafana.app already exists")
+		return
+	}
+
+	d := &dashboard{id: s.id()}
+	if !s.storeDashboardResource(w, d, &res) {
+		return
+	}
+
+	s.dashboards[res.Metadata.Name] = d
+
+	writeJSON(w, http.StatusCreated, d.resource(res.Metadata.Name, r.PathValue("namespace")))
+}
+
+func (s *Server) handleUpdateDashboardResource(w http.ResponseWriter, r *http.Request) {
+	var res dashboardResource
+	if !decode(w, r, &res) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.dashboards[r.PathValue("name")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "dashboards.dashboard.grafana.app not found")
+		return
+	}
+
+	if res.Metadata.ResourceVersion != strconv.FormatInt(d.version, 10) {
+		writeMessage(w, http.StatusConflict, "the object has been modified")
+		return
+	}
+
+	if !s.storeDashboardResource(w, d, &res) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, d.resource(r.PathValue("name"), r.PathValue("namespace")))
+}
+
+func (s *Server) storeDashboardResource(w http.ResponseWriter, d *dashboard, res *dashboardResource) bool {
+	folderUID := res.Metadata.Annotations["grafana.app/folder"]
+	if folderUID != "" {
+		if _, ok := s.folders[folderUID]; !ok {
+			writeMessage(w, http.StatusBadRequest, "folder not found")
+			return false
+		}
+	}
+
+	d.version++
+	d.folderUID = folderUID
+	d.annotations = res.Metadata.Annotations
+	d.model = res.Spec
+	d.model["uid"] = res.Metadata.Name
+	d.model["id"] = d.id
+	d.model["version"] = d.version
+
+	return true
+}
+
+func (s *Server) handleDeleteDashboardResource(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	if _, ok := s.dashboards[name]; !ok {
+		writeMessage(w, http.StatusNotFound, "dashboards.dashboard.grafana.app not found")
+		return
+	}
+
+	delete(s.dashboards, name)
+
+	writeJSON(w, http.StatusOK, map[string]any{"kind": "Status", "status": "Success"})
+}
+
+func (d *dashboard) resource(name, namespace string) *dashboardResource {
+	res := &dashboardResource{APIVersion: "dashboard.grafana.app/v1beta1", Kind: "Dashboard", Spec: d.model}
+	res.Metadata.Name = name
+	res.Metadata.Namespace = namespace
+	res.Metadata.ResourceVersion = strconv.FormatInt(d.version, 10)
+	res.Metadata.Annotations = d.annotations
+
+	return res
+}
+
+// handleGetFolders lists the folders below parentUid, the root folders when it is not set
+func (s *Server) handleGetFolders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+
+	hits := make([]*models.FolderSearchHit, 0, len(s.folders))
+
+	for _, f := range s.folders {
+		if f.ParentUID == query.Get("parentUid") {
+			hits = append(hits, &models.FolderSearchHit{ID: f.ID, UID: f.UID, Title: f.Title, ParentUID: f.ParentUID})
+		}
+	}
+
+	s.mu.Unlock()
+
+	slices.SortFunc(hits, func(a, b *models.FolderSearchHit) int {
+		return strings.Compare(a.Title+a.UID, b.Title+b.UID)
+	})
+
+	writeJSON(w, http.StatusOK, paginate(hits, query.Get("limit"), query.Get("page")))
+}
+
+func (s *Server) handleGetFolder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.folders[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request) {
+	var cmd models.CreateFolderCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.folders[cmd.UID]; exists {
+		writeMessage(w, http.StatusConflict, "a folder with the same uid already exists")
+		return
+	}
+
+	if _, ok := s.folders[cmd.ParentUID]; cmd.ParentUID != "" && !ok {
+		writeMessage(w, http.StatusBadRequest, "parent folder not found")
+		return
+	}
+
+	f := &models.Folder{ID: s.id(), UID: cmd.UID, Title: cmd.Title, ParentUID: cmd.ParentUID, Version: 1}
+	if f.UID == "" {
+		f.UID = fmt.Sprintf("fake%06d", f.ID)
+	}
+
+	f.URL = "/dashboards/f/" + f.UID
+	s.folders[f.UID] = f
+
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (s *Server) handleUpdateFolder(w http.ResponseWriter, r *http.Request) {
+	var cmd models.UpdateFolderCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.folders[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	if !cmd.Overwrite && cmd.Version != f.Version {
+		writeMessage(w, http.StatusPreconditionFailed, "the folder has been changed by someone else")
+		return
+	}
+
+	f.Title = cmd.Title
+	f.Version++
+
+	writeJSON(w, http.StatusOK, f)
+}
+
+func (s *Server) handleMoveFolder(w http.ResponseWriter, r *http.Request) {
+	var cmd models.MoveFolderCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.folders[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	if _, ok := s.folders[cmd.ParentUID]; cmd.ParentUID != "" && !ok {
+		writeMessage(w, http.StatusBadRequest, "parent folder not found")
+		return
+	}
+
+	f.ParentUID = cmd.ParentUID
+	f.Version++
+
+	writeJSON(w, http.StatusOK, f)
+}
+
+// handleDeleteFolder deletes the folder with its subfolders, dashboards and alert rule groups like Grafana does
+func (s *Server) handleDeleteFolder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.folders[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	s.deleteFolder(f.UID)
+
+	writeJSON(w, http.StatusOK, map[string]any{"id": f.ID, "title": f.Title, "message": "Folder deleted"})
+}
+
+// deleteFolder removes the folder and its content, the caller must hold the lock
+func (s *Server) deleteFolder(uid string) {
+	for _, f := range s.folders {
+		if f.ParentUID == uid {
+			s.deleteFolder(f.UID)
+		}
+	}
+
+	for dashboardUID, d := range s.dashboards {
+		if d.folderUID == uid {
+			delete(s.dashboards, dashboardUID)
+		}
+	}
+
+	for key, g := range s.ruleGroups {
+		if g.FolderUID == uid {
+			delete(s.ruleGroups, key)
+		}
+	}
+
+	delete(s.folders, uid)
+	delete(s.permissions, uid)
+}
+
+func (s *Server) handleUpdateFolderPermissions(w http.ResponseWriter, r *http.Request) {
+	var cmd models.UpdateDashboardACLCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := s.folders[uid]; !ok {
+		writeMessage(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	s.permissions[uid] = cmd.Items
+
+	writeMessage(w, http.StatusOK, "Folder permissions updated")
+}
+
+func (s *Server) handleSearchTeams(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.URL.Query().Get("name")
+	result := &models.SearchTeamQueryResult{Teams: []*models.TeamDTO{}, Page: 1}
+
+	for _, t := range s.teams {
+		if name == "" || t.Name == name {
+			result.Teams = append(result.Teams, t)
+		}
+	}
+
+	result.TotalCount = int64(len(result.Teams))
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	var cmd models.CreateTeamCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.teams[cmd.Name]; exists {
+		writeMessage(w, http.StatusConflict, "Team name taken")
+		return
+	}
+
+	t := &models.TeamDTO{ID: s.id(), Name: cmd.Name, Email: cmd.Email}
+	t.UID = fmt.Sprintf("fake%06d", t.ID)
+	s.teams[t.Name] = t
+
+	writeJSON(w, http.StatusOK, &models.CreateTeamOKBody{TeamID: t.ID, UID: t.UID, Message: "Team created"})
+}
+
+func (s *Server) handleGetDatasources(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+
+	list := make([]*models.DataSource, 0, len(s.datasources))
+	for _, ds := range s.datasources {
+		list = append(list, ds)
+	}
+
+	s.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b *models.DataSource) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGetDatasource(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, ok := s.datasources[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Data source not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ds)
+}
+
+func (s *Server) handleGetDatasourceByName(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ds := range s.datasources {
+		if ds.Name == r.PathValue("name") {
+			writeJSON(w, http.StatusOK, ds)
+			return
+		}
+	}
+
+	writeMessage(w, http.StatusNotFound, "Data source not found")
+}
+
+func (s *Server) handleAddDatasource(w http.ResponseWriter, r *http.Request) {
+	var cmd models.AddDataSourceCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ds := range s.datasources {
+		if ds.Name == cmd.Name || ds.UID == cmd.UID {
+			writeMessage(w, http.StatusConflict, "data source with the same name or uid already exists")
+			return
+		}
+	}
+
+	ds := &models.DataSource{
+		ID:               s.id(),
+		OrgID:            1,
+		UID:              cmd.UID,
+		Name:             cmd.Name,
+		Type:             cmd.Type,
+		Access:           cmd.Access,
+		URL:              cmd.URL,
+		User:             cmd.User,
+		Database:         cmd.Database,
+		BasicAuth:        cmd.BasicAuth,
+		BasicAuthUser:    cmd.BasicAuthUser,
+		WithCredentials:  cmd.WithCredentials,
+		IsDefault:        cmd.IsDefault,
+		JSONData:         cmd.JSONData,
+		SecureJSONFields: secureFields(cmd.SecureJSONData),
+		Version:          1,
+	}
+	if ds.UID == "" {
+		ds.UID = fmt.Sprintf("fake%06d", ds.ID)
+	}
+
+	s.datasources[ds.UID] = ds
+
+	writeJSON(w, http.StatusOK, map[string]any{"datasource": ds, "id": ds.ID, "name": ds.Name, "message": "Datasource added"})
+}
+
+func (s *Server) handleUpdateDatasource(w http.ResponseWriter, r *http.Request) {
+	var cmd models.UpdateDataSourceCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ds, ok := s.datasources[r.PathValue("uid")]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Data source not found")
+		return
+	}
+
+	ds.Name = cmd.Name
+	ds.Type = cmd.Type
+	ds.Access = cmd.Access
+	ds.URL = cmd.URL
+	ds.User = cmd.User
+	ds.Database = cmd.Database
+	ds.BasicAuth = cmd.BasicAuth
+	ds.BasicAuthUser = cmd.BasicAuthUser
+	ds.WithCredentials = cmd.WithCredentials
+	ds.IsDefault = cmd.IsDefault
+	ds.JSONData = cmd.JSONData
+	ds.SecureJSONFields = secureFields(cmd.SecureJSONData)
+	ds.Version++
+
+	writeJSON(w, http.StatusOK, map[string]any{"datasource": ds, "id": ds.ID, "name": ds.Name, "message": "Datasource updated"})
+}
+
+func (s *Server) handleDeleteDatasource(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := s.datasources[uid]; !ok {
+		writeMessage(w, http.StatusNotFound, "Data source not found")
+		return
+	}
+
+	delete(s.datasources, uid)
+	delete(s.lbacRules, uid)
+
+	writeMessage(w, http.StatusOK, "Data source deleted")
+}
+
+func (s *Server) handleGetLBACRules(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := s.datasources[uid]; !ok {
+		writeMessage(w, http.StatusNotFound, "Data source not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &models.TeamLBACRules{Rules: s.lbacRules[uid]})
+}
+
+// handleQueryDatasources answers with 400 along with the results when a query fails, like Grafana does
+func (s *Server) handleQueryDatasources(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Queries []map[string]any `json:"queries"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type result struct {
+		Status int    `json:"status"`
+		Frames []any  `json:"frames"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	status := http.StatusOK
+	results := make(map[string]result, len(req.Queries))
+
+	for _, query := range req.Queries {
+		refID, _ := query["refId"].(string)
+		ref, _ := query["datasource"].(map[string]any)
+		uid, _ := ref["uid"].(string)
+		expr, _ := query["expr"].(string)
+
+		res := result{Status: http.StatusOK, Frames: []any{}}
+
+		if _, ok := s.datasources[uid]; !ok {
+			res = result{Status: http.StatusNotFound, Error: "data source not found"}
+		} else if message, ok := s.queryErrors[expr]; ok {
+			res = result{Status: http.StatusBadRequest, Error: message}
+		}
+
+		if res.Error != "" {
+			status = http.StatusBadRequest
+		}
+
+		results[refID] = res
+	}
+
+	writeJSON(w, status, map[string]any{"results": results})
+}
+
+func (s *Server) handleUpdateLBACRules(w http.ResponseWriter, r *http.Request) {
+	var cmd models.UpdateTeamLBACCommand
+	if !decode(w, r, &cmd) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+
+	ds, ok := s.datasources[uid]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Data source not found")
+		return
+	}
+
+	s.lbacRules[uid] = cmd.Rules
+
+	writeJSON(w, http.StatusOK, &models.UpdateTeamLBACRulesAPIOKBody{
+		ID:      ds.ID,
+		UID:     uid,
+		Name:    ds.Name,
+		Rules:   cmd.Rules,
+		Message: "Data source LBAC rules updated",
+	})
+}
+
+func (s *Server) handleGetRuleGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.ruleGroups[ruleGroupKey(r.PathValue("folder"), r.PathValue("group"))]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g)
+}
+
+// handlePutRuleGroup replaces the group, rules missing from the body are deleted
+func (s *Server) handlePutRuleGroup(w http.ResponseWriter, r *http.Request) {
+	var g models.AlertRuleGroup
+	if !decode(w, r, &g) {
+		return
+	}
+
+	folderUID := r.PathValue("folder")
+	title := r.PathValue("group")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.folders[folderUID]; !ok {
+		writeMessage(w, http.StatusBadRequest, "folder not found")
+		return
+	}
+
+	for _, rule := range g.Rules {
+		if err := validateRule(rule); err != nil {
+			writeMessage(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	g.FolderUID = folderUID
+	g.Title = title
+
+	for _, rule := range g.Rules {
+		s.setRuleDefaults(rule, folderUID, title)
+	}
+
+	s.ruleGroups[ruleGroupKey(folderUID, title)] = &g
+
+	writeJSON(w, http.StatusOK, &g)
+}
+
+func (s *Server) handleDeleteRuleGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := ruleGroupKey(r.PathValue("folder"), r.PathValue("group"))
+	if _, ok := s.ruleGroups[key]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{})
+		return
+	}
+
+	delete(s.ruleGroups, key)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostAlertRule adds the rule to its group, creating the group when needed
+func (s *Server) handlePostAlertRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.ProvisionedAlertRule
+	if !decode(w, r, &rule) {
+		return
+	}
+
+	if rule.FolderUID == nil || rule.RuleGroup == nil {
+		writeMessage(w, http.StatusBadRequest, "folderUID and ruleGroup are required")
+		return
+	}
+
+	if err := validateRule(&rule); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.folders[*rule.FolderUID]; !ok {
+		writeMessage(w, http.StatusBadRequest, "folder not found")
+		return
+	}
+
+	key := ruleGroupKey(*rule.FolderUID, *rule.RuleGroup)
+
+	g, ok := s.ruleGroups[key]
+	if !ok {
+		g = &models.AlertRuleGroup{FolderUID: *rule.FolderUID, Title: *rule.RuleGroup, Interval: 60}
+		s.ruleGroups[key] = g
+	}
+
+	s.setRuleDefaults(&rule, g.FolderUID, g.Title)
+	g.Rules = append(g.Rules, &rule)
+
+	writeJSON(w, http.StatusCreated, &rule)
+}
+
+// handlePutAlertRule replaces a rule of an existing group
+func (s *Server) handlePutAlertRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.ProvisionedAlertRule
+	if !decode(w, r, &rule) {
+		return
+	}
+
+	if err := validateRule(&rule); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	uid := r.PathValue("uid")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, g := range s.ruleGroups {
+		for i, existing := range g.Rules {
+			if existing.UID != uid {
+				continue
+			}
+
+			rule.ID = existing.ID
+			rule.UID = uid
+			s.setRuleDefaults(&rule, g.FolderUID, g.Title)
+			g.Rules[i] = &rule
+
+			writeJSON(w, http.StatusOK, &rule)
+
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusNotFound, map[string]any{})
+}
+
+// validateRule rejects alert rules whose condition does not reference one of their queries
+func validateRule(rule *models.ProvisionedAlertRule) error {
+	if rule.Condition == nil || *rule.Condition == "" {
+		return nil
+	}
+
+	for _, q := range rule.Data {
+		if q != nil && q.RefID == *rule.Condition {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid alert rule %s: condition %s does not exist, must be one of the refIDs of the queries", rule.UID, *rule.Condition)
+}
+
+// setRuleDefaults fills the fields Grafana sets on stored rules, the caller must hold the lock
+func (s *Server) setRuleDefaults(rule *models.ProvisionedAlertRule, folderUID, group string) {
+	orgID := int64(1)
+
+	rule.FolderUID = &folderUID
+	rule.RuleGroup = &group
+	rule.OrgID = &orgID
+
+	if rule.ID == 0 {
+		rule.ID = s.id()
+	}
+
+	if rule.UID == "" {
+		rule.UID = fmt.Sprintf("fake%06d", rule.ID)
+	}
+}
+
+// handleGetContactPoints supports the name parameter
+func (s *Server) handleGetContactPoints(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	s.mu.Lock()
+
+	list := make(models.ContactPoints, 0, len(s.contactPoints))
+
+	for _, cp := range s.contactPoints {
+		if name == "" || cp.Name == name {
+			list = append(list, cp)
+		}
+	}
+
+	s.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b *models.EmbeddedContactPoint) int {
+		return strings.Compare(a.Name+a.UID, b.Name+b.UID)
+	})
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handlePostContactPoint(w http.ResponseWriter, r *http.Request) {
+	var cp models.EmbeddedContactPoint
+	if !decode(w, r, &cp) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.contactPoints[cp.UID]; exists {
+		writeMessage(w, http.StatusBadRequest, "a contact point with the same uid already exists")
+		return
+	}
+
+	if cp.UID == "" {
+		cp.UID = fmt.Sprintf("fake%06d", s.id())
+	}
+
+	s.contactPoints[cp.UID] = &cp
+
+	writeJSON(w, http.StatusAccepted, &cp)
+}
+
+func (s *Server) handlePutContactPoint(w http.ResponseWriter, r *http.Request) {
+	var cp models.EmbeddedContactPoint
+	if !decode(w, r, &cp) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := r.PathValue("uid")
+	if _, ok := s.contactPoints[uid]; !ok {
+		writeMessage(w, http.StatusBadRequest, "contact point not found")
+		return
+	}
+
+	cp.UID = uid
+	s.contactPoints[uid] = &cp
+
+	writeJSON(w, http.StatusAccepted, map[string]any{})
+}
+
+func (s *Server) handleDeleteContactPoint(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.contactPoints, r.PathValue("uid"))
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func secureFields(data map[string]string) map[string]bool {
+	fields := make(map[string]bool, len(data))
+	for k := range data {
+		fields[k] = true
+	}
+
+	return fields
+}
+
+// paginate returns the requested page, pages start at 1 and limit defaults to 1000 like in Grafana
+func paginate[T any](items []T, limitParam, pageParam string) []T {
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit <= 0 {
+		limit = 1000
+	}
+
+	page, err := strconv.Atoi(pageParam)
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return []T{}
+	}
+
+	return items[start:min(start+limit, len(items))]
+}
+
+// decode reads the JSON body of the request, gzip encoded bodies are decompressed
+func (s *Server) handleGetMuteTimings(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+
+	list := make(models.MuteTimings, 0, len(s.muteTimings))
+	for _, mt := range s.muteTimings {
+		list = append(list, mt)
+	}
+
+	s.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b *models.MuteTimeInterval) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleGetPolicyTree returns the default policy tree of a new instance
+func (s *Server) handleGetPolicyTree(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, &models.Route{Receiver: "grafana-default-email"})
+}
+
+func (s *Server) handlePostMuteTiming(w http.ResponseWriter, r *http.Request) {
+	var mt models.MuteTimeInterval
+	if !decode(w, r, &mt) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.muteTimings[mt.Name]; exists {
+		writeMessage(w, http.StatusConflict, "a mute timing with the same name already exists")
+		return
+	}
+
+	s.muteTimings[mt.Name] = &mt
+
+	writeJSON(w, http.StatusCreated, &mt)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	body := r.Body
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeMessage(w, http.StatusBadRequest, "bad request data: "+err.Error())
+			return false
+		}
+		defer gz.Close() //nolint:errcheck
+
+		body = gz
+	}
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		writeMessage(w, http.StatusBadRequest, "bad request data: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
@@ -0,0 +1,198 @@
+package grafanafake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
+	"github.com/grafana/grafana-openapi-client-go/client/folders"
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/client/search"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grafana-operator/v5/api/v1beta1"
+	grafanaclient "github.com/grafana/grafana-operator/v5/controllers/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServerWithOperatorClient(t *testing.T) {
+	srv := NewServer(WithBasicAuth("admin", "secret"))
+	defer srv.Close()
+
+	s := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "credentials"},
+		Data:       map[string][]byte{"user": []byte("admin"), "password": []byte("secret")},
+	}
+
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "fake"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL: srv.URL,
+				AdminUser: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+					Key:                  "user",
+				},
+				AdminPassword: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+					Key:                  "password",
+				},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminURL: srv.URL},
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(s).WithObjects(credentials).Build()
+
+	cl, err := grafanaclient.NewGeneratedGrafanaClient(context.Background(), k8sClient, grafana)
+	require.NoError(t, err)
+
+	t.Run("dashboards in folders", func(t *testing.T) {
+		_, err := cl.Folders.CreateFolder(&models.CreateFolderCommand{UID: "team", Title: "Team"})
+		require.NoError(t, err)
+
+		resp, err := cl.Dashboards.PostDashboard(&models.SaveDashboardCommand{
+			Dashboard: map[string]any{"uid": "overview", "title": "Overview"},
+			FolderUID: "team",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", *resp.Payload.Status)
+
+		dash, err := cl.Dashboards.GetDashboardByUID("overview")
+		require.NoError(t, err)
+		assert.Equal(t, "team", dash.Payload.Meta.FolderUID)
+		assert.Equal(t, "Team", dash.Payload.Meta.FolderTitle)
+
+		hits, err := cl.Search.Search(search.NewSearchParams().WithFolderUIDs([]string{"team"}))
+		require.NoError(t, err)
+		require.Len(t, hits.Payload, 1)
+		assert.Equal(t, "overview", hits.Payload[0].UID)
+
+		// Deleting a folder deletes its dashboards
+		_, err = cl.Folders.DeleteFolder(folders.NewDeleteFolderParams().WithFolderUID("team"))
+		require.NoError(t, err)
+
+		_, _, found := srv.Dashboard("overview")
+		assert.False(t, found)
+
+		_, err = cl.Dashboards.GetDashboardByUID("overview")
+
+		var notFound *dashboards.GetDashboardByUIDNotFound
+		assert.True(t, errors.As(err, &notFound))
+	})
+
+	t.Run("dashboard resources", func(t *testing.T) {
+		ctx := context.Background()
+
+		resources, err := grafanaclient.NewDashboardResourceClient(ctx, fake.NewClientBuilder().WithScheme(s).WithObjects(credentials).Build(), grafana)
+		require.NoError(t, err)
+
+		_, err = resources.Get(ctx, "provisioned")
+		require.ErrorIs(t, err, grafanaclient.ErrDashboardResourceNotFound)
+
+		dashboard := &grafanaclient.DashboardResource{
+			APIVersion: grafanaclient.DashboardResourceAPIVersion,
+			Kind:       "Dashboard",
+			Metadata: grafanaclient.DashboardResourceMetadata{
+				Name:        "provisioned",
+				Annotations: map[string]string{grafanaclient.AnnotationManagedBy: grafanaclient.ManagerKindKubectl},
+			},
+			Spec: map[string]any{"title": "Provisioned"},
+		}
+		require.NoError(t, resources.Create(ctx, dashboard))
+
+		// Updates must be based on the current resource version
+		require.Error(t, resources.Update(ctx, dashboard))
+
+		applied, err := resources.Get(ctx, "provisioned")
+		require.NoError(t, err)
+		assert.Equal(t, grafanaclient.ManagerKindKubectl, applied.Metadata.Annotations[grafanaclient.AnnotationManagedBy])
+
+		dashboard.Metadata.ResourceVersion = applied.Metadata.ResourceVersion
+		dashboard.Spec = map[string]any{"title": "Renamed"}
+		require.NoError(t, resources.Update(ctx, dashboard))
+
+		model, _, found := srv.Dashboard("provisioned")
+		require.True(t, found)
+		assert.Equal(t, "Renamed", model["title"])
+
+		require.NoError(t, resources.Delete(ctx, "provisioned"))
+		require.ErrorIs(t, resources.Delete(ctx, "provisioned"), grafanaclient.ErrDashboardResourceNotFound)
+	})
+
+	t.Run("datasources", func(t *testing.T) {
+		_, err := cl.Datasources.AddDataSource(&models.AddDataSourceCommand{
+			UID:            "prometheus",
+			Name:           "Prometheus",
+			Type:           "prometheus",
+			SecureJSONData: map[string]string{"httpHeaderValue1": "token"},
+		})
+		require.NoError(t, err)
+
+		ds, err := cl.Datasources.GetDataSourceByUID("prometheus")
+		require.NoError(t, err)
+		assert.Equal(t, "Prometheus", ds.Payload.Name)
+		assert.True(t, ds.Payload.SecureJSONFields["httpHeaderValue1"])
+	})
+
+	t.Run("datasource queries", func(t *testing.T) {
+		srv.FailQuery("rate(up[", "parse error: unclosed left bracket")
+
+		failures, err := grafanaclient.QueryDatasources(context.Background(), k8sClient, grafana, []map[string]any{
+			{"refId": "A", "datasource": map[string]any{"uid": "prometheus"}, "expr": "up"},
+			{"refId": "B", "datasource": map[string]any{"uid": "prometheus"}, "expr": "rate(up["},
+			{"refId": "C", "datasource": map[string]any{"uid": "missing"}, "expr": "up"},
+		}, 5*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"B": "parse error: unclosed left bracket",
+			"C": "data source not found",
+		}, failures)
+	})
+
+	t.Run("alert rule groups", func(t *testing.T) {
+		_, err := cl.Folders.CreateFolder(&models.CreateFolderCommand{UID: "alerts", Title: "Alerts"})
+		require.NoError(t, err)
+
+		folderUID := "alerts"
+		group := "availability"
+		title := "Instance down"
+
+		_, err = cl.Provisioning.PostAlertRule(provisioning.NewPostAlertRuleParams().WithBody(&models.ProvisionedAlertRule{
+			UID:       "down",
+			FolderUID: &folderUID,
+			RuleGroup: &group,
+			Title:     &title,
+		}))
+		require.NoError(t, err)
+
+		_, err = cl.Provisioning.PutAlertRuleGroup(provisioning.NewPutAlertRuleGroupParams().
+			WithFolderUID(folderUID).
+			WithGroup(group).
+			WithBody(&models.AlertRuleGroup{Interval: 30, Rules: []*models.ProvisionedAlertRule{}}))
+		require.NoError(t, err)
+
+		applied, err := cl.Provisioning.GetAlertRuleGroup(group, folderUID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(30), applied.Payload.Interval)
+		assert.Empty(t, applied.Payload.Rules)
+	})
+
+	t.Run("rejects invalid credentials", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/api/health")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}